@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/config"
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// bill-subscriptions invoices subscription deliveries for a month. Customers already
+// invoiced for the month are skipped, so it is safe to re-run (e.g. from cron).
+func main() {
+	lastMonth := time.Now().AddDate(0, -1, 0).Format("2006-01")
+	month := flag.String("period", lastMonth, "Billing period (YYYY-MM), defaults to last month")
+	customerID := flag.Int64("customer", 0, "Bill a single customer ID (0 = all customers)")
+	flag.Parse()
+
+	period, err := entity.ParseBillingPeriod(*month)
+	if err != nil {
+		log.Fatal("Invalid period, usage: bill-subscriptions -period 2026-09 [-customer 42]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	db, err := postgres.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	txManager := postgres.NewTxManager(db)
+	ledgerRepo := postgres.NewCustomerLedgerRepository(db)
+	ledgerService := service.NewCustomerLedgerService(ledgerRepo, postgres.NewCustomerRepository(db), txManager)
+	billingService := service.NewSubscriptionBillingService(postgres.NewSubscriptionInvoiceRepository(db), ledgerRepo, ledgerService, txManager)
+
+	var customer *int64
+	if *customerID > 0 {
+		customer = customerID
+	}
+
+	fmt.Printf("Billing subscriptions for %s to %s...\n", period.Start.Format("2006-01-02"), period.End.Format("2006-01-02"))
+	result, err := billingService.RunBilling(context.Background(), period, customer, nil)
+	if err != nil {
+		log.Fatal("Billing run failed:", err)
+	}
+
+	for _, inv := range result.Generated {
+		fmt.Printf("  %s  customer %-6d  %3d lines  %s\n", inv.InvoiceNumber, inv.CustomerID, len(inv.Lines), inv.TotalAmount.StringFixed(2))
+	}
+	fmt.Printf("Generated %d invoices, skipped %d customers already invoiced\n", len(result.Generated), len(result.Skipped))
+}
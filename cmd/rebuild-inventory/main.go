@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/config"
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// rebuild-inventory compares inventory_levels with the inventory_movements ledger, reports
+// every level that has drifted and, unless -dry-run is given, resets it to the ledger balance.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Only report drift, do not change inventory levels")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	db, err := postgres.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	inventoryService := service.NewInventoryService(
+		postgres.NewInventoryRepository(db, entity.CostingMethod(cfg.Inventory.CostingMethod)),
+		postgres.NewInventoryAdjustmentRepository(db),
+		postgres.NewWarehouseRepository(db),
+		postgres.NewProductVariantRepository(db),
+		postgres.NewTxManager(db),
+		cfg.Inventory,
+	)
+
+	ctx := context.Background()
+	var drift []entity.InventoryDrift
+	if *dryRun {
+		drift, err = inventoryService.Drift(ctx)
+	} else {
+		drift, err = inventoryService.RebuildLevels(ctx)
+	}
+	if err != nil {
+		log.Fatal("Inventory rebuild failed:", err)
+	}
+
+	for _, d := range drift {
+		fmt.Printf("  warehouse %-4d  variant %-6d  level %12s  ledger %12s  drift %12s\n",
+			d.WarehouseID, d.VariantID, d.LevelQuantity.StringFixed(3), d.LedgerQuantity.StringFixed(3), d.Drift().StringFixed(3))
+	}
+	switch {
+	case len(drift) == 0:
+		fmt.Println("Inventory levels match the movement ledger")
+	case *dryRun:
+		fmt.Printf("%d inventory levels drift from the ledger (dry run, nothing changed)\n", len(drift))
+	default:
+		fmt.Printf("Rebuilt %d inventory levels from the ledger\n", len(drift))
+	}
+}
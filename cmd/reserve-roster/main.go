@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/config"
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// reserve-roster expires lapsed stock reservations and holds stock for the subscription
+// deliveries due on a date. The date's reservations are replaced on each run, so it is safe to
+// re-run (e.g. from cron every evening, and again after late roster changes).
+func main() {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	dateStr := flag.String("date", tomorrow, "Roster date (YYYY-MM-DD), defaults to tomorrow")
+	flag.Parse()
+
+	date, err := time.Parse("2006-01-02", *dateStr)
+	if err != nil {
+		log.Fatal("Invalid date, usage: reserve-roster -date 2026-10-17")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	db, err := postgres.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	reservationService := service.NewStockReservationService(
+		postgres.NewInventoryRepository(db, entity.CostingMethod(cfg.Inventory.CostingMethod)),
+		postgres.NewSubscriptionRepository(db),
+		postgres.NewCustomerAddressRepository(db),
+		postgres.NewPincodeRepository(db),
+		postgres.NewProductVariantRepository(db),
+		postgres.NewTxManager(db),
+	)
+
+	ctx := context.Background()
+	expired, err := reservationService.ExpireStale(ctx)
+	if err != nil {
+		log.Fatal("Failed to expire reservations:", err)
+	}
+	fmt.Printf("Expired %d lapsed reservations\n", expired)
+
+	fmt.Printf("Reserving stock for the %s roster...\n", date.Format("2006-01-02"))
+	run, err := reservationService.ReserveRoster(ctx, date)
+	if err != nil {
+		log.Fatal("Roster reservation failed:", err)
+	}
+
+	for _, r := range run.Reservations {
+		fmt.Printf("  subscription %-6d  warehouse %-4d  %-30s  %s\n", r.ReferenceID, r.WarehouseID, r.VariantName, r.Quantity.String())
+	}
+	for _, id := range run.Unassigned {
+		fmt.Printf("  subscription %-6d  no delivery zone warehouse serves its address\n", id)
+	}
+	for _, id := range run.Short {
+		fmt.Printf("  subscription %-6d  not enough available stock\n", id)
+	}
+	fmt.Printf("Made %d reservations, %d subscriptions unassigned, %d short\n", len(run.Reservations), len(run.Unassigned), len(run.Short))
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/qwikshelf/api/internal/application/service"
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
 	"github.com/qwikshelf/api/pkg/response"
 )
 
@@ -22,7 +25,7 @@ func NewCollectionHandler(collectionService *service.CollectionService) *Collect
 }
 
 // @Summary      Record a collection
-// @Description  Allows an agent to record a product receipt with weight and supplier
+// @Description  Allows an agent to record a product receipt with weight and supplier, and the milk's shift, fat and SNF percentages, temperature and any adulterants detected. With fat and SNF given, the collection is priced from the rate chart in force for the supplier. Milk with adulterants detected is paid at zero and not taken into stock.
 // @Tags         Collections
 // @Accept       json
 // @Produce      json
@@ -52,7 +55,22 @@ func (h *CollectionHandler) Record(c *gin.Context) {
 		WarehouseID: req.WarehouseID,
 		Weight:      req.Weight,
 		CollectedAt: req.CollectedAt,
+		Shift:       entity.CollectionShift(req.Shift),
 		Notes:       req.Notes,
+		BatchNumber: req.BatchNumber,
+
+		FatPercent:        req.FatPercent,
+		SNFPercent:        req.SNFPercent,
+		Temperature:       req.Temperature,
+		AdulterationFlags: req.AdulterationFlags,
+	}
+	if req.ExpiryDate != "" {
+		t, err := time.Parse("2006-01-02", req.ExpiryDate)
+		if err != nil {
+			response.BadRequest(c, "Invalid expiry_date format. Use YYYY-MM-DD")
+			return
+		}
+		collection.ExpiryDate = &t
 	}
 
 	if err := h.collectionService.RecordCollection(c.Request.Context(), collection); err != nil {
@@ -62,6 +80,8 @@ func (h *CollectionHandler) Record(c *gin.Context) {
 			response.NotFound(c, "Product variant not found")
 		} else if err == domainErrors.ErrSupplierNotFound {
 			response.NotFound(c, "Supplier not found")
+		} else if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Fat and SNF must be between 0 and 100 percent, with a valid shift and known adulterants")
 		} else {
 			response.InternalErrorDebug(c, "Failed to record collection", err)
 		}
@@ -71,13 +91,88 @@ func (h *CollectionHandler) Record(c *gin.Context) {
 	response.Created(c, "Collection recorded successfully", mapCollectionResponse(collection))
 }
 
+// @Summary      Submit a shift of collections
+// @Description  Records every collection of a shift at once; if any entry fails nothing is recorded. With a route, every supplier must be a stop on it and the warehouse defaults to the route's.
+// @Tags         Collections
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.RecordShiftRequest  true  "Shift collections"
+// @Success      201      {object}  response.Response{data=[]dto.CollectionResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /collections/batch [post]
+func (h *CollectionHandler) RecordShift(c *gin.Context) {
+	var req dto.RecordShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	collectedAt := req.CollectedAt
+	if collectedAt.IsZero() {
+		collectedAt = time.Now()
+	}
+	collections := make([]*entity.Collection, 0, len(req.Entries))
+	for _, e := range req.Entries {
+		collections = append(collections, &entity.Collection{
+			VariantID:         e.VariantID,
+			SupplierID:        e.SupplierID,
+			AgentID:           userID.(int64),
+			WarehouseID:       req.WarehouseID,
+			Weight:            e.Weight,
+			CollectedAt:       collectedAt,
+			Shift:             entity.CollectionShift(req.Shift),
+			Notes:             e.Notes,
+			FatPercent:        e.FatPercent,
+			SNFPercent:        e.SNFPercent,
+			Temperature:       e.Temperature,
+			AdulterationFlags: e.AdulterationFlags,
+		})
+	}
+
+	if err := h.collectionService.RecordShift(c.Request.Context(), req.RouteID, collections); err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrCollectionRouteNotFound):
+			response.NotFound(c, "Collection route not found")
+		case errors.Is(err, domainErrors.ErrWarehouseNotFound),
+			errors.Is(err, domainErrors.ErrProductVariantNotFound),
+			errors.Is(err, domainErrors.ErrSupplierNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, domainErrors.ErrSupplierNotOnRoute), errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalErrorDebug(c, "Failed to record shift", err)
+		}
+		return
+	}
+
+	resp := make([]dto.CollectionResponse, 0, len(collections))
+	for _, col := range collections {
+		resp = append(resp, mapCollectionResponse(col))
+	}
+	response.Created(c, "Shift recorded successfully", resp)
+}
+
 // @Summary      List collections
-// @Description  Returns a paginated list of all collections
+// @Description  Returns a paginated list of collections, newest first, optionally filtered by agent, supplier, warehouse, shift and date range
 // @Tags         Collections
 // @Produce      json
 // @Security     BearerAuth
-// @Param        page      query  int  false  "Page number"    default(1)
-// @Param        per_page  query  int  false  "Items per page" default(20)
+// @Param        agent_id      query  int     false  "Agent (user) ID"
+// @Param        supplier_id   query  int     false  "Supplier ID"
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Param        shift         query  string  false  "morning or evening"
+// @Param        start_date    query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date      query  string  false  "End date inclusive (YYYY-MM-DD)"
+// @Param        page          query  int     false  "Page number"    default(1)
+// @Param        per_page      query  int     false  "Items per page" default(20)
 // @Success      200  {object}  response.Response{data=[]dto.CollectionResponse}
 // @Router       /collections [get]
 func (h *CollectionHandler) List(c *gin.Context) {
@@ -91,7 +186,38 @@ func (h *CollectionHandler) List(c *gin.Context) {
 	}
 	offset := (page - 1) * perPage
 
-	collections, total, err := h.collectionService.ListCollections(c.Request.Context(), offset, perPage)
+	var filter repository.CollectionFilter
+	if aid, err := strconv.ParseInt(c.Query("agent_id"), 10, 64); err == nil {
+		filter.AgentID = &aid
+	}
+	if sid, err := strconv.ParseInt(c.Query("supplier_id"), 10, 64); err == nil {
+		filter.SupplierID = &sid
+	}
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &wid
+	}
+	if shift := entity.CollectionShift(c.Query("shift")); shift.IsValid() {
+		filter.Shift = &shift
+	}
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return
+		}
+		filter.From = &t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return
+		}
+		to := t.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	collections, total, err := h.collectionService.ListCollections(c.Request.Context(), filter, offset, perPage)
 	if err != nil {
 		response.InternalErrorDebug(c, "Failed to fetch collections", err)
 		return
@@ -124,7 +250,21 @@ func mapCollectionResponse(c *entity.Collection) dto.CollectionResponse {
 		WarehouseID: c.WarehouseID,
 		Weight:      c.Weight,
 		CollectedAt: c.CollectedAt,
+		Shift:       string(c.Shift),
 		Notes:       c.Notes,
+		BatchNumber: c.BatchNumber,
+		ExpiryDate:  c.ExpiryDate,
+
+		FatPercent:        c.FatPercent,
+		SNFPercent:        c.SNFPercent,
+		Temperature:       c.Temperature,
+		AdulterationFlags: c.AdulterationFlags,
+		RateChartID:       c.RateChartID,
+		Rate:              c.Rate,
+		Amount:            c.Amount,
+	}
+	if resp.AdulterationFlags == nil {
+		resp.AdulterationFlags = []string{}
 	}
 
 	if c.Variant != nil {
@@ -139,3 +279,300 @@ func mapCollectionResponse(c *entity.Collection) dto.CollectionResponse {
 
 	return resp
 }
+
+// @Summary      Collection summary by shift
+// @Description  Totals each supplier's collections per day and shift: count, weight, weighted average fat and SNF, adulterated collections and amount payable
+// @Tags         Collections
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date    query  string  false  "Start date (YYYY-MM-DD), defaults to today"
+// @Param        end_date      query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param        supplier_id   query  int     false  "Supplier ID"
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.CollectionSummaryResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /collections/summary/shifts [get]
+func (h *CollectionHandler) ShiftSummary(c *gin.Context) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	filter, ok := parseCollectionSummaryFilter(c, today)
+	if !ok {
+		return
+	}
+
+	summaries, err := h.collectionService.ShiftSummary(c.Request.Context(), filter)
+	if err != nil {
+		h.writeSummaryError(c, err)
+		return
+	}
+	response.OK(c, "Shift summary retrieved", mapCollectionSummaries(summaries))
+}
+
+// @Summary      Collection summary by period
+// @Description  Totals each supplier's collections over a collection period: count, weight, weighted average fat and SNF, adulterated collections and amount payable
+// @Tags         Collections
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date    query  string  false  "Start date (YYYY-MM-DD), defaults to the first of the month"
+// @Param        end_date      query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param        supplier_id   query  int     false  "Supplier ID"
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.CollectionSummaryResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /collections/summary/period [get]
+func (h *CollectionHandler) PeriodSummary(c *gin.Context) {
+	now := time.Now()
+	filter, ok := parseCollectionSummaryFilter(c, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if !ok {
+		return
+	}
+
+	summaries, err := h.collectionService.PeriodSummary(c.Request.Context(), filter)
+	if err != nil {
+		h.writeSummaryError(c, err)
+		return
+	}
+	response.OK(c, "Period summary retrieved", mapCollectionSummaries(summaries))
+}
+
+func (h *CollectionHandler) writeSummaryError(c *gin.Context, err error) {
+	if err == domainErrors.ErrInvalidInput {
+		response.BadRequest(c, "start_date must not be after end_date")
+		return
+	}
+	response.InternalErrorDebug(c, "Failed to summarize collections", err)
+}
+
+// parseCollectionSummaryFilter reads the supplier, warehouse and inclusive date range of a summary,
+// writing an error response when a date is malformed
+func parseCollectionSummaryFilter(c *gin.Context, defaultFrom time.Time) (repository.CollectionSummaryFilter, bool) {
+	now := time.Now()
+	filter := repository.CollectionSummaryFilter{
+		From: defaultFrom,
+		To:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return filter, false
+		}
+		filter.From = t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return filter, false
+		}
+		filter.To = t
+	}
+	filter.To = filter.To.AddDate(0, 0, 1)
+
+	if sid, err := strconv.ParseInt(c.Query("supplier_id"), 10, 64); err == nil {
+		filter.SupplierID = &sid
+	}
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &wid
+	}
+	return filter, true
+}
+
+func mapCollectionSummaries(summaries []entity.CollectionSummary) []dto.CollectionSummaryResponse {
+	resp := make([]dto.CollectionSummaryResponse, 0, len(summaries))
+	for _, s := range summaries {
+		r := dto.CollectionSummaryResponse{
+			SupplierID:     s.SupplierID,
+			SupplierName:   s.SupplierName,
+			Shift:          string(s.Shift),
+			Collections:    s.Collections,
+			TotalWeight:    s.TotalWeight,
+			AvgFatPercent:  s.AvgFatPercent,
+			AvgSNFPercent:  s.AvgSNFPercent,
+			Adulterated:    s.Adulterated,
+			TotalAmount:    s.TotalAmount,
+			UnpricedWeight: s.UnpricedWeight,
+		}
+		if s.Date != nil {
+			r.Date = s.Date.Format("2006-01-02")
+		}
+		resp = append(resp, r)
+	}
+	return resp
+}
+
+// @Summary      Create a rate chart
+// @Description  Configures a fat/SNF rate chart for a variant, scoped to a supplier, a zone, or neither for the default chart. Milk is paid the rate of the highest slab whose fat and SNF minimums it meets.
+// @Tags         Collections
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.CreateCollectionRateChartRequest  true  "Rate chart"
+// @Success      201      {object}  response.Response{data=dto.CollectionRateChartResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /collections/rate-charts [post]
+func (h *CollectionHandler) CreateRateChart(c *gin.Context) {
+	var req dto.CreateCollectionRateChartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	chart := &entity.CollectionRateChart{
+		Name:       req.Name,
+		VariantID:  req.VariantID,
+		ZoneID:     req.ZoneID,
+		SupplierID: req.SupplierID,
+	}
+	if req.EffectiveFrom != "" {
+		t, err := time.Parse("2006-01-02", req.EffectiveFrom)
+		if err != nil {
+			response.BadRequest(c, "Invalid effective_from format, expected YYYY-MM-DD")
+			return
+		}
+		chart.EffectiveFrom = t
+	}
+	for _, slab := range req.Slabs {
+		chart.Slabs = append(chart.Slabs, entity.CollectionRateSlab{
+			MinFatPercent: slab.MinFatPercent,
+			MinSNFPercent: slab.MinSNFPercent,
+			Rate:          slab.Rate,
+		})
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		createdBy := userID.(int64)
+		chart.CreatedByUserID = &createdBy
+	}
+
+	if err := h.collectionService.CreateRateChart(c.Request.Context(), chart); err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrProductVariantNotFound):
+			response.NotFound(c, "Product variant not found")
+		case errors.Is(err, domainErrors.ErrSupplierNotFound):
+			response.NotFound(c, "Supplier not found")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, "A rate chart is scoped to a supplier or a zone, not both, and needs distinct slabs with no negative values")
+		default:
+			response.InternalErrorDebug(c, "Failed to create rate chart", err)
+		}
+		return
+	}
+
+	response.Created(c, "Rate chart created", mapRateChartResponse(chart))
+}
+
+// @Summary      List rate charts
+// @Description  Returns rate charts, newest first, optionally filtered by variant, zone and supplier
+// @Tags         Collections
+// @Produce      json
+// @Security     BearerAuth
+// @Param        variant_id   query  int   false  "Variant ID"
+// @Param        zone_id      query  int   false  "Zone ID"
+// @Param        supplier_id  query  int   false  "Supplier ID"
+// @Param        active       query  bool  false  "Only active charts"
+// @Success      200  {object}  response.Response{data=[]dto.CollectionRateChartResponse}
+// @Router       /collections/rate-charts [get]
+func (h *CollectionHandler) ListRateCharts(c *gin.Context) {
+	var filter repository.CollectionRateChartFilter
+	if vid, err := strconv.ParseInt(c.Query("variant_id"), 10, 64); err == nil {
+		filter.VariantID = &vid
+	}
+	if zid, err := strconv.ParseInt(c.Query("zone_id"), 10, 64); err == nil {
+		filter.ZoneID = &zid
+	}
+	if sid, err := strconv.ParseInt(c.Query("supplier_id"), 10, 64); err == nil {
+		filter.SupplierID = &sid
+	}
+	filter.ActiveOnly = c.Query("active") == "true"
+
+	charts, err := h.collectionService.ListRateCharts(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch rate charts", err)
+		return
+	}
+
+	resp := make([]dto.CollectionRateChartResponse, 0, len(charts))
+	for i := range charts {
+		resp = append(resp, mapRateChartResponse(&charts[i]))
+	}
+	response.OK(c, "Rate charts retrieved", resp)
+}
+
+// @Summary      Get a rate chart
+// @Description  Returns a rate chart with its slabs
+// @Tags         Collections
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Rate chart ID"
+// @Success      200  {object}  response.Response{data=dto.CollectionRateChartResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /collections/rate-charts/{id} [get]
+func (h *CollectionHandler) GetRateChart(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid rate chart ID")
+		return
+	}
+
+	chart, err := h.collectionService.GetRateChart(c.Request.Context(), id)
+	if err != nil {
+		if err == domainErrors.ErrRateChartNotFound {
+			response.NotFound(c, "Rate chart not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to fetch rate chart", err)
+		return
+	}
+	response.OK(c, "Rate chart retrieved", mapRateChartResponse(chart))
+}
+
+// @Summary      Deactivate a rate chart
+// @Description  Retires a rate chart so it no longer prices new collections; collections already priced keep their rate
+// @Tags         Collections
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Rate chart ID"
+// @Success      200  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /collections/rate-charts/{id} [delete]
+func (h *CollectionHandler) DeactivateRateChart(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid rate chart ID")
+		return
+	}
+
+	if err := h.collectionService.DeactivateRateChart(c.Request.Context(), id); err != nil {
+		if err == domainErrors.ErrRateChartNotFound {
+			response.NotFound(c, "Rate chart not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to deactivate rate chart", err)
+		return
+	}
+	response.OK(c, "Rate chart deactivated", nil)
+}
+
+func mapRateChartResponse(chart *entity.CollectionRateChart) dto.CollectionRateChartResponse {
+	resp := dto.CollectionRateChartResponse{
+		ID:            chart.ID,
+		Name:          chart.Name,
+		VariantID:     chart.VariantID,
+		ZoneID:        chart.ZoneID,
+		SupplierID:    chart.SupplierID,
+		EffectiveFrom: chart.EffectiveFrom.Format("2006-01-02"),
+		IsActive:      chart.IsActive,
+		Slabs:         []dto.CollectionRateSlabResponse{},
+		CreatedAt:     chart.CreatedAt,
+	}
+	for _, s := range chart.Slabs {
+		resp.Slabs = append(resp.Slabs, dto.CollectionRateSlabResponse{
+			ID:            s.ID,
+			MinFatPercent: s.MinFatPercent,
+			MinSNFPercent: s.MinSNFPercent,
+			Rate:          s.Rate,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// CollectionRouteHandler handles collection route and shift sheet API requests
+type CollectionRouteHandler struct {
+	routeService *service.CollectionRouteService
+}
+
+// NewCollectionRouteHandler creates a new collection route handler
+func NewCollectionRouteHandler(routeService *service.CollectionRouteService) *CollectionRouteHandler {
+	return &CollectionRouteHandler{routeService: routeService}
+}
+
+// @Summary      Create a collection route
+// @Description  Plans an agent's route. The stops follow supplier_ids in order or, without them, every supplier of the zone.
+// @Tags         Collection Routes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.CreateCollectionRouteRequest  true  "Route details"
+// @Success      201      {object}  response.Response{data=dto.CollectionRouteResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /collection-routes [post]
+func (h *CollectionRouteHandler) Create(c *gin.Context) {
+	var req dto.CreateCollectionRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	route := &entity.CollectionRoute{
+		Name:        req.Name,
+		AgentID:     req.AgentID,
+		ZoneID:      req.ZoneID,
+		WarehouseID: req.WarehouseID,
+	}
+	if err := h.routeService.CreateRoute(c.Request.Context(), route, req.SupplierIDs); err != nil {
+		h.writeRouteError(c, err, "Failed to create collection route")
+		return
+	}
+
+	route, err := h.routeService.GetRoute(c.Request.Context(), route.ID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch collection route", err)
+		return
+	}
+	response.Created(c, "Collection route created", mapCollectionRouteResponse(route))
+}
+
+// @Summary      List collection routes
+// @Description  Returns collection routes with their stops, optionally only one agent's
+// @Tags         Collection Routes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        agent_id  query  int   false  "Agent (user) ID"
+// @Param        active    query  bool  false  "Only active routes"
+// @Success      200  {object}  response.Response{data=[]dto.CollectionRouteResponse}
+// @Router       /collection-routes [get]
+func (h *CollectionRouteHandler) List(c *gin.Context) {
+	var agentID *int64
+	if aid, err := strconv.ParseInt(c.Query("agent_id"), 10, 64); err == nil {
+		agentID = &aid
+	}
+	h.list(c, agentID, c.Query("active") == "true")
+}
+
+// @Summary      My collection routes
+// @Description  Returns the active routes assigned to the signed-in agent
+// @Tags         Collection Routes
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]dto.CollectionRouteResponse}
+// @Router       /collection-routes/mine [get]
+func (h *CollectionRouteHandler) Mine(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	agentID := userID.(int64)
+	h.list(c, &agentID, true)
+}
+
+func (h *CollectionRouteHandler) list(c *gin.Context, agentID *int64, activeOnly bool) {
+	routes, err := h.routeService.ListRoutes(c.Request.Context(), agentID, activeOnly)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch collection routes", err)
+		return
+	}
+
+	resp := make([]dto.CollectionRouteResponse, 0, len(routes))
+	for i := range routes {
+		resp = append(resp, mapCollectionRouteResponse(&routes[i]))
+	}
+	response.OK(c, "Collection routes retrieved", resp)
+}
+
+// @Summary      Get a collection route
+// @Description  Returns a collection route with its stops in visiting order
+// @Tags         Collection Routes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Route ID"
+// @Success      200  {object}  response.Response{data=dto.CollectionRouteResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /collection-routes/{id} [get]
+func (h *CollectionRouteHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid route ID")
+		return
+	}
+
+	route, err := h.routeService.GetRoute(c.Request.Context(), id)
+	if err != nil {
+		h.writeRouteError(c, err, "Failed to fetch collection route")
+		return
+	}
+	response.OK(c, "Collection route retrieved", mapCollectionRouteResponse(route))
+}
+
+// @Summary      Update a collection route
+// @Description  Updates a route's name, agent, zone, warehouse and active flag; its stops are unchanged
+// @Tags         Collection Routes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                               true  "Route ID"
+// @Param        request  body      dto.UpdateCollectionRouteRequest  true  "Route details"
+// @Success      200      {object}  response.Response{data=dto.CollectionRouteResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /collection-routes/{id} [put]
+func (h *CollectionRouteHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid route ID")
+		return
+	}
+
+	var req dto.UpdateCollectionRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	route, err := h.routeService.GetRoute(c.Request.Context(), id)
+	if err != nil {
+		h.writeRouteError(c, err, "Failed to fetch collection route")
+		return
+	}
+	route.Name = req.Name
+	route.AgentID = req.AgentID
+	route.ZoneID = req.ZoneID
+	route.WarehouseID = req.WarehouseID
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	if err := h.routeService.UpdateRoute(c.Request.Context(), route); err != nil {
+		h.writeRouteError(c, err, "Failed to update collection route")
+		return
+	}
+	response.OK(c, "Collection route updated", mapCollectionRouteResponse(route))
+}
+
+// @Summary      Set route stops
+// @Description  Reorders a route to visit the given suppliers in order; with an empty list the route is rebuilt from the suppliers of its zone
+// @Tags         Collection Routes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                       true  "Route ID"
+// @Param        request  body      dto.SetRouteStopsRequest  true  "Suppliers in visiting order"
+// @Success      200      {object}  response.Response{data=dto.CollectionRouteResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /collection-routes/{id}/stops [put]
+func (h *CollectionRouteHandler) SetStops(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid route ID")
+		return
+	}
+
+	var req dto.SetRouteStopsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	route, err := h.routeService.SetStops(c.Request.Context(), id, req.SupplierIDs)
+	if err != nil {
+		h.writeRouteError(c, err, "Failed to set route stops")
+		return
+	}
+	response.OK(c, "Route stops updated", mapCollectionRouteResponse(route))
+}
+
+// @Summary      Shift sheet
+// @Description  Lists a route's stops in order for one shift with what has been collected from each and who is still pending. Use format=csv for a printable sheet.
+// @Tags         Collection Routes
+// @Produce      json
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        id      path   int     true   "Route ID"
+// @Param        date    query  string  false  "Date (YYYY-MM-DD), defaults to today"
+// @Param        shift   query  string  false  "morning or evening, defaults to the current shift"
+// @Param        format  query  string  false  "json or csv" default(json)
+// @Success      200  {object}  response.Response{data=dto.ShiftSheetResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /collection-routes/{id}/sheet [get]
+func (h *CollectionRouteHandler) ShiftSheet(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid route ID")
+		return
+	}
+
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if d := c.Query("date"); d != "" {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		date = t
+	}
+	shift := entity.ShiftAt(now)
+	if s := c.Query("shift"); s != "" {
+		shift = entity.CollectionShift(s)
+	}
+
+	sheet, err := h.routeService.ShiftSheet(c.Request.Context(), id, date, shift)
+	if err != nil {
+		if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Shift must be morning or evening")
+			return
+		}
+		h.writeRouteError(c, err, "Failed to build shift sheet")
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeShiftSheetCSV(c, sheet)
+		return
+	}
+	response.OK(c, "Shift sheet retrieved", mapShiftSheetResponse(sheet))
+}
+
+func writeShiftSheetCSV(c *gin.Context, sheet *entity.ShiftSheet) {
+	filename := fmt.Sprintf("shift-sheet-%d-%s-%s.csv", sheet.Route.ID, sheet.Date.Format("20060102"), sheet.Shift)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"Route", sheet.Route.Name, sheet.Route.AgentName})
+	_ = w.Write([]string{"Shift", sheet.Date.Format("2006-01-02"), string(sheet.Shift)})
+	_ = w.Write([]string{"Collected", strconv.Itoa(sheet.Collected), "Pending", strconv.Itoa(sheet.Pending)})
+	_ = w.Write([]string{})
+	_ = w.Write([]string{"#", "Supplier", "Phone", "Location", "Status", "Weight", "Fat %", "SNF %", "Amount", "Signature"})
+	for _, row := range sheet.Rows {
+		record := []string{strconv.Itoa(row.Stop.Sequence), "", "", "", "pending", "", "", "", "", ""}
+		if row.Stop.Supplier != nil {
+			record[1], record[2], record[3] = row.Stop.Supplier.Name, row.Stop.Supplier.Phone, row.Stop.Supplier.Location
+		}
+		if row.IsCollected() {
+			record[4] = "collected"
+			record[5] = row.TotalWeight().String()
+			fat, snf, amount := []string{}, []string{}, decimal.Zero
+			for _, col := range row.Collections {
+				if col.FatPercent != nil {
+					fat = append(fat, col.FatPercent.StringFixed(2))
+				}
+				if col.SNFPercent != nil {
+					snf = append(snf, col.SNFPercent.StringFixed(2))
+				}
+				if col.Amount != nil {
+					amount = amount.Add(*col.Amount)
+				}
+			}
+			record[6], record[7], record[8] = strings.Join(fat, " / "), strings.Join(snf, " / "), amount.StringFixed(2)
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+}
+
+func (h *CollectionRouteHandler) writeRouteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domainErrors.ErrCollectionRouteNotFound):
+		response.NotFound(c, "Collection route not found")
+	case errors.Is(err, domainErrors.ErrUserNotFound):
+		response.NotFound(c, "Agent not found")
+	case errors.Is(err, domainErrors.ErrWarehouseNotFound):
+		response.NotFound(c, "Warehouse not found")
+	case errors.Is(err, domainErrors.ErrSupplierNotFound):
+		response.NotFound(c, "Supplier not found")
+	case errors.Is(err, domainErrors.ErrInvalidInput):
+		response.BadRequest(c, "A route needs a name and each supplier at most once")
+	default:
+		response.InternalErrorDebug(c, message, err)
+	}
+}
+
+func mapCollectionRouteStop(stop entity.CollectionRouteStop) dto.CollectionRouteStopResponse {
+	resp := dto.CollectionRouteStopResponse{Sequence: stop.Sequence, SupplierID: stop.SupplierID}
+	if stop.Supplier != nil {
+		resp.SupplierName = stop.Supplier.Name
+		resp.Phone = stop.Supplier.Phone
+		resp.Location = stop.Supplier.Location
+	}
+	return resp
+}
+
+func mapCollectionRouteResponse(route *entity.CollectionRoute) dto.CollectionRouteResponse {
+	resp := dto.CollectionRouteResponse{
+		ID:          route.ID,
+		Name:        route.Name,
+		AgentID:     route.AgentID,
+		AgentName:   route.AgentName,
+		ZoneID:      route.ZoneID,
+		WarehouseID: route.WarehouseID,
+		IsActive:    route.IsActive,
+		Stops:       []dto.CollectionRouteStopResponse{},
+		CreatedAt:   route.CreatedAt,
+		UpdatedAt:   route.UpdatedAt,
+	}
+	for _, stop := range route.Stops {
+		resp.Stops = append(resp.Stops, mapCollectionRouteStop(stop))
+	}
+	return resp
+}
+
+func mapShiftSheetResponse(sheet *entity.ShiftSheet) dto.ShiftSheetResponse {
+	resp := dto.ShiftSheetResponse{
+		RouteID:   sheet.Route.ID,
+		RouteName: sheet.Route.Name,
+		AgentID:   sheet.Route.AgentID,
+		AgentName: sheet.Route.AgentName,
+		Date:      sheet.Date.Format("2006-01-02"),
+		Shift:     string(sheet.Shift),
+		Collected: sheet.Collected,
+		Pending:   sheet.Pending,
+		Rows:      []dto.ShiftSheetRowResponse{},
+	}
+	for i := range sheet.Rows {
+		row := &sheet.Rows[i]
+		r := dto.ShiftSheetRowResponse{
+			CollectionRouteStopResponse: mapCollectionRouteStop(row.Stop),
+			Collected:                   row.IsCollected(),
+			TotalWeight:                 row.TotalWeight(),
+			Collections:                 []dto.CollectionResponse{},
+		}
+		for j := range row.Collections {
+			r.Collections = append(r.Collections, mapCollectionResponse(&row.Collections[j]))
+		}
+		resp.Rows = append(resp.Rows, r)
+	}
+	return resp
+}
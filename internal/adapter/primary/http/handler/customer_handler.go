@@ -295,6 +295,51 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// @Summary      Merge duplicate customers
+// @Description  Folds the source customer into this one. Orders, addresses, subscriptions and the linked storefront account move over and the source is deleted.
+// @Tags         Customers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                       true  "Target customer ID"
+// @Param        request  body      dto.MergeCustomerRequest  true  "Duplicate customer to merge"
+// @Success      200      {object}  response.Response{data=dto.CustomerResponse}
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /customers/{id}/merge [post]
+func (h *CustomerHandler) Merge(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	var req dto.MergeCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	merged, err := h.customerService.Merge(c.Request.Context(), id, req.SourceCustomerID)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Cannot merge a customer into itself")
+		case domainErrors.ErrCustomerNotFound:
+			response.NotFound(c, "Customer not found")
+		case domainErrors.ErrCustomerAlreadyLinked:
+			response.Conflict(c, "Both customers are linked to different storefront accounts")
+		case domainErrors.ErrInvoiceExists:
+			response.Conflict(c, "Both customers are invoiced for the same billing period")
+		default:
+			response.InternalErrorDebug(c, "Failed to merge customers", err)
+		}
+		return
+	}
+
+	response.OK(c, "Customers merged", mapCustomerToResponse(merged))
+}
+
 func mapCustomerToResponse(c *entity.Customer) dto.CustomerResponse {
 	return dto.CustomerResponse{
 		ID:               c.ID,
@@ -312,6 +357,7 @@ func mapCustomerToResponse(c *entity.Customer) dto.CustomerResponse {
 		ZoneName:         c.ZoneName,
 		Latitude:         c.Latitude,
 		Longitude:        c.Longitude,
+		UserID:           c.UserID,
 		CreatedAt:        c.CreatedAt,
 		UpdatedAt:        c.UpdatedAt,
 	}
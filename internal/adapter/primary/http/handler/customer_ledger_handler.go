@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// CustomerLedgerHandler handles customer accounts receivable API requests
+type CustomerLedgerHandler struct {
+	ledgerService *service.CustomerLedgerService
+}
+
+// NewCustomerLedgerHandler creates a new customer ledger handler
+func NewCustomerLedgerHandler(ledgerService *service.CustomerLedgerService) *CustomerLedgerHandler {
+	return &CustomerLedgerHandler{ledgerService: ledgerService}
+}
+
+// RecordPayment records a payment received from a customer
+// @Summary      Record customer payment
+// @Description  Records money received from a customer and credits their ledger
+// @Tags         Customers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                               true  "Customer ID"
+// @Param        request  body      dto.RecordCustomerPaymentRequest  true  "Payment details"
+// @Success      201      {object}  response.Response{data=dto.CustomerPaymentResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /customers/{id}/payments [post]
+func (h *CustomerLedgerHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	var req dto.RecordCustomerPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	payment := &entity.CustomerPayment{
+		CustomerID:    id,
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		Reference:     req.Reference,
+		Notes:         req.Notes,
+	}
+	if req.ReceivedAt != "" {
+		t, err := time.Parse("2006-01-02", req.ReceivedAt)
+		if err != nil {
+			response.BadRequest(c, "Invalid received_at format, expected YYYY-MM-DD")
+			return
+		}
+		payment.ReceivedAt = t
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		recordedBy := userID.(int64)
+		payment.RecordedByUserID = &recordedBy
+	}
+
+	if err := h.ledgerService.RecordPayment(c.Request.Context(), payment); err != nil {
+		switch err {
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Payment amount must be greater than zero")
+		case domainErrors.ErrCustomerNotFound:
+			response.NotFound(c, "Customer not found")
+		default:
+			response.InternalErrorDebug(c, "Failed to record payment", err)
+		}
+		return
+	}
+
+	response.Created(c, "Payment recorded", dto.CustomerPaymentResponse{
+		ID:            payment.ID,
+		CustomerID:    payment.CustomerID,
+		Amount:        payment.Amount,
+		PaymentMethod: payment.PaymentMethod,
+		Reference:     payment.Reference,
+		Notes:         payment.Notes,
+		ReceivedAt:    payment.ReceivedAt,
+		CreatedAt:     payment.CreatedAt,
+	})
+}
+
+// Ledger returns a customer's ledger with running balances for a date range
+// @Summary      Customer ledger
+// @Description  Returns the customer's ledger entries in the date range with opening, running and closing balances
+// @Tags         Customers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Customer ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the first of the month"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.CustomerStatementResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /customers/{id}/ledger [get]
+func (h *CustomerLedgerHandler) Ledger(c *gin.Context) {
+	statement, ok := h.loadStatement(c)
+	if !ok {
+		return
+	}
+	response.OK(c, "Customer ledger retrieved", mapCustomerStatementResponse(statement))
+}
+
+// Statement downloads a customer's statement for a date range as CSV
+// @Summary      Download customer statement
+// @Description  Downloads the customer's statement for the date range as a CSV file
+// @Tags         Customers
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Customer ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the first of the month"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {file}    file
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /customers/{id}/statement [get]
+func (h *CustomerLedgerHandler) Statement(c *gin.Context) {
+	statement, ok := h.loadStatement(c)
+	if !ok {
+		return
+	}
+
+	lastDay := statement.To.AddDate(0, 0, -1)
+	filename := fmt.Sprintf("statement-%d-%s-%s.csv", statement.Customer.ID, statement.From.Format("20060102"), lastDay.Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"Customer", statement.Customer.Name, statement.Customer.Phone})
+	_ = w.Write([]string{"Period", statement.From.Format("2006-01-02"), lastDay.Format("2006-01-02")})
+	_ = w.Write([]string{})
+	_ = w.Write([]string{"Date", "Type", "Description", "Due Date", "Debit", "Credit", "Balance"})
+	_ = w.Write([]string{statement.From.Format("2006-01-02"), "", "Opening balance", "", "", "", statement.OpeningBalance.StringFixed(2)})
+	for _, e := range statement.Entries {
+		dueDate := ""
+		if e.DueDate != nil {
+			dueDate = e.DueDate.Format("2006-01-02")
+		}
+		_ = w.Write([]string{
+			e.EntryDate.Format("2006-01-02"),
+			string(e.EntryType),
+			e.Description,
+			dueDate,
+			e.Debit.StringFixed(2),
+			e.Credit.StringFixed(2),
+			e.Balance.StringFixed(2),
+		})
+	}
+	_ = w.Write([]string{"", "", "Total", "", statement.TotalDebit.StringFixed(2), statement.TotalCredit.StringFixed(2), ""})
+	_ = w.Write([]string{lastDay.Format("2006-01-02"), "", "Closing balance", "", "", "", statement.ClosingBalance.StringFixed(2)})
+	w.Flush()
+}
+
+// CustomerAging returns a customer's outstanding balance split into age buckets
+// @Summary      Customer aging
+// @Description  Returns the customer's unpaid balance split into 0-15, 16-30, 31-60 and 60+ days past due (from each debit's due date, or its entry date without one)
+// @Tags         Customers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path   int     true   "Customer ID"
+// @Param        as_of  query  string  false  "Aging date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.CustomerAgingResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /customers/{id}/aging [get]
+func (h *CustomerLedgerHandler) CustomerAging(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	aging, err := h.ledgerService.CustomerAging(c.Request.Context(), id, asOf)
+	if err != nil {
+		if err == domainErrors.ErrCustomerNotFound {
+			response.NotFound(c, "Customer not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to build customer aging", err)
+		return
+	}
+
+	response.OK(c, "Customer aging retrieved", mapCustomerAgingResponse(aging))
+}
+
+// Aging returns the accounts receivable aging report for all customers
+// @Summary      Accounts receivable aging
+// @Description  Returns every customer with an outstanding balance split into 0-15, 16-30, 31-60 and 60+ days past due (from each debit's due date, or its entry date without one)
+// @Tags         Customers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of  query  string  false  "Aging date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.CustomerAgingResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /customers/aging [get]
+func (h *CustomerLedgerHandler) Aging(c *gin.Context) {
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.ledgerService.Aging(c.Request.Context(), asOf)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to build aging report", err)
+		return
+	}
+
+	resp := []dto.CustomerAgingResponse{}
+	for i := range report {
+		resp = append(resp, mapCustomerAgingResponse(&report[i]))
+	}
+	response.OK(c, "Aging report retrieved", resp)
+}
+
+// loadStatement parses the customer and date range and builds the statement,
+// writing an error response when it fails
+func (h *CustomerLedgerHandler) loadStatement(c *gin.Context) (*entity.CustomerStatement, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return nil, false
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return nil, false
+		}
+		from = t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return nil, false
+		}
+		to = t
+	}
+
+	statement, err := h.ledgerService.GetStatement(c.Request.Context(), id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		switch err {
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "start_date must not be after end_date")
+		case domainErrors.ErrCustomerNotFound:
+			response.NotFound(c, "Customer not found")
+		default:
+			response.InternalErrorDebug(c, "Failed to build customer statement", err)
+		}
+		return nil, false
+	}
+	return statement, true
+}
+
+// parseAsOf reads the as_of date, returning the start of the following day so
+// entries dated on as_of are included
+func parseAsOf(c *gin.Context) (time.Time, bool) {
+	now := time.Now()
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if s := c.Query("as_of"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			response.BadRequest(c, "Invalid as_of format, expected YYYY-MM-DD")
+			return time.Time{}, false
+		}
+		asOf = t
+	}
+	return asOf.AddDate(0, 0, 1), true
+}
+
+func mapCustomerStatementResponse(s *entity.CustomerStatement) dto.CustomerStatementResponse {
+	resp := dto.CustomerStatementResponse{
+		CustomerID:     s.Customer.ID,
+		CustomerName:   s.Customer.Name,
+		StartDate:      s.From.Format("2006-01-02"),
+		EndDate:        s.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		OpeningBalance: s.OpeningBalance,
+		TotalDebit:     s.TotalDebit,
+		TotalCredit:    s.TotalCredit,
+		ClosingBalance: s.ClosingBalance,
+		Entries:        []dto.CustomerLedgerEntryResponse{},
+	}
+	for _, e := range s.Entries {
+		resp.Entries = append(resp.Entries, dto.CustomerLedgerEntryResponse{
+			ID:          e.ID,
+			EntryType:   string(e.EntryType),
+			SaleID:      e.SaleID,
+			InvoiceID:   e.InvoiceID,
+			PaymentID:   e.PaymentID,
+			Description: e.Description,
+			EntryDate:   e.EntryDate,
+			DueDate:     e.DueDate,
+			Debit:       e.Debit,
+			Credit:      e.Credit,
+			Balance:     e.Balance,
+		})
+	}
+	return resp
+}
+
+func mapCustomerAgingResponse(a *entity.CustomerAging) dto.CustomerAgingResponse {
+	return dto.CustomerAgingResponse{
+		CustomerID:   a.CustomerID,
+		CustomerName: a.CustomerName,
+		Balance:      a.Balance,
+		Days0To15:    a.Days0To15,
+		Days16To30:   a.Days16To30,
+		Days31To60:   a.Days31To60,
+		Over60:       a.Over60,
+	}
+}
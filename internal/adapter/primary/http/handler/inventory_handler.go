@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 
 	"github.com/qwikshelf/api/internal/adapter/primary/http/middleware"
 	"github.com/qwikshelf/api/internal/application/dto"
 	"github.com/qwikshelf/api/internal/application/service"
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
 	"github.com/qwikshelf/api/pkg/response"
 )
 
@@ -132,13 +138,14 @@ func (h *InventoryHandler) ListByWarehouse(c *gin.Context) {
 }
 
 // @Summary      Adjust inventory
-// @Description  Adjusts stock level for a product variant in a warehouse
+// @Description  Adjusts stock level for a product variant in a warehouse with a reason code. Adjustments worth more than the approval threshold (quantity × cost price) are held pending approval and answered with 202.
 // @Tags         Inventory
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request  body      dto.AdjustInventoryRequest  true  "Adjustment details"
-// @Success      200      {object}  response.Response{data=dto.InventoryLevelResponse}
+// @Success      200      {object}  response.Response{data=dto.InventoryAdjustmentResponse}
+// @Success      202      {object}  response.Response{data=dto.InventoryAdjustmentResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      404      {object}  response.Response
 // @Router       /inventory/adjust [post]
@@ -148,23 +155,252 @@ func (h *InventoryHandler) Adjust(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
-	level, err := h.inventoryService.Adjust(c.Request.Context(), req.WarehouseID, req.VariantID, req.QuantityDelta)
+	adjustment := &entity.InventoryAdjustment{
+		WarehouseID:   req.WarehouseID,
+		VariantID:     req.VariantID,
+		ReasonCode:    entity.AdjustmentReason(req.ReasonCode),
+		QuantityDelta: req.QuantityDelta,
+	}
+	if req.Reason != "" {
+		adjustment.Notes = &req.Reason
+	}
+	if uid := middleware.GetUserID(c); uid != 0 {
+		adjustment.RequestedByUserID = &uid
+	}
+
+	level, err := h.inventoryService.Adjust(c.Request.Context(), adjustment)
 	if err != nil {
 		switch err {
 		case domainErrors.ErrWarehouseNotFound:
 			response.NotFound(c, "Warehouse not found")
 		case domainErrors.ErrProductVariantNotFound:
 			response.NotFound(c, "Product variant not found")
+		case domainErrors.ErrInvalidQuantity:
+			response.BadRequest(c, "Quantity delta must not be zero")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Invalid reason code")
 		default:
 			response.InternalErrorDebug(c, "Failed to adjust inventory", err)
 		}
 		return
 	}
-	response.OK(c, "Inventory adjusted", dto.InventoryLevelResponse{ID: level.ID, WarehouseID: level.WarehouseID, VariantID: level.VariantID, Quantity: level.Quantity, BatchNumber: level.BatchNumber, ExpiryDate: level.ExpiryDate})
+
+	resp := mapAdjustmentResponse(adjustment)
+	if level == nil {
+		response.Success(c, http.StatusAccepted, "Adjustment awaiting approval", resp)
+		return
+	}
+	resp.Level = &dto.InventoryLevelResponse{ID: level.ID, WarehouseID: level.WarehouseID, VariantID: level.VariantID, Quantity: level.Quantity, BatchNumber: level.BatchNumber, ExpiryDate: level.ExpiryDate}
+	response.OK(c, "Inventory adjusted", resp)
+}
+
+// @Summary      List inventory adjustments
+// @Description  Returns manual adjustments, optionally filtered by warehouse, status and reason
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Param        status        query  string  false  "pending, approved or rejected"
+// @Param        reason_code   query  string  false  "damage, spoilage, theft, count_correction or sample"
+// @Param        page          query  int     false  "Page number (default 1)"
+// @Param        limit         query  int     false  "Items per page (default 20)"
+// @Success      200  {object}  response.Response{data=[]dto.InventoryAdjustmentResponse}
+// @Router       /inventory/adjustments [get]
+func (h *InventoryHandler) ListAdjustments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var filter repository.InventoryAdjustmentFilter
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &wid
+	}
+	switch st := entity.AdjustmentStatus(c.Query("status")); st {
+	case entity.AdjustmentStatusPending, entity.AdjustmentStatusApproved, entity.AdjustmentStatusRejected:
+		filter.Status = &st
+	}
+	if reason := entity.AdjustmentReason(c.Query("reason_code")); reason.IsValid() {
+		filter.ReasonCode = &reason
+	}
+
+	adjustments, total, err := h.inventoryService.ListAdjustments(c.Request.Context(), filter, (page-1)*limit, limit)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch adjustments", err)
+		return
+	}
+
+	resp := []dto.InventoryAdjustmentResponse{}
+	for i := range adjustments {
+		resp = append(resp, mapAdjustmentResponse(&adjustments[i]))
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Inventory adjustments retrieved", resp, &response.Meta{Page: page, PerPage: limit, Total: total, TotalPages: totalPages})
+}
+
+// @Summary      Approve inventory adjustment
+// @Description  Applies a pending adjustment to stock. The approver must be a different user from the requester.
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true   "Adjustment ID"
+// @Param        request  body      dto.ReviewAdjustmentRequest  false  "Review note"
+// @Success      200      {object}  response.Response{data=dto.InventoryAdjustmentResponse}
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /inventory/adjustments/{id}/approve [post]
+func (h *InventoryHandler) ApproveAdjustment(c *gin.Context) {
+	h.reviewAdjustment(c, h.inventoryService.ApproveAdjustment, "Adjustment approved")
+}
+
+// @Summary      Reject inventory adjustment
+// @Description  Discards a pending adjustment without changing stock
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true   "Adjustment ID"
+// @Param        request  body      dto.ReviewAdjustmentRequest  false  "Review note"
+// @Success      200      {object}  response.Response{data=dto.InventoryAdjustmentResponse}
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /inventory/adjustments/{id}/reject [post]
+func (h *InventoryHandler) RejectAdjustment(c *gin.Context) {
+	h.reviewAdjustment(c, h.inventoryService.RejectAdjustment, "Adjustment rejected")
+}
+
+func (h *InventoryHandler) reviewAdjustment(
+	c *gin.Context,
+	review func(ctx context.Context, id, approverID int64, note *string) (*entity.InventoryAdjustment, error),
+	message string,
+) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid adjustment ID")
+		return
+	}
+	var req dto.ReviewAdjustmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	adjustment, err := review(c.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrAdjustmentNotFound:
+			response.NotFound(c, "Adjustment not found")
+		case domainErrors.ErrAdjustmentNotPending:
+			response.Conflict(c, "Adjustment is not pending approval")
+		case domainErrors.ErrSelfApproval:
+			response.Forbidden(c, "You cannot review your own adjustment")
+		default:
+			response.InternalErrorDebug(c, "Failed to review adjustment", err)
+		}
+		return
+	}
+	response.OK(c, message, mapAdjustmentResponse(adjustment))
+}
+
+// @Summary      Shrinkage report
+// @Description  Returns the value of stock written off by approved adjustments, by warehouse and reason
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from          query  string  false  "Start date (YYYY-MM-DD), defaults to the first of this month"
+// @Param        to            query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=dto.ShrinkageReportResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /inventory/shrinkage [get]
+func (h *InventoryHandler) Shrinkage(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var err error
+	if f := c.Query("from"); f != "" {
+		if from, err = time.Parse("2006-01-02", f); err != nil {
+			response.BadRequest(c, "Invalid from format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	if t := c.Query("to"); t != "" {
+		if to, err = time.Parse("2006-01-02", t); err != nil {
+			response.BadRequest(c, "Invalid to format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	var warehouseID *int64
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		warehouseID = &wid
+	}
+
+	rows, err := h.inventoryService.Shrinkage(c.Request.Context(), from, to.AddDate(0, 0, 1), warehouseID)
+	if err != nil {
+		if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "from must not be after to")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to build shrinkage report", err)
+		return
+	}
+
+	resp := dto.ShrinkageReportResponse{From: from, To: to, TotalValue: decimal.Zero, Rows: []dto.ShrinkageSummaryResponse{}}
+	for _, r := range rows {
+		resp.Rows = append(resp.Rows, dto.ShrinkageSummaryResponse{
+			WarehouseID:     r.WarehouseID,
+			WarehouseName:   r.WarehouseName,
+			ReasonCode:      r.ReasonCode,
+			AdjustmentCount: r.AdjustmentCount,
+			Quantity:        r.Quantity,
+			Value:           r.Value,
+		})
+		resp.TotalValue = resp.TotalValue.Add(r.Value)
+	}
+	response.OK(c, "Shrinkage report retrieved", resp)
+}
+
+func mapAdjustmentResponse(a *entity.InventoryAdjustment) dto.InventoryAdjustmentResponse {
+	return dto.InventoryAdjustmentResponse{
+		ID:                a.ID,
+		WarehouseID:       a.WarehouseID,
+		WarehouseName:     a.WarehouseName,
+		VariantID:         a.VariantID,
+		VariantName:       a.VariantName,
+		ReasonCode:        a.ReasonCode,
+		QuantityDelta:     a.QuantityDelta,
+		UnitCost:          a.UnitCost,
+		Value:             a.Value,
+		Notes:             a.Notes,
+		Status:            a.Status,
+		RequestedByUserID: a.RequestedByUserID,
+		ReviewedByUserID:  a.ReviewedByUserID,
+		ReviewedAt:        a.ReviewedAt,
+		ReviewNote:        a.ReviewNote,
+		CreatedAt:         a.CreatedAt,
+	}
 }
 
 // @Summary      Transfer inventory
-// @Description  Creates an inventory transfer between warehouses
+// @Description  Creates an inventory transfer between warehouses. With dispatch set the stock leaves the source straight away and is in transit until received; otherwise the transfer waits as pending.
 // @Tags         Inventory
 // @Accept       json
 // @Produce      json
@@ -175,6 +411,25 @@ func (h *InventoryHandler) Adjust(c *gin.Context) {
 // @Failure      404      {object}  response.Response
 // @Router       /inventory/transfer [post]
 func (h *InventoryHandler) Transfer(c *gin.Context) {
+	h.createTransfer(c, entity.TransferStatusPending)
+}
+
+// @Summary      Request a transfer
+// @Description  Lets a receiving store ask another warehouse for stock. The source warehouse dispatches the request or cancels it.
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.CreateTransferRequest  true  "Requested stock; dispatch is ignored"
+// @Success      201      {object}  response.Response{data=dto.TransferResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /inventory/transfers/requests [post]
+func (h *InventoryHandler) RequestTransfer(c *gin.Context) {
+	h.createTransfer(c, entity.TransferStatusRequested)
+}
+
+func (h *InventoryHandler) createTransfer(c *gin.Context, status entity.TransferStatus) {
 	var req dto.CreateTransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "Invalid request body")
@@ -185,35 +440,716 @@ func (h *InventoryHandler) Transfer(c *gin.Context) {
 		response.Unauthorized(c, "Not authenticated")
 		return
 	}
-	var items []entity.InventoryTransferItem
+	transfer := &entity.InventoryTransfer{
+		SourceWarehouseID:      req.SourceWarehouseID,
+		DestinationWarehouseID: req.DestinationWarehouseID,
+		AuthorizedByUserID:     userID,
+		Status:                 status,
+		Notes:                  req.Notes,
+	}
+	if status == entity.TransferStatusRequested {
+		transfer.RequestedByUserID = &userID
+	}
+	for _, item := range req.Items {
+		transfer.Items = append(transfer.Items, entity.InventoryTransferItem{VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+
+	var err error
+	if status == entity.TransferStatusPending && req.Dispatch {
+		err = h.inventoryService.Transfer(c.Request.Context(), transfer)
+	} else {
+		err = h.inventoryService.CreateTransfer(c.Request.Context(), transfer)
+	}
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to create transfer")
+		return
+	}
+	response.Created(c, "Transfer created", mapTransferResponse(transfer))
+}
+
+// @Summary      List transfers
+// @Description  Lists inventory transfers, newest first
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page                      query  int     false  "Page number"
+// @Param        per_page                  query  int     false  "Items per page"
+// @Param        status                    query  string  false  "requested, pending, in_transit, completed or cancelled"
+// @Param        warehouse_id              query  int     false  "Transfers from or to this warehouse"
+// @Param        source_warehouse_id       query  int     false  "Source warehouse ID"
+// @Param        destination_warehouse_id  query  int     false  "Destination warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.TransferResponse}
+// @Router       /inventory/transfers [get]
+func (h *InventoryHandler) ListTransfers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var filter repository.TransferFilter
+	switch st := entity.TransferStatus(c.Query("status")); st {
+	case entity.TransferStatusRequested, entity.TransferStatusPending, entity.TransferStatusInTransit,
+		entity.TransferStatusCompleted, entity.TransferStatusCancelled:
+		filter.Status = &st
+	}
+	if id, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &id
+	}
+	if id, err := strconv.ParseInt(c.Query("source_warehouse_id"), 10, 64); err == nil {
+		filter.SourceWarehouseID = &id
+	}
+	if id, err := strconv.ParseInt(c.Query("destination_warehouse_id"), 10, 64); err == nil {
+		filter.DestinationWarehouseID = &id
+	}
+
+	transfers, total, err := h.inventoryService.ListTransfers(c.Request.Context(), filter, (page-1)*perPage, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch transfers", err)
+		return
+	}
+
+	resp := []dto.TransferResponse{}
+	for i := range transfers {
+		resp = append(resp, mapTransferResponse(&transfers[i]))
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Transfers retrieved", resp, &response.Meta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages})
+}
+
+// @Summary      Get a transfer
+// @Description  Returns a transfer with the quantities asked for, dispatched, received, damaged and short per item
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Transfer ID"
+// @Success      200  {object}  response.Response{data=dto.TransferResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /inventory/transfers/{id} [get]
+func (h *InventoryHandler) GetTransfer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID")
+		return
+	}
+	transfer, err := h.inventoryService.GetTransfer(c.Request.Context(), id)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to fetch transfer")
+		return
+	}
+	response.OK(c, "Transfer retrieved", mapTransferResponse(transfer))
+}
+
+// @Summary      Dispatch a transfer
+// @Description  Takes the stock of a requested or pending transfer out of the source warehouse, first-expiry-first-out, and puts it in transit. Items can be sent short; those left out are sent in full.
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                          true   "Transfer ID"
+// @Param        request  body  dto.DispatchTransferRequest  false  "Quantities actually sent"
+// @Success      200  {object}  response.Response{data=dto.TransferResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /inventory/transfers/{id}/dispatch [post]
+func (h *InventoryHandler) DispatchTransfer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID")
+		return
+	}
+	var req dto.DispatchTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	quantities := make(map[int64]decimal.Decimal, len(req.Items))
+	for _, item := range req.Items {
+		quantities[item.ItemID] = item.Quantity
+	}
+	transfer, err := h.inventoryService.DispatchTransfer(c.Request.Context(), id, userID, quantities)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to dispatch transfer")
+		return
+	}
+	response.OK(c, "Transfer dispatched", mapTransferResponse(transfer))
+}
+
+// @Summary      Receive a transfer
+// @Description  Books an in-transit transfer into the destination warehouse and completes it, recording damaged and short quantities. Items left out are received in full.
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                         true   "Transfer ID"
+// @Param        request  body  dto.ReceiveTransferRequest  false  "Quantities received"
+// @Success      200  {object}  response.Response{data=dto.TransferResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /inventory/transfers/{id}/receive [post]
+func (h *InventoryHandler) ReceiveTransfer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID")
+		return
+	}
+	var req dto.ReceiveTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	receipts := make([]entity.TransferReceipt, 0, len(req.Items))
 	for _, item := range req.Items {
-		items = append(items, entity.InventoryTransferItem{VariantID: item.VariantID, Quantity: item.Quantity})
+		receipts = append(receipts, entity.TransferReceipt{
+			ItemID:           item.ItemID,
+			ReceivedQuantity: item.ReceivedQuantity,
+			DamagedQuantity:  item.DamagedQuantity,
+			Notes:            item.Notes,
+		})
 	}
-	transfer, err := h.inventoryService.Transfer(c.Request.Context(), req.SourceWarehouseID, req.DestinationWarehouseID, userID, items)
+	transfer, err := h.inventoryService.ReceiveTransfer(c.Request.Context(), id, userID, receipts)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to receive transfer")
+		return
+	}
+	response.OK(c, "Transfer received", mapTransferResponse(transfer))
+}
+
+// @Summary      Cancel a transfer
+// @Description  Cancels a transfer that has not been received; stock already dispatched goes back to the source warehouse
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Transfer ID"
+// @Success      200  {object}  response.Response{data=dto.TransferResponse}
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /inventory/transfers/{id}/cancel [post]
+func (h *InventoryHandler) CancelTransfer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID")
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+	transfer, err := h.inventoryService.CancelTransfer(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to cancel transfer")
+		return
+	}
+	response.OK(c, "Transfer cancelled", mapTransferResponse(transfer))
+}
+
+// @Summary      In-transit stock
+// @Description  Sums the stock dispatched and not yet received per destination warehouse and variant
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int  false  "Destination warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.InTransitStockResponse}
+// @Router       /inventory/transfers/in-transit [get]
+func (h *InventoryHandler) InTransit(c *gin.Context) {
+	var warehouseID *int64
+	if w := c.Query("warehouse_id"); w != "" {
+		id, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid warehouse ID")
+			return
+		}
+		warehouseID = &id
+	}
+
+	stock, err := h.inventoryService.InTransit(c.Request.Context(), warehouseID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch in-transit stock", err)
+		return
+	}
+	resp := make([]dto.InTransitStockResponse, len(stock))
+	for i, s := range stock {
+		resp[i] = dto.InTransitStockResponse{
+			DestinationWarehouseID: s.DestinationWarehouseID,
+			VariantID:              s.VariantID,
+			VariantName:            s.VariantName,
+			Quantity:               s.Quantity,
+			Transfers:              s.Transfers,
+		}
+	}
+	response.OK(c, "In-transit stock retrieved", resp)
+}
+
+func (h *InventoryHandler) handleTransferError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, domainErrors.ErrTransferNotFound):
+		response.NotFound(c, "Transfer not found")
+	case errors.Is(err, domainErrors.ErrTransferStatus):
+		response.Conflict(c, "Transfer cannot be changed in its current status")
+	case errors.Is(err, domainErrors.ErrSameWarehouse):
+		response.BadRequest(c, "Source and destination warehouse cannot be the same")
+	case errors.Is(err, domainErrors.ErrWarehouseNotFound):
+		response.NotFound(c, "Warehouse not found")
+	case errors.Is(err, domainErrors.ErrProductVariantNotFound):
+		response.NotFound(c, "Product variant not found")
+	case errors.Is(err, domainErrors.ErrInsufficientStock):
+		response.BadRequest(c, "Insufficient stock for transfer")
+	case errors.Is(err, domainErrors.ErrStockExpired):
+		response.BadRequest(c, "Only expired stock is available for one or more items")
+	case errors.Is(err, domainErrors.ErrStockReserved):
+		response.BadRequest(c, "Stock for one or more items is reserved for orders and subscriptions")
+	case errors.Is(err, domainErrors.ErrInvalidQuantity):
+		response.BadRequest(c, "Invalid quantity")
+	case errors.Is(err, domainErrors.ErrInvalidInput):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalErrorDebug(c, msg, err)
+	}
+}
+
+func mapTransferResponse(t *entity.InventoryTransfer) dto.TransferResponse {
+	resp := dto.TransferResponse{
+		ID: t.ID, SourceWarehouseID: t.SourceWarehouseID,
+		DestinationWarehouseID: t.DestinationWarehouseID, AuthorizedByUserID: t.AuthorizedByUserID,
+		TransferredAt: t.TransferredAt, Status: t.Status, Notes: t.Notes,
+		RequestedByUserID:  t.RequestedByUserID,
+		DispatchedByUserID: t.DispatchedByUserID, DispatchedAt: t.DispatchedAt,
+		ReceivedByUserID: t.ReceivedByUserID, ReceivedAt: t.ReceivedAt,
+		CancelledByUserID: t.CancelledByUserID, CancelledAt: t.CancelledAt,
+	}
+	for i := range t.Items {
+		item := &t.Items[i]
+		itemResp := dto.TransferItemResponse{
+			ID: item.ID, VariantID: item.VariantID, Quantity: item.Quantity,
+			DispatchedQuantity: item.DispatchedQuantity, ReceivedQuantity: item.ReceivedQuantity,
+			DamagedQuantity: item.DamagedQuantity, Notes: item.Notes,
+		}
+		if item.Variant != nil {
+			itemResp.Variant = &dto.ProductVariantResponse{ID: item.Variant.ID, Name: item.Variant.Name, SKU: item.Variant.SKU}
+		}
+		if item.ReceivedQuantity != nil {
+			short := item.ShortQuantity()
+			itemResp.ShortQuantity = &short
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+	return resp
+}
+
+// @Summary      Stock card
+// @Description  Returns the stock ledger of a product variant at a warehouse over a date range, with opening and closing balances
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int     true   "Warehouse ID"
+// @Param        variant_id    query  int     true   "Product variant ID"
+// @Param        from          query  string  false  "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param        to            query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.StockCardResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /inventory/stock-card [get]
+func (h *InventoryHandler) StockCard(c *gin.Context) {
+	warehouseID, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid warehouse ID")
+		return
+	}
+	variantID, err := strconv.ParseInt(c.Query("variant_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -30)
+	to := today
+	if f := c.Query("from"); f != "" {
+		if from, err = time.Parse("2006-01-02", f); err != nil {
+			response.BadRequest(c, "Invalid from format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	if t := c.Query("to"); t != "" {
+		if to, err = time.Parse("2006-01-02", t); err != nil {
+			response.BadRequest(c, "Invalid to format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	card, err := h.inventoryService.StockCard(c.Request.Context(), warehouseID, variantID, from, to.AddDate(0, 0, 1))
 	if err != nil {
 		switch err {
-		case domainErrors.ErrSameWarehouse:
-			response.BadRequest(c, "Source and destination warehouse cannot be the same")
 		case domainErrors.ErrWarehouseNotFound:
 			response.NotFound(c, "Warehouse not found")
 		case domainErrors.ErrProductVariantNotFound:
 			response.NotFound(c, "Product variant not found")
-		case domainErrors.ErrInsufficientStock:
-			response.BadRequest(c, "Insufficient stock for transfer")
-		case domainErrors.ErrInvalidQuantity:
-			response.BadRequest(c, "Invalid quantity")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "from must not be after to")
 		default:
-			response.InternalErrorDebug(c, "Failed to create transfer", err)
+			response.InternalErrorDebug(c, "Failed to fetch stock card", err)
 		}
 		return
 	}
-	resp := dto.TransferResponse{
-		ID: transfer.ID, SourceWarehouseID: transfer.SourceWarehouseID,
-		DestinationWarehouseID: transfer.DestinationWarehouseID, AuthorizedByUserID: transfer.AuthorizedByUserID,
-		TransferredAt: transfer.TransferredAt, Status: transfer.Status,
+
+	resp := dto.StockCardResponse{
+		WarehouseID:    card.WarehouseID,
+		VariantID:      card.VariantID,
+		From:           card.From,
+		To:             card.To,
+		OpeningBalance: card.OpeningBalance,
+		TotalIn:        card.TotalIn,
+		TotalOut:       card.TotalOut,
+		ClosingBalance: card.ClosingBalance,
+		Movements:      []dto.InventoryMovementResponse{},
+	}
+	for _, m := range card.Movements {
+		resp.Movements = append(resp.Movements, dto.InventoryMovementResponse{
+			ID:            m.ID,
+			SourceType:    m.SourceType,
+			ReferenceID:   m.ReferenceID,
+			LotID:         m.LotID,
+			UserID:        m.UserID,
+			QuantityDelta: m.QuantityDelta,
+			BalanceAfter:  m.BalanceAfter,
+			UnitCost:      m.UnitCost,
+			TotalCost:     m.TotalCost,
+			ValueAfter:    m.ValueAfter,
+			Notes:         m.Notes,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	response.OK(c, "Stock card retrieved", resp)
+}
+
+// @Summary      Inventory valuation
+// @Description  Values the stock held at the end of a day (default today) per warehouse and variant at its cost under the configured costing method (FIFO or moving average). Stock recorded before costing is valued at cost price.
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of         query  string  false  "Date (YYYY-MM-DD), defaults to today"
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=dto.InventoryValuationResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /inventory/valuation [get]
+func (h *InventoryHandler) Valuation(c *gin.Context) {
+	var warehouseID *int64
+	if w := c.Query("warehouse_id"); w != "" {
+		id, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid warehouse ID")
+			return
+		}
+		warehouseID = &id
+	}
+	asOf := time.Now()
+	if d := c.Query("as_of"); d != "" {
+		var err error
+		if asOf, err = time.Parse("2006-01-02", d); err != nil {
+			response.BadRequest(c, "Invalid as_of format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	valuation, err := h.inventoryService.Valuation(c.Request.Context(), asOf, warehouseID)
+	if err != nil {
+		if err == domainErrors.ErrWarehouseNotFound {
+			response.NotFound(c, "Warehouse not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to value inventory", err)
+		return
+	}
+
+	resp := dto.InventoryValuationResponse{
+		AsOf:       valuation.AsOf.Format("2006-01-02"),
+		Method:     valuation.Method,
+		TotalValue: valuation.TotalValue,
+		Lines:      make([]dto.InventoryValuationLineResponse, len(valuation.Lines)),
+	}
+	for i := range valuation.Lines {
+		l := &valuation.Lines[i]
+		resp.Lines[i] = dto.InventoryValuationLineResponse{
+			WarehouseID:   l.WarehouseID,
+			WarehouseName: l.WarehouseName,
+			VariantID:     l.VariantID,
+			VariantName:   l.VariantName,
+			SKU:           l.SKU,
+			Quantity:      l.Quantity,
+			UnitCost:      l.UnitCost(),
+			Value:         l.Value,
+		}
+	}
+	response.OK(c, "Inventory valuation retrieved", resp)
+}
+
+// @Summary      List cost layers
+// @Description  Lists stock by the unit cost it was received at, oldest first. Layers whose stock has all been issued are left out unless include_consumed is set.
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id      query  int   false  "Warehouse ID"
+// @Param        variant_id        query  int   false  "Variant ID"
+// @Param        include_consumed  query  bool  false  "Include layers with no stock left"
+// @Success      200  {object}  response.Response{data=[]dto.CostLayerResponse}
+// @Router       /inventory/cost-layers [get]
+func (h *InventoryHandler) ListCostLayers(c *gin.Context) {
+	var filter repository.CostLayerFilter
+	if w := c.Query("warehouse_id"); w != "" {
+		id, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid warehouse ID")
+			return
+		}
+		filter.WarehouseID = &id
+	}
+	if v := c.Query("variant_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid variant ID")
+			return
+		}
+		filter.VariantID = &id
+	}
+	filter.IncludeConsumed = c.Query("include_consumed") == "true"
+
+	layers, err := h.inventoryService.ListCostLayers(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch cost layers", err)
+		return
+	}
+
+	resp := make([]dto.CostLayerResponse, len(layers))
+	for i := range layers {
+		l := &layers[i]
+		resp[i] = dto.CostLayerResponse{
+			ID:                l.ID,
+			WarehouseID:       l.WarehouseID,
+			VariantID:         l.VariantID,
+			VariantName:       l.VariantName,
+			MovementID:        l.MovementID,
+			SourceType:        l.SourceType,
+			UnitCost:          l.UnitCost,
+			Quantity:          l.Quantity,
+			RemainingQuantity: l.RemainingQuantity,
+			ReceivedAt:        l.ReceivedAt,
+		}
+	}
+	response.OK(c, "Cost layers retrieved", resp)
+}
+
+// @Summary      List inventory lots
+// @Description  Lists stock by batch, first-expiring first. Empty lots are left out unless include_empty is set.
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id          query  int     false  "Warehouse ID"
+// @Param        variant_id            query  int     false  "Variant ID"
+// @Param        batch_number          query  string  false  "Batch number"
+// @Param        expiring_within_days  query  int     false  "Only lots expiring within this many days, expired ones included"
+// @Param        include_empty         query  bool    false  "Include lots with no stock left"
+// @Success      200  {object}  response.Response{data=[]dto.InventoryLotResponse}
+// @Router       /inventory/lots [get]
+func (h *InventoryHandler) ListLots(c *gin.Context) {
+	var filter repository.InventoryLotFilter
+	if w := c.Query("warehouse_id"); w != "" {
+		id, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid warehouse ID")
+			return
+		}
+		filter.WarehouseID = &id
+	}
+	if v := c.Query("variant_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid variant ID")
+			return
+		}
+		filter.VariantID = &id
+	}
+	if b := c.Query("batch_number"); b != "" {
+		filter.BatchNumber = &b
+	}
+	if d := c.Query("expiring_within_days"); d != "" {
+		days, err := strconv.Atoi(d)
+		if err != nil || days < 0 {
+			response.BadRequest(c, "Invalid expiring_within_days")
+			return
+		}
+		by := time.Now().AddDate(0, 0, days)
+		filter.ExpiringBy = &by
+	}
+	filter.IncludeEmpty = c.Query("include_empty") == "true"
+
+	lots, err := h.inventoryService.ListLots(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch inventory lots", err)
+		return
+	}
+
+	resp := make([]dto.InventoryLotResponse, len(lots))
+	for i := range lots {
+		resp[i] = mapInventoryLotResponse(&lots[i])
+	}
+	response.OK(c, "Inventory lots retrieved", resp)
+}
+
+// @Summary      Trace a batch
+// @Description  Follows a batch of a variant from where it was received, through the warehouses it moved to, to the customers it was sold to
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        batch_number  query  string  true  "Batch number"
+// @Param        variant_id    query  int     true  "Variant ID"
+// @Success      200  {object}  response.Response{data=dto.LotTraceResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /inventory/lots/trace [get]
+func (h *InventoryHandler) TraceLot(c *gin.Context) {
+	batchNumber := c.Query("batch_number")
+	if batchNumber == "" {
+		response.BadRequest(c, "batch_number is required")
+		return
+	}
+	variantID, err := strconv.ParseInt(c.Query("variant_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	trace, err := h.inventoryService.TraceLot(c.Request.Context(), batchNumber, variantID)
+	if err != nil {
+		if err == domainErrors.ErrLotNotFound {
+			response.NotFound(c, "No lot found for this batch and variant")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to trace batch", err)
+		return
+	}
+
+	resp := dto.LotTraceResponse{
+		BatchNumber: trace.BatchNumber,
+		VariantID:   trace.VariantID,
+		Lots:        make([]dto.InventoryLotResponse, len(trace.Lots)),
+		Movements:   make([]dto.LotTraceMovementResponse, len(trace.Movements)),
+		Customers:   make([]dto.LotTraceCustomerResponse, len(trace.Customers)),
+	}
+	for i := range trace.Lots {
+		resp.Lots[i] = mapInventoryLotResponse(&trace.Lots[i])
+	}
+	for i, m := range trace.Movements {
+		resp.Movements[i] = dto.LotTraceMovementResponse{
+			ID:            m.ID,
+			WarehouseID:   m.WarehouseID,
+			WarehouseName: m.WarehouseName,
+			LotID:         m.LotID,
+			SourceType:    m.SourceType,
+			ReferenceID:   m.ReferenceID,
+			QuantityDelta: m.QuantityDelta,
+			CustomerID:    m.CustomerID,
+			CustomerName:  m.CustomerName,
+			CreatedAt:     m.CreatedAt,
+		}
+	}
+	for i, cust := range trace.Customers {
+		resp.Customers[i] = dto.LotTraceCustomerResponse{
+			CustomerID:   cust.CustomerID,
+			CustomerName: cust.CustomerName,
+			Quantity:     cust.Quantity,
+			SaleIDs:      cust.SaleIDs,
+		}
+	}
+	response.OK(c, "Batch trace retrieved", resp)
+}
+
+func mapInventoryLotResponse(lot *entity.InventoryLot) dto.InventoryLotResponse {
+	return dto.InventoryLotResponse{
+		ID:            lot.ID,
+		WarehouseID:   lot.WarehouseID,
+		WarehouseName: lot.WarehouseName,
+		VariantID:     lot.VariantID,
+		VariantName:   lot.VariantName,
+		BatchNumber:   lot.BatchNumber,
+		ExpiryDate:    lot.ExpiryDate,
+		Expired:       lot.IsExpired(time.Now()),
+		Quantity:      lot.Quantity,
+		SupplierID:    lot.SupplierID,
+		SupplierName:  lot.SupplierName,
+		SourceType:    lot.SourceType,
+		ReferenceID:   lot.ReferenceID,
+		ReceivedAt:    lot.ReceivedAt,
+	}
+}
+
+// @Summary      Inventory drift
+// @Description  Lists stock levels that disagree with the sum of their ledger movements
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]dto.InventoryDriftResponse}
+// @Router       /inventory/drift [get]
+func (h *InventoryHandler) Drift(c *gin.Context) {
+	drift, err := h.inventoryService.Drift(c.Request.Context())
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to check inventory drift", err)
+		return
+	}
+	response.OK(c, "Inventory drift retrieved", mapDriftResponse(drift))
+}
+
+// @Summary      Rebuild inventory levels
+// @Description  Resets every drifting stock level to the sum of its ledger movements and returns the drift corrected
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]dto.InventoryDriftResponse}
+// @Router       /inventory/rebuild [post]
+func (h *InventoryHandler) Rebuild(c *gin.Context) {
+	drift, err := h.inventoryService.RebuildLevels(c.Request.Context())
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to rebuild inventory levels", err)
+		return
 	}
-	for _, item := range transfer.Items {
-		resp.Items = append(resp.Items, dto.TransferItemResponse{ID: item.ID, VariantID: item.VariantID, Quantity: item.Quantity})
+	response.OK(c, "Inventory levels rebuilt from ledger", mapDriftResponse(drift))
+}
+
+func mapDriftResponse(drift []entity.InventoryDrift) []dto.InventoryDriftResponse {
+	resp := []dto.InventoryDriftResponse{}
+	for _, d := range drift {
+		resp = append(resp, dto.InventoryDriftResponse{
+			WarehouseID:    d.WarehouseID,
+			VariantID:      d.VariantID,
+			LevelQuantity:  d.LevelQuantity,
+			LedgerQuantity: d.LedgerQuantity,
+			Drift:          d.Drift(),
+		})
 	}
-	response.Created(c, "Transfer created", resp)
+	return resp
 }
@@ -2,12 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/shopspring/decimal"
 
+	"github.com/qwikshelf/api/internal/adapter/primary/http/middleware"
 	"github.com/qwikshelf/api/internal/application/dto"
 	"github.com/qwikshelf/api/internal/application/service"
 	"github.com/qwikshelf/api/internal/domain/entity"
@@ -45,12 +46,19 @@ func mapProcurementResponse(p *entity.Procurement) dto.ProcurementResponse {
 	}
 	for _, item := range p.Items {
 		itemResp := dto.ProcurementItemResponse{
-			ID:               item.ID,
-			VariantID:        item.VariantID,
-			QuantityOrdered:  item.QuantityOrdered,
-			QuantityReceived: item.QuantityReceived,
-			UnitCost:         item.UnitCost,
-			LineTotal:        item.LineTotal(),
+			ID:                  item.ID,
+			VariantID:           item.VariantID,
+			QuantityOrdered:     item.QuantityOrdered,
+			QuantityReceived:    item.QuantityReceived,
+			QuantityRejected:    item.QuantityRejected,
+			QuantityOutstanding: item.OutstandingQuantity(),
+			UnitCost:            item.UnitCost,
+			LineTotal:           item.LineTotal(),
+			ListPrice:           item.ListPrice,
+			PriceListID:         item.PriceListID,
+			AboveListPrice:      item.IsAboveListPrice(),
+			BatchNumber:         item.BatchNumber,
+			ExpiryDate:          item.ExpiryDate,
 		}
 		if item.Variant != nil {
 			itemResp.VariantName = item.Variant.Name
@@ -62,9 +70,39 @@ func mapProcurementResponse(p *entity.Procurement) dto.ProcurementResponse {
 	return resp
 }
 
+// mapGoodsReceiptResponse maps a goods receipt to its response DTO
+func mapGoodsReceiptResponse(gr *entity.GoodsReceipt) dto.GoodsReceiptResponse {
+	resp := dto.GoodsReceiptResponse{
+		ID:                gr.ID,
+		ProcurementID:     gr.ProcurementID,
+		WarehouseID:       gr.WarehouseID,
+		ReceivedByUserID:  gr.ReceivedByUserID,
+		ReceivedByName:    gr.ReceivedByName,
+		DeliveryReference: gr.DeliveryReference,
+		Notes:             gr.Notes,
+		ReceivedAt:        gr.ReceivedAt,
+		Items:             make([]dto.GoodsReceiptItemResponse, 0, len(gr.Items)),
+	}
+	for _, item := range gr.Items {
+		resp.Items = append(resp.Items, dto.GoodsReceiptItemResponse{
+			ID:                item.ID,
+			ProcurementItemID: item.ProcurementItemID,
+			VariantID:         item.VariantID,
+			VariantName:       item.VariantName,
+			QuantityAccepted:  item.QuantityAccepted,
+			QuantityRejected:  item.QuantityRejected,
+			RejectionReason:   item.RejectionReason,
+			BatchNumber:       item.BatchNumber,
+			ExpiryDate:        item.ExpiryDate,
+			LotID:             item.LotID,
+		})
+	}
+	return resp
+}
+
 // Create creates a new procurement/purchase order
 // @Summary      Create purchase order
-// @Description  Creates a new purchase order for a supplier with line items
+// @Description  Creates a new purchase order for a supplier with line items. Lines are checked against the supplier's price list for today: lines without a unit cost are ordered at the list price, and lines ordered above it are flagged.
 // @Tags         Procurements
 // @Security     BearerAuth
 // @Accept       json
@@ -127,6 +165,8 @@ func (h *ProcurementHandler) Create(c *gin.Context) {
 			response.NotFound(c, "Warehouse not found")
 		} else if err == domainErrors.ErrProductVariantNotFound {
 			response.NotFound(c, "One or more product variants not found")
+		} else if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Quantities cannot be negative")
 		} else {
 			response.InternalErrorDebug(c, "Failed to create purchase order", err)
 		}
@@ -233,7 +273,7 @@ func (h *ProcurementHandler) ListBySupplier(c *gin.Context) {
 
 // UpdateStatus updates the status of a procurement
 // @Summary      Update purchase order status
-// @Description  Updates status. When set to 'received', inventory is auto-adjusted.
+// @Description  Moves the order along pending -> approved -> ordered; orders can be cancelled until anything is received. Approval goes through /procurements/{id}/approve and goods receipts move the order to partial or received. A partially received order can be closed short by setting it to 'received'.
 // @Tags         Procurements
 // @Security     BearerAuth
 // @Accept       json
@@ -242,6 +282,7 @@ func (h *ProcurementHandler) ListBySupplier(c *gin.Context) {
 // @Success      200  {object}  response.Response
 // @Failure      400  {object}  response.Response
 // @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /procurements/{id}/status [patch]
 func (h *ProcurementHandler) UpdateStatus(c *gin.Context) {
@@ -257,12 +298,26 @@ func (h *ProcurementHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	var userID *int64
+	if uid, exists := c.Get("user_id"); exists {
+		receivedBy := uid.(int64)
+		userID = &receivedBy
+	}
+
 	status := entity.ProcurementStatus(req.Status)
-	if err := h.procurementService.UpdateStatus(c.Request.Context(), id, status); err != nil {
+	if err := h.procurementService.UpdateStatus(c.Request.Context(), id, status, userID, req.Note); err != nil {
 		if err == domainErrors.ErrProcurementNotFound {
 			response.NotFound(c, "Purchase order not found")
 		} else if err == domainErrors.ErrInvalidInput {
 			response.BadRequest(c, "Invalid status")
+		} else if err == domainErrors.ErrApprovalRequired {
+			response.BadRequest(c, "Purchase orders are approved through the approval endpoint")
+		} else if err == domainErrors.ErrReceiptRequired {
+			response.BadRequest(c, "Purchase orders are received through goods receipts")
+		} else if err == domainErrors.ErrProcurementStatus {
+			response.Conflict(c, "Purchase order cannot move to that status from its current status")
+		} else if err == domainErrors.ErrProcurementReceived {
+			response.Conflict(c, "Purchase order cannot be cancelled once goods have been received against it")
 		} else {
 			response.InternalErrorDebug(c, "Failed to update status", err)
 		}
@@ -272,49 +327,255 @@ func (h *ProcurementHandler) UpdateStatus(c *gin.Context) {
 	response.OK(c, "Status updated", nil)
 }
 
-// ReceiveItems records received quantities for procurement items
-// @Summary      Receive items
-// @Description  Records partial or full receipt of items in a purchase order
+// ReceiveGoods records a delivery against a purchase order
+// @Summary      Receive goods
+// @Description  Records a goods receipt note for a delivery. Accepted quantities are booked into stock at once in a lot per line; rejected quantities need a reason. Lines cannot be received beyond the ordered quantity plus the over-receipt tolerance. The order moves to partial, or received once every line is in full.
 // @Tags         Procurements
 // @Security     BearerAuth
 // @Accept       json
-// @Param        id       path  int                                true  "Procurement ID"
-// @Param        request  body  dto.ReceiveProcurementItemRequest  true  "Items received"
-// @Success      200  {object}  response.Response
+// @Produce      json
+// @Param        id       path  int                            true  "Procurement ID"
+// @Param        request  body  dto.CreateGoodsReceiptRequest  true  "Delivery received"
+// @Success      201  {object}  response.Response{data=dto.GoodsReceiptResponse}
 // @Failure      400  {object}  response.Response
 // @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      422  {object}  response.Response
 // @Failure      500  {object}  response.Response
-// @Router       /procurements/{id}/receive [patch]
-func (h *ProcurementHandler) ReceiveItems(c *gin.Context) {
+// @Router       /procurements/{id}/receipts [post]
+func (h *ProcurementHandler) ReceiveGoods(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		response.BadRequest(c, "Invalid procurement ID")
 		return
 	}
 
-	var req dto.ReceiveProcurementItemRequest
+	var req dto.CreateGoodsReceiptRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
-	items := make([]struct {
-		ItemID           int64
-		QuantityReceived decimal.Decimal
-	}, len(req.Items))
+	receipt := &entity.GoodsReceipt{
+		ProcurementID:     id,
+		DeliveryReference: req.DeliveryReference,
+		Notes:             req.Notes,
+		Items:             make([]entity.GoodsReceiptItem, len(req.Items)),
+	}
+	if uid, exists := c.Get("user_id"); exists {
+		receivedBy := uid.(int64)
+		receipt.ReceivedByUserID = &receivedBy
+	}
 	for i, item := range req.Items {
-		items[i].ItemID = item.ItemID
-		items[i].QuantityReceived = item.QuantityReceived
+		receipt.Items[i] = entity.GoodsReceiptItem{
+			ProcurementItemID: item.ItemID,
+			QuantityAccepted:  item.QuantityAccepted,
+			QuantityRejected:  item.QuantityRejected,
+			RejectionReason:   item.RejectionReason,
+			BatchNumber:       item.BatchNumber,
+		}
+		if item.ExpiryDate != "" {
+			t, err := time.Parse("2006-01-02", item.ExpiryDate)
+			if err != nil {
+				response.BadRequest(c, "Invalid expiry_date format. Use YYYY-MM-DD")
+				return
+			}
+			receipt.Items[i].ExpiryDate = &t
+		}
+	}
+
+	if err := h.procurementService.ReceiveGoods(c.Request.Context(), receipt); err != nil {
+		switch err {
+		case domainErrors.ErrProcurementNotFound:
+			response.NotFound(c, "Purchase order not found")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Each line needs a positive accepted or rejected quantity")
+		case domainErrors.ErrRejectionReasonRequired:
+			response.BadRequest(c, "Rejected quantities need a rejection reason")
+		case domainErrors.ErrReceiptItemNotOnOrder:
+			response.BadRequest(c, "Receipt line is not on the purchase order")
+		case domainErrors.ErrProcurementStatus:
+			response.Conflict(c, "Only ordered or partially received purchase orders can be received")
+		case domainErrors.ErrOverReceipt:
+			response.Error(c, http.StatusUnprocessableEntity, "OVER_RECEIPT", "Received quantity exceeds the ordered quantity beyond the allowed tolerance")
+		default:
+			response.InternalErrorDebug(c, "Failed to receive goods", err)
+		}
+		return
+	}
+
+	response.Created(c, "Goods received", mapGoodsReceiptResponse(receipt))
+}
+
+// ListReceipts lists the goods receipts of a purchase order
+// @Summary      List goods receipts
+// @Description  Returns the goods receipts recorded against a purchase order, oldest first
+// @Tags         Procurements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  int  true  "Procurement ID"
+// @Success      200  {object}  response.Response{data=[]dto.GoodsReceiptResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /procurements/{id}/receipts [get]
+func (h *ProcurementHandler) ListReceipts(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid procurement ID")
+		return
+	}
+
+	receipts, err := h.procurementService.ListReceipts(c.Request.Context(), id)
+	if err != nil {
+		if err == domainErrors.ErrProcurementNotFound {
+			response.NotFound(c, "Purchase order not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to fetch goods receipts", err)
+		}
+		return
+	}
+
+	resp := make([]dto.GoodsReceiptResponse, 0, len(receipts))
+	for i := range receipts {
+		resp = append(resp, mapGoodsReceiptResponse(&receipts[i]))
+	}
+
+	response.OK(c, "Goods receipts retrieved", resp)
+}
+
+// GetReceipt retrieves a goods receipt of a purchase order
+// @Summary      Get goods receipt
+// @Description  Returns a goods receipt with its accepted and rejected lines
+// @Tags         Procurements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path  int  true  "Procurement ID"
+// @Param        receiptId  path  int  true  "Goods receipt ID"
+// @Success      200  {object}  response.Response{data=dto.GoodsReceiptResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /procurements/{id}/receipts/{receiptId} [get]
+func (h *ProcurementHandler) GetReceipt(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid procurement ID")
+		return
+	}
+	receiptID, err := strconv.ParseInt(c.Param("receiptId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid receipt ID")
+		return
+	}
+
+	receipt, err := h.procurementService.GetReceipt(c.Request.Context(), id, receiptID)
+	if err != nil {
+		if err == domainErrors.ErrGoodsReceiptNotFound {
+			response.NotFound(c, "Goods receipt not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to fetch goods receipt", err)
+		}
+		return
+	}
+
+	response.OK(c, "Goods receipt retrieved", mapGoodsReceiptResponse(receipt))
+}
+
+// Approve approves a pending purchase order
+// @Summary      Approve purchase order
+// @Description  Approves a pending purchase order. The order total must be within the approval limit of the approver's role, and orders cannot be approved by the user who raised them.
+// @Tags         Procurements
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                            true   "Procurement ID"
+// @Param        request  body  dto.ApproveProcurementRequest  false  "Approval note"
+// @Success      200  {object}  response.Response{data=dto.ProcurementResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /procurements/{id}/approve [post]
+func (h *ProcurementHandler) Approve(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid procurement ID")
+		return
+	}
+
+	var req dto.ApproveProcurementRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
 	}
 
-	if err := h.procurementService.ReceiveItems(c.Request.Context(), id, items); err != nil {
+	procurement, err := h.procurementService.Approve(c.Request.Context(), id, middleware.GetUserID(c), middleware.GetRoleID(c), req.Note)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrProcurementNotFound:
+			response.NotFound(c, "Purchase order not found")
+		case domainErrors.ErrProcurementStatus:
+			response.Conflict(c, "Only pending purchase orders can be approved")
+		case domainErrors.ErrProcurementSelfApproval:
+			response.Forbidden(c, "You cannot approve your own purchase order")
+		case domainErrors.ErrApprovalLimitExceeded:
+			response.Forbidden(c, "Purchase order total exceeds the approval limit of your role")
+		default:
+			response.InternalErrorDebug(c, "Failed to approve purchase order", err)
+		}
+		return
+	}
+
+	response.OK(c, "Purchase order approved", mapProcurementResponse(procurement))
+}
+
+// History lists the status changes of a purchase order
+// @Summary      Purchase order status history
+// @Description  Returns the status changes of a purchase order, approvals included, oldest first
+// @Tags         Procurements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  int  true  "Procurement ID"
+// @Success      200  {object}  response.Response{data=[]dto.ProcurementStatusEventResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /procurements/{id}/history [get]
+func (h *ProcurementHandler) History(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid procurement ID")
+		return
+	}
+
+	events, err := h.procurementService.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
 		if err == domainErrors.ErrProcurementNotFound {
 			response.NotFound(c, "Purchase order not found")
 		} else {
-			response.InternalErrorDebug(c, "Failed to receive items", err)
+			response.InternalErrorDebug(c, "Failed to fetch status history", err)
 		}
 		return
 	}
 
-	response.OK(c, "Items received", nil)
+	resp := make([]dto.ProcurementStatusEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, dto.ProcurementStatusEventResponse{
+			ID:              e.ID,
+			FromStatus:      string(e.FromStatus),
+			ToStatus:        string(e.ToStatus),
+			Amount:          e.Amount,
+			ChangedByUserID: e.ChangedByUserID,
+			ChangedByName:   e.ChangedByName,
+			RoleID:          e.RoleID,
+			Note:            e.Note,
+			ChangedAt:       e.ChangedAt,
+		})
+	}
+
+	response.OK(c, "Status history retrieved", resp)
 }
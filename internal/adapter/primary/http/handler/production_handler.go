@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// ProductionHandler handles production API requests
+type ProductionHandler struct {
+	productionService *service.ProductionService
+}
+
+// NewProductionHandler creates a new production handler
+func NewProductionHandler(productionService *service.ProductionService) *ProductionHandler {
+	return &ProductionHandler{productionService: productionService}
+}
+
+// mapProductionLogResponse maps a production log entity to response DTO
+func mapProductionLogResponse(l *entity.ProductionLog) dto.ProductionLogResponse {
+	resp := dto.ProductionLogResponse{
+		ID:              l.ID,
+		InputVariantID:  l.InputVariantID,
+		InputQty:        l.InputQty,
+		OutputVariantID: l.OutputVariantID,
+		OutputQty:       l.OutputQty,
+		YieldPercent:    l.Yield().Round(2),
+	}
+	if l.InputVariant != nil {
+		resp.InputVariantName = l.InputVariant.Name
+		resp.InputVariantUnit = l.InputVariant.Unit
+	}
+	if l.OutputVariant != nil {
+		resp.OutputVariantName = l.OutputVariant.Name
+		resp.OutputVariantUnit = l.OutputVariant.Unit
+	}
+	return resp
+}
+
+// mapProductionRunResponse maps a production run entity to response DTO
+func mapProductionRunResponse(r *entity.ProductionRun) dto.ProductionRunResponse {
+	resp := dto.ProductionRunResponse{
+		ID:          r.ID,
+		WarehouseID: r.WarehouseID,
+		StaffID:     r.StaffID,
+		BatchCode:   r.BatchCode,
+		ExpiryDate:  r.ExpiryDate,
+		CreatedAt:   r.CreatedAt,
+	}
+	if r.Warehouse != nil {
+		resp.WarehouseName = r.Warehouse.Name
+	}
+	if r.Staff != nil {
+		resp.StaffName = r.Staff.Username
+	}
+	for i := range r.Logs {
+		resp.Logs = append(resp.Logs, mapProductionLogResponse(&r.Logs[i]))
+	}
+	return resp
+}
+
+// Create records a new production run
+// @Summary      Record production run
+// @Description  Records a batch at a factory warehouse. Input variants are consumed from stock and output variants are credited in one step.
+// @Tags         Production
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.CreateProductionRunRequest  true  "Production run details"
+// @Success      201  {object}  response.Response{data=dto.ProductionRunResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /production [post]
+func (h *ProductionHandler) Create(c *gin.Context) {
+	var req dto.CreateProductionRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	run := &entity.ProductionRun{
+		WarehouseID: req.WarehouseID,
+		StaffID:     userID.(int64),
+		BatchCode:   req.BatchCode,
+	}
+	if req.ExpiryDate != "" {
+		t, err := time.Parse("2006-01-02", req.ExpiryDate)
+		if err != nil {
+			response.BadRequest(c, "Invalid expiry_date format. Use YYYY-MM-DD")
+			return
+		}
+		run.ExpiryDate = &t
+	}
+	for _, l := range req.Logs {
+		run.Logs = append(run.Logs, entity.ProductionLog{
+			InputVariantID:  l.InputVariantID,
+			InputQty:        l.InputQty,
+			OutputVariantID: l.OutputVariantID,
+			OutputQty:       l.OutputQty,
+		})
+	}
+
+	if err := h.productionService.RecordRun(c.Request.Context(), run); err != nil {
+		switch err {
+		case domainErrors.ErrWarehouseNotFound:
+			response.NotFound(c, "Warehouse not found")
+		case domainErrors.ErrProductVariantNotFound:
+			response.NotFound(c, "One or more product variants not found")
+		case domainErrors.ErrNotFactoryWarehouse:
+			response.BadRequest(c, "Production can only be recorded at a factory warehouse")
+		case domainErrors.ErrBatchCodeExists:
+			response.Conflict(c, "Batch code already exists")
+		case domainErrors.ErrInsufficientStock:
+			response.BadRequest(c, "Insufficient stock for one or more input variants")
+		case domainErrors.ErrStockExpired:
+			response.BadRequest(c, "Not enough unexpired stock for one or more input variants")
+		case domainErrors.ErrStockReserved:
+			response.BadRequest(c, "Stock of one or more input variants is reserved for orders and subscriptions")
+		case domainErrors.ErrInvalidQuantity:
+			response.BadRequest(c, "Input quantity must be greater than zero and output quantity cannot be negative")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Input and output variant must differ")
+		default:
+			response.InternalErrorDebug(c, "Failed to record production run", err)
+		}
+		return
+	}
+
+	// Reload to include variant, warehouse and staff details
+	created, err := h.productionService.GetByID(c.Request.Context(), run.ID)
+	if err != nil {
+		created = run
+	}
+
+	response.Success(c, 201, "Production run recorded", mapProductionRunResponse(created))
+}
+
+// Get retrieves a production run by ID
+// @Summary      Get production run
+// @Description  Returns a production run with its input/output logs
+// @Tags         Production
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Production run ID"
+// @Success      200  {object}  response.Response{data=dto.ProductionRunResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /production/{id} [get]
+func (h *ProductionHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid production run ID")
+		return
+	}
+
+	run, err := h.productionService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if err == domainErrors.ErrProductionRunNotFound {
+			response.NotFound(c, "Production run not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to get production run", err)
+		}
+		return
+	}
+
+	response.OK(c, "Production run retrieved", mapProductionRunResponse(run))
+}
+
+// List retrieves production runs with pagination
+// @Summary      List production runs
+// @Description  Returns paginated list of production runs
+// @Tags         Production
+// @Security     BearerAuth
+// @Param        page      query  int  false  "Page number" default(1)
+// @Param        per_page  query  int  false  "Items per page" default(20)
+// @Success      200  {object}  response.Response{data=[]dto.ProductionRunResponse}
+// @Failure      500  {object}  response.Response
+// @Router       /production [get]
+func (h *ProductionHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+	offset := (page - 1) * perPage
+
+	runs, total, err := h.productionService.List(c.Request.Context(), offset, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to list production runs", err)
+		return
+	}
+
+	var respList []dto.ProductionRunResponse
+	for _, r := range runs {
+		respList = append(respList, mapProductionRunResponse(&r))
+	}
+
+	response.SuccessWithMeta(c, 200, "Production runs retrieved", respList, &response.Meta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (int(total) + perPage - 1) / perPage,
+	})
+}
+
+// GetYield returns the yield report for a batch
+// @Summary      Get batch yield
+// @Description  Returns per-line and overall yield (output/input %) for a production batch
+// @Tags         Production
+// @Security     BearerAuth
+// @Param        batchCode  path  string  true  "Batch code"
+// @Success      200  {object}  response.Response{data=dto.ProductionYieldResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /production/batch/{batchCode}/yield [get]
+func (h *ProductionHandler) GetYield(c *gin.Context) {
+	run, err := h.productionService.GetByBatchCode(c.Request.Context(), c.Param("batchCode"))
+	if err != nil {
+		if err == domainErrors.ErrProductionRunNotFound {
+			response.NotFound(c, "Production batch not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to get yield report", err)
+		}
+		return
+	}
+
+	resp := dto.ProductionYieldResponse{
+		RunID:        run.ID,
+		BatchCode:    run.BatchCode,
+		WarehouseID:  run.WarehouseID,
+		CreatedAt:    run.CreatedAt,
+		TotalInput:   run.TotalInput(),
+		TotalOutput:  run.TotalOutput(),
+		YieldPercent: run.Yield().Round(2),
+		Lines:        []dto.ProductionLogResponse{},
+	}
+	for i := range run.Logs {
+		resp.Lines = append(resp.Lines, mapProductionLogResponse(&run.Logs[i]))
+	}
+
+	response.OK(c, "Yield report retrieved", resp)
+}
+
+// Plan pre-fills production inputs from a product's recipe
+// @Summary      Plan production from recipe
+// @Description  Scales the product's recipe to the requested output and returns the expected input lines for a new run
+// @Tags         Production
+// @Security     BearerAuth
+// @Param        variant_id  query  int     true  "Output product variant ID"
+// @Param        output_qty  query  number  true  "Planned output quantity"
+// @Success      200  {object}  response.Response{data=[]dto.ProductionLogResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /production/plan [get]
+func (h *ProductionHandler) Plan(c *gin.Context) {
+	variantID, err := strconv.ParseInt(c.Query("variant_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant_id")
+		return
+	}
+	outputQty, err := decimal.NewFromString(c.Query("output_qty"))
+	if err != nil {
+		response.BadRequest(c, "Invalid output_qty")
+		return
+	}
+
+	logs, err := h.productionService.Plan(c.Request.Context(), variantID, outputQty)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrRecipeNotFound:
+			response.NotFound(c, "No recipe defined for this product")
+		case domainErrors.ErrInvalidQuantity:
+			response.BadRequest(c, "Output quantity must be greater than zero")
+		default:
+			response.InternalErrorDebug(c, "Failed to plan production", err)
+		}
+		return
+	}
+
+	respList := []dto.ProductionLogResponse{}
+	for i := range logs {
+		respList = append(respList, mapProductionLogResponse(&logs[i]))
+	}
+
+	response.OK(c, "Production plan generated", respList)
+}
+
+// YieldDeviations lists production lines whose yield is outside recipe tolerance
+// @Summary      Yield deviation report
+// @Description  Returns production lines in the date range whose actual yield falls outside the recipe's expected yield ± tolerance
+// @Tags         Production
+// @Security     BearerAuth
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.ProductionYieldDeviationResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /production/yield-deviations [get]
+func (h *ProductionHandler) YieldDeviations(c *gin.Context) {
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -30)
+	to := today
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+
+	deviations, err := h.productionService.YieldDeviations(c.Request.Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to build yield deviation report", err)
+		return
+	}
+
+	respList := []dto.ProductionYieldDeviationResponse{}
+	for i := range deviations {
+		d := &deviations[i]
+		respList = append(respList, dto.ProductionYieldDeviationResponse{
+			RunID:          d.RunID,
+			BatchCode:      d.BatchCode,
+			WarehouseID:    d.WarehouseID,
+			CreatedAt:      d.CreatedAt,
+			Line:           mapProductionLogResponse(&d.Log),
+			ActualYield:    d.ActualYield,
+			ExpectedYield:  d.ExpectedYield,
+			YieldTolerance: d.YieldTolerance,
+			Deviation:      d.ActualYield.Sub(d.ExpectedYield),
+		})
+	}
+
+	response.OK(c, "Yield deviations retrieved", respList)
+}
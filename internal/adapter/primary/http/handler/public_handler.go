@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"net/http"
 	"strconv"
 	"time"
 
@@ -11,41 +12,52 @@ import (
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 	"github.com/qwikshelf/api/pkg/response"
+	"github.com/shopspring/decimal"
 )
 
 type PublicHandler struct {
 	variantService  *service.ProductVariantService
 	categoryService *service.CategoryService
 	saleService     *service.SaleService
+	customerService *service.CustomerService
+	addressService  *service.CustomerAddressService
 	userService     *service.UserService
 	authService     *service.AuthService
 	deliveryService *service.DeliveryService
+	stockService    *service.StockReservationService
 }
 
 func NewPublicHandler(
 	variantService *service.ProductVariantService,
 	categoryService *service.CategoryService,
 	saleService *service.SaleService,
+	customerService *service.CustomerService,
+	addressService *service.CustomerAddressService,
 	userService *service.UserService,
 	authService *service.AuthService,
 	deliveryService *service.DeliveryService,
+	stockService *service.StockReservationService,
 ) *PublicHandler {
 	return &PublicHandler{
 		variantService:  variantService,
 		categoryService: categoryService,
 		saleService:     saleService,
+		customerService: customerService,
+		addressService:  addressService,
 		userService:     userService,
 		authService:     authService,
 		deliveryService: deliveryService,
+		stockService:    stockService,
 	}
 }
 
 // @Summary      List public products
-// @Description  Returns a list of product variants for the storefront
+// @Description  Returns a list of product variants for the storefront with the quantity available to order, at the warehouse serving the pincode when one is given
 // @Tags         Public
 // @Produce      json
-// @Param        page      query  int  false  "Page number"    default(1)
-// @Param        per_page  query  int  false  "Items per page" default(20)
+// @Param        page      query  int     false  "Page number"    default(1)
+// @Param        per_page  query  int     false  "Items per page" default(20)
+// @Param        pincode   query  string  false  "Delivery pincode"
 // @Success      200  {object}  response.Response{data=[]dto.PublicProductResponse}
 // @Router       /public/products [get]
 func (h *PublicHandler) ListProducts(c *gin.Context) {
@@ -80,6 +92,11 @@ func (h *PublicHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
+	available, ok := h.availableToPromise(c, variants)
+	if !ok {
+		return
+	}
+
 	resp := make([]dto.PublicProductResponse, 0)
 	for _, v := range variants {
 		// Basic masking of sensitive data (CostPrice excluded in PublicProductResponse)
@@ -92,6 +109,8 @@ func (h *PublicHandler) ListProducts(c *gin.Context) {
 			Unit:             v.Unit,
 			SellingPrice:     v.SellingPrice,
 			ConversionFactor: v.ConversionFactor,
+			Available:        available[v.ID],
+			InStock:          available[v.ID].IsPositive(),
 			// For now description is empty as we don't have it on variant level,
 			// but we can fetch it from family if needed later.
 		})
@@ -111,10 +130,11 @@ func (h *PublicHandler) ListProducts(c *gin.Context) {
 }
 
 // @Summary      Get public product detail
-// @Description  Returns details for a single product variant
+// @Description  Returns details for a single product variant with the quantity available to order, at the warehouse serving the pincode when one is given
 // @Tags         Public
 // @Produce      json
-// @Param        id   path      int  true  "Product Variant ID"
+// @Param        id       path   int     true   "Product Variant ID"
+// @Param        pincode  query  string  false  "Delivery pincode"
 // @Success      200  {object}  response.Response{data=dto.PublicProductResponse}
 // @Router       /public/products/{id} [get]
 func (h *PublicHandler) GetProduct(c *gin.Context) {
@@ -130,6 +150,11 @@ func (h *PublicHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	available, ok := h.availableToPromise(c, []entity.ProductVariant{*v})
+	if !ok {
+		return
+	}
+
 	resp := dto.PublicProductResponse{
 		ID:               v.ID,
 		FamilyID:         v.FamilyID,
@@ -139,11 +164,28 @@ func (h *PublicHandler) GetProduct(c *gin.Context) {
 		Unit:             v.Unit,
 		SellingPrice:     v.SellingPrice,
 		ConversionFactor: v.ConversionFactor,
+		Available:        available[v.ID],
+		InStock:          available[v.ID].IsPositive(),
 	}
 
 	response.OK(c, "Product retrieved", resp)
 }
 
+// availableToPromise looks up how many units of each variant can be ordered, for the pincode
+// query parameter if given, writing the error response if it fails
+func (h *PublicHandler) availableToPromise(c *gin.Context, variants []entity.ProductVariant) (map[int64]decimal.Decimal, bool) {
+	available, err := h.stockService.AvailableToPromise(c.Request.Context(), variants, c.Query("pincode"))
+	if err != nil {
+		if err == domainErrors.ErrPincodeNotServiceable {
+			response.Error(c, http.StatusUnprocessableEntity, "NOT_SERVICEABLE", "We do not deliver to this pincode yet")
+		} else {
+			response.InternalErrorDebug(c, "Failed to fetch product availability", err)
+		}
+		return nil, false
+	}
+	return available, true
+}
+
 // @Summary      List public categories
 // @Description  Returns a list of categories for the storefront navigation
 // @Tags         Public
@@ -169,12 +211,14 @@ func (h *PublicHandler) ListCategories(c *gin.Context) {
 }
 
 // @Summary      Create storefront order
-// @Description  Place a new order from the public storefront. Defaults to COD.
+// @Description  Place a new order from the public storefront. The delivery pincode selects the fulfilling warehouse, minimum order amount and delivery charge. Defaults to COD.
 // @Tags         Public
 // @Accept       json
 // @Produce      json
 // @Param        request  body  dto.PublicCreateOrderRequest  true  "Order details"
 // @Success      201  {object}  response.Response{data=dto.PublicOrderResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      422  {object}  response.Response
 // @Router       /public/orders [post]
 func (h *PublicHandler) CreateOrder(c *gin.Context) {
 	var req dto.PublicCreateOrderRequest
@@ -183,19 +227,61 @@ func (h *PublicHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	// Map request to entity.Sale
+	paymentMethod := req.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = "cash" // cash on delivery
+	}
+
+	// Link the order to the signed-in customer, or to a customer record keyed by phone
+	var customer *entity.Customer
+	if middleware.GetUserID(c) != 0 {
+		var ok bool
+		if customer, ok = h.requireCustomer(c); !ok {
+			return
+		}
+	} else {
+		var err error
+		customer, err = h.customerService.FindOrCreateByPhone(c.Request.Context(), &entity.Customer{
+			Name:    req.CustomerName,
+			Phone:   req.CustomerPhone,
+			Address: &req.Address,
+		})
+		if err != nil {
+			response.InternalErrorDebug(c, "Failed to resolve customer", err)
+			return
+		}
+	}
+
 	sale := &entity.Sale{
-		WarehouseID:       1, // Main Warehouse
-		CustomerName:      req.CustomerName + " (Ph: " + req.CustomerPhone + ", Addr: " + req.Address + ")",
-		PaymentMethod:     "cash", // default to COD for storefront
-		ProcessedByUserID: 1,      // System/Admin user
-		CreatedAt:         time.Now(),
+		CustomerID:    &customer.ID,
+		CustomerName:  req.CustomerName,
+		PaymentMethod: paymentMethod,
+		CreatedAt:     time.Now(),
+		DeliveryAddress: &entity.SaleDeliveryAddress{
+			CustomerID:   &customer.ID,
+			ContactName:  req.CustomerName,
+			Phone:        req.CustomerPhone,
+			AddressLine1: req.Address,
+			AddressLine2: req.AddressLine2,
+			Landmark:     req.Landmark,
+			City:         req.City,
+			Pincode:      req.Pincode,
+		},
 	}
 
-	// Link to customer if authenticated
-	userID := middleware.GetUserID(c)
-	if userID != 0 {
-		sale.CustomerID = &userID
+	// Deliver to a saved address from the customer's address book
+	if req.AddressID != nil {
+		if middleware.GetUserID(c) == 0 {
+			response.Unauthorized(c, "Sign in to use a saved address")
+			return
+		}
+		address, err := h.addressService.GetForCustomer(c.Request.Context(), customer.ID, *req.AddressID)
+		if err != nil {
+			response.NotFound(c, "Address not found")
+			return
+		}
+		sale.DeliveryAddress = mapSavedDeliveryAddress(address, req.CustomerName, req.CustomerPhone)
+		sale.ZoneID = address.ZoneID
 	}
 
 	for _, item := range req.Items {
@@ -212,22 +298,36 @@ func (h *PublicHandler) CreateOrder(c *gin.Context) {
 		})
 	}
 
-	if err := h.saleService.ProcessSale(c.Request.Context(), sale); err != nil {
-		response.InternalErrorDebug(c, "Failed to process order", err)
+	if err := h.saleService.PlaceOrder(c.Request.Context(), sale); err != nil {
+		switch err {
+		case domainErrors.ErrPincodeNotServiceable:
+			response.Error(c, http.StatusUnprocessableEntity, "NOT_SERVICEABLE", "We do not deliver to this pincode yet")
+		case domainErrors.ErrBelowMinOrderAmount:
+			response.Error(c, http.StatusUnprocessableEntity, "BELOW_MIN_ORDER", "Order total is below the minimum order amount for your area")
+		case domainErrors.ErrInsufficientStock, domainErrors.ErrStockReserved:
+			response.BadRequest(c, "Insufficient stock for one or more items")
+		case domainErrors.ErrStockExpired:
+			response.BadRequest(c, "One or more items are out of stock")
+		case domainErrors.ErrProductVariantNotFound:
+			response.NotFound(c, "One or more products not found")
+		default:
+			response.InternalErrorDebug(c, "Failed to process order", err)
+		}
 		return
 	}
 
 	resp := dto.PublicOrderResponse{
-		ID:          sale.ID,
-		Status:      "confirmed",
-		TotalAmount: sale.TotalAmount,
+		ID:             sale.ID,
+		Status:         string(sale.Status),
+		DeliveryCharge: sale.DeliveryCharge,
+		TotalAmount:    sale.TotalAmount,
 	}
 
 	response.Success(c, 201, "Order placed successfully", resp)
 }
 
 // @Summary      Customer Registration
-// @Description  Create a new customer account for the storefront.
+// @Description  Create a new customer account for the storefront, linked to the customer record with the same phone.
 // @Tags         Public
 // @Accept       json
 // @Produce      json
@@ -248,11 +348,14 @@ func (h *PublicHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Create(c.Request.Context(), req.Username, req.Password, req.FullName, req.Phone, req.Address, role.ID, true, nil)
+	user, _, err := h.customerService.RegisterAccount(c.Request.Context(), req.Username, req.Password, req.FullName, req.Phone, req.Address, role.ID)
 	if err != nil {
-		if err == domainErrors.ErrUsernameExists {
+		switch err {
+		case domainErrors.ErrUsernameExists:
 			response.Conflict(c, "Username already exists")
-		} else {
+		case domainErrors.ErrCustomerAlreadyLinked:
+			response.Conflict(c, "An account is already registered with this phone number")
+		default:
 			response.InternalErrorDebug(c, "Failed to register customer", err)
 		}
 		return
@@ -330,9 +433,8 @@ func (h *PublicHandler) Login(c *gin.Context) {
 // @Success      200      {object}  response.Response{data=[]dto.PublicOrderResponse}
 // @Router       /public/my/orders [get]
 func (h *PublicHandler) GetMyOrders(c *gin.Context) {
-	userID := middleware.GetUserID(c)
-	if userID == 0 {
-		response.Unauthorized(c, "Authentication required")
+	customer, ok := h.requireCustomer(c)
+	if !ok {
 		return
 	}
 
@@ -340,7 +442,7 @@ func (h *PublicHandler) GetMyOrders(c *gin.Context) {
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
 	offset := (page - 1) * perPage
 
-	sales, total, err := h.saleService.ListByCustomer(c.Request.Context(), userID, offset, perPage)
+	sales, total, err := h.saleService.ListByCustomer(c.Request.Context(), customer.ID, offset, perPage)
 	if err != nil {
 		response.InternalErrorDebug(c, "Failed to fetch orders", err)
 		return
@@ -349,9 +451,10 @@ func (h *PublicHandler) GetMyOrders(c *gin.Context) {
 	var resp []dto.PublicOrderResponse
 	for _, s := range sales {
 		resp = append(resp, dto.PublicOrderResponse{
-			ID:          s.ID,
-			Status:      "confirmed", // Map from sale status if available
-			TotalAmount: s.TotalAmount,
+			ID:             s.ID,
+			Status:         string(s.Status),
+			DeliveryCharge: s.DeliveryCharge,
+			TotalAmount:    s.TotalAmount,
 		})
 	}
 
@@ -374,29 +477,283 @@ func (h *PublicHandler) GetMyOrders(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Order ID"
-// @Success      200  {object}  response.Response
+// @Success      200  {object}  response.Response{data=dto.PublicOrderTrackingResponse}
 // @Router       /public/my/orders/{id} [get]
 func (h *PublicHandler) GetOrderTracking(c *gin.Context) {
-	userID := middleware.GetUserID(c)
 	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		response.BadRequest(c, "Invalid order ID")
 		return
 	}
 
+	customer, ok := h.requireCustomer(c)
+	if !ok {
+		return
+	}
+
 	sale, err := h.saleService.GetByID(c.Request.Context(), orderID)
 	if err != nil {
 		response.NotFound(c, "Order not found")
 		return
 	}
 
-	// Security check: ensure order belongs to user
-	if sale.CustomerID == nil || *sale.CustomerID != userID {
+	// Security check: ensure order belongs to the signed-in customer
+	if sale.CustomerID == nil || *sale.CustomerID != customer.ID {
 		response.Forbidden(c, "Order does not belong to you")
 		return
 	}
 
-	response.OK(c, "Order details retrieved", sale)
+	events, err := h.saleService.GetStatusHistory(c.Request.Context(), orderID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch order timeline", err)
+		return
+	}
+
+	// Customers are not shown what the goods cost
+	items := mapSaleResponse(sale).Items
+	for i := range items {
+		items[i].CostOfGoods = nil
+	}
+
+	resp := dto.PublicOrderTrackingResponse{
+		ID:          sale.ID,
+		Status:      string(sale.Status),
+		TotalAmount: sale.TotalAmount,
+		CreatedAt:   sale.CreatedAt,
+		Items:       items,
+		Delivery:    mapDeliveryAddress(sale.DeliveryAddress),
+		Timeline:    mapOrderTimeline(events),
+	}
+
+	response.OK(c, "Order details retrieved", resp)
+}
+
+// currentCustomer resolves the customer record linked to the signed-in storefront user
+func (h *PublicHandler) currentCustomer(c *gin.Context) (*entity.Customer, error) {
+	return h.customerService.ResolveForUser(c.Request.Context(), middleware.GetUserID(c))
+}
+
+// requireCustomer resolves the signed-in customer, writing the error response when it cannot
+func (h *PublicHandler) requireCustomer(c *gin.Context) (*entity.Customer, bool) {
+	customer, err := h.currentCustomer(c)
+	if err == domainErrors.ErrCustomerNotFound {
+		response.BadRequest(c, "Add a phone number to your account to manage deliveries")
+		return nil, false
+	}
+	if err == domainErrors.ErrCustomerAlreadyLinked {
+		response.Conflict(c, "Your phone number is linked to another account")
+		return nil, false
+	}
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to resolve customer", err)
+		return nil, false
+	}
+	return customer, true
+}
+
+// mapSavedDeliveryAddress copies a saved address onto an order, defaulting the contact to the order's
+func mapSavedDeliveryAddress(a *entity.CustomerAddress, contactName, phone string) *entity.SaleDeliveryAddress {
+	addr := &entity.SaleDeliveryAddress{
+		CustomerID:        &a.CustomerID,
+		CustomerAddressID: &a.ID,
+		ContactName:       contactName,
+		Phone:             phone,
+		AddressLine1:      a.AddressLine1,
+		AddressLine2:      a.AddressLine2,
+		Landmark:          a.Landmark,
+		City:              a.City,
+		Pincode:           a.Pincode,
+	}
+	if a.ContactName != "" {
+		addr.ContactName = a.ContactName
+	}
+	if a.Phone != "" {
+		addr.Phone = a.Phone
+	}
+	return addr
+}
+
+func mapCustomerAddressResponse(a *entity.CustomerAddress) dto.CustomerAddressResponse {
+	return dto.CustomerAddressResponse{
+		ID:           a.ID,
+		Label:        string(a.Label),
+		ContactName:  a.ContactName,
+		Phone:        a.Phone,
+		AddressLine1: a.AddressLine1,
+		AddressLine2: a.AddressLine2,
+		Landmark:     a.Landmark,
+		City:         a.City,
+		Pincode:      a.Pincode,
+		Latitude:     a.Latitude,
+		Longitude:    a.Longitude,
+		ZoneID:       a.ZoneID,
+		ZoneName:     a.ZoneName,
+		Serviceable:  a.ZoneID != nil,
+		IsDefault:    a.IsDefault,
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+	}
+}
+
+func mapCustomerAddressRequest(req *dto.CustomerAddressRequest) *entity.CustomerAddress {
+	return &entity.CustomerAddress{
+		Label:        entity.AddressLabel(req.Label),
+		ContactName:  req.ContactName,
+		Phone:        req.Phone,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		Landmark:     req.Landmark,
+		City:         req.City,
+		Pincode:      req.Pincode,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		IsDefault:    req.IsDefault,
+	}
+}
+
+// @Summary      List my addresses
+// @Description  Returns the signed-in customer's saved delivery addresses, default first.
+// @Tags         Public
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]dto.CustomerAddressResponse}
+// @Router       /public/my/addresses [get]
+func (h *PublicHandler) ListMyAddresses(c *gin.Context) {
+	customer, ok := h.requireCustomer(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.addressService.ListByCustomer(c.Request.Context(), customer.ID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch addresses", err)
+		return
+	}
+
+	resp := make([]dto.CustomerAddressResponse, 0, len(addresses))
+	for i := range addresses {
+		resp = append(resp, mapCustomerAddressResponse(&addresses[i]))
+	}
+
+	response.OK(c, "Addresses retrieved", resp)
+}
+
+// @Summary      Add an address
+// @Description  Saves a delivery address. Its delivery zone is assigned from the coordinates, or the pincode when no coordinates are given.
+// @Tags         Public
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body  dto.CustomerAddressRequest  true  "Address details"
+// @Success      201  {object}  response.Response{data=dto.CustomerAddressResponse}
+// @Router       /public/my/addresses [post]
+func (h *PublicHandler) CreateMyAddress(c *gin.Context) {
+	var req dto.CustomerAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	customer, ok := h.requireCustomer(c)
+	if !ok {
+		return
+	}
+
+	address := mapCustomerAddressRequest(&req)
+	address.CustomerID = customer.ID
+	if err := h.addressService.Create(c.Request.Context(), address); err != nil {
+		if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Invalid address")
+		} else {
+			response.InternalErrorDebug(c, "Failed to save address", err)
+		}
+		return
+	}
+
+	response.Created(c, "Address saved", mapCustomerAddressResponse(address))
+}
+
+// @Summary      Update an address
+// @Description  Updates a saved delivery address and re-assigns its delivery zone.
+// @Tags         Public
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                         true  "Address ID"
+// @Param        request  body  dto.CustomerAddressRequest  true  "Address details"
+// @Success      200  {object}  response.Response{data=dto.CustomerAddressResponse}
+// @Router       /public/my/addresses/{id} [put]
+func (h *PublicHandler) UpdateMyAddress(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid address ID")
+		return
+	}
+
+	var req dto.CustomerAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	customer, ok := h.requireCustomer(c)
+	if !ok {
+		return
+	}
+
+	address := mapCustomerAddressRequest(&req)
+	address.ID = id
+	address.CustomerID = customer.ID
+	if err := h.addressService.Update(c.Request.Context(), address); err != nil {
+		switch err {
+		case domainErrors.ErrAddressNotFound:
+			response.NotFound(c, "Address not found")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Invalid address")
+		default:
+			response.InternalErrorDebug(c, "Failed to update address", err)
+		}
+		return
+	}
+
+	updated, err := h.addressService.GetForCustomer(c.Request.Context(), customer.ID, id)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch address", err)
+		return
+	}
+
+	response.OK(c, "Address updated", mapCustomerAddressResponse(updated))
+}
+
+// @Summary      Delete an address
+// @Description  Removes a saved delivery address.
+// @Tags         Public
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Address ID"
+// @Success      204  "No Content"
+// @Router       /public/my/addresses/{id} [delete]
+func (h *PublicHandler) DeleteMyAddress(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid address ID")
+		return
+	}
+
+	customer, ok := h.requireCustomer(c)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.Delete(c.Request.Context(), customer.ID, id); err != nil {
+		if err == domainErrors.ErrAddressNotFound {
+			response.NotFound(c, "Address not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to delete address", err)
+		}
+		return
+	}
+
+	response.NoContent(c)
 }
 
 // @Summary      Check serviceability
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// RecipeHandler handles recipe (bill of materials) API requests
+type RecipeHandler struct {
+	recipeService *service.RecipeService
+}
+
+// NewRecipeHandler creates a new recipe handler
+func NewRecipeHandler(recipeService *service.RecipeService) *RecipeHandler {
+	return &RecipeHandler{recipeService: recipeService}
+}
+
+// mapRecipeResponse maps a recipe entity to response DTO
+func mapRecipeResponse(r *entity.Recipe) dto.RecipeResponse {
+	resp := dto.RecipeResponse{
+		ID:              r.ID,
+		OutputVariantID: r.OutputVariantID,
+		OutputQty:       r.OutputQty,
+		ExpectedYield:   r.ExpectedYield,
+		YieldTolerance:  r.YieldTolerance,
+		Notes:           r.Notes,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+		Items:           []dto.RecipeItemResponse{},
+	}
+	if r.OutputVariant != nil {
+		resp.OutputVariantName = r.OutputVariant.Name
+		resp.OutputVariantUnit = r.OutputVariant.Unit
+	}
+	for _, item := range r.Items {
+		itemResp := dto.RecipeItemResponse{
+			ID:             item.ID,
+			InputVariantID: item.InputVariantID,
+			Quantity:       item.Quantity,
+		}
+		if item.InputVariant != nil {
+			itemResp.InputVariantName = item.InputVariant.Name
+			itemResp.InputVariantUnit = item.InputVariant.Unit
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+	return resp
+}
+
+func recipeFromRequest(variantID int64, req *dto.RecipeRequest) *entity.Recipe {
+	recipe := &entity.Recipe{
+		OutputVariantID: variantID,
+		OutputQty:       req.OutputQty,
+		ExpectedYield:   req.ExpectedYield,
+		YieldTolerance:  req.YieldTolerance,
+		Notes:           req.Notes,
+	}
+	for _, item := range req.Items {
+		recipe.Items = append(recipe.Items, entity.RecipeItem{
+			InputVariantID: item.InputVariantID,
+			Quantity:       item.Quantity,
+		})
+	}
+	return recipe
+}
+
+// handleRecipeError maps recipe service errors to HTTP responses
+func handleRecipeError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case domainErrors.ErrRecipeNotFound:
+		response.NotFound(c, "Recipe not found")
+	case domainErrors.ErrRecipeExists:
+		response.Conflict(c, "Recipe already exists for this product")
+	case domainErrors.ErrProductVariantNotFound:
+		response.NotFound(c, "Product or input variant not found")
+	case domainErrors.ErrVariantNotManufactured:
+		response.BadRequest(c, "Recipes can only be defined for manufactured products")
+	case domainErrors.ErrInvalidQuantity:
+		response.BadRequest(c, "Quantities must be greater than zero")
+	case domainErrors.ErrInvalidInput:
+		response.BadRequest(c, "Inputs must be unique, differ from the product, and yields cannot be negative")
+	default:
+		response.InternalErrorDebug(c, fallback, err)
+	}
+}
+
+// Get retrieves the recipe for a product
+// @Summary      Get product recipe
+// @Description  Returns the bill of materials for a manufactured product variant
+// @Tags         Products
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Product ID"
+// @Success      200  {object}  response.Response{data=dto.RecipeResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /products/{id}/recipe [get]
+func (h *RecipeHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID")
+		return
+	}
+
+	recipe, err := h.recipeService.GetByVariant(c.Request.Context(), id)
+	if err != nil {
+		handleRecipeError(c, err, "Failed to get recipe")
+		return
+	}
+
+	response.OK(c, "Recipe retrieved", mapRecipeResponse(recipe))
+}
+
+// Create creates the recipe for a product
+// @Summary      Create product recipe
+// @Description  Defines the input variants, standard quantities and expected yield for a manufactured product
+// @Tags         Products
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                true  "Product ID"
+// @Param        request  body  dto.RecipeRequest  true  "Recipe details"
+// @Success      201  {object}  response.Response{data=dto.RecipeResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /products/{id}/recipe [post]
+func (h *RecipeHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID")
+		return
+	}
+
+	var req dto.RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	recipe := recipeFromRequest(id, &req)
+	if err := h.recipeService.Create(c.Request.Context(), recipe); err != nil {
+		handleRecipeError(c, err, "Failed to create recipe")
+		return
+	}
+
+	created, err := h.recipeService.GetByVariant(c.Request.Context(), id)
+	if err != nil {
+		created = recipe
+	}
+	response.Created(c, "Recipe created", mapRecipeResponse(created))
+}
+
+// Update replaces the recipe for a product
+// @Summary      Update product recipe
+// @Description  Replaces the recipe quantities, yield expectations and input list for a product
+// @Tags         Products
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                true  "Product ID"
+// @Param        request  body  dto.RecipeRequest  true  "Recipe details"
+// @Success      200  {object}  response.Response{data=dto.RecipeResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /products/{id}/recipe [put]
+func (h *RecipeHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID")
+		return
+	}
+
+	var req dto.RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	recipe := recipeFromRequest(id, &req)
+	if err := h.recipeService.Update(c.Request.Context(), recipe); err != nil {
+		handleRecipeError(c, err, "Failed to update recipe")
+		return
+	}
+
+	updated, err := h.recipeService.GetByVariant(c.Request.Context(), id)
+	if err != nil {
+		updated = recipe
+	}
+	response.OK(c, "Recipe updated", mapRecipeResponse(updated))
+}
+
+// Delete deletes the recipe for a product
+// @Summary      Delete product recipe
+// @Description  Removes the bill of materials from a product variant
+// @Tags         Products
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Product ID"
+// @Success      204  "No Content"
+// @Failure      404  {object}  response.Response
+// @Router       /products/{id}/recipe [delete]
+func (h *RecipeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID")
+		return
+	}
+
+	if err := h.recipeService.Delete(c.Request.Context(), id); err != nil {
+		handleRecipeError(c, err, "Failed to delete recipe")
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/adapter/primary/http/middleware"
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// ReplenishmentHandler handles HTTP requests for reorder settings and purchase order suggestions
+type ReplenishmentHandler struct {
+	replenishmentService *service.ReplenishmentService
+}
+
+// NewReplenishmentHandler creates a new ReplenishmentHandler
+func NewReplenishmentHandler(replenishmentService *service.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{replenishmentService: replenishmentService}
+}
+
+// ListSettings godoc
+// @Summary      List reorder settings
+// @Description  Lists the min, reorder point and max levels set per warehouse and variant
+// @Tags         Replenishment
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int  false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.ReorderSettingResponse}
+// @Router       /inventory/reorder-settings [get]
+func (h *ReplenishmentHandler) ListSettings(c *gin.Context) {
+	warehouseID, ok := parseOptionalWarehouseID(c)
+	if !ok {
+		return
+	}
+	settings, err := h.replenishmentService.ListReorderSettings(c.Request.Context(), warehouseID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch reorder settings", err)
+		return
+	}
+	resp := make([]dto.ReorderSettingResponse, len(settings))
+	for i := range settings {
+		resp[i] = mapReorderSettingResponse(&settings[i])
+	}
+	response.OK(c, "Reorder settings retrieved", resp)
+}
+
+// SetSetting godoc
+// @Summary      Set reorder levels
+// @Description  Creates or replaces the min, reorder point, max and lead time of a variant at a warehouse
+// @Tags         Replenishment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.SetReorderSettingRequest  true  "Reorder levels"
+// @Success      200      {object}  response.Response{data=dto.ReorderSettingResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /inventory/reorder-settings [put]
+func (h *ReplenishmentHandler) SetSetting(c *gin.Context) {
+	var req dto.SetReorderSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	setting := &entity.ReorderSetting{
+		WarehouseID:  req.WarehouseID,
+		VariantID:    req.VariantID,
+		MinQuantity:  req.MinQuantity,
+		ReorderPoint: req.ReorderPoint,
+		MaxQuantity:  req.MaxQuantity,
+		LeadTimeDays: req.LeadTimeDays,
+	}
+	if err := h.replenishmentService.SetReorderSetting(c.Request.Context(), setting); err != nil {
+		switch err {
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Levels must satisfy 0 <= min_quantity <= reorder_point < max_quantity")
+		case domainErrors.ErrWarehouseNotFound:
+			response.NotFound(c, "Warehouse not found")
+		case domainErrors.ErrProductVariantNotFound:
+			response.NotFound(c, "Product variant not found")
+		default:
+			response.InternalErrorDebug(c, "Failed to save reorder setting", err)
+		}
+		return
+	}
+	response.OK(c, "Reorder setting saved", mapReorderSettingResponse(setting))
+}
+
+// DeleteSetting godoc
+// @Summary      Remove reorder levels
+// @Description  Stops replenishing a variant at a warehouse
+// @Tags         Replenishment
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouseId  path  int  true  "Warehouse ID"
+// @Param        variantId    path  int  true  "Variant ID"
+// @Success      200  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /inventory/reorder-settings/{warehouseId}/{variantId} [delete]
+func (h *ReplenishmentHandler) DeleteSetting(c *gin.Context) {
+	warehouseID, err := strconv.ParseInt(c.Param("warehouseId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid warehouse ID")
+		return
+	}
+	variantID, err := strconv.ParseInt(c.Param("variantId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+	if err := h.replenishmentService.DeleteReorderSetting(c.Request.Context(), warehouseID, variantID); err != nil {
+		if err == domainErrors.ErrReorderSettingNotFound {
+			response.NotFound(c, "Reorder setting not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to delete reorder setting", err)
+		return
+	}
+	response.OK(c, "Reorder setting deleted", nil)
+}
+
+// Suggestions godoc
+// @Summary      Purchase order suggestions
+// @Description  Lists variants whose stock on hand less reservations, plus on order and in transit, is at or below the reorder point, with the quantity to order up to the max plus lead-time demand, and the preferred supplier
+// @Tags         Replenishment
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int  false  "Warehouse ID"
+// @Success      200  {object}  response.Response{data=[]dto.ReplenishmentSuggestionResponse}
+// @Router       /procurements/suggestions [get]
+func (h *ReplenishmentHandler) Suggestions(c *gin.Context) {
+	warehouseID, ok := parseOptionalWarehouseID(c)
+	if !ok {
+		return
+	}
+	suggestions, err := h.replenishmentService.Suggest(c.Request.Context(), warehouseID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to build purchase order suggestions", err)
+		return
+	}
+	resp := make([]dto.ReplenishmentSuggestionResponse, len(suggestions))
+	for i := range suggestions {
+		resp[i] = mapReplenishmentSuggestionResponse(&suggestions[i])
+	}
+	response.OK(c, "Purchase order suggestions retrieved", resp)
+}
+
+// GenerateDraftOrders godoc
+// @Summary      Raise draft purchase orders
+// @Description  Turns the current suggestions into pending purchase orders, one per supplier and warehouse, for a buyer to approve. Suggestions without a preferred supplier are returned as unsourced.
+// @Tags         Replenishment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.GenerateDraftOrdersRequest  false  "Warehouse to replenish"
+// @Success      201      {object}  response.Response{data=dto.ReplenishmentRunResponse}
+// @Router       /procurements/suggestions/generate [post]
+func (h *ReplenishmentHandler) GenerateDraftOrders(c *gin.Context) {
+	var req dto.GenerateDraftOrdersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	run, err := h.replenishmentService.GenerateDraftOrders(c.Request.Context(), req.WarehouseID, userID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to raise draft purchase orders", err)
+		return
+	}
+
+	resp := dto.ReplenishmentRunResponse{
+		Procurements: make([]dto.ProcurementResponse, len(run.Procurements)),
+		Unsourced:    make([]dto.ReplenishmentSuggestionResponse, len(run.Unsourced)),
+	}
+	for i := range run.Procurements {
+		resp.Procurements[i] = mapProcurementResponse(&run.Procurements[i])
+	}
+	for i := range run.Unsourced {
+		resp.Unsourced[i] = mapReplenishmentSuggestionResponse(&run.Unsourced[i])
+	}
+	response.Created(c, "Draft purchase orders raised", resp)
+}
+
+// parseOptionalWarehouseID reads the warehouse_id query parameter, writing a 400 if it is malformed
+func parseOptionalWarehouseID(c *gin.Context) (*int64, bool) {
+	w := c.Query("warehouse_id")
+	if w == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(w, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid warehouse ID")
+		return nil, false
+	}
+	return &id, true
+}
+
+func mapReorderSettingResponse(s *entity.ReorderSetting) dto.ReorderSettingResponse {
+	return dto.ReorderSettingResponse{
+		ID:            s.ID,
+		WarehouseID:   s.WarehouseID,
+		WarehouseName: s.WarehouseName,
+		VariantID:     s.VariantID,
+		VariantName:   s.VariantName,
+		SKU:           s.SKU,
+		MinQuantity:   s.MinQuantity,
+		ReorderPoint:  s.ReorderPoint,
+		MaxQuantity:   s.MaxQuantity,
+		LeadTimeDays:  s.LeadTimeDays,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+func mapReplenishmentSuggestionResponse(s *entity.ReplenishmentSuggestion) dto.ReplenishmentSuggestionResponse {
+	return dto.ReplenishmentSuggestionResponse{
+		WarehouseID:       s.Setting.WarehouseID,
+		WarehouseName:     s.Setting.WarehouseName,
+		VariantID:         s.Setting.VariantID,
+		VariantName:       s.Setting.VariantName,
+		SKU:               s.Setting.SKU,
+		OnHand:            s.OnHand,
+		Reserved:          s.Reserved,
+		OnOrder:           s.OnOrder,
+		InTransit:         s.InTransit,
+		Projected:         s.Projected(),
+		ReorderPoint:      s.Setting.ReorderPoint,
+		MaxQuantity:       s.Setting.MaxQuantity,
+		LeadTimeDays:      s.Setting.LeadTimeDays,
+		AvgDailySales:     s.AvgDailySales,
+		DaysOfCover:       s.DaysOfCover,
+		SuggestedQuantity: s.SuggestedQuantity,
+		SupplierID:        s.SupplierID,
+		SupplierName:      s.SupplierName,
+		UnitCost:          s.UnitCost,
+	}
+}
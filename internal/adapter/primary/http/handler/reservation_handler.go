@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	"github.com/qwikshelf/api/internal/domain/repository"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// ReservationHandler handles HTTP requests for stock reservations and available-to-promise stock
+type ReservationHandler struct {
+	reservationService *service.StockReservationService
+}
+
+// NewReservationHandler creates a new ReservationHandler
+func NewReservationHandler(reservationService *service.StockReservationService) *ReservationHandler {
+	return &ReservationHandler{reservationService: reservationService}
+}
+
+// List godoc
+// @Summary      List stock reservations
+// @Description  Returns the stock held for placed orders and subscription deliveries, newest first
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Param        variant_id    query  int     false  "Variant ID"
+// @Param        source_type   query  string  false  "order or subscription"
+// @Param        reference_id  query  int     false  "Sale or subscription ID"
+// @Param        status        query  string  false  "active, fulfilled, released or expired"
+// @Param        reserved_for  query  string  false  "Delivery date (YYYY-MM-DD)"
+// @Param        page          query  int     false  "Page number"    default(1)
+// @Param        per_page      query  int     false  "Items per page" default(20)
+// @Success      200  {object}  response.Response{data=[]dto.StockReservationResponse}
+// @Router       /inventory/reservations [get]
+func (h *ReservationHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var filter repository.ReservationFilter
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &wid
+	}
+	if vid, err := strconv.ParseInt(c.Query("variant_id"), 10, 64); err == nil {
+		filter.VariantID = &vid
+	}
+	if rid, err := strconv.ParseInt(c.Query("reference_id"), 10, 64); err == nil {
+		filter.ReferenceID = &rid
+	}
+	switch src := entity.ReservationSourceType(c.Query("source_type")); src {
+	case entity.ReservationSourceOrder, entity.ReservationSourceSubscription:
+		filter.SourceType = &src
+	}
+	switch st := entity.ReservationStatus(c.Query("status")); st {
+	case entity.ReservationStatusActive, entity.ReservationStatusFulfilled, entity.ReservationStatusReleased, entity.ReservationStatusExpired:
+		filter.Status = &st
+	}
+	if d := c.Query("reserved_for"); d != "" {
+		day, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			response.BadRequest(c, "Invalid reserved_for date, expected YYYY-MM-DD")
+			return
+		}
+		filter.ReservedFor = &day
+	}
+
+	reservations, total, err := h.reservationService.List(c.Request.Context(), filter, (page-1)*perPage, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch stock reservations", err)
+		return
+	}
+
+	resp := make([]dto.StockReservationResponse, len(reservations))
+	for i := range reservations {
+		resp[i] = mapStockReservationResponse(&reservations[i])
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Stock reservations retrieved", resp, &response.Meta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages})
+}
+
+// Availability godoc
+// @Summary      Available-to-promise stock
+// @Description  Returns the stock on hand per warehouse and variant, the part of it in expired lots or held by reservations, and what is left to sell
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int  false  "Warehouse ID"
+// @Param        variant_id    query  int  false  "Variant ID"
+// @Success      200  {object}  response.Response{data=[]dto.StockAvailabilityResponse}
+// @Router       /inventory/availability [get]
+func (h *ReservationHandler) Availability(c *gin.Context) {
+	warehouseID, ok := parseOptionalWarehouseID(c)
+	if !ok {
+		return
+	}
+	filter := repository.AvailabilityFilter{WarehouseID: warehouseID}
+	if v := c.Query("variant_id"); v != "" {
+		vid, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid variant ID")
+			return
+		}
+		filter.VariantIDs = []int64{vid}
+	}
+
+	positions, err := h.reservationService.Availability(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch stock availability", err)
+		return
+	}
+
+	resp := make([]dto.StockAvailabilityResponse, len(positions))
+	for i := range positions {
+		a := &positions[i]
+		resp[i] = dto.StockAvailabilityResponse{
+			WarehouseID: a.WarehouseID,
+			VariantID:   a.VariantID,
+			VariantName: a.VariantName,
+			SKU:         a.SKU,
+			OnHand:      a.OnHand,
+			Expired:     a.Expired,
+			Reserved:    a.Reserved,
+			Available:   a.Available(),
+		}
+	}
+	response.OK(c, "Stock availability retrieved", resp)
+}
+
+// ReserveRoster godoc
+// @Summary      Reserve stock for a delivery roster
+// @Description  Holds stock for the subscription deliveries due on a date (default tomorrow) at the warehouse serving each subscription. Re-running replaces the date's reservations. Subscriptions without a serving warehouse or with too little stock are listed and reserve nothing.
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.ReserveRosterRequest  false  "Roster date"
+// @Success      200      {object}  response.Response{data=dto.RosterReservationResponse}
+// @Failure      400      {object}  response.Response
+// @Router       /inventory/reservations/roster [post]
+func (h *ReservationHandler) ReserveRoster(c *gin.Context) {
+	var req dto.ReserveRosterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	date := time.Now().AddDate(0, 0, 1)
+	if req.Date != "" {
+		d, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			response.BadRequest(c, "Invalid date, expected YYYY-MM-DD")
+			return
+		}
+		date = d
+	}
+
+	run, err := h.reservationService.ReserveRoster(c.Request.Context(), date)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to reserve roster stock", err)
+		return
+	}
+
+	resp := dto.RosterReservationResponse{
+		Date:         run.Date.Format("2006-01-02"),
+		Reservations: make([]dto.StockReservationResponse, len(run.Reservations)),
+		Unassigned:   run.Unassigned,
+		Short:        run.Short,
+	}
+	for i := range run.Reservations {
+		resp.Reservations[i] = mapStockReservationResponse(&run.Reservations[i])
+	}
+	response.OK(c, "Roster stock reserved", resp)
+}
+
+// Expire godoc
+// @Summary      Expire lapsed reservations
+// @Description  Marks active reservations past their expiry time as expired. Lapsed reservations stop holding stock even before they are swept.
+// @Tags         Inventory
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=dto.ExpireReservationsResponse}
+// @Router       /inventory/reservations/expire [post]
+func (h *ReservationHandler) Expire(c *gin.Context) {
+	expired, err := h.reservationService.ExpireStale(c.Request.Context())
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to expire reservations", err)
+		return
+	}
+	response.OK(c, "Lapsed reservations expired", dto.ExpireReservationsResponse{Expired: expired})
+}
+
+func mapStockReservationResponse(r *entity.StockReservation) dto.StockReservationResponse {
+	resp := dto.StockReservationResponse{
+		ID:          r.ID,
+		WarehouseID: r.WarehouseID,
+		VariantID:   r.VariantID,
+		VariantName: r.VariantName,
+		SKU:         r.SKU,
+		Quantity:    r.Quantity,
+		SourceType:  string(r.SourceType),
+		ReferenceID: r.ReferenceID,
+		Status:      string(r.Status),
+		ExpiresAt:   r.ExpiresAt,
+		CreatedAt:   r.CreatedAt,
+		ClosedAt:    r.ClosedAt,
+	}
+	if r.ReservedFor != nil {
+		d := r.ReservedFor.Format("2006-01-02")
+		resp.ReservedFor = &d
+	}
+	return resp
+}
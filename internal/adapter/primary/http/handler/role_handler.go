@@ -41,9 +41,10 @@ func (h *RoleHandler) List(c *gin.Context) {
 	var resp []dto.RoleResponse
 	for _, r := range roles {
 		resp = append(resp, dto.RoleResponse{
-			ID:          r.ID,
-			Name:        r.Name,
-			Description: r.Description,
+			ID:              r.ID,
+			Name:            r.Name,
+			Description:     r.Description,
+			POApprovalLimit: r.POApprovalLimit,
 		})
 	}
 
@@ -77,9 +78,10 @@ func (h *RoleHandler) Create(c *gin.Context) {
 	}
 
 	resp := dto.RoleResponse{
-		ID:          role.ID,
-		Name:        role.Name,
-		Description: role.Description,
+		ID:              role.ID,
+		Name:            role.Name,
+		Description:     role.Description,
+		POApprovalLimit: role.POApprovalLimit,
 	}
 
 	response.Created(c, "Role created", resp)
@@ -111,9 +113,10 @@ func (h *RoleHandler) Get(c *gin.Context) {
 	}
 
 	resp := dto.RoleResponse{
-		ID:          role.ID,
-		Name:        role.Name,
-		Description: role.Description,
+		ID:              role.ID,
+		Name:            role.Name,
+		Description:     role.Description,
+		POApprovalLimit: role.POApprovalLimit,
 	}
 
 	for _, p := range permissions {
@@ -174,14 +177,66 @@ func (h *RoleHandler) Update(c *gin.Context) {
 	}
 
 	resp := dto.RoleResponse{
-		ID:          role.ID,
-		Name:        role.Name,
-		Description: role.Description,
+		ID:              role.ID,
+		Name:            role.Name,
+		Description:     role.Description,
+		POApprovalLimit: role.POApprovalLimit,
 	}
 
 	response.OK(c, "Role updated", resp)
 }
 
+// SetApprovalLimit sets the purchase order approval limit of a role
+// @Summary      Set role approval limit
+// @Description  Sets the highest purchase order total users of the role may approve. A null limit lets the role approve orders of any amount.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                              true  "Role ID"
+// @Param        request  body      dto.SetRoleApprovalLimitRequest  true  "Approval limit"
+// @Success      200      {object}  response.Response{data=dto.RoleResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /roles/{id}/approval-limit [put]
+func (h *RoleHandler) SetApprovalLimit(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID")
+		return
+	}
+
+	var req dto.SetRoleApprovalLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	role, err := h.roleService.SetApprovalLimit(c.Request.Context(), id, req.POApprovalLimit)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrRoleNotFound:
+			response.NotFound(c, "Role not found")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Approval limit cannot be negative")
+		default:
+			response.InternalErrorDebug(c, "Failed to set approval limit", err)
+		}
+		return
+	}
+
+	resp := dto.RoleResponse{
+		ID:              role.ID,
+		Name:            role.Name,
+		Description:     role.Description,
+		POApprovalLimit: role.POApprovalLimit,
+	}
+
+	response.OK(c, "Approval limit updated", resp)
+}
+
 // Delete deletes a role
 // @Summary      Delete role
 // @Description  Deletes a role by ID
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -33,9 +34,15 @@ func mapSaleResponse(s *entity.Sale) dto.SaleResponse {
 		TotalAmount:       s.TotalAmount,
 		TaxAmount:         s.TaxAmount,
 		DiscountAmount:    s.DiscountAmount,
+		DeliveryCharge:    s.DeliveryCharge,
 		PaymentMethod:     s.PaymentMethod,
+		Status:            string(s.Status),
+		ZoneID:            s.ZoneID,
 		ProcessedByUserID: s.ProcessedByUserID,
 		CreatedAt:         s.CreatedAt,
+		CostOfGoods:       s.CostOfGoods,
+		GrossMargin:       s.GrossMargin(),
+		DeliveryAddress:   mapDeliveryAddress(s.DeliveryAddress),
 	}
 
 	if s.Warehouse != nil {
@@ -47,11 +54,12 @@ func mapSaleResponse(s *entity.Sale) dto.SaleResponse {
 
 	for _, item := range s.Items {
 		itemResp := dto.SaleItemResponse{
-			ID:        item.ID,
-			VariantID: item.VariantID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
-			LineTotal: item.LineTotal,
+			ID:          item.ID,
+			VariantID:   item.VariantID,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			LineTotal:   item.LineTotal,
+			CostOfGoods: item.CostOfGoods,
 		}
 		if item.Variant != nil {
 			itemResp.VariantName = item.Variant.Name
@@ -63,6 +71,23 @@ func mapSaleResponse(s *entity.Sale) dto.SaleResponse {
 	return resp
 }
 
+// mapDeliveryAddress maps a storefront order's delivery address to its response DTO
+func mapDeliveryAddress(a *entity.SaleDeliveryAddress) *dto.DeliveryAddressResponse {
+	if a == nil {
+		return nil
+	}
+	return &dto.DeliveryAddressResponse{
+		CustomerID:   a.CustomerID,
+		ContactName:  a.ContactName,
+		Phone:        a.Phone,
+		AddressLine1: a.AddressLine1,
+		AddressLine2: a.AddressLine2,
+		Landmark:     a.Landmark,
+		City:         a.City,
+		Pincode:      a.Pincode,
+	}
+}
+
 // Create processes a new sale (POS)
 // @Summary      Process sale
 // @Description  Creates a new sale and deducts inventory
@@ -90,13 +115,15 @@ func (h *SaleHandler) Create(c *gin.Context) {
 		return
 	}
 
+	processedBy := userID.(int64)
 	sale := &entity.Sale{
 		WarehouseID:       req.WarehouseID,
+		CustomerID:        req.CustomerID,
 		CustomerName:      req.CustomerName,
 		TaxAmount:         req.TaxAmount,
 		DiscountAmount:    req.DiscountAmount,
 		PaymentMethod:     req.PaymentMethod,
-		ProcessedByUserID: userID.(int64),
+		ProcessedByUserID: &processedBy,
 	}
 
 	for _, item := range req.Items {
@@ -114,6 +141,18 @@ func (h *SaleHandler) Create(c *gin.Context) {
 			response.NotFound(c, "One or more products not found")
 		} else if err == domainErrors.ErrInsufficientStock {
 			response.BadRequest(c, "Insufficient stock for one or more items")
+		} else if err == domainErrors.ErrStockExpired {
+			response.BadRequest(c, "Only expired stock is available for one or more items")
+		} else if err == domainErrors.ErrStockReserved {
+			response.BadRequest(c, "Stock for one or more items is reserved for orders and subscriptions")
+		} else if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Credit sales require a customer")
+		} else if err == domainErrors.ErrCustomerNotFound {
+			response.NotFound(c, "Customer not found")
+		} else if err == domainErrors.ErrCreditNotAllowed {
+			response.BadRequest(c, "Customer is not allowed to buy on credit")
+		} else if err == domainErrors.ErrCreditLimitExceeded {
+			response.Error(c, http.StatusUnprocessableEntity, "CREDIT_LIMIT_EXCEEDED", "Sale would exceed the customer's credit limit")
 		} else {
 			response.InternalErrorDebug(c, "Failed to process sale", err)
 		}
@@ -172,6 +211,11 @@ func (h *SaleHandler) List(c *gin.Context) {
 		}
 	}
 
+	var status *entity.OrderStatus
+	if st := entity.OrderStatus(c.Query("status")); st.IsValid() {
+		status = &st
+	}
+
 	var customerID *int64
 	if cid, err := strconv.ParseInt(c.Query("customer_id"), 10, 64); err == nil {
 		customerID = &cid
@@ -189,7 +233,7 @@ func (h *SaleHandler) List(c *gin.Context) {
 	if customerID != nil {
 		sales, total, err = h.saleService.ListByCustomer(c.Request.Context(), *customerID, offset, perPage)
 	} else {
-		sales, total, err = h.saleService.List(c.Request.Context(), warehouseID, startDate, endDate, offset, perPage)
+		sales, total, err = h.saleService.List(c.Request.Context(), warehouseID, status, startDate, endDate, offset, perPage)
 	}
 
 	if err != nil {
@@ -209,3 +253,97 @@ func (h *SaleHandler) List(c *gin.Context) {
 		TotalPages: (int(total) + perPage - 1) / perPage,
 	})
 }
+
+// mapOrderTimeline maps status history entities to response DTOs
+func mapOrderTimeline(events []entity.OrderStatusEvent) []dto.OrderStatusEventResponse {
+	timeline := []dto.OrderStatusEventResponse{}
+	for _, e := range events {
+		timeline = append(timeline, dto.OrderStatusEventResponse{
+			Status:          string(e.Status),
+			Note:            e.Note,
+			ChangedByUserID: e.ChangedByUserID,
+			ChangedAt:       e.ChangedAt,
+		})
+	}
+	return timeline
+}
+
+// UpdateStatus moves an order through its lifecycle
+// @Summary      Update order status
+// @Description  Moves an order along placed → confirmed → packed → out_for_delivery → delivered, or to cancelled/returned. Cancelling or returning an order puts its items back in stock.
+// @Tags         Sales
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                           true  "Sale ID"
+// @Param        request  body  dto.UpdateOrderStatusRequest  true  "New status"
+// @Success      200  {object}  response.Response{data=dto.SaleResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /sales/{id}/status [patch]
+func (h *SaleHandler) UpdateStatus(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid sale ID")
+		return
+	}
+
+	var req dto.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	var changedBy *int64
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int64)
+		changedBy = &uid
+	}
+
+	sale, err := h.saleService.UpdateOrderStatus(c.Request.Context(), id, entity.OrderStatus(req.Status), changedBy, req.Note)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrNotFound:
+			response.NotFound(c, "Sale not found")
+		case domainErrors.ErrInvalidStatusTransition:
+			response.Conflict(c, "Order cannot move to the requested status from its current status")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Invalid status")
+		case domainErrors.ErrInsufficientStock, domainErrors.ErrStockReserved:
+			response.BadRequest(c, "Not enough stock to pack one or more items")
+		case domainErrors.ErrStockExpired:
+			response.BadRequest(c, "Only expired stock is available to pack one or more items")
+		default:
+			response.InternalErrorDebug(c, "Failed to update order status", err)
+		}
+		return
+	}
+
+	response.OK(c, "Order status updated", mapSaleResponse(sale))
+}
+
+// GetHistory returns the status timeline of an order
+// @Summary      Get order status history
+// @Description  Returns every status transition of an order with timestamps
+// @Tags         Sales
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Sale ID"
+// @Success      200  {object}  response.Response{data=[]dto.OrderStatusEventResponse}
+// @Failure      500  {object}  response.Response
+// @Router       /sales/{id}/history [get]
+func (h *SaleHandler) GetHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid sale ID")
+		return
+	}
+
+	events, err := h.saleService.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to get order history", err)
+		return
+	}
+
+	response.OK(c, "Order history retrieved", mapOrderTimeline(events))
+}
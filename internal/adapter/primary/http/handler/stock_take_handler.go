@@ -0,0 +1,448 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/adapter/primary/http/middleware"
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// StockTakeHandler handles HTTP requests for stock-take sessions and cycle counts
+type StockTakeHandler struct {
+	stockTakeService *service.StockTakeService
+}
+
+// NewStockTakeHandler creates a new StockTakeHandler
+func NewStockTakeHandler(stockTakeService *service.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{stockTakeService: stockTakeService}
+}
+
+// Start godoc
+// @Summary      Start a stock take
+// @Description  Opens a counting session at a warehouse and snapshots the expected quantities. A cycle count covers only the due items of one ABC class.
+// @Tags         Stock Takes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.StartStockTakeRequest  true  "Session details"
+// @Success      201      {object}  response.Response{data=dto.StockTakeResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /stock-takes [post]
+func (h *StockTakeHandler) Start(c *gin.Context) {
+	var req dto.StartStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	st := &entity.StockTake{
+		WarehouseID: req.WarehouseID,
+		Scope:       entity.StockTakeScope(req.Scope),
+		Notes:       req.Notes,
+	}
+	if req.ABCClass != nil {
+		class := entity.ABCClass(*req.ABCClass)
+		st.ABCClass = &class
+	}
+	if uid := middleware.GetUserID(c); uid != 0 {
+		st.StartedByUserID = &uid
+	}
+
+	if err := h.stockTakeService.Start(c.Request.Context(), st); err != nil {
+		switch {
+		case err == domainErrors.ErrWarehouseNotFound:
+			response.NotFound(c, "Warehouse not found")
+		case err == domainErrors.ErrStockTakeInProgress:
+			response.Conflict(c, "Warehouse already has an open stock take")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalErrorDebug(c, "Failed to start stock take", err)
+		}
+		return
+	}
+
+	// Reload to return the snapshot lines
+	created, err := h.stockTakeService.GetByID(c.Request.Context(), st.ID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch stock take", err)
+		return
+	}
+	response.Created(c, "Stock take started", mapStockTakeResponse(created, true))
+}
+
+// List godoc
+// @Summary      List stock takes
+// @Description  Returns stock-take sessions, optionally filtered by warehouse and status
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int     false  "Warehouse ID"
+// @Param        status        query  string  false  "open, posted or cancelled"
+// @Param        page          query  int     false  "Page number"    default(1)
+// @Param        per_page      query  int     false  "Items per page" default(20)
+// @Success      200  {object}  response.Response{data=[]dto.StockTakeResponse}
+// @Router       /stock-takes [get]
+func (h *StockTakeHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var filter repository.StockTakeFilter
+	if wid, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64); err == nil {
+		filter.WarehouseID = &wid
+	}
+	switch st := entity.StockTakeStatus(c.Query("status")); st {
+	case entity.StockTakeStatusOpen, entity.StockTakeStatusPosted, entity.StockTakeStatusCancelled:
+		filter.Status = &st
+	}
+
+	stockTakes, total, err := h.stockTakeService.List(c.Request.Context(), filter, (page-1)*perPage, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch stock takes", err)
+		return
+	}
+
+	resp := []dto.StockTakeResponse{}
+	for i := range stockTakes {
+		resp = append(resp, mapStockTakeResponse(&stockTakes[i], false))
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Stock takes retrieved", resp, &response.Meta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages})
+}
+
+// Get godoc
+// @Summary      Get a stock take
+// @Description  Returns a stock-take session with its expected and counted quantities
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Stock take ID"
+// @Success      200  {object}  response.Response{data=dto.StockTakeResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /stock-takes/{id} [get]
+func (h *StockTakeHandler) Get(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+	st, err := h.stockTakeService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to fetch stock take")
+		return
+	}
+	response.OK(c, "Stock take retrieved", mapStockTakeResponse(st, true))
+}
+
+// RecordCount godoc
+// @Summary      Record a count
+// @Description  Records the counted quantity of a variant, identified by variant_id or barcode. Use mode=add to accumulate barcode scans.
+// @Tags         Stock Takes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true  "Stock take ID"
+// @Param        request  body      dto.RecordStockCountRequest  true  "Count"
+// @Success      200      {object}  response.Response{data=dto.StockTakeLineResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /stock-takes/{id}/counts [post]
+func (h *StockTakeHandler) RecordCount(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+	var req dto.RecordStockCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	var userID *int64
+	if uid := middleware.GetUserID(c); uid != 0 {
+		userID = &uid
+	}
+
+	line, err := h.stockTakeService.RecordCount(c.Request.Context(), id, req.VariantID, req.Barcode, req.Quantity, req.Mode == "add", userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to record count")
+		return
+	}
+	response.OK(c, "Count recorded", mapStockTakeLineResponse(line))
+}
+
+// Variance godoc
+// @Summary      Stock take variance report
+// @Description  Compares counted quantities with the snapshot plus the stock moved between the snapshot and each count, and values the differences at cost price
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Stock take ID"
+// @Success      200  {object}  response.Response{data=dto.StockTakeVarianceResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /stock-takes/{id}/variance [get]
+func (h *StockTakeHandler) Variance(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+	report, err := h.stockTakeService.Variance(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to build variance report")
+		return
+	}
+	response.OK(c, "Variance report retrieved", mapVarianceResponse(report))
+}
+
+// Post godoc
+// @Summary      Post a stock take
+// @Description  Posts every counted variance, net of stock moved since the snapshot, as an approved count-correction adjustment in one transaction and closes the session
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Stock take ID"
+// @Success      200  {object}  response.Response{data=dto.StockTakeVarianceResponse}
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /stock-takes/{id}/post [post]
+func (h *StockTakeHandler) Post(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	report, err := h.stockTakeService.Post(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to post stock take")
+		return
+	}
+	response.OK(c, "Stock take posted", mapVarianceResponse(report))
+}
+
+// Cancel godoc
+// @Summary      Cancel a stock take
+// @Description  Abandons an open session without changing stock
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Stock take ID"
+// @Success      200  {object}  response.Response{data=dto.StockTakeResponse}
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /stock-takes/{id}/cancel [post]
+func (h *StockTakeHandler) Cancel(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "Not authenticated")
+		return
+	}
+
+	st, err := h.stockTakeService.Cancel(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to cancel stock take")
+		return
+	}
+	response.OK(c, "Stock take cancelled", mapStockTakeResponse(st, false))
+}
+
+// CycleCountPlan godoc
+// @Summary      Cycle count plan
+// @Description  Ranks the variants stocked at a warehouse into ABC classes by consumption value over the last 90 days and shows when each is due for counting
+// @Tags         Stock Takes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id  query  int   true   "Warehouse ID"
+// @Param        due_only      query  bool  false  "Only items due now"
+// @Success      200  {object}  response.Response{data=dto.CycleCountPlanResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /stock-takes/cycle-counts [get]
+func (h *StockTakeHandler) CycleCountPlan(c *gin.Context) {
+	warehouseID, err := strconv.ParseInt(c.Query("warehouse_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid warehouse ID")
+		return
+	}
+	dueOnly := c.Query("due_only") == "true"
+
+	now := time.Now()
+	items, err := h.stockTakeService.CycleCountPlan(c.Request.Context(), warehouseID, now)
+	if err != nil {
+		h.handleError(c, err, "Failed to build cycle count plan")
+		return
+	}
+	schedules, err := h.stockTakeService.CycleSchedules(c.Request.Context(), warehouseID)
+	if err != nil {
+		h.handleError(c, err, "Failed to fetch cycle count schedules")
+		return
+	}
+
+	resp := dto.CycleCountPlanResponse{
+		WarehouseID: warehouseID,
+		Schedules:   []dto.CycleCountScheduleResponse{},
+		Items:       []dto.CycleCountItemResponse{},
+	}
+	for _, s := range schedules {
+		resp.Schedules = append(resp.Schedules, dto.CycleCountScheduleResponse{ABCClass: s.ABCClass, IntervalDays: s.IntervalDays})
+	}
+	for i := range items {
+		item := &items[i]
+		due := item.IsDue(now)
+		if dueOnly && !due {
+			continue
+		}
+		resp.Items = append(resp.Items, dto.CycleCountItemResponse{
+			VariantID:        item.VariantID,
+			VariantName:      item.VariantName,
+			SKU:              item.SKU,
+			ConsumptionValue: item.ConsumptionValue,
+			ABCClass:         item.ABCClass,
+			LastCountedAt:    item.LastCountedAt,
+			DueAt:            item.DueAt,
+			IsDue:            due,
+		})
+	}
+	response.OK(c, "Cycle count plan retrieved", resp)
+}
+
+// SetCycleSchedule godoc
+// @Summary      Set cycle count schedule
+// @Description  Sets how many days apart items of an ABC class are counted at a warehouse (defaults: A 30, B 90, C 180)
+// @Tags         Stock Takes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.SetCycleScheduleRequest  true  "Schedule"
+// @Success      200      {object}  response.Response{data=dto.CycleCountScheduleResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /stock-takes/cycle-counts/schedules [put]
+func (h *StockTakeHandler) SetCycleSchedule(c *gin.Context) {
+	var req dto.SetCycleScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	schedule := &entity.CycleCountSchedule{
+		WarehouseID:  req.WarehouseID,
+		ABCClass:     entity.ABCClass(req.ABCClass),
+		IntervalDays: req.IntervalDays,
+	}
+	if err := h.stockTakeService.SetCycleSchedule(c.Request.Context(), schedule); err != nil {
+		h.handleError(c, err, "Failed to save cycle count schedule")
+		return
+	}
+	response.OK(c, "Cycle count schedule saved", dto.CycleCountScheduleResponse{ABCClass: schedule.ABCClass, IntervalDays: schedule.IntervalDays})
+}
+
+func (h *StockTakeHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case err == domainErrors.ErrStockTakeNotFound:
+		response.NotFound(c, "Stock take not found")
+	case err == domainErrors.ErrWarehouseNotFound:
+		response.NotFound(c, "Warehouse not found")
+	case err == domainErrors.ErrProductVariantNotFound:
+		response.NotFound(c, "Product variant not found")
+	case err == domainErrors.ErrStockTakeNotOpen:
+		response.Conflict(c, "Stock take is not open")
+	case err == domainErrors.ErrInvalidQuantity:
+		response.BadRequest(c, "Quantity cannot be negative")
+	case errors.Is(err, domainErrors.ErrInvalidInput):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalErrorDebug(c, message, err)
+	}
+}
+
+func parseStockTakeID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid stock take ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func mapStockTakeResponse(st *entity.StockTake, withLines bool) dto.StockTakeResponse {
+	resp := dto.StockTakeResponse{
+		ID:              st.ID,
+		WarehouseID:     st.WarehouseID,
+		WarehouseName:   st.WarehouseName,
+		Scope:           st.Scope,
+		ABCClass:        st.ABCClass,
+		Status:          st.Status,
+		Notes:           st.Notes,
+		StartedByUserID: st.StartedByUserID,
+		StartedAt:       st.StartedAt,
+		ClosedByUserID:  st.ClosedByUserID,
+		ClosedAt:        st.ClosedAt,
+	}
+	if !withLines {
+		return resp
+	}
+	resp.Lines = []dto.StockTakeLineResponse{}
+	for i := range st.Lines {
+		resp.Lines = append(resp.Lines, mapStockTakeLineResponse(&st.Lines[i]))
+	}
+	return resp
+}
+
+func mapStockTakeLineResponse(l *entity.StockTakeLine) dto.StockTakeLineResponse {
+	return dto.StockTakeLineResponse{
+		ID:               l.ID,
+		VariantID:        l.VariantID,
+		VariantName:      l.VariantName,
+		SKU:              l.SKU,
+		ExpectedQuantity: l.ExpectedQuantity,
+		MovedQuantity:    l.MovedQuantity,
+		BookQuantity:     l.BookQuantity(),
+		CountedQuantity:  l.CountedQuantity,
+		Variance:         l.Variance(),
+		VarianceValue:    l.VarianceValue(),
+		CountedByUserID:  l.CountedByUserID,
+		CountedAt:        l.CountedAt,
+		AdjustmentID:     l.AdjustmentID,
+	}
+}
+
+func mapVarianceResponse(r *entity.StockTakeVarianceReport) dto.StockTakeVarianceResponse {
+	return dto.StockTakeVarianceResponse{
+		StockTake:      mapStockTakeResponse(r.StockTake, true),
+		CountedLines:   r.CountedLines,
+		UncountedLines: r.UncountedLines,
+		VarianceLines:  r.VarianceLines,
+		ShortageValue:  r.ShortageValue,
+		SurplusValue:   r.SurplusValue,
+		NetValue:       r.NetValue,
+	}
+}
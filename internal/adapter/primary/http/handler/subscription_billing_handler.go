@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// SubscriptionBillingHandler handles HTTP requests for subscription invoices
+type SubscriptionBillingHandler struct {
+	billingService *service.SubscriptionBillingService
+}
+
+// NewSubscriptionBillingHandler creates a new SubscriptionBillingHandler
+func NewSubscriptionBillingHandler(billingService *service.SubscriptionBillingService) *SubscriptionBillingHandler {
+	return &SubscriptionBillingHandler{billingService: billingService}
+}
+
+// RunBilling godoc
+// @Summary      Run subscription billing
+// @Description  Invoices delivered subscription items for a month that are not on an invoice yet. A customer already invoiced for the month gets a supplementary invoice for deliveries recorded since and is skipped when there are none, so the run can be repeated safely.
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.RunSubscriptionBillingRequest  true  "Billing period"
+// @Success      200      {object}  response.Response{data=dto.SubscriptionBillingRunResponse}
+// @Failure      400      {object}  response.Response
+// @Router       /subscriptions/billing/run [post]
+func (h *SubscriptionBillingHandler) RunBilling(c *gin.Context) {
+	var req dto.RunSubscriptionBillingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	period, err := entity.ParseBillingPeriod(req.Period)
+	if err != nil {
+		response.BadRequest(c, "Invalid period format, expected YYYY-MM")
+		return
+	}
+
+	var userID *int64
+	if uid, exists := c.Get("user_id"); exists {
+		id := uid.(int64)
+		userID = &id
+	}
+
+	result, err := h.billingService.RunBilling(c.Request.Context(), period, req.CustomerID, userID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to run subscription billing", err)
+		return
+	}
+
+	resp := dto.SubscriptionBillingRunResponse{
+		PeriodStart:         result.Period.Start,
+		PeriodEnd:           result.Period.End,
+		Generated:           []dto.SubscriptionInvoiceResponse{},
+		SkippedCustomerIDs:  result.Skipped,
+		TotalInvoicedAmount: decimal.Zero,
+	}
+	for i := range result.Generated {
+		inv := &result.Generated[i]
+		resp.Generated = append(resp.Generated, mapSubscriptionInvoiceToResponse(inv, false))
+		resp.TotalInvoicedAmount = resp.TotalInvoicedAmount.Add(inv.TotalAmount)
+	}
+
+	response.OK(c, fmt.Sprintf("%d invoices generated", len(resp.Generated)), resp)
+}
+
+// ListInvoices godoc
+// @Summary      List subscription invoices
+// @Description  Returns subscription invoices, optionally filtered by customer, period and status
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        customer_id  query  int     false  "Customer ID"
+// @Param        period       query  string  false  "Billing period (YYYY-MM)"
+// @Param        status       query  string  false  "outstanding or paid"
+// @Param        page         query  int     false  "Page number"    default(1)
+// @Param        per_page     query  int     false  "Items per page" default(20)
+// @Success      200  {object}  response.Response{data=[]dto.SubscriptionInvoiceResponse}
+// @Router       /subscriptions/invoices [get]
+func (h *SubscriptionBillingHandler) ListInvoices(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var filter repository.SubscriptionInvoiceFilter
+	if cid, err := strconv.ParseInt(c.Query("customer_id"), 10, 64); err == nil {
+		filter.CustomerID = &cid
+	}
+	if p := c.Query("period"); p != "" {
+		period, err := entity.ParseBillingPeriod(p)
+		if err != nil {
+			response.BadRequest(c, "Invalid period format, expected YYYY-MM")
+			return
+		}
+		filter.PeriodStart = &period.Start
+	}
+	if st := entity.InvoiceStatus(c.Query("status")); st == entity.InvoiceStatusOutstanding || st == entity.InvoiceStatusPaid {
+		filter.Status = &st
+	}
+
+	invoices, total, err := h.billingService.List(c.Request.Context(), filter, (page-1)*perPage, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch invoices", err)
+		return
+	}
+
+	resp := []dto.SubscriptionInvoiceResponse{}
+	for i := range invoices {
+		resp = append(resp, mapSubscriptionInvoiceToResponse(&invoices[i], false))
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Invoices retrieved", resp, &response.Meta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages})
+}
+
+// GetInvoice godoc
+// @Summary      Get a subscription invoice
+// @Description  Returns an invoice with a line per delivered product per day
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Invoice ID"
+// @Success      200  {object}  response.Response{data=dto.SubscriptionInvoiceResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /subscriptions/invoices/{id} [get]
+func (h *SubscriptionBillingHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.billingService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrInvoiceNotFound) {
+			response.NotFound(c, "Invoice not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to fetch invoice", err)
+		return
+	}
+
+	response.OK(c, "Invoice retrieved", mapSubscriptionInvoiceToResponse(invoice, true))
+}
+
+// RecordInvoicePayment godoc
+// @Summary      Record a payment against a subscription invoice
+// @Description  Records a customer payment for the invoice, credits the customer's ledger and marks the invoice paid once settled
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                               true  "Invoice ID"
+// @Param        request  body      dto.RecordCustomerPaymentRequest  true  "Payment details"
+// @Success      200      {object}  response.Response{data=dto.SubscriptionInvoiceResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /subscriptions/invoices/{id}/payments [post]
+func (h *SubscriptionBillingHandler) RecordInvoicePayment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	var req dto.RecordCustomerPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	payment := &entity.CustomerPayment{
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		Reference:     req.Reference,
+		Notes:         req.Notes,
+	}
+	if req.ReceivedAt != "" {
+		t, err := time.Parse("2006-01-02", req.ReceivedAt)
+		if err != nil {
+			response.BadRequest(c, "Invalid received_at format, expected YYYY-MM-DD")
+			return
+		}
+		payment.ReceivedAt = t
+	}
+	if uid, exists := c.Get("user_id"); exists {
+		recordedBy := uid.(int64)
+		payment.RecordedByUserID = &recordedBy
+	}
+
+	invoice, err := h.billingService.RecordPayment(c.Request.Context(), id, payment)
+	if err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrInvoiceNotFound):
+			response.NotFound(c, "Invoice not found")
+		case errors.Is(err, domainErrors.ErrInvoiceAlreadyPaid):
+			response.Conflict(c, "Invoice is already paid")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, "Payment must be greater than zero and no more than the balance due")
+		default:
+			response.InternalErrorDebug(c, "Failed to record invoice payment", err)
+		}
+		return
+	}
+
+	response.OK(c, "Payment recorded", mapSubscriptionInvoiceToResponse(invoice, true))
+}
+
+// mapSubscriptionInvoiceToResponse converts an invoice, including its lines when requested
+func mapSubscriptionInvoiceToResponse(inv *entity.SubscriptionInvoice, withLines bool) dto.SubscriptionInvoiceResponse {
+	resp := dto.SubscriptionInvoiceResponse{
+		ID:            inv.ID,
+		InvoiceNumber: inv.InvoiceNumber,
+		Sequence:      inv.Sequence,
+		CustomerID:    inv.CustomerID,
+		CustomerName:  inv.CustomerName,
+		PeriodStart:   inv.PeriodStart,
+		PeriodEnd:     inv.PeriodEnd,
+		TotalAmount:   inv.TotalAmount,
+		AmountPaid:    inv.AmountPaid,
+		BalanceDue:    inv.BalanceDue(),
+		Status:        string(inv.Status),
+		DueDate:       inv.DueDate,
+		CreatedAt:     inv.CreatedAt,
+	}
+	if !withLines {
+		return resp
+	}
+
+	resp.Lines = []dto.SubscriptionInvoiceLineResponse{}
+	for _, l := range inv.Lines {
+		resp.Lines = append(resp.Lines, dto.SubscriptionInvoiceLineResponse{
+			ID:             l.ID,
+			SubscriptionID: l.SubscriptionID,
+			DeliveryID:     l.DeliveryID,
+			DeliveryDate:   l.DeliveryDate,
+			VariantID:      l.VariantID,
+			VariantName:    l.VariantName,
+			Unit:           l.Unit,
+			Quantity:       l.Quantity,
+			UnitPrice:      l.UnitPrice,
+			LineTotal:      l.LineTotal,
+		})
+	}
+	return resp
+}
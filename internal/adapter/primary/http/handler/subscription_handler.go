@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -98,18 +99,18 @@ func (h *SubscriptionHandler) Create(c *gin.Context) {
 		StartDate:            startDate,
 		EndDate:              endDate,
 		DeliveryInstructions: req.DeliveryInstructions,
+		AddressID:            req.AddressID,
+		DeliveryWeekdays:     mapWeekdays(req.DeliveryWeekdays),
+		DeliveryDayOfMonth:   req.DeliveryDayOfMonth,
 	}
 
 	for _, item := range req.Items {
-		sub.Items = append(sub.Items, entity.SubscriptionItem{
-			VariantID: item.VariantID,
-			Quantity:  decimal.NewFromFloat(item.Quantity),
-		})
+		sub.Items = append(sub.Items, mapSubscriptionItemRequest(item))
 	}
 
 	created, err := h.subscriptionService.Create(c.Request.Context(), sub)
 	if err != nil {
-		if err == domainErrors.ErrInvalidInput {
+		if errors.Is(err, domainErrors.ErrInvalidInput) {
 			response.BadRequest(c, "Invalid subscription data")
 		} else {
 			response.InternalErrorDebug(c, "Failed to create subscription", err)
@@ -208,13 +209,19 @@ func (h *SubscriptionHandler) Update(c *gin.Context) {
 	if req.DeliveryInstructions != nil {
 		existing.DeliveryInstructions = req.DeliveryInstructions
 	}
+	if req.AddressID != nil {
+		existing.AddressID = req.AddressID
+	}
+	if req.DeliveryWeekdays != nil {
+		existing.DeliveryWeekdays = mapWeekdays(req.DeliveryWeekdays)
+	}
+	if req.DeliveryDayOfMonth != nil {
+		existing.DeliveryDayOfMonth = req.DeliveryDayOfMonth
+	}
 	if len(req.Items) > 0 {
 		existing.Items = nil
 		for _, item := range req.Items {
-			existing.Items = append(existing.Items, entity.SubscriptionItem{
-				VariantID: item.VariantID,
-				Quantity:  decimal.NewFromFloat(item.Quantity),
-			})
+			existing.Items = append(existing.Items, mapSubscriptionItemRequest(item))
 		}
 	}
 
@@ -222,6 +229,8 @@ func (h *SubscriptionHandler) Update(c *gin.Context) {
 	if err != nil {
 		if domainErrors.IsNotFound(err) {
 			response.NotFound(c, "Subscription not found")
+		} else if errors.Is(err, domainErrors.ErrInvalidInput) {
+			response.BadRequest(c, err.Error())
 		} else {
 			response.InternalErrorDebug(c, "Failed to update subscription", err)
 		}
@@ -306,9 +315,14 @@ func mapSubscriptionToResponse(sub *entity.Subscription) dto.SubscriptionRespons
 		StartDate:            sub.StartDate,
 		EndDate:              sub.EndDate,
 		DeliveryInstructions: sub.DeliveryInstructions,
+		AddressID:            sub.AddressID,
+		DeliveryDayOfMonth:   sub.DeliveryDayOfMonth,
 		CreatedAt:            sub.CreatedAt,
 		UpdatedAt:            sub.UpdatedAt,
 	}
+	for _, wd := range sub.DeliveryWeekdays {
+		resp.DeliveryWeekdays = append(resp.DeliveryWeekdays, int(wd))
+	}
 
 	if sub.Customer != nil {
 		resp.CustomerName = sub.Customer.Name
@@ -320,6 +334,10 @@ func mapSubscriptionToResponse(sub *entity.Subscription) dto.SubscriptionRespons
 			VariantID: item.VariantID,
 			Quantity:  item.Quantity.InexactFloat64(),
 		}
+		if item.UnitPrice != nil {
+			price := item.UnitPrice.InexactFloat64()
+			itemResp.UnitPrice = &price
+		}
 		if item.Variant != nil {
 			itemResp.VariantName = item.Variant.Name
 			itemResp.FamilyName = item.Variant.FamilyName
@@ -352,7 +370,7 @@ func mapDeliveryToResponse(del *entity.SubscriptionDelivery) *dto.SubscriptionDe
 
 // RecordDelivery godoc
 // @Summary      Record a daily delivery
-// @Description  Create or update a delivery log indicating success/failure for a subscription on a specific date
+// @Description  Create or update a delivery log indicating success/failure for a subscription on a specific date. A delivered log takes the day's items out of stock at the warehouse that held them or serves the subscription, and cannot then be changed to failed or skipped.
 // @Tags         Subscriptions
 // @Accept       json
 // @Produce      json
@@ -391,7 +409,24 @@ func (h *SubscriptionHandler) RecordDelivery(c *gin.Context) {
 	}(), recordedBy)
 
 	if err != nil {
-		response.InternalErrorDebug(c, "Failed to record delivery", err)
+		switch {
+		case errors.Is(err, domainErrors.ErrSubscriptionNotFound):
+			response.NotFound(c, "Subscription not found")
+		case errors.Is(err, domainErrors.ErrDeliveryStockIssued):
+			response.Conflict(c, "The delivery's stock has been issued, so it cannot be changed to another status")
+		case errors.Is(err, domainErrors.ErrNoServingWarehouse):
+			response.BadRequest(c, "No delivery zone with a warehouse serves the subscription's address")
+		case errors.Is(err, domainErrors.ErrInsufficientStock):
+			response.BadRequest(c, "Insufficient stock for the delivery")
+		case errors.Is(err, domainErrors.ErrStockExpired):
+			response.BadRequest(c, "Only expired stock is available for one or more items")
+		case errors.Is(err, domainErrors.ErrStockReserved):
+			response.BadRequest(c, "Stock for one or more items is reserved for orders and subscriptions")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalErrorDebug(c, "Failed to record delivery", err)
+		}
 		return
 	}
 
@@ -400,7 +435,7 @@ func (h *SubscriptionHandler) RecordDelivery(c *gin.Context) {
 
 // GetDailyRoster godoc
 // @Summary      Get daily subscriptions roster
-// @Description  Returns all active subscriptions mapping to a given date alongside their current delivery log
+// @Description  Returns the active subscriptions due on a given date, honouring pauses, delivery days and one-off quantity overrides, alongside their current delivery log
 // @Tags         Subscriptions
 // @Produce      json
 // @Security     BearerAuth
@@ -433,3 +468,318 @@ func (h *SubscriptionHandler) GetDailyRoster(c *gin.Context) {
 
 	response.OK(c, "Daily roster retrieved", resp)
 }
+
+// mapSubscriptionItemRequest converts a requested line item, keeping any locked-in price
+func mapSubscriptionItemRequest(req dto.SubscriptionItemRequest) entity.SubscriptionItem {
+	item := entity.SubscriptionItem{
+		VariantID: req.VariantID,
+		Quantity:  decimal.NewFromFloat(req.Quantity),
+	}
+	if req.UnitPrice != nil {
+		price := decimal.NewFromFloat(*req.UnitPrice)
+		item.UnitPrice = &price
+	}
+	return item
+}
+
+// mapWeekdays converts requested weekday numbers (0 = Sunday) to weekdays
+func mapWeekdays(days []int) []time.Weekday {
+	if days == nil {
+		return nil
+	}
+	weekdays := make([]time.Weekday, 0, len(days))
+	for _, d := range days {
+		weekdays = append(weekdays, time.Weekday(d))
+	}
+	return weekdays
+}
+
+// ListPauses godoc
+// @Summary      List subscription pauses
+// @Description  Returns the vacation holds of a subscription
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Subscription ID"
+// @Success      200  {object}  response.Response{data=[]dto.SubscriptionPauseResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /subscriptions/{id}/pauses [get]
+func (h *SubscriptionHandler) ListPauses(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+
+	pauses, err := h.subscriptionService.ListPauses(c.Request.Context(), subID)
+	if err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Subscription not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to fetch subscription pauses", err)
+		}
+		return
+	}
+
+	resp := []dto.SubscriptionPauseResponse{}
+	for _, p := range pauses {
+		resp = append(resp, mapPauseToResponse(p))
+	}
+	response.OK(c, "Subscription pauses retrieved", resp)
+}
+
+// CreatePause godoc
+// @Summary      Pause a subscription for a date range
+// @Description  Adds a vacation hold; no deliveries are due between start_date and end_date inclusive
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                                 true  "Subscription ID"
+// @Param        request  body      dto.CreateSubscriptionPauseRequest  true  "Pause window"
+// @Success      201      {object}  response.Response{data=dto.SubscriptionPauseResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /subscriptions/{id}/pauses [post]
+func (h *SubscriptionHandler) CreatePause(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+
+	var req dto.CreateSubscriptionPauseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+		return
+	}
+
+	pause := &entity.SubscriptionPause{
+		SubscriptionID: subID,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Reason:         req.Reason,
+	}
+	if uid, exists := c.Get("user_id"); exists {
+		createdBy := uid.(int64)
+		pause.CreatedBy = &createdBy
+	}
+	if err := h.subscriptionService.AddPause(c.Request.Context(), pause); err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Subscription not found")
+		} else if errors.Is(err, domainErrors.ErrInvalidInput) {
+			response.BadRequest(c, err.Error())
+		} else {
+			response.InternalErrorDebug(c, "Failed to pause subscription", err)
+		}
+		return
+	}
+
+	response.Created(c, "Subscription paused", mapPauseToResponse(pause))
+}
+
+// DeletePause godoc
+// @Summary      Remove a subscription pause
+// @Description  Lifts a vacation hold so deliveries resume on its dates
+// @Tags         Subscriptions
+// @Security     BearerAuth
+// @Param        id        path  int  true  "Subscription ID"
+// @Param        pauseId   path  int  true  "Pause ID"
+// @Success      204
+// @Failure      404  {object}  response.Response
+// @Router       /subscriptions/{id}/pauses/{pauseId} [delete]
+func (h *SubscriptionHandler) DeletePause(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+	pauseID, err := strconv.ParseInt(c.Param("pauseId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid pause ID")
+		return
+	}
+
+	if err := h.subscriptionService.RemovePause(c.Request.Context(), subID, pauseID); err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Pause not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to remove subscription pause", err)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ListOverrides godoc
+// @Summary      List subscription date overrides
+// @Description  Returns one-off quantity overrides of a subscription from a date onwards
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path   int     true   "Subscription ID"
+// @Param        from  query  string  false  "Earliest date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.SubscriptionOverrideResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /subscriptions/{id}/overrides [get]
+func (h *SubscriptionHandler) ListOverrides(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+
+	from := time.Now().Truncate(24 * time.Hour)
+	if f := c.Query("from"); f != "" {
+		parsed, err := time.Parse("2006-01-02", f)
+		if err != nil {
+			response.BadRequest(c, "Invalid from format, expected YYYY-MM-DD")
+			return
+		}
+		from = parsed
+	}
+
+	overrides, err := h.subscriptionService.ListOverrides(c.Request.Context(), subID, from)
+	if err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Subscription not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to fetch subscription overrides", err)
+		}
+		return
+	}
+
+	resp := []dto.SubscriptionOverrideResponse{}
+	for _, o := range overrides {
+		resp = append(resp, mapOverrideToResponse(o))
+	}
+	response.OK(c, "Subscription overrides retrieved", resp)
+}
+
+// SetOverride godoc
+// @Summary      Set a one-off quantity for a date
+// @Description  Replaces a subscribed product's quantity for a single date; 0 skips it that day
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                                 true  "Subscription ID"
+// @Param        request  body      dto.SetSubscriptionOverrideRequest  true  "Override"
+// @Success      200      {object}  response.Response{data=dto.SubscriptionOverrideResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /subscriptions/{id}/overrides [put]
+func (h *SubscriptionHandler) SetOverride(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+
+	var req dto.SetSubscriptionOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	override := &entity.SubscriptionDateOverride{
+		SubscriptionID: subID,
+		DeliveryDate:   date,
+		VariantID:      req.VariantID,
+		Quantity:       decimal.NewFromFloat(req.Quantity),
+		Notes:          req.Notes,
+	}
+	if uid, exists := c.Get("user_id"); exists {
+		createdBy := uid.(int64)
+		override.CreatedBy = &createdBy
+	}
+	if err := h.subscriptionService.SetOverride(c.Request.Context(), override); err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Subscription not found")
+		} else if errors.Is(err, domainErrors.ErrInvalidInput) {
+			response.BadRequest(c, err.Error())
+		} else {
+			response.InternalErrorDebug(c, "Failed to save subscription override", err)
+		}
+		return
+	}
+
+	response.OK(c, "Subscription override saved", mapOverrideToResponse(override))
+}
+
+// DeleteOverride godoc
+// @Summary      Remove a subscription date override
+// @Description  Restores the regular quantity for the override's date
+// @Tags         Subscriptions
+// @Security     BearerAuth
+// @Param        id          path  int  true  "Subscription ID"
+// @Param        overrideId  path  int  true  "Override ID"
+// @Success      204
+// @Failure      404  {object}  response.Response
+// @Router       /subscriptions/{id}/overrides/{overrideId} [delete]
+func (h *SubscriptionHandler) DeleteOverride(c *gin.Context) {
+	subID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+	overrideID, err := strconv.ParseInt(c.Param("overrideId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid override ID")
+		return
+	}
+
+	if err := h.subscriptionService.RemoveOverride(c.Request.Context(), subID, overrideID); err != nil {
+		if domainErrors.IsNotFound(err) {
+			response.NotFound(c, "Override not found")
+		} else {
+			response.InternalErrorDebug(c, "Failed to remove subscription override", err)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func mapPauseToResponse(p *entity.SubscriptionPause) dto.SubscriptionPauseResponse {
+	return dto.SubscriptionPauseResponse{
+		ID:             p.ID,
+		SubscriptionID: p.SubscriptionID,
+		StartDate:      p.StartDate,
+		EndDate:        p.EndDate,
+		Reason:         p.Reason,
+		CreatedBy:      p.CreatedBy,
+		CreatedAt:      p.CreatedAt,
+	}
+}
+
+func mapOverrideToResponse(o *entity.SubscriptionDateOverride) dto.SubscriptionOverrideResponse {
+	return dto.SubscriptionOverrideResponse{
+		ID:             o.ID,
+		SubscriptionID: o.SubscriptionID,
+		DeliveryDate:   o.DeliveryDate,
+		VariantID:      o.VariantID,
+		Quantity:       o.Quantity.InexactFloat64(),
+		Notes:          o.Notes,
+		CreatedBy:      o.CreatedBy,
+		CreatedAt:      o.CreatedAt,
+	}
+}
@@ -312,7 +312,7 @@ func (h *SupplierHandler) ListVariants(c *gin.Context) {
 }
 
 // @Summary      Add variant to supplier
-// @Description  Links a product variant to a supplier with cost
+// @Description  Links a product variant to a supplier with cost. A cost that differs from the price in force today starts an open-ended price list from today, ending the previous open-ended list; it is refused when another list already covers that period.
 // @Tags         Suppliers
 // @Accept       json
 // @Produce      json
@@ -321,6 +321,7 @@ func (h *SupplierHandler) ListVariants(c *gin.Context) {
 // @Param        request  body      dto.AddSupplierVariantRequest  true  "Variant details"
 // @Success      201      {object}  response.Response
 // @Failure      400      {object}  response.Response
+// @Failure      409      {object}  response.Response
 // @Router       /suppliers/{id}/variants [post]
 func (h *SupplierHandler) AddVariant(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -341,6 +342,10 @@ func (h *SupplierHandler) AddVariant(c *gin.Context) {
 	if err := h.supplierService.AddVariant(c.Request.Context(), id, sv); err != nil {
 		if err == domainErrors.ErrSupplierNotFound {
 			response.NotFound(c, "Supplier not found")
+		} else if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "Agreed cost cannot be negative")
+		} else if err == domainErrors.ErrPriceListOverlap {
+			response.Conflict(c, "Another price list covers today onwards; change the price through the supplier's price lists")
 		} else {
 			response.InternalErrorDebug(c, "Failed to add variant to supplier", err)
 		}
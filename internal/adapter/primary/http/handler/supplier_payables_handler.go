@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// SupplierPayablesHandler handles supplier accounts payable API requests
+type SupplierPayablesHandler struct {
+	payablesService *service.SupplierPayablesService
+}
+
+// NewSupplierPayablesHandler creates a new supplier payables handler
+func NewSupplierPayablesHandler(payablesService *service.SupplierPayablesService) *SupplierPayablesHandler {
+	return &SupplierPayablesHandler{payablesService: payablesService}
+}
+
+// BillProcurement godoc
+// @Summary      Bill a purchase order
+// @Description  Raises a supplier bill for the goods accepted on a purchase order's receipts that have not been billed yet, priced at the order's unit costs
+// @Tags         Payables
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.BillProcurementRequest  true  "Purchase order to bill"
+// @Success      201      {object}  response.Response{data=dto.SupplierBillResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /payables/bills/procurement [post]
+func (h *SupplierPayablesHandler) BillProcurement(c *gin.Context) {
+	var req dto.BillProcurementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	bill := &entity.SupplierBill{Reference: req.Reference, Notes: req.Notes}
+	if !parseBillDates(c, bill, req.BillDate, req.DueDate) {
+		return
+	}
+
+	if err := h.payablesService.BillProcurement(c.Request.Context(), req.ProcurementID, bill); err != nil {
+		h.writeBillError(c, err, "Purchase order not found", "Nothing received on this purchase order is left to bill")
+		return
+	}
+
+	response.Created(c, "Supplier bill created", mapSupplierBillResponse(bill, true))
+}
+
+// BillCollections godoc
+// @Summary      Bill supplier collections
+// @Description  Raises a supplier bill for the supplier's collections in the period that have not been billed yet, priced at their rate chart rate, else the agreed cost for the variant or its cost price
+// @Tags         Payables
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.BillCollectionsRequest  true  "Supplier and collection period"
+// @Success      201      {object}  response.Response{data=dto.SupplierBillResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /payables/bills/collections [post]
+func (h *SupplierPayablesHandler) BillCollections(c *gin.Context) {
+	var req dto.BillCollectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+		return
+	}
+
+	bill := &entity.SupplierBill{Reference: req.Reference, Notes: req.Notes}
+	if !parseBillDates(c, bill, req.BillDate, req.DueDate) {
+		return
+	}
+
+	if err := h.payablesService.BillCollections(c.Request.Context(), req.SupplierID, from, to.AddDate(0, 0, 1), bill); err != nil {
+		h.writeBillError(c, err, "Supplier not found", "No unbilled collections for this supplier in the period")
+		return
+	}
+
+	response.Created(c, "Supplier bill created", mapSupplierBillResponse(bill, true))
+}
+
+// parseBillDates reads the optional bill and due dates into the bill, writing an error response when
+// one is malformed. The user raising the bill is recorded against it.
+func parseBillDates(c *gin.Context, bill *entity.SupplierBill, billDate, dueDate string) bool {
+	if billDate != "" {
+		t, err := time.Parse("2006-01-02", billDate)
+		if err != nil {
+			response.BadRequest(c, "Invalid bill_date format, expected YYYY-MM-DD")
+			return false
+		}
+		bill.BillDate = t
+	}
+	if dueDate != "" {
+		t, err := time.Parse("2006-01-02", dueDate)
+		if err != nil {
+			response.BadRequest(c, "Invalid due_date format, expected YYYY-MM-DD")
+			return false
+		}
+		bill.DueDate = &t
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		createdBy := userID.(int64)
+		bill.CreatedByUserID = &createdBy
+	}
+	return true
+}
+
+func (h *SupplierPayablesHandler) writeBillError(c *gin.Context, err error, notFound, nothingToBill string) {
+	switch err {
+	case domainErrors.ErrProcurementNotFound, domainErrors.ErrSupplierNotFound:
+		response.NotFound(c, notFound)
+	case domainErrors.ErrNothingToBill:
+		response.Conflict(c, nothingToBill)
+	case domainErrors.ErrInvalidInput:
+		response.BadRequest(c, "The period must not end before it starts, and the due date must not be before the bill date")
+	default:
+		response.InternalErrorDebug(c, "Failed to create supplier bill", err)
+	}
+}
+
+// ListBills godoc
+// @Summary      List supplier bills
+// @Description  Returns supplier bills, newest first, optionally filtered by supplier, purchase order and status
+// @Tags         Payables
+// @Produce      json
+// @Security     BearerAuth
+// @Param        supplier_id     query  int     false  "Supplier ID"
+// @Param        procurement_id  query  int     false  "Purchase order ID"
+// @Param        status          query  string  false  "open, partially_paid or paid"
+// @Param        page            query  int     false  "Page number"    default(1)
+// @Param        per_page        query  int     false  "Items per page" default(20)
+// @Success      200  {object}  response.Response{data=[]dto.SupplierBillResponse}
+// @Router       /payables/bills [get]
+func (h *SupplierPayablesHandler) ListBills(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var filter repository.SupplierBillFilter
+	if sid, err := strconv.ParseInt(c.Query("supplier_id"), 10, 64); err == nil {
+		filter.SupplierID = &sid
+	}
+	if pid, err := strconv.ParseInt(c.Query("procurement_id"), 10, 64); err == nil {
+		filter.ProcurementID = &pid
+	}
+	if st := entity.SupplierBillStatus(c.Query("status")); st.IsValid() {
+		filter.Status = &st
+	}
+
+	bills, total, err := h.payablesService.ListBills(c.Request.Context(), filter, (page-1)*perPage, perPage)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to fetch supplier bills", err)
+		return
+	}
+
+	resp := []dto.SupplierBillResponse{}
+	for i := range bills {
+		resp = append(resp, mapSupplierBillResponse(&bills[i], false))
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+	response.SuccessWithMeta(c, 200, "Supplier bills retrieved", resp, &response.Meta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages})
+}
+
+// GetBill godoc
+// @Summary      Get a supplier bill
+// @Description  Returns a supplier bill with its lines
+// @Tags         Payables
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Bill ID"
+// @Success      200  {object}  response.Response{data=dto.SupplierBillResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /payables/bills/{id} [get]
+func (h *SupplierPayablesHandler) GetBill(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid bill ID")
+		return
+	}
+
+	bill, err := h.payablesService.GetBill(c.Request.Context(), id)
+	if err != nil {
+		if err == domainErrors.ErrSupplierBillNotFound {
+			response.NotFound(c, "Supplier bill not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to fetch supplier bill", err)
+		return
+	}
+
+	response.OK(c, "Supplier bill retrieved", mapSupplierBillResponse(bill, true))
+}
+
+// RecordPayment godoc
+// @Summary      Record supplier payment
+// @Description  Records money paid to a supplier by cash, UPI, bank transfer or cheque and debits their ledger. A payment against a bill settles it and cannot exceed its balance due.
+// @Tags         Payables
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.RecordSupplierPaymentRequest  true  "Payment details"
+// @Success      201      {object}  response.Response{data=dto.SupplierPaymentResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Router       /payables/payments [post]
+func (h *SupplierPayablesHandler) RecordPayment(c *gin.Context) {
+	var req dto.RecordSupplierPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	payment := &entity.SupplierPayment{
+		SupplierID:    req.SupplierID,
+		BillID:        req.BillID,
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		Reference:     req.Reference,
+		Notes:         req.Notes,
+	}
+	if req.PaidAt != "" {
+		t, err := time.Parse("2006-01-02", req.PaidAt)
+		if err != nil {
+			response.BadRequest(c, "Invalid paid_at format, expected YYYY-MM-DD")
+			return
+		}
+		payment.PaidAt = t
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		recordedBy := userID.(int64)
+		payment.RecordedByUserID = &recordedBy
+	}
+
+	if err := h.payablesService.RecordPayment(c.Request.Context(), payment); err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrSupplierNotFound):
+			response.NotFound(c, "Supplier not found")
+		case errors.Is(err, domainErrors.ErrSupplierBillNotFound):
+			response.NotFound(c, "Supplier bill not found")
+		case errors.Is(err, domainErrors.ErrSupplierBillPaid):
+			response.Conflict(c, "Supplier bill is already paid")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, "Payment must be greater than zero and, against a bill of the supplier, no more than its balance due")
+		default:
+			response.InternalErrorDebug(c, "Failed to record supplier payment", err)
+		}
+		return
+	}
+
+	response.Created(c, "Payment recorded", dto.SupplierPaymentResponse{
+		ID:            payment.ID,
+		SupplierID:    payment.SupplierID,
+		BillID:        payment.BillID,
+		Amount:        payment.Amount,
+		PaymentMethod: payment.PaymentMethod,
+		Reference:     payment.Reference,
+		Notes:         payment.Notes,
+		PaidAt:        payment.PaidAt,
+		CreatedAt:     payment.CreatedAt,
+	})
+}
+
+// Ledger godoc
+// @Summary      Supplier ledger
+// @Description  Returns the supplier's ledger entries in the date range with opening, running and closing amounts owed
+// @Tags         Payables
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Supplier ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the first of the month"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.SupplierStatementResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /payables/suppliers/{id}/ledger [get]
+func (h *SupplierPayablesHandler) Ledger(c *gin.Context) {
+	statement, ok := h.loadStatement(c)
+	if !ok {
+		return
+	}
+	response.OK(c, "Supplier ledger retrieved", mapSupplierStatementResponse(statement))
+}
+
+// Statement godoc
+// @Summary      Download supplier statement
+// @Description  Downloads the supplier's statement for the date range as a CSV file
+// @Tags         Payables
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Supplier ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the first of the month"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {file}    file
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /payables/suppliers/{id}/statement [get]
+func (h *SupplierPayablesHandler) Statement(c *gin.Context) {
+	statement, ok := h.loadStatement(c)
+	if !ok {
+		return
+	}
+
+	lastDay := statement.To.AddDate(0, 0, -1)
+	filename := fmt.Sprintf("supplier-statement-%d-%s-%s.csv", statement.Supplier.ID, statement.From.Format("20060102"), lastDay.Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"Supplier", statement.Supplier.Name, statement.Supplier.Phone})
+	_ = w.Write([]string{"Period", statement.From.Format("2006-01-02"), lastDay.Format("2006-01-02")})
+	_ = w.Write([]string{})
+	_ = w.Write([]string{"Date", "Type", "Description", "Due Date", "Paid", "Billed", "Owed"})
+	_ = w.Write([]string{statement.From.Format("2006-01-02"), "", "Opening balance", "", "", "", statement.OpeningBalance.StringFixed(2)})
+	for _, e := range statement.Entries {
+		dueDate := ""
+		if e.DueDate != nil {
+			dueDate = e.DueDate.Format("2006-01-02")
+		}
+		_ = w.Write([]string{
+			e.EntryDate.Format("2006-01-02"),
+			string(e.EntryType),
+			e.Description,
+			dueDate,
+			e.Debit.StringFixed(2),
+			e.Credit.StringFixed(2),
+			e.Balance.StringFixed(2),
+		})
+	}
+	_ = w.Write([]string{"", "", "Total", "", statement.TotalDebit.StringFixed(2), statement.TotalCredit.StringFixed(2), ""})
+	_ = w.Write([]string{lastDay.Format("2006-01-02"), "", "Closing balance", "", "", "", statement.ClosingBalance.StringFixed(2)})
+	w.Flush()
+}
+
+// SupplierAging godoc
+// @Summary      Supplier aging
+// @Description  Returns the amount owed to the supplier split into 0-15, 16-30, 31-60 and 60+ days past due (from each bill's due date, or its bill date without one)
+// @Tags         Payables
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path   int     true   "Supplier ID"
+// @Param        as_of  query  string  false  "Aging date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.SupplierAgingResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /payables/suppliers/{id}/aging [get]
+func (h *SupplierPayablesHandler) SupplierAging(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	aging, err := h.payablesService.SupplierAging(c.Request.Context(), id, asOf)
+	if err != nil {
+		if err == domainErrors.ErrSupplierNotFound {
+			response.NotFound(c, "Supplier not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to build supplier aging", err)
+		return
+	}
+
+	response.OK(c, "Supplier aging retrieved", mapSupplierAgingResponse(aging))
+}
+
+// Aging godoc
+// @Summary      Accounts payable aging
+// @Description  Returns every supplier with an outstanding balance, the amount owed split into 0-15, 16-30, 31-60 and 60+ days past due
+// @Tags         Payables
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of  query  string  false  "Aging date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.SupplierAgingResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /payables/aging [get]
+func (h *SupplierPayablesHandler) Aging(c *gin.Context) {
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.payablesService.Aging(c.Request.Context(), asOf)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to build payables aging report", err)
+		return
+	}
+
+	resp := []dto.SupplierAgingResponse{}
+	for i := range report {
+		resp = append(resp, mapSupplierAgingResponse(&report[i]))
+	}
+	response.OK(c, "Payables aging report retrieved", resp)
+}
+
+// loadStatement parses the supplier and date range and builds the statement,
+// writing an error response when it fails
+func (h *SupplierPayablesHandler) loadStatement(c *gin.Context) (*entity.SupplierStatement, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return nil, false
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return nil, false
+		}
+		from = t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return nil, false
+		}
+		to = t
+	}
+
+	statement, err := h.payablesService.GetStatement(c.Request.Context(), id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		switch err {
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "start_date must not be after end_date")
+		case domainErrors.ErrSupplierNotFound:
+			response.NotFound(c, "Supplier not found")
+		default:
+			response.InternalErrorDebug(c, "Failed to build supplier statement", err)
+		}
+		return nil, false
+	}
+	return statement, true
+}
+
+func mapSupplierBillResponse(b *entity.SupplierBill, withLines bool) dto.SupplierBillResponse {
+	resp := dto.SupplierBillResponse{
+		ID:            b.ID,
+		SupplierID:    b.SupplierID,
+		SupplierName:  b.SupplierName,
+		Source:        string(b.Source),
+		ProcurementID: b.ProcurementID,
+		PeriodStart:   b.PeriodStart,
+		PeriodEnd:     b.PeriodEnd,
+		Reference:     b.Reference,
+		BillDate:      b.BillDate,
+		DueDate:       b.DueDate,
+		Amount:        b.Amount,
+		AmountPaid:    b.AmountPaid,
+		BalanceDue:    b.BalanceDue(),
+		Status:        string(b.Status),
+		Notes:         b.Notes,
+		CreatedAt:     b.CreatedAt,
+	}
+	if !withLines {
+		return resp
+	}
+	resp.Lines = []dto.SupplierBillLineResponse{}
+	for _, l := range b.Lines {
+		resp.Lines = append(resp.Lines, dto.SupplierBillLineResponse{
+			ID:                 l.ID,
+			GoodsReceiptItemID: l.GoodsReceiptItemID,
+			CollectionID:       l.CollectionID,
+			VariantID:          l.VariantID,
+			VariantName:        l.VariantName,
+			Unit:               l.Unit,
+			LineDate:           l.LineDate,
+			Quantity:           l.Quantity,
+			UnitPrice:          l.UnitPrice,
+			LineTotal:          l.LineTotal,
+		})
+	}
+	return resp
+}
+
+func mapSupplierStatementResponse(s *entity.SupplierStatement) dto.SupplierStatementResponse {
+	resp := dto.SupplierStatementResponse{
+		SupplierID:     s.Supplier.ID,
+		SupplierName:   s.Supplier.Name,
+		StartDate:      s.From.Format("2006-01-02"),
+		EndDate:        s.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		OpeningBalance: s.OpeningBalance,
+		TotalDebit:     s.TotalDebit,
+		TotalCredit:    s.TotalCredit,
+		ClosingBalance: s.ClosingBalance,
+		Entries:        []dto.SupplierLedgerEntryResponse{},
+	}
+	for _, e := range s.Entries {
+		resp.Entries = append(resp.Entries, dto.SupplierLedgerEntryResponse{
+			ID:          e.ID,
+			EntryType:   string(e.EntryType),
+			BillID:      e.BillID,
+			PaymentID:   e.PaymentID,
+			Description: e.Description,
+			EntryDate:   e.EntryDate,
+			DueDate:     e.DueDate,
+			Debit:       e.Debit,
+			Credit:      e.Credit,
+			Balance:     e.Balance,
+		})
+	}
+	return resp
+}
+
+func mapSupplierAgingResponse(a *entity.SupplierAging) dto.SupplierAgingResponse {
+	return dto.SupplierAgingResponse{
+		SupplierID:   a.SupplierID,
+		SupplierName: a.SupplierName,
+		Balance:      a.Balance,
+		Days0To15:    a.Days0To15,
+		Days16To30:   a.Days16To30,
+		Days31To60:   a.Days31To60,
+		Over60:       a.Over60,
+	}
+}
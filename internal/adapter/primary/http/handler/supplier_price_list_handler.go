@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// SupplierPriceListHandler handles supplier price list API requests
+type SupplierPriceListHandler struct {
+	priceListService *service.SupplierPriceListService
+}
+
+// NewSupplierPriceListHandler creates a new supplier price list handler
+func NewSupplierPriceListHandler(priceListService *service.SupplierPriceListService) *SupplierPriceListHandler {
+	return &SupplierPriceListHandler{priceListService: priceListService}
+}
+
+// Create godoc
+// @Summary      Add supplier price list
+// @Description  Adds a dated price list with quantity breaks for a variant the supplier supplies. Lists for a variant cannot overlap, except that a new open-ended list ends the current open-ended one the day before it starts. A list in force today also becomes the agreed cost.
+// @Tags         Suppliers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                                 true  "Supplier ID"
+// @Param        request  body  dto.CreateSupplierPriceListRequest  true  "Price list"
+// @Success      201  {object}  response.Response{data=dto.SupplierPriceListResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Router       /suppliers/{id}/price-lists [post]
+func (h *SupplierPriceListHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	var req dto.CreateSupplierPriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	list := &entity.SupplierPriceList{
+		SupplierID: id,
+		VariantID:  req.VariantID,
+		Notes:      req.Notes,
+	}
+	if list.ValidFrom, err = time.Parse("2006-01-02", req.ValidFrom); err != nil {
+		response.BadRequest(c, "Invalid valid_from format, expected YYYY-MM-DD")
+		return
+	}
+	if req.ValidTo != "" {
+		t, err := time.Parse("2006-01-02", req.ValidTo)
+		if err != nil {
+			response.BadRequest(c, "Invalid valid_to format, expected YYYY-MM-DD")
+			return
+		}
+		list.ValidTo = &t
+	}
+	for _, t := range req.Tiers {
+		list.Tiers = append(list.Tiers, entity.SupplierPriceTier{MinQuantity: t.MinQuantity, UnitPrice: t.UnitPrice})
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		createdBy := userID.(int64)
+		list.CreatedByUserID = &createdBy
+	}
+
+	if err := h.priceListService.Create(c.Request.Context(), list); err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrSupplierNotFound):
+			response.NotFound(c, "Supplier not found")
+		case errors.Is(err, domainErrors.ErrVariantNotSupplied):
+			response.BadRequest(c, "Supplier does not supply this variant; add the variant to the supplier first")
+		case errors.Is(err, domainErrors.ErrPriceListOverlap):
+			response.Conflict(c, "Price list overlaps another price list for this variant")
+		case errors.Is(err, domainErrors.ErrInvalidInput):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalErrorDebug(c, "Failed to create price list", err)
+		}
+		return
+	}
+
+	created, err := h.priceListService.Get(c.Request.Context(), list.ID)
+	if err != nil {
+		response.InternalErrorDebug(c, "Failed to load price list", err)
+		return
+	}
+	response.Created(c, "Price list created", mapSupplierPriceListResponse(created))
+}
+
+// List godoc
+// @Summary      List supplier price lists
+// @Description  Returns a supplier's price lists with their quantity breaks, latest first for each variant
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path   int  true   "Supplier ID"
+// @Param        variant_id  query  int  false  "Only price lists for this variant"
+// @Success      200  {object}  response.Response{data=[]dto.SupplierPriceListResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /suppliers/{id}/price-lists [get]
+func (h *SupplierPriceListHandler) List(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	var variantID *int64
+	if v := c.Query("variant_id"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid variant_id")
+			return
+		}
+		variantID = &parsed
+	}
+
+	lists, err := h.priceListService.List(c.Request.Context(), id, variantID)
+	if err != nil {
+		if err == domainErrors.ErrSupplierNotFound {
+			response.NotFound(c, "Supplier not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to list price lists", err)
+		return
+	}
+
+	resp := make([]dto.SupplierPriceListResponse, 0, len(lists))
+	for i := range lists {
+		resp = append(resp, mapSupplierPriceListResponse(&lists[i]))
+	}
+	response.OK(c, "Price lists retrieved", resp)
+}
+
+// Get godoc
+// @Summary      Get supplier price list
+// @Description  Returns a supplier price list with its quantity breaks
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  int  true  "Supplier ID"
+// @Param        listId  path  int  true  "Price list ID"
+// @Success      200  {object}  response.Response{data=dto.SupplierPriceListResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /suppliers/{id}/price-lists/{listId} [get]
+func (h *SupplierPriceListHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	listID, err := strconv.ParseInt(c.Param("listId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid price list ID")
+		return
+	}
+
+	list, err := h.priceListService.Get(c.Request.Context(), listID)
+	if err != nil {
+		if err == domainErrors.ErrPriceListNotFound {
+			response.NotFound(c, "Price list not found")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to get price list", err)
+		return
+	}
+	if list.SupplierID != id {
+		response.NotFound(c, "Price list not found")
+		return
+	}
+	response.OK(c, "Price list retrieved", mapSupplierPriceListResponse(list))
+}
+
+// Price godoc
+// @Summary      Supplier price on a date
+// @Description  Returns the unit price a supplier charges for a quantity of a variant on a date, from the quantity break it reaches on the price list in force then, or the agreed cost when no list covers the date
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Supplier ID"
+// @Param        variant_id  query  int     true   "Variant ID"
+// @Param        date        query  string  false  "Date (YYYY-MM-DD), defaults to today"
+// @Param        quantity    query  number  false  "Quantity ordered, defaults to 0 for the base price"
+// @Success      200  {object}  response.Response{data=dto.SupplierPriceResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /suppliers/{id}/price [get]
+func (h *SupplierPriceListHandler) Price(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	variantID, err := strconv.ParseInt(c.Query("variant_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "variant_id is required")
+		return
+	}
+	on := time.Now()
+	if d := c.Query("date"); d != "" {
+		if on, err = time.Parse("2006-01-02", d); err != nil {
+			response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	quantity := decimal.Zero
+	if q := c.Query("quantity"); q != "" {
+		if quantity, err = decimal.NewFromString(q); err != nil {
+			response.BadRequest(c, "Invalid quantity")
+			return
+		}
+	}
+
+	price, err := h.priceListService.EffectivePrice(c.Request.Context(), id, variantID, on, quantity)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrSupplierPriceNotFound:
+			response.NotFound(c, "No price agreed with the supplier for this variant on that date")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "Quantity cannot be negative")
+		default:
+			response.InternalErrorDebug(c, "Failed to look up supplier price", err)
+		}
+		return
+	}
+
+	resp := dto.SupplierPriceResponse{
+		SupplierID:  price.SupplierID,
+		VariantID:   price.VariantID,
+		Date:        price.Date.Format("2006-01-02"),
+		Quantity:    price.Quantity,
+		UnitPrice:   price.UnitPrice,
+		MinQuantity: price.MinQuantity,
+		PriceListID: price.PriceListID,
+	}
+	if price.ValidFrom != nil {
+		resp.ValidFrom = price.ValidFrom.Format("2006-01-02")
+	}
+	if price.ValidTo != nil {
+		resp.ValidTo = price.ValidTo.Format("2006-01-02")
+	}
+	response.OK(c, "Supplier price retrieved", resp)
+}
+
+func mapSupplierPriceListResponse(l *entity.SupplierPriceList) dto.SupplierPriceListResponse {
+	resp := dto.SupplierPriceListResponse{
+		ID:           l.ID,
+		SupplierID:   l.SupplierID,
+		SupplierName: l.SupplierName,
+		VariantID:    l.VariantID,
+		VariantName:  l.VariantName,
+		ValidFrom:    l.ValidFrom.Format("2006-01-02"),
+		Notes:        l.Notes,
+		CreatedAt:    l.CreatedAt,
+		Tiers:        make([]dto.SupplierPriceTierResponse, 0, len(l.Tiers)),
+	}
+	if l.ValidTo != nil {
+		resp.ValidTo = l.ValidTo.Format("2006-01-02")
+	}
+	for _, t := range l.Tiers {
+		resp.Tiers = append(resp.Tiers, dto.SupplierPriceTierResponse{
+			ID:          t.ID,
+			MinQuantity: t.MinQuantity,
+			UnitPrice:   t.UnitPrice,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qwikshelf/api/internal/application/dto"
+	"github.com/qwikshelf/api/internal/application/service"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/pkg/response"
+)
+
+// SupplierScorecardHandler handles supplier performance API requests
+type SupplierScorecardHandler struct {
+	scorecardService *service.SupplierScorecardService
+}
+
+// NewSupplierScorecardHandler creates a new supplier scorecard handler
+func NewSupplierScorecardHandler(scorecardService *service.SupplierScorecardService) *SupplierScorecardHandler {
+	return &SupplierScorecardHandler{scorecardService: scorecardService}
+}
+
+// List godoc
+// @Summary      Supplier scorecards
+// @Description  Scores every supplier active in the period on on-time delivery, fill rate, price variance against agreed cost, rejection rate and collection volume consistency, best first
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the scoring lookback before end_date"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.SupplierScorecardResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /suppliers/scorecards [get]
+func (h *SupplierScorecardHandler) List(c *gin.Context) {
+	from, to, ok := parseScorecardPeriod(c)
+	if !ok {
+		return
+	}
+
+	cards, err := h.scorecardService.Scorecards(c.Request.Context(), from, to)
+	if err != nil {
+		if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "start_date must not be after end_date")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to build supplier scorecards", err)
+		return
+	}
+
+	resp := make([]dto.SupplierScorecardResponse, 0, len(cards))
+	for i := range cards {
+		resp = append(resp, mapSupplierScorecardResponse(&cards[i]))
+	}
+	response.OK(c, "Supplier scorecards retrieved", resp)
+}
+
+// Get godoc
+// @Summary      Supplier scorecard
+// @Description  Scores a supplier over the period on on-time delivery, fill rate, price variance against agreed cost, rejection rate and collection volume consistency
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path   int     true   "Supplier ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the scoring lookback before end_date"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=dto.SupplierScorecardResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /suppliers/{id}/scorecard [get]
+func (h *SupplierScorecardHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+	from, to, ok := parseScorecardPeriod(c)
+	if !ok {
+		return
+	}
+
+	card, err := h.scorecardService.Scorecard(c.Request.Context(), id, from, to)
+	if err != nil {
+		switch err {
+		case domainErrors.ErrSupplierNotFound:
+			response.NotFound(c, "Supplier not found")
+		case domainErrors.ErrInvalidInput:
+			response.BadRequest(c, "start_date must not be after end_date")
+		default:
+			response.InternalErrorDebug(c, "Failed to build supplier scorecard", err)
+		}
+		return
+	}
+	response.OK(c, "Supplier scorecard retrieved", mapSupplierScorecardResponse(card))
+}
+
+// Rank godoc
+// @Summary      Rank suppliers of a variant
+// @Description  Ranks the suppliers offering a variant by scorecard over the period; suppliers without a score come last, ties go to the preferred supplier, then the lowest agreed cost
+// @Tags         Suppliers
+// @Produce      json
+// @Security     BearerAuth
+// @Param        variant_id  query  int     true   "Variant ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to the scoring lookback before end_date"
+// @Param        end_date    query  string  false  "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  response.Response{data=[]dto.SupplierRankingResponse}
+// @Failure      400  {object}  response.Response
+// @Router       /suppliers/ranking [get]
+func (h *SupplierScorecardHandler) Rank(c *gin.Context) {
+	variantID, err := strconv.ParseInt(c.Query("variant_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "variant_id is required")
+		return
+	}
+	from, to, ok := parseScorecardPeriod(c)
+	if !ok {
+		return
+	}
+
+	rankings, err := h.scorecardService.RankSuppliers(c.Request.Context(), variantID, from, to)
+	if err != nil {
+		if err == domainErrors.ErrInvalidInput {
+			response.BadRequest(c, "start_date must not be after end_date")
+			return
+		}
+		response.InternalErrorDebug(c, "Failed to rank suppliers", err)
+		return
+	}
+
+	resp := make([]dto.SupplierRankingResponse, 0, len(rankings))
+	for _, r := range rankings {
+		item := dto.SupplierRankingResponse{
+			Rank:        r.Rank,
+			SupplierID:  r.SupplierVariant.SupplierID,
+			AgreedCost:  r.SupplierVariant.AgreedCost,
+			IsPreferred: r.SupplierVariant.IsPreferred,
+		}
+		if r.SupplierVariant.Supplier != nil {
+			item.Supplier = r.SupplierVariant.Supplier.Name
+		}
+		if r.Scorecard != nil {
+			card := mapSupplierScorecardResponse(r.Scorecard)
+			item.Scorecard = &card
+		}
+		resp = append(resp, item)
+	}
+	response.OK(c, "Supplier ranking retrieved", resp)
+}
+
+// parseScorecardPeriod reads the optional inclusive date range; missing ends are left zero for the
+// service to default
+func parseScorecardPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	if sd := c.Query("start_date"); sd != "" {
+		t, err := time.Parse("2006-01-02", sd)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, expected YYYY-MM-DD")
+			return from, to, false
+		}
+		from = t
+	}
+	if ed := c.Query("end_date"); ed != "" {
+		t, err := time.Parse("2006-01-02", ed)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, expected YYYY-MM-DD")
+			return from, to, false
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	return from, to, true
+}
+
+func mapSupplierScorecardResponse(s *entity.SupplierScorecard) dto.SupplierScorecardResponse {
+	return dto.SupplierScorecardResponse{
+		SupplierID:        s.SupplierID,
+		SupplierName:      s.SupplierName,
+		StartDate:         s.From.Format("2006-01-02"),
+		EndDate:           s.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		OnTimeRate:        s.OnTimeRate,
+		FillRate:          s.FillRate,
+		PriceVariance:     s.PriceVariance,
+		RejectionRate:     s.RejectionRate,
+		VolumeConsistency: s.VolumeConsistency,
+		Score:             s.Score,
+		Receipts:          s.Receipts,
+		OnTimeReceipts:    s.OnTimeReceipts,
+		QuantityOrdered:   s.QuantityOrdered,
+		QuantityReceived:  s.QuantityReceived,
+		QuantityAccepted:  s.QuantityAccepted,
+		QuantityRejected:  s.QuantityRejected,
+		AgreedValue:       s.AgreedValue,
+		OrderedValue:      s.OrderedValue,
+		Collections:       s.Collections,
+		AdulteratedCount:  s.AdulteratedCount,
+		CollectionDays:    s.CollectionDays,
+		PeriodDays:        s.PeriodDays,
+		DailyVolumeMean:   s.DailyVolumeMean,
+		DailyVolumeStdDev: s.DailyVolumeStdDev,
+	}
+}
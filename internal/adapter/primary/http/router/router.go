@@ -35,6 +35,17 @@ type Config struct {
 	SubscriptionHandler   *handler.SubscriptionHandler
 	AuditMiddleware       *middleware.AuditMiddleware
 	ExpenseHandler        *handler.ExpenseHandler
+	ProductionHandler     *handler.ProductionHandler
+	RecipeHandler         *handler.RecipeHandler
+	CustomerLedgerHandler *handler.CustomerLedgerHandler
+	SubscriptionBillingHandler *handler.SubscriptionBillingHandler
+	StockTakeHandler           *handler.StockTakeHandler
+	ReplenishmentHandler       *handler.ReplenishmentHandler
+	ReservationHandler         *handler.ReservationHandler
+	SupplierPayablesHandler    *handler.SupplierPayablesHandler
+	CollectionRouteHandler     *handler.CollectionRouteHandler
+	SupplierScorecardHandler   *handler.SupplierScorecardHandler
+	SupplierPriceListHandler   *handler.SupplierPriceListHandler
 }
 
 // SetupRoutes configures all API routes
@@ -67,7 +78,7 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 			public.GET("/products", cfg.PublicHandler.ListProducts)
 			public.GET("/products/:id", cfg.PublicHandler.GetProduct)
 			public.GET("/categories", cfg.PublicHandler.ListCategories)
-			public.POST("/orders", cfg.PublicHandler.CreateOrder)
+			public.POST("/orders", cfg.AuthMiddleware.OptionalAuth(), cfg.PublicHandler.CreateOrder)
 			public.POST("/register", cfg.PublicHandler.Register)
 			public.POST("/login", cfg.PublicHandler.Login)
 			public.GET("/serviceability", cfg.PublicHandler.CheckServiceability)
@@ -77,6 +88,10 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 			{
 				me.GET("/orders", cfg.PublicHandler.GetMyOrders)
 				me.GET("/orders/:id", cfg.PublicHandler.GetOrderTracking)
+				me.GET("/addresses", cfg.PublicHandler.ListMyAddresses)
+				me.POST("/addresses", cfg.PublicHandler.CreateMyAddress)
+				me.PUT("/addresses/:id", cfg.PublicHandler.UpdateMyAddress)
+				me.DELETE("/addresses/:id", cfg.PublicHandler.DeleteMyAddress)
 			}
 		}
 
@@ -110,9 +125,15 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				customers.GET("", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerHandler.List)
 				customers.POST("", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerHandler.Create)
 				customers.POST("/bulk", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerHandler.CreateBulk)
+				customers.GET("/aging", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerLedgerHandler.Aging)
 				customers.GET("/:id", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerHandler.Get)
 				customers.PUT("/:id", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerHandler.Update)
 				customers.DELETE("/:id", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerHandler.Delete)
+				customers.POST("/:id/merge", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerHandler.Merge)
+				customers.GET("/:id/ledger", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerLedgerHandler.Ledger)
+				customers.GET("/:id/statement", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerLedgerHandler.Statement)
+				customers.GET("/:id/aging", cfg.AuthMiddleware.RequirePermission("customers.view"), cfg.CustomerLedgerHandler.CustomerAging)
+				customers.POST("/:id/payments", cfg.AuthMiddleware.RequirePermission("customers.manage"), cfg.CustomerLedgerHandler.RecordPayment)
 			}
 
 			// Role routes
@@ -122,6 +143,7 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				roles.POST("", cfg.AuthMiddleware.RequirePermission("roles.manage"), cfg.RoleHandler.Create)
 				roles.GET("/:id", cfg.AuthMiddleware.RequirePermission("roles.view"), cfg.RoleHandler.Get)
 				roles.PUT("/:id", cfg.AuthMiddleware.RequirePermission("roles.manage"), cfg.RoleHandler.Update)
+				roles.PUT("/:id/approval-limit", cfg.AuthMiddleware.RequirePermission("roles.manage"), cfg.RoleHandler.SetApprovalLimit)
 				roles.DELETE("/:id", cfg.AuthMiddleware.RequirePermission("roles.manage"), cfg.RoleHandler.Delete)
 			}
 
@@ -159,15 +181,36 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 			suppliers := protected.Group("/suppliers")
 			{
 				suppliers.GET("", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierHandler.List)
+				suppliers.GET("/scorecards", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierScorecardHandler.List)
+				suppliers.GET("/ranking", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierScorecardHandler.Rank)
 				suppliers.POST("", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierHandler.Create)
 				suppliers.GET("/:id", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierHandler.Get)
 				suppliers.PUT("/:id", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierHandler.Update)
 				suppliers.DELETE("/:id", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierHandler.Delete)
 				suppliers.GET("/:id/variants", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierHandler.ListVariants)
+				suppliers.GET("/:id/scorecard", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierScorecardHandler.Get)
+				suppliers.GET("/:id/price", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierPriceListHandler.Price)
+				suppliers.GET("/:id/price-lists", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierPriceListHandler.List)
+				suppliers.POST("/:id/price-lists", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierPriceListHandler.Create)
+				suppliers.GET("/:id/price-lists/:listId", cfg.AuthMiddleware.RequirePermission("suppliers.view"), cfg.SupplierPriceListHandler.Get)
 				suppliers.POST("/:id/variants", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierHandler.AddVariant)
 				suppliers.DELETE("/:id/variants/:variantId", cfg.AuthMiddleware.RequirePermission("suppliers.manage"), cfg.SupplierHandler.RemoveVariant)
 			}
 
+			// Supplier payables routes
+			payables := protected.Group("/payables")
+			{
+				payables.GET("/aging", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.Aging)
+				payables.GET("/bills", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.ListBills)
+				payables.POST("/bills/procurement", cfg.AuthMiddleware.RequirePermission("payables.manage"), cfg.SupplierPayablesHandler.BillProcurement)
+				payables.POST("/bills/collections", cfg.AuthMiddleware.RequirePermission("payables.manage"), cfg.SupplierPayablesHandler.BillCollections)
+				payables.GET("/bills/:id", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.GetBill)
+				payables.POST("/payments", cfg.AuthMiddleware.RequirePermission("payables.manage"), cfg.SupplierPayablesHandler.RecordPayment)
+				payables.GET("/suppliers/:id/ledger", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.Ledger)
+				payables.GET("/suppliers/:id/statement", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.Statement)
+				payables.GET("/suppliers/:id/aging", cfg.AuthMiddleware.RequirePermission("payables.view"), cfg.SupplierPayablesHandler.SupplierAging)
+			}
+
 			// Category routes
 			categories := protected.Group("/categories")
 			{
@@ -196,6 +239,10 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				products.GET("/:id", cfg.AuthMiddleware.RequirePermission("products.view"), cfg.VariantHandler.Get)
 				products.PUT("/:id", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.VariantHandler.Update)
 				products.DELETE("/:id", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.VariantHandler.Delete)
+				products.GET("/:id/recipe", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.RecipeHandler.Get)
+				products.POST("/:id/recipe", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.RecipeHandler.Create)
+				products.PUT("/:id/recipe", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.RecipeHandler.Update)
+				products.DELETE("/:id/recipe", cfg.AuthMiddleware.RequirePermission("products.manage"), cfg.RecipeHandler.Delete)
 			}
 
 			// Inventory routes
@@ -205,6 +252,45 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				inventory.GET("/warehouse/:warehouseId", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.ListByWarehouse)
 				inventory.POST("/adjust", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.Adjust)
 				inventory.POST("/transfer", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.Transfer)
+				inventory.GET("/transfers", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.ListTransfers)
+				inventory.GET("/transfers/in-transit", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.InTransit)
+				inventory.POST("/transfers/requests", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.RequestTransfer)
+				inventory.GET("/transfers/:id", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.GetTransfer)
+				inventory.POST("/transfers/:id/dispatch", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.DispatchTransfer)
+				inventory.POST("/transfers/:id/receive", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.ReceiveTransfer)
+				inventory.POST("/transfers/:id/cancel", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.CancelTransfer)
+				inventory.GET("/stock-card", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.StockCard)
+				inventory.GET("/valuation", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.Valuation)
+				inventory.GET("/cost-layers", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.ListCostLayers)
+				inventory.GET("/lots", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.ListLots)
+				inventory.GET("/lots/trace", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.TraceLot)
+				inventory.GET("/drift", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.Drift)
+				inventory.POST("/rebuild", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.InventoryHandler.Rebuild)
+				inventory.GET("/adjustments", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.ListAdjustments)
+				inventory.POST("/adjustments/:id/approve", cfg.AuthMiddleware.RequirePermission("inventory.approve"), cfg.InventoryHandler.ApproveAdjustment)
+				inventory.POST("/adjustments/:id/reject", cfg.AuthMiddleware.RequirePermission("inventory.approve"), cfg.InventoryHandler.RejectAdjustment)
+				inventory.GET("/shrinkage", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.InventoryHandler.Shrinkage)
+				inventory.GET("/reorder-settings", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.ReplenishmentHandler.ListSettings)
+				inventory.PUT("/reorder-settings", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.ReplenishmentHandler.SetSetting)
+				inventory.DELETE("/reorder-settings/:warehouseId/:variantId", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.ReplenishmentHandler.DeleteSetting)
+				inventory.GET("/availability", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.ReservationHandler.Availability)
+				inventory.GET("/reservations", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.ReservationHandler.List)
+				inventory.POST("/reservations/roster", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.ReservationHandler.ReserveRoster)
+				inventory.POST("/reservations/expire", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.ReservationHandler.Expire)
+			}
+
+			// Stock take routes
+			stockTakes := protected.Group("/stock-takes")
+			{
+				stockTakes.GET("", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.StockTakeHandler.List)
+				stockTakes.POST("", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.StockTakeHandler.Start)
+				stockTakes.GET("/cycle-counts", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.StockTakeHandler.CycleCountPlan)
+				stockTakes.PUT("/cycle-counts/schedules", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.StockTakeHandler.SetCycleSchedule)
+				stockTakes.GET("/:id", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.StockTakeHandler.Get)
+				stockTakes.POST("/:id/counts", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.StockTakeHandler.RecordCount)
+				stockTakes.GET("/:id/variance", cfg.AuthMiddleware.RequirePermission("inventory.view"), cfg.StockTakeHandler.Variance)
+				stockTakes.POST("/:id/post", cfg.AuthMiddleware.RequirePermission("inventory.approve"), cfg.StockTakeHandler.Post)
+				stockTakes.POST("/:id/cancel", cfg.AuthMiddleware.RequirePermission("inventory.manage"), cfg.StockTakeHandler.Cancel)
 			}
 
 			// Procurement routes
@@ -212,10 +298,27 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 			{
 				procurements.GET("", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.List)
 				procurements.POST("", cfg.AuthMiddleware.RequirePermission("procurement.manage"), cfg.ProcurementHandler.Create)
+				procurements.GET("/suggestions", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ReplenishmentHandler.Suggestions)
+				procurements.POST("/suggestions/generate", cfg.AuthMiddleware.RequirePermission("procurement.manage"), cfg.ReplenishmentHandler.GenerateDraftOrders)
 				procurements.GET("/:id", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.Get)
 				procurements.GET("/supplier/:supplierId", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.ListBySupplier)
+				procurements.GET("/:id/history", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.History)
+				procurements.POST("/:id/approve", cfg.AuthMiddleware.RequirePermission("procurement.approve"), cfg.ProcurementHandler.Approve)
 				procurements.PATCH("/:id/status", cfg.AuthMiddleware.RequirePermission("procurement.manage"), cfg.ProcurementHandler.UpdateStatus)
-				procurements.PATCH("/:id/receive", cfg.AuthMiddleware.RequirePermission("procurement.manage"), cfg.ProcurementHandler.ReceiveItems)
+				procurements.GET("/:id/receipts", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.ListReceipts)
+				procurements.POST("/:id/receipts", cfg.AuthMiddleware.RequirePermission("procurement.manage"), cfg.ProcurementHandler.ReceiveGoods)
+				procurements.GET("/:id/receipts/:receiptId", cfg.AuthMiddleware.RequirePermission("procurement.view"), cfg.ProcurementHandler.GetReceipt)
+			}
+
+			// Production routes
+			production := protected.Group("/production")
+			{
+				production.GET("", cfg.AuthMiddleware.RequirePermission("production.view"), cfg.ProductionHandler.List)
+				production.POST("", cfg.AuthMiddleware.RequirePermission("production.manage"), cfg.ProductionHandler.Create)
+				production.GET("/plan", cfg.AuthMiddleware.RequirePermission("production.manage"), cfg.ProductionHandler.Plan)
+				production.GET("/yield-deviations", cfg.AuthMiddleware.RequirePermission("production.view"), cfg.ProductionHandler.YieldDeviations)
+				production.GET("/:id", cfg.AuthMiddleware.RequirePermission("production.view"), cfg.ProductionHandler.Get)
+				production.GET("/batch/:batchCode/yield", cfg.AuthMiddleware.RequirePermission("production.view"), cfg.ProductionHandler.GetYield)
 			}
 
 			// Sale routes
@@ -224,6 +327,8 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				sales.GET("", cfg.AuthMiddleware.RequirePermission("sales.view"), cfg.SaleHandler.List)
 				sales.POST("", cfg.AuthMiddleware.RequirePermission("sales.manage"), cfg.SaleHandler.Create)
 				sales.GET("/:id", cfg.AuthMiddleware.RequirePermission("sales.view"), cfg.SaleHandler.Get)
+				sales.GET("/:id/history", cfg.AuthMiddleware.RequirePermission("sales.view"), cfg.SaleHandler.GetHistory)
+				sales.PATCH("/:id/status", cfg.AuthMiddleware.RequirePermission("sales.manage"), cfg.SaleHandler.UpdateStatus)
 			}
 
 			// Collection routes
@@ -231,6 +336,25 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 			{
 				collections.GET("", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionHandler.List)
 				collections.POST("", cfg.AuthMiddleware.RequirePermission("collections.manage"), cfg.CollectionHandler.Record)
+				collections.POST("/batch", cfg.AuthMiddleware.RequirePermission("collections.manage"), cfg.CollectionHandler.RecordShift)
+				collections.GET("/summary/shifts", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionHandler.ShiftSummary)
+				collections.GET("/summary/period", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionHandler.PeriodSummary)
+				collections.GET("/rate-charts", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionHandler.ListRateCharts)
+				collections.POST("/rate-charts", cfg.AuthMiddleware.RequirePermission("collection_rates.manage"), cfg.CollectionHandler.CreateRateChart)
+				collections.GET("/rate-charts/:id", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionHandler.GetRateChart)
+				collections.DELETE("/rate-charts/:id", cfg.AuthMiddleware.RequirePermission("collection_rates.manage"), cfg.CollectionHandler.DeactivateRateChart)
+			}
+
+			// Collection route planning routes
+			collectionRoutes := protected.Group("/collection-routes")
+			{
+				collectionRoutes.GET("", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionRouteHandler.List)
+				collectionRoutes.POST("", cfg.AuthMiddleware.RequirePermission("collection_routes.manage"), cfg.CollectionRouteHandler.Create)
+				collectionRoutes.GET("/mine", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionRouteHandler.Mine)
+				collectionRoutes.GET("/:id", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionRouteHandler.Get)
+				collectionRoutes.PUT("/:id", cfg.AuthMiddleware.RequirePermission("collection_routes.manage"), cfg.CollectionRouteHandler.Update)
+				collectionRoutes.PUT("/:id/stops", cfg.AuthMiddleware.RequirePermission("collection_routes.manage"), cfg.CollectionRouteHandler.SetStops)
+				collectionRoutes.GET("/:id/sheet", cfg.AuthMiddleware.RequirePermission("collections.view"), cfg.CollectionRouteHandler.ShiftSheet)
 			}
 
 			// Dashboard routes
@@ -244,11 +368,21 @@ func SetupRoutes(r *gin.Engine, cfg *Config) {
 				subscriptions.GET("", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionHandler.List)
 				subscriptions.POST("", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.Create)
 				subscriptions.GET("/roster", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionHandler.GetDailyRoster)
+				subscriptions.POST("/billing/run", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionBillingHandler.RunBilling)
+				subscriptions.GET("/invoices", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionBillingHandler.ListInvoices)
+				subscriptions.GET("/invoices/:id", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionBillingHandler.GetInvoice)
+				subscriptions.POST("/invoices/:id/payments", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionBillingHandler.RecordInvoicePayment)
 				subscriptions.GET("/:id", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionHandler.Get)
 				subscriptions.PUT("/:id", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.Update)
 				subscriptions.PATCH("/:id/status", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.UpdateStatus)
 				subscriptions.DELETE("/:id", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.Delete)
 				subscriptions.POST("/:id/deliveries", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.RecordDelivery)
+				subscriptions.GET("/:id/pauses", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionHandler.ListPauses)
+				subscriptions.POST("/:id/pauses", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.CreatePause)
+				subscriptions.DELETE("/:id/pauses/:pauseId", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.DeletePause)
+				subscriptions.GET("/:id/overrides", cfg.AuthMiddleware.RequirePermission("subscriptions.view"), cfg.SubscriptionHandler.ListOverrides)
+				subscriptions.PUT("/:id/overrides", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.SetOverride)
+				subscriptions.DELETE("/:id/overrides/:overrideId", cfg.AuthMiddleware.RequirePermission("subscriptions.manage"), cfg.SubscriptionHandler.DeleteOverride)
 			}
 
 			// Serviceability routes
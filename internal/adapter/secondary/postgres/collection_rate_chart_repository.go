@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// CollectionRateChartRepository implements repository.CollectionRateChartRepository
+type CollectionRateChartRepository struct {
+	db *DB
+}
+
+// NewCollectionRateChartRepository creates a new collection rate chart repository
+func NewCollectionRateChartRepository(db *DB) *CollectionRateChartRepository {
+	return &CollectionRateChartRepository{db: db}
+}
+
+const rateChartColumns = `id, name, variant_id, zone_id, supplier_id, effective_from, is_active, created_by_user_id, created_at`
+
+func scanRateChart(row pgx.Row, c *entity.CollectionRateChart) error {
+	return row.Scan(&c.ID, &c.Name, &c.VariantID, &c.ZoneID, &c.SupplierID, &c.EffectiveFrom, &c.IsActive, &c.CreatedByUserID, &c.CreatedAt)
+}
+
+// Create inserts a rate chart with its slabs
+func (r *CollectionRateChartRepository) Create(ctx context.Context, chart *entity.CollectionRateChart) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO collection_rate_charts (name, variant_id, zone_id, supplier_id, effective_from, is_active, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6)
+		RETURNING id, is_active, created_at
+	`, chart.Name, chart.VariantID, chart.ZoneID, chart.SupplierID, chart.EffectiveFrom, chart.CreatedByUserID,
+	).Scan(&chart.ID, &chart.IsActive, &chart.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create rate chart: %w", err)
+	}
+
+	for i := range chart.Slabs {
+		s := &chart.Slabs[i]
+		err := tx.QueryRow(ctx, `
+			INSERT INTO collection_rate_slabs (chart_id, min_fat_percent, min_snf_percent, rate)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, chart.ID, s.MinFatPercent, s.MinSNFPercent, s.Rate).Scan(&s.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add rate slab: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a rate chart with its slabs
+func (r *CollectionRateChartRepository) GetByID(ctx context.Context, id int64) (*entity.CollectionRateChart, error) {
+	chart := &entity.CollectionRateChart{}
+	err := scanRateChart(r.db.Conn(ctx).QueryRow(ctx, `SELECT `+rateChartColumns+` FROM collection_rate_charts WHERE id = $1`, id), chart)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrRateChartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadSlabs(ctx, []*entity.CollectionRateChart{chart}); err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+// List retrieves rate charts matching the filter, newest first
+func (r *CollectionRateChartRepository) List(ctx context.Context, filter repository.CollectionRateChartFilter) ([]entity.CollectionRateChart, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.VariantID != nil {
+		args = append(args, *filter.VariantID)
+		conditions = append(conditions, fmt.Sprintf("variant_id = $%d", len(args)))
+	}
+	if filter.ZoneID != nil {
+		args = append(args, *filter.ZoneID)
+		conditions = append(conditions, fmt.Sprintf("zone_id = $%d", len(args)))
+	}
+	if filter.SupplierID != nil {
+		args = append(args, *filter.SupplierID)
+		conditions = append(conditions, fmt.Sprintf("supplier_id = $%d", len(args)))
+	}
+	if filter.ActiveOnly {
+		conditions = append(conditions, "is_active")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `SELECT `+rateChartColumns+` FROM collection_rate_charts `+where+` ORDER BY effective_from DESC, id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate charts: %w", err)
+	}
+	defer rows.Close()
+
+	charts := []entity.CollectionRateChart{}
+	for rows.Next() {
+		var c entity.CollectionRateChart
+		if err := scanRateChart(rows, &c); err != nil {
+			return nil, err
+		}
+		charts = append(charts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*entity.CollectionRateChart, len(charts))
+	for i := range charts {
+		ptrs[i] = &charts[i]
+	}
+	if err := r.loadSlabs(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return charts, nil
+}
+
+// Deactivate retires a rate chart so it no longer prices new collections
+func (r *CollectionRateChartRepository) Deactivate(ctx context.Context, id int64) error {
+	tag, err := r.db.Conn(ctx).Exec(ctx, `UPDATE collection_rate_charts SET is_active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domainErrors.ErrRateChartNotFound
+	}
+	return nil
+}
+
+// FindApplicable returns the active chart for the variant in force on the date, preferring the
+// supplier's own chart, then its zone's, then the default chart; nil when none applies
+func (r *CollectionRateChartRepository) FindApplicable(ctx context.Context, variantID, supplierID int64, zoneID *int64, on time.Time) (*entity.CollectionRateChart, error) {
+	query := `
+		SELECT ` + rateChartColumns + `
+		FROM collection_rate_charts
+		WHERE variant_id = $1 AND is_active AND effective_from <= $4::date
+		  AND (supplier_id = $2 OR (supplier_id IS NULL AND (zone_id = $3 OR zone_id IS NULL)))
+		ORDER BY (supplier_id IS NOT NULL) DESC, (zone_id IS NOT NULL) DESC, effective_from DESC, id DESC
+		LIMIT 1
+	`
+	chart := &entity.CollectionRateChart{}
+	err := scanRateChart(r.db.Conn(ctx).QueryRow(ctx, query, variantID, supplierID, zoneID, on), chart)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rate chart: %w", err)
+	}
+	if err := r.loadSlabs(ctx, []*entity.CollectionRateChart{chart}); err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+func (r *CollectionRateChartRepository) loadSlabs(ctx context.Context, charts []*entity.CollectionRateChart) error {
+	if len(charts) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(charts))
+	byID := make(map[int64]*entity.CollectionRateChart, len(charts))
+	for i, c := range charts {
+		ids[i] = c.ID
+		c.Slabs = []entity.CollectionRateSlab{}
+		byID[c.ID] = c
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT id, chart_id, min_fat_percent, min_snf_percent, rate
+		FROM collection_rate_slabs
+		WHERE chart_id = ANY($1)
+		ORDER BY chart_id, min_fat_percent, min_snf_percent
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load rate slabs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.CollectionRateSlab
+		var chartID int64
+		if err := rows.Scan(&s.ID, &chartID, &s.MinFatPercent, &s.MinSNFPercent, &s.Rate); err != nil {
+			return err
+		}
+		byID[chartID].Slabs = append(byID[chartID].Slabs, s)
+	}
+	return rows.Err()
+}
@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/qwikshelf/api/internal/domain/entity"
+	"github.com/qwikshelf/api/internal/domain/repository"
 )
 
 type CollectionRepository struct {
@@ -15,22 +18,40 @@ func NewCollectionRepository(db *DB) *CollectionRepository {
 	return &CollectionRepository{db: db}
 }
 
+const collectionColumns = `
+		c.id, c.variant_id, c.supplier_id, c.agent_id, c.warehouse_id, c.weight, c.collected_at, c.shift, c.notes,
+		c.fat_percent, c.snf_percent, c.temperature, c.adulteration_flags, c.rate_chart_id, c.rate, c.amount,
+		v.name, s.name, u.username`
+
+func scanCollection(row pgx.Row, c *entity.Collection) error {
+	return row.Scan(
+		&c.ID, &c.VariantID, &c.SupplierID, &c.AgentID, &c.WarehouseID, &c.Weight, &c.CollectedAt, &c.Shift, &c.Notes,
+		&c.FatPercent, &c.SNFPercent, &c.Temperature, &c.AdulterationFlags, &c.RateChartID, &c.Rate, &c.Amount,
+		&c.Variant.Name, &c.Supplier.Name, &c.Agent.Username,
+	)
+}
+
 func (r *CollectionRepository) Create(ctx context.Context, collection *entity.Collection) error {
+	if collection.AdulterationFlags == nil {
+		collection.AdulterationFlags = []string{}
+	}
 	query := `
-		INSERT INTO collections (variant_id, supplier_id, agent_id, warehouse_id, weight, collected_at, notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO collections (variant_id, supplier_id, agent_id, warehouse_id, weight, collected_at, shift, notes,
+		                         fat_percent, snf_percent, temperature, adulteration_flags, rate_chart_id, rate, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, collected_at
 	`
-	return r.db.Pool.QueryRow(ctx, query,
+	return r.db.Conn(ctx).QueryRow(ctx, query,
 		collection.VariantID, collection.SupplierID, collection.AgentID,
-		collection.WarehouseID, collection.Weight, collection.CollectedAt, collection.Notes,
+		collection.WarehouseID, collection.Weight, collection.CollectedAt, collection.Shift, collection.Notes,
+		collection.FatPercent, collection.SNFPercent, collection.Temperature, collection.AdulterationFlags,
+		collection.RateChartID, collection.Rate, collection.Amount,
 	).Scan(&collection.ID, &collection.CollectedAt)
 }
 
 func (r *CollectionRepository) GetByID(ctx context.Context, id int64) (*entity.Collection, error) {
 	query := `
-		SELECT c.id, c.variant_id, c.supplier_id, c.agent_id, c.warehouse_id, c.weight, c.collected_at, c.notes,
-		       v.name, s.name, u.username
+		SELECT` + collectionColumns + `
 		FROM collections c
 		JOIN product_variants v ON c.variant_id = v.id
 		JOIN suppliers s ON c.supplier_id = s.id
@@ -42,38 +63,61 @@ func (r *CollectionRepository) GetByID(ctx context.Context, id int64) (*entity.C
 		Supplier: &entity.Supplier{},
 		Agent:    &entity.User{},
 	}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.VariantID, &c.SupplierID, &c.AgentID, &c.WarehouseID, &c.Weight, &c.CollectedAt, &c.Notes,
-		&c.Variant.Name, &c.Supplier.Name, &c.Agent.Username,
-	)
+	err := scanCollection(r.db.Conn(ctx).QueryRow(ctx, query, id), c)
 	if err == pgx.ErrNoRows {
 		return nil, nil // Or return a domain error
 	}
 	return c, err
 }
 
-func (r *CollectionRepository) List(ctx context.Context, offset, limit int) ([]entity.Collection, int64, error) {
-	countQuery := `SELECT COUNT(*) FROM collections`
+func (r *CollectionRepository) List(ctx context.Context, filter repository.CollectionFilter, offset, limit int) ([]entity.Collection, int64, error) {
+	where, args := collectionFilterClause(filter)
+
+	countQuery := `SELECT COUNT(*) FROM collections c ` + where
 	var total int64
-	err := r.db.Pool.QueryRow(ctx, countQuery).Scan(&total)
+	err := r.db.Conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `
-		SELECT c.id, c.variant_id, c.supplier_id, c.agent_id, c.warehouse_id, c.weight, c.collected_at, c.notes,
-		       v.name, s.name, u.username
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT`+collectionColumns+`
 		FROM collections c
 		JOIN product_variants v ON c.variant_id = v.id
 		JOIN suppliers s ON c.supplier_id = s.id
 		JOIN users u ON c.agent_id = u.id
+		%s
 		ORDER BY c.collected_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+	collections, err := r.query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
+	return collections, total, nil
+}
+
+// ListAll returns every collection matching the filter, oldest first
+func (r *CollectionRepository) ListAll(ctx context.Context, filter repository.CollectionFilter) ([]entity.Collection, error) {
+	where, args := collectionFilterClause(filter)
+	query := `
+		SELECT` + collectionColumns + `
+		FROM collections c
+		JOIN product_variants v ON c.variant_id = v.id
+		JOIN suppliers s ON c.supplier_id = s.id
+		JOIN users u ON c.agent_id = u.id
+		` + where + `
+		ORDER BY c.collected_at, c.id
+	`
+	return r.query(ctx, query, args...)
+}
+
+func (r *CollectionRepository) query(ctx context.Context, query string, args ...interface{}) ([]entity.Collection, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var collections []entity.Collection
@@ -83,15 +127,115 @@ func (r *CollectionRepository) List(ctx context.Context, offset, limit int) ([]e
 			Supplier: &entity.Supplier{},
 			Agent:    &entity.User{},
 		}
-		err := rows.Scan(
-			&c.ID, &c.VariantID, &c.SupplierID, &c.AgentID, &c.WarehouseID, &c.Weight, &c.CollectedAt, &c.Notes,
-			&c.Variant.Name, &c.Supplier.Name, &c.Agent.Username,
-		)
-		if err != nil {
-			return nil, 0, err
+		if err := scanCollection(rows, &c); err != nil {
+			return nil, err
 		}
 		collections = append(collections, c)
 	}
+	return collections, rows.Err()
+}
 
-	return collections, total, nil
+func collectionFilterClause(filter repository.CollectionFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.AgentID != nil {
+		add("c.agent_id = $%d", *filter.AgentID)
+	}
+	if filter.SupplierID != nil {
+		add("c.supplier_id = $%d", *filter.SupplierID)
+	}
+	if filter.SupplierIDs != nil {
+		add("c.supplier_id = ANY($%d)", filter.SupplierIDs)
+	}
+	if filter.WarehouseID != nil {
+		add("c.warehouse_id = $%d", *filter.WarehouseID)
+	}
+	if filter.Shift != nil {
+		add("c.shift = $%d", *filter.Shift)
+	}
+	if filter.From != nil {
+		add("c.collected_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("c.collected_at < $%d", *filter.To)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// SummarizeByShift totals collections per supplier, day and shift
+func (r *CollectionRepository) SummarizeByShift(ctx context.Context, filter repository.CollectionSummaryFilter) ([]entity.CollectionSummary, error) {
+	return r.summarize(ctx, filter, true)
+}
+
+// SummarizeByPeriod totals collections per supplier over the whole filter period
+func (r *CollectionRepository) SummarizeByPeriod(ctx context.Context, filter repository.CollectionSummaryFilter) ([]entity.CollectionSummary, error) {
+	return r.summarize(ctx, filter, false)
+}
+
+// summarize aggregates collections per supplier, and per day and shift when byShift is set.
+// Fat and SNF are averaged over the weight they were measured on.
+func (r *CollectionRepository) summarize(ctx context.Context, filter repository.CollectionSummaryFilter, byShift bool) ([]entity.CollectionSummary, error) {
+	conditions := []string{"c.collected_at >= $1", "c.collected_at < $2"}
+	args := []interface{}{filter.From, filter.To}
+	if filter.SupplierID != nil {
+		args = append(args, *filter.SupplierID)
+		conditions = append(conditions, fmt.Sprintf("c.supplier_id = $%d", len(args)))
+	}
+	if filter.WarehouseID != nil {
+		args = append(args, *filter.WarehouseID)
+		conditions = append(conditions, fmt.Sprintf("c.warehouse_id = $%d", len(args)))
+	}
+
+	dayColumns, groupBy, orderBy := "NULL::date, NULL::varchar", "c.supplier_id, s.name", "s.name, c.supplier_id"
+	if byShift {
+		dayColumns = "c.collected_at::date, c.shift"
+		groupBy = "c.supplier_id, s.name, c.collected_at::date, c.shift"
+		orderBy = "c.collected_at::date, c.shift DESC, s.name, c.supplier_id"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT c.supplier_id, s.name, %s,
+		       COUNT(*), COALESCE(SUM(c.weight), 0),
+		       ROUND(SUM(c.fat_percent * c.weight) / NULLIF(SUM(c.weight) FILTER (WHERE c.fat_percent IS NOT NULL), 0), 2),
+		       ROUND(SUM(c.snf_percent * c.weight) / NULLIF(SUM(c.weight) FILTER (WHERE c.snf_percent IS NOT NULL), 0), 2),
+		       COUNT(*) FILTER (WHERE cardinality(c.adulteration_flags) > 0),
+		       COALESCE(SUM(c.amount), 0),
+		       COALESCE(SUM(c.weight) FILTER (WHERE c.amount IS NULL), 0)
+		FROM collections c
+		JOIN suppliers s ON s.id = c.supplier_id
+		WHERE %s
+		GROUP BY %s
+		ORDER BY %s
+	`, dayColumns, strings.Join(conditions, " AND "), groupBy, orderBy)
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize collections: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []entity.CollectionSummary{}
+	for rows.Next() {
+		var s entity.CollectionSummary
+		var shift *string
+		if err := rows.Scan(
+			&s.SupplierID, &s.SupplierName, &s.Date, &shift,
+			&s.Collections, &s.TotalWeight, &s.AvgFatPercent, &s.AvgSNFPercent,
+			&s.Adulterated, &s.TotalAmount, &s.UnpricedWeight,
+		); err != nil {
+			return nil, err
+		}
+		if shift != nil {
+			s.Shift = entity.CollectionShift(*shift)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
 }
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// CollectionRouteRepository implements repository.CollectionRouteRepository
+type CollectionRouteRepository struct {
+	db *DB
+}
+
+// NewCollectionRouteRepository creates a new collection route repository
+func NewCollectionRouteRepository(db *DB) *CollectionRouteRepository {
+	return &CollectionRouteRepository{db: db}
+}
+
+const collectionRouteColumns = `
+		r.id, r.name, r.agent_id, u.username, r.zone_id, r.warehouse_id, r.is_active, r.created_at, r.updated_at`
+
+func scanCollectionRoute(row pgx.Row, route *entity.CollectionRoute) error {
+	return row.Scan(&route.ID, &route.Name, &route.AgentID, &route.AgentName, &route.ZoneID,
+		&route.WarehouseID, &route.IsActive, &route.CreatedAt, &route.UpdatedAt)
+}
+
+// Create inserts a route with its stops
+func (r *CollectionRouteRepository) Create(ctx context.Context, route *entity.CollectionRoute) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO collection_routes (name, agent_id, zone_id, warehouse_id, is_active)
+		VALUES ($1, $2, $3, $4, TRUE)
+		RETURNING id, is_active, created_at, updated_at
+	`, route.Name, route.AgentID, route.ZoneID, route.WarehouseID,
+	).Scan(&route.ID, &route.IsActive, &route.CreatedAt, &route.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create collection route: %w", err)
+	}
+
+	if err := insertRouteStops(ctx, tx, route.ID, route.Stops); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a route with its stops in order
+func (r *CollectionRouteRepository) GetByID(ctx context.Context, id int64) (*entity.CollectionRoute, error) {
+	query := `
+		SELECT` + collectionRouteColumns + `
+		FROM collection_routes r
+		JOIN users u ON u.id = r.agent_id
+		WHERE r.id = $1
+	`
+	route := &entity.CollectionRoute{}
+	err := scanCollectionRoute(r.db.Conn(ctx).QueryRow(ctx, query, id), route)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrCollectionRouteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadStops(ctx, []*entity.CollectionRoute{route}); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// List retrieves routes, optionally for one agent, with their stops
+func (r *CollectionRouteRepository) List(ctx context.Context, agentID *int64, activeOnly bool) ([]entity.CollectionRoute, error) {
+	query := `
+		SELECT` + collectionRouteColumns + `
+		FROM collection_routes r
+		JOIN users u ON u.id = r.agent_id
+		WHERE ($1::bigint IS NULL OR r.agent_id = $1) AND (NOT $2 OR r.is_active)
+		ORDER BY r.name, r.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, agentID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := []entity.CollectionRoute{}
+	for rows.Next() {
+		var route entity.CollectionRoute
+		if err := scanCollectionRoute(rows, &route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*entity.CollectionRoute, len(routes))
+	for i := range routes {
+		ptrs[i] = &routes[i]
+	}
+	if err := r.loadStops(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// Update saves a route's name, agent, zone, warehouse and active flag
+func (r *CollectionRouteRepository) Update(ctx context.Context, route *entity.CollectionRoute) error {
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		UPDATE collection_routes
+		SET name = $1, agent_id = $2, zone_id = $3, warehouse_id = $4, is_active = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING updated_at
+	`, route.Name, route.AgentID, route.ZoneID, route.WarehouseID, route.IsActive, route.ID).Scan(&route.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrCollectionRouteNotFound
+	}
+	return err
+}
+
+// SetStops replaces the route's stops with the given ones in order
+func (r *CollectionRouteRepository) SetStops(ctx context.Context, routeID int64, stops []entity.CollectionRouteStop) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM collection_route_stops WHERE route_id = $1`, routeID); err != nil {
+		return err
+	}
+	if err := insertRouteStops(ctx, tx, routeID, stops); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE collection_routes SET updated_at = NOW() WHERE id = $1`, routeID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertRouteStops(ctx context.Context, tx pgx.Tx, routeID int64, stops []entity.CollectionRouteStop) error {
+	for _, stop := range stops {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO collection_route_stops (route_id, supplier_id, sequence)
+			VALUES ($1, $2, $3)
+		`, routeID, stop.SupplierID, stop.Sequence)
+		if err != nil {
+			return fmt.Errorf("failed to add route stop: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *CollectionRouteRepository) loadStops(ctx context.Context, routes []*entity.CollectionRoute) error {
+	if len(routes) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(routes))
+	byID := make(map[int64]*entity.CollectionRoute, len(routes))
+	for i, route := range routes {
+		ids[i] = route.ID
+		route.Stops = []entity.CollectionRouteStop{}
+		byID[route.ID] = route
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT rs.route_id, rs.sequence, s.id, s.name, COALESCE(s.phone, ''), COALESCE(s.location, ''), s.zone_id
+		FROM collection_route_stops rs
+		JOIN suppliers s ON s.id = rs.supplier_id
+		WHERE rs.route_id = ANY($1)
+		ORDER BY rs.route_id, rs.sequence
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load route stops: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var routeID int64
+		stop := entity.CollectionRouteStop{Supplier: &entity.Supplier{}}
+		if err := rows.Scan(&routeID, &stop.Sequence, &stop.Supplier.ID, &stop.Supplier.Name,
+			&stop.Supplier.Phone, &stop.Supplier.Location, &stop.Supplier.ZoneID); err != nil {
+			return err
+		}
+		stop.SupplierID = stop.Supplier.ID
+		byID[routeID].Stops = append(byID[routeID].Stops, stop)
+	}
+	return rows.Err()
+}
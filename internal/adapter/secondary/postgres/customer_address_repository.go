@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// CustomerAddressRepository implements repository.CustomerAddressRepository
+type CustomerAddressRepository struct {
+	db *DB
+}
+
+// NewCustomerAddressRepository creates a new customer address repository
+func NewCustomerAddressRepository(db *DB) *CustomerAddressRepository {
+	return &CustomerAddressRepository{db: db}
+}
+
+// Create adds an address to a customer's address book.
+// A default address replaces the customer's previous default.
+func (r *CustomerAddressRepository) Create(ctx context.Context, a *entity.CustomerAddress) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if a.IsDefault {
+		if _, err := tx.Exec(ctx, `UPDATE customer_addresses SET is_default = false WHERE customer_id = $1 AND is_default`, a.CustomerID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO customer_addresses (
+			customer_id, label, contact_name, phone, address_line1, address_line2,
+			landmark, city, pincode, latitude, longitude, zone_id, is_default
+		)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		a.CustomerID, a.Label, a.ContactName, a.Phone, a.AddressLine1, a.AddressLine2,
+		a.Landmark, a.City, a.Pincode, a.Latitude, a.Longitude, a.ZoneID, a.IsDefault,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves an address by ID
+func (r *CustomerAddressRepository) GetByID(ctx context.Context, id int64) (*entity.CustomerAddress, error) {
+	query := customerAddressSelect + ` WHERE ca.id = $1`
+	a, err := scanCustomerAddress(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrAddressNotFound
+	}
+	return a, err
+}
+
+// ListByCustomer retrieves a customer's addresses, default first
+func (r *CustomerAddressRepository) ListByCustomer(ctx context.Context, customerID int64) ([]entity.CustomerAddress, error) {
+	query := customerAddressSelect + ` WHERE ca.customer_id = $1 ORDER BY ca.is_default DESC, ca.created_at`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []entity.CustomerAddress{}
+	for rows.Next() {
+		a, err := scanCustomerAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *a)
+	}
+	return addresses, rows.Err()
+}
+
+// Update updates an address.
+// A default address replaces the customer's previous default.
+func (r *CustomerAddressRepository) Update(ctx context.Context, a *entity.CustomerAddress) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if a.IsDefault {
+		if _, err := tx.Exec(ctx, `UPDATE customer_addresses SET is_default = false WHERE customer_id = $1 AND id <> $2 AND is_default`, a.CustomerID, a.ID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE customer_addresses
+		SET label = $1, contact_name = NULLIF($2, ''), phone = NULLIF($3, ''), address_line1 = $4,
+		    address_line2 = NULLIF($5, ''), landmark = NULLIF($6, ''), city = NULLIF($7, ''), pincode = $8,
+		    latitude = $9, longitude = $10, zone_id = $11, is_default = $12, updated_at = NOW()
+		WHERE id = $13
+		RETURNING updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		a.Label, a.ContactName, a.Phone, a.AddressLine1, a.AddressLine2, a.Landmark, a.City, a.Pincode,
+		a.Latitude, a.Longitude, a.ZoneID, a.IsDefault, a.ID,
+	).Scan(&a.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrAddressNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes an address from the address book
+func (r *CustomerAddressRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.Conn(ctx).Exec(ctx, `DELETE FROM customer_addresses WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrAddressNotFound
+	}
+	return nil
+}
+
+const customerAddressSelect = `
+	SELECT ca.id, ca.customer_id, ca.label, COALESCE(ca.contact_name, ''), COALESCE(ca.phone, ''),
+	       ca.address_line1, COALESCE(ca.address_line2, ''), COALESCE(ca.landmark, ''), COALESCE(ca.city, ''),
+	       ca.pincode, ca.latitude, ca.longitude, ca.zone_id, COALESCE(dz.name, ''), ca.is_default,
+	       ca.created_at, ca.updated_at
+	FROM customer_addresses ca
+	LEFT JOIN delivery_zones dz ON dz.id = ca.zone_id`
+
+func scanCustomerAddress(row pgx.Row) (*entity.CustomerAddress, error) {
+	var a entity.CustomerAddress
+	err := row.Scan(
+		&a.ID, &a.CustomerID, &a.Label, &a.ContactName, &a.Phone,
+		&a.AddressLine1, &a.AddressLine2, &a.Landmark, &a.City,
+		&a.Pincode, &a.Latitude, &a.Longitude, &a.ZoneID, &a.ZoneName, &a.IsDefault,
+		&a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
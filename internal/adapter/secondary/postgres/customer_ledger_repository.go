@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// CustomerLedgerRepository implements repository.CustomerLedgerRepository
+type CustomerLedgerRepository struct {
+	db *DB
+}
+
+// NewCustomerLedgerRepository creates a new customer ledger repository
+func NewCustomerLedgerRepository(db *DB) *CustomerLedgerRepository {
+	return &CustomerLedgerRepository{db: db}
+}
+
+// GetCreditAccountForUpdate locks the customer row so concurrent credit sales are checked one at a time
+func (r *CustomerLedgerRepository) GetCreditAccountForUpdate(ctx context.Context, customerID int64) (*entity.CustomerCreditAccount, error) {
+	account := &entity.CustomerCreditAccount{CustomerID: customerID}
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(credit_limit, 0), COALESCE(payment_terms, 'cash')
+		FROM customers WHERE id = $1
+		FOR UPDATE
+	`, customerID).Scan(&account.CreditLimit, &account.PaymentTerms)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(SUM(debit - credit), 0) FROM customer_ledger_entries WHERE customer_id = $1
+	`, customerID).Scan(&account.Balance)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// AddEntry posts an entry to a customer's ledger
+func (r *CustomerLedgerRepository) AddEntry(ctx context.Context, e *entity.CustomerLedgerEntry) error {
+	query := `
+		INSERT INTO customer_ledger_entries (customer_id, entry_type, sale_id, invoice_id, payment_id, debit, credit, description, entry_date, due_date, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), COALESCE($9, NOW()), $10, $11)
+		RETURNING id, entry_date, created_at
+	`
+	var entryDate *time.Time
+	if !e.EntryDate.IsZero() {
+		entryDate = &e.EntryDate
+	}
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		e.CustomerID, e.EntryType, e.SaleID, e.InvoiceID, e.PaymentID, e.Debit, e.Credit, e.Description, entryDate, e.DueDate, e.CreatedByUserID,
+	).Scan(&e.ID, &e.EntryDate, &e.CreatedAt)
+}
+
+// CreatePayment records a payment received from a customer
+func (r *CustomerLedgerRepository) CreatePayment(ctx context.Context, p *entity.CustomerPayment) error {
+	query := `
+		INSERT INTO customer_payments (customer_id, invoice_id, amount, payment_method, reference, notes, received_at, recorded_by_user_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		p.CustomerID, p.InvoiceID, p.Amount, p.PaymentMethod, p.Reference, p.Notes, p.ReceivedAt, p.RecordedByUserID,
+	).Scan(&p.ID, &p.CreatedAt)
+}
+
+// GetBalance returns a customer's balance from entries dated before the given time
+func (r *CustomerLedgerRepository) GetBalance(ctx context.Context, customerID int64, before time.Time) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(SUM(debit - credit), 0) FROM customer_ledger_entries
+		WHERE customer_id = $1 AND entry_date < $2
+	`, customerID, before).Scan(&balance)
+	return balance, err
+}
+
+// ListEntries retrieves a customer's ledger entries dated within [from, to)
+func (r *CustomerLedgerRepository) ListEntries(ctx context.Context, customerID int64, from, to time.Time) ([]entity.CustomerLedgerEntry, error) {
+	query := ledgerEntrySelect + `
+		WHERE customer_id = $1 AND entry_date >= $2 AND entry_date < $3
+		ORDER BY entry_date, id
+	`
+	return r.queryEntries(ctx, query, customerID, from, to)
+}
+
+// ListEntriesUpTo retrieves ledger entries dated before asOf, optionally for one customer,
+// ordered by customer and date
+func (r *CustomerLedgerRepository) ListEntriesUpTo(ctx context.Context, customerID *int64, asOf time.Time) ([]entity.CustomerLedgerEntry, error) {
+	query := ledgerEntrySelect + `
+		WHERE entry_date < $1 AND ($2::int IS NULL OR customer_id = $2)
+		ORDER BY customer_id, entry_date, id
+	`
+	return r.queryEntries(ctx, query, asOf, customerID)
+}
+
+// ListBalances returns every customer with a non-zero balance from entries dated before asOf
+func (r *CustomerLedgerRepository) ListBalances(ctx context.Context, asOf time.Time) ([]entity.CustomerAging, error) {
+	query := `
+		SELECT c.id, c.name, SUM(le.debit - le.credit) AS balance
+		FROM customer_ledger_entries le
+		JOIN customers c ON c.id = le.customer_id
+		WHERE le.entry_date < $1
+		GROUP BY c.id, c.name
+		HAVING SUM(le.debit - le.credit) <> 0
+		ORDER BY balance DESC
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []entity.CustomerAging{}
+	for rows.Next() {
+		var a entity.CustomerAging
+		if err := rows.Scan(&a.CustomerID, &a.CustomerName, &a.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, a)
+	}
+	return balances, rows.Err()
+}
+
+const ledgerEntrySelect = `
+	SELECT id, customer_id, entry_type, sale_id, invoice_id, payment_id, debit, credit, COALESCE(description, ''),
+	       entry_date, due_date, created_by_user_id, created_at
+	FROM customer_ledger_entries`
+
+func (r *CustomerLedgerRepository) queryEntries(ctx context.Context, query string, args ...any) ([]entity.CustomerLedgerEntry, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []entity.CustomerLedgerEntry{}
+	for rows.Next() {
+		var e entity.CustomerLedgerEntry
+		if err := rows.Scan(
+			&e.ID, &e.CustomerID, &e.EntryType, &e.SaleID, &e.InvoiceID, &e.PaymentID, &e.Debit, &e.Credit, &e.Description,
+			&e.EntryDate, &e.DueDate, &e.CreatedByUserID, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
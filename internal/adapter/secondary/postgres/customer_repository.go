@@ -6,18 +6,17 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 )
 
 type CustomerRepository struct {
-	pool *pgxpool.Pool
+	db *DB
 }
 
 func NewCustomerRepository(db *DB) *CustomerRepository {
-	return &CustomerRepository{pool: db.Pool}
+	return &CustomerRepository{db: db}
 }
 
 func (r *CustomerRepository) Create(ctx context.Context, customer *entity.Customer) error {
@@ -30,7 +29,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entity.Custom
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at, updated_at
 	`
-	err := r.pool.QueryRow(ctx, query,
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
 		customer.Name, customer.Phone, customer.Email, customer.Address,
 		customer.GSTNumber, customer.CreditLimit, customer.PaymentTerms,
 		customer.CustomerCategory, customer.DeliveryRoute, customer.InternalNotes,
@@ -38,7 +37,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entity.Custom
 	).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
 
 	if err != nil {
-		if strings.Contains(err.Error(), "customers_phone_key") {
+		if strings.Contains(err.Error(), "phone_key") {
 			return fmt.Errorf("phone number already exists: %w", domainErrors.ErrInvalidInput)
 		}
 		return fmt.Errorf("failed to create customer: %w", err)
@@ -47,7 +46,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entity.Custom
 	// Fetch zone name if zone_id was provided
 	if customer.ZoneID != nil {
 		var zName string
-		err := r.pool.QueryRow(ctx, "SELECT name FROM delivery_zones WHERE id = $1", *customer.ZoneID).Scan(&zName)
+		err := r.db.Conn(ctx).QueryRow(ctx, "SELECT name FROM delivery_zones WHERE id = $1", *customer.ZoneID).Scan(&zName)
 		if err == nil {
 			customer.ZoneName = zName
 		}
@@ -66,7 +65,7 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *entity.Custom
 		WHERE id = $14
 		RETURNING updated_at
 	`
-	err := r.pool.QueryRow(ctx, query,
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
 		customer.Name, customer.Phone, customer.Email, customer.Address,
 		customer.GSTNumber, customer.CreditLimit, customer.PaymentTerms,
 		customer.CustomerCategory, customer.DeliveryRoute, customer.InternalNotes,
@@ -77,7 +76,7 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *entity.Custom
 		if err == pgx.ErrNoRows {
 			return domainErrors.ErrCustomerNotFound
 		}
-		if strings.Contains(err.Error(), "customers_phone_key") {
+		if strings.Contains(err.Error(), "phone_key") {
 			return fmt.Errorf("phone number already exists: %w", domainErrors.ErrInvalidInput)
 		}
 		return fmt.Errorf("failed to update customer: %w", err)
@@ -85,7 +84,7 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *entity.Custom
 
 	if customer.ZoneID != nil {
 		var zName string
-		_ = r.pool.QueryRow(ctx, "SELECT name FROM delivery_zones WHERE id = $1", *customer.ZoneID).Scan(&zName)
+		_ = r.db.Conn(ctx).QueryRow(ctx, "SELECT name FROM delivery_zones WHERE id = $1", *customer.ZoneID).Scan(&zName)
 		customer.ZoneName = zName
 	}
 
@@ -96,18 +95,183 @@ func (r *CustomerRepository) GetByID(ctx context.Context, id int64) (*entity.Cus
 	query := `
 		SELECT c.id, c.name, c.phone, c.email, c.address, c.gst_number, c.credit_limit,
 		       c.payment_terms, c.customer_category, c.delivery_route, c.internal_notes,
-			   c.zone_id, c.latitude, c.longitude, c.created_at, c.updated_at,
+			   c.zone_id, c.latitude, c.longitude, c.user_id, c.created_at, c.updated_at,
 			   COALESCE(dz.name, '') as zone_name
 		FROM customers c
 		LEFT JOIN delivery_zones dz ON c.zone_id = dz.id
 		WHERE c.id = $1
 	`
-	row := r.pool.QueryRow(ctx, query, id)
+	row := r.db.Conn(ctx).QueryRow(ctx, query, id)
 	return mapCustomerRow(row)
 }
 
+// GetByPhone returns the customer with the given phone that is not linked to a storefront account
+func (r *CustomerRepository) GetByPhone(ctx context.Context, phone string) (*entity.Customer, error) {
+	query := `
+		SELECT c.id, c.name, c.phone, c.email, c.address, c.gst_number, c.credit_limit,
+		       c.payment_terms, c.customer_category, c.delivery_route, c.internal_notes,
+			   c.zone_id, c.latitude, c.longitude, c.user_id, c.created_at, c.updated_at,
+			   COALESCE(dz.name, '') as zone_name
+		FROM customers c
+		LEFT JOIN delivery_zones dz ON c.zone_id = dz.id
+		WHERE c.phone = $1 AND c.user_id IS NULL
+	`
+	row := r.db.Conn(ctx).QueryRow(ctx, query, phone)
+	return mapCustomerRow(row)
+}
+
+// FindOrCreateByPhone returns the customer with the given phone that is not linked to an account,
+// creating a retail customer when none exists. Such phones are unique, so concurrent callers
+// resolve to the same row.
+func (r *CustomerRepository) FindOrCreateByPhone(ctx context.Context, customer *entity.Customer) error {
+	query := `
+		INSERT INTO customers (name, phone, address, zone_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (phone) WHERE user_id IS NULL DO NOTHING
+	`
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, customer.Name, customer.Phone, customer.Address, customer.ZoneID); err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	existing, err := r.GetByPhone(ctx, customer.Phone)
+	if err != nil {
+		return err
+	}
+	*customer = *existing
+	return nil
+}
+
+func (r *CustomerRepository) GetByUserID(ctx context.Context, userID int64) (*entity.Customer, error) {
+	query := `
+		SELECT c.id, c.name, c.phone, c.email, c.address, c.gst_number, c.credit_limit,
+		       c.payment_terms, c.customer_category, c.delivery_route, c.internal_notes,
+			   c.zone_id, c.latitude, c.longitude, c.user_id, c.created_at, c.updated_at,
+			   COALESCE(dz.name, '') as zone_name
+		FROM customers c
+		LEFT JOIN delivery_zones dz ON c.zone_id = dz.id
+		WHERE c.user_id = $1
+	`
+	row := r.db.Conn(ctx).QueryRow(ctx, query, userID)
+	return mapCustomerRow(row)
+}
+
+// CreateForUser creates the customer record of a storefront account. A customer with the same
+// phone but no account is left alone for staff to merge; another account with the phone, or a
+// record already linked to this account, is a conflict.
+func (r *CustomerRepository) CreateForUser(ctx context.Context, customer *entity.Customer) error {
+	query := `
+		INSERT INTO customers (name, phone, address, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int64
+	err := r.db.Conn(ctx).QueryRow(ctx, query, customer.Name, customer.Phone, customer.Address, customer.UserID).Scan(&id)
+	if err != nil {
+		if strings.Contains(err.Error(), "customers_user_id_key") || strings.Contains(err.Error(), "customers_account_phone_key") {
+			return domainErrors.ErrCustomerAlreadyLinked
+		}
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	created, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	*customer = *created
+	return nil
+}
+
+// Merge folds the source customer into the target: orders, addresses, subscriptions, invoices, ledger
+// entries and the linked storefront account move to the target, empty target fields are filled from the source,
+// and the source record is deleted. It must run inside a transaction.
+func (r *CustomerRepository) Merge(ctx context.Context, targetID, sourceID int64) error {
+	conn := r.db.Conn(ctx)
+
+	var sourceUserID *int64
+	err := conn.QueryRow(ctx, `SELECT user_id FROM customers WHERE id = $1 FOR UPDATE`, sourceID).Scan(&sourceUserID)
+	if err == pgx.ErrNoRows {
+		return domainErrors.ErrCustomerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var targetUserID *int64
+	err = conn.QueryRow(ctx, `SELECT user_id FROM customers WHERE id = $1 FOR UPDATE`, targetID).Scan(&targetUserID)
+	if err == pgx.ErrNoRows {
+		return domainErrors.ErrCustomerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if sourceUserID != nil && targetUserID != nil && *sourceUserID != *targetUserID {
+		return domainErrors.ErrCustomerAlreadyLinked
+	}
+
+	// Both customers invoiced for the same billing period cannot share one invoice per period
+	var overlappingInvoices bool
+	err = conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM subscription_invoices s
+			JOIN subscription_invoices t ON t.period_start = s.period_start AND t.customer_id = $1
+			WHERE s.customer_id = $2
+		)
+	`, targetID, sourceID).Scan(&overlappingInvoices)
+	if err != nil {
+		return err
+	}
+	if overlappingInvoices {
+		return domainErrors.ErrInvoiceExists
+	}
+
+	// The target keeps its default address; the source's addresses join as non-default entries
+	statements := []string{
+		`UPDATE sales SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE sale_delivery_addresses SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE customer_addresses SET is_default = false
+		 WHERE customer_id = $2 AND EXISTS (SELECT 1 FROM customer_addresses WHERE customer_id = $1 AND is_default)`,
+		`UPDATE customer_addresses SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE customer_subscriptions SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE customer_payments SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE subscription_invoices SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE customer_ledger_entries SET customer_id = $1 WHERE customer_id = $2`,
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(ctx, stmt, targetID, sourceID); err != nil {
+			return fmt.Errorf("failed to merge customer: %w", err)
+		}
+	}
+
+	// Release the source's unique phone and account link before copying them onto the target
+	var source entity.Customer
+	err = conn.QueryRow(ctx, `
+		DELETE FROM customers WHERE id = $1
+		RETURNING email, address, gst_number, delivery_route, internal_notes, zone_id, latitude, longitude
+	`, sourceID).Scan(
+		&source.Email, &source.Address, &source.GSTNumber, &source.DeliveryRoute, &source.InternalNotes,
+		&source.ZoneID, &source.Latitude, &source.Longitude,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete merged customer: %w", err)
+	}
+
+	_, err = conn.Exec(ctx, `
+		UPDATE customers
+		SET email = COALESCE(email, $2), address = COALESCE(address, $3), gst_number = COALESCE(gst_number, $4),
+		    delivery_route = COALESCE(delivery_route, $5), internal_notes = COALESCE(internal_notes, $6),
+		    zone_id = COALESCE(zone_id, $7), latitude = COALESCE(latitude, $8), longitude = COALESCE(longitude, $9),
+		    user_id = COALESCE(user_id, $10), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, targetID, source.Email, source.Address, source.GSTNumber, source.DeliveryRoute, source.InternalNotes,
+		source.ZoneID, source.Latitude, source.Longitude, sourceUserID)
+	if err != nil {
+		return fmt.Errorf("failed to update merged customer: %w", err)
+	}
+	return nil
+}
+
 func (r *CustomerRepository) Delete(ctx context.Context, id int64) error {
-	tag, err := r.pool.Exec(ctx, "DELETE FROM customers WHERE id = $1", id)
+	tag, err := r.db.Conn(ctx).Exec(ctx, "DELETE FROM customers WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
@@ -119,7 +283,7 @@ func (r *CustomerRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *CustomerRepository) List(ctx context.Context, offset, limit int) ([]*entity.Customer, int64, error) {
 	var total int64
-	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM customers").Scan(&total)
+	err := r.db.Conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM customers").Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count customers: %w", err)
 	}
@@ -127,14 +291,14 @@ func (r *CustomerRepository) List(ctx context.Context, offset, limit int) ([]*en
 	query := `
 		SELECT c.id, c.name, c.phone, c.email, c.address, c.gst_number, c.credit_limit,
 		       c.payment_terms, c.customer_category, c.delivery_route, c.internal_notes,
-			   c.zone_id, c.latitude, c.longitude, c.created_at, c.updated_at,
+			   c.zone_id, c.latitude, c.longitude, c.user_id, c.created_at, c.updated_at,
 			   COALESCE(dz.name, '') as zone_name
 		FROM customers c
 		LEFT JOIN delivery_zones dz ON c.zone_id = dz.id
 		ORDER BY c.name ASC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list customers: %w", err)
 	}
@@ -164,7 +328,7 @@ func mapCustomerRow(row customerRow) (*entity.Customer, error) {
 	err := row.Scan(
 		&c.ID, &c.Name, &c.Phone, &c.Email, &c.Address, &c.GSTNumber, &c.CreditLimit,
 		&c.PaymentTerms, &c.CustomerCategory, &c.DeliveryRoute, &c.InternalNotes,
-		&c.ZoneID, &c.Latitude, &c.Longitude, &c.CreatedAt, &c.UpdatedAt, &c.ZoneName,
+		&c.ZoneID, &c.Latitude, &c.Longitude, &c.UserID, &c.CreatedAt, &c.UpdatedAt, &c.ZoneName,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// InventoryAdjustmentRepository implements repository.InventoryAdjustmentRepository
+type InventoryAdjustmentRepository struct {
+	db *DB
+}
+
+// NewInventoryAdjustmentRepository creates a new inventory adjustment repository
+func NewInventoryAdjustmentRepository(db *DB) *InventoryAdjustmentRepository {
+	return &InventoryAdjustmentRepository{db: db}
+}
+
+// Create inserts a manual adjustment
+func (r *InventoryAdjustmentRepository) Create(ctx context.Context, a *entity.InventoryAdjustment) error {
+	query := `
+		INSERT INTO inventory_adjustments (warehouse_id, variant_id, reason_code, quantity_delta, unit_cost, value, notes,
+		                                   status, requested_by_user_id, reviewed_by_user_id, reviewed_at, movement_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		a.WarehouseID, a.VariantID, a.ReasonCode, a.QuantityDelta, a.UnitCost, a.Value, a.Notes,
+		a.Status, a.RequestedByUserID, a.ReviewedByUserID, a.ReviewedAt, a.MovementID,
+	).Scan(&a.ID, &a.CreatedAt)
+}
+
+const inventoryAdjustmentSelect = `
+	SELECT ia.id, ia.warehouse_id, w.name, ia.variant_id, pv.name, ia.reason_code, ia.quantity_delta,
+	       ia.unit_cost, ia.value, ia.notes, ia.status, ia.requested_by_user_id, ia.reviewed_by_user_id,
+	       ia.reviewed_at, ia.review_note, ia.movement_id, ia.created_at
+	FROM inventory_adjustments ia
+	JOIN warehouses w ON w.id = ia.warehouse_id
+	JOIN product_variants pv ON pv.id = ia.variant_id`
+
+// GetByID retrieves an adjustment by ID
+func (r *InventoryAdjustmentRepository) GetByID(ctx context.Context, id int64) (*entity.InventoryAdjustment, error) {
+	return r.get(ctx, inventoryAdjustmentSelect+` WHERE ia.id = $1`, id)
+}
+
+// GetByIDForUpdate retrieves an adjustment and locks it for review
+func (r *InventoryAdjustmentRepository) GetByIDForUpdate(ctx context.Context, id int64) (*entity.InventoryAdjustment, error) {
+	return r.get(ctx, inventoryAdjustmentSelect+` WHERE ia.id = $1 FOR UPDATE OF ia`, id)
+}
+
+func (r *InventoryAdjustmentRepository) get(ctx context.Context, query string, id int64) (*entity.InventoryAdjustment, error) {
+	a, err := scanInventoryAdjustment(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrAdjustmentNotFound
+	}
+	return a, err
+}
+
+// List retrieves adjustments matching the filter, newest first
+func (r *InventoryAdjustmentRepository) List(ctx context.Context, filter repository.InventoryAdjustmentFilter, offset, limit int) ([]entity.InventoryAdjustment, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.WarehouseID != nil {
+		where += fmt.Sprintf(" AND ia.warehouse_id = $%d", argCount)
+		args = append(args, *filter.WarehouseID)
+		argCount++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND ia.status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+	if filter.ReasonCode != nil {
+		where += fmt.Sprintf(" AND ia.reason_code = $%d", argCount)
+		args = append(args, *filter.ReasonCode)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM inventory_adjustments ia`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := inventoryAdjustmentSelect + where +
+		fmt.Sprintf(" ORDER BY ia.created_at DESC, ia.id DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	adjustments := []entity.InventoryAdjustment{}
+	for rows.Next() {
+		a, err := scanInventoryAdjustment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		adjustments = append(adjustments, *a)
+	}
+	return adjustments, total, rows.Err()
+}
+
+// UpdateReview saves the approval decision and the movement posted for it
+func (r *InventoryAdjustmentRepository) UpdateReview(ctx context.Context, a *entity.InventoryAdjustment) error {
+	query := `
+		UPDATE inventory_adjustments
+		SET status = $1, reviewed_by_user_id = $2, reviewed_at = $3, review_note = $4, movement_id = $5
+		WHERE id = $6
+	`
+	result, err := r.db.Conn(ctx).Exec(ctx, query, a.Status, a.ReviewedByUserID, a.ReviewedAt, a.ReviewNote, a.MovementID, a.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrAdjustmentNotFound
+	}
+	return nil
+}
+
+// Shrinkage totals approved write-offs made within [from, to) by warehouse and reason
+func (r *InventoryAdjustmentRepository) Shrinkage(ctx context.Context, from, to time.Time, warehouseID *int64) ([]entity.ShrinkageSummary, error) {
+	query := `
+		SELECT ia.warehouse_id, w.name, ia.reason_code, COUNT(*), SUM(-ia.quantity_delta), SUM(ia.value)
+		FROM inventory_adjustments ia
+		JOIN warehouses w ON w.id = ia.warehouse_id
+		WHERE ia.status = 'approved' AND ia.quantity_delta < 0
+		  AND ia.created_at >= $1 AND ia.created_at < $2
+		  AND ($3::int IS NULL OR ia.warehouse_id = $3)
+		GROUP BY ia.warehouse_id, w.name, ia.reason_code
+		ORDER BY w.name, SUM(ia.value) DESC
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, from, to, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []entity.ShrinkageSummary{}
+	for rows.Next() {
+		var s entity.ShrinkageSummary
+		if err := rows.Scan(&s.WarehouseID, &s.WarehouseName, &s.ReasonCode, &s.AdjustmentCount, &s.Quantity, &s.Value); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+func scanInventoryAdjustment(row pgx.Row) (*entity.InventoryAdjustment, error) {
+	var a entity.InventoryAdjustment
+	err := row.Scan(
+		&a.ID, &a.WarehouseID, &a.WarehouseName, &a.VariantID, &a.VariantName, &a.ReasonCode, &a.QuantityDelta,
+		&a.UnitCost, &a.Value, &a.Notes, &a.Status, &a.RequestedByUserID, &a.ReviewedByUserID,
+		&a.ReviewedAt, &a.ReviewNote, &a.MovementID, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
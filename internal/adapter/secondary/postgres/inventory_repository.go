@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -10,16 +11,19 @@ import (
 
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
 )
 
 // InventoryRepository implements repository.InventoryRepository
 type InventoryRepository struct {
-	db *DB
+	db      *DB
+	costing entity.CostingMethod
 }
 
-// NewInventoryRepository creates a new inventory repository
-func NewInventoryRepository(db *DB) *InventoryRepository {
-	return &InventoryRepository{db: db}
+// NewInventoryRepository creates a new inventory repository that values stock issues under the
+// given costing method
+func NewInventoryRepository(db *DB, costing entity.CostingMethod) *InventoryRepository {
+	return &InventoryRepository{db: db, costing: costing}
 }
 
 // GetLevel retrieves inventory level for a specific warehouse and variant
@@ -30,7 +34,7 @@ func (r *InventoryRepository) GetLevel(ctx context.Context, warehouseID, variant
 		WHERE warehouse_id = $1 AND variant_id = $2
 	`
 	il := &entity.InventoryLevel{}
-	err := r.db.Pool.QueryRow(ctx, query, warehouseID, variantID).Scan(
+	err := r.db.Conn(ctx).QueryRow(ctx, query, warehouseID, variantID).Scan(
 		&il.ID, &il.WarehouseID, &il.VariantID, &il.Quantity, &il.BatchNumber, &il.ExpiryDate,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -47,6 +51,31 @@ func (r *InventoryRepository) GetLevel(ctx context.Context, warehouseID, variant
 	return il, nil
 }
 
+// GetLevelForUpdate retrieves an inventory level and locks the row until the surrounding transaction ends
+func (r *InventoryRepository) GetLevelForUpdate(ctx context.Context, warehouseID, variantID int64) (*entity.InventoryLevel, error) {
+	query := `
+		SELECT id, warehouse_id, variant_id, quantity, batch_number, expiry_date
+		FROM inventory_levels
+		WHERE warehouse_id = $1 AND variant_id = $2
+		FOR UPDATE
+	`
+	il := &entity.InventoryLevel{}
+	err := r.db.Conn(ctx).QueryRow(ctx, query, warehouseID, variantID).Scan(
+		&il.ID, &il.WarehouseID, &il.VariantID, &il.Quantity, &il.BatchNumber, &il.ExpiryDate,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &entity.InventoryLevel{
+			WarehouseID: warehouseID,
+			VariantID:   variantID,
+			Quantity:    decimal.Zero,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return il, nil
+}
+
 // GetLevelsByWarehouse retrieves all inventory levels for a warehouse
 func (r *InventoryRepository) GetLevelsByWarehouse(ctx context.Context, warehouseID int64) ([]entity.InventoryLevel, error) {
 	query := `
@@ -57,7 +86,7 @@ func (r *InventoryRepository) GetLevelsByWarehouse(ctx context.Context, warehous
 		WHERE il.warehouse_id = $1
 		ORDER BY pv.name
 	`
-	rows, err := r.db.Pool.Query(ctx, query, warehouseID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, warehouseID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +116,7 @@ func (r *InventoryRepository) GetLevelsByVariant(ctx context.Context, variantID
 		WHERE il.variant_id = $1
 		ORDER BY w.name
 	`
-	rows, err := r.db.Pool.Query(ctx, query, variantID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, variantID)
 	if err != nil {
 		return nil, err
 	}
@@ -107,35 +136,440 @@ func (r *InventoryRepository) GetLevelsByVariant(ctx context.Context, variantID
 	return levels, rows.Err()
 }
 
-// SetLevel sets the inventory level
-func (r *InventoryRepository) SetLevel(ctx context.Context, level *entity.InventoryLevel) error {
-	query := `
-		INSERT INTO inventory_levels (warehouse_id, variant_id, quantity, batch_number, expiry_date)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET 
-			quantity = $3, batch_number = $4, expiry_date = $5
-		RETURNING id
-	`
-	return r.db.Pool.QueryRow(ctx, query,
-		level.WarehouseID, level.VariantID, level.Quantity, level.BatchNumber, level.ExpiryDate,
-	).Scan(&level.ID)
-}
+// AdjustLevel adds the movement's delta to the inventory level and records the movement
+// with the resulting balance, both or neither. Receipts open a cost layer and issues consume
+// layers oldest first; the movement records its cost and the stock value after it.
+func (r *InventoryRepository) AdjustLevel(ctx context.Context, movement *entity.InventoryMovement) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-// AdjustLevel adjusts inventory level by a delta amount
-func (r *InventoryRepository) AdjustLevel(ctx context.Context, warehouseID, variantID int64, quantityDelta decimal.Decimal) error {
-	query := `
+	// stock_value is returned as it was before the movement; it is updated once the cost is known
+	var valueBefore decimal.Decimal
+	err = tx.QueryRow(ctx, `
 		INSERT INTO inventory_levels (warehouse_id, variant_id, quantity)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET 
 			quantity = inventory_levels.quantity + $3
+		RETURNING quantity, stock_value
+	`, movement.WarehouseID, movement.VariantID, movement.QuantityDelta).Scan(&movement.BalanceAfter, &valueBefore)
+	if err != nil {
+		return err
+	}
+
+	if movement.LotID != nil {
+		// The lot's CHECK (quantity >= 0) stops a lot from being overdrawn
+		result, err := tx.Exec(ctx, `
+			UPDATE inventory_lots SET quantity = quantity + $1
+			WHERE id = $2 AND warehouse_id = $3 AND variant_id = $4
+		`, movement.QuantityDelta, *movement.LotID, movement.WarehouseID, movement.VariantID)
+		if err != nil {
+			return fmt.Errorf("failed to update inventory lot: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return domainErrors.ErrLotNotFound
+		}
+	}
+
+	var draws []entity.CostLayerDraw
+	if movement.QuantityDelta.IsPositive() {
+		if movement.UnitCost == nil {
+			unitCost, err := r.averageCost(ctx, tx, movement, valueBefore)
+			if err != nil {
+				return err
+			}
+			movement.UnitCost = &unitCost
+		}
+		totalCost := movement.QuantityDelta.Mul(*movement.UnitCost).Round(4)
+		movement.TotalCost = &totalCost
+	} else if movement.QuantityDelta.IsNegative() {
+		draws, err = r.costIssue(ctx, tx, movement, valueBefore)
+		if err != nil {
+			return err
+		}
+	} else {
+		zero := decimal.Zero
+		movement.TotalCost = &zero
+	}
+
+	valueAfter := valueBefore.Add(*movement.TotalCost)
+	if !movement.BalanceAfter.IsPositive() || valueAfter.IsNegative() {
+		valueAfter = decimal.Zero
+	}
+	movement.ValueAfter = &valueAfter
+	if _, err := tx.Exec(ctx, `
+		UPDATE inventory_levels SET stock_value = $1 WHERE warehouse_id = $2 AND variant_id = $3
+	`, valueAfter, movement.WarehouseID, movement.VariantID); err != nil {
+		return fmt.Errorf("failed to update stock value: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO inventory_movements (warehouse_id, variant_id, lot_id, source_type, reference_id, user_id, quantity_delta, balance_after, unit_cost, total_cost, value_after, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`, movement.WarehouseID, movement.VariantID, movement.LotID, movement.SourceType, movement.ReferenceID, movement.UserID,
+		movement.QuantityDelta, movement.BalanceAfter, movement.UnitCost, movement.TotalCost, movement.ValueAfter, movement.Notes,
+	).Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record inventory movement: %w", err)
+	}
+
+	if movement.QuantityDelta.IsPositive() {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO inventory_cost_layers (warehouse_id, variant_id, movement_id, source_type, unit_cost, quantity, remaining_quantity, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+		`, movement.WarehouseID, movement.VariantID, movement.ID, movement.SourceType, movement.UnitCost, movement.QuantityDelta, movement.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to open cost layer: %w", err)
+		}
+	}
+	for _, d := range draws {
+		if _, err := tx.Exec(ctx, `
+			UPDATE inventory_cost_layers SET remaining_quantity = remaining_quantity - $1 WHERE id = $2
+		`, d.Quantity, d.Layer.ID); err != nil {
+			return fmt.Errorf("failed to consume cost layer: %w", err)
+		}
+	}
+	if !movement.BalanceAfter.IsPositive() {
+		// Nothing is left to value, so no layer should outlive the stock it stood for
+		if _, err := tx.Exec(ctx, `
+			UPDATE inventory_cost_layers SET remaining_quantity = 0
+			WHERE warehouse_id = $1 AND variant_id = $2 AND remaining_quantity > 0
+		`, movement.WarehouseID, movement.VariantID); err != nil {
+			return fmt.Errorf("failed to close cost layers: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// averageCost is the average unit cost of the stock held before the movement, or the variant's
+// cost price when there is none to average
+func (r *InventoryRepository) averageCost(ctx context.Context, tx pgx.Tx, movement *entity.InventoryMovement, valueBefore decimal.Decimal) (decimal.Decimal, error) {
+	quantityBefore := movement.BalanceAfter.Sub(movement.QuantityDelta)
+	if quantityBefore.IsPositive() && valueBefore.IsPositive() {
+		return valueBefore.Div(quantityBefore).Round(4), nil
+	}
+	var costPrice decimal.Decimal
+	err := tx.QueryRow(ctx, `SELECT cost_price FROM product_variants WHERE id = $1`, movement.VariantID).Scan(&costPrice)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to load variant cost price: %w", err)
+	}
+	return costPrice, nil
+}
+
+// costIssue consumes cost layers for an issue, oldest first, and sets its cost under the costing
+// method. Stock the layers do not cover is costed at the average cost. Issuing the last of the
+// stock takes out whatever value is left.
+func (r *InventoryRepository) costIssue(ctx context.Context, tx pgx.Tx, movement *entity.InventoryMovement, valueBefore decimal.Decimal) ([]entity.CostLayerDraw, error) {
+	quantity := movement.QuantityDelta.Neg()
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, unit_cost, remaining_quantity
+		FROM inventory_cost_layers
+		WHERE warehouse_id = $1 AND variant_id = $2 AND remaining_quantity > 0
+		ORDER BY received_at, id
+		FOR UPDATE
+	`, movement.WarehouseID, movement.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	var layers []entity.CostLayer
+	for rows.Next() {
+		var l entity.CostLayer
+		if err := rows.Scan(&l.ID, &l.UnitCost, &l.RemainingQuantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	draws, fifoCost, uncovered := entity.DrawCostLayers(layers, quantity)
+	average, err := r.averageCost(ctx, tx, movement, valueBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := quantity.Mul(average)
+	if r.costing == entity.CostingFIFO {
+		cost = fifoCost.Add(uncovered.Mul(average))
+	}
+	if !movement.BalanceAfter.IsPositive() && valueBefore.IsPositive() {
+		cost = valueBefore
+	}
+	cost = cost.Round(4)
+
+	unitCost := cost.Div(quantity).Round(4)
+	totalCost := cost.Neg()
+	movement.UnitCost = &unitCost
+	movement.TotalCost = &totalCost
+	return draws, nil
+}
+
+// ListMovements retrieves the ledger entries of a variant at a warehouse within [from, to), oldest first
+func (r *InventoryRepository) ListMovements(ctx context.Context, warehouseID, variantID int64, from, to time.Time) ([]entity.InventoryMovement, error) {
+	query := `
+		SELECT id, warehouse_id, variant_id, lot_id, source_type, reference_id, user_id, quantity_delta, balance_after,
+		       unit_cost, total_cost, value_after, notes, created_at
+		FROM inventory_movements
+		WHERE warehouse_id = $1 AND variant_id = $2 AND created_at >= $3 AND created_at < $4
+		ORDER BY created_at, id
+	`
+	return r.listMovements(ctx, query, warehouseID, variantID, from, to)
+}
+
+// ListMovementsByReference retrieves the ledger entries recorded for one sale, transfer, run..., oldest first
+func (r *InventoryRepository) ListMovementsByReference(ctx context.Context, source entity.MovementSourceType, referenceID int64) ([]entity.InventoryMovement, error) {
+	query := `
+		SELECT id, warehouse_id, variant_id, lot_id, source_type, reference_id, user_id, quantity_delta, balance_after,
+		       unit_cost, total_cost, value_after, notes, created_at
+		FROM inventory_movements
+		WHERE source_type = $1 AND reference_id = $2
+		ORDER BY id
+	`
+	return r.listMovements(ctx, query, source, referenceID)
+}
+
+func (r *InventoryRepository) listMovements(ctx context.Context, query string, args ...any) ([]entity.InventoryMovement, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []entity.InventoryMovement{}
+	for rows.Next() {
+		var m entity.InventoryMovement
+		if err := rows.Scan(
+			&m.ID, &m.WarehouseID, &m.VariantID, &m.LotID, &m.SourceType, &m.ReferenceID, &m.UserID,
+			&m.QuantityDelta, &m.BalanceAfter, &m.UnitCost, &m.TotalCost, &m.ValueAfter, &m.Notes, &m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+// UpsertLot creates the lot of a batch at a warehouse with zero quantity, or loads the existing
+// one; stock is added to it by AdjustLevel. An existing lot keeps its origin details.
+func (r *InventoryRepository) UpsertLot(ctx context.Context, lot *entity.InventoryLot) error {
+	query := `
+		INSERT INTO inventory_lots (warehouse_id, variant_id, batch_number, expiry_date, supplier_id, source_type, reference_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (warehouse_id, variant_id, batch_number) DO UPDATE SET
+			expiry_date = COALESCE(inventory_lots.expiry_date, EXCLUDED.expiry_date)
+		RETURNING id, expiry_date, quantity, supplier_id, source_type, reference_id, received_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		lot.WarehouseID, lot.VariantID, lot.BatchNumber, lot.ExpiryDate, lot.SupplierID, lot.SourceType, lot.ReferenceID,
+	).Scan(&lot.ID, &lot.ExpiryDate, &lot.Quantity, &lot.SupplierID, &lot.SourceType, &lot.ReferenceID, &lot.ReceivedAt)
+}
+
+const inventoryLotSelect = `
+	SELECT l.id, l.warehouse_id, w.name, l.variant_id, pv.name, l.batch_number, l.expiry_date, l.quantity,
+	       l.supplier_id, s.name, l.source_type, l.reference_id, l.received_at
+	FROM inventory_lots l
+	JOIN warehouses w ON w.id = l.warehouse_id
+	JOIN product_variants pv ON pv.id = l.variant_id
+	LEFT JOIN suppliers s ON s.id = l.supplier_id`
+
+// GetLotByID retrieves a lot by ID
+func (r *InventoryRepository) GetLotByID(ctx context.Context, id int64) (*entity.InventoryLot, error) {
+	lot, err := scanInventoryLot(r.db.Conn(ctx).QueryRow(ctx, inventoryLotSelect+` WHERE l.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrLotNotFound
+	}
+	return lot, err
+}
+
+// ListLotsForUpdate retrieves the lots of a variant at a warehouse that still hold stock and locks
+// them until the surrounding transaction ends
+func (r *InventoryRepository) ListLotsForUpdate(ctx context.Context, warehouseID, variantID int64) ([]entity.InventoryLot, error) {
+	query := inventoryLotSelect + `
+		WHERE l.warehouse_id = $1 AND l.variant_id = $2 AND l.quantity > 0
+		ORDER BY l.expiry_date NULLS LAST, l.received_at, l.id
+		FOR UPDATE OF l
+	`
+	return r.listLots(ctx, query, warehouseID, variantID)
+}
+
+// ListLots retrieves lots matching the filter, earliest expiry first
+func (r *InventoryRepository) ListLots(ctx context.Context, filter repository.InventoryLotFilter) ([]entity.InventoryLot, error) {
+	query := inventoryLotSelect + " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.WarehouseID != nil {
+		query += fmt.Sprintf(" AND l.warehouse_id = $%d", argCount)
+		args = append(args, *filter.WarehouseID)
+		argCount++
+	}
+	if filter.VariantID != nil {
+		query += fmt.Sprintf(" AND l.variant_id = $%d", argCount)
+		args = append(args, *filter.VariantID)
+		argCount++
+	}
+	if filter.BatchNumber != nil {
+		query += fmt.Sprintf(" AND l.batch_number = $%d", argCount)
+		args = append(args, *filter.BatchNumber)
+		argCount++
+	}
+	if filter.ExpiringBy != nil {
+		query += fmt.Sprintf(" AND l.expiry_date <= $%d::date", argCount)
+		args = append(args, *filter.ExpiringBy)
+		argCount++
+	}
+	if !filter.IncludeEmpty {
+		query += " AND l.quantity > 0"
+	}
+	query += " ORDER BY l.expiry_date NULLS LAST, w.name, pv.name, l.id"
+
+	return r.listLots(ctx, query, args...)
+}
+
+func (r *InventoryRepository) listLots(ctx context.Context, query string, args ...any) ([]entity.InventoryLot, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := []entity.InventoryLot{}
+	for rows.Next() {
+		lot, err := scanInventoryLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, *lot)
+	}
+	return lots, rows.Err()
+}
+
+// ListLotMovements retrieves every ledger entry of a batch of a variant across warehouses,
+// oldest first, with the customer of each sale
+func (r *InventoryRepository) ListLotMovements(ctx context.Context, batchNumber string, variantID int64) ([]entity.LotTraceMovement, error) {
+	query := `
+		SELECT m.id, m.warehouse_id, m.variant_id, m.lot_id, m.source_type, m.reference_id, m.user_id,
+		       m.quantity_delta, m.balance_after, m.notes, m.created_at,
+		       w.name, l.batch_number, s.customer_id, COALESCE(c.name, s.customer_name)
+		FROM inventory_movements m
+		JOIN inventory_lots l ON l.id = m.lot_id
+		JOIN warehouses w ON w.id = m.warehouse_id
+		LEFT JOIN sales s ON m.source_type IN ('sale', 'sale_cancellation') AND s.id = m.reference_id
+		LEFT JOIN customers c ON c.id = s.customer_id
+		WHERE l.batch_number = $1 AND l.variant_id = $2
+		ORDER BY m.created_at, m.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, batchNumber, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []entity.LotTraceMovement{}
+	for rows.Next() {
+		var m entity.LotTraceMovement
+		if err := rows.Scan(
+			&m.ID, &m.WarehouseID, &m.VariantID, &m.LotID, &m.SourceType, &m.ReferenceID, &m.UserID,
+			&m.QuantityDelta, &m.BalanceAfter, &m.UnitCost, &m.TotalCost, &m.ValueAfter, &m.Notes, &m.CreatedAt,
+			&m.WarehouseName, &m.BatchNumber, &m.CustomerID, &m.CustomerName,
+		); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+func scanInventoryLot(row pgx.Row) (*entity.InventoryLot, error) {
+	var l entity.InventoryLot
+	err := row.Scan(
+		&l.ID, &l.WarehouseID, &l.WarehouseName, &l.VariantID, &l.VariantName, &l.BatchNumber, &l.ExpiryDate, &l.Quantity,
+		&l.SupplierID, &l.SupplierName, &l.SourceType, &l.ReferenceID, &l.ReceivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// GetLedgerBalance sums the movements of a variant at a warehouse recorded before a point in time
+func (r *InventoryRepository) GetLedgerBalance(ctx context.Context, warehouseID, variantID int64, before time.Time) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(SUM(quantity_delta), 0)
+		FROM inventory_movements
+		WHERE warehouse_id = $1 AND variant_id = $2 AND created_at < $3
+	`, warehouseID, variantID, before).Scan(&balance)
+	return balance, err
+}
+
+// ListDrift returns every stock level that differs from the sum of its ledger movements,
+// including ledger balances with no level row and levels with no movements
+func (r *InventoryRepository) ListDrift(ctx context.Context) ([]entity.InventoryDrift, error) {
+	query := `
+		WITH ledger AS (
+			SELECT warehouse_id, variant_id, SUM(quantity_delta) AS quantity
+			FROM inventory_movements
+			GROUP BY warehouse_id, variant_id
+		)
+		SELECT COALESCE(il.warehouse_id, l.warehouse_id), COALESCE(il.variant_id, l.variant_id),
+		       COALESCE(il.quantity, 0), COALESCE(l.quantity, 0)
+		FROM inventory_levels il
+		FULL OUTER JOIN ledger l ON l.warehouse_id = il.warehouse_id AND l.variant_id = il.variant_id
+		WHERE COALESCE(il.quantity, 0) <> COALESCE(l.quantity, 0)
+		ORDER BY 1, 2
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drift := []entity.InventoryDrift{}
+	for rows.Next() {
+		var d entity.InventoryDrift
+		if err := rows.Scan(&d.WarehouseID, &d.VariantID, &d.LevelQuantity, &d.LedgerQuantity); err != nil {
+			return nil, err
+		}
+		drift = append(drift, d)
+	}
+	return drift, rows.Err()
+}
+
+// SyncLevelToLedger resets a stock level to the sum of its ledger movements. The stock value is
+// reset to the value after the latest costed movement, or to the quantity at cost price when none is.
+func (r *InventoryRepository) SyncLevelToLedger(ctx context.Context, warehouseID, variantID int64) error {
+	query := `
+		INSERT INTO inventory_levels (warehouse_id, variant_id, quantity, stock_value)
+		SELECT $1, $2, l.quantity,
+		       CASE WHEN l.quantity <= 0 THEN 0
+		            ELSE COALESCE((
+		                SELECT value_after FROM inventory_movements
+		                WHERE warehouse_id = $1 AND variant_id = $2 AND value_after IS NOT NULL
+		                ORDER BY created_at DESC, id DESC LIMIT 1
+		            ), l.quantity * (SELECT cost_price FROM product_variants WHERE id = $2)) END
+		FROM (
+			SELECT COALESCE(SUM(quantity_delta), 0) AS quantity
+			FROM inventory_movements
+			WHERE warehouse_id = $1 AND variant_id = $2
+		) l
+		ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET quantity = EXCLUDED.quantity, stock_value = EXCLUDED.stock_value
 	`
-	_, err := r.db.Pool.Exec(ctx, query, warehouseID, variantID, quantityDelta)
+	_, err := r.db.Conn(ctx).Exec(ctx, query, warehouseID, variantID)
 	return err
 }
 
 // CreateTransfer creates an inventory transfer
 func (r *InventoryRepository) CreateTransfer(ctx context.Context, transfer *entity.InventoryTransfer) error {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -143,13 +577,13 @@ func (r *InventoryRepository) CreateTransfer(ctx context.Context, transfer *enti
 
 	// Insert transfer
 	transferQuery := `
-		INSERT INTO inventory_transfers (source_warehouse_id, destination_warehouse_id, authorized_by_user_id, transferred_at, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO inventory_transfers (source_warehouse_id, destination_warehouse_id, authorized_by_user_id, transferred_at, status, notes, requested_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 	err = tx.QueryRow(ctx, transferQuery,
 		transfer.SourceWarehouseID, transfer.DestinationWarehouseID, transfer.AuthorizedByUserID,
-		transfer.TransferredAt, transfer.Status,
+		transfer.TransferredAt, transfer.Status, transfer.Notes, transfer.RequestedByUserID,
 	).Scan(&transfer.ID)
 	if err != nil {
 		return err
@@ -174,17 +608,24 @@ func (r *InventoryRepository) CreateTransfer(ctx context.Context, transfer *enti
 	return tx.Commit(ctx)
 }
 
+const transferSelect = `
+	SELECT id, source_warehouse_id, destination_warehouse_id, authorized_by_user_id, transferred_at, status, notes,
+	       requested_by_user_id, dispatched_by_user_id, dispatched_at, received_by_user_id, received_at,
+	       cancelled_by_user_id, cancelled_at
+	FROM inventory_transfers`
+
 // GetTransferByID retrieves a transfer by ID
 func (r *InventoryRepository) GetTransferByID(ctx context.Context, id int64) (*entity.InventoryTransfer, error) {
-	query := `
-		SELECT id, source_warehouse_id, destination_warehouse_id, authorized_by_user_id, transferred_at, status
-		FROM inventory_transfers WHERE id = $1
-	`
-	t := &entity.InventoryTransfer{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&t.ID, &t.SourceWarehouseID, &t.DestinationWarehouseID,
-		&t.AuthorizedByUserID, &t.TransferredAt, &t.Status,
-	)
+	return r.getTransfer(ctx, transferSelect+` WHERE id = $1`, id)
+}
+
+// GetTransferByIDForUpdate retrieves a transfer by ID and locks it until the transaction ends
+func (r *InventoryRepository) GetTransferByIDForUpdate(ctx context.Context, id int64) (*entity.InventoryTransfer, error) {
+	return r.getTransfer(ctx, transferSelect+` WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *InventoryRepository) getTransfer(ctx context.Context, query string, id int64) (*entity.InventoryTransfer, error) {
+	t, err := scanTransfer(r.db.Conn(ctx).QueryRow(ctx, query, id))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrTransferNotFound
 	}
@@ -193,107 +634,471 @@ func (r *InventoryRepository) GetTransferByID(ctx context.Context, id int64) (*e
 	}
 
 	// Get items
-	itemQuery := `SELECT id, transfer_id, variant_id, quantity FROM inventory_transfer_items WHERE transfer_id = $1`
-	rows, err := r.db.Pool.Query(ctx, itemQuery, id)
+	itemQuery := `
+		SELECT i.id, i.transfer_id, i.variant_id, pv.name, pv.sku, i.quantity,
+		       i.dispatched_quantity, i.received_quantity, i.damaged_quantity, i.notes
+		FROM inventory_transfer_items i
+		JOIN product_variants pv ON pv.id = i.variant_id
+		WHERE i.transfer_id = $1
+		ORDER BY i.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, itemQuery, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var item entity.InventoryTransferItem
-		if err := rows.Scan(&item.ID, &item.TransferID, &item.VariantID, &item.Quantity); err != nil {
+		item := entity.InventoryTransferItem{Variant: &entity.ProductVariant{}}
+		if err := rows.Scan(
+			&item.ID, &item.TransferID, &item.VariantID, &item.Variant.Name, &item.Variant.SKU, &item.Quantity,
+			&item.DispatchedQuantity, &item.ReceivedQuantity, &item.DamagedQuantity, &item.Notes,
+		); err != nil {
 			return nil, err
 		}
+		item.Variant.ID = item.VariantID
 		t.Items = append(t.Items, item)
 	}
 
 	return t, rows.Err()
 }
 
-// ListTransfers retrieves all transfers with pagination
-func (r *InventoryRepository) ListTransfers(ctx context.Context, offset, limit int) ([]entity.InventoryTransfer, int64, error) {
+// ListTransfers retrieves transfers matching the filter with pagination, newest first
+func (r *InventoryRepository) ListTransfers(ctx context.Context, filter repository.TransferFilter, offset, limit int) ([]entity.InventoryTransfer, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+	if filter.WarehouseID != nil {
+		where += fmt.Sprintf(" AND (source_warehouse_id = $%d OR destination_warehouse_id = $%d)", argCount, argCount)
+		args = append(args, *filter.WarehouseID)
+		argCount++
+	}
+	if filter.SourceWarehouseID != nil {
+		where += fmt.Sprintf(" AND source_warehouse_id = $%d", argCount)
+		args = append(args, *filter.SourceWarehouseID)
+		argCount++
+	}
+	if filter.DestinationWarehouseID != nil {
+		where += fmt.Sprintf(" AND destination_warehouse_id = $%d", argCount)
+		args = append(args, *filter.DestinationWarehouseID)
+		argCount++
+	}
+
 	var total int64
-	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM inventory_transfers`).Scan(&total); err != nil {
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM inventory_transfers`+where, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
-	query := `
-		SELECT id, source_warehouse_id, destination_warehouse_id, authorized_by_user_id, transferred_at, status
-		FROM inventory_transfers
-		ORDER BY transferred_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	query := transferSelect + where + fmt.Sprintf(" ORDER BY transferred_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var transfers []entity.InventoryTransfer
+	transfers := []entity.InventoryTransfer{}
 	for rows.Next() {
-		var t entity.InventoryTransfer
-		if err := rows.Scan(
-			&t.ID, &t.SourceWarehouseID, &t.DestinationWarehouseID,
-			&t.AuthorizedByUserID, &t.TransferredAt, &t.Status,
-		); err != nil {
+		t, err := scanTransfer(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		transfers = append(transfers, t)
+		transfers = append(transfers, *t)
 	}
 	return transfers, total, rows.Err()
 }
 
-// UpdateTransferStatus updates the status of a transfer
-func (r *InventoryRepository) UpdateTransferStatus(ctx context.Context, id int64, status entity.TransferStatus) error {
-	query := `UPDATE inventory_transfers SET status = $1 WHERE id = $2`
-	result, err := r.db.Pool.Exec(ctx, query, status, id)
+// UpdateTransfer saves the status and audit fields of a transfer and the quantities of its items
+func (r *InventoryRepository) UpdateTransfer(ctx context.Context, t *entity.InventoryTransfer) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE inventory_transfers
+		SET status = $1, dispatched_by_user_id = $2, dispatched_at = $3, received_by_user_id = $4,
+		    received_at = $5, cancelled_by_user_id = $6, cancelled_at = $7
+		WHERE id = $8
+	`, t.Status, t.DispatchedByUserID, t.DispatchedAt, t.ReceivedByUserID, t.ReceivedAt,
+		t.CancelledByUserID, t.CancelledAt, t.ID)
 	if err != nil {
 		return err
 	}
 	if result.RowsAffected() == 0 {
 		return domainErrors.ErrTransferNotFound
 	}
-	return nil
+
+	for _, item := range t.Items {
+		_, err := tx.Exec(ctx, `
+			UPDATE inventory_transfer_items
+			SET dispatched_quantity = $1, received_quantity = $2, damaged_quantity = $3, notes = $4
+			WHERE id = $5 AND transfer_id = $6
+		`, item.DispatchedQuantity, item.ReceivedQuantity, item.DamagedQuantity, item.Notes, item.ID, t.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
-// GetExpiringStock retrieves inventory expiring within specified days
-func (r *InventoryRepository) GetExpiringStock(ctx context.Context, daysUntilExpiry int) ([]entity.InventoryLevel, error) {
-	expiryDate := time.Now().AddDate(0, 0, daysUntilExpiry)
+// ListInTransit sums the stock dispatched and not yet received per destination and variant
+func (r *InventoryRepository) ListInTransit(ctx context.Context, destinationWarehouseID *int64) ([]entity.InTransitStock, error) {
 	query := `
-		SELECT id, warehouse_id, variant_id, quantity, batch_number, expiry_date
-		FROM inventory_levels
-		WHERE expiry_date IS NOT NULL AND expiry_date <= $1 AND quantity > 0
-		ORDER BY expiry_date
+		SELECT t.destination_warehouse_id, i.variant_id, pv.name, SUM(i.dispatched_quantity), COUNT(DISTINCT t.id)
+		FROM inventory_transfers t
+		JOIN inventory_transfer_items i ON i.transfer_id = t.id
+		JOIN product_variants pv ON pv.id = i.variant_id
+		WHERE t.status = 'in_transit' AND i.dispatched_quantity > 0
+		  AND ($1::int IS NULL OR t.destination_warehouse_id = $1)
+		GROUP BY t.destination_warehouse_id, i.variant_id, pv.name
+		ORDER BY t.destination_warehouse_id, pv.name
 	`
-	rows, err := r.db.Pool.Query(ctx, query, expiryDate)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, destinationWarehouseID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var levels []entity.InventoryLevel
+	stock := []entity.InTransitStock{}
 	for rows.Next() {
-		var il entity.InventoryLevel
+		var s entity.InTransitStock
+		if err := rows.Scan(&s.DestinationWarehouseID, &s.VariantID, &s.VariantName, &s.Quantity, &s.Transfers); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, rows.Err()
+}
+
+func scanTransfer(row pgx.Row) (*entity.InventoryTransfer, error) {
+	var t entity.InventoryTransfer
+	err := row.Scan(
+		&t.ID, &t.SourceWarehouseID, &t.DestinationWarehouseID,
+		&t.AuthorizedByUserID, &t.TransferredAt, &t.Status, &t.Notes,
+		&t.RequestedByUserID, &t.DispatchedByUserID, &t.DispatchedAt, &t.ReceivedByUserID, &t.ReceivedAt,
+		&t.CancelledByUserID, &t.CancelledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateReservation records a reservation holding stock for an order or subscription delivery
+func (r *InventoryRepository) CreateReservation(ctx context.Context, res *entity.StockReservation) error {
+	if res.Status == "" {
+		res.Status = entity.ReservationStatusActive
+	}
+	query := `
+		INSERT INTO stock_reservations (warehouse_id, variant_id, quantity, source_type, reference_id, reserved_for, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		res.WarehouseID, res.VariantID, res.Quantity, res.SourceType, res.ReferenceID, res.ReservedFor, res.Status, res.ExpiresAt,
+	).Scan(&res.ID, &res.CreatedAt)
+}
+
+// SumHeldReservations returns the stock of a variant at a warehouse held by active, unexpired reservations
+func (r *InventoryRepository) SumHeldReservations(ctx context.Context, warehouseID, variantID int64) (decimal.Decimal, error) {
+	var reserved decimal.Decimal
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_reservations
+		WHERE warehouse_id = $1 AND variant_id = $2 AND status = 'active' AND expires_at > NOW()
+	`, warehouseID, variantID).Scan(&reserved)
+	return reserved, err
+}
+
+const reservationSelect = `
+	SELECT r.id, r.warehouse_id, r.variant_id, pv.name, pv.sku, r.quantity, r.source_type, r.reference_id,
+	       r.reserved_for, r.status, r.expires_at, r.created_at, r.closed_at
+	FROM stock_reservations r
+	JOIN product_variants pv ON pv.id = r.variant_id`
+
+// ListReservationsByReference retrieves every reservation made for an order or subscription, whatever its status
+func (r *InventoryRepository) ListReservationsByReference(ctx context.Context, source entity.ReservationSourceType, referenceID int64) ([]entity.StockReservation, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, reservationSelect+`
+		WHERE r.source_type = $1 AND r.reference_id = $2
+		ORDER BY r.id
+	`, source, referenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []entity.StockReservation{}
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, *res)
+	}
+	return reservations, rows.Err()
+}
+
+// ListReservations retrieves reservations matching the filter, newest first
+func (r *InventoryRepository) ListReservations(ctx context.Context, filter repository.ReservationFilter, offset, limit int) ([]entity.StockReservation, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.WarehouseID != nil {
+		where += fmt.Sprintf(" AND r.warehouse_id = $%d", argCount)
+		args = append(args, *filter.WarehouseID)
+		argCount++
+	}
+	if filter.VariantID != nil {
+		where += fmt.Sprintf(" AND r.variant_id = $%d", argCount)
+		args = append(args, *filter.VariantID)
+		argCount++
+	}
+	if filter.SourceType != nil {
+		where += fmt.Sprintf(" AND r.source_type = $%d", argCount)
+		args = append(args, *filter.SourceType)
+		argCount++
+	}
+	if filter.ReferenceID != nil {
+		where += fmt.Sprintf(" AND r.reference_id = $%d", argCount)
+		args = append(args, *filter.ReferenceID)
+		argCount++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND r.status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+	if filter.ReservedFor != nil {
+		where += fmt.Sprintf(" AND r.reserved_for = $%d::date", argCount)
+		args = append(args, *filter.ReservedFor)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM stock_reservations r`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := reservationSelect + where + fmt.Sprintf(" ORDER BY r.created_at DESC, r.id DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reservations := []entity.StockReservation{}
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reservations = append(reservations, *res)
+	}
+	return reservations, total, rows.Err()
+}
+
+// CloseReservations moves the active reservations in scope to the given status
+func (r *InventoryRepository) CloseReservations(ctx context.Context, scope repository.ReservationScope, status entity.ReservationStatus) (int64, error) {
+	query := `
+		UPDATE stock_reservations SET status = $1, closed_at = NOW()
+		WHERE source_type = $2 AND reference_id = $3 AND status = 'active'
+	`
+	args := []any{status, scope.SourceType, scope.ReferenceID}
+	if scope.From != nil {
+		args = append(args, *scope.From)
+		query += fmt.Sprintf(" AND reserved_for >= $%d::date", len(args))
+	}
+	if scope.To != nil {
+		args = append(args, *scope.To)
+		query += fmt.Sprintf(" AND reserved_for <= $%d::date", len(args))
+	}
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// ExpireReservations marks active reservations past their expiry time as expired
+func (r *InventoryRepository) ExpireReservations(ctx context.Context, asOf time.Time) (int64, error) {
+	result, err := r.db.Conn(ctx).Exec(ctx, `
+		UPDATE stock_reservations SET status = 'expired', closed_at = NOW()
+		WHERE status = 'active' AND expires_at <= $1
+	`, asOf)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// GetAvailability returns the stock on hand per warehouse and variant with the part of it in
+// expired lots and the part held by active, unexpired reservations. Lots are expired once past
+// their expiry date on the application's calendar day, as InventoryLot.IsExpired counts them.
+func (r *InventoryRepository) GetAvailability(ctx context.Context, filter repository.AvailabilityFilter) ([]entity.StockAvailability, error) {
+	query := `
+		SELECT il.warehouse_id, il.variant_id, pv.name, pv.sku, il.quantity,
+		       COALESCE(expired.quantity, 0), COALESCE(reserved.quantity, 0)
+		FROM inventory_levels il
+		JOIN product_variants pv ON pv.id = il.variant_id
+		JOIN warehouses w ON w.id = il.warehouse_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(l.quantity) AS quantity FROM inventory_lots l
+			WHERE l.warehouse_id = il.warehouse_id AND l.variant_id = il.variant_id
+			  AND l.quantity > 0 AND l.expiry_date < $1::date
+		) expired ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT SUM(sr.quantity) AS quantity FROM stock_reservations sr
+			WHERE sr.warehouse_id = il.warehouse_id AND sr.variant_id = il.variant_id
+			  AND sr.status = 'active' AND sr.expires_at > NOW()
+		) reserved ON TRUE
+		WHERE w.is_active = TRUE`
+	y, m, d := time.Now().Date()
+	args := []any{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+	if filter.WarehouseID != nil {
+		args = append(args, *filter.WarehouseID)
+		query += fmt.Sprintf(" AND il.warehouse_id = $%d", len(args))
+	}
+	if len(filter.VariantIDs) > 0 {
+		args = append(args, filter.VariantIDs)
+		query += fmt.Sprintf(" AND il.variant_id = ANY($%d)", len(args))
+	}
+	query += " ORDER BY il.warehouse_id, pv.name"
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	availability := []entity.StockAvailability{}
+	for rows.Next() {
+		var a entity.StockAvailability
+		if err := rows.Scan(&a.WarehouseID, &a.VariantID, &a.VariantName, &a.SKU, &a.OnHand, &a.Expired, &a.Reserved); err != nil {
+			return nil, err
+		}
+		availability = append(availability, a)
+	}
+	return availability, rows.Err()
+}
+
+func scanReservation(row pgx.Row) (*entity.StockReservation, error) {
+	var res entity.StockReservation
+	err := row.Scan(
+		&res.ID, &res.WarehouseID, &res.VariantID, &res.VariantName, &res.SKU, &res.Quantity, &res.SourceType, &res.ReferenceID,
+		&res.ReservedFor, &res.Status, &res.ExpiresAt, &res.CreatedAt, &res.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListCostLayers retrieves cost layers oldest first, by default only those with stock left
+func (r *InventoryRepository) ListCostLayers(ctx context.Context, filter repository.CostLayerFilter) ([]entity.CostLayer, error) {
+	query := `
+		SELECT cl.id, cl.warehouse_id, cl.variant_id, pv.name, cl.movement_id, cl.source_type,
+		       cl.unit_cost, cl.quantity, cl.remaining_quantity, cl.received_at
+		FROM inventory_cost_layers cl
+		JOIN product_variants pv ON pv.id = cl.variant_id
+		WHERE 1=1`
+	args := []any{}
+	if filter.WarehouseID != nil {
+		args = append(args, *filter.WarehouseID)
+		query += fmt.Sprintf(" AND cl.warehouse_id = $%d", len(args))
+	}
+	if filter.VariantID != nil {
+		args = append(args, *filter.VariantID)
+		query += fmt.Sprintf(" AND cl.variant_id = $%d", len(args))
+	}
+	if !filter.IncludeConsumed {
+		query += " AND cl.remaining_quantity > 0"
+	}
+	query += " ORDER BY cl.warehouse_id, cl.variant_id, cl.received_at, cl.id"
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	layers := []entity.CostLayer{}
+	for rows.Next() {
+		var l entity.CostLayer
 		if err := rows.Scan(
-			&il.ID, &il.WarehouseID, &il.VariantID, &il.Quantity, &il.BatchNumber, &il.ExpiryDate,
+			&l.ID, &l.WarehouseID, &l.VariantID, &l.VariantName, &l.MovementID, &l.SourceType,
+			&l.UnitCost, &l.Quantity, &l.RemainingQuantity, &l.ReceivedAt,
 		); err != nil {
 			return nil, err
 		}
-		levels = append(levels, il)
+		layers = append(layers, l)
 	}
-	return levels, rows.Err()
+	return layers, rows.Err()
 }
 
-// GetLowStock retrieves inventory below threshold
+// GetValuation values stock from the last ledger entry of each warehouse and variant recorded
+// before a point in time. Entries recorded before costing are valued at the variant's cost price.
+func (r *InventoryRepository) GetValuation(ctx context.Context, before time.Time, warehouseID *int64) ([]entity.InventoryValuationLine, error) {
+	query := `
+		SELECT m.warehouse_id, w.name, m.variant_id, pv.name, pv.sku, m.balance_after,
+		       COALESCE(m.value_after, m.balance_after * pv.cost_price)
+		FROM (
+			SELECT DISTINCT ON (warehouse_id, variant_id) warehouse_id, variant_id, balance_after, value_after
+			FROM inventory_movements
+			WHERE created_at < $1 AND ($2::int IS NULL OR warehouse_id = $2)
+			ORDER BY warehouse_id, variant_id, created_at DESC, id DESC
+		) m
+		JOIN warehouses w ON w.id = m.warehouse_id
+		JOIN product_variants pv ON pv.id = m.variant_id
+		WHERE m.balance_after > 0
+		ORDER BY w.name, pv.name
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, before, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []entity.InventoryValuationLine{}
+	for rows.Next() {
+		var l entity.InventoryValuationLine
+		if err := rows.Scan(&l.WarehouseID, &l.WarehouseName, &l.VariantID, &l.VariantName, &l.SKU, &l.Quantity, &l.Value); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// GetExpiringStock retrieves lots with stock left that expire within the specified days
+func (r *InventoryRepository) GetExpiringStock(ctx context.Context, daysUntilExpiry int) ([]entity.InventoryLot, error) {
+	expiryDate := time.Now().AddDate(0, 0, daysUntilExpiry)
+	return r.ListLots(ctx, repository.InventoryLotFilter{ExpiringBy: &expiryDate})
+}
+
+// GetLowStock retrieves inventory at or below its reorder point. Variants without reorder
+// settings at a warehouse fall back to being below the given threshold.
 func (r *InventoryRepository) GetLowStock(ctx context.Context, threshold decimal.Decimal) ([]entity.InventoryLevel, error) {
 	query := `
-		SELECT id, warehouse_id, variant_id, quantity, batch_number, expiry_date
-		FROM inventory_levels
-		WHERE quantity < $1
-		ORDER BY quantity
+		SELECT il.id, il.warehouse_id, il.variant_id, il.quantity, il.batch_number, il.expiry_date
+		FROM inventory_levels il
+		LEFT JOIN reorder_settings rs ON rs.warehouse_id = il.warehouse_id AND rs.variant_id = il.variant_id
+		WHERE (rs.id IS NOT NULL AND il.quantity <= rs.reorder_point)
+		   OR (rs.id IS NULL AND il.quantity < $1)
+		ORDER BY il.quantity
 	`
-	rows, err := r.db.Pool.Query(ctx, query, threshold)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, threshold)
 	if err != nil {
 		return nil, err
 	}
@@ -315,7 +1120,7 @@ func (r *InventoryRepository) GetLowStock(ctx context.Context, threshold decimal
 // List stores all inventory levels with pagination
 func (r *InventoryRepository) List(ctx context.Context, offset, limit int) ([]entity.InventoryLevel, int64, error) {
 	var total int64
-	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM inventory_levels`).Scan(&total); err != nil {
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM inventory_levels`).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -329,7 +1134,7 @@ func (r *InventoryRepository) List(ctx context.Context, offset, limit int) ([]en
 		ORDER BY il.id DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
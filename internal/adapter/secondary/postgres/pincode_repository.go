@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 	"github.com/qwikshelf/api/internal/domain/repository"
 )
 
@@ -77,6 +78,25 @@ func (r *pincodeRepository) GetByPincode(ctx context.Context, pincode string) (*
 	return &area, nil
 }
 
+// GetZoneByCoordinates finds the active delivery zone whose pincode boundary contains the coordinate
+func (r *pincodeRepository) GetZoneByCoordinates(ctx context.Context, lat, lng float64) (int64, error) {
+	query := `
+		SELECT sp.zone_id
+		FROM pincode_geodata pg
+		JOIN serviceable_pincodes sp ON sp.pincode = pg.pincode
+		JOIN delivery_zones dz ON dz.id = sp.zone_id
+		WHERE ST_Contains(pg.boundary, ST_SetSRID(ST_MakePoint($1, $2), 4326))
+		  AND sp.is_active = true AND dz.is_active = true
+		LIMIT 1
+	`
+	var zoneID int64
+	err := r.db.Pool.QueryRow(ctx, query, lng, lat).Scan(&zoneID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domainErrors.ErrNotFound
+	}
+	return zoneID, err
+}
+
 func (r *pincodeRepository) CreateZone(ctx context.Context, zone *entity.DeliveryZone) error {
 	query := `
 		INSERT INTO delivery_zones (name, warehouse_id, min_order_amount, delivery_charge, estimated_delivery_text, is_active)
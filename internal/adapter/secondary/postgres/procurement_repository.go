@@ -22,7 +22,7 @@ func NewProcurementRepository(db *DB) *ProcurementRepository {
 
 // Create creates a new procurement with its items
 func (r *ProcurementRepository) Create(ctx context.Context, procurement *entity.Procurement) error {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -42,8 +42,8 @@ func (r *ProcurementRepository) Create(ctx context.Context, procurement *entity.
 	}
 
 	itemQuery := `
-		INSERT INTO procurement_items (procurement_id, variant_id, quantity_ordered, unit_cost)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO procurement_items (procurement_id, variant_id, quantity_ordered, unit_cost, list_price, price_list_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`
 	for i := range procurement.Items {
@@ -51,6 +51,7 @@ func (r *ProcurementRepository) Create(ctx context.Context, procurement *entity.
 		err = tx.QueryRow(ctx, itemQuery,
 			procurement.ID, procurement.Items[i].VariantID,
 			procurement.Items[i].QuantityOrdered, procurement.Items[i].UnitCost,
+			procurement.Items[i].ListPrice, procurement.Items[i].PriceListID,
 		).Scan(&procurement.Items[i].ID)
 		if err != nil {
 			return err
@@ -76,7 +77,7 @@ func (r *ProcurementRepository) GetByID(ctx context.Context, id int64) (*entity.
 		Supplier:  &entity.Supplier{},
 		Warehouse: &entity.Warehouse{},
 	}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Conn(ctx).QueryRow(ctx, query, id).Scan(
 		&p.ID, &p.SupplierID, &p.WarehouseID, &p.OrderedByUserID,
 		&p.CreatedAt, &p.ExpectedDelivery, &p.Status,
 		&p.Supplier.Name, &p.Supplier.Phone, &p.Supplier.Location,
@@ -93,14 +94,14 @@ func (r *ProcurementRepository) GetByID(ctx context.Context, id int64) (*entity.
 
 	// Fetch items
 	itemQuery := `
-		SELECT pi.id, pi.variant_id, pi.quantity_ordered, pi.quantity_received, pi.unit_cost,
-		       pv.name, pv.sku, pv.unit
+		SELECT pi.id, pi.variant_id, pi.quantity_ordered, pi.quantity_received, pi.quantity_rejected, pi.unit_cost,
+		       pi.batch_number, pi.expiry_date, pi.list_price, pi.price_list_id, pv.name, pv.sku, pv.unit
 		FROM procurement_items pi
 		JOIN product_variants pv ON pv.id = pi.variant_id
 		WHERE pi.procurement_id = $1
 		ORDER BY pi.id
 	`
-	rows, err := r.db.Pool.Query(ctx, itemQuery, id)
+	rows, err := r.db.Conn(ctx).Query(ctx, itemQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +112,8 @@ func (r *ProcurementRepository) GetByID(ctx context.Context, id int64) (*entity.
 		item.ProcurementID = id
 		item.Variant = &entity.ProductVariant{}
 		if err := rows.Scan(
-			&item.ID, &item.VariantID, &item.QuantityOrdered, &item.QuantityReceived, &item.UnitCost,
-			&item.Variant.Name, &item.Variant.SKU, &item.Variant.Unit,
+			&item.ID, &item.VariantID, &item.QuantityOrdered, &item.QuantityReceived, &item.QuantityRejected, &item.UnitCost,
+			&item.BatchNumber, &item.ExpiryDate, &item.ListPrice, &item.PriceListID, &item.Variant.Name, &item.Variant.SKU, &item.Variant.Unit,
 		); err != nil {
 			return nil, err
 		}
@@ -122,10 +123,23 @@ func (r *ProcurementRepository) GetByID(ctx context.Context, id int64) (*entity.
 	return p, rows.Err()
 }
 
+// GetByIDForUpdate retrieves a procurement with its items and locks the procurement row
+func (r *ProcurementRepository) GetByIDForUpdate(ctx context.Context, id int64) (*entity.Procurement, error) {
+	var locked int64
+	err := r.db.Conn(ctx).QueryRow(ctx, `SELECT id FROM procurements WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrProcurementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
 // List retrieves all procurements with pagination
 func (r *ProcurementRepository) List(ctx context.Context, offset, limit int) ([]entity.Procurement, int64, error) {
 	var total int64
-	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM procurements`).Scan(&total); err != nil {
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM procurements`).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -139,7 +153,7 @@ func (r *ProcurementRepository) List(ctx context.Context, offset, limit int) ([]
 		ORDER BY p.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -176,7 +190,7 @@ func (r *ProcurementRepository) ListBySupplier(ctx context.Context, supplierID i
 		WHERE p.supplier_id = $1
 		ORDER BY p.created_at DESC
 	`
-	rows, err := r.db.Pool.Query(ctx, query, supplierID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, supplierID)
 	if err != nil {
 		return nil, err
 	}
@@ -199,28 +213,210 @@ func (r *ProcurementRepository) ListBySupplier(ctx context.Context, supplierID i
 	return procurements, rows.Err()
 }
 
-// UpdateStatus updates the status of a procurement
-func (r *ProcurementRepository) UpdateStatus(ctx context.Context, id int64, status entity.ProcurementStatus) error {
-	query := `UPDATE procurements SET status = $1 WHERE id = $2`
-	result, err := r.db.Pool.Exec(ctx, query, status, id)
+// UpdateStatus moves a procurement from one status to another.
+// The update only applies while the procurement is still in the expected status.
+func (r *ProcurementRepository) UpdateStatus(ctx context.Context, id int64, from, to entity.ProcurementStatus) error {
+	query := `UPDATE procurements SET status = $1 WHERE id = $2 AND status = $3`
+	result, err := r.db.Conn(ctx).Exec(ctx, query, to, id, from)
 	if err != nil {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return domainErrors.ErrProcurementNotFound
+		return domainErrors.ErrProcurementStatus
 	}
 	return nil
 }
 
-// UpdateItemReceived updates the quantity_received for a procurement item
-func (r *ProcurementRepository) UpdateItemReceived(ctx context.Context, itemID int64, quantityReceived float64) error {
-	query := `UPDATE procurement_items SET quantity_received = $1 WHERE id = $2`
-	result, err := r.db.Pool.Exec(ctx, query, quantityReceived, itemID)
+// AddStatusEvent appends an entry to a procurement's status history
+func (r *ProcurementRepository) AddStatusEvent(ctx context.Context, event *entity.ProcurementStatusEvent) error {
+	query := `
+		INSERT INTO procurement_status_history (procurement_id, from_status, to_status, amount, changed_by_user_id, role_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING id, changed_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		event.ProcurementID, event.FromStatus, event.ToStatus, event.Amount, event.ChangedByUserID, event.RoleID, event.Note,
+	).Scan(&event.ID, &event.ChangedAt)
+}
+
+// ListStatusHistory retrieves a procurement's status history in chronological order
+func (r *ProcurementRepository) ListStatusHistory(ctx context.Context, procurementID int64) ([]entity.ProcurementStatusEvent, error) {
+	query := `
+		SELECT h.id, h.procurement_id, h.from_status, h.to_status, h.amount, h.changed_by_user_id,
+		       COALESCE(NULLIF(u.full_name, ''), u.username, ''), h.role_id, COALESCE(h.note, ''), h.changed_at
+		FROM procurement_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by_user_id
+		WHERE h.procurement_id = $1
+		ORDER BY h.changed_at, h.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, procurementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entity.ProcurementStatusEvent{}
+	for rows.Next() {
+		var e entity.ProcurementStatusEvent
+		if err := rows.Scan(
+			&e.ID, &e.ProcurementID, &e.FromStatus, &e.ToStatus, &e.Amount, &e.ChangedByUserID,
+			&e.ChangedByName, &e.RoleID, &e.Note, &e.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CreateReceipt records the header of a goods receipt; its lines are added with AddReceiptItem
+func (r *ProcurementRepository) CreateReceipt(ctx context.Context, receipt *entity.GoodsReceipt) error {
+	query := `
+		INSERT INTO goods_receipts (procurement_id, warehouse_id, received_by_user_id, delivery_reference, notes)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING id, received_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		receipt.ProcurementID, receipt.WarehouseID, receipt.ReceivedByUserID, receipt.DeliveryReference, receipt.Notes,
+	).Scan(&receipt.ID, &receipt.ReceivedAt)
+}
+
+// AddReceiptItem records a receipt line and adds its accepted and rejected quantities to the
+// purchase order line; the line keeps the batch details of its latest delivery
+func (r *ProcurementRepository) AddReceiptItem(ctx context.Context, item *entity.GoodsReceiptItem) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO goods_receipt_items (receipt_id, procurement_item_id, variant_id, quantity_accepted, quantity_rejected,
+		                                 rejection_reason, batch_number, expiry_date, lot_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9)
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query,
+		item.ReceiptID, item.ProcurementItemID, item.VariantID, item.QuantityAccepted, item.QuantityRejected,
+		item.RejectionReason, item.BatchNumber, item.ExpiryDate, item.LotID,
+	).Scan(&item.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE procurement_items
+		SET quantity_received = quantity_received + $1, quantity_rejected = quantity_rejected + $2,
+		    batch_number = COALESCE($3, batch_number), expiry_date = COALESCE($4, expiry_date)
+		WHERE id = $5
+	`, item.QuantityAccepted, item.QuantityRejected, item.BatchNumber, item.ExpiryDate, item.ProcurementItemID)
 	if err != nil {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return domainErrors.ErrProcurementNotFound
+		return domainErrors.ErrReceiptItemNotOnOrder
 	}
-	return nil
+
+	return tx.Commit(ctx)
+}
+
+const goodsReceiptSelect = `
+	SELECT gr.id, gr.procurement_id, gr.warehouse_id, gr.received_by_user_id,
+	       COALESCE(NULLIF(u.full_name, ''), u.username, ''), COALESCE(gr.delivery_reference, ''),
+	       COALESCE(gr.notes, ''), gr.received_at
+	FROM goods_receipts gr
+	LEFT JOIN users u ON u.id = gr.received_by_user_id`
+
+func scanGoodsReceipt(row pgx.Row) (*entity.GoodsReceipt, error) {
+	gr := &entity.GoodsReceipt{}
+	err := row.Scan(
+		&gr.ID, &gr.ProcurementID, &gr.WarehouseID, &gr.ReceivedByUserID,
+		&gr.ReceivedByName, &gr.DeliveryReference, &gr.Notes, &gr.ReceivedAt,
+	)
+	return gr, err
+}
+
+// GetReceiptByID retrieves a goods receipt with its lines
+func (r *ProcurementRepository) GetReceiptByID(ctx context.Context, id int64) (*entity.GoodsReceipt, error) {
+	receipt, err := scanGoodsReceipt(r.db.Conn(ctx).QueryRow(ctx, goodsReceiptSelect+` WHERE gr.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrGoodsReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := []entity.GoodsReceipt{*receipt}
+	if err := r.loadReceiptItems(ctx, receipts); err != nil {
+		return nil, err
+	}
+	return &receipts[0], nil
+}
+
+// ListReceipts retrieves the goods receipts of a procurement with their lines, oldest first
+func (r *ProcurementRepository) ListReceipts(ctx context.Context, procurementID int64) ([]entity.GoodsReceipt, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, goodsReceiptSelect+` WHERE gr.procurement_id = $1 ORDER BY gr.received_at, gr.id`, procurementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []entity.GoodsReceipt{}
+	for rows.Next() {
+		receipt, err := scanGoodsReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, *receipt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadReceiptItems(ctx, receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// loadReceiptItems fills in the lines of the given receipts
+func (r *ProcurementRepository) loadReceiptItems(ctx context.Context, receipts []entity.GoodsReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(receipts))
+	index := make(map[int64]int, len(receipts))
+	for i := range receipts {
+		ids[i] = receipts[i].ID
+		index[receipts[i].ID] = i
+	}
+
+	query := `
+		SELECT gri.id, gri.receipt_id, gri.procurement_item_id, gri.variant_id, pv.name,
+		       gri.quantity_accepted, gri.quantity_rejected, COALESCE(gri.rejection_reason, ''),
+		       gri.batch_number, gri.expiry_date, gri.lot_id
+		FROM goods_receipt_items gri
+		JOIN product_variants pv ON pv.id = gri.variant_id
+		WHERE gri.receipt_id = ANY($1)
+		ORDER BY gri.receipt_id, gri.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.GoodsReceiptItem
+		if err := rows.Scan(
+			&item.ID, &item.ReceiptID, &item.ProcurementItemID, &item.VariantID, &item.VariantName,
+			&item.QuantityAccepted, &item.QuantityRejected, &item.RejectionReason,
+			&item.BatchNumber, &item.ExpiryDate, &item.LotID,
+		); err != nil {
+			return err
+		}
+		i := index[item.ReceiptID]
+		receipts[i].Items = append(receipts[i].Items, item)
+	}
+	return rows.Err()
 }
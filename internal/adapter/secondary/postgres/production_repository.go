@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// ProductionRepository implements repository.ProductionRepository
+type ProductionRepository struct {
+	db *DB
+}
+
+// NewProductionRepository creates a new production repository
+func NewProductionRepository(db *DB) *ProductionRepository {
+	return &ProductionRepository{db: db}
+}
+
+// uniqueViolation is the SQLSTATE raised when an insert repeats a unique key
+const uniqueViolation = "23505"
+
+// CreateRun creates a new production run with its logs. A batch code already used by another
+// run, including one recorded concurrently, returns ErrBatchCodeExists.
+func (r *ProductionRepository) CreateRun(ctx context.Context, run *entity.ProductionRun) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO production_runs (warehouse_id, staff_id, batch_code, expiry_date)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, query, run.WarehouseID, run.StaffID, run.BatchCode, run.ExpiryDate).Scan(&run.ID, &run.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "production_runs_batch_code_key" {
+		return domainErrors.ErrBatchCodeExists
+	}
+	if err != nil {
+		return err
+	}
+
+	logQuery := `
+		INSERT INTO production_logs (run_id, input_variant_id, input_qty, output_variant_id, output_qty)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	for i := range run.Logs {
+		run.Logs[i].RunID = run.ID
+		err = tx.QueryRow(ctx, logQuery,
+			run.ID, run.Logs[i].InputVariantID, run.Logs[i].InputQty,
+			run.Logs[i].OutputVariantID, run.Logs[i].OutputQty,
+		).Scan(&run.Logs[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetRunByID retrieves a production run with its logs
+func (r *ProductionRepository) GetRunByID(ctx context.Context, id int64) (*entity.ProductionRun, error) {
+	return r.getRun(ctx, `pr.id = $1`, id)
+}
+
+// GetRunByBatchCode retrieves a production run by its batch code
+func (r *ProductionRepository) GetRunByBatchCode(ctx context.Context, batchCode string) (*entity.ProductionRun, error) {
+	return r.getRun(ctx, `pr.batch_code = $1`, batchCode)
+}
+
+func (r *ProductionRepository) getRun(ctx context.Context, where string, arg any) (*entity.ProductionRun, error) {
+	query := `
+		SELECT pr.id, pr.warehouse_id, pr.staff_id, pr.batch_code, pr.expiry_date, pr.created_at,
+		       w.name, w.type, u.username
+		FROM production_runs pr
+		JOIN warehouses w ON w.id = pr.warehouse_id
+		JOIN users u ON u.id = pr.staff_id
+		WHERE ` + where
+	run := &entity.ProductionRun{
+		Warehouse: &entity.Warehouse{},
+		Staff:     &entity.User{},
+	}
+	err := r.db.Conn(ctx).QueryRow(ctx, query, arg).Scan(
+		&run.ID, &run.WarehouseID, &run.StaffID, &run.BatchCode, &run.ExpiryDate, &run.CreatedAt,
+		&run.Warehouse.Name, &run.Warehouse.Type, &run.Staff.Username,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrProductionRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	run.Warehouse.ID = run.WarehouseID
+	run.Staff.ID = run.StaffID
+
+	run.Logs, err = r.GetLogsByRun(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns retrieves production runs with pagination
+func (r *ProductionRepository) ListRuns(ctx context.Context, offset, limit int) ([]entity.ProductionRun, int64, error) {
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM production_runs`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT pr.id, pr.warehouse_id, pr.staff_id, pr.batch_code, pr.expiry_date, pr.created_at,
+		       w.name, u.username
+		FROM production_runs pr
+		JOIN warehouses w ON w.id = pr.warehouse_id
+		JOIN users u ON u.id = pr.staff_id
+		ORDER BY pr.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var runs []entity.ProductionRun
+	for rows.Next() {
+		run := entity.ProductionRun{
+			Warehouse: &entity.Warehouse{},
+			Staff:     &entity.User{},
+		}
+		if err := rows.Scan(
+			&run.ID, &run.WarehouseID, &run.StaffID, &run.BatchCode, &run.ExpiryDate, &run.CreatedAt,
+			&run.Warehouse.Name, &run.Staff.Username,
+		); err != nil {
+			return nil, 0, err
+		}
+		run.Warehouse.ID = run.WarehouseID
+		run.Staff.ID = run.StaffID
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// ListRunsBetween retrieves production runs with their logs created within [from, to)
+func (r *ProductionRepository) ListRunsBetween(ctx context.Context, from, to time.Time) ([]entity.ProductionRun, error) {
+	query := `
+		SELECT pr.id, pr.warehouse_id, pr.staff_id, pr.batch_code, pr.expiry_date, pr.created_at, w.name
+		FROM production_runs pr
+		JOIN warehouses w ON w.id = pr.warehouse_id
+		WHERE pr.created_at >= $1 AND pr.created_at < $2
+		ORDER BY pr.created_at
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []entity.ProductionRun
+	for rows.Next() {
+		run := entity.ProductionRun{Warehouse: &entity.Warehouse{}}
+		if err := rows.Scan(
+			&run.ID, &run.WarehouseID, &run.StaffID, &run.BatchCode, &run.ExpiryDate, &run.CreatedAt, &run.Warehouse.Name,
+		); err != nil {
+			return nil, err
+		}
+		run.Warehouse.ID = run.WarehouseID
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range runs {
+		if runs[i].Logs, err = r.GetLogsByRun(ctx, runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// AddLog adds an input/output log to an existing production run
+func (r *ProductionRepository) AddLog(ctx context.Context, log *entity.ProductionLog) error {
+	query := `
+		INSERT INTO production_logs (run_id, input_variant_id, input_qty, output_variant_id, output_qty)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		log.RunID, log.InputVariantID, log.InputQty, log.OutputVariantID, log.OutputQty,
+	).Scan(&log.ID)
+}
+
+// GetLogsByRun retrieves all logs for a production run
+func (r *ProductionRepository) GetLogsByRun(ctx context.Context, runID int64) ([]entity.ProductionLog, error) {
+	query := `
+		SELECT pl.id, pl.run_id, pl.input_variant_id, pl.input_qty, pl.output_variant_id, pl.output_qty,
+		       iv.name, iv.sku, iv.unit, ov.name, ov.sku, ov.unit
+		FROM production_logs pl
+		JOIN product_variants iv ON iv.id = pl.input_variant_id
+		JOIN product_variants ov ON ov.id = pl.output_variant_id
+		WHERE pl.run_id = $1
+		ORDER BY pl.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []entity.ProductionLog
+	for rows.Next() {
+		log := entity.ProductionLog{
+			InputVariant:  &entity.ProductVariant{},
+			OutputVariant: &entity.ProductVariant{},
+		}
+		if err := rows.Scan(
+			&log.ID, &log.RunID, &log.InputVariantID, &log.InputQty, &log.OutputVariantID, &log.OutputQty,
+			&log.InputVariant.Name, &log.InputVariant.SKU, &log.InputVariant.Unit,
+			&log.OutputVariant.Name, &log.OutputVariant.SKU, &log.OutputVariant.Unit,
+		); err != nil {
+			return nil, err
+		}
+		log.InputVariant.ID = log.InputVariantID
+		log.OutputVariant.ID = log.OutputVariantID
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
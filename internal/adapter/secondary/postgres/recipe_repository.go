@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// RecipeRepository implements repository.RecipeRepository
+type RecipeRepository struct {
+	db *DB
+}
+
+// NewRecipeRepository creates a new recipe repository
+func NewRecipeRepository(db *DB) *RecipeRepository {
+	return &RecipeRepository{db: db}
+}
+
+// Create creates a recipe with its items
+func (r *RecipeRepository) Create(ctx context.Context, recipe *entity.Recipe) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO recipes (output_variant_id, output_qty, expected_yield, yield_tolerance, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		recipe.OutputVariantID, recipe.OutputQty, recipe.ExpectedYield, recipe.YieldTolerance, recipe.Notes,
+	).Scan(&recipe.ID, &recipe.CreatedAt, &recipe.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRecipeItems(ctx, tx, recipe); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetByOutputVariant retrieves the recipe that produces a variant
+func (r *RecipeRepository) GetByOutputVariant(ctx context.Context, variantID int64) (*entity.Recipe, error) {
+	query := `
+		SELECT r.id, r.output_variant_id, r.output_qty, r.expected_yield, r.yield_tolerance,
+		       COALESCE(r.notes, ''), r.created_at, r.updated_at,
+		       pv.name, pv.sku, pv.unit
+		FROM recipes r
+		JOIN product_variants pv ON pv.id = r.output_variant_id
+		WHERE r.output_variant_id = $1
+	`
+	recipe := &entity.Recipe{OutputVariant: &entity.ProductVariant{}}
+	err := r.db.Conn(ctx).QueryRow(ctx, query, variantID).Scan(
+		&recipe.ID, &recipe.OutputVariantID, &recipe.OutputQty, &recipe.ExpectedYield, &recipe.YieldTolerance,
+		&recipe.Notes, &recipe.CreatedAt, &recipe.UpdatedAt,
+		&recipe.OutputVariant.Name, &recipe.OutputVariant.SKU, &recipe.OutputVariant.Unit,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrRecipeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	recipe.OutputVariant.ID = recipe.OutputVariantID
+
+	itemQuery := `
+		SELECT ri.id, ri.input_variant_id, ri.quantity, pv.name, pv.sku, pv.unit
+		FROM recipe_items ri
+		JOIN product_variants pv ON pv.id = ri.input_variant_id
+		WHERE ri.recipe_id = $1
+		ORDER BY ri.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, itemQuery, recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entity.RecipeItem{RecipeID: recipe.ID, InputVariant: &entity.ProductVariant{}}
+		if err := rows.Scan(
+			&item.ID, &item.InputVariantID, &item.Quantity,
+			&item.InputVariant.Name, &item.InputVariant.SKU, &item.InputVariant.Unit,
+		); err != nil {
+			return nil, err
+		}
+		item.InputVariant.ID = item.InputVariantID
+		recipe.Items = append(recipe.Items, item)
+	}
+	return recipe, rows.Err()
+}
+
+// Update updates a recipe and replaces its items
+func (r *RecipeRepository) Update(ctx context.Context, recipe *entity.Recipe) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE recipes
+		SET output_qty = $1, expected_yield = $2, yield_tolerance = $3, notes = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		recipe.OutputQty, recipe.ExpectedYield, recipe.YieldTolerance, recipe.Notes, recipe.ID,
+	).Scan(&recipe.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrRecipeNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recipe_items WHERE recipe_id = $1`, recipe.ID); err != nil {
+		return err
+	}
+	if err := insertRecipeItems(ctx, tx, recipe); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete deletes the recipe for a variant
+func (r *RecipeRepository) Delete(ctx context.Context, variantID int64) error {
+	result, err := r.db.Conn(ctx).Exec(ctx, `DELETE FROM recipes WHERE output_variant_id = $1`, variantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrRecipeNotFound
+	}
+	return nil
+}
+
+func insertRecipeItems(ctx context.Context, tx pgx.Tx, recipe *entity.Recipe) error {
+	query := `
+		INSERT INTO recipe_items (recipe_id, input_variant_id, quantity)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	for i := range recipe.Items {
+		recipe.Items[i].RecipeID = recipe.ID
+		if err := tx.QueryRow(ctx, query,
+			recipe.ID, recipe.Items[i].InputVariantID, recipe.Items[i].Quantity,
+		).Scan(&recipe.Items[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// ReplenishmentRepository implements repository.ReplenishmentRepository
+type ReplenishmentRepository struct {
+	db *DB
+}
+
+// NewReplenishmentRepository creates a new replenishment repository
+func NewReplenishmentRepository(db *DB) *ReplenishmentRepository {
+	return &ReplenishmentRepository{db: db}
+}
+
+// UpsertReorderSetting creates or replaces the setting of a variant at a warehouse
+func (r *ReplenishmentRepository) UpsertReorderSetting(ctx context.Context, s *entity.ReorderSetting) error {
+	query := `
+		INSERT INTO reorder_settings (warehouse_id, variant_id, min_quantity, reorder_point, max_quantity, lead_time_days)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET
+			min_quantity = EXCLUDED.min_quantity,
+			reorder_point = EXCLUDED.reorder_point,
+			max_quantity = EXCLUDED.max_quantity,
+			lead_time_days = EXCLUDED.lead_time_days,
+			updated_at = NOW()
+		RETURNING id, updated_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		s.WarehouseID, s.VariantID, s.MinQuantity, s.ReorderPoint, s.MaxQuantity, s.LeadTimeDays,
+	).Scan(&s.ID, &s.UpdatedAt)
+}
+
+// DeleteReorderSetting removes the setting of a variant at a warehouse
+func (r *ReplenishmentRepository) DeleteReorderSetting(ctx context.Context, warehouseID, variantID int64) error {
+	result, err := r.db.Conn(ctx).Exec(ctx, `DELETE FROM reorder_settings WHERE warehouse_id = $1 AND variant_id = $2`, warehouseID, variantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrReorderSettingNotFound
+	}
+	return nil
+}
+
+const reorderSettingSelect = `
+	SELECT rs.id, rs.warehouse_id, w.name, rs.variant_id, pv.name, pv.sku,
+	       rs.min_quantity, rs.reorder_point, rs.max_quantity, rs.lead_time_days, rs.updated_at`
+
+// ListReorderSettings retrieves reorder settings, optionally for one warehouse
+func (r *ReplenishmentRepository) ListReorderSettings(ctx context.Context, warehouseID *int64) ([]entity.ReorderSetting, error) {
+	query := reorderSettingSelect + `
+		FROM reorder_settings rs
+		JOIN warehouses w ON w.id = rs.warehouse_id
+		JOIN product_variants pv ON pv.id = rs.variant_id
+		WHERE ($1::int IS NULL OR rs.warehouse_id = $1)
+		ORDER BY w.name, pv.name
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []entity.ReorderSetting{}
+	for rows.Next() {
+		s, err := scanReorderSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *s)
+	}
+	return settings, rows.Err()
+}
+
+// ListCandidates returns the reorder settings with their stock position, counting reservations
+// that have not expired the way stock availability does. Sales of pack variants
+// count towards their family's base variant, converted by their conversion factor, the way
+// sales deduct stock.
+func (r *ReplenishmentRepository) ListCandidates(ctx context.Context, warehouseID *int64, salesSince time.Time) ([]entity.ReplenishmentCandidate, error) {
+	query := reorderSettingSelect + `,
+	       COALESCE(il.quantity, 0),
+	       COALESCE((
+	           SELECT SUM(sr.quantity)
+	           FROM stock_reservations sr
+	           WHERE sr.warehouse_id = rs.warehouse_id AND sr.variant_id = rs.variant_id
+	             AND sr.status = 'active' AND sr.expires_at > NOW()
+	       ), 0),
+	       COALESCE((
+	           SELECT SUM(pi.quantity_ordered - COALESCE(pi.quantity_received, 0))
+	           FROM procurement_items pi
+	           JOIN procurements p ON p.id = pi.procurement_id
+	           WHERE p.warehouse_id = rs.warehouse_id AND pi.variant_id = rs.variant_id
+	             AND p.status IN ('pending', 'approved', 'ordered', 'partial')
+	             AND pi.quantity_ordered > COALESCE(pi.quantity_received, 0)
+	       ), 0),
+	       COALESCE((
+	           SELECT SUM(ti.dispatched_quantity)
+	           FROM inventory_transfer_items ti
+	           JOIN inventory_transfers t ON t.id = ti.transfer_id
+	           WHERE t.destination_warehouse_id = rs.warehouse_id AND ti.variant_id = rs.variant_id
+	             AND t.status = 'in_transit'
+	       ), 0),
+	       COALESCE((
+	           SELECT SUM(si.quantity * CASE WHEN sv.id = pv.id THEN 1 ELSE sv.conversion_factor END)
+	           FROM sale_items si
+	           JOIN sales s ON s.id = si.sale_id
+	           JOIN product_variants sv ON sv.id = si.variant_id
+	           WHERE s.warehouse_id = rs.warehouse_id AND s.created_at >= $2
+	             AND s.status NOT IN ('cancelled', 'returned')
+	             AND (sv.id = pv.id OR (pv.conversion_factor = 1 AND sv.family_id = pv.family_id AND sv.conversion_factor > 1))
+	       ), 0)
+		FROM reorder_settings rs
+		JOIN warehouses w ON w.id = rs.warehouse_id
+		JOIN product_variants pv ON pv.id = rs.variant_id
+		LEFT JOIN inventory_levels il ON il.warehouse_id = rs.warehouse_id AND il.variant_id = rs.variant_id
+		WHERE ($1::int IS NULL OR rs.warehouse_id = $1)
+		ORDER BY w.name, pv.name
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, warehouseID, salesSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := decimal.NewFromFloat(time.Since(salesSince).Hours() / 24)
+	if days.LessThan(decimal.NewFromInt(1)) {
+		days = decimal.NewFromInt(1)
+	}
+
+	candidates := []entity.ReplenishmentCandidate{}
+	for rows.Next() {
+		var c entity.ReplenishmentCandidate
+		var sold decimal.Decimal
+		s := &c.Setting
+		if err := rows.Scan(
+			&s.ID, &s.WarehouseID, &s.WarehouseName, &s.VariantID, &s.VariantName, &s.SKU,
+			&s.MinQuantity, &s.ReorderPoint, &s.MaxQuantity, &s.LeadTimeDays, &s.UpdatedAt,
+			&c.OnHand, &c.Reserved, &c.OnOrder, &c.InTransit, &sold,
+		); err != nil {
+			return nil, err
+		}
+		c.AvgDailySales = sold.Div(days).Round(3)
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func scanReorderSetting(row pgx.Row) (*entity.ReorderSetting, error) {
+	var s entity.ReorderSetting
+	err := row.Scan(
+		&s.ID, &s.WarehouseID, &s.WarehouseName, &s.VariantID, &s.VariantName, &s.SKU,
+		&s.MinQuantity, &s.ReorderPoint, &s.MaxQuantity, &s.LeadTimeDays, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
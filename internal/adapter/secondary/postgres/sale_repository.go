@@ -24,25 +24,46 @@ func NewSaleRepository(db *DB) *SaleRepository {
 
 // Create records a new sale with its items
 func (r *SaleRepository) Create(ctx context.Context, sale *entity.Sale) error {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
 	query := `
-		INSERT INTO sales (warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, payment_method, processed_by_user_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO sales (warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, delivery_charge, payment_method, processed_by_user_id, status, zone_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 	err = tx.QueryRow(ctx, query,
 		sale.WarehouseID, sale.CustomerID, sale.CustomerName, sale.TotalAmount, sale.TaxAmount, sale.DiscountAmount,
-		sale.PaymentMethod, sale.ProcessedByUserID,
+		sale.DeliveryCharge, sale.PaymentMethod, sale.ProcessedByUserID, sale.Status, sale.ZoneID,
 	).Scan(&sale.ID, &sale.CreatedAt)
 	if err != nil {
 		return err
 	}
 
+	if addr := sale.DeliveryAddress; addr != nil {
+		addr.SaleID = sale.ID
+		_, err = tx.Exec(ctx, `
+			INSERT INTO sale_delivery_addresses (sale_id, customer_id, customer_address_id, contact_name, phone, address_line1, address_line2, landmark, city, pincode)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10)
+		`, addr.SaleID, addr.CustomerID, addr.CustomerAddressID, addr.ContactName, addr.Phone, addr.AddressLine1,
+			addr.AddressLine2, addr.Landmark, addr.City, addr.Pincode)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Start the order timeline with its initial status
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_history (sale_id, status, changed_by_user_id, changed_at)
+		VALUES ($1, $2, $3, $4)
+	`, sale.ID, sale.Status, sale.ProcessedByUserID, sale.CreatedAt)
+	if err != nil {
+		return err
+	}
+
 	itemQuery := `
 		INSERT INTO sale_items (sale_id, variant_id, quantity, unit_price, line_total)
 		VALUES ($1, $2, $3, $4, $5)
@@ -62,16 +83,23 @@ func (r *SaleRepository) Create(ctx context.Context, sale *entity.Sale) error {
 	return tx.Commit(ctx)
 }
 
+// saleCostOfGoods sums the cost of goods of a sale's items, or is NULL while any item has none
+const saleCostOfGoods = `(
+			SELECT SUM(si.cost_of_goods) FROM sale_items si WHERE si.sale_id = sales.id
+			HAVING COUNT(*) = COUNT(si.cost_of_goods)
+		)`
+
 // GetByID retrieves a sale with its items
 func (r *SaleRepository) GetByID(ctx context.Context, id int64) (*entity.Sale, error) {
 	query := `
-		SELECT id, warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, payment_method, processed_by_user_id, created_at
+		SELECT id, warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, delivery_charge, payment_method, processed_by_user_id, status, zone_id, created_at,
+		       ` + saleCostOfGoods + `
 		FROM sales WHERE id = $1
 	`
 	s := &entity.Sale{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Conn(ctx).QueryRow(ctx, query, id).Scan(
 		&s.ID, &s.WarehouseID, &s.CustomerID, &s.CustomerName, &s.TotalAmount, &s.TaxAmount, &s.DiscountAmount,
-		&s.PaymentMethod, &s.ProcessedByUserID, &s.CreatedAt,
+		&s.DeliveryCharge, &s.PaymentMethod, &s.ProcessedByUserID, &s.Status, &s.ZoneID, &s.CreatedAt, &s.CostOfGoods,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrNotFound
@@ -80,15 +108,31 @@ func (r *SaleRepository) GetByID(ctx context.Context, id int64) (*entity.Sale, e
 		return nil, err
 	}
 
+	// Fetch delivery address (storefront orders only)
+	addr := &entity.SaleDeliveryAddress{SaleID: id}
+	err = r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT customer_id, customer_address_id, contact_name, phone, address_line1, COALESCE(address_line2, ''),
+		       COALESCE(landmark, ''), COALESCE(city, ''), pincode
+		FROM sale_delivery_addresses WHERE sale_id = $1
+	`, id).Scan(
+		&addr.CustomerID, &addr.CustomerAddressID, &addr.ContactName, &addr.Phone, &addr.AddressLine1, &addr.AddressLine2,
+		&addr.Landmark, &addr.City, &addr.Pincode,
+	)
+	if err == nil {
+		s.DeliveryAddress = addr
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
 	// Fetch items
 	itemQuery := `
-		SELECT si.id, si.variant_id, si.quantity, si.unit_price, si.line_total,
+		SELECT si.id, si.variant_id, si.quantity, si.unit_price, si.line_total, si.cost_of_goods,
 		       pv.name, pv.sku
 		FROM sale_items si
 		JOIN product_variants pv ON pv.id = si.variant_id
 		WHERE si.sale_id = $1
 	`
-	rows, err := r.db.Pool.Query(ctx, itemQuery, id)
+	rows, err := r.db.Conn(ctx).Query(ctx, itemQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +143,7 @@ func (r *SaleRepository) GetByID(ctx context.Context, id int64) (*entity.Sale, e
 		item.SaleID = id
 		item.Variant = &entity.ProductVariant{}
 		if err := rows.Scan(
-			&item.ID, &item.VariantID, &item.Quantity, &item.UnitPrice, &item.LineTotal,
+			&item.ID, &item.VariantID, &item.Quantity, &item.UnitPrice, &item.LineTotal, &item.CostOfGoods,
 			&item.Variant.Name, &item.Variant.SKU,
 		); err != nil {
 			return nil, err
@@ -111,13 +155,14 @@ func (r *SaleRepository) GetByID(ctx context.Context, id int64) (*entity.Sale, e
 }
 
 // List retrieves all sales with pagination and optional filters
-func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error) {
+func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, status *entity.OrderStatus, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error) {
 	var total int64
 
 	// Build dynamic query
 	countQuery := `SELECT COUNT(*) FROM sales WHERE 1=1`
 	query := `
-		SELECT id, warehouse_id, customer_name, total_amount, tax_amount, discount_amount, payment_method, processed_by_user_id, created_at
+		SELECT id, warehouse_id, customer_name, total_amount, tax_amount, discount_amount, delivery_charge, payment_method, processed_by_user_id, status, zone_id, created_at,
+		       ` + saleCostOfGoods + `
 		FROM sales WHERE 1=1
 	`
 
@@ -132,6 +177,14 @@ func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate
 		argCount++
 	}
 
+	if status != nil {
+		where := fmt.Sprintf(" AND status = $%d", argCount)
+		countQuery += where
+		query += where
+		args = append(args, *status)
+		argCount++
+	}
+
 	if startDate != nil {
 		where := fmt.Sprintf(" AND created_at >= $%d", argCount)
 		countQuery += where
@@ -149,7 +202,7 @@ func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate
 	}
 
 	// Get total count
-	err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total)
+	err := r.db.Conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -158,7 +211,7 @@ func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
 	args = append(args, limit, offset)
 
-	rows, err := r.db.Pool.Query(ctx, query, args...)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -169,7 +222,7 @@ func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate
 		var s entity.Sale
 		if err := rows.Scan(
 			&s.ID, &s.WarehouseID, &s.CustomerName, &s.TotalAmount, &s.TaxAmount, &s.DiscountAmount,
-			&s.PaymentMethod, &s.ProcessedByUserID, &s.CreatedAt,
+			&s.DeliveryCharge, &s.PaymentMethod, &s.ProcessedByUserID, &s.Status, &s.ZoneID, &s.CreatedAt, &s.CostOfGoods,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -182,17 +235,18 @@ func (r *SaleRepository) List(ctx context.Context, warehouseID *int64, startDate
 func (r *SaleRepository) ListByCustomer(ctx context.Context, customerID int64, offset, limit int) ([]entity.Sale, int64, error) {
 	var total int64
 	countQuery := `SELECT COUNT(*) FROM sales WHERE customer_id = $1`
-	if err := r.db.Pool.QueryRow(ctx, countQuery, customerID).Scan(&total); err != nil {
+	if err := r.db.Conn(ctx).QueryRow(ctx, countQuery, customerID).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	query := `
-		SELECT id, warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, payment_method, processed_by_user_id, created_at
+		SELECT id, warehouse_id, customer_id, customer_name, total_amount, tax_amount, discount_amount, delivery_charge, payment_method, processed_by_user_id, status, zone_id, created_at,
+		       ` + saleCostOfGoods + `
 		FROM sales WHERE customer_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.Pool.Query(ctx, query, customerID, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, customerID, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -203,7 +257,7 @@ func (r *SaleRepository) ListByCustomer(ctx context.Context, customerID int64, o
 		var s entity.Sale
 		if err := rows.Scan(
 			&s.ID, &s.WarehouseID, &s.CustomerID, &s.CustomerName, &s.TotalAmount, &s.TaxAmount, &s.DiscountAmount,
-			&s.PaymentMethod, &s.ProcessedByUserID, &s.CreatedAt,
+			&s.DeliveryCharge, &s.PaymentMethod, &s.ProcessedByUserID, &s.Status, &s.ZoneID, &s.CreatedAt, &s.CostOfGoods,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -211,3 +265,64 @@ func (r *SaleRepository) ListByCustomer(ctx context.Context, customerID int64, o
 	}
 	return sales, total, rows.Err()
 }
+
+// SetItemCosts saves the cost of goods of each of the sale's items
+func (r *SaleRepository) SetItemCosts(ctx context.Context, items []entity.SaleItem) error {
+	for _, item := range items {
+		if _, err := r.db.Conn(ctx).Exec(ctx, `UPDATE sale_items SET cost_of_goods = $1 WHERE id = $2`, item.CostOfGoods, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateStatus moves a sale from one status to another.
+// The update only applies while the sale is still in the expected status.
+func (r *SaleRepository) UpdateStatus(ctx context.Context, id int64, from, to entity.OrderStatus) error {
+	query := `UPDATE sales SET status = $1 WHERE id = $2 AND status = $3`
+	result, err := r.db.Conn(ctx).Exec(ctx, query, to, id, from)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+// AddStatusEvent appends an entry to an order's status timeline
+func (r *SaleRepository) AddStatusEvent(ctx context.Context, event *entity.OrderStatusEvent) error {
+	query := `
+		INSERT INTO order_status_history (sale_id, status, note, changed_by_user_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id, changed_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		event.SaleID, event.Status, event.Note, event.ChangedByUserID,
+	).Scan(&event.ID, &event.ChangedAt)
+}
+
+// ListStatusHistory retrieves an order's status timeline in chronological order
+func (r *SaleRepository) ListStatusHistory(ctx context.Context, saleID int64) ([]entity.OrderStatusEvent, error) {
+	query := `
+		SELECT id, sale_id, status, COALESCE(note, ''), changed_by_user_id, changed_at
+		FROM order_status_history
+		WHERE sale_id = $1
+		ORDER BY changed_at, id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entity.OrderStatusEvent
+	for rows.Next() {
+		var e entity.OrderStatusEvent
+		if err := rows.Scan(&e.ID, &e.SaleID, &e.Status, &e.Note, &e.ChangedByUserID, &e.ChangedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// StockTakeRepository implements repository.StockTakeRepository
+type StockTakeRepository struct {
+	db *DB
+}
+
+// NewStockTakeRepository creates a new stock-take repository
+func NewStockTakeRepository(db *DB) *StockTakeRepository {
+	return &StockTakeRepository{db: db}
+}
+
+// Create opens a session and snapshots expected quantities from inventory_levels.
+// The partial unique index on open sessions makes a second open session per warehouse fail.
+func (r *StockTakeRepository) Create(ctx context.Context, st *entity.StockTake, variantIDs []int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO stock_takes (warehouse_id, scope, abc_class, status, notes, started_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (warehouse_id) WHERE status = 'open' DO NOTHING
+		RETURNING id, started_at
+	`, st.WarehouseID, st.Scope, st.ABCClass, st.Status, st.Notes, st.StartedByUserID).Scan(&st.ID, &st.StartedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrStockTakeInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create stock take: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO stock_take_lines (stock_take_id, variant_id, expected_quantity)
+		SELECT $1, variant_id, quantity
+		FROM inventory_levels
+		WHERE warehouse_id = $2 AND ($3::int[] IS NULL OR variant_id = ANY($3))
+	`, st.ID, st.WarehouseID, variantIDs)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot stock levels: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// stockTakeLineMoved sums the movements of a line's variant at the session's warehouse between the
+// snapshot and the count, so sales and receipts while counting are not mistaken for variances.
+// It needs the line as l and its session as st.
+const stockTakeLineMoved = `
+	LEFT JOIN LATERAL (
+		SELECT SUM(m.quantity_delta) AS quantity
+		FROM inventory_movements m
+		WHERE m.warehouse_id = st.warehouse_id AND m.variant_id = l.variant_id
+		  AND m.created_at > st.started_at AND m.created_at < l.counted_at
+	) moved ON TRUE`
+
+const stockTakeSelect = `
+	SELECT st.id, st.warehouse_id, w.name, st.scope, st.abc_class, st.status, st.notes,
+	       st.started_by_user_id, st.started_at, st.closed_by_user_id, st.closed_at
+	FROM stock_takes st
+	JOIN warehouses w ON w.id = st.warehouse_id`
+
+// GetByID retrieves a stock take with its lines
+func (r *StockTakeRepository) GetByID(ctx context.Context, id int64) (*entity.StockTake, error) {
+	return r.get(ctx, stockTakeSelect+` WHERE st.id = $1`, id)
+}
+
+// GetByIDForUpdate retrieves a stock take with its lines and locks it for posting
+func (r *StockTakeRepository) GetByIDForUpdate(ctx context.Context, id int64) (*entity.StockTake, error) {
+	return r.get(ctx, stockTakeSelect+` WHERE st.id = $1 FOR UPDATE OF st`, id)
+}
+
+func (r *StockTakeRepository) get(ctx context.Context, query string, id int64) (*entity.StockTake, error) {
+	st, err := scanStockTake(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrStockTakeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT l.id, l.stock_take_id, l.variant_id, pv.name, pv.sku, pv.cost_price, l.expected_quantity,
+		       COALESCE(moved.quantity, 0), l.counted_quantity, l.counted_by_user_id, l.counted_at, l.adjustment_id
+		FROM stock_take_lines l
+		JOIN stock_takes st ON st.id = l.stock_take_id
+		JOIN product_variants pv ON pv.id = l.variant_id`+stockTakeLineMoved+`
+		WHERE l.stock_take_id = $1
+		ORDER BY pv.name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	st.Lines = []entity.StockTakeLine{}
+	for rows.Next() {
+		line, err := scanStockTakeLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		st.Lines = append(st.Lines, *line)
+	}
+	return st, rows.Err()
+}
+
+// List retrieves stock takes matching the filter, newest first, without their lines
+func (r *StockTakeRepository) List(ctx context.Context, filter repository.StockTakeFilter, offset, limit int) ([]entity.StockTake, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.WarehouseID != nil {
+		where += fmt.Sprintf(" AND st.warehouse_id = $%d", argCount)
+		args = append(args, *filter.WarehouseID)
+		argCount++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND st.status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM stock_takes st`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := stockTakeSelect + where +
+		fmt.Sprintf(" ORDER BY st.started_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	stockTakes := []entity.StockTake{}
+	for rows.Next() {
+		st, err := scanStockTake(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		stockTakes = append(stockTakes, *st)
+	}
+	return stockTakes, total, rows.Err()
+}
+
+// RecordCount sets or adds to the counted quantity of a variant in a session
+func (r *StockTakeRepository) RecordCount(ctx context.Context, stockTakeID, variantID int64, quantity decimal.Decimal, add bool, userID *int64) (*entity.StockTakeLine, error) {
+	query := `
+		WITH line AS (
+			INSERT INTO stock_take_lines (stock_take_id, variant_id, expected_quantity, counted_quantity, counted_by_user_id, counted_at)
+			VALUES ($1, $2, 0, $3, $5, NOW())
+			ON CONFLICT (stock_take_id, variant_id) DO UPDATE SET
+				counted_quantity = CASE WHEN $4 THEN COALESCE(stock_take_lines.counted_quantity, 0) + $3 ELSE $3 END,
+				counted_by_user_id = $5,
+				counted_at = NOW()
+			RETURNING *
+		)
+		SELECT l.id, l.stock_take_id, l.variant_id, pv.name, pv.sku, pv.cost_price, l.expected_quantity,
+		       COALESCE(moved.quantity, 0), l.counted_quantity, l.counted_by_user_id, l.counted_at, l.adjustment_id
+		FROM line l
+		JOIN stock_takes st ON st.id = l.stock_take_id
+		JOIN product_variants pv ON pv.id = l.variant_id` + stockTakeLineMoved
+	return scanStockTakeLine(r.db.Conn(ctx).QueryRow(ctx, query, stockTakeID, variantID, quantity, add, userID))
+}
+
+// SetLineAdjustment links a line to the adjustment that posted its variance
+func (r *StockTakeRepository) SetLineAdjustment(ctx context.Context, lineID, adjustmentID int64) error {
+	_, err := r.db.Conn(ctx).Exec(ctx, `UPDATE stock_take_lines SET adjustment_id = $1 WHERE id = $2`, adjustmentID, lineID)
+	return err
+}
+
+// Close saves the final status of a session and who closed it
+func (r *StockTakeRepository) Close(ctx context.Context, st *entity.StockTake) error {
+	result, err := r.db.Conn(ctx).Exec(ctx, `
+		UPDATE stock_takes SET status = $1, closed_by_user_id = $2, closed_at = $3 WHERE id = $4
+	`, st.Status, st.ClosedByUserID, st.ClosedAt, st.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrStockTakeNotFound
+	}
+	return nil
+}
+
+// ListCycleCountItems ranks the variants stocked at a warehouse by the cost value of stock
+// sold or consumed by production since the given time
+func (r *StockTakeRepository) ListCycleCountItems(ctx context.Context, warehouseID int64, since time.Time) ([]entity.CycleCountItem, error) {
+	query := `
+		WITH consumption AS (
+			SELECT variant_id, SUM(-quantity_delta) AS quantity
+			FROM inventory_movements
+			WHERE warehouse_id = $1 AND created_at >= $2
+			  AND source_type IN ('sale', 'sale_cancellation', 'production_input')
+			GROUP BY variant_id
+		), last_count AS (
+			SELECT l.variant_id, MAX(l.counted_at) AS counted_at
+			FROM stock_take_lines l
+			JOIN stock_takes st ON st.id = l.stock_take_id
+			WHERE st.warehouse_id = $1 AND st.status = 'posted' AND l.counted_at IS NOT NULL
+			GROUP BY l.variant_id
+		)
+		SELECT pv.id, pv.name, pv.sku, GREATEST(COALESCE(c.quantity, 0), 0) * pv.cost_price, lc.counted_at
+		FROM inventory_levels il
+		JOIN product_variants pv ON pv.id = il.variant_id
+		LEFT JOIN consumption c ON c.variant_id = il.variant_id
+		LEFT JOIN last_count lc ON lc.variant_id = il.variant_id
+		WHERE il.warehouse_id = $1
+		ORDER BY 4 DESC, pv.name
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, warehouseID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []entity.CycleCountItem{}
+	for rows.Next() {
+		var item entity.CycleCountItem
+		if err := rows.Scan(&item.VariantID, &item.VariantName, &item.SKU, &item.ConsumptionValue, &item.LastCountedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListCycleSchedules retrieves the cycle count intervals configured for a warehouse
+func (r *StockTakeRepository) ListCycleSchedules(ctx context.Context, warehouseID int64) ([]entity.CycleCountSchedule, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT warehouse_id, abc_class, interval_days FROM cycle_count_schedules WHERE warehouse_id = $1 ORDER BY abc_class
+	`, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []entity.CycleCountSchedule{}
+	for rows.Next() {
+		var s entity.CycleCountSchedule
+		if err := rows.Scan(&s.WarehouseID, &s.ABCClass, &s.IntervalDays); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// SetCycleSchedule creates or replaces the count interval of an ABC class at a warehouse
+func (r *StockTakeRepository) SetCycleSchedule(ctx context.Context, s *entity.CycleCountSchedule) error {
+	_, err := r.db.Conn(ctx).Exec(ctx, `
+		INSERT INTO cycle_count_schedules (warehouse_id, abc_class, interval_days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (warehouse_id, abc_class) DO UPDATE SET interval_days = EXCLUDED.interval_days
+	`, s.WarehouseID, s.ABCClass, s.IntervalDays)
+	return err
+}
+
+func scanStockTake(row pgx.Row) (*entity.StockTake, error) {
+	var st entity.StockTake
+	err := row.Scan(
+		&st.ID, &st.WarehouseID, &st.WarehouseName, &st.Scope, &st.ABCClass, &st.Status, &st.Notes,
+		&st.StartedByUserID, &st.StartedAt, &st.ClosedByUserID, &st.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func scanStockTakeLine(row pgx.Row) (*entity.StockTakeLine, error) {
+	var l entity.StockTakeLine
+	err := row.Scan(
+		&l.ID, &l.StockTakeID, &l.VariantID, &l.VariantName, &l.SKU, &l.UnitCost, &l.ExpectedQuantity, &l.MovedQuantity,
+		&l.CountedQuantity, &l.CountedByUserID, &l.CountedAt, &l.AdjustmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SubscriptionInvoiceRepository implements repository.SubscriptionInvoiceRepository
+type SubscriptionInvoiceRepository struct {
+	db *DB
+}
+
+// NewSubscriptionInvoiceRepository creates a new subscription invoice repository
+func NewSubscriptionInvoiceRepository(db *DB) *SubscriptionInvoiceRepository {
+	return &SubscriptionInvoiceRepository{db: db}
+}
+
+// ListBillableDeliveries returns each delivered subscription item in [from, to] not yet on an invoice,
+// with the quantity overridden for that date if any, priced at the item's locked-in price or the variant's selling price.
+// On a date the subscription's schedule skips only the overridden items were due, so only those are billed.
+func (r *SubscriptionInvoiceRepository) ListBillableDeliveries(ctx context.Context, customerID *int64, from, to time.Time) ([]entity.BillableDelivery, error) {
+	query := `
+		SELECT cs.customer_id, sd.subscription_id, sd.id, sd.delivery_date,
+		       si.variant_id, COALESCE(o.quantity, si.quantity), COALESCE(si.unit_price, pv.selling_price),
+		       o.quantity IS NOT NULL, cs.frequency, cs.start_date, cs.end_date,
+		       cs.delivery_weekdays, cs.delivery_day_of_month
+		FROM subscription_deliveries sd
+		JOIN customer_subscriptions cs ON cs.id = sd.subscription_id
+		JOIN subscription_items si ON si.subscription_id = sd.subscription_id
+		JOIN product_variants pv ON pv.id = si.variant_id
+		LEFT JOIN subscription_date_overrides o
+		       ON o.subscription_id = sd.subscription_id AND o.delivery_date = sd.delivery_date AND o.variant_id = si.variant_id
+		WHERE sd.status = 'delivered'
+		  AND COALESCE(o.quantity, si.quantity) > 0
+		  AND sd.delivery_date BETWEEN $1::date AND $2::date
+		  AND ($3::int IS NULL OR cs.customer_id = $3)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM subscription_invoice_lines l
+		      WHERE l.delivery_id = sd.id AND l.variant_id = si.variant_id
+		  )
+		ORDER BY cs.customer_id, sd.delivery_date, sd.subscription_id, si.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, from, to, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch billable deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []entity.BillableDelivery{}
+	for rows.Next() {
+		var d entity.BillableDelivery
+		var overridden bool
+		var sub entity.Subscription
+		var weekdays []int16
+		var dayOfMonth *int16
+		if err := rows.Scan(
+			&d.CustomerID, &d.SubscriptionID, &d.DeliveryID, &d.DeliveryDate,
+			&d.VariantID, &d.Quantity, &d.UnitPrice,
+			&overridden, &sub.Frequency, &sub.StartDate, &sub.EndDate,
+			&weekdays, &dayOfMonth,
+		); err != nil {
+			return nil, err
+		}
+		setDeliverySchedule(&sub, weekdays, dayOfMonth)
+		if !overridden && !sub.IsScheduledOn(d.DeliveryDate) {
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ListInvoiceSequences returns the last invoice sequence of each customer invoiced for the period
+// starting on periodStart
+func (r *SubscriptionInvoiceRepository) ListInvoiceSequences(ctx context.Context, periodStart time.Time) (map[int64]int, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT customer_id, MAX(sequence) FROM subscription_invoices WHERE period_start = $1::date GROUP BY customer_id
+	`, periodStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sequences := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var sequence int
+		if err := rows.Scan(&id, &sequence); err != nil {
+			return nil, err
+		}
+		sequences[id] = sequence
+	}
+	return sequences, rows.Err()
+}
+
+// Create inserts an invoice with its lines. The unique customer+period+sequence constraint
+// makes concurrent billing runs safe: the loser gets ErrInvoiceExists.
+func (r *SubscriptionInvoiceRepository) Create(ctx context.Context, inv *entity.SubscriptionInvoice) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO subscription_invoices (invoice_number, sequence, customer_id, period_start, period_end, total_amount, amount_paid, status, due_date, generated_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (customer_id, period_start, sequence) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, inv.InvoiceNumber, inv.Sequence, inv.CustomerID, inv.PeriodStart, inv.PeriodEnd, inv.TotalAmount, inv.AmountPaid,
+		inv.Status, inv.DueDate, inv.GeneratedByUserID,
+	).Scan(&inv.ID, &inv.CreatedAt, &inv.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrInvoiceExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	for i := range inv.Lines {
+		line := &inv.Lines[i]
+		line.InvoiceID = inv.ID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO subscription_invoice_lines (invoice_id, subscription_id, delivery_id, delivery_date, variant_id, quantity, unit_price, line_total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, line.InvoiceID, line.SubscriptionID, line.DeliveryID, line.DeliveryDate, line.VariantID,
+			line.Quantity, line.UnitPrice, line.LineTotal,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert invoice line: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+const subscriptionInvoiceSelect = `
+	SELECT si.id, si.invoice_number, si.sequence, si.customer_id, c.name, si.period_start, si.period_end,
+	       si.total_amount, si.amount_paid, si.status, si.due_date, si.generated_by_user_id,
+	       si.created_at, si.updated_at
+	FROM subscription_invoices si
+	JOIN customers c ON c.id = si.customer_id`
+
+// GetByID retrieves an invoice with its lines
+func (r *SubscriptionInvoiceRepository) GetByID(ctx context.Context, id int64) (*entity.SubscriptionInvoice, error) {
+	return r.get(ctx, subscriptionInvoiceSelect+` WHERE si.id = $1`, id)
+}
+
+// GetByIDForUpdate retrieves an invoice with its lines and locks it for a payment
+func (r *SubscriptionInvoiceRepository) GetByIDForUpdate(ctx context.Context, id int64) (*entity.SubscriptionInvoice, error) {
+	return r.get(ctx, subscriptionInvoiceSelect+` WHERE si.id = $1 FOR UPDATE OF si`, id)
+}
+
+func (r *SubscriptionInvoiceRepository) get(ctx context.Context, query string, id int64) (*entity.SubscriptionInvoice, error) {
+	inv, err := scanSubscriptionInvoice(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT l.id, l.invoice_id, l.subscription_id, l.delivery_id, l.delivery_date, l.variant_id,
+		       pv.name, pv.unit, l.quantity, l.unit_price, l.line_total
+		FROM subscription_invoice_lines l
+		JOIN product_variants pv ON pv.id = l.variant_id
+		WHERE l.invoice_id = $1
+		ORDER BY l.delivery_date, l.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inv.Lines = []entity.SubscriptionInvoiceLine{}
+	for rows.Next() {
+		var l entity.SubscriptionInvoiceLine
+		if err := rows.Scan(
+			&l.ID, &l.InvoiceID, &l.SubscriptionID, &l.DeliveryID, &l.DeliveryDate, &l.VariantID,
+			&l.VariantName, &l.Unit, &l.Quantity, &l.UnitPrice, &l.LineTotal,
+		); err != nil {
+			return nil, err
+		}
+		inv.Lines = append(inv.Lines, l)
+	}
+	return inv, rows.Err()
+}
+
+// List retrieves invoices matching the filter, newest period first
+func (r *SubscriptionInvoiceRepository) List(ctx context.Context, filter repository.SubscriptionInvoiceFilter, offset, limit int) ([]entity.SubscriptionInvoice, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.CustomerID != nil {
+		where += fmt.Sprintf(" AND si.customer_id = $%d", argCount)
+		args = append(args, *filter.CustomerID)
+		argCount++
+	}
+	if filter.PeriodStart != nil {
+		where += fmt.Sprintf(" AND si.period_start = $%d::date", argCount)
+		args = append(args, *filter.PeriodStart)
+		argCount++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND si.status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM subscription_invoices si`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := subscriptionInvoiceSelect + where +
+		fmt.Sprintf(" ORDER BY si.period_start DESC, c.name LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invoices := []entity.SubscriptionInvoice{}
+	for rows.Next() {
+		inv, err := scanSubscriptionInvoice(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invoices = append(invoices, *inv)
+	}
+	return invoices, total, rows.Err()
+}
+
+// UpdatePayment saves the amount paid and status of an invoice
+func (r *SubscriptionInvoiceRepository) UpdatePayment(ctx context.Context, inv *entity.SubscriptionInvoice) error {
+	return r.db.Conn(ctx).QueryRow(ctx, `
+		UPDATE subscription_invoices SET amount_paid = $1, status = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+	`, inv.AmountPaid, inv.Status, inv.ID).Scan(&inv.UpdatedAt)
+}
+
+func scanSubscriptionInvoice(row pgx.Row) (*entity.SubscriptionInvoice, error) {
+	var inv entity.SubscriptionInvoice
+	err := row.Scan(
+		&inv.ID, &inv.InvoiceNumber, &inv.Sequence, &inv.CustomerID, &inv.CustomerName, &inv.PeriodStart, &inv.PeriodEnd,
+		&inv.TotalAmount, &inv.AmountPaid, &inv.Status, &inv.DueDate, &inv.GeneratedByUserID,
+		&inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
 
 	"github.com/qwikshelf/api/internal/application/dto"
@@ -16,32 +15,35 @@ import (
 
 // SubscriptionRepository is the PostgreSQL implementation for subscription data access
 type SubscriptionRepository struct {
-	pool *pgxpool.Pool
+	db *DB
 }
 
 // NewSubscriptionRepository creates a new SubscriptionRepository
 func NewSubscriptionRepository(db *DB) *SubscriptionRepository {
-	return &SubscriptionRepository{pool: db.Pool}
+	return &SubscriptionRepository{db: db}
 }
 
 // Create inserts a new subscription and its items within a transaction
 func (r *SubscriptionRepository) Create(ctx context.Context, sub *entity.Subscription) error {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	weekdays, dayOfMonth := deliveryScheduleArgs(sub)
 	query := `
 		INSERT INTO customer_subscriptions (
-			customer_id, status, frequency, start_date, end_date, delivery_instructions
+			customer_id, status, frequency, start_date, end_date, delivery_instructions, address_id,
+			delivery_weekdays, delivery_day_of_month
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 	err = tx.QueryRow(ctx, query,
 		sub.CustomerID, sub.Status, sub.Frequency,
-		sub.StartDate, sub.EndDate, sub.DeliveryInstructions,
+		sub.StartDate, sub.EndDate, sub.DeliveryInstructions, sub.AddressID,
+		weekdays, dayOfMonth,
 	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create subscription: %w", err)
@@ -50,12 +52,12 @@ func (r *SubscriptionRepository) Create(ctx context.Context, sub *entity.Subscri
 	// Insert items
 	for i := range sub.Items {
 		itemQuery := `
-			INSERT INTO subscription_items (subscription_id, variant_id, quantity)
-			VALUES ($1, $2, $3)
+			INSERT INTO subscription_items (subscription_id, variant_id, quantity, unit_price)
+			VALUES ($1, $2, $3, $4)
 			RETURNING id
 		`
 		err = tx.QueryRow(ctx, itemQuery,
-			sub.ID, sub.Items[i].VariantID, sub.Items[i].Quantity,
+			sub.ID, sub.Items[i].VariantID, sub.Items[i].Quantity, sub.Items[i].UnitPrice,
 		).Scan(&sub.Items[i].ID)
 		if err != nil {
 			return fmt.Errorf("failed to insert subscription item: %w", err)
@@ -71,13 +73,14 @@ func (r *SubscriptionRepository) GetByID(ctx context.Context, id int64) (*entity
 	query := `
 		SELECT 
 			cs.id, cs.customer_id, c.name, cs.status, cs.frequency,
-			cs.start_date, cs.end_date, cs.delivery_instructions,
+			cs.start_date, cs.end_date, cs.delivery_instructions, cs.address_id,
+			cs.delivery_weekdays, cs.delivery_day_of_month,
 			cs.created_at, cs.updated_at
 		FROM customer_subscriptions cs
 		JOIN customers c ON cs.customer_id = c.id
 		WHERE cs.id = $1
 	`
-	row := r.pool.QueryRow(ctx, query, id)
+	row := r.db.Conn(ctx).QueryRow(ctx, query, id)
 	sub, err := mapSubscriptionRow(row)
 	if err != nil {
 		return nil, err
@@ -97,7 +100,8 @@ func (r *SubscriptionRepository) ListFiltered(ctx context.Context, filter dto.Su
 	query := `
 		SELECT 
 			cs.id, cs.customer_id, c.name, cs.status, cs.frequency,
-			cs.start_date, cs.end_date, cs.delivery_instructions,
+			cs.start_date, cs.end_date, cs.delivery_instructions, cs.address_id,
+			cs.delivery_weekdays, cs.delivery_day_of_month,
 			cs.created_at, cs.updated_at
 		FROM customer_subscriptions cs
 		JOIN customers c ON cs.customer_id = c.id
@@ -127,7 +131,7 @@ func (r *SubscriptionRepository) ListFiltered(ctx context.Context, filter dto.Su
 
 	query += ` ORDER BY cs.created_at DESC`
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
@@ -158,14 +162,15 @@ func (r *SubscriptionRepository) ListByCustomer(ctx context.Context, customerID
 	query := `
 		SELECT 
 			cs.id, cs.customer_id, c.name, cs.status, cs.frequency,
-			cs.start_date, cs.end_date, cs.delivery_instructions,
+			cs.start_date, cs.end_date, cs.delivery_instructions, cs.address_id,
+			cs.delivery_weekdays, cs.delivery_day_of_month,
 			cs.created_at, cs.updated_at
 		FROM customer_subscriptions cs
 		JOIN customers c ON cs.customer_id = c.id
 		WHERE cs.customer_id = $1
 		ORDER BY cs.created_at DESC
 	`
-	rows, err := r.pool.Query(ctx, query, customerID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, customerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
@@ -193,22 +198,25 @@ func (r *SubscriptionRepository) ListByCustomer(ctx context.Context, customerID
 
 // Update replaces the subscription header fields and its items
 func (r *SubscriptionRepository) Update(ctx context.Context, sub *entity.Subscription) error {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	weekdays, dayOfMonth := deliveryScheduleArgs(sub)
 	updateQuery := `
 		UPDATE customer_subscriptions
 		SET frequency = $1, start_date = $2, end_date = $3,
-		    delivery_instructions = $4, updated_at = NOW()
-		WHERE id = $5
+		    delivery_instructions = $4, address_id = $5,
+		    delivery_weekdays = $6, delivery_day_of_month = $7, updated_at = NOW()
+		WHERE id = $8
 		RETURNING updated_at
 	`
 	err = tx.QueryRow(ctx, updateQuery,
 		sub.Frequency, sub.StartDate, sub.EndDate,
-		sub.DeliveryInstructions, sub.ID,
+		sub.DeliveryInstructions, sub.AddressID,
+		weekdays, dayOfMonth, sub.ID,
 	).Scan(&sub.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -224,12 +232,12 @@ func (r *SubscriptionRepository) Update(ctx context.Context, sub *entity.Subscri
 
 	for i := range sub.Items {
 		itemQuery := `
-			INSERT INTO subscription_items (subscription_id, variant_id, quantity)
-			VALUES ($1, $2, $3)
+			INSERT INTO subscription_items (subscription_id, variant_id, quantity, unit_price)
+			VALUES ($1, $2, $3, $4)
 			RETURNING id
 		`
 		err = tx.QueryRow(ctx, itemQuery,
-			sub.ID, sub.Items[i].VariantID, sub.Items[i].Quantity,
+			sub.ID, sub.Items[i].VariantID, sub.Items[i].Quantity, sub.Items[i].UnitPrice,
 		).Scan(&sub.Items[i].ID)
 		if err != nil {
 			return fmt.Errorf("failed to insert subscription item: %w", err)
@@ -242,7 +250,7 @@ func (r *SubscriptionRepository) Update(ctx context.Context, sub *entity.Subscri
 
 // UpdateStatus changes only the status of a subscription
 func (r *SubscriptionRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
-	tag, err := r.pool.Exec(ctx,
+	tag, err := r.db.Conn(ctx).Exec(ctx,
 		"UPDATE customer_subscriptions SET status = $1, updated_at = NOW() WHERE id = $2",
 		status, id,
 	)
@@ -257,7 +265,7 @@ func (r *SubscriptionRepository) UpdateStatus(ctx context.Context, id int64, sta
 
 // Delete permanently removes a subscription and its cascade-deleted items
 func (r *SubscriptionRepository) Delete(ctx context.Context, id int64) error {
-	tag, err := r.pool.Exec(ctx, "DELETE FROM customer_subscriptions WHERE id = $1", id)
+	tag, err := r.db.Conn(ctx).Exec(ctx, "DELETE FROM customer_subscriptions WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
@@ -272,14 +280,14 @@ func (r *SubscriptionRepository) fetchItems(ctx context.Context, subscriptionID
 	query := `
 		SELECT 
 			si.id, si.subscription_id, si.variant_id,
-			pv.name, pf.name, pv.unit, si.quantity
+			pv.name, pf.name, pv.unit, si.quantity, si.unit_price
 		FROM subscription_items si
 		JOIN product_variants pv ON si.variant_id = pv.id
 		JOIN product_families pf ON pv.family_id = pf.id
 		WHERE si.subscription_id = $1
 		ORDER BY si.id ASC
 	`
-	rows, err := r.pool.Query(ctx, query, subscriptionID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, subscriptionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subscription items: %w", err)
 	}
@@ -293,7 +301,7 @@ func (r *SubscriptionRepository) fetchItems(ctx context.Context, subscriptionID
 
 		err := rows.Scan(
 			&item.ID, &item.SubscriptionID, &item.VariantID,
-			&variantName, &familyName, &unit, &qty,
+			&variantName, &familyName, &unit, &qty, &item.UnitPrice,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan subscription item: %w", err)
@@ -321,10 +329,13 @@ type subscriptionRow interface {
 func mapSubscriptionRow(row subscriptionRow) (*entity.Subscription, error) {
 	var sub entity.Subscription
 	var customerName string
+	var weekdays []int16
+	var dayOfMonth *int16
 
 	err := row.Scan(
 		&sub.ID, &sub.CustomerID, &customerName, &sub.Status, &sub.Frequency,
-		&sub.StartDate, &sub.EndDate, &sub.DeliveryInstructions,
+		&sub.StartDate, &sub.EndDate, &sub.DeliveryInstructions, &sub.AddressID,
+		&weekdays, &dayOfMonth,
 		&sub.CreatedAt, &sub.UpdatedAt,
 	)
 	if err != nil {
@@ -338,9 +349,37 @@ func mapSubscriptionRow(row subscriptionRow) (*entity.Subscription, error) {
 		ID:   sub.CustomerID,
 		Name: customerName,
 	}
+	setDeliverySchedule(&sub, weekdays, dayOfMonth)
 	return &sub, nil
 }
 
+// setDeliverySchedule copies the stored delivery days onto the subscription
+func setDeliverySchedule(sub *entity.Subscription, weekdays []int16, dayOfMonth *int16) {
+	sub.DeliveryWeekdays = nil
+	for _, wd := range weekdays {
+		sub.DeliveryWeekdays = append(sub.DeliveryWeekdays, time.Weekday(wd))
+	}
+	sub.DeliveryDayOfMonth = nil
+	if dayOfMonth != nil {
+		dom := int(*dayOfMonth)
+		sub.DeliveryDayOfMonth = &dom
+	}
+}
+
+// deliveryScheduleArgs converts the subscription's delivery days into column values
+func deliveryScheduleArgs(sub *entity.Subscription) ([]int16, *int16) {
+	var weekdays []int16
+	for _, wd := range sub.DeliveryWeekdays {
+		weekdays = append(weekdays, int16(wd))
+	}
+	var dayOfMonth *int16
+	if sub.DeliveryDayOfMonth != nil {
+		dom := int16(*sub.DeliveryDayOfMonth)
+		dayOfMonth = &dom
+	}
+	return weekdays, dayOfMonth
+}
+
 // ==========================================
 // Deliveries
 // ==========================================
@@ -359,7 +398,7 @@ func (r *SubscriptionRepository) RecordDelivery(ctx context.Context, d *entity.S
 			recorded_at = NOW()
 		RETURNING id, recorded_at
 	`
-	err := r.pool.QueryRow(ctx, query,
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
 		d.SubscriptionID, d.DeliveryDate, d.Status, d.Notes, d.RecordedBy,
 	).Scan(&d.ID, &d.RecordedAt)
 	if err != nil {
@@ -376,7 +415,7 @@ func (r *SubscriptionRepository) GetDeliveries(ctx context.Context, subscription
 		WHERE subscription_id = $1
 		ORDER BY delivery_date DESC
 	`
-	rows, err := r.pool.Query(ctx, query, subscriptionID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, subscriptionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch deliveries: %w", err)
 	}
@@ -397,12 +436,26 @@ func (r *SubscriptionRepository) GetDeliveries(ctx context.Context, subscription
 	return deliveries, nil
 }
 
-// GetDailyRoster returns all active subscriptions eligible for delivery on the given date, alongside their current delivery log
+// GetDailyRoster returns the active subscriptions due for delivery on the given date, alongside their current delivery log.
+// Pause windows, weekday and day-of-month schedules and alternate days counted from the start date are honoured,
+// and item quantities reflect any one-off overrides for the date. Subscriptions with a delivery already recorded
+// for the date are always listed.
 func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string) ([]*entity.DailyRosterItem, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid roster date: %w", domainErrors.ErrInvalidInput)
+	}
+
+	overrides, err := r.listOverridesOn(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT 
 			cs.id, cs.customer_id, c.name, cs.status, cs.frequency,
-			cs.start_date, cs.end_date, cs.delivery_instructions,
+			cs.start_date, cs.end_date, cs.delivery_instructions, cs.address_id,
+			cs.delivery_weekdays, cs.delivery_day_of_month,
 			cs.created_at, cs.updated_at,
 			sd.id, sd.status, sd.notes, sd.recorded_by, sd.recorded_at
 		FROM customer_subscriptions cs
@@ -411,9 +464,13 @@ func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string
 		WHERE cs.status = 'active'
 		  AND cs.start_date <= $1::date
 		  AND (cs.end_date IS NULL OR cs.end_date >= $1::date)
+		  AND (sd.id IS NOT NULL OR NOT EXISTS (
+		      SELECT 1 FROM subscription_pauses p
+		      WHERE p.subscription_id = cs.id AND $1::date BETWEEN p.start_date AND p.end_date
+		  ))
 		ORDER BY c.name ASC
 	`
-	rows, err := r.pool.Query(ctx, query, date)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch daily roster: %w", err)
 	}
@@ -423,6 +480,8 @@ func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string
 	for rows.Next() {
 		var sub entity.Subscription
 		var customerName string
+		var weekdays []int16
+		var dayOfMonth *int16
 		var sdID *int64
 		var sdStatus *string
 		var sdNotes *string
@@ -431,13 +490,20 @@ func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string
 
 		err := rows.Scan(
 			&sub.ID, &sub.CustomerID, &customerName, &sub.Status, &sub.Frequency,
-			&sub.StartDate, &sub.EndDate, &sub.DeliveryInstructions,
+			&sub.StartDate, &sub.EndDate, &sub.DeliveryInstructions, &sub.AddressID,
+			&weekdays, &dayOfMonth,
 			&sub.CreatedAt, &sub.UpdatedAt,
 			&sdID, &sdStatus, &sdNotes, &sdRecordedBy, &sdRecordedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		setDeliverySchedule(&sub, weekdays, dayOfMonth)
+
+		// A one-off override makes the date a delivery day even when the schedule skips it
+		if sdID == nil && !sub.IsScheduledOn(day) && len(overrides[sub.ID]) == 0 {
+			continue
+		}
 
 		sub.Customer = &entity.Customer{
 			ID:   sub.CustomerID,
@@ -453,6 +519,7 @@ func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string
 			item.Delivery = &entity.SubscriptionDelivery{
 				ID:             *sdID,
 				SubscriptionID: sub.ID,
+				DeliveryDate:   day,
 				Status:         entity.DeliveryStatus(*sdStatus),
 				Notes:          sdNotes,
 				RecordedBy:     sdRecordedBy,
@@ -462,15 +529,166 @@ func (r *SubscriptionRepository) GetDailyRoster(ctx context.Context, date string
 
 		roster = append(roster, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("roster rows error: %w", err)
+	}
 
-	// Fetch items for each subscription (n+1 problem mitigated if we load items, but this might be thousands. For now, looping to keep interface small, or just omit if UI doesn't need to show them in the daily roster right away. Roster mainly needs name and maybe sub items. We will fetch items per subscription)
-	// We'll hydrate the items
+	// Hydrate items with the quantities due on the date, dropping subscriptions overridden to nothing
+	filtered := roster[:0]
 	for _, item := range roster {
 		items, err := r.fetchItems(ctx, item.Subscription.ID)
-		if err == nil {
-			item.Subscription.Items = items
+		if err != nil {
+			return nil, err
+		}
+		item.Subscription.Items = items
+		item.Subscription.Items = item.Subscription.ItemsFor(day, overrides[item.Subscription.ID])
+		if len(item.Subscription.Items) == 0 && item.Delivery == nil {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+
+	return filtered, nil
+}
+
+// ==========================================
+// Pauses & Date Overrides
+// ==========================================
+
+// CreatePause adds a vacation hold to a subscription
+func (r *SubscriptionRepository) CreatePause(ctx context.Context, p *entity.SubscriptionPause) error {
+	query := `
+		INSERT INTO subscription_pauses (subscription_id, start_date, end_date, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
+		p.SubscriptionID, p.StartDate, p.EndDate, p.Reason, p.CreatedBy,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription pause: %w", err)
+	}
+	return nil
+}
+
+// ListPauses returns a subscription's pause windows, most recent first
+func (r *SubscriptionRepository) ListPauses(ctx context.Context, subscriptionID int64) ([]*entity.SubscriptionPause, error) {
+	query := `
+		SELECT id, subscription_id, start_date, end_date, reason, created_by, created_at
+		FROM subscription_pauses
+		WHERE subscription_id = $1
+		ORDER BY start_date DESC
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subscription pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []*entity.SubscriptionPause
+	for rows.Next() {
+		var p entity.SubscriptionPause
+		if err := rows.Scan(&p.ID, &p.SubscriptionID, &p.StartDate, &p.EndDate, &p.Reason, &p.CreatedBy, &p.CreatedAt); err != nil {
+			return nil, err
 		}
+		pauses = append(pauses, &p)
 	}
+	return pauses, rows.Err()
+}
+
+// DeletePause removes a pause window from a subscription
+func (r *SubscriptionRepository) DeletePause(ctx context.Context, subscriptionID, pauseID int64) error {
+	tag, err := r.db.Conn(ctx).Exec(ctx, "DELETE FROM subscription_pauses WHERE id = $1 AND subscription_id = $2", pauseID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription pause: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domainErrors.ErrNotFound
+	}
+	return nil
+}
+
+// SetOverride saves the one-off quantity of an item for a date, replacing any earlier override
+func (r *SubscriptionRepository) SetOverride(ctx context.Context, o *entity.SubscriptionDateOverride) error {
+	query := `
+		INSERT INTO subscription_date_overrides (subscription_id, delivery_date, variant_id, quantity, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (subscription_id, delivery_date, variant_id)
+		DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			notes = EXCLUDED.notes,
+			created_by = EXCLUDED.created_by,
+			created_at = NOW()
+		RETURNING id, created_at
+	`
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
+		o.SubscriptionID, o.DeliveryDate, o.VariantID, o.Quantity, o.Notes, o.CreatedBy,
+	).Scan(&o.ID, &o.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription override: %w", err)
+	}
+	return nil
+}
 
-	return roster, nil
+// ListOverrides returns a subscription's date overrides on or after the given date
+func (r *SubscriptionRepository) ListOverrides(ctx context.Context, subscriptionID int64, from time.Time) ([]*entity.SubscriptionDateOverride, error) {
+	query := `
+		SELECT id, subscription_id, delivery_date, variant_id, quantity, notes, created_by, created_at
+		FROM subscription_date_overrides
+		WHERE subscription_id = $1 AND delivery_date >= $2::date
+		ORDER BY delivery_date ASC, variant_id ASC
+	`
+	return r.queryOverrides(ctx, query, subscriptionID, from)
+}
+
+// DeleteOverride removes a date override from a subscription
+func (r *SubscriptionRepository) DeleteOverride(ctx context.Context, subscriptionID, overrideID int64) error {
+	tag, err := r.db.Conn(ctx).Exec(ctx, "DELETE FROM subscription_date_overrides WHERE id = $1 AND subscription_id = $2", overrideID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domainErrors.ErrNotFound
+	}
+	return nil
+}
+
+// listOverridesOn returns every override for a date, keyed by subscription
+func (r *SubscriptionRepository) listOverridesOn(ctx context.Context, date time.Time) (map[int64][]entity.SubscriptionDateOverride, error) {
+	query := `
+		SELECT id, subscription_id, delivery_date, variant_id, quantity, notes, created_by, created_at
+		FROM subscription_date_overrides
+		WHERE delivery_date = $1::date
+	`
+	overrides, err := r.queryOverrides(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+
+	bySubscription := make(map[int64][]entity.SubscriptionDateOverride)
+	for _, o := range overrides {
+		bySubscription[o.SubscriptionID] = append(bySubscription[o.SubscriptionID], *o)
+	}
+	return bySubscription, nil
+}
+
+func (r *SubscriptionRepository) queryOverrides(ctx context.Context, query string, args ...any) ([]*entity.SubscriptionDateOverride, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subscription overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*entity.SubscriptionDateOverride
+	for rows.Next() {
+		var o entity.SubscriptionDateOverride
+		if err := rows.Scan(
+			&o.ID, &o.SubscriptionID, &o.DeliveryDate, &o.VariantID, &o.Quantity,
+			&o.Notes, &o.CreatedBy, &o.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, &o)
+	}
+	return overrides, rows.Err()
 }
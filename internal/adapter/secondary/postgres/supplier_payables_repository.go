@@ -0,0 +1,373 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SupplierPayablesRepository implements repository.SupplierPayablesRepository
+type SupplierPayablesRepository struct {
+	db *DB
+}
+
+// NewSupplierPayablesRepository creates a new supplier payables repository
+func NewSupplierPayablesRepository(db *DB) *SupplierPayablesRepository {
+	return &SupplierPayablesRepository{db: db}
+}
+
+// LockSupplier locks the supplier row so concurrent bills and payments for it are posted one at a time
+func (r *SupplierPayablesRepository) LockSupplier(ctx context.Context, supplierID int64) error {
+	var id int64
+	err := r.db.Conn(ctx).QueryRow(ctx, `SELECT id FROM suppliers WHERE id = $1 FOR UPDATE`, supplierID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrSupplierNotFound
+	}
+	return err
+}
+
+// ListUnbilledReceiptLines returns each goods receipt line of a purchase order with accepted stock
+// that is not on a bill yet, priced at the purchase order line's unit cost
+func (r *SupplierPayablesRepository) ListUnbilledReceiptLines(ctx context.Context, procurementID int64) ([]entity.SupplierBillLine, error) {
+	query := `
+		SELECT gri.id, gri.variant_id, pv.name, pv.unit, gr.received_at, gri.quantity_accepted, pi.unit_cost
+		FROM goods_receipt_items gri
+		JOIN goods_receipts gr ON gr.id = gri.receipt_id
+		JOIN procurement_items pi ON pi.id = gri.procurement_item_id
+		JOIN product_variants pv ON pv.id = gri.variant_id
+		WHERE gr.procurement_id = $1
+		  AND gri.quantity_accepted > 0
+		  AND NOT EXISTS (SELECT 1 FROM supplier_bill_lines l WHERE l.goods_receipt_item_id = gri.id)
+		ORDER BY gr.received_at, gri.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, procurementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch unbilled receipt lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []entity.SupplierBillLine{}
+	for rows.Next() {
+		var l entity.SupplierBillLine
+		var receiptItemID int64
+		if err := rows.Scan(&receiptItemID, &l.VariantID, &l.VariantName, &l.Unit, &l.LineDate, &l.Quantity, &l.UnitPrice); err != nil {
+			return nil, err
+		}
+		l.GoodsReceiptItemID = &receiptItemID
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// ListUnbilledCollections returns each of a supplier's collections dated within [from, to) that is
+// not on a bill yet, priced at its rate chart rate, else the agreed cost for the variant, or its cost price
+func (r *SupplierPayablesRepository) ListUnbilledCollections(ctx context.Context, supplierID int64, from, to time.Time) ([]entity.SupplierBillLine, error) {
+	query := `
+		SELECT c.id, c.variant_id, pv.name, pv.unit, c.collected_at, c.weight,
+		       COALESCE(c.rate, NULLIF(sv.agreed_cost, 0), pv.cost_price)
+		FROM collections c
+		JOIN product_variants pv ON pv.id = c.variant_id
+		LEFT JOIN supplier_variants sv ON sv.supplier_id = c.supplier_id AND sv.variant_id = c.variant_id
+		WHERE c.supplier_id = $1 AND c.collected_at >= $2 AND c.collected_at < $3
+		  AND c.weight > 0
+		  AND NOT EXISTS (SELECT 1 FROM supplier_bill_lines l WHERE l.collection_id = c.id)
+		ORDER BY c.collected_at, c.id
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, supplierID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch unbilled collections: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []entity.SupplierBillLine{}
+	for rows.Next() {
+		var l entity.SupplierBillLine
+		var collectionID int64
+		if err := rows.Scan(&collectionID, &l.VariantID, &l.VariantName, &l.Unit, &l.LineDate, &l.Quantity, &l.UnitPrice); err != nil {
+			return nil, err
+		}
+		l.CollectionID = &collectionID
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// CreateBill inserts a bill with its lines
+func (r *SupplierPayablesRepository) CreateBill(ctx context.Context, bill *entity.SupplierBill) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO supplier_bills (supplier_id, source, procurement_id, period_start, period_end, reference,
+		                            bill_date, due_date, amount, amount_paid, status, notes, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, NULLIF($12, ''), $13)
+		RETURNING id, created_at, updated_at
+	`, bill.SupplierID, bill.Source, bill.ProcurementID, bill.PeriodStart, bill.PeriodEnd, bill.Reference,
+		bill.BillDate, bill.DueDate, bill.Amount, bill.AmountPaid, bill.Status, bill.Notes, bill.CreatedByUserID,
+	).Scan(&bill.ID, &bill.CreatedAt, &bill.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create supplier bill: %w", err)
+	}
+
+	for i := range bill.Lines {
+		line := &bill.Lines[i]
+		line.BillID = bill.ID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO supplier_bill_lines (bill_id, goods_receipt_item_id, collection_id, variant_id, line_date, quantity, unit_price, line_total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, line.BillID, line.GoodsReceiptItemID, line.CollectionID, line.VariantID, line.LineDate,
+			line.Quantity, line.UnitPrice, line.LineTotal,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert supplier bill line: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+const supplierBillSelect = `
+	SELECT b.id, b.supplier_id, s.name, b.source, b.procurement_id, b.period_start, b.period_end,
+	       COALESCE(b.reference, ''), b.bill_date, b.due_date, b.amount, b.amount_paid, b.status,
+	       COALESCE(b.notes, ''), b.created_by_user_id, b.created_at, b.updated_at
+	FROM supplier_bills b
+	JOIN suppliers s ON s.id = b.supplier_id`
+
+// GetBillByID retrieves a bill with its lines
+func (r *SupplierPayablesRepository) GetBillByID(ctx context.Context, id int64) (*entity.SupplierBill, error) {
+	return r.getBill(ctx, supplierBillSelect+` WHERE b.id = $1`, id)
+}
+
+// GetBillByIDForUpdate retrieves a bill with its lines and locks it for a payment
+func (r *SupplierPayablesRepository) GetBillByIDForUpdate(ctx context.Context, id int64) (*entity.SupplierBill, error) {
+	return r.getBill(ctx, supplierBillSelect+` WHERE b.id = $1 FOR UPDATE OF b`, id)
+}
+
+func (r *SupplierPayablesRepository) getBill(ctx context.Context, query string, id int64) (*entity.SupplierBill, error) {
+	bill, err := scanSupplierBill(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrSupplierBillNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT l.id, l.bill_id, l.goods_receipt_item_id, l.collection_id, l.variant_id, pv.name, pv.unit,
+		       l.line_date, l.quantity, l.unit_price, l.line_total
+		FROM supplier_bill_lines l
+		JOIN product_variants pv ON pv.id = l.variant_id
+		WHERE l.bill_id = $1
+		ORDER BY l.line_date, l.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bill.Lines = []entity.SupplierBillLine{}
+	for rows.Next() {
+		var l entity.SupplierBillLine
+		if err := rows.Scan(
+			&l.ID, &l.BillID, &l.GoodsReceiptItemID, &l.CollectionID, &l.VariantID, &l.VariantName, &l.Unit,
+			&l.LineDate, &l.Quantity, &l.UnitPrice, &l.LineTotal,
+		); err != nil {
+			return nil, err
+		}
+		bill.Lines = append(bill.Lines, l)
+	}
+	return bill, rows.Err()
+}
+
+// ListBills retrieves bills matching the filter, newest first
+func (r *SupplierPayablesRepository) ListBills(ctx context.Context, filter repository.SupplierBillFilter, offset, limit int) ([]entity.SupplierBill, int64, error) {
+	where := " WHERE 1=1"
+	args := []any{}
+	argCount := 1
+
+	if filter.SupplierID != nil {
+		where += fmt.Sprintf(" AND b.supplier_id = $%d", argCount)
+		args = append(args, *filter.SupplierID)
+		argCount++
+	}
+	if filter.ProcurementID != nil {
+		where += fmt.Sprintf(" AND b.procurement_id = $%d", argCount)
+		args = append(args, *filter.ProcurementID)
+		argCount++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND b.status = $%d", argCount)
+		args = append(args, *filter.Status)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.Conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM supplier_bills b`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := supplierBillSelect + where +
+		fmt.Sprintf(" ORDER BY b.bill_date DESC, b.id DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	bills := []entity.SupplierBill{}
+	for rows.Next() {
+		bill, err := scanSupplierBill(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		bills = append(bills, *bill)
+	}
+	return bills, total, rows.Err()
+}
+
+// UpdateBillPayment saves the amount paid and status of a bill
+func (r *SupplierPayablesRepository) UpdateBillPayment(ctx context.Context, bill *entity.SupplierBill) error {
+	return r.db.Conn(ctx).QueryRow(ctx, `
+		UPDATE supplier_bills SET amount_paid = $1, status = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+	`, bill.AmountPaid, bill.Status, bill.ID).Scan(&bill.UpdatedAt)
+}
+
+func scanSupplierBill(row pgx.Row) (*entity.SupplierBill, error) {
+	var b entity.SupplierBill
+	err := row.Scan(
+		&b.ID, &b.SupplierID, &b.SupplierName, &b.Source, &b.ProcurementID, &b.PeriodStart, &b.PeriodEnd,
+		&b.Reference, &b.BillDate, &b.DueDate, &b.Amount, &b.AmountPaid, &b.Status,
+		&b.Notes, &b.CreatedByUserID, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// CreatePayment records a payment made to a supplier
+func (r *SupplierPayablesRepository) CreatePayment(ctx context.Context, p *entity.SupplierPayment) error {
+	query := `
+		INSERT INTO supplier_payments (supplier_id, bill_id, amount, payment_method, reference, notes, paid_at, recorded_by_user_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		p.SupplierID, p.BillID, p.Amount, p.PaymentMethod, p.Reference, p.Notes, p.PaidAt, p.RecordedByUserID,
+	).Scan(&p.ID, &p.CreatedAt)
+}
+
+// AddEntry posts an entry to a supplier's ledger
+func (r *SupplierPayablesRepository) AddEntry(ctx context.Context, e *entity.SupplierLedgerEntry) error {
+	query := `
+		INSERT INTO supplier_ledger_entries (supplier_id, entry_type, bill_id, payment_id, debit, credit, description, entry_date, due_date, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), COALESCE($8, NOW()), $9, $10)
+		RETURNING id, entry_date, created_at
+	`
+	var entryDate *time.Time
+	if !e.EntryDate.IsZero() {
+		entryDate = &e.EntryDate
+	}
+	return r.db.Conn(ctx).QueryRow(ctx, query,
+		e.SupplierID, e.EntryType, e.BillID, e.PaymentID, e.Debit, e.Credit, e.Description, entryDate, e.DueDate, e.CreatedByUserID,
+	).Scan(&e.ID, &e.EntryDate, &e.CreatedAt)
+}
+
+// GetBalance returns the amount owed to a supplier from entries dated before the given time
+func (r *SupplierPayablesRepository) GetBalance(ctx context.Context, supplierID int64, before time.Time) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT COALESCE(SUM(credit - debit), 0) FROM supplier_ledger_entries
+		WHERE supplier_id = $1 AND entry_date < $2
+	`, supplierID, before).Scan(&balance)
+	return balance, err
+}
+
+// ListEntries retrieves a supplier's ledger entries dated within [from, to)
+func (r *SupplierPayablesRepository) ListEntries(ctx context.Context, supplierID int64, from, to time.Time) ([]entity.SupplierLedgerEntry, error) {
+	query := supplierLedgerEntrySelect + `
+		WHERE supplier_id = $1 AND entry_date >= $2 AND entry_date < $3
+		ORDER BY entry_date, id
+	`
+	return r.queryEntries(ctx, query, supplierID, from, to)
+}
+
+// ListEntriesUpTo retrieves ledger entries dated before asOf, optionally for one supplier,
+// ordered by supplier and date
+func (r *SupplierPayablesRepository) ListEntriesUpTo(ctx context.Context, supplierID *int64, asOf time.Time) ([]entity.SupplierLedgerEntry, error) {
+	query := supplierLedgerEntrySelect + `
+		WHERE entry_date < $1 AND ($2::int IS NULL OR supplier_id = $2)
+		ORDER BY supplier_id, entry_date, id
+	`
+	return r.queryEntries(ctx, query, asOf, supplierID)
+}
+
+// ListBalances returns every supplier with a non-zero balance from entries dated before asOf
+func (r *SupplierPayablesRepository) ListBalances(ctx context.Context, asOf time.Time) ([]entity.SupplierAging, error) {
+	query := `
+		SELECT s.id, s.name, SUM(le.credit - le.debit) AS balance
+		FROM supplier_ledger_entries le
+		JOIN suppliers s ON s.id = le.supplier_id
+		WHERE le.entry_date < $1
+		GROUP BY s.id, s.name
+		HAVING SUM(le.credit - le.debit) <> 0
+		ORDER BY balance DESC
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []entity.SupplierAging{}
+	for rows.Next() {
+		var a entity.SupplierAging
+		if err := rows.Scan(&a.SupplierID, &a.SupplierName, &a.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, a)
+	}
+	return balances, rows.Err()
+}
+
+const supplierLedgerEntrySelect = `
+	SELECT id, supplier_id, entry_type, bill_id, payment_id, debit, credit, COALESCE(description, ''),
+	       entry_date, due_date, created_by_user_id, created_at
+	FROM supplier_ledger_entries`
+
+func (r *SupplierPayablesRepository) queryEntries(ctx context.Context, query string, args ...any) ([]entity.SupplierLedgerEntry, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []entity.SupplierLedgerEntry{}
+	for rows.Next() {
+		var e entity.SupplierLedgerEntry
+		if err := rows.Scan(
+			&e.ID, &e.SupplierID, &e.EntryType, &e.BillID, &e.PaymentID, &e.Debit, &e.Credit, &e.Description,
+			&e.EntryDate, &e.DueDate, &e.CreatedByUserID, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
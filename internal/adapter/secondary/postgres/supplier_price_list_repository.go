@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+)
+
+// SupplierPriceListRepository implements repository.SupplierPriceListRepository
+type SupplierPriceListRepository struct {
+	db *DB
+}
+
+// NewSupplierPriceListRepository creates a new supplier price list repository
+func NewSupplierPriceListRepository(db *DB) *SupplierPriceListRepository {
+	return &SupplierPriceListRepository{db: db}
+}
+
+const priceListColumns = `
+	pl.id, pl.supplier_id, s.name, pl.variant_id, pv.name,
+	pl.valid_from, pl.valid_to, COALESCE(pl.notes, ''), pl.created_by_user_id, pl.created_at
+`
+
+const priceListFrom = `
+	FROM supplier_price_lists pl
+	JOIN suppliers s ON s.id = pl.supplier_id
+	JOIN product_variants pv ON pv.id = pl.variant_id
+`
+
+func scanPriceList(row pgx.Row, l *entity.SupplierPriceList) error {
+	return row.Scan(
+		&l.ID, &l.SupplierID, &l.SupplierName, &l.VariantID, &l.VariantName,
+		&l.ValidFrom, &l.ValidTo, &l.Notes, &l.CreatedByUserID, &l.CreatedAt,
+	)
+}
+
+// exclusionViolation is the SQLSTATE raised when a new list overlaps another for the same variant
+const exclusionViolation = "23P01"
+
+// Create inserts a price list with its tiers
+func (r *SupplierPriceListRepository) Create(ctx context.Context, list *entity.SupplierPriceList) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO supplier_price_lists (supplier_id, variant_id, valid_from, valid_to, notes, created_by_user_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id, created_at
+	`, list.SupplierID, list.VariantID, list.ValidFrom, list.ValidTo, list.Notes, list.CreatedByUserID,
+	).Scan(&list.ID, &list.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return domainErrors.ErrPriceListOverlap
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create price list: %w", err)
+	}
+
+	for i := range list.Tiers {
+		t := &list.Tiers[i]
+		err := tx.QueryRow(ctx, `
+			INSERT INTO supplier_price_tiers (price_list_id, min_quantity, unit_price)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, list.ID, t.MinQuantity, t.UnitPrice).Scan(&t.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add price tier: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a price list with its tiers
+func (r *SupplierPriceListRepository) GetByID(ctx context.Context, id int64) (*entity.SupplierPriceList, error) {
+	list := &entity.SupplierPriceList{}
+	err := scanPriceList(r.db.Conn(ctx).QueryRow(ctx, `SELECT `+priceListColumns+priceListFrom+` WHERE pl.id = $1`, id), list)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrPriceListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadTiers(ctx, []*entity.SupplierPriceList{list}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// List retrieves a supplier's price lists, of one variant when variantID is set, latest first
+func (r *SupplierPriceListRepository) List(ctx context.Context, supplierID int64, variantID *int64) ([]entity.SupplierPriceList, error) {
+	query := `SELECT ` + priceListColumns + priceListFrom + `
+		WHERE pl.supplier_id = $1 AND ($2::int IS NULL OR pl.variant_id = $2)
+		ORDER BY pv.name, pl.valid_from DESC, pl.id DESC
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, supplierID, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []entity.SupplierPriceList{}
+	for rows.Next() {
+		var l entity.SupplierPriceList
+		if err := scanPriceList(rows, &l); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*entity.SupplierPriceList, len(lists))
+	for i := range lists {
+		ptrs[i] = &lists[i]
+	}
+	if err := r.loadTiers(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// FindEffective returns the list in force for the supplier and variant on the date
+func (r *SupplierPriceListRepository) FindEffective(ctx context.Context, supplierID, variantID int64, on time.Time) (*entity.SupplierPriceList, error) {
+	query := `SELECT ` + priceListColumns + priceListFrom + `
+		WHERE pl.supplier_id = $1 AND pl.variant_id = $2
+		  AND pl.valid_from <= $3::date AND (pl.valid_to IS NULL OR pl.valid_to >= $3::date)
+		ORDER BY pl.valid_from DESC, pl.id DESC
+		LIMIT 1
+	`
+	list := &entity.SupplierPriceList{}
+	err := scanPriceList(r.db.Conn(ctx).QueryRow(ctx, query, supplierID, variantID, on), list)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domainErrors.ErrPriceListNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price list: %w", err)
+	}
+	if err := r.loadTiers(ctx, []*entity.SupplierPriceList{list}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SetValidTo sets the last day a price list is in force
+func (r *SupplierPriceListRepository) SetValidTo(ctx context.Context, id int64, validTo time.Time) error {
+	tag, err := r.db.Conn(ctx).Exec(ctx, `UPDATE supplier_price_lists SET valid_to = $2 WHERE id = $1`, id, validTo)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domainErrors.ErrPriceListNotFound
+	}
+	return nil
+}
+
+// LockSupplierVariant locks the supplier's link to the variant
+func (r *SupplierPriceListRepository) LockSupplierVariant(ctx context.Context, supplierID, variantID int64) error {
+	var id int64
+	err := r.db.Conn(ctx).QueryRow(ctx, `
+		SELECT id FROM supplier_variants WHERE supplier_id = $1 AND variant_id = $2 FOR UPDATE
+	`, supplierID, variantID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainErrors.ErrVariantNotSupplied
+	}
+	return err
+}
+
+// SetAgreedCost updates the supplier's agreed cost for the variant
+func (r *SupplierPriceListRepository) SetAgreedCost(ctx context.Context, supplierID, variantID int64, cost decimal.Decimal) error {
+	_, err := r.db.Conn(ctx).Exec(ctx, `
+		UPDATE supplier_variants SET agreed_cost = $3 WHERE supplier_id = $1 AND variant_id = $2
+	`, supplierID, variantID, cost)
+	return err
+}
+
+func (r *SupplierPriceListRepository) loadTiers(ctx context.Context, lists []*entity.SupplierPriceList) error {
+	if len(lists) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(lists))
+	byID := make(map[int64]*entity.SupplierPriceList, len(lists))
+	for i, l := range lists {
+		ids[i] = l.ID
+		l.Tiers = []entity.SupplierPriceTier{}
+		byID[l.ID] = l
+	}
+
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT id, price_list_id, min_quantity, unit_price
+		FROM supplier_price_tiers
+		WHERE price_list_id = ANY($1)
+		ORDER BY price_list_id, min_quantity
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load price tiers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t entity.SupplierPriceTier
+		var listID int64
+		if err := rows.Scan(&t.ID, &listID, &t.MinQuantity, &t.UnitPrice); err != nil {
+			return err
+		}
+		byID[listID].Tiers = append(byID[listID].Tiers, t)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// SupplierScorecardRepository implements repository.SupplierScorecardRepository
+type SupplierScorecardRepository struct {
+	db *DB
+}
+
+// NewSupplierScorecardRepository creates a new supplier scorecard repository
+func NewSupplierScorecardRepository(db *DB) *SupplierScorecardRepository {
+	return &SupplierScorecardRepository{db: db}
+}
+
+// ListMeasures returns the raw measures for [from, to) of every supplier with any purchase order
+// or collection activity in the period, or only of the given suppliers when supplierIDs is set
+func (r *SupplierScorecardRepository) ListMeasures(ctx context.Context, supplierIDs []int64, from, to time.Time) ([]entity.SupplierScorecard, error) {
+	cards := map[int64]*entity.SupplierScorecard{}
+	card := func(supplierID int64) *entity.SupplierScorecard {
+		c, ok := cards[supplierID]
+		if !ok {
+			c = &entity.SupplierScorecard{SupplierID: supplierID, From: from, To: to}
+			cards[supplierID] = c
+		}
+		return c
+	}
+	// $3 restricts every query to the given suppliers; NULL keeps them all
+	var ids interface{}
+	if supplierIDs != nil {
+		ids = supplierIDs
+	}
+
+	// Receipts against orders with an expected delivery, on time when received by that date
+	rows, err := r.db.Conn(ctx).Query(ctx, `
+		SELECT p.supplier_id, COUNT(*),
+		       COUNT(*) FILTER (WHERE gr.received_at::date <= p.expected_delivery::date)
+		FROM goods_receipts gr
+		JOIN procurements p ON p.id = gr.procurement_id
+		WHERE gr.received_at >= $1 AND gr.received_at < $2 AND p.expected_delivery IS NOT NULL
+		  AND ($3::bigint[] IS NULL OR p.supplier_id = ANY($3))
+		GROUP BY p.supplier_id
+	`, from, to, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure delivery times: %w", err)
+	}
+	for rows.Next() {
+		var supplierID int64
+		var receipts, onTime int
+		if err := rows.Scan(&supplierID, &receipts, &onTime); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := card(supplierID)
+		c.Receipts, c.OnTimeReceipts = receipts, onTime
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Fill on orders raised in the period that are due: received in part or full, or still open
+	// past their expected delivery. Price variance on every order raised that wasn't cancelled.
+	rows, err = r.db.Conn(ctx).Query(ctx, `
+		SELECT p.supplier_id,
+		       COALESCE(SUM(pi.quantity_ordered) FILTER (WHERE due), 0),
+		       COALESCE(SUM(LEAST(pi.quantity_received, pi.quantity_ordered)) FILTER (WHERE due), 0),
+		       COALESCE(SUM(a.price * pi.quantity_ordered) FILTER (WHERE a.price > 0), 0),
+		       COALESCE(SUM(pi.unit_cost * pi.quantity_ordered) FILTER (WHERE a.price > 0), 0)
+		FROM procurements p
+		JOIN procurement_items pi ON pi.procurement_id = p.id
+		LEFT JOIN supplier_variants sv ON sv.supplier_id = p.supplier_id AND sv.variant_id = pi.variant_id
+		CROSS JOIN LATERAL (SELECT COALESCE(pi.list_price, sv.agreed_cost) AS price) a
+		CROSS JOIN LATERAL (
+			SELECT p.status IN ('partial', 'received')
+			    OR (p.status = 'ordered' AND p.expected_delivery < LEAST($2, NOW())) AS due
+		) d
+		WHERE p.created_at >= $1 AND p.created_at < $2 AND p.status <> 'cancelled'
+		  AND ($3::bigint[] IS NULL OR p.supplier_id = ANY($3))
+		GROUP BY p.supplier_id
+	`, from, to, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure order fill and pricing: %w", err)
+	}
+	for rows.Next() {
+		var supplierID int64
+		var ordered, received, agreed, actual decimal.Decimal
+		if err := rows.Scan(&supplierID, &ordered, &received, &agreed, &actual); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := card(supplierID)
+		c.QuantityOrdered, c.QuantityReceived = ordered, received
+		c.AgreedValue, c.OrderedValue = agreed, actual
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Accepted and rejected quantities on the period's receipts
+	rows, err = r.db.Conn(ctx).Query(ctx, `
+		SELECT p.supplier_id, COALESCE(SUM(gri.quantity_accepted), 0), COALESCE(SUM(gri.quantity_rejected), 0)
+		FROM goods_receipt_items gri
+		JOIN goods_receipts gr ON gr.id = gri.receipt_id
+		JOIN procurements p ON p.id = gr.procurement_id
+		WHERE gr.received_at >= $1 AND gr.received_at < $2
+		  AND ($3::bigint[] IS NULL OR p.supplier_id = ANY($3))
+		GROUP BY p.supplier_id
+	`, from, to, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure rejections: %w", err)
+	}
+	for rows.Next() {
+		var supplierID int64
+		var accepted, rejected decimal.Decimal
+		if err := rows.Scan(&supplierID, &accepted, &rejected); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := card(supplierID)
+		c.QuantityAccepted, c.QuantityRejected = accepted, rejected
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Daily collection volume over every day of the period, days without a collection counting as zero
+	rows, err = r.db.Conn(ctx).Query(ctx, `
+		WITH daily AS (
+			SELECT supplier_id, collected_at::date AS day, SUM(weight) AS weight, COUNT(*) AS collections,
+			       COUNT(*) FILTER (WHERE cardinality(adulteration_flags) > 0) AS adulterated
+			FROM collections
+			WHERE collected_at >= $1 AND collected_at < $2
+			  AND ($3::bigint[] IS NULL OR supplier_id = ANY($3))
+			GROUP BY supplier_id, collected_at::date
+		),
+		days AS (
+			SELECT generate_series($1::date, $2::date - 1, INTERVAL '1 day')::date AS day
+		)
+		SELECT s.supplier_id, COUNT(d.day), COALESCE(SUM(d.collections), 0), COALESCE(SUM(d.adulterated), 0),
+		       AVG(COALESCE(d.weight, 0)), STDDEV_POP(COALESCE(d.weight, 0))
+		FROM (SELECT DISTINCT supplier_id FROM daily) s
+		CROSS JOIN days
+		LEFT JOIN daily d ON d.supplier_id = s.supplier_id AND d.day = days.day
+		GROUP BY s.supplier_id
+	`, from, to, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure collection volume: %w", err)
+	}
+	periodDays := int(to.Sub(from).Hours() / 24)
+	for rows.Next() {
+		var supplierID int64
+		var days, collections, adulterated int
+		var mean, stddev decimal.Decimal
+		if err := rows.Scan(&supplierID, &days, &collections, &adulterated, &mean, &stddev); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := card(supplierID)
+		c.CollectionDays, c.Collections, c.AdulteratedCount = days, collections, adulterated
+		c.PeriodDays = periodDays
+		c.DailyVolumeMean, c.DailyVolumeStdDev = mean.Round(3), stddev.Round(3)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Suppliers asked for by ID are scored even without activity
+	for _, id := range supplierIDs {
+		card(id)
+	}
+	if len(cards) == 0 {
+		return []entity.SupplierScorecard{}, nil
+	}
+
+	scored := make([]int64, 0, len(cards))
+	for id := range cards {
+		scored = append(scored, id)
+	}
+	rows, err = r.db.Conn(ctx).Query(ctx, `SELECT id, name FROM suppliers WHERE id = ANY($1)`, scored)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		cards[id].SupplierName = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]entity.SupplierScorecard, 0, len(cards))
+	for _, c := range cards {
+		if c.PeriodDays == 0 {
+			c.PeriodDays = periodDays
+		}
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SupplierID < result[j].SupplierID })
+	return result, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
@@ -28,7 +29,7 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 		RETURNING id, created_at
 	`
-	return r.db.Pool.QueryRow(ctx, query,
+	return r.db.Conn(ctx).QueryRow(ctx, query,
 		user.Username, user.PasswordHash, user.RoleID, user.IsActive, user.FullName, user.Phone, user.Address,
 	).Scan(&user.ID, &user.CreatedAt)
 }
@@ -44,7 +45,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*entity.User, e
 		WHERE u.id = $1
 	`
 	user := &entity.User{Role: &entity.Role{}}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Conn(ctx).QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.PasswordHash, &user.RoleID, &user.IsActive, &user.CreatedAt,
 		&user.FullName, &user.Phone, &user.Address,
 		&user.Role.ID, &user.Role.Name, &user.Role.Description,
@@ -69,7 +70,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*e
 		WHERE u.username = $1
 	`
 	user := &entity.User{Role: &entity.Role{}}
-	err := r.db.Pool.QueryRow(ctx, query, username).Scan(
+	err := r.db.Conn(ctx).QueryRow(ctx, query, username).Scan(
 		&user.ID, &user.Username, &user.PasswordHash, &user.RoleID, &user.IsActive, &user.CreatedAt,
 		&user.FullName, &user.Phone, &user.Address,
 		&user.Role.ID, &user.Role.Name, &user.Role.Description,
@@ -88,7 +89,7 @@ func (r *UserRepository) List(ctx context.Context, offset, limit int) ([]entity.
 	// Get total count
 	var total int64
 	countQuery := `SELECT COUNT(*) FROM users`
-	if err := r.db.Pool.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+	if err := r.db.Conn(ctx).QueryRow(ctx, countQuery).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -102,7 +103,7 @@ func (r *UserRepository) List(ctx context.Context, offset, limit int) ([]entity.
 		ORDER BY u.id
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -131,7 +132,7 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 		SET username = $1, password_hash = $2, role_id = $3, is_active = $4, full_name = $5, phone = $6, address = $7
 		WHERE id = $8
 	`
-	result, err := r.db.Pool.Exec(ctx, query,
+	result, err := r.db.Conn(ctx).Exec(ctx, query,
 		user.Username, user.PasswordHash, user.RoleID, user.IsActive, user.FullName, user.Phone, user.Address, user.ID,
 	)
 	if err != nil {
@@ -146,7 +147,7 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 // Delete deletes a user by ID
 func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM users WHERE id = $1`
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -160,7 +161,7 @@ func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
 	var exists bool
-	err := r.db.Pool.QueryRow(ctx, query, username).Scan(&exists)
+	err := r.db.Conn(ctx).QueryRow(ctx, query, username).Scan(&exists)
 	return exists, err
 }
 
@@ -182,7 +183,7 @@ func (r *UserRepository) GetPermissions(ctx context.Context, userID int64) ([]en
 		)
 		ORDER BY p.id
 	`
-	rows, err := r.db.Pool.Query(ctx, query, userID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +203,7 @@ func (r *UserRepository) GetPermissions(ctx context.Context, userID int64) ([]en
 
 // SetDirectPermissions sets the direct permissions for a user
 func (r *UserRepository) SetDirectPermissions(ctx context.Context, userID int64, permissionIDs []int64) error {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -242,14 +243,14 @@ func (r *RoleRepository) Create(ctx context.Context, role *entity.Role) error {
 		VALUES ($1, $2)
 		RETURNING id
 	`
-	return r.db.Pool.QueryRow(ctx, query, role.Name, role.Description).Scan(&role.ID)
+	return r.db.Conn(ctx).QueryRow(ctx, query, role.Name, role.Description).Scan(&role.ID)
 }
 
 // GetByID retrieves a role by ID
 func (r *RoleRepository) GetByID(ctx context.Context, id int64) (*entity.Role, error) {
-	query := `SELECT id, name, description FROM roles WHERE id = $1`
+	query := `SELECT id, name, description, po_approval_limit FROM roles WHERE id = $1`
 	role := &entity.Role{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description)
+	err := r.db.Conn(ctx).QueryRow(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description, &role.POApprovalLimit)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrRoleNotFound
 	}
@@ -261,9 +262,9 @@ func (r *RoleRepository) GetByID(ctx context.Context, id int64) (*entity.Role, e
 
 // GetByName retrieves a role by name
 func (r *RoleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
-	query := `SELECT id, name, description FROM roles WHERE name = $1`
+	query := `SELECT id, name, description, po_approval_limit FROM roles WHERE name = $1`
 	role := &entity.Role{}
-	err := r.db.Pool.QueryRow(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description)
+	err := r.db.Conn(ctx).QueryRow(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.POApprovalLimit)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrRoleNotFound
 	}
@@ -275,8 +276,8 @@ func (r *RoleRepository) GetByName(ctx context.Context, name string) (*entity.Ro
 
 // List retrieves all roles
 func (r *RoleRepository) List(ctx context.Context) ([]entity.Role, error) {
-	query := `SELECT id, name, description FROM roles ORDER BY id`
-	rows, err := r.db.Pool.Query(ctx, query)
+	query := `SELECT id, name, description, po_approval_limit FROM roles ORDER BY id`
+	rows, err := r.db.Conn(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +286,7 @@ func (r *RoleRepository) List(ctx context.Context) ([]entity.Role, error) {
 	var roles []entity.Role
 	for rows.Next() {
 		var role entity.Role
-		if err := rows.Scan(&role.ID, &role.Name, &role.Description); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.POApprovalLimit); err != nil {
 			return nil, err
 		}
 		roles = append(roles, role)
@@ -296,7 +297,20 @@ func (r *RoleRepository) List(ctx context.Context) ([]entity.Role, error) {
 // Update updates an existing role
 func (r *RoleRepository) Update(ctx context.Context, role *entity.Role) error {
 	query := `UPDATE roles SET name = $1, description = $2 WHERE id = $3`
-	result, err := r.db.Pool.Exec(ctx, query, role.Name, role.Description, role.ID)
+	result, err := r.db.Conn(ctx).Exec(ctx, query, role.Name, role.Description, role.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domainErrors.ErrRoleNotFound
+	}
+	return nil
+}
+
+// SetApprovalLimit sets the purchase order approval limit of a role; nil removes the limit
+func (r *RoleRepository) SetApprovalLimit(ctx context.Context, id int64, limit *decimal.Decimal) error {
+	query := `UPDATE roles SET po_approval_limit = $1 WHERE id = $2`
+	result, err := r.db.Conn(ctx).Exec(ctx, query, limit, id)
 	if err != nil {
 		return err
 	}
@@ -309,7 +323,7 @@ func (r *RoleRepository) Update(ctx context.Context, role *entity.Role) error {
 // Delete deletes a role by ID
 func (r *RoleRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM roles WHERE id = $1`
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -328,7 +342,7 @@ func (r *RoleRepository) GetPermissions(ctx context.Context, roleID int64) ([]en
 		WHERE rp.role_id = $1
 		ORDER BY p.id
 	`
-	rows, err := r.db.Pool.Query(ctx, query, roleID)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, roleID)
 	if err != nil {
 		return nil, err
 	}
@@ -349,7 +363,7 @@ func (r *RoleRepository) GetPermissions(ctx context.Context, roleID int64) ([]en
 func (r *RoleRepository) SetPermissions(ctx context.Context, roleID int64, permissionIDs []int64) error {
 	// Delete existing permissions
 	deleteQuery := `DELETE FROM role_permissions WHERE role_id = $1`
-	if _, err := r.db.Pool.Exec(ctx, deleteQuery, roleID); err != nil {
+	if _, err := r.db.Conn(ctx).Exec(ctx, deleteQuery, roleID); err != nil {
 		return err
 	}
 
@@ -360,7 +374,7 @@ func (r *RoleRepository) SetPermissions(ctx context.Context, roleID int64, permi
 
 	insertQuery := `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2)`
 	for _, permissionID := range permissionIDs {
-		if _, err := r.db.Pool.Exec(ctx, insertQuery, roleID, permissionID); err != nil {
+		if _, err := r.db.Conn(ctx).Exec(ctx, insertQuery, roleID, permissionID); err != nil {
 			return err
 		}
 	}
@@ -384,14 +398,14 @@ func (r *PermissionRepository) Create(ctx context.Context, permission *entity.Pe
 		VALUES ($1, $2)
 		RETURNING id
 	`
-	return r.db.Pool.QueryRow(ctx, query, permission.Slug, permission.Description).Scan(&permission.ID)
+	return r.db.Conn(ctx).QueryRow(ctx, query, permission.Slug, permission.Description).Scan(&permission.ID)
 }
 
 // GetByID retrieves a permission by ID
 func (r *PermissionRepository) GetByID(ctx context.Context, id int64) (*entity.Permission, error) {
 	query := `SELECT id, slug, description FROM permissions WHERE id = $1`
 	p := &entity.Permission{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&p.ID, &p.Slug, &p.Description)
+	err := r.db.Conn(ctx).QueryRow(ctx, query, id).Scan(&p.ID, &p.Slug, &p.Description)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrPermissionNotFound
 	}
@@ -405,7 +419,7 @@ func (r *PermissionRepository) GetByID(ctx context.Context, id int64) (*entity.P
 func (r *PermissionRepository) GetBySlug(ctx context.Context, slug string) (*entity.Permission, error) {
 	query := `SELECT id, slug, description FROM permissions WHERE slug = $1`
 	p := &entity.Permission{}
-	err := r.db.Pool.QueryRow(ctx, query, slug).Scan(&p.ID, &p.Slug, &p.Description)
+	err := r.db.Conn(ctx).QueryRow(ctx, query, slug).Scan(&p.ID, &p.Slug, &p.Description)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domainErrors.ErrPermissionNotFound
 	}
@@ -418,7 +432,7 @@ func (r *PermissionRepository) GetBySlug(ctx context.Context, slug string) (*ent
 // List retrieves all permissions
 func (r *PermissionRepository) List(ctx context.Context) ([]entity.Permission, error) {
 	query := `SELECT id, slug, description FROM permissions ORDER BY id`
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Conn(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -438,7 +452,7 @@ func (r *PermissionRepository) List(ctx context.Context) ([]entity.Permission, e
 // Delete deletes a permission by ID
 func (r *PermissionRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM permissions WHERE id = $1`
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -241,14 +241,15 @@ func (r *SupplierRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// AddVariant adds a variant to a supplier
+// AddVariant adds a variant to a supplier at the agreed cost; an existing link keeps its cost,
+// which only changes with the supplier's price lists
 func (r *SupplierRepository) AddVariant(ctx context.Context, sv *entity.SupplierVariant) error {
 	query := `
 		INSERT INTO supplier_variants (supplier_id, variant_id, agreed_cost, is_preferred)
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (supplier_id, variant_id) DO UPDATE SET agreed_cost = $3, is_preferred = $4
+		ON CONFLICT (supplier_id, variant_id) DO UPDATE SET is_preferred = $4
 	`
-	_, err := r.db.Pool.Exec(ctx, query, sv.SupplierID, sv.VariantID, sv.AgreedCost, sv.IsPreferred)
+	_, err := r.db.Conn(ctx).Exec(ctx, query, sv.SupplierID, sv.VariantID, sv.AgreedCost, sv.IsPreferred)
 	return err
 }
 
@@ -311,6 +312,37 @@ func (r *SupplierRepository) GetPreferredSupplierForVariant(ctx context.Context,
 	return sv, nil
 }
 
+// ListSuppliersForVariant retrieves every supplier offering a variant at its agreed cost
+func (r *SupplierRepository) ListSuppliersForVariant(ctx context.Context, variantID int64) ([]entity.SupplierVariant, error) {
+	query := `
+		SELECT sv.supplier_id, s.name, COALESCE(s.phone, ''), COALESCE(s.location, ''),
+		       sv.variant_id, sv.agreed_cost, sv.is_preferred
+		FROM supplier_variants sv
+		JOIN suppliers s ON s.id = sv.supplier_id
+		WHERE sv.variant_id = $1
+		ORDER BY sv.is_preferred DESC, sv.agreed_cost, s.name
+	`
+	rows, err := r.db.Conn(ctx).Query(ctx, query, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []entity.SupplierVariant{}
+	for rows.Next() {
+		sv := entity.SupplierVariant{Supplier: &entity.Supplier{}}
+		if err := rows.Scan(
+			&sv.SupplierID, &sv.Supplier.Name, &sv.Supplier.Phone, &sv.Supplier.Location,
+			&sv.VariantID, &sv.AgreedCost, &sv.IsPreferred,
+		); err != nil {
+			return nil, err
+		}
+		sv.Supplier.ID = sv.SupplierID
+		variants = append(variants, sv)
+	}
+	return variants, rows.Err()
+}
+
 // RemoveVariant removes a variant from a supplier
 func (r *SupplierRepository) RemoveVariant(ctx context.Context, supplierID, variantID int64) error {
 	query := `DELETE FROM supplier_variants WHERE supplier_id = $1 AND variant_id = $2`
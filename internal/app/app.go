@@ -19,6 +19,7 @@ import (
 	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
 	"github.com/qwikshelf/api/internal/application/service"
 	"github.com/qwikshelf/api/internal/config"
+	"github.com/qwikshelf/api/internal/domain/entity"
 	"github.com/qwikshelf/api/pkg/logger"
 )
 
@@ -49,6 +50,7 @@ func NewApp(cfg *config.Config) (*App, error) {
 	}
 
 	// Initialize repositories
+	txManager := postgres.NewTxManager(db)
 	userRepo := postgres.NewUserRepository(db)
 	sessionRepo := postgres.NewSessionRepository(db)
 	roleRepo := postgres.NewRoleRepository(db)
@@ -58,13 +60,26 @@ func NewApp(cfg *config.Config) (*App, error) {
 	productFamilyRepo := postgres.NewProductFamilyRepository(db)
 	productVariantRepo := postgres.NewProductVariantRepository(db)
 	supplierRepo := postgres.NewSupplierRepository(db)
-	inventoryRepo := postgres.NewInventoryRepository(db)
+	supplierPayablesRepo := postgres.NewSupplierPayablesRepository(db)
+	supplierScorecardRepo := postgres.NewSupplierScorecardRepository(db)
+	supplierPriceListRepo := postgres.NewSupplierPriceListRepository(db)
+	inventoryRepo := postgres.NewInventoryRepository(db, entity.CostingMethod(cfg.Inventory.CostingMethod))
+	inventoryAdjustmentRepo := postgres.NewInventoryAdjustmentRepository(db)
+	stockTakeRepo := postgres.NewStockTakeRepository(db)
+	replenishmentRepo := postgres.NewReplenishmentRepository(db)
 	procurementRepo := postgres.NewProcurementRepository(db)
+	productionRepo := postgres.NewProductionRepository(db)
+	recipeRepo := postgres.NewRecipeRepository(db)
 	saleRepo := postgres.NewSaleRepository(db)
 	customerRepo := postgres.NewCustomerRepository(db)
+	customerAddressRepo := postgres.NewCustomerAddressRepository(db)
+	customerLedgerRepo := postgres.NewCustomerLedgerRepository(db)
 	collectionRepo := postgres.NewCollectionRepository(db)
+	collectionRateChartRepo := postgres.NewCollectionRateChartRepository(db)
+	collectionRouteRepo := postgres.NewCollectionRouteRepository(db)
 	pincodeRepo := postgres.NewPincodeRepository(db)
 	subscriptionRepo := postgres.NewSubscriptionRepository(db)
+	subscriptionInvoiceRepo := postgres.NewSubscriptionInvoiceRepository(db)
 	auditRepo := postgres.NewAuditLogRepository(db)
 	expenseRepo := postgres.NewPostgresExpenseRepository(db)
 	expenseCategoryRepo := postgres.NewPostgresExpenseCategoryRepository(db)
@@ -78,15 +93,27 @@ func NewApp(cfg *config.Config) (*App, error) {
 	categoryService := service.NewCategoryService(categoryRepo)
 	productFamilyService := service.NewProductFamilyService(productFamilyRepo, categoryRepo)
 	productVariantService := service.NewProductVariantService(productVariantRepo, productFamilyRepo)
-	supplierService := service.NewSupplierService(supplierRepo)
-	inventoryService := service.NewInventoryService(inventoryRepo, warehouseRepo, productVariantRepo)
-	procurementService := service.NewProcurementService(procurementRepo, supplierRepo, inventoryRepo, warehouseRepo, productVariantRepo)
-	saleService := service.NewSaleService(saleRepo, inventoryRepo, productVariantRepo, warehouseRepo)
-	customerService := service.NewCustomerService(customerRepo)
-	collectionService := service.NewCollectionService(collectionRepo, inventoryRepo, productVariantRepo, warehouseRepo, supplierRepo)
-	dashboardService := service.NewDashboardService(db)
+	recipeService := service.NewRecipeService(recipeRepo, productVariantRepo)
+	supplierPriceListService := service.NewSupplierPriceListService(supplierPriceListRepo, supplierRepo, txManager)
+	supplierService := service.NewSupplierService(supplierRepo, supplierPriceListService, txManager)
+	supplierScorecardService := service.NewSupplierScorecardService(supplierScorecardRepo, supplierRepo, cfg.Inventory.PreferredSupplierRanking, cfg.Inventory.SupplierScoreLookbackDays)
+	inventoryService := service.NewInventoryService(inventoryRepo, inventoryAdjustmentRepo, warehouseRepo, productVariantRepo, txManager, cfg.Inventory)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, warehouseRepo, productVariantRepo, inventoryService, txManager)
+	procurementService := service.NewProcurementService(procurementRepo, supplierRepo, inventoryRepo, warehouseRepo, productVariantRepo, roleRepo, txManager, supplierPriceListService, cfg.Inventory.OverReceiptTolerancePercent)
+	productionService := service.NewProductionService(productionRepo, recipeRepo, inventoryRepo, warehouseRepo, productVariantRepo, txManager)
+	saleService := service.NewSaleService(saleRepo, inventoryRepo, productVariantRepo, warehouseRepo, pincodeRepo, customerLedgerRepo, txManager, time.Duration(cfg.Inventory.OrderReservationHours)*time.Hour)
+	customerService := service.NewCustomerService(customerRepo, userService, txManager)
+	customerAddressService := service.NewCustomerAddressService(customerAddressRepo, pincodeRepo)
+	customerLedgerService := service.NewCustomerLedgerService(customerLedgerRepo, customerRepo, txManager)
+	supplierPayablesService := service.NewSupplierPayablesService(supplierPayablesRepo, supplierRepo, procurementRepo, txManager)
+	collectionRouteService := service.NewCollectionRouteService(collectionRouteRepo, collectionRepo, supplierRepo, userRepo, warehouseRepo)
+	collectionService := service.NewCollectionService(collectionRepo, collectionRateChartRepo, collectionRouteRepo, inventoryRepo, productVariantRepo, warehouseRepo, supplierRepo, txManager)
+	replenishmentService := service.NewReplenishmentService(replenishmentRepo, supplierRepo, warehouseRepo, productVariantRepo, supplierScorecardService, supplierPriceListService, procurementService, txManager, cfg.Inventory.ReplenishmentLookbackDays)
+	dashboardService := service.NewDashboardService(db, cfg.Inventory.LowStockThreshold)
 	deliveryService := service.NewDeliveryService(pincodeRepo)
-	subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+	stockReservationService := service.NewStockReservationService(inventoryRepo, subscriptionRepo, customerAddressRepo, pincodeRepo, productVariantRepo, txManager)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, customerAddressRepo, inventoryRepo, stockReservationService, txManager)
+	subscriptionBillingService := service.NewSubscriptionBillingService(subscriptionInvoiceRepo, customerLedgerRepo, customerLedgerService, txManager)
 	auditService := service.NewAuditService(auditRepo)
 	expenseService := service.NewExpenseService(expenseRepo, expenseCategoryRepo)
 
@@ -98,16 +125,27 @@ func NewApp(cfg *config.Config) (*App, error) {
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	productFamilyHandler := handler.NewProductFamilyHandler(productFamilyService)
 	productVariantHandler := handler.NewProductVariantHandler(productVariantService)
+	recipeHandler := handler.NewRecipeHandler(recipeService)
 	supplierHandler := handler.NewSupplierHandler(supplierService)
+	supplierPayablesHandler := handler.NewSupplierPayablesHandler(supplierPayablesService)
+	supplierScorecardHandler := handler.NewSupplierScorecardHandler(supplierScorecardService)
+	supplierPriceListHandler := handler.NewSupplierPriceListHandler(supplierPriceListService)
 	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	stockTakeHandler := handler.NewStockTakeHandler(stockTakeService)
+	replenishmentHandler := handler.NewReplenishmentHandler(replenishmentService)
+	reservationHandler := handler.NewReservationHandler(stockReservationService)
 	procurementHandler := handler.NewProcurementHandler(procurementService)
+	productionHandler := handler.NewProductionHandler(productionService)
 	saleHandler := handler.NewSaleHandler(saleService)
 	customerHandler := handler.NewCustomerHandler(customerService)
+	customerLedgerHandler := handler.NewCustomerLedgerHandler(customerLedgerService)
 	collectionHandler := handler.NewCollectionHandler(collectionService)
+	collectionRouteHandler := handler.NewCollectionRouteHandler(collectionRouteService)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService, authService)
 	serviceabilityHandler := handler.NewServiceabilityHandler(deliveryService)
-	publicHandler := handler.NewPublicHandler(productVariantService, categoryService, saleService, userService, authService, deliveryService)
+	publicHandler := handler.NewPublicHandler(productVariantService, categoryService, saleService, customerService, customerAddressService, userService, authService, deliveryService, stockReservationService)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	subscriptionBillingHandler := handler.NewSubscriptionBillingHandler(subscriptionBillingService)
 	expenseHandler := handler.NewExpenseHandler(auditService, expenseService)
 
 	// Initialize middleware
@@ -139,6 +177,17 @@ func NewApp(cfg *config.Config) (*App, error) {
 		SubscriptionHandler:   subscriptionHandler,
 		AuditMiddleware:       auditMiddleware,
 		ExpenseHandler:        expenseHandler,
+		ProductionHandler:     productionHandler,
+		RecipeHandler:         recipeHandler,
+		CustomerLedgerHandler: customerLedgerHandler,
+		SubscriptionBillingHandler: subscriptionBillingHandler,
+		StockTakeHandler:           stockTakeHandler,
+		ReplenishmentHandler:       replenishmentHandler,
+		ReservationHandler:         reservationHandler,
+		SupplierPayablesHandler:    supplierPayablesHandler,
+		CollectionRouteHandler:     collectionRouteHandler,
+		SupplierScorecardHandler:   supplierScorecardHandler,
+		SupplierPriceListHandler:   supplierPriceListHandler,
 	})
 
 	return &App{
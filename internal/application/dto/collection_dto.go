@@ -11,8 +11,16 @@ type RecordCollectionRequest struct {
 	SupplierID  int64           `json:"supplier_id" binding:"required"`
 	WarehouseID int64           `json:"warehouse_id"` // Optional, server can default to Main
 	Weight      decimal.Decimal `json:"weight" binding:"required"`
-	CollectedAt time.Time       `json:"collected_at"` // If nil, server uses now
+	CollectedAt time.Time       `json:"collected_at"`                                              // If nil, server uses now
+	Shift       string          `json:"shift,omitempty" binding:"omitempty,oneof=morning evening"` // Defaults from collected_at
 	Notes       string          `json:"notes"`
+	BatchNumber *string         `json:"batch_number,omitempty" binding:"omitempty,max=100"` // Defaults to one per collection
+	ExpiryDate  string          `json:"expiry_date,omitempty"`                              // YYYY-MM-DD
+
+	FatPercent        *decimal.Decimal `json:"fat_percent,omitempty"`
+	SNFPercent        *decimal.Decimal `json:"snf_percent,omitempty"`
+	Temperature       *decimal.Decimal `json:"temperature,omitempty"` // °C
+	AdulterationFlags []string         `json:"adulteration_flags,omitempty" binding:"omitempty,dive,oneof=water urea starch sugar salt detergent neutralizer formalin hydrogen_peroxide"`
 }
 
 type CollectionResponse struct {
@@ -26,5 +34,159 @@ type CollectionResponse struct {
 	WarehouseID  int64           `json:"warehouse_id"`
 	Weight       decimal.Decimal `json:"weight"`
 	CollectedAt  time.Time       `json:"collected_at"`
+	Shift        string          `json:"shift"`
 	Notes        string          `json:"notes"`
+	BatchNumber  *string         `json:"batch_number,omitempty"`
+	ExpiryDate   *time.Time      `json:"expiry_date,omitempty"`
+
+	FatPercent        *decimal.Decimal `json:"fat_percent,omitempty"`
+	SNFPercent        *decimal.Decimal `json:"snf_percent,omitempty"`
+	Temperature       *decimal.Decimal `json:"temperature,omitempty"`
+	AdulterationFlags []string         `json:"adulteration_flags"`
+	RateChartID       *int64           `json:"rate_chart_id,omitempty"`
+	Rate              *decimal.Decimal `json:"rate,omitempty"`
+	Amount            *decimal.Decimal `json:"amount,omitempty"`
+}
+
+// CollectionSummaryResponse totals a supplier's collections for a shift or a collection period
+type CollectionSummaryResponse struct {
+	SupplierID     int64            `json:"supplier_id"`
+	SupplierName   string           `json:"supplier_name"`
+	Date           string           `json:"date,omitempty"`  // YYYY-MM-DD, shift summaries only
+	Shift          string           `json:"shift,omitempty"` // shift summaries only
+	Collections    int              `json:"collections"`
+	TotalWeight    decimal.Decimal  `json:"total_weight"`
+	AvgFatPercent  *decimal.Decimal `json:"avg_fat_percent,omitempty"`
+	AvgSNFPercent  *decimal.Decimal `json:"avg_snf_percent,omitempty"`
+	Adulterated    int              `json:"adulterated"`
+	TotalAmount    decimal.Decimal  `json:"total_amount"`
+	UnpricedWeight decimal.Decimal  `json:"unpriced_weight"`
+}
+
+// CollectionRateSlabRequest is one fat/SNF slab of a rate chart
+type CollectionRateSlabRequest struct {
+	MinFatPercent decimal.Decimal `json:"min_fat_percent"`
+	MinSNFPercent decimal.Decimal `json:"min_snf_percent"`
+	Rate          decimal.Decimal `json:"rate" binding:"required"`
+}
+
+// CreateCollectionRateChartRequest represents a request to configure a rate chart. Set supplier_id
+// or zone_id to scope it; with neither it is the default chart for the variant.
+type CreateCollectionRateChartRequest struct {
+	Name          string                      `json:"name" binding:"required,max=100"`
+	VariantID     int64                       `json:"variant_id" binding:"required"`
+	ZoneID        *int64                      `json:"zone_id,omitempty"`
+	SupplierID    *int64                      `json:"supplier_id,omitempty"`
+	EffectiveFrom string                      `json:"effective_from,omitempty"` // YYYY-MM-DD, defaults to today
+	Slabs         []CollectionRateSlabRequest `json:"slabs" binding:"required,min=1,dive"`
+}
+
+// CollectionRateSlabResponse represents a rate chart slab
+type CollectionRateSlabResponse struct {
+	ID            int64           `json:"id"`
+	MinFatPercent decimal.Decimal `json:"min_fat_percent"`
+	MinSNFPercent decimal.Decimal `json:"min_snf_percent"`
+	Rate          decimal.Decimal `json:"rate"`
+}
+
+// CollectionRateChartResponse represents a rate chart in API responses
+type CollectionRateChartResponse struct {
+	ID            int64                        `json:"id"`
+	Name          string                       `json:"name"`
+	VariantID     int64                        `json:"variant_id"`
+	ZoneID        *int64                       `json:"zone_id,omitempty"`
+	SupplierID    *int64                       `json:"supplier_id,omitempty"`
+	EffectiveFrom string                       `json:"effective_from"`
+	IsActive      bool                         `json:"is_active"`
+	Slabs         []CollectionRateSlabResponse `json:"slabs"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+// ShiftCollectionEntry is one supplier's collection within a shift submission
+type ShiftCollectionEntry struct {
+	SupplierID        int64            `json:"supplier_id" binding:"required"`
+	VariantID         int64            `json:"variant_id" binding:"required"`
+	Weight            decimal.Decimal  `json:"weight" binding:"required"`
+	FatPercent        *decimal.Decimal `json:"fat_percent,omitempty"`
+	SNFPercent        *decimal.Decimal `json:"snf_percent,omitempty"`
+	Temperature       *decimal.Decimal `json:"temperature,omitempty"`
+	AdulterationFlags []string         `json:"adulteration_flags,omitempty" binding:"omitempty,dive,oneof=water urea starch sugar salt detergent neutralizer formalin hydrogen_peroxide"`
+	Notes             string           `json:"notes"`
+}
+
+// RecordShiftRequest submits a whole shift of collections at once, optionally against a route
+type RecordShiftRequest struct {
+	RouteID     *int64                 `json:"route_id,omitempty"`
+	WarehouseID int64                  `json:"warehouse_id"` // Defaults to the route's warehouse, else Main
+	Shift       string                 `json:"shift,omitempty" binding:"omitempty,oneof=morning evening"`
+	CollectedAt time.Time              `json:"collected_at"` // If nil, server uses now
+	Entries     []ShiftCollectionEntry `json:"entries" binding:"required,min=1,dive"`
+}
+
+// CreateCollectionRouteRequest represents a request to plan a route for an agent. Without
+// supplier_ids the stops are built from the suppliers of the zone.
+type CreateCollectionRouteRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	AgentID     int64   `json:"agent_id" binding:"required"`
+	ZoneID      *int64  `json:"zone_id,omitempty"`
+	WarehouseID int64   `json:"warehouse_id" binding:"required"`
+	SupplierIDs []int64 `json:"supplier_ids,omitempty"`
+}
+
+// UpdateCollectionRouteRequest represents a request to update a route's details
+type UpdateCollectionRouteRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	AgentID     int64  `json:"agent_id" binding:"required"`
+	ZoneID      *int64 `json:"zone_id,omitempty"`
+	WarehouseID int64  `json:"warehouse_id" binding:"required"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+}
+
+// SetRouteStopsRequest lists a route's suppliers in visiting order; empty rebuilds it from its zone
+type SetRouteStopsRequest struct {
+	SupplierIDs []int64 `json:"supplier_ids"`
+}
+
+// CollectionRouteStopResponse represents a stop on a route
+type CollectionRouteStopResponse struct {
+	Sequence     int    `json:"sequence"`
+	SupplierID   int64  `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	Phone        string `json:"phone,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// CollectionRouteResponse represents a collection route in API responses
+type CollectionRouteResponse struct {
+	ID          int64                         `json:"id"`
+	Name        string                        `json:"name"`
+	AgentID     int64                         `json:"agent_id"`
+	AgentName   string                        `json:"agent_name,omitempty"`
+	ZoneID      *int64                        `json:"zone_id,omitempty"`
+	WarehouseID int64                         `json:"warehouse_id"`
+	IsActive    bool                          `json:"is_active"`
+	Stops       []CollectionRouteStopResponse `json:"stops"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+}
+
+// ShiftSheetRowResponse is one stop on a shift sheet with what was collected from it
+type ShiftSheetRowResponse struct {
+	CollectionRouteStopResponse
+	Collected   bool                 `json:"collected"`
+	TotalWeight decimal.Decimal      `json:"total_weight"`
+	Collections []CollectionResponse `json:"collections"`
+}
+
+// ShiftSheetResponse represents a route's shift sheet
+type ShiftSheetResponse struct {
+	RouteID   int64                   `json:"route_id"`
+	RouteName string                  `json:"route_name"`
+	AgentID   int64                   `json:"agent_id"`
+	AgentName string                  `json:"agent_name,omitempty"`
+	Date      string                  `json:"date"`
+	Shift     string                  `json:"shift"`
+	Collected int                     `json:"collected"`
+	Pending   int                     `json:"pending"`
+	Rows      []ShiftSheetRowResponse `json:"rows"`
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type CustomerResponse struct {
 	ID               int64     `json:"id"`
@@ -18,6 +22,7 @@ type CustomerResponse struct {
 	ZoneName         string    `json:"zone_name"`
 	Latitude         *float64  `json:"latitude,omitempty"`
 	Longitude        *float64  `json:"longitude,omitempty"`
+	UserID           *int64    `json:"user_id,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -65,3 +70,108 @@ type BulkImportResponse struct {
 	Failed      int               `json:"failed"`
 	Errors      []BulkImportError `json:"errors,omitempty"`
 }
+
+// MergeCustomerRequest represents a request to fold a duplicate customer into another
+type MergeCustomerRequest struct {
+	SourceCustomerID int64 `json:"source_customer_id" binding:"required"`
+}
+
+// --- Customer Address Book DTOs ---
+
+// CustomerAddressRequest represents a request to save an address in the customer's address book
+type CustomerAddressRequest struct {
+	Label        string   `json:"label" binding:"omitempty,oneof=home work other"`
+	ContactName  string   `json:"contact_name,omitempty"`
+	Phone        string   `json:"phone,omitempty"`
+	AddressLine1 string   `json:"address_line1" binding:"required"`
+	AddressLine2 string   `json:"address_line2,omitempty"`
+	Landmark     string   `json:"landmark,omitempty"`
+	City         string   `json:"city,omitempty"`
+	Pincode      string   `json:"pincode" binding:"required"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	IsDefault    bool     `json:"is_default"`
+}
+
+// CustomerAddressResponse represents a saved customer address
+type CustomerAddressResponse struct {
+	ID           int64     `json:"id"`
+	Label        string    `json:"label"`
+	ContactName  string    `json:"contact_name,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2,omitempty"`
+	Landmark     string    `json:"landmark,omitempty"`
+	City         string    `json:"city,omitempty"`
+	Pincode      string    `json:"pincode"`
+	Latitude     *float64  `json:"latitude,omitempty"`
+	Longitude    *float64  `json:"longitude,omitempty"`
+	ZoneID       *int64    `json:"zone_id,omitempty"`
+	ZoneName     string    `json:"zone_name,omitempty"`
+	Serviceable  bool      `json:"serviceable"`
+	IsDefault    bool      `json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// --- Customer Ledger DTOs ---
+
+// RecordCustomerPaymentRequest represents a payment received against a customer's account
+type RecordCustomerPaymentRequest struct {
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	PaymentMethod string          `json:"payment_method" binding:"omitempty,oneof=cash card upi bank_transfer cheque other"`
+	Reference     string          `json:"reference,omitempty"`
+	Notes         string          `json:"notes,omitempty"`
+	ReceivedAt    string          `json:"received_at,omitempty"` // YYYY-MM-DD, defaults to now
+}
+
+// CustomerPaymentResponse represents a recorded customer payment
+type CustomerPaymentResponse struct {
+	ID            int64           `json:"id"`
+	CustomerID    int64           `json:"customer_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	PaymentMethod string          `json:"payment_method"`
+	Reference     string          `json:"reference,omitempty"`
+	Notes         string          `json:"notes,omitempty"`
+	ReceivedAt    time.Time       `json:"received_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// CustomerLedgerEntryResponse represents a ledger line with its running balance
+type CustomerLedgerEntryResponse struct {
+	ID          int64           `json:"id"`
+	EntryType   string          `json:"entry_type"`
+	SaleID      *int64          `json:"sale_id,omitempty"`
+	InvoiceID   *int64          `json:"invoice_id,omitempty"`
+	PaymentID   *int64          `json:"payment_id,omitempty"`
+	Description string          `json:"description,omitempty"`
+	EntryDate   time.Time       `json:"entry_date"`
+	DueDate     *time.Time      `json:"due_date,omitempty"`
+	Debit       decimal.Decimal `json:"debit"`
+	Credit      decimal.Decimal `json:"credit"`
+	Balance     decimal.Decimal `json:"balance"`
+}
+
+// CustomerStatementResponse represents a customer's statement for a date range
+type CustomerStatementResponse struct {
+	CustomerID     int64                         `json:"customer_id"`
+	CustomerName   string                        `json:"customer_name"`
+	StartDate      string                        `json:"start_date"`
+	EndDate        string                        `json:"end_date"`
+	OpeningBalance decimal.Decimal               `json:"opening_balance"`
+	TotalDebit     decimal.Decimal               `json:"total_debit"`
+	TotalCredit    decimal.Decimal               `json:"total_credit"`
+	ClosingBalance decimal.Decimal               `json:"closing_balance"`
+	Entries        []CustomerLedgerEntryResponse `json:"entries"`
+}
+
+// CustomerAgingResponse represents a customer's outstanding balance by age bucket
+type CustomerAgingResponse struct {
+	CustomerID   int64           `json:"customer_id"`
+	CustomerName string          `json:"customer_name"`
+	Balance      decimal.Decimal `json:"balance"`
+	Days0To15    decimal.Decimal `json:"days_0_15"`
+	Days16To30   decimal.Decimal `json:"days_16_30"`
+	Days31To60   decimal.Decimal `json:"days_31_60"`
+	Over60       decimal.Decimal `json:"over_60"`
+}
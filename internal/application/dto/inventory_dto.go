@@ -51,19 +51,156 @@ type InventoryLevelResponse struct {
 
 // AdjustInventoryRequest represents a request to manually adjust inventory quantities.
 // Used for corrections, shrinkage, or stocktaking adjustments.
+// ReasonCode must be one of: damage, spoilage, theft, count_correction, sample.
 type AdjustInventoryRequest struct {
 	WarehouseID   int64           `json:"warehouse_id" binding:"required"`
 	VariantID     int64           `json:"variant_id" binding:"required"`
 	QuantityDelta decimal.Decimal `json:"quantity_delta"`
+	ReasonCode    string          `json:"reason_code" binding:"required,oneof=damage spoilage theft count_correction sample"`
 	Reason        string          `json:"reason,omitempty"`
 }
 
+// ReviewAdjustmentRequest represents an approval or rejection of a pending adjustment
+type ReviewAdjustmentRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// InventoryAdjustmentResponse represents a manual adjustment in API responses.
+// Level is the updated stock level once the adjustment has been applied.
+type InventoryAdjustmentResponse struct {
+	ID                int64                   `json:"id"`
+	WarehouseID       int64                   `json:"warehouse_id"`
+	WarehouseName     string                  `json:"warehouse_name,omitempty"`
+	VariantID         int64                   `json:"variant_id"`
+	VariantName       string                  `json:"variant_name,omitempty"`
+	ReasonCode        entity.AdjustmentReason `json:"reason_code"`
+	QuantityDelta     decimal.Decimal         `json:"quantity_delta"`
+	UnitCost          decimal.Decimal         `json:"unit_cost"`
+	Value             decimal.Decimal         `json:"value"`
+	Notes             *string                 `json:"notes,omitempty"`
+	Status            entity.AdjustmentStatus `json:"status"`
+	RequestedByUserID *int64                  `json:"requested_by_user_id,omitempty"`
+	ReviewedByUserID  *int64                  `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt        *time.Time              `json:"reviewed_at,omitempty"`
+	ReviewNote        *string                 `json:"review_note,omitempty"`
+	CreatedAt         time.Time               `json:"created_at"`
+	Level             *InventoryLevelResponse `json:"level,omitempty"`
+}
+
+// ShrinkageSummaryResponse represents the stock written off for one reason at one warehouse
+type ShrinkageSummaryResponse struct {
+	WarehouseID     int64                   `json:"warehouse_id"`
+	WarehouseName   string                  `json:"warehouse_name"`
+	ReasonCode      entity.AdjustmentReason `json:"reason_code"`
+	AdjustmentCount int                     `json:"adjustment_count"`
+	Quantity        decimal.Decimal         `json:"quantity"`
+	Value           decimal.Decimal         `json:"value"`
+}
+
+// ShrinkageReportResponse represents shrinkage by reason and warehouse over a period
+type ShrinkageReportResponse struct {
+	From       time.Time                  `json:"from"`
+	To         time.Time                  `json:"to"`
+	TotalValue decimal.Decimal            `json:"total_value"`
+	Rows       []ShrinkageSummaryResponse `json:"rows"`
+}
+
+// InventoryMovementResponse represents a stock ledger entry in API responses
+type InventoryMovementResponse struct {
+	ID            int64                     `json:"id"`
+	SourceType    entity.MovementSourceType `json:"source_type"`
+	ReferenceID   *int64                    `json:"reference_id,omitempty"`
+	LotID         *int64                    `json:"lot_id,omitempty"`
+	UserID        *int64                    `json:"user_id,omitempty"`
+	QuantityDelta decimal.Decimal           `json:"quantity_delta"`
+	BalanceAfter  decimal.Decimal           `json:"balance_after"`
+	UnitCost      *decimal.Decimal          `json:"unit_cost,omitempty"`
+	TotalCost     *decimal.Decimal          `json:"total_cost,omitempty"`
+	ValueAfter    *decimal.Decimal          `json:"value_after,omitempty"`
+	Notes         *string                   `json:"notes,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+}
+
+// StockCardResponse represents the movement history of a variant at a warehouse over a period
+type StockCardResponse struct {
+	WarehouseID    int64                       `json:"warehouse_id"`
+	VariantID      int64                       `json:"variant_id"`
+	From           time.Time                   `json:"from"`
+	To             time.Time                   `json:"to"`
+	OpeningBalance decimal.Decimal             `json:"opening_balance"`
+	TotalIn        decimal.Decimal             `json:"total_in"`
+	TotalOut       decimal.Decimal             `json:"total_out"`
+	ClosingBalance decimal.Decimal             `json:"closing_balance"`
+	Movements      []InventoryMovementResponse `json:"movements"`
+}
+
+// InventoryDriftResponse represents a stock level that disagrees with the movement ledger
+type InventoryDriftResponse struct {
+	WarehouseID    int64           `json:"warehouse_id"`
+	VariantID      int64           `json:"variant_id"`
+	LevelQuantity  decimal.Decimal `json:"level_quantity"`
+	LedgerQuantity decimal.Decimal `json:"ledger_quantity"`
+	Drift          decimal.Decimal `json:"drift"`
+}
+
+// InventoryLotResponse represents the stock of one batch at a warehouse in API responses
+type InventoryLotResponse struct {
+	ID            int64                     `json:"id"`
+	WarehouseID   int64                     `json:"warehouse_id"`
+	WarehouseName string                    `json:"warehouse_name"`
+	VariantID     int64                     `json:"variant_id"`
+	VariantName   string                    `json:"variant_name"`
+	BatchNumber   string                    `json:"batch_number"`
+	ExpiryDate    *time.Time                `json:"expiry_date,omitempty"`
+	Expired       bool                      `json:"expired"`
+	Quantity      decimal.Decimal           `json:"quantity"`
+	SupplierID    *int64                    `json:"supplier_id,omitempty"`
+	SupplierName  *string                   `json:"supplier_name,omitempty"`
+	SourceType    entity.MovementSourceType `json:"source_type"`
+	ReferenceID   *int64                    `json:"reference_id,omitempty"`
+	ReceivedAt    time.Time                 `json:"received_at"`
+}
+
+// LotTraceMovementResponse represents a ledger entry of a traced batch
+type LotTraceMovementResponse struct {
+	ID            int64                     `json:"id"`
+	WarehouseID   int64                     `json:"warehouse_id"`
+	WarehouseName string                    `json:"warehouse_name"`
+	LotID         *int64                    `json:"lot_id,omitempty"`
+	SourceType    entity.MovementSourceType `json:"source_type"`
+	ReferenceID   *int64                    `json:"reference_id,omitempty"`
+	QuantityDelta decimal.Decimal           `json:"quantity_delta"`
+	CustomerID    *int64                    `json:"customer_id,omitempty"`
+	CustomerName  *string                   `json:"customer_name,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+}
+
+// LotTraceCustomerResponse represents the net quantity of a traced batch sold to a customer
+type LotTraceCustomerResponse struct {
+	CustomerID   *int64          `json:"customer_id,omitempty"`
+	CustomerName string          `json:"customer_name"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	SaleIDs      []int64         `json:"sale_ids"`
+}
+
+// LotTraceResponse represents where a batch came from and where it went
+type LotTraceResponse struct {
+	BatchNumber string                     `json:"batch_number"`
+	VariantID   int64                      `json:"variant_id"`
+	Lots        []InventoryLotResponse     `json:"lots"`
+	Movements   []LotTraceMovementResponse `json:"movements"`
+	Customers   []LotTraceCustomerResponse `json:"customers"`
+}
+
 // CreateTransferRequest represents a request to move inventory between warehouses.
 // Source and Destination must be different. Items list cannot be empty.
+// With dispatch set the stock leaves the source straight away; otherwise the transfer waits as pending.
 type CreateTransferRequest struct {
 	SourceWarehouseID      int64                 `json:"source_warehouse_id" binding:"required"`
 	DestinationWarehouseID int64                 `json:"destination_warehouse_id" binding:"required"`
 	Items                  []TransferItemRequest `json:"items" binding:"required,min=1,dive"`
+	Notes                  *string               `json:"notes"`
+	Dispatch               bool                  `json:"dispatch"`
 }
 
 // TransferItemRequest represents a specific product variant and quantity to transfer.
@@ -73,6 +210,33 @@ type TransferItemRequest struct {
 	Quantity  decimal.Decimal `json:"quantity"`
 }
 
+// DispatchTransferRequest represents the quantities sent when they differ from those asked for.
+// Items left out are sent in full.
+type DispatchTransferRequest struct {
+	Items []DispatchTransferItemRequest `json:"items" binding:"dive"`
+}
+
+// DispatchTransferItemRequest represents the quantity of one transfer item actually sent
+type DispatchTransferItemRequest struct {
+	ItemID   int64           `json:"item_id" binding:"required"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// ReceiveTransferRequest represents what arrived at the destination.
+// Items left out are taken as received in full.
+type ReceiveTransferRequest struct {
+	Items []ReceiveTransferItemRequest `json:"items" binding:"dive"`
+}
+
+// ReceiveTransferItemRequest represents the quantity of one transfer item that arrived in good
+// condition and the quantity that arrived damaged; the rest of what was dispatched is short
+type ReceiveTransferItemRequest struct {
+	ItemID           int64           `json:"item_id" binding:"required"`
+	ReceivedQuantity decimal.Decimal `json:"received_quantity"`
+	DamagedQuantity  decimal.Decimal `json:"damaged_quantity"`
+	Notes            *string         `json:"notes"`
+}
+
 // TransferResponse represents an inventory transfer in API responses
 type TransferResponse struct {
 	ID                     int64                  `json:"id"`
@@ -83,13 +247,69 @@ type TransferResponse struct {
 	AuthorizedByUserID     int64                  `json:"authorized_by_user_id"`
 	TransferredAt          time.Time              `json:"transferred_at"`
 	Status                 entity.TransferStatus  `json:"status"`
+	Notes                  *string                `json:"notes,omitempty"`
+	RequestedByUserID      *int64                 `json:"requested_by_user_id,omitempty"`
+	DispatchedByUserID     *int64                 `json:"dispatched_by_user_id,omitempty"`
+	DispatchedAt           *time.Time             `json:"dispatched_at,omitempty"`
+	ReceivedByUserID       *int64                 `json:"received_by_user_id,omitempty"`
+	ReceivedAt             *time.Time             `json:"received_at,omitempty"`
+	CancelledByUserID      *int64                 `json:"cancelled_by_user_id,omitempty"`
+	CancelledAt            *time.Time             `json:"cancelled_at,omitempty"`
 	Items                  []TransferItemResponse `json:"items,omitempty"`
 }
 
+// InTransitStockResponse represents stock dispatched to a warehouse and not yet received
+type InTransitStockResponse struct {
+	DestinationWarehouseID int64           `json:"destination_warehouse_id"`
+	VariantID              int64           `json:"variant_id"`
+	VariantName            string          `json:"variant_name"`
+	Quantity               decimal.Decimal `json:"quantity"`
+	Transfers              int             `json:"transfers"`
+}
+
 // TransferItemResponse represents a transfer item in API responses
 type TransferItemResponse struct {
-	ID        int64                   `json:"id"`
-	VariantID int64                   `json:"variant_id"`
-	Variant   *ProductVariantResponse `json:"variant,omitempty"`
-	Quantity  decimal.Decimal         `json:"quantity"`
+	ID                 int64                   `json:"id"`
+	VariantID          int64                   `json:"variant_id"`
+	Variant            *ProductVariantResponse `json:"variant,omitempty"`
+	Quantity           decimal.Decimal         `json:"quantity"`
+	DispatchedQuantity *decimal.Decimal        `json:"dispatched_quantity,omitempty"`
+	ReceivedQuantity   *decimal.Decimal        `json:"received_quantity,omitempty"`
+	DamagedQuantity    *decimal.Decimal        `json:"damaged_quantity,omitempty"`
+	ShortQuantity      *decimal.Decimal        `json:"short_quantity,omitempty"`
+	Notes              *string                 `json:"notes,omitempty"`
+}
+
+// InventoryValuationResponse represents the value of stock at the end of a day
+type InventoryValuationResponse struct {
+	AsOf       string                           `json:"as_of"`
+	Method     entity.CostingMethod             `json:"costing_method"`
+	TotalValue decimal.Decimal                  `json:"total_value"`
+	Lines      []InventoryValuationLineResponse `json:"lines"`
+}
+
+// InventoryValuationLineResponse represents the stock and value of a variant at a warehouse
+type InventoryValuationLineResponse struct {
+	WarehouseID   int64           `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name"`
+	VariantID     int64           `json:"variant_id"`
+	VariantName   string          `json:"variant_name"`
+	SKU           string          `json:"sku"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	UnitCost      decimal.Decimal `json:"unit_cost"`
+	Value         decimal.Decimal `json:"value"`
+}
+
+// CostLayerResponse represents stock received at one unit cost and what is left of it
+type CostLayerResponse struct {
+	ID                int64                     `json:"id"`
+	WarehouseID       int64                     `json:"warehouse_id"`
+	VariantID         int64                     `json:"variant_id"`
+	VariantName       string                    `json:"variant_name"`
+	MovementID        *int64                    `json:"movement_id,omitempty"`
+	SourceType        entity.MovementSourceType `json:"source_type"`
+	UnitCost          decimal.Decimal           `json:"unit_cost"`
+	Quantity          decimal.Decimal           `json:"quantity"`
+	RemainingQuantity decimal.Decimal           `json:"remaining_quantity"`
+	ReceivedAt        time.Time                 `json:"received_at"`
 }
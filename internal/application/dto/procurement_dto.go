@@ -16,27 +16,70 @@ type CreateProcurementRequest struct {
 	Items            []CreateProcurementItemRequest `json:"items" binding:"required,min=1,dive"`
 }
 
-// CreateProcurementItemRequest represents a line item in a procurement request
+// CreateProcurementItemRequest represents a line item in a procurement request.
+// Lines without a unit cost are ordered at the supplier's price for the quantity.
 type CreateProcurementItemRequest struct {
 	VariantID int64           `json:"variant_id" binding:"required"`
 	Quantity  decimal.Decimal `json:"quantity"`
 	UnitCost  decimal.Decimal `json:"unit_cost"`
 }
 
-// UpdateProcurementStatusRequest represents a request to update procurement status
+// UpdateProcurementStatusRequest represents a request to update procurement status.
+// Orders are approved through the approval endpoint rather than by setting the status.
 type UpdateProcurementStatusRequest struct {
 	Status string `json:"status" binding:"required,oneof=pending approved ordered partial received cancelled"`
+	Note   string `json:"note,omitempty" binding:"omitempty,max=500"`
 }
 
-// ReceiveProcurementItemRequest represents a request to receive items
-type ReceiveProcurementItemRequest struct {
-	Items []ReceiveItemRequest `json:"items" binding:"required,min=1,dive"`
+// ApproveProcurementRequest represents a request to approve a purchase order
+type ApproveProcurementRequest struct {
+	Note string `json:"note,omitempty" binding:"omitempty,max=500"`
 }
 
-// ReceiveItemRequest represents a single item being received
-type ReceiveItemRequest struct {
+// CreateGoodsReceiptRequest represents a delivery received against a purchase order
+type CreateGoodsReceiptRequest struct {
+	DeliveryReference string                    `json:"delivery_reference,omitempty" binding:"omitempty,max=100"`
+	Notes             string                    `json:"notes,omitempty"`
+	Items             []GoodsReceiptItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// GoodsReceiptItemRequest represents the quantity of a purchase order line in a delivery, with the
+// batch it arrived in. Rejected quantities need a reason. Lines received without a batch number are
+// given one from the receipt.
+type GoodsReceiptItemRequest struct {
 	ItemID           int64           `json:"item_id" binding:"required"`
-	QuantityReceived decimal.Decimal `json:"quantity_received"`
+	QuantityAccepted decimal.Decimal `json:"quantity_accepted"`
+	QuantityRejected decimal.Decimal `json:"quantity_rejected"`
+	RejectionReason  string          `json:"rejection_reason,omitempty" binding:"omitempty,max=500"`
+	BatchNumber      *string         `json:"batch_number,omitempty" binding:"omitempty,max=100"`
+	ExpiryDate       string          `json:"expiry_date,omitempty"` // YYYY-MM-DD
+}
+
+// GoodsReceiptResponse represents a goods receipt in API responses
+type GoodsReceiptResponse struct {
+	ID                int64                      `json:"id"`
+	ProcurementID     int64                      `json:"procurement_id"`
+	WarehouseID       int64                      `json:"warehouse_id"`
+	ReceivedByUserID  *int64                     `json:"received_by_user_id,omitempty"`
+	ReceivedByName    string                     `json:"received_by_name,omitempty"`
+	DeliveryReference string                     `json:"delivery_reference,omitempty"`
+	Notes             string                     `json:"notes,omitempty"`
+	ReceivedAt        time.Time                  `json:"received_at"`
+	Items             []GoodsReceiptItemResponse `json:"items"`
+}
+
+// GoodsReceiptItemResponse represents a goods receipt line in API responses
+type GoodsReceiptItemResponse struct {
+	ID                int64           `json:"id"`
+	ProcurementItemID int64           `json:"procurement_item_id"`
+	VariantID         int64           `json:"variant_id"`
+	VariantName       string          `json:"variant_name,omitempty"`
+	QuantityAccepted  decimal.Decimal `json:"quantity_accepted"`
+	QuantityRejected  decimal.Decimal `json:"quantity_rejected"`
+	RejectionReason   string          `json:"rejection_reason,omitempty"`
+	BatchNumber       *string         `json:"batch_number,omitempty"`
+	ExpiryDate        *time.Time      `json:"expiry_date,omitempty"`
+	LotID             *int64          `json:"lot_id,omitempty"`
 }
 
 // ProcurementResponse represents a procurement in API responses
@@ -56,13 +99,33 @@ type ProcurementResponse struct {
 
 // ProcurementItemResponse represents a procurement item in API responses
 type ProcurementItemResponse struct {
-	ID               int64           `json:"id"`
-	VariantID        int64           `json:"variant_id"`
-	VariantName      string          `json:"variant_name,omitempty"`
-	VariantSKU       string          `json:"variant_sku,omitempty"`
-	VariantUnit      string          `json:"variant_unit,omitempty"`
-	QuantityOrdered  decimal.Decimal `json:"quantity_ordered"`
-	QuantityReceived decimal.Decimal `json:"quantity_received"`
-	UnitCost         decimal.Decimal `json:"unit_cost"`
-	LineTotal        decimal.Decimal `json:"line_total"`
+	ID                  int64            `json:"id"`
+	VariantID           int64            `json:"variant_id"`
+	VariantName         string           `json:"variant_name,omitempty"`
+	VariantSKU          string           `json:"variant_sku,omitempty"`
+	VariantUnit         string           `json:"variant_unit,omitempty"`
+	QuantityOrdered     decimal.Decimal  `json:"quantity_ordered"`
+	QuantityReceived    decimal.Decimal  `json:"quantity_received"`
+	QuantityRejected    decimal.Decimal  `json:"quantity_rejected"`
+	QuantityOutstanding decimal.Decimal  `json:"quantity_outstanding"`
+	UnitCost            decimal.Decimal  `json:"unit_cost"`
+	LineTotal           decimal.Decimal  `json:"line_total"`
+	ListPrice           *decimal.Decimal `json:"list_price,omitempty"`
+	PriceListID         *int64           `json:"price_list_id,omitempty"`
+	AboveListPrice      bool             `json:"above_list_price"`
+	BatchNumber         *string          `json:"batch_number,omitempty"`
+	ExpiryDate          *time.Time       `json:"expiry_date,omitempty"`
+}
+
+// ProcurementStatusEventResponse represents a purchase order status change in API responses
+type ProcurementStatusEventResponse struct {
+	ID              int64           `json:"id"`
+	FromStatus      string          `json:"from_status"`
+	ToStatus        string          `json:"to_status"`
+	Amount          decimal.Decimal `json:"amount"`
+	ChangedByUserID *int64          `json:"changed_by_user_id,omitempty"`
+	ChangedByName   string          `json:"changed_by_name,omitempty"`
+	RoleID          *int64          `json:"role_id,omitempty"`
+	Note            string          `json:"note,omitempty"`
+	ChangedAt       time.Time       `json:"changed_at"`
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // --- Category DTOs ---
 
@@ -111,15 +115,25 @@ type PublicProductResponse struct {
 	ConversionFactor decimal.Decimal `json:"conversion_factor"`
 	Description      string          `json:"description,omitempty"`
 	CategoryName     string          `json:"category_name,omitempty"`
+	// Available is how many units can be ordered: stock on hand less expired and reserved stock
+	Available decimal.Decimal `json:"available_quantity"`
+	InStock   bool            `json:"in_stock"`
 }
 
 // --- Public Order DTOs ---
 
-// PublicCreateOrderRequest represents a request to create an order from the storefront
+// PublicCreateOrderRequest represents a request to create an order from the storefront.
+// Either a saved address ID or an inline address is required. The pincode decides the fulfilling warehouse, minimum order amount and delivery charge.
 type PublicCreateOrderRequest struct {
 	CustomerName  string                  `json:"customer_name" binding:"required"`
 	CustomerPhone string                  `json:"customer_phone" binding:"required"`
-	Address       string                  `json:"address" binding:"required"`
+	AddressID     *int64                  `json:"address_id,omitempty"` // saved address of the signed-in customer
+	Address       string                  `json:"address" binding:"required_without=AddressID"`
+	AddressLine2  string                  `json:"address_line2,omitempty"`
+	Landmark      string                  `json:"landmark,omitempty"`
+	City          string                  `json:"city,omitempty"`
+	Pincode       string                  `json:"pincode" binding:"required_without=AddressID"`
+	PaymentMethod string                  `json:"payment_method,omitempty" binding:"omitempty,oneof=cash upi"`
 	Items         []PublicCreateOrderItem `json:"items" binding:"required,min=1,dive"`
 }
 
@@ -131,7 +145,19 @@ type PublicCreateOrderItem struct {
 
 // PublicOrderResponse represents a summary of a created order for the storefront
 type PublicOrderResponse struct {
-	ID          int64           `json:"id"`
-	Status      string          `json:"status"`
-	TotalAmount decimal.Decimal `json:"total_amount"`
+	ID             int64           `json:"id"`
+	Status         string          `json:"status"`
+	DeliveryCharge decimal.Decimal `json:"delivery_charge"`
+	TotalAmount    decimal.Decimal `json:"total_amount"`
+}
+
+// PublicOrderTrackingResponse represents an order with its status timeline for the storefront
+type PublicOrderTrackingResponse struct {
+	ID          int64                      `json:"id"`
+	Status      string                     `json:"status"`
+	TotalAmount decimal.Decimal            `json:"total_amount"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	Items       []SaleItemResponse         `json:"items"`
+	Delivery    *DeliveryAddressResponse   `json:"delivery_address,omitempty"`
+	Timeline    []OrderStatusEventResponse `json:"timeline"`
 }
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// --- Production DTOs ---
+
+// CreateProductionRunRequest represents a request to record a production run.
+// The warehouse must be a factory and the batch code must be unique.
+type CreateProductionRunRequest struct {
+	WarehouseID int64                        `json:"warehouse_id" binding:"required"`
+	BatchCode   string                       `json:"batch_code" binding:"required,max=100"`
+	ExpiryDate  string                       `json:"expiry_date,omitempty"` // YYYY-MM-DD, of the output lots
+	Logs        []CreateProductionLogRequest `json:"logs" binding:"required,min=1,dive"`
+}
+
+// CreateProductionLogRequest represents one input-to-output conversion within a run
+type CreateProductionLogRequest struct {
+	InputVariantID  int64           `json:"input_variant_id" binding:"required"`
+	InputQty        decimal.Decimal `json:"input_qty"`
+	OutputVariantID int64           `json:"output_variant_id" binding:"required"`
+	OutputQty       decimal.Decimal `json:"output_qty"`
+}
+
+// ProductionRunResponse represents a production run in API responses
+type ProductionRunResponse struct {
+	ID            int64                   `json:"id"`
+	WarehouseID   int64                   `json:"warehouse_id"`
+	WarehouseName string                  `json:"warehouse_name,omitempty"`
+	StaffID       int64                   `json:"staff_id"`
+	StaffName     string                  `json:"staff_name,omitempty"`
+	BatchCode     string                  `json:"batch_code"`
+	ExpiryDate    *time.Time              `json:"expiry_date,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	Logs          []ProductionLogResponse `json:"logs,omitempty"`
+}
+
+// ProductionLogResponse represents a production log in API responses
+type ProductionLogResponse struct {
+	ID                int64           `json:"id"`
+	InputVariantID    int64           `json:"input_variant_id"`
+	InputVariantName  string          `json:"input_variant_name,omitempty"`
+	InputVariantUnit  string          `json:"input_variant_unit,omitempty"`
+	InputQty          decimal.Decimal `json:"input_qty"`
+	OutputVariantID   int64           `json:"output_variant_id"`
+	OutputVariantName string          `json:"output_variant_name,omitempty"`
+	OutputVariantUnit string          `json:"output_variant_unit,omitempty"`
+	OutputQty         decimal.Decimal `json:"output_qty"`
+	YieldPercent      decimal.Decimal `json:"yield_percent"`
+}
+
+// ProductionYieldResponse represents the yield report for a single batch
+type ProductionYieldResponse struct {
+	RunID        int64                   `json:"run_id"`
+	BatchCode    string                  `json:"batch_code"`
+	WarehouseID  int64                   `json:"warehouse_id"`
+	CreatedAt    time.Time               `json:"created_at"`
+	TotalInput   decimal.Decimal         `json:"total_input"`
+	TotalOutput  decimal.Decimal         `json:"total_output"`
+	YieldPercent decimal.Decimal         `json:"yield_percent"`
+	Lines        []ProductionLogResponse `json:"lines"`
+}
+
+// ProductionYieldDeviationResponse represents a production line whose yield is outside recipe tolerance
+type ProductionYieldDeviationResponse struct {
+	RunID          int64                 `json:"run_id"`
+	BatchCode      string                `json:"batch_code"`
+	WarehouseID    int64                 `json:"warehouse_id"`
+	CreatedAt      time.Time             `json:"created_at"`
+	Line           ProductionLogResponse `json:"line"`
+	ActualYield    decimal.Decimal       `json:"actual_yield"`
+	ExpectedYield  decimal.Decimal       `json:"expected_yield"`
+	YieldTolerance decimal.Decimal       `json:"yield_tolerance"`
+	Deviation      decimal.Decimal       `json:"deviation"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// --- Recipe DTOs ---
+
+// RecipeRequest represents a request to create or replace a variant's recipe.
+// Quantities are standard amounts for producing output_qty of the variant;
+// the first item is the primary input that yield is measured against.
+type RecipeRequest struct {
+	OutputQty      decimal.Decimal     `json:"output_qty"`
+	ExpectedYield  decimal.Decimal     `json:"expected_yield"`
+	YieldTolerance decimal.Decimal     `json:"yield_tolerance"`
+	Notes          string              `json:"notes,omitempty"`
+	Items          []RecipeItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// RecipeItemRequest represents an input variant and its standard quantity
+type RecipeItemRequest struct {
+	InputVariantID int64           `json:"input_variant_id" binding:"required"`
+	Quantity       decimal.Decimal `json:"quantity"`
+}
+
+// RecipeResponse represents a recipe in API responses
+type RecipeResponse struct {
+	ID                int64                `json:"id"`
+	OutputVariantID   int64                `json:"output_variant_id"`
+	OutputVariantName string               `json:"output_variant_name,omitempty"`
+	OutputVariantUnit string               `json:"output_variant_unit,omitempty"`
+	OutputQty         decimal.Decimal      `json:"output_qty"`
+	ExpectedYield     decimal.Decimal      `json:"expected_yield"`
+	YieldTolerance    decimal.Decimal      `json:"yield_tolerance"`
+	Notes             string               `json:"notes,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+	Items             []RecipeItemResponse `json:"items"`
+}
+
+// RecipeItemResponse represents a recipe item in API responses
+type RecipeItemResponse struct {
+	ID               int64           `json:"id"`
+	InputVariantID   int64           `json:"input_variant_id"`
+	InputVariantName string          `json:"input_variant_name,omitempty"`
+	InputVariantUnit string          `json:"input_variant_unit,omitempty"`
+	Quantity         decimal.Decimal `json:"quantity"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SetReorderSettingRequest represents the stock control levels of a variant at a warehouse.
+// Levels must satisfy 0 <= min_quantity <= reorder_point < max_quantity.
+type SetReorderSettingRequest struct {
+	WarehouseID  int64           `json:"warehouse_id" binding:"required"`
+	VariantID    int64           `json:"variant_id" binding:"required"`
+	MinQuantity  decimal.Decimal `json:"min_quantity"`
+	ReorderPoint decimal.Decimal `json:"reorder_point"`
+	MaxQuantity  decimal.Decimal `json:"max_quantity"`
+	LeadTimeDays int             `json:"lead_time_days" binding:"min=0"`
+}
+
+// GenerateDraftOrdersRequest represents a request to raise draft purchase orders from the
+// current suggestions, optionally for one warehouse
+type GenerateDraftOrdersRequest struct {
+	WarehouseID *int64 `json:"warehouse_id"`
+}
+
+// ReorderSettingResponse represents a reorder setting in API responses
+type ReorderSettingResponse struct {
+	ID            int64           `json:"id"`
+	WarehouseID   int64           `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name,omitempty"`
+	VariantID     int64           `json:"variant_id"`
+	VariantName   string          `json:"variant_name,omitempty"`
+	SKU           string          `json:"sku,omitempty"`
+	MinQuantity   decimal.Decimal `json:"min_quantity"`
+	ReorderPoint  decimal.Decimal `json:"reorder_point"`
+	MaxQuantity   decimal.Decimal `json:"max_quantity"`
+	LeadTimeDays  int             `json:"lead_time_days"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// ReplenishmentSuggestionResponse represents a variant to reorder at a warehouse
+type ReplenishmentSuggestionResponse struct {
+	WarehouseID       int64            `json:"warehouse_id"`
+	WarehouseName     string           `json:"warehouse_name"`
+	VariantID         int64            `json:"variant_id"`
+	VariantName       string           `json:"variant_name"`
+	SKU               string           `json:"sku"`
+	OnHand            decimal.Decimal  `json:"on_hand"`
+	Reserved          decimal.Decimal  `json:"reserved"`
+	OnOrder           decimal.Decimal  `json:"on_order"`
+	InTransit         decimal.Decimal  `json:"in_transit"`
+	Projected         decimal.Decimal  `json:"projected"`
+	ReorderPoint      decimal.Decimal  `json:"reorder_point"`
+	MaxQuantity       decimal.Decimal  `json:"max_quantity"`
+	LeadTimeDays      int              `json:"lead_time_days"`
+	AvgDailySales     decimal.Decimal  `json:"avg_daily_sales"`
+	DaysOfCover       *decimal.Decimal `json:"days_of_cover,omitempty"`
+	SuggestedQuantity decimal.Decimal  `json:"suggested_quantity"`
+	SupplierID        *int64           `json:"supplier_id,omitempty"`
+	SupplierName      *string          `json:"supplier_name,omitempty"`
+	UnitCost          decimal.Decimal  `json:"unit_cost"`
+}
+
+// ReplenishmentRunResponse represents the draft purchase orders raised from suggestions and
+// the suggestions left out for lack of a preferred supplier
+type ReplenishmentRunResponse struct {
+	Procurements []ProcurementResponse             `json:"procurements"`
+	Unsourced    []ReplenishmentSuggestionResponse `json:"unsourced"`
+}
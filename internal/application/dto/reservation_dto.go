@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReserveRosterRequest represents a request to reserve stock for a day's subscription deliveries.
+// The date defaults to tomorrow.
+type ReserveRosterRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD
+}
+
+// StockReservationResponse represents a stock reservation in API responses
+type StockReservationResponse struct {
+	ID          int64           `json:"id"`
+	WarehouseID int64           `json:"warehouse_id"`
+	VariantID   int64           `json:"variant_id"`
+	VariantName string          `json:"variant_name,omitempty"`
+	SKU         string          `json:"sku,omitempty"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	SourceType  string          `json:"source_type"`
+	ReferenceID int64           `json:"reference_id"`
+	ReservedFor *string         `json:"reserved_for,omitempty"`
+	Status      string          `json:"status"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ClosedAt    *time.Time      `json:"closed_at,omitempty"`
+}
+
+// StockAvailabilityResponse represents the available-to-promise stock of a variant at a warehouse
+type StockAvailabilityResponse struct {
+	WarehouseID int64           `json:"warehouse_id"`
+	VariantID   int64           `json:"variant_id"`
+	VariantName string          `json:"variant_name,omitempty"`
+	SKU         string          `json:"sku,omitempty"`
+	OnHand      decimal.Decimal `json:"on_hand"`
+	Expired     decimal.Decimal `json:"expired"`
+	Reserved    decimal.Decimal `json:"reserved"`
+	Available   decimal.Decimal `json:"available"`
+}
+
+// RosterReservationResponse represents the outcome of reserving stock for a day's roster
+type RosterReservationResponse struct {
+	Date         string                     `json:"date"`
+	Reservations []StockReservationResponse `json:"reservations"`
+	Unassigned   []int64                    `json:"unassigned_subscription_ids"`
+	Short        []int64                    `json:"short_subscription_ids"`
+}
+
+// ExpireReservationsResponse represents the outcome of sweeping lapsed reservations
+type ExpireReservationsResponse struct {
+	Expired int64 `json:"expired"`
+}
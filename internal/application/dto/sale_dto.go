@@ -9,6 +9,7 @@ import (
 // CreateSaleRequest represents a request to record a POS transaction
 type CreateSaleRequest struct {
 	WarehouseID    int64            `json:"warehouse_id" binding:"required"`
+	CustomerID     *int64           `json:"customer_id"` // required for credit sales
 	CustomerName   string           `json:"customer_name"`
 	TaxAmount      decimal.Decimal  `json:"tax_amount"`
 	DiscountAmount decimal.Decimal  `json:"discount_amount"`
@@ -25,27 +26,60 @@ type CreateSaleItem struct {
 
 // SaleResponse represents a sale in API responses
 type SaleResponse struct {
-	ID                int64              `json:"id"`
-	WarehouseID       int64              `json:"warehouse_id"`
-	WarehouseName     string             `json:"warehouse_name,omitempty"`
-	CustomerName      string             `json:"customer_name"`
-	TotalAmount       decimal.Decimal    `json:"total_amount"`
-	TaxAmount         decimal.Decimal    `json:"tax_amount"`
-	DiscountAmount    decimal.Decimal    `json:"discount_amount"`
-	PaymentMethod     string             `json:"payment_method"`
-	ProcessedByUserID int64              `json:"processed_by_user_id"`
-	ProcessedByName   string             `json:"processed_by_name,omitempty"`
-	CreatedAt         time.Time          `json:"created_at"`
-	Items             []SaleItemResponse `json:"items,omitempty"`
+	ID                int64                    `json:"id"`
+	WarehouseID       int64                    `json:"warehouse_id"`
+	WarehouseName     string                   `json:"warehouse_name,omitempty"`
+	CustomerName      string                   `json:"customer_name"`
+	TotalAmount       decimal.Decimal          `json:"total_amount"`
+	TaxAmount         decimal.Decimal          `json:"tax_amount"`
+	DiscountAmount    decimal.Decimal          `json:"discount_amount"`
+	DeliveryCharge    decimal.Decimal          `json:"delivery_charge"`
+	PaymentMethod     string                   `json:"payment_method"`
+	Status            string                   `json:"status"`
+	ZoneID            *int64                   `json:"zone_id,omitempty"`
+	ProcessedByUserID *int64                   `json:"processed_by_user_id,omitempty"`
+	ProcessedByName   string                   `json:"processed_by_name,omitempty"`
+	CreatedAt         time.Time                `json:"created_at"`
+	CostOfGoods       *decimal.Decimal         `json:"cost_of_goods,omitempty"`
+	GrossMargin       *decimal.Decimal         `json:"gross_margin,omitempty"`
+	Items             []SaleItemResponse       `json:"items,omitempty"`
+	DeliveryAddress   *DeliveryAddressResponse `json:"delivery_address,omitempty"`
+}
+
+// DeliveryAddressResponse represents the delivery address of a storefront order
+type DeliveryAddressResponse struct {
+	CustomerID   *int64 `json:"customer_id,omitempty"`
+	ContactName  string `json:"contact_name"`
+	Phone        string `json:"phone"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	Landmark     string `json:"landmark,omitempty"`
+	City         string `json:"city,omitempty"`
+	Pincode      string `json:"pincode"`
 }
 
 // SaleItemResponse represents a sale item in API responses
 type SaleItemResponse struct {
-	ID          int64           `json:"id"`
-	VariantID   int64           `json:"variant_id"`
-	VariantName string          `json:"variant_name,omitempty"`
-	VariantSKU  string          `json:"variant_sku,omitempty"`
-	Quantity    decimal.Decimal `json:"quantity"`
-	UnitPrice   decimal.Decimal `json:"unit_price"`
-	LineTotal   decimal.Decimal `json:"line_total"`
+	ID          int64            `json:"id"`
+	VariantID   int64            `json:"variant_id"`
+	VariantName string           `json:"variant_name,omitempty"`
+	VariantSKU  string           `json:"variant_sku,omitempty"`
+	Quantity    decimal.Decimal  `json:"quantity"`
+	UnitPrice   decimal.Decimal  `json:"unit_price"`
+	LineTotal   decimal.Decimal  `json:"line_total"`
+	CostOfGoods *decimal.Decimal `json:"cost_of_goods,omitempty"`
+}
+
+// UpdateOrderStatusRequest represents a request to move an order to its next status
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=placed confirmed packed out_for_delivery delivered cancelled returned"`
+	Note   string `json:"note,omitempty"`
+}
+
+// OrderStatusEventResponse represents one entry in an order's status timeline
+type OrderStatusEventResponse struct {
+	Status          string    `json:"status"`
+	Note            string    `json:"note,omitempty"`
+	ChangedByUserID *int64    `json:"changed_by_user_id,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
 }
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// StartStockTakeRequest represents a request to open a stock-take session.
+// Scope is full (every stocked variant) or cycle (the due items of one ABC class).
+type StartStockTakeRequest struct {
+	WarehouseID int64   `json:"warehouse_id" binding:"required"`
+	Scope       string  `json:"scope" binding:"required,oneof=full cycle"`
+	ABCClass    *string `json:"abc_class,omitempty" binding:"omitempty,oneof=A B C"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// RecordStockCountRequest represents a counted quantity for one variant, identified by
+// variant_id or barcode. Mode add accumulates scans; set (default) replaces the count.
+type RecordStockCountRequest struct {
+	VariantID *int64          `json:"variant_id,omitempty"`
+	Barcode   string          `json:"barcode,omitempty"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Mode      string          `json:"mode,omitempty" binding:"omitempty,oneof=set add"`
+}
+
+// SetCycleScheduleRequest represents how often an ABC class is counted at a warehouse
+type SetCycleScheduleRequest struct {
+	WarehouseID  int64  `json:"warehouse_id" binding:"required"`
+	ABCClass     string `json:"abc_class" binding:"required,oneof=A B C"`
+	IntervalDays int    `json:"interval_days" binding:"required,min=1"`
+}
+
+// StockTakeResponse represents a stock-take session in API responses
+type StockTakeResponse struct {
+	ID              int64                   `json:"id"`
+	WarehouseID     int64                   `json:"warehouse_id"`
+	WarehouseName   string                  `json:"warehouse_name,omitempty"`
+	Scope           entity.StockTakeScope   `json:"scope"`
+	ABCClass        *entity.ABCClass        `json:"abc_class,omitempty"`
+	Status          entity.StockTakeStatus  `json:"status"`
+	Notes           *string                 `json:"notes,omitempty"`
+	StartedByUserID *int64                  `json:"started_by_user_id,omitempty"`
+	StartedAt       time.Time               `json:"started_at"`
+	ClosedByUserID  *int64                  `json:"closed_by_user_id,omitempty"`
+	ClosedAt        *time.Time              `json:"closed_at,omitempty"`
+	Lines           []StockTakeLineResponse `json:"lines,omitempty"`
+}
+
+// StockTakeLineResponse represents the expected and counted quantity of a variant
+type StockTakeLineResponse struct {
+	ID               int64            `json:"id"`
+	VariantID        int64            `json:"variant_id"`
+	VariantName      string           `json:"variant_name"`
+	SKU              string           `json:"sku"`
+	ExpectedQuantity decimal.Decimal  `json:"expected_quantity"`
+	MovedQuantity    decimal.Decimal  `json:"moved_quantity"`
+	BookQuantity     decimal.Decimal  `json:"book_quantity"`
+	CountedQuantity  *decimal.Decimal `json:"counted_quantity,omitempty"`
+	Variance         decimal.Decimal  `json:"variance"`
+	VarianceValue    decimal.Decimal  `json:"variance_value"`
+	CountedByUserID  *int64           `json:"counted_by_user_id,omitempty"`
+	CountedAt        *time.Time       `json:"counted_at,omitempty"`
+	AdjustmentID     *int64           `json:"adjustment_id,omitempty"`
+}
+
+// StockTakeVarianceResponse represents the variance report of a session
+type StockTakeVarianceResponse struct {
+	StockTake      StockTakeResponse `json:"stock_take"`
+	CountedLines   int               `json:"counted_lines"`
+	UncountedLines int               `json:"uncounted_lines"`
+	VarianceLines  int               `json:"variance_lines"`
+	ShortageValue  decimal.Decimal   `json:"shortage_value"`
+	SurplusValue   decimal.Decimal   `json:"surplus_value"`
+	NetValue       decimal.Decimal   `json:"net_value"`
+}
+
+// CycleCountItemResponse represents a variant's ABC class and count due date
+type CycleCountItemResponse struct {
+	VariantID        int64           `json:"variant_id"`
+	VariantName      string          `json:"variant_name"`
+	SKU              string          `json:"sku"`
+	ConsumptionValue decimal.Decimal `json:"consumption_value"`
+	ABCClass         entity.ABCClass `json:"abc_class"`
+	LastCountedAt    *time.Time      `json:"last_counted_at,omitempty"`
+	DueAt            time.Time       `json:"due_at"`
+	IsDue            bool            `json:"is_due"`
+}
+
+// CycleCountPlanResponse represents the cycle count plan of a warehouse
+type CycleCountPlanResponse struct {
+	WarehouseID int64                        `json:"warehouse_id"`
+	Schedules   []CycleCountScheduleResponse `json:"schedules"`
+	Items       []CycleCountItemResponse     `json:"items"`
+}
+
+// CycleCountScheduleResponse represents how often an ABC class is counted
+type CycleCountScheduleResponse struct {
+	ABCClass     entity.ABCClass `json:"abc_class"`
+	IntervalDays int             `json:"interval_days"`
+}
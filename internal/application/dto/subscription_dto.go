@@ -1,11 +1,16 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // SubscriptionItemRequest is a line item within a create/update subscription request
 type SubscriptionItemRequest struct {
-	VariantID int64   `json:"variant_id" binding:"required"`
-	Quantity  float64 `json:"quantity" binding:"required,gt=0"`
+	VariantID int64    `json:"variant_id" binding:"required"`
+	Quantity  float64  `json:"quantity" binding:"required,gt=0"`
+	UnitPrice *float64 `json:"unit_price" binding:"omitempty,gte=0"` // locks in the billed price; defaults to the variant's selling price
 }
 
 // CreateSubscriptionRequest is the payload for creating a new customer subscription
@@ -15,6 +20,9 @@ type CreateSubscriptionRequest struct {
 	StartDate            string                    `json:"start_date" binding:"required"` // "YYYY-MM-DD"
 	EndDate              *string                   `json:"end_date"`
 	DeliveryInstructions *string                   `json:"delivery_instructions"`
+	AddressID            *int64                    `json:"address_id"`
+	DeliveryWeekdays     []int                     `json:"delivery_weekdays" binding:"omitempty,dive,min=0,max=6"` // weekly plans, 0 = Sunday
+	DeliveryDayOfMonth   *int                      `json:"delivery_day_of_month" binding:"omitempty,min=1,max=31"` // monthly plans
 	Items                []SubscriptionItemRequest `json:"items" binding:"required,min=1,dive"`
 }
 
@@ -24,6 +32,9 @@ type UpdateSubscriptionRequest struct {
 	StartDate            string                    `json:"start_date"`
 	EndDate              *string                   `json:"end_date"`
 	DeliveryInstructions *string                   `json:"delivery_instructions"`
+	AddressID            *int64                    `json:"address_id"`
+	DeliveryWeekdays     []int                     `json:"delivery_weekdays" binding:"omitempty,dive,min=0,max=6"` // replaces the weekdays when present
+	DeliveryDayOfMonth   *int                      `json:"delivery_day_of_month" binding:"omitempty,min=1,max=31"`
 	Items                []SubscriptionItemRequest `json:"items" binding:"omitempty,min=1,dive"`
 }
 
@@ -34,12 +45,13 @@ type UpdateSubscriptionStatusRequest struct {
 
 // SubscriptionItemResponse represents a line item in a subscription response
 type SubscriptionItemResponse struct {
-	ID          int64   `json:"id"`
-	VariantID   int64   `json:"variant_id"`
-	VariantName string  `json:"variant_name"`
-	FamilyName  string  `json:"family_name"`
-	Unit        string  `json:"unit"`
-	Quantity    float64 `json:"quantity"`
+	ID          int64    `json:"id"`
+	VariantID   int64    `json:"variant_id"`
+	VariantName string   `json:"variant_name"`
+	FamilyName  string   `json:"family_name"`
+	Unit        string   `json:"unit"`
+	Quantity    float64  `json:"quantity"`
+	UnitPrice   *float64 `json:"unit_price,omitempty"`
 }
 
 // SubscriptionResponse is the full response shape for a subscription
@@ -52,6 +64,9 @@ type SubscriptionResponse struct {
 	StartDate            time.Time                  `json:"start_date"`
 	EndDate              *time.Time                 `json:"end_date,omitempty"`
 	DeliveryInstructions *string                    `json:"delivery_instructions,omitempty"`
+	AddressID            *int64                     `json:"address_id,omitempty"`
+	DeliveryWeekdays     []int                      `json:"delivery_weekdays,omitempty"`
+	DeliveryDayOfMonth   *int                       `json:"delivery_day_of_month,omitempty"`
 	Items                []SubscriptionItemResponse `json:"items"`
 	CreatedAt            time.Time                  `json:"created_at"`
 	UpdatedAt            time.Time                  `json:"updated_at"`
@@ -87,3 +102,88 @@ type DailyRosterItemResponse struct {
 	Subscription SubscriptionResponse          `json:"subscription"`
 	Delivery     *SubscriptionDeliveryResponse `json:"delivery,omitempty"`
 }
+
+// CreateSubscriptionPauseRequest is the payload for putting a subscription on hold for a date range
+type CreateSubscriptionPauseRequest struct {
+	StartDate string  `json:"start_date" binding:"required"` // "YYYY-MM-DD"
+	EndDate   string  `json:"end_date" binding:"required"`   // "YYYY-MM-DD", inclusive
+	Reason    *string `json:"reason"`
+}
+
+// SubscriptionPauseResponse represents a subscription pause window
+type SubscriptionPauseResponse struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	Reason         *string   `json:"reason,omitempty"`
+	CreatedBy      *int64    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SetSubscriptionOverrideRequest is the payload for a one-off quantity on a single date
+type SetSubscriptionOverrideRequest struct {
+	Date      string  `json:"date" binding:"required"` // "YYYY-MM-DD"
+	VariantID int64   `json:"variant_id" binding:"required"`
+	Quantity  float64 `json:"quantity" binding:"gte=0"` // 0 skips the product that day
+	Notes     *string `json:"notes"`
+}
+
+// SubscriptionOverrideResponse represents a one-off quantity override
+type SubscriptionOverrideResponse struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	DeliveryDate   time.Time `json:"delivery_date"`
+	VariantID      int64     `json:"variant_id"`
+	Quantity       float64   `json:"quantity"`
+	Notes          *string   `json:"notes,omitempty"`
+	CreatedBy      *int64    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RunSubscriptionBillingRequest is the payload for invoicing subscription deliveries for a month
+type RunSubscriptionBillingRequest struct {
+	Period     string `json:"period" binding:"required"` // "YYYY-MM"
+	CustomerID *int64 `json:"customer_id"`               // bill a single customer; all customers when omitted
+}
+
+// SubscriptionInvoiceLineResponse is one delivered product on one day of an invoice
+type SubscriptionInvoiceLineResponse struct {
+	ID             int64           `json:"id"`
+	SubscriptionID *int64          `json:"subscription_id,omitempty"`
+	DeliveryID     *int64          `json:"delivery_id,omitempty"`
+	DeliveryDate   time.Time       `json:"delivery_date"`
+	VariantID      int64           `json:"variant_id"`
+	VariantName    string          `json:"variant_name,omitempty"`
+	Unit           string          `json:"unit,omitempty"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	LineTotal      decimal.Decimal `json:"line_total"`
+}
+
+// SubscriptionInvoiceResponse is the response shape for a subscription invoice
+type SubscriptionInvoiceResponse struct {
+	ID            int64                             `json:"id"`
+	InvoiceNumber string                            `json:"invoice_number"`
+	Sequence      int                               `json:"sequence"`
+	CustomerID    int64                             `json:"customer_id"`
+	CustomerName  string                            `json:"customer_name,omitempty"`
+	PeriodStart   time.Time                         `json:"period_start"`
+	PeriodEnd     time.Time                         `json:"period_end"`
+	TotalAmount   decimal.Decimal                   `json:"total_amount"`
+	AmountPaid    decimal.Decimal                   `json:"amount_paid"`
+	BalanceDue    decimal.Decimal                   `json:"balance_due"`
+	Status        string                            `json:"status"`
+	DueDate       *time.Time                        `json:"due_date,omitempty"`
+	Lines         []SubscriptionInvoiceLineResponse `json:"lines,omitempty"`
+	CreatedAt     time.Time                         `json:"created_at"`
+}
+
+// SubscriptionBillingRunResponse summarizes a billing run
+type SubscriptionBillingRunResponse struct {
+	PeriodStart         time.Time                     `json:"period_start"`
+	PeriodEnd           time.Time                     `json:"period_end"`
+	Generated           []SubscriptionInvoiceResponse `json:"generated"`
+	SkippedCustomerIDs  []int64                       `json:"skipped_customer_ids"`
+	TotalInvoicedAmount decimal.Decimal               `json:"total_invoiced_amount"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // --- Supplier DTOs ---
 
@@ -53,3 +57,217 @@ type SupplierVariantResponse struct {
 	AgreedCost  decimal.Decimal         `json:"agreed_cost"`
 	IsPreferred bool                    `json:"is_preferred"`
 }
+
+// --- Supplier Payables DTOs ---
+
+// BillProcurementRequest represents a request to bill the goods accepted against a purchase order
+type BillProcurementRequest struct {
+	ProcurementID int64  `json:"procurement_id" binding:"required"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=100"`
+	BillDate      string `json:"bill_date,omitempty"` // YYYY-MM-DD, defaults to today
+	DueDate       string `json:"due_date,omitempty"`  // YYYY-MM-DD
+	Notes         string `json:"notes,omitempty"`
+}
+
+// BillCollectionsRequest represents a request to bill a supplier's collections over a period
+type BillCollectionsRequest struct {
+	SupplierID int64  `json:"supplier_id" binding:"required"`
+	StartDate  string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate    string `json:"end_date" binding:"required"`   // YYYY-MM-DD, inclusive
+	Reference  string `json:"reference,omitempty" binding:"omitempty,max=100"`
+	BillDate   string `json:"bill_date,omitempty"` // YYYY-MM-DD, defaults to today
+	DueDate    string `json:"due_date,omitempty"`  // YYYY-MM-DD
+	Notes      string `json:"notes,omitempty"`
+}
+
+// SupplierBillResponse represents a supplier bill in API responses
+type SupplierBillResponse struct {
+	ID            int64                      `json:"id"`
+	SupplierID    int64                      `json:"supplier_id"`
+	SupplierName  string                     `json:"supplier_name,omitempty"`
+	Source        string                     `json:"source"`
+	ProcurementID *int64                     `json:"procurement_id,omitempty"`
+	PeriodStart   *time.Time                 `json:"period_start,omitempty"`
+	PeriodEnd     *time.Time                 `json:"period_end,omitempty"`
+	Reference     string                     `json:"reference,omitempty"`
+	BillDate      time.Time                  `json:"bill_date"`
+	DueDate       *time.Time                 `json:"due_date,omitempty"`
+	Amount        decimal.Decimal            `json:"amount"`
+	AmountPaid    decimal.Decimal            `json:"amount_paid"`
+	BalanceDue    decimal.Decimal            `json:"balance_due"`
+	Status        string                     `json:"status"`
+	Notes         string                     `json:"notes,omitempty"`
+	Lines         []SupplierBillLineResponse `json:"lines,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+}
+
+// SupplierBillLineResponse represents a billed receipt line or collection in API responses
+type SupplierBillLineResponse struct {
+	ID                 int64           `json:"id"`
+	GoodsReceiptItemID *int64          `json:"goods_receipt_item_id,omitempty"`
+	CollectionID       *int64          `json:"collection_id,omitempty"`
+	VariantID          int64           `json:"variant_id"`
+	VariantName        string          `json:"variant_name,omitempty"`
+	Unit               string          `json:"unit,omitempty"`
+	LineDate           time.Time       `json:"line_date"`
+	Quantity           decimal.Decimal `json:"quantity"`
+	UnitPrice          decimal.Decimal `json:"unit_price"`
+	LineTotal          decimal.Decimal `json:"line_total"`
+}
+
+// RecordSupplierPaymentRequest represents a payment made to a supplier, against a bill or on account
+type RecordSupplierPaymentRequest struct {
+	SupplierID    int64           `json:"supplier_id" binding:"required"`
+	BillID        *int64          `json:"bill_id,omitempty"`
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	PaymentMethod string          `json:"payment_method" binding:"omitempty,oneof=cash upi bank_transfer cheque"`
+	Reference     string          `json:"reference,omitempty" binding:"omitempty,max=100"`
+	Notes         string          `json:"notes,omitempty"`
+	PaidAt        string          `json:"paid_at,omitempty"` // YYYY-MM-DD, defaults to now
+}
+
+// SupplierPaymentResponse represents a recorded supplier payment
+type SupplierPaymentResponse struct {
+	ID            int64           `json:"id"`
+	SupplierID    int64           `json:"supplier_id"`
+	BillID        *int64          `json:"bill_id,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	PaymentMethod string          `json:"payment_method"`
+	Reference     string          `json:"reference,omitempty"`
+	Notes         string          `json:"notes,omitempty"`
+	PaidAt        time.Time       `json:"paid_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// SupplierLedgerEntryResponse represents a supplier ledger line with the running amount owed
+type SupplierLedgerEntryResponse struct {
+	ID          int64           `json:"id"`
+	EntryType   string          `json:"entry_type"`
+	BillID      *int64          `json:"bill_id,omitempty"`
+	PaymentID   *int64          `json:"payment_id,omitempty"`
+	Description string          `json:"description,omitempty"`
+	EntryDate   time.Time       `json:"entry_date"`
+	DueDate     *time.Time      `json:"due_date,omitempty"`
+	Debit       decimal.Decimal `json:"debit"`
+	Credit      decimal.Decimal `json:"credit"`
+	Balance     decimal.Decimal `json:"balance"`
+}
+
+// SupplierStatementResponse represents a supplier's statement for a date range
+type SupplierStatementResponse struct {
+	SupplierID     int64                         `json:"supplier_id"`
+	SupplierName   string                        `json:"supplier_name"`
+	StartDate      string                        `json:"start_date"`
+	EndDate        string                        `json:"end_date"`
+	OpeningBalance decimal.Decimal               `json:"opening_balance"`
+	TotalDebit     decimal.Decimal               `json:"total_debit"`
+	TotalCredit    decimal.Decimal               `json:"total_credit"`
+	ClosingBalance decimal.Decimal               `json:"closing_balance"`
+	Entries        []SupplierLedgerEntryResponse `json:"entries"`
+}
+
+// SupplierAgingResponse represents the amount owed to a supplier by age bucket
+type SupplierAgingResponse struct {
+	SupplierID   int64           `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name"`
+	Balance      decimal.Decimal `json:"balance"`
+	Days0To15    decimal.Decimal `json:"days_0_15"`
+	Days16To30   decimal.Decimal `json:"days_16_30"`
+	Days31To60   decimal.Decimal `json:"days_31_60"`
+	Over60       decimal.Decimal `json:"over_60"`
+}
+
+// --- Supplier Scorecard DTOs ---
+
+// SupplierScorecardResponse represents a supplier's performance over a period. Rates are fractions
+// between 0 and 1; the score is out of 100.
+type SupplierScorecardResponse struct {
+	SupplierID   int64  `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+
+	OnTimeRate        *decimal.Decimal `json:"on_time_rate"`
+	FillRate          *decimal.Decimal `json:"fill_rate"`
+	PriceVariance     *decimal.Decimal `json:"price_variance"`
+	RejectionRate     *decimal.Decimal `json:"rejection_rate"`
+	VolumeConsistency *decimal.Decimal `json:"volume_consistency"`
+	Score             *decimal.Decimal `json:"score"`
+
+	Receipts          int             `json:"receipts"`
+	OnTimeReceipts    int             `json:"on_time_receipts"`
+	QuantityOrdered   decimal.Decimal `json:"quantity_ordered"`
+	QuantityReceived  decimal.Decimal `json:"quantity_received"`
+	QuantityAccepted  decimal.Decimal `json:"quantity_accepted"`
+	QuantityRejected  decimal.Decimal `json:"quantity_rejected"`
+	AgreedValue       decimal.Decimal `json:"agreed_value"`
+	OrderedValue      decimal.Decimal `json:"ordered_value"`
+	Collections       int             `json:"collections"`
+	AdulteratedCount  int             `json:"adulterated_collections"`
+	CollectionDays    int             `json:"collection_days"`
+	PeriodDays        int             `json:"period_days"`
+	DailyVolumeMean   decimal.Decimal `json:"daily_volume_mean"`
+	DailyVolumeStdDev decimal.Decimal `json:"daily_volume_stddev"`
+}
+
+// SupplierRankingResponse represents a supplier of a variant in score order
+type SupplierRankingResponse struct {
+	Rank        int                        `json:"rank"`
+	SupplierID  int64                      `json:"supplier_id"`
+	Supplier    string                     `json:"supplier_name"`
+	AgreedCost  decimal.Decimal            `json:"agreed_cost"`
+	IsPreferred bool                       `json:"is_preferred"`
+	Scorecard   *SupplierScorecardResponse `json:"scorecard,omitempty"`
+}
+
+// --- Supplier Price List DTOs ---
+
+// CreateSupplierPriceListRequest represents a dated price list for a variant the supplier supplies.
+// Tiers are quantity breaks and must include a base tier from quantity 0.
+type CreateSupplierPriceListRequest struct {
+	VariantID int64                      `json:"variant_id" binding:"required"`
+	ValidFrom string                     `json:"valid_from" binding:"required"` // YYYY-MM-DD
+	ValidTo   string                     `json:"valid_to,omitempty"`            // YYYY-MM-DD, inclusive; open-ended when empty
+	Notes     string                     `json:"notes,omitempty"`
+	Tiers     []SupplierPriceTierRequest `json:"tiers" binding:"required,min=1,dive"`
+}
+
+// SupplierPriceTierRequest represents a quantity break on a price list
+type SupplierPriceTierRequest struct {
+	MinQuantity decimal.Decimal `json:"min_quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price" binding:"required"`
+}
+
+// SupplierPriceListResponse represents a supplier price list in API responses
+type SupplierPriceListResponse struct {
+	ID           int64                       `json:"id"`
+	SupplierID   int64                       `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name,omitempty"`
+	VariantID    int64                       `json:"variant_id"`
+	VariantName  string                      `json:"variant_name,omitempty"`
+	ValidFrom    string                      `json:"valid_from"`
+	ValidTo      string                      `json:"valid_to,omitempty"`
+	Notes        string                      `json:"notes,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	Tiers        []SupplierPriceTierResponse `json:"tiers"`
+}
+
+// SupplierPriceTierResponse represents a quantity break on a price list
+type SupplierPriceTierResponse struct {
+	ID          int64           `json:"id"`
+	MinQuantity decimal.Decimal `json:"min_quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+}
+
+// SupplierPriceResponse represents the price a supplier charges for a quantity on a date
+type SupplierPriceResponse struct {
+	SupplierID  int64           `json:"supplier_id"`
+	VariantID   int64           `json:"variant_id"`
+	Date        string          `json:"date"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	MinQuantity decimal.Decimal `json:"min_quantity"`
+	PriceListID *int64          `json:"price_list_id,omitempty"`
+	ValidFrom   string          `json:"valid_from,omitempty"`
+	ValidTo     string          `json:"valid_to,omitempty"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // --- Auth DTOs ---
 
@@ -16,7 +20,7 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Password string `json:"password" binding:"required,min=5"`
 	FullName string `json:"full_name" binding:"required"`
-	Phone    string `json:"phone,omitempty"`
+	Phone    string `json:"phone" binding:"required"` // links the account to its customer record
 	Address  string `json:"address,omitempty"`
 }
 
@@ -93,12 +97,19 @@ type UpdateRoleRequest struct {
 	PermissionIDs []int64 `json:"permission_ids,omitempty"`
 }
 
+// SetRoleApprovalLimitRequest represents a request to set the highest purchase order total a
+// role may approve. A null limit lets the role approve orders of any amount.
+type SetRoleApprovalLimitRequest struct {
+	POApprovalLimit *decimal.Decimal `json:"po_approval_limit"`
+}
+
 // RoleResponse represents a role in API responses
 type RoleResponse struct {
-	ID          int64                `json:"id"`
-	Name        string               `json:"name"`
-	Description string               `json:"description,omitempty"`
-	Permissions []PermissionResponse `json:"permissions,omitempty"`
+	ID              int64                `json:"id"`
+	Name            string               `json:"name"`
+	Description     string               `json:"description,omitempty"`
+	POApprovalLimit *decimal.Decimal     `json:"po_approval_limit,omitempty"`
+	Permissions     []PermissionResponse `json:"permissions,omitempty"`
 }
 
 // PermissionResponse represents a permission in API responses
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// CollectionRouteService plans agents' collection routes and builds their shift sheets
+type CollectionRouteService struct {
+	routeRepo      repository.CollectionRouteRepository
+	collectionRepo repository.CollectionRepository
+	supplierRepo   repository.SupplierRepository
+	userRepo       repository.UserRepository
+	warehouseRepo  repository.WarehouseRepository
+}
+
+// NewCollectionRouteService creates a new collection route service
+func NewCollectionRouteService(
+	routeRepo repository.CollectionRouteRepository,
+	collectionRepo repository.CollectionRepository,
+	supplierRepo repository.SupplierRepository,
+	userRepo repository.UserRepository,
+	warehouseRepo repository.WarehouseRepository,
+) *CollectionRouteService {
+	return &CollectionRouteService{
+		routeRepo:      routeRepo,
+		collectionRepo: collectionRepo,
+		supplierRepo:   supplierRepo,
+		userRepo:       userRepo,
+		warehouseRepo:  warehouseRepo,
+	}
+}
+
+// CreateRoute creates a route for an agent. The stops follow the given suppliers in order or,
+// without any, every supplier of the route's zone.
+func (s *CollectionRouteService) CreateRoute(ctx context.Context, route *entity.CollectionRoute, supplierIDs []int64) error {
+	if err := s.validateRoute(ctx, route); err != nil {
+		return err
+	}
+	stops, err := s.buildStops(ctx, route, supplierIDs)
+	if err != nil {
+		return err
+	}
+	route.Stops = stops
+	return s.routeRepo.Create(ctx, route)
+}
+
+// GetRoute retrieves a route with its stops
+func (s *CollectionRouteService) GetRoute(ctx context.Context, id int64) (*entity.CollectionRoute, error) {
+	return s.routeRepo.GetByID(ctx, id)
+}
+
+// ListRoutes retrieves routes, optionally only those of one agent
+func (s *CollectionRouteService) ListRoutes(ctx context.Context, agentID *int64, activeOnly bool) ([]entity.CollectionRoute, error) {
+	return s.routeRepo.List(ctx, agentID, activeOnly)
+}
+
+// UpdateRoute saves a route's name, agent, zone, warehouse and active flag; its stops are unchanged
+func (s *CollectionRouteService) UpdateRoute(ctx context.Context, route *entity.CollectionRoute) error {
+	if err := s.validateRoute(ctx, route); err != nil {
+		return err
+	}
+	return s.routeRepo.Update(ctx, route)
+}
+
+// SetStops reorders a route to visit the given suppliers in order. Without any suppliers the
+// route is rebuilt from its zone.
+func (s *CollectionRouteService) SetStops(ctx context.Context, routeID int64, supplierIDs []int64) (*entity.CollectionRoute, error) {
+	route, err := s.routeRepo.GetByID(ctx, routeID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := s.buildStops(ctx, route, supplierIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.routeRepo.SetStops(ctx, routeID, stops); err != nil {
+		return nil, err
+	}
+	return s.routeRepo.GetByID(ctx, routeID)
+}
+
+// ShiftSheet lists a route's stops in order for one shift, each with the collections recorded
+// from that supplier during the shift, whichever agent recorded them
+func (s *CollectionRouteService) ShiftSheet(ctx context.Context, routeID int64, date time.Time, shift entity.CollectionShift) (*entity.ShiftSheet, error) {
+	if !shift.IsValid() {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	route, err := s.routeRepo.GetByID(ctx, routeID)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &entity.ShiftSheet{Route: route, Date: date, Shift: shift, Rows: []entity.ShiftSheetRow{}}
+	if len(route.Stops) == 0 {
+		return sheet, nil
+	}
+
+	supplierIDs := make([]int64, len(route.Stops))
+	for i, stop := range route.Stops {
+		supplierIDs[i] = stop.SupplierID
+	}
+	to := date.AddDate(0, 0, 1)
+	collections, err := s.collectionRepo.ListAll(ctx, repository.CollectionFilter{
+		SupplierIDs: supplierIDs,
+		Shift:       &shift,
+		From:        &date,
+		To:          &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bySupplier := make(map[int64][]entity.Collection)
+	for _, c := range collections {
+		bySupplier[c.SupplierID] = append(bySupplier[c.SupplierID], c)
+	}
+	for _, stop := range route.Stops {
+		row := entity.ShiftSheetRow{Stop: stop, Collections: bySupplier[stop.SupplierID]}
+		if row.Collections == nil {
+			row.Collections = []entity.Collection{}
+		}
+		if row.IsCollected() {
+			sheet.Collected++
+		} else {
+			sheet.Pending++
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheet, nil
+}
+
+func (s *CollectionRouteService) validateRoute(ctx context.Context, route *entity.CollectionRoute) error {
+	route.Name = strings.TrimSpace(route.Name)
+	if route.Name == "" {
+		return domainErrors.ErrInvalidInput
+	}
+	if _, err := s.userRepo.GetByID(ctx, route.AgentID); err != nil {
+		return domainErrors.ErrUserNotFound
+	}
+	if _, err := s.warehouseRepo.GetByID(ctx, route.WarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// buildStops numbers the given suppliers in order or, without any, the suppliers of the route's zone
+func (s *CollectionRouteService) buildStops(ctx context.Context, route *entity.CollectionRoute, supplierIDs []int64) ([]entity.CollectionRouteStop, error) {
+	if len(supplierIDs) == 0 && route.ZoneID != nil {
+		suppliers, err := s.supplierRepo.ListByZone(ctx, *route.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		for _, supplier := range suppliers {
+			supplierIDs = append(supplierIDs, supplier.ID)
+		}
+		return numberStops(supplierIDs), nil
+	}
+
+	seen := make(map[int64]bool, len(supplierIDs))
+	for _, id := range supplierIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("supplier %d is listed twice: %w", id, domainErrors.ErrInvalidInput)
+		}
+		seen[id] = true
+		if _, err := s.supplierRepo.GetByID(ctx, id); err != nil {
+			return nil, domainErrors.ErrSupplierNotFound
+		}
+	}
+	return numberStops(supplierIDs), nil
+}
+
+func numberStops(supplierIDs []int64) []entity.CollectionRouteStop {
+	stops := make([]entity.CollectionRouteStop, len(supplierIDs))
+	for i, id := range supplierIDs {
+		stops[i] = entity.CollectionRouteStop{Sequence: i + 1, SupplierID: id}
+	}
+	return stops
+}
@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 	"github.com/qwikshelf/api/internal/domain/repository"
@@ -11,25 +16,34 @@ import (
 
 type CollectionService struct {
 	collectionRepo repository.CollectionRepository
+	rateChartRepo  repository.CollectionRateChartRepository
+	routeRepo      repository.CollectionRouteRepository
 	inventoryRepo  repository.InventoryRepository
 	variantRepo    repository.ProductVariantRepository
 	warehouseRepo  repository.WarehouseRepository
 	supplierRepo   repository.SupplierRepository
+	txManager      repository.TxManager
 }
 
 func NewCollectionService(
 	collectionRepo repository.CollectionRepository,
+	rateChartRepo repository.CollectionRateChartRepository,
+	routeRepo repository.CollectionRouteRepository,
 	inventoryRepo repository.InventoryRepository,
 	variantRepo repository.ProductVariantRepository,
 	warehouseRepo repository.WarehouseRepository,
 	supplierRepo repository.SupplierRepository,
+	txManager repository.TxManager,
 ) *CollectionService {
 	return &CollectionService{
 		collectionRepo: collectionRepo,
+		rateChartRepo:  rateChartRepo,
+		routeRepo:      routeRepo,
 		inventoryRepo:  inventoryRepo,
 		variantRepo:    variantRepo,
 		warehouseRepo:  warehouseRepo,
 		supplierRepo:   supplierRepo,
+		txManager:      txManager,
 	}
 }
 
@@ -38,7 +52,8 @@ func (s *CollectionService) RecordCollection(ctx context.Context, collection *en
 	if _, err := s.variantRepo.GetByID(ctx, collection.VariantID); err != nil {
 		return domainErrors.ErrProductVariantNotFound
 	}
-	if _, err := s.supplierRepo.GetByID(ctx, collection.SupplierID); err != nil {
+	supplier, err := s.supplierRepo.GetByID(ctx, collection.SupplierID)
+	if err != nil {
 		return domainErrors.ErrSupplierNotFound
 	}
 
@@ -55,21 +70,212 @@ func (s *CollectionService) RecordCollection(ctx context.Context, collection *en
 	if collection.CollectedAt.IsZero() {
 		collection.CollectedAt = time.Now()
 	}
+	if collection.Shift == "" {
+		collection.Shift = entity.ShiftAt(collection.CollectedAt)
+	}
+	if err := validateQuality(collection); err != nil {
+		return err
+	}
 
-	// 4. Record the collection
-	if err := s.collectionRepo.Create(ctx, collection); err != nil {
+	// 4. Price the milk from the rate chart when its fat and SNF were measured
+	if err := s.priceCollection(ctx, collection, supplier); err != nil {
 		return err
 	}
+	unitCost, err := s.receiptCost(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	// 5. Record the collection and update inventory in one transaction
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.collectionRepo.Create(ctx, collection); err != nil {
+			return err
+		}
+		// Adulterated milk is rejected at the gate: it is recorded and paid at zero, but never
+		// becomes stock that could be sold, reserved or used in production
+		if collection.IsAdulterated() {
+			return nil
+		}
+
+		// 6. Update inventory in the Main Warehouse (Add weight as quantity)
+		// Note: We use the weight recorded by the agent as the quantity increase.
+		movement := entity.NewInventoryMovement(collection.WarehouseID, collection.VariantID, collection.Weight,
+			entity.MovementSourceCollection, &collection.ID, &collection.AgentID)
+		movement.UnitCost = unitCost
+		if collection.BatchNumber == nil || *collection.BatchNumber == "" {
+			batch := fmt.Sprintf("COL%d", collection.ID)
+			collection.BatchNumber = &batch
+		}
+		lot := &entity.InventoryLot{
+			BatchNumber: *collection.BatchNumber,
+			ExpiryDate:  collection.ExpiryDate,
+			SupplierID:  &collection.SupplierID,
+		}
+		return receiveIntoLot(ctx, s.inventoryRepo, movement, lot)
+	})
+}
+
+// RecordShift records a whole shift of collections at once; either all of them are recorded or
+// none is. With a route, every supplier must be a stop on it and the warehouse defaults to the route's.
+func (s *CollectionService) RecordShift(ctx context.Context, routeID *int64, collections []*entity.Collection) error {
+	if len(collections) == 0 {
+		return domainErrors.ErrInvalidInput
+	}
+
+	var route *entity.CollectionRoute
+	if routeID != nil {
+		var err error
+		if route, err = s.routeRepo.GetByID(ctx, *routeID); err != nil {
+			return err
+		}
+	}
 
-	// 5. Update inventory in the Main Warehouse (Add weight as quantity)
-	// Note: We use the weight recorded by the agent as the quantity increase.
-	if err := s.inventoryRepo.AdjustLevel(ctx, collection.WarehouseID, collection.VariantID, collection.Weight); err != nil {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, collection := range collections {
+			if route != nil {
+				if !route.HasSupplier(collection.SupplierID) {
+					return fmt.Errorf("entry %d: %w", i+1, domainErrors.ErrSupplierNotOnRoute)
+				}
+				if collection.WarehouseID == 0 {
+					collection.WarehouseID = route.WarehouseID
+				}
+			}
+			if err := s.RecordCollection(ctx, collection); err != nil {
+				return fmt.Errorf("entry %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *CollectionService) ListCollections(ctx context.Context, filter repository.CollectionFilter, offset, limit int) ([]entity.Collection, int64, error) {
+	return s.collectionRepo.List(ctx, filter, offset, limit)
+}
+
+// validateQuality checks the shift, the fat and SNF percentages and the adulterants flagged
+func validateQuality(collection *entity.Collection) error {
+	if !collection.Shift.IsValid() {
+		return domainErrors.ErrInvalidInput
+	}
+	for _, p := range []*decimal.Decimal{collection.FatPercent, collection.SNFPercent} {
+		if p != nil && (!p.IsPositive() || p.GreaterThan(decimal.NewFromInt(100))) {
+			return domainErrors.ErrInvalidInput
+		}
+	}
+	for i, flag := range collection.AdulterationFlags {
+		flag = strings.ToLower(strings.TrimSpace(flag))
+		if !slices.Contains(entity.Adulterants, flag) {
+			return domainErrors.ErrInvalidInput
+		}
+		collection.AdulterationFlags[i] = flag
+	}
+	return nil
+}
+
+// priceCollection sets the rate and amount payable from the rate chart in force for the supplier.
+// Milk below every slab of the chart, or with adulterants detected, is payable at zero. Collections
+// without fat and SNF readings, or with no chart for the variant, are left unpriced.
+func (s *CollectionService) priceCollection(ctx context.Context, collection *entity.Collection, supplier *entity.Supplier) error {
+	if collection.FatPercent == nil || collection.SNFPercent == nil {
+		return nil
+	}
+	chart, err := s.rateChartRepo.FindApplicable(ctx, collection.VariantID, supplier.ID, supplier.ZoneID, collection.CollectedAt)
+	if err != nil || chart == nil {
 		return err
 	}
 
+	rate, ok := chart.RateFor(*collection.FatPercent, *collection.SNFPercent)
+	if !ok || collection.IsAdulterated() {
+		rate = decimal.Zero
+	}
+	amount := collection.Weight.Mul(rate).Round(2)
+	collection.RateChartID = &chart.ID
+	collection.Rate = &rate
+	collection.Amount = &amount
 	return nil
 }
 
-func (s *CollectionService) ListCollections(ctx context.Context, offset, limit int) ([]entity.Collection, int64, error) {
-	return s.collectionRepo.List(ctx, offset, limit)
+// receiptCost is the cost the milk is booked into stock at: the rate it is paid at when priced,
+// otherwise the cost agreed with the supplier. Without either it comes in at the current average.
+func (s *CollectionService) receiptCost(ctx context.Context, collection *entity.Collection) (*decimal.Decimal, error) {
+	if collection.Rate != nil {
+		rate := *collection.Rate
+		return &rate, nil
+	}
+	variants, err := s.supplierRepo.GetVariants(ctx, collection.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sv := range variants {
+		if sv.VariantID == collection.VariantID && sv.AgreedCost.IsPositive() {
+			cost := sv.AgreedCost
+			return &cost, nil
+		}
+	}
+	return nil, nil
+}
+
+// ShiftSummary totals collections per supplier for each day and shift within [from, to)
+func (s *CollectionService) ShiftSummary(ctx context.Context, filter repository.CollectionSummaryFilter) ([]entity.CollectionSummary, error) {
+	if !filter.From.Before(filter.To) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	return s.collectionRepo.SummarizeByShift(ctx, filter)
+}
+
+// PeriodSummary totals collections per supplier over a collection period [from, to)
+func (s *CollectionService) PeriodSummary(ctx context.Context, filter repository.CollectionSummaryFilter) ([]entity.CollectionSummary, error) {
+	if !filter.From.Before(filter.To) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	return s.collectionRepo.SummarizeByPeriod(ctx, filter)
+}
+
+// CreateRateChart configures a rate chart for a supplier, a zone or, with neither, every supplier
+// of the variant. It prices collections from its effective date until a newer chart replaces it.
+func (s *CollectionService) CreateRateChart(ctx context.Context, chart *entity.CollectionRateChart) error {
+	chart.Name = strings.TrimSpace(chart.Name)
+	if chart.Name == "" || len(chart.Slabs) == 0 || (chart.ZoneID != nil && chart.SupplierID != nil) {
+		return domainErrors.ErrInvalidInput
+	}
+	seen := make(map[string]bool, len(chart.Slabs))
+	for _, slab := range chart.Slabs {
+		if slab.MinFatPercent.IsNegative() || slab.MinSNFPercent.IsNegative() || slab.Rate.IsNegative() {
+			return domainErrors.ErrInvalidInput
+		}
+		key := slab.MinFatPercent.String() + "/" + slab.MinSNFPercent.String()
+		if seen[key] {
+			return fmt.Errorf("duplicate slab %s: %w", key, domainErrors.ErrInvalidInput)
+		}
+		seen[key] = true
+	}
+
+	if _, err := s.variantRepo.GetByID(ctx, chart.VariantID); err != nil {
+		return domainErrors.ErrProductVariantNotFound
+	}
+	if chart.SupplierID != nil {
+		if _, err := s.supplierRepo.GetByID(ctx, *chart.SupplierID); err != nil {
+			return domainErrors.ErrSupplierNotFound
+		}
+	}
+	if chart.EffectiveFrom.IsZero() {
+		now := time.Now()
+		chart.EffectiveFrom = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return s.rateChartRepo.Create(ctx, chart)
+}
+
+// GetRateChart retrieves a rate chart with its slabs
+func (s *CollectionService) GetRateChart(ctx context.Context, id int64) (*entity.CollectionRateChart, error) {
+	return s.rateChartRepo.GetByID(ctx, id)
+}
+
+// ListRateCharts retrieves rate charts matching the filter
+func (s *CollectionService) ListRateCharts(ctx context.Context, filter repository.CollectionRateChartFilter) ([]entity.CollectionRateChart, error) {
+	return s.rateChartRepo.List(ctx, filter)
+}
+
+// DeactivateRateChart retires a rate chart; collections already priced from it keep their rate
+func (s *CollectionService) DeactivateRateChart(ctx context.Context, id int64) error {
+	return s.rateChartRepo.Deactivate(ctx, id)
 }
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// CustomerAddressService handles the customer address book
+type CustomerAddressService struct {
+	addressRepo repository.CustomerAddressRepository
+	pincodeRepo repository.PincodeRepository
+}
+
+// NewCustomerAddressService creates a new customer address service
+func NewCustomerAddressService(addressRepo repository.CustomerAddressRepository, pincodeRepo repository.PincodeRepository) *CustomerAddressService {
+	return &CustomerAddressService{
+		addressRepo: addressRepo,
+		pincodeRepo: pincodeRepo,
+	}
+}
+
+// Create adds an address to a customer's address book and assigns its delivery zone.
+// The first address a customer saves becomes their default.
+func (s *CustomerAddressService) Create(ctx context.Context, address *entity.CustomerAddress) error {
+	if err := s.prepare(ctx, address); err != nil {
+		return err
+	}
+
+	existing, err := s.addressRepo.ListByCustomer(ctx, address.CustomerID)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		address.IsDefault = true
+	}
+
+	return s.addressRepo.Create(ctx, address)
+}
+
+// Update replaces an address owned by the customer and re-assigns its delivery zone
+func (s *CustomerAddressService) Update(ctx context.Context, address *entity.CustomerAddress) error {
+	if _, err := s.GetForCustomer(ctx, address.CustomerID, address.ID); err != nil {
+		return err
+	}
+	if err := s.prepare(ctx, address); err != nil {
+		return err
+	}
+	return s.addressRepo.Update(ctx, address)
+}
+
+// GetForCustomer retrieves an address, ensuring it belongs to the customer
+func (s *CustomerAddressService) GetForCustomer(ctx context.Context, customerID, id int64) (*entity.CustomerAddress, error) {
+	address, err := s.addressRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if address.CustomerID != customerID {
+		return nil, domainErrors.ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// ListByCustomer retrieves a customer's address book
+func (s *CustomerAddressService) ListByCustomer(ctx context.Context, customerID int64) ([]entity.CustomerAddress, error) {
+	return s.addressRepo.ListByCustomer(ctx, customerID)
+}
+
+// Delete removes an address owned by the customer
+func (s *CustomerAddressService) Delete(ctx context.Context, customerID, id int64) error {
+	if _, err := s.GetForCustomer(ctx, customerID, id); err != nil {
+		return err
+	}
+	return s.addressRepo.Delete(ctx, id)
+}
+
+// prepare validates an address and assigns the delivery zone that serves it
+func (s *CustomerAddressService) prepare(ctx context.Context, address *entity.CustomerAddress) error {
+	address.AddressLine1 = strings.TrimSpace(address.AddressLine1)
+	address.Pincode = strings.TrimSpace(address.Pincode)
+	if address.AddressLine1 == "" || address.Pincode == "" {
+		return domainErrors.ErrInvalidInput
+	}
+	if address.Label == "" {
+		address.Label = entity.AddressLabelHome
+	}
+	if !address.Label.IsValid() {
+		return domainErrors.ErrInvalidInput
+	}
+	if (address.Latitude == nil) != (address.Longitude == nil) {
+		return domainErrors.ErrInvalidInput
+	}
+
+	zoneID, err := s.resolveZone(ctx, address)
+	if err != nil {
+		return err
+	}
+	address.ZoneID = zoneID
+	return nil
+}
+
+// resolveZone finds the delivery zone containing the address coordinates,
+// falling back to the zone mapped to its pincode. Unserviceable addresses have no zone.
+func (s *CustomerAddressService) resolveZone(ctx context.Context, address *entity.CustomerAddress) (*int64, error) {
+	if address.Latitude != nil && address.Longitude != nil {
+		zoneID, err := s.pincodeRepo.GetZoneByCoordinates(ctx, *address.Latitude, *address.Longitude)
+		if err == nil {
+			return &zoneID, nil
+		}
+		if err != domainErrors.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	area, err := s.pincodeRepo.GetByPincode(ctx, address.Pincode)
+	if err != nil {
+		return nil, err
+	}
+	if area == nil {
+		return nil, nil
+	}
+	return &area.ZoneID, nil
+}
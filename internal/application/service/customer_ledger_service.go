@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// CustomerLedgerService handles customer accounts receivable
+type CustomerLedgerService struct {
+	ledgerRepo   repository.CustomerLedgerRepository
+	customerRepo *postgres.CustomerRepository
+	txManager    repository.TxManager
+}
+
+// NewCustomerLedgerService creates a new customer ledger service
+func NewCustomerLedgerService(
+	ledgerRepo repository.CustomerLedgerRepository,
+	customerRepo *postgres.CustomerRepository,
+	txManager repository.TxManager,
+) *CustomerLedgerService {
+	return &CustomerLedgerService{
+		ledgerRepo:   ledgerRepo,
+		customerRepo: customerRepo,
+		txManager:    txManager,
+	}
+}
+
+// RecordPayment records money received from a customer and credits their ledger
+func (s *CustomerLedgerService) RecordPayment(ctx context.Context, payment *entity.CustomerPayment) error {
+	if !payment.Amount.IsPositive() {
+		return domainErrors.ErrInvalidInput
+	}
+	payment.PaymentMethod = strings.TrimSpace(payment.PaymentMethod)
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = "cash"
+	}
+	if payment.ReceivedAt.IsZero() {
+		payment.ReceivedAt = time.Now()
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Lock the account so the payment is serialized with credit sales
+		if _, err := s.ledgerRepo.GetCreditAccountForUpdate(ctx, payment.CustomerID); err != nil {
+			return err
+		}
+		if err := s.ledgerRepo.CreatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("Payment (%s)", payment.PaymentMethod)
+		if payment.Reference != "" {
+			description = fmt.Sprintf("Payment (%s, ref %s)", payment.PaymentMethod, payment.Reference)
+		}
+		return s.ledgerRepo.AddEntry(ctx, &entity.CustomerLedgerEntry{
+			CustomerID:      payment.CustomerID,
+			EntryType:       entity.LedgerEntryPayment,
+			InvoiceID:       payment.InvoiceID,
+			PaymentID:       &payment.ID,
+			Credit:          payment.Amount,
+			Description:     description,
+			EntryDate:       payment.ReceivedAt,
+			CreatedByUserID: payment.RecordedByUserID,
+		})
+	})
+}
+
+// GetStatement builds a customer's statement for entries dated within [from, to)
+// with running balances carried forward from the opening balance
+func (s *CustomerLedgerService) GetStatement(ctx context.Context, customerID int64, from, to time.Time) (*entity.CustomerStatement, error) {
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := s.ledgerRepo.GetBalance(ctx, customerID, from)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.ledgerRepo.ListEntries(ctx, customerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &entity.CustomerStatement{
+		Customer:       customer,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		Entries:        entries,
+		TotalDebit:     decimal.Zero,
+		TotalCredit:    decimal.Zero,
+	}
+	balance := opening
+	for i := range statement.Entries {
+		e := &statement.Entries[i]
+		balance = balance.Add(e.Debit).Sub(e.Credit)
+		e.Balance = balance
+		statement.TotalDebit = statement.TotalDebit.Add(e.Debit)
+		statement.TotalCredit = statement.TotalCredit.Add(e.Credit)
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// Aging returns the outstanding balance of every customer as of a date, split into age buckets
+func (s *CustomerLedgerService) Aging(ctx context.Context, asOf time.Time) ([]entity.CustomerAging, error) {
+	balances, err := s.ledgerRepo.ListBalances(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.ledgerRepo.ListEntriesUpTo(ctx, nil, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byCustomer := make(map[int64][]entity.CustomerLedgerEntry)
+	for _, e := range entries {
+		byCustomer[e.CustomerID] = append(byCustomer[e.CustomerID], e)
+	}
+	for i := range balances {
+		bucketOutstanding(&balances[i], byCustomer[balances[i].CustomerID], asOf)
+	}
+	return balances, nil
+}
+
+// CustomerAging returns a single customer's outstanding balance as of a date, split into age buckets
+func (s *CustomerLedgerService) CustomerAging(ctx context.Context, customerID int64, asOf time.Time) (*entity.CustomerAging, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.ledgerRepo.ListEntriesUpTo(ctx, &customerID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	aging := &entity.CustomerAging{CustomerID: customer.ID, CustomerName: customer.Name}
+	for _, e := range entries {
+		aging.Balance = aging.Balance.Add(e.Debit).Sub(e.Credit)
+	}
+	bucketOutstanding(aging, entries, asOf)
+	return aging, nil
+}
+
+// bucketOutstanding applies a customer's credits to their oldest debits first
+// and ages whatever remains unpaid from its due date
+func bucketOutstanding(aging *entity.CustomerAging, entries []entity.CustomerLedgerEntry, asOf time.Time) {
+	credits := decimal.Zero
+	charges := make([]entity.AgedCharge, 0, len(entries))
+	for _, e := range entries {
+		credits = credits.Add(e.Credit)
+		charges = append(charges, entity.AgedCharge{Amount: e.Debit, EntryDate: e.EntryDate, DueDate: e.DueDate})
+	}
+	aging.AgeOutstanding(charges, credits, asOf)
+}
@@ -6,15 +6,21 @@ import (
 	"github.com/qwikshelf/api/internal/adapter/secondary/postgres"
 	"github.com/qwikshelf/api/internal/application/dto"
 	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
 )
 
 type CustomerService struct {
 	customerRepo postgres.CustomerRepository
+	userService  *UserService
+	txManager    repository.TxManager
 }
 
-func NewCustomerService(customerRepo *postgres.CustomerRepository) *CustomerService {
+func NewCustomerService(customerRepo *postgres.CustomerRepository, userService *UserService, txManager repository.TxManager) *CustomerService {
 	return &CustomerService{
 		customerRepo: *customerRepo,
+		userService:  userService,
+		txManager:    txManager,
 	}
 }
 
@@ -60,6 +66,88 @@ func (s *CustomerService) GetByID(ctx context.Context, id int64) (*entity.Custom
 	return s.customerRepo.GetByID(ctx, id)
 }
 
+func (s *CustomerService) GetByPhone(ctx context.Context, phone string) (*entity.Customer, error) {
+	return s.customerRepo.GetByPhone(ctx, phone)
+}
+
+// FindOrCreateByPhone links storefront orders to a customer record, keyed by phone number
+func (s *CustomerService) FindOrCreateByPhone(ctx context.Context, customer *entity.Customer) (*entity.Customer, error) {
+	if err := s.customerRepo.FindOrCreateByPhone(ctx, customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// RegisterAccount creates a storefront account and a customer record linked to it in one
+// transaction. The phone is not verified, so an existing customer with the same phone is not
+// claimed; staff merge the two records once they have confirmed the customer.
+func (s *CustomerService) RegisterAccount(ctx context.Context, username, password, fullName, phone, address string, roleID int64) (*entity.User, *entity.Customer, error) {
+	if phone == "" {
+		return nil, nil, domainErrors.ErrInvalidInput
+	}
+
+	var user *entity.User
+	customer := &entity.Customer{Name: fullName, Phone: phone}
+	if address != "" {
+		customer.Address = &address
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userService.Create(ctx, username, password, fullName, phone, address, roleID, true, nil)
+		if err != nil {
+			return err
+		}
+		customer.UserID = &user.ID
+		return s.customerRepo.CreateForUser(ctx, customer)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, customer, nil
+}
+
+// ResolveForUser returns the customer linked to a storefront account. Accounts registered before
+// the link existed get a customer record of their own on first use, like new registrations.
+func (s *CustomerService) ResolveForUser(ctx context.Context, userID int64) (*entity.Customer, error) {
+	customer, err := s.customerRepo.GetByUserID(ctx, userID)
+	if err != domainErrors.ErrCustomerNotFound {
+		return customer, err
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == "" {
+		return nil, domainErrors.ErrCustomerNotFound
+	}
+
+	customer = &entity.Customer{Name: user.FullName, Phone: user.Phone, UserID: &user.ID}
+	if user.Address != "" {
+		customer.Address = &user.Address
+	}
+	if err := s.customerRepo.CreateForUser(ctx, customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// Merge folds a duplicate customer record into the target and returns the merged customer
+func (s *CustomerService) Merge(ctx context.Context, targetID, sourceID int64) (*entity.Customer, error) {
+	if targetID == sourceID {
+		return nil, domainErrors.ErrInvalidInput
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.customerRepo.Merge(ctx, targetID, sourceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.customerRepo.GetByID(ctx, targetID)
+}
+
 func (s *CustomerService) List(ctx context.Context, offset, limit int) ([]*entity.Customer, int64, error) {
 	return s.customerRepo.List(ctx, offset, limit)
 }
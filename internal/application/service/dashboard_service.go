@@ -8,11 +8,13 @@ import (
 )
 
 type DashboardService struct {
-	db *postgres.DB
+	db                *postgres.DB
+	lowStockThreshold float64
 }
 
-func NewDashboardService(db *postgres.DB) *DashboardService {
-	return &DashboardService{db: db}
+// NewDashboardService creates a dashboard service; lowStockThreshold applies to stock without a reorder point
+func NewDashboardService(db *postgres.DB, lowStockThreshold float64) *DashboardService {
+	return &DashboardService{db: db, lowStockThreshold: lowStockThreshold}
 }
 
 // TrendPoint represents a data point in a time-series chart
@@ -91,15 +93,19 @@ func (s *DashboardService) GetStats(ctx context.Context, user *entity.User, days
 	// Inventory Stats (SKUs, Low Stock, Out of Stock, Value)
 	if canViewInventory {
 		_ = pool.QueryRow(ctx, "SELECT count(*) FROM inventory_levels").Scan(&stats.TotalSKUs)
-		_ = pool.QueryRow(ctx, "SELECT count(*) FROM inventory_levels WHERE quantity > 0 AND quantity < 10").Scan(&stats.LowStockItems)
-		_ = pool.QueryRow(ctx, "SELECT count(*) FROM inventory_levels WHERE quantity <= 0").Scan(&stats.OutOfStockItems)
-
-		// Closing Inventory Value = sum(quantity * cost_price)
-		invQuery := `
-			SELECT COALESCE(SUM(il.quantity * pv.cost_price), 0)
+		// Low stock: at or below the reorder point, or under the default threshold where none is set
+		lowStockQuery := `
+			SELECT count(*)
 			FROM inventory_levels il
-			JOIN product_variants pv ON il.variant_id = pv.id
+			LEFT JOIN reorder_settings rs ON rs.warehouse_id = il.warehouse_id AND rs.variant_id = il.variant_id
+			WHERE il.quantity > 0
+			  AND ((rs.id IS NOT NULL AND il.quantity <= rs.reorder_point) OR (rs.id IS NULL AND il.quantity < $1))
 		`
+		_ = pool.QueryRow(ctx, lowStockQuery, s.lowStockThreshold).Scan(&stats.LowStockItems)
+		_ = pool.QueryRow(ctx, "SELECT count(*) FROM inventory_levels WHERE quantity <= 0").Scan(&stats.OutOfStockItems)
+
+		// Closing Inventory Value = sum of the stock value held at cost
+		invQuery := `SELECT COALESCE(SUM(stock_value), 0) FROM inventory_levels`
 		_ = pool.QueryRow(ctx, invQuery).Scan(&stats.InventoryValue)
 		stats.ClosingInventoryValue = stats.InventoryValue
 	}
@@ -123,14 +129,15 @@ func (s *DashboardService) GetStats(ctx context.Context, user *entity.User, days
 
 	// Sales Stats (Total Sales, Accounts Receivable, Trend)
 	if canViewSales {
-		_ = pool.QueryRow(ctx, "SELECT COALESCE(SUM(total_amount), 0) FROM sales").Scan(&stats.TotalSalesValue)
-		_ = pool.QueryRow(ctx, "SELECT COALESCE(SUM(total_amount), 0) FROM sales WHERE payment_method IN ('credit', 'other')").Scan(&stats.AccountsReceivable)
+		// Cancelled and returned orders no longer count as revenue
+		_ = pool.QueryRow(ctx, "SELECT COALESCE(SUM(total_amount), 0) FROM sales WHERE status NOT IN ('cancelled', 'returned')").Scan(&stats.TotalSalesValue)
+		_ = pool.QueryRow(ctx, "SELECT COALESCE(SUM(balance), 0) FROM (SELECT SUM(debit - credit) AS balance FROM customer_ledger_entries GROUP BY customer_id) b WHERE balance > 0").Scan(&stats.AccountsReceivable)
 
 		// Sales Trend (Dynamic days)
 		rows, _ := pool.Query(ctx, `
 			SELECT TO_CHAR(created_at, 'YYYY-MM-DD') as date, COALESCE(SUM(total_amount), 0) as total
 			FROM sales
-			WHERE created_at >= NOW() - (INTERVAL '1 day' * $1)
+			WHERE created_at >= NOW() - (INTERVAL '1 day' * $1) AND status NOT IN ('cancelled', 'returned')
 			GROUP BY 1
 			ORDER BY 1
 		`, days)
@@ -145,7 +152,9 @@ func (s *DashboardService) GetStats(ctx context.Context, user *entity.User, days
 		pRows, _ := pool.Query(ctx, `
 			SELECT pv.name, COALESCE(SUM(si.line_total), 0) as total
 			FROM sale_items si
+			JOIN sales s ON s.id = si.sale_id
 			JOIN product_variants pv ON si.variant_id = pv.id
+			WHERE s.status NOT IN ('cancelled', 'returned')
 			GROUP BY pv.name
 			ORDER BY total DESC
 			LIMIT 5
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// lockLots locks the stock level of a variant at a warehouse and the lots holding it, and
+// returns the lots with the untracked remainder of the level
+func lockLots(ctx context.Context, inventoryRepo repository.InventoryRepository, warehouseID, variantID int64) ([]entity.InventoryLot, decimal.Decimal, error) {
+	level, err := inventoryRepo.GetLevelForUpdate(ctx, warehouseID, variantID)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	lots, err := inventoryRepo.ListLotsForUpdate(ctx, warehouseID, variantID)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	untracked := level.Quantity
+	for _, lot := range lots {
+		untracked = untracked.Sub(lot.Quantity)
+	}
+	return lots, untracked, nil
+}
+
+// allocateFEFO locks the stock of a variant at a warehouse and plans which lots a withdrawal
+// is taken from, first-expiry-first-out. Expired lots cannot be used: if only they would make
+// up the quantity the withdrawal fails with ErrStockExpired. Stock held by reservations cannot
+// be used either: if the withdrawal needs it, it fails with ErrStockReserved.
+func allocateFEFO(ctx context.Context, inventoryRepo repository.InventoryRepository, warehouseID, variantID int64, quantity decimal.Decimal) ([]entity.LotAllocation, error) {
+	return allocate(ctx, inventoryRepo, warehouseID, variantID, quantity, true)
+}
+
+// allocateReserved plans a withdrawal of stock a reservation was holding. The reservation was
+// made before any still held, so the withdrawal does not have to leave their stock untouched.
+func allocateReserved(ctx context.Context, inventoryRepo repository.InventoryRepository, warehouseID, variantID int64, quantity decimal.Decimal) ([]entity.LotAllocation, error) {
+	return allocate(ctx, inventoryRepo, warehouseID, variantID, quantity, false)
+}
+
+func allocate(ctx context.Context, inventoryRepo repository.InventoryRepository, warehouseID, variantID int64, quantity decimal.Decimal, respectReservations bool) ([]entity.LotAllocation, error) {
+	lots, untracked, err := lockLots(ctx, inventoryRepo, warehouseID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	allocations, ok := entity.AllocateFEFO(lots, untracked, quantity, now, false)
+	if !ok {
+		if _, ok := entity.AllocateFEFO(lots, untracked, quantity, now, true); ok {
+			return nil, domainErrors.ErrStockExpired
+		}
+		return nil, domainErrors.ErrInsufficientStock
+	}
+	if !respectReservations {
+		return allocations, nil
+	}
+
+	// Reservations do not pin lots, so it is enough that the usable stock covers both
+	reserved, err := inventoryRepo.SumHeldReservations(ctx, warehouseID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if reserved.IsPositive() {
+		if _, ok := entity.AllocateFEFO(lots, untracked, quantity.Add(reserved), now, false); !ok {
+			return nil, domainErrors.ErrStockReserved
+		}
+	}
+	return allocations, nil
+}
+
+// reserveStock checks the quantity can be promised at the reservation's warehouse on top of what
+// is already reserved there, and holds it. Reservations of the same variant and warehouse are
+// serialised by the stock level lock.
+func reserveStock(ctx context.Context, inventoryRepo repository.InventoryRepository, reservation *entity.StockReservation) error {
+	if _, err := allocateFEFO(ctx, inventoryRepo, reservation.WarehouseID, reservation.VariantID, reservation.Quantity); err != nil {
+		return err
+	}
+	return inventoryRepo.CreateReservation(ctx, reservation)
+}
+
+// postWithdrawal records a planned withdrawal as one ledger movement per lot, each a copy of
+// base with the lot and quantity filled in
+func postWithdrawal(ctx context.Context, inventoryRepo repository.InventoryRepository, base *entity.InventoryMovement, allocations []entity.LotAllocation) ([]entity.InventoryMovement, error) {
+	movements := make([]entity.InventoryMovement, 0, len(allocations))
+	for _, allocation := range allocations {
+		movement := *base
+		movement.QuantityDelta = allocation.Quantity.Neg()
+		movement.LotID = nil
+		if allocation.Lot != nil {
+			lotID := allocation.Lot.ID
+			movement.LotID = &lotID
+		}
+		if err := inventoryRepo.AdjustLevel(ctx, &movement); err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	return movements, nil
+}
+
+// receiveIntoLot adds the movement's stock to the lot of the given batch at the movement's
+// warehouse, creating the lot on first receipt. A new lot's origin defaults to the movement.
+func receiveIntoLot(ctx context.Context, inventoryRepo repository.InventoryRepository, movement *entity.InventoryMovement, lot *entity.InventoryLot) error {
+	lot.WarehouseID = movement.WarehouseID
+	lot.VariantID = movement.VariantID
+	if lot.SourceType == "" {
+		lot.SourceType = movement.SourceType
+		lot.ReferenceID = movement.ReferenceID
+	}
+	if err := inventoryRepo.UpsertLot(ctx, lot); err != nil {
+		return err
+	}
+	movement.LotID = &lot.ID
+	return inventoryRepo.AdjustLevel(ctx, movement)
+}
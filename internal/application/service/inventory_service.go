@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
 
+	"github.com/qwikshelf/api/internal/config"
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 	"github.com/qwikshelf/api/internal/domain/repository"
@@ -13,21 +15,32 @@ import (
 
 // InventoryService handles inventory management logic
 type InventoryService struct {
-	inventoryRepo repository.InventoryRepository
-	warehouseRepo repository.WarehouseRepository
-	variantRepo   repository.ProductVariantRepository
+	inventoryRepo     repository.InventoryRepository
+	adjustmentRepo    repository.InventoryAdjustmentRepository
+	warehouseRepo     repository.WarehouseRepository
+	variantRepo       repository.ProductVariantRepository
+	txManager         repository.TxManager
+	approvalThreshold decimal.Decimal
+	costing           entity.CostingMethod
 }
 
 // NewInventoryService creates a new inventory service
 func NewInventoryService(
 	inventoryRepo repository.InventoryRepository,
+	adjustmentRepo repository.InventoryAdjustmentRepository,
 	warehouseRepo repository.WarehouseRepository,
 	variantRepo repository.ProductVariantRepository,
+	txManager repository.TxManager,
+	inventoryCfg config.InventoryConfig,
 ) *InventoryService {
 	return &InventoryService{
-		inventoryRepo: inventoryRepo,
-		warehouseRepo: warehouseRepo,
-		variantRepo:   variantRepo,
+		inventoryRepo:     inventoryRepo,
+		adjustmentRepo:    adjustmentRepo,
+		warehouseRepo:     warehouseRepo,
+		variantRepo:       variantRepo,
+		txManager:         txManager,
+		approvalThreshold: decimal.NewFromFloat(inventoryCfg.AdjustmentApprovalThreshold),
+		costing:           entity.CostingMethod(inventoryCfg.CostingMethod),
 	}
 }
 
@@ -68,103 +81,474 @@ func (s *InventoryService) List(ctx context.Context, warehouseID *int64, offset,
 	return s.inventoryRepo.List(ctx, offset, limit)
 }
 
-// Adjust adjusts inventory level for a specific warehouse and variant
-func (s *InventoryService) Adjust(ctx context.Context, warehouseID, variantID int64, quantityDelta decimal.Decimal) (*entity.InventoryLevel, error) {
+// Adjust records a manual stock adjustment. Adjustments worth up to the approval threshold
+// are applied straight away and the updated level is returned; costlier ones are left
+// pending for a second user to approve, and no level is returned.
+func (s *InventoryService) Adjust(ctx context.Context, adjustment *entity.InventoryAdjustment) (*entity.InventoryLevel, error) {
+	if !adjustment.ReasonCode.IsValid() {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	if adjustment.QuantityDelta.IsZero() {
+		return nil, domainErrors.ErrInvalidQuantity
+	}
+
 	// Verify warehouse exists
-	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+	if _, err := s.warehouseRepo.GetByID(ctx, adjustment.WarehouseID); err != nil {
 		return nil, domainErrors.ErrWarehouseNotFound
 	}
 
 	// Verify variant exists
-	if _, err := s.variantRepo.GetByID(ctx, variantID); err != nil {
+	variant, err := s.variantRepo.GetByID(ctx, adjustment.VariantID)
+	if err != nil {
 		return nil, domainErrors.ErrProductVariantNotFound
 	}
 
-	// Perform adjustment
-	if err := s.inventoryRepo.AdjustLevel(ctx, warehouseID, variantID, quantityDelta); err != nil {
+	adjustment.Price(variant.CostPrice)
+	if adjustment.Value.GreaterThan(s.approvalThreshold) {
+		adjustment.Status = entity.AdjustmentStatusPending
+		if err := s.adjustmentRepo.Create(ctx, adjustment); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if err := s.ApplyApprovedAdjustment(ctx, adjustment, nil); err != nil {
 		return nil, err
 	}
 
 	// Return updated level
-	return s.inventoryRepo.GetLevel(ctx, warehouseID, variantID)
+	return s.inventoryRepo.GetLevel(ctx, adjustment.WarehouseID, adjustment.VariantID)
 }
 
-// Transfer creates an inventory transfer between warehouses
-func (s *InventoryService) Transfer(ctx context.Context, sourceWarehouseID, destWarehouseID, authorizedByUserID int64, items []entity.InventoryTransferItem) (*entity.InventoryTransfer, error) {
+// ApplyApprovedAdjustment records an already-priced adjustment as approved by approverID and
+// applies it to stock, whatever its value. Used where approval happens as part of another flow,
+// such as posting a stock take; approverID is nil for adjustments below the approval threshold.
+func (s *InventoryService) ApplyApprovedAdjustment(ctx context.Context, adjustment *entity.InventoryAdjustment, approverID *int64) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		adjustment.Status = entity.AdjustmentStatusApproved
+		if approverID != nil {
+			now := time.Now()
+			adjustment.ReviewedByUserID = approverID
+			adjustment.ReviewedAt = &now
+		}
+		if err := s.adjustmentRepo.Create(ctx, adjustment); err != nil {
+			return err
+		}
+		if err := s.postAdjustment(ctx, adjustment); err != nil {
+			return err
+		}
+		return s.adjustmentRepo.UpdateReview(ctx, adjustment)
+	})
+}
+
+// ApproveAdjustment applies a pending adjustment. The approver must not be the requester.
+func (s *InventoryService) ApproveAdjustment(ctx context.Context, id, approverID int64, note *string) (*entity.InventoryAdjustment, error) {
+	var adjustment *entity.InventoryAdjustment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if adjustment, err = s.lockPendingAdjustment(ctx, id, approverID); err != nil {
+			return err
+		}
+		if err := s.postAdjustment(ctx, adjustment); err != nil {
+			return err
+		}
+		adjustment.Status = entity.AdjustmentStatusApproved
+		return s.recordReview(ctx, adjustment, approverID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// RejectAdjustment discards a pending adjustment without touching stock
+func (s *InventoryService) RejectAdjustment(ctx context.Context, id, approverID int64, note *string) (*entity.InventoryAdjustment, error) {
+	var adjustment *entity.InventoryAdjustment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if adjustment, err = s.lockPendingAdjustment(ctx, id, approverID); err != nil {
+			return err
+		}
+		adjustment.Status = entity.AdjustmentStatusRejected
+		return s.recordReview(ctx, adjustment, approverID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (s *InventoryService) lockPendingAdjustment(ctx context.Context, id, approverID int64) (*entity.InventoryAdjustment, error) {
+	adjustment, err := s.adjustmentRepo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != entity.AdjustmentStatusPending {
+		return nil, domainErrors.ErrAdjustmentNotPending
+	}
+	if adjustment.RequestedByUserID != nil && *adjustment.RequestedByUserID == approverID {
+		return nil, domainErrors.ErrSelfApproval
+	}
+	return adjustment, nil
+}
+
+func (s *InventoryService) recordReview(ctx context.Context, adjustment *entity.InventoryAdjustment, approverID int64, note *string) error {
+	now := time.Now()
+	adjustment.ReviewedByUserID = &approverID
+	adjustment.ReviewedAt = &now
+	adjustment.ReviewNote = note
+	return s.adjustmentRepo.UpdateReview(ctx, adjustment)
+}
+
+// postAdjustment applies the adjustment to stock and links it to the resulting ledger movement.
+// Stock added goes in untracked; stock written off comes out of lots first-expiry-first-out,
+// expired lots first, and is recorded as one movement per lot, the first of which is linked.
+func (s *InventoryService) postAdjustment(ctx context.Context, adjustment *entity.InventoryAdjustment) error {
+	movement := entity.NewInventoryMovement(adjustment.WarehouseID, adjustment.VariantID, adjustment.QuantityDelta,
+		entity.MovementSourceAdjustment, &adjustment.ID, adjustment.RequestedByUserID)
+	notes := string(adjustment.ReasonCode)
+	if adjustment.Notes != nil && *adjustment.Notes != "" {
+		notes += ": " + *adjustment.Notes
+	}
+	movement.Notes = &notes
+
+	if adjustment.QuantityDelta.IsPositive() {
+		if err := s.inventoryRepo.AdjustLevel(ctx, movement); err != nil {
+			return err
+		}
+		adjustment.MovementID = &movement.ID
+		return nil
+	}
+
+	lots, untracked, err := lockLots(ctx, s.inventoryRepo, adjustment.WarehouseID, adjustment.VariantID)
+	if err != nil {
+		return err
+	}
+	quantity := adjustment.QuantityDelta.Neg()
+	allocations, _ := entity.AllocateFEFO(lots, untracked, quantity, time.Now(), true)
+	// Whatever the lots cannot cover is written off untracked stock, as before lots existed
+	for _, allocation := range allocations {
+		quantity = quantity.Sub(allocation.Quantity)
+	}
+	if quantity.IsPositive() {
+		allocations = append(allocations, entity.LotAllocation{Quantity: quantity})
+	}
+
+	movements, err := postWithdrawal(ctx, s.inventoryRepo, movement, allocations)
+	if err != nil {
+		return err
+	}
+	adjustment.MovementID = &movements[0].ID
+	return nil
+}
+
+// GetAdjustment retrieves a manual adjustment by ID
+func (s *InventoryService) GetAdjustment(ctx context.Context, id int64) (*entity.InventoryAdjustment, error) {
+	return s.adjustmentRepo.GetByID(ctx, id)
+}
+
+// ListAdjustments retrieves manual adjustments matching the filter
+func (s *InventoryService) ListAdjustments(ctx context.Context, filter repository.InventoryAdjustmentFilter, offset, limit int) ([]entity.InventoryAdjustment, int64, error) {
+	return s.adjustmentRepo.List(ctx, filter, offset, limit)
+}
+
+// Shrinkage reports the value of stock written off within [from, to) by warehouse and reason
+func (s *InventoryService) Shrinkage(ctx context.Context, from, to time.Time, warehouseID *int64) ([]entity.ShrinkageSummary, error) {
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	return s.adjustmentRepo.Shrinkage(ctx, from, to, warehouseID)
+}
+
+// CreateTransfer creates a transfer between warehouses. A transfer asked for by the receiving
+// store has status requested; any other starts out pending. Stock does not move until dispatch.
+func (s *InventoryService) CreateTransfer(ctx context.Context, transfer *entity.InventoryTransfer) error {
 	// Validate warehouses are different
-	if sourceWarehouseID == destWarehouseID {
-		return nil, domainErrors.ErrSameWarehouse
+	if transfer.SourceWarehouseID == transfer.DestinationWarehouseID {
+		return domainErrors.ErrSameWarehouse
 	}
 
 	// Verify source warehouse exists
-	if _, err := s.warehouseRepo.GetByID(ctx, sourceWarehouseID); err != nil {
-		return nil, domainErrors.ErrWarehouseNotFound
+	if _, err := s.warehouseRepo.GetByID(ctx, transfer.SourceWarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
 	}
 
 	// Verify destination warehouse exists
-	if _, err := s.warehouseRepo.GetByID(ctx, destWarehouseID); err != nil {
-		return nil, domainErrors.ErrWarehouseNotFound
+	if _, err := s.warehouseRepo.GetByID(ctx, transfer.DestinationWarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
 	}
 
-	// Validate and check stock for each item
-	for _, item := range items {
+	// Validate each item; a variant may appear only once so its lots are allocated once
+	seen := make(map[int64]bool, len(transfer.Items))
+	for _, item := range transfer.Items {
 		if item.Quantity.LessThanOrEqual(decimal.Zero) {
-			return nil, domainErrors.ErrInvalidQuantity
+			return domainErrors.ErrInvalidQuantity
+		}
+		if seen[item.VariantID] {
+			return fmt.Errorf("variant %d is listed more than once: %w", item.VariantID, domainErrors.ErrInvalidInput)
 		}
+		seen[item.VariantID] = true
 
 		// Verify variant exists
 		if _, err := s.variantRepo.GetByID(ctx, item.VariantID); err != nil {
-			return nil, domainErrors.ErrProductVariantNotFound
+			return domainErrors.ErrProductVariantNotFound
 		}
+	}
+
+	if transfer.Status != entity.TransferStatusRequested {
+		transfer.Status = entity.TransferStatusPending
+		transfer.RequestedByUserID = nil
+	}
+	transfer.TransferredAt = time.Now()
+	return s.inventoryRepo.CreateTransfer(ctx, transfer)
+}
 
-		// Check stock level at source
-		level, err := s.inventoryRepo.GetLevel(ctx, sourceWarehouseID, item.VariantID)
+// Transfer creates a transfer and dispatches it straight away
+func (s *InventoryService) Transfer(ctx context.Context, transfer *entity.InventoryTransfer) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.CreateTransfer(ctx, transfer); err != nil {
+			return err
+		}
+		dispatched, err := s.DispatchTransfer(ctx, transfer.ID, transfer.AuthorizedByUserID, nil)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if level.Quantity.LessThan(item.Quantity) {
-			return nil, domainErrors.ErrInsufficientStock
+		*transfer = *dispatched
+		return nil
+	})
+}
+
+// DispatchTransfer takes the stock of a requested or pending transfer out of the source warehouse,
+// lot by lot first-expiry-first-out, and marks it in transit. quantities maps item IDs to the
+// quantity actually sent when it differs from what was asked for; zero sends none of an item.
+func (s *InventoryService) DispatchTransfer(ctx context.Context, id, userID int64, quantities map[int64]decimal.Decimal) (*entity.InventoryTransfer, error) {
+	var transfer *entity.InventoryTransfer
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if transfer, err = s.inventoryRepo.GetTransferByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if !transfer.CanDispatch() {
+			return domainErrors.ErrTransferStatus
 		}
-	}
 
-	// Create transfer
-	transfer := &entity.InventoryTransfer{
-		SourceWarehouseID:      sourceWarehouseID,
-		DestinationWarehouseID: destWarehouseID,
-		AuthorizedByUserID:     authorizedByUserID,
-		TransferredAt:          time.Now(),
-		Status:                 entity.TransferStatusPending,
-		Items:                  items,
-	}
+		sent := make([]decimal.Decimal, len(transfer.Items))
+		total := decimal.Zero
+		for i, item := range transfer.Items {
+			sent[i] = item.Quantity
+			if q, ok := quantities[item.ID]; ok {
+				sent[i] = q
+			}
+			if sent[i].IsNegative() {
+				return domainErrors.ErrInvalidQuantity
+			}
+			total = total.Add(sent[i])
+		}
+		for itemID := range quantities {
+			if !transferHasItem(transfer, itemID) {
+				return fmt.Errorf("item %d is not on transfer %d: %w", itemID, transfer.ID, domainErrors.ErrInvalidInput)
+			}
+		}
+		if !total.IsPositive() {
+			return domainErrors.ErrInvalidQuantity
+		}
+
+		// Lock source stock and pick lots first; expired lots are not sent
+		allocations := make([][]entity.LotAllocation, len(transfer.Items))
+		for i, item := range transfer.Items {
+			if !sent[i].IsPositive() {
+				continue
+			}
+			planned, err := allocateFEFO(ctx, s.inventoryRepo, transfer.SourceWarehouseID, item.VariantID, sent[i])
+			if err != nil {
+				return err
+			}
+			allocations[i] = planned
+		}
+
+		for i := range transfer.Items {
+			item := &transfer.Items[i]
+			if len(allocations[i]) > 0 {
+				out := entity.NewInventoryMovement(transfer.SourceWarehouseID, item.VariantID, sent[i].Neg(), entity.MovementSourceTransferOut, &transfer.ID, &userID)
+				if _, err := postWithdrawal(ctx, s.inventoryRepo, out, allocations[i]); err != nil {
+					return err
+				}
+			}
+			qty := sent[i]
+			item.DispatchedQuantity = &qty
+		}
 
-	if err := s.inventoryRepo.CreateTransfer(ctx, transfer); err != nil {
+		now := time.Now()
+		transfer.Status = entity.TransferStatusInTransit
+		transfer.DispatchedByUserID = &userID
+		transfer.DispatchedAt = &now
+		return s.inventoryRepo.UpdateTransfer(ctx, transfer)
+	})
+	if err != nil {
 		return nil, err
 	}
+	return transfer, nil
+}
 
-	// Deduct from source and add to destination
-	for _, item := range items {
-		// Deduct from source
-		if err := s.inventoryRepo.AdjustLevel(ctx, sourceWarehouseID, item.VariantID, item.Quantity.Neg()); err != nil {
-			return nil, err
+// ReceiveTransfer books an in-transit transfer into the destination warehouse and completes it.
+// Items without a receipt are taken as received in full. Stock reported short or damaged is not
+// added at the destination; received stock keeps the batch it was dispatched under.
+func (s *InventoryService) ReceiveTransfer(ctx context.Context, id, userID int64, receipts []entity.TransferReceipt) (*entity.InventoryTransfer, error) {
+	var transfer *entity.InventoryTransfer
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if transfer, err = s.inventoryRepo.GetTransferByIDForUpdate(ctx, id); err != nil {
+			return err
 		}
-		// Add to destination
-		if err := s.inventoryRepo.AdjustLevel(ctx, destWarehouseID, item.VariantID, item.Quantity); err != nil {
-			return nil, err
+		if transfer.Status != entity.TransferStatusInTransit {
+			return domainErrors.ErrTransferStatus
+		}
+
+		byItem := make(map[int64]entity.TransferReceipt, len(receipts))
+		for _, receipt := range receipts {
+			if !transferHasItem(transfer, receipt.ItemID) {
+				return fmt.Errorf("item %d is not on transfer %d: %w", receipt.ItemID, transfer.ID, domainErrors.ErrInvalidInput)
+			}
+			byItem[receipt.ItemID] = receipt
+		}
+
+		// What left the source, per variant and lot in the order it was picked
+		dispatched, err := s.inventoryRepo.ListMovementsByReference(ctx, entity.MovementSourceTransferOut, transfer.ID)
+		if err != nil {
+			return err
+		}
+		picked := make(map[int64][]entity.InventoryMovement)
+		for _, m := range dispatched {
+			picked[m.VariantID] = append(picked[m.VariantID], m)
+		}
+
+		for i := range transfer.Items {
+			item := &transfer.Items[i]
+			sent := decimal.Zero
+			if item.DispatchedQuantity != nil {
+				sent = *item.DispatchedQuantity
+			}
+			receipt, ok := byItem[item.ID]
+			if !ok {
+				receipt = entity.TransferReceipt{ItemID: item.ID, ReceivedQuantity: sent}
+			}
+			if receipt.ReceivedQuantity.IsNegative() || receipt.DamagedQuantity.IsNegative() ||
+				receipt.ReceivedQuantity.Add(receipt.DamagedQuantity).GreaterThan(sent) {
+				return domainErrors.ErrInvalidQuantity
+			}
+
+			remaining := receipt.ReceivedQuantity
+			for _, m := range picked[item.VariantID] {
+				if !remaining.IsPositive() {
+					break
+				}
+				allocation := entity.LotAllocation{Quantity: decimal.Min(m.QuantityDelta.Neg(), remaining)}
+				if m.LotID != nil {
+					if allocation.Lot, err = s.inventoryRepo.GetLotByID(ctx, *m.LotID); err != nil {
+						return err
+					}
+				}
+				if err := s.receiveTransferred(ctx, transfer.DestinationWarehouseID, item.VariantID, allocation, m.UnitCost, transfer.ID, userID); err != nil {
+					return err
+				}
+				remaining = remaining.Sub(allocation.Quantity)
+			}
+			if remaining.IsPositive() {
+				// Dispatched before lots were recorded on the ledger
+				in := entity.NewInventoryMovement(transfer.DestinationWarehouseID, item.VariantID, remaining, entity.MovementSourceTransferIn, &transfer.ID, &userID)
+				if err := s.inventoryRepo.AdjustLevel(ctx, in); err != nil {
+					return err
+				}
+			}
+
+			received, damaged := receipt.ReceivedQuantity, receipt.DamagedQuantity
+			item.ReceivedQuantity = &received
+			item.DamagedQuantity = &damaged
+			item.Notes = receipt.Notes
 		}
-	}
 
-	// Update transfer status to completed
-	if err := s.inventoryRepo.UpdateTransferStatus(ctx, transfer.ID, entity.TransferStatusCompleted); err != nil {
+		now := time.Now()
+		transfer.Status = entity.TransferStatusCompleted
+		transfer.ReceivedByUserID = &userID
+		transfer.ReceivedAt = &now
+		return s.inventoryRepo.UpdateTransfer(ctx, transfer)
+	})
+	if err != nil {
 		return nil, err
 	}
-	transfer.Status = entity.TransferStatusCompleted
+	return transfer, nil
+}
+
+// CancelTransfer cancels a transfer that has not been received. Stock already dispatched goes
+// back into the lots it was taken from at the source.
+func (s *InventoryService) CancelTransfer(ctx context.Context, id, userID int64) (*entity.InventoryTransfer, error) {
+	var transfer *entity.InventoryTransfer
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if transfer, err = s.inventoryRepo.GetTransferByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if !transfer.CanCancel() {
+			return domainErrors.ErrTransferStatus
+		}
+
+		if transfer.Status == entity.TransferStatusInTransit {
+			dispatched, err := s.inventoryRepo.ListMovementsByReference(ctx, entity.MovementSourceTransferOut, transfer.ID)
+			if err != nil {
+				return err
+			}
+			for _, m := range dispatched {
+				movement := entity.NewInventoryMovement(m.WarehouseID, m.VariantID, m.QuantityDelta.Neg(), entity.MovementSourceTransferCancellation, &transfer.ID, &userID)
+				movement.LotID = m.LotID
+				movement.UnitCost = m.UnitCost
+				if err := s.inventoryRepo.AdjustLevel(ctx, movement); err != nil {
+					return err
+				}
+			}
+		}
 
+		now := time.Now()
+		transfer.Status = entity.TransferStatusCancelled
+		transfer.CancelledByUserID = &userID
+		transfer.CancelledAt = &now
+		return s.inventoryRepo.UpdateTransfer(ctx, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return transfer, nil
 }
 
-// ListTransfers retrieves all transfers with pagination
-func (s *InventoryService) ListTransfers(ctx context.Context, offset, limit int) ([]entity.InventoryTransfer, int64, error) {
-	return s.inventoryRepo.ListTransfers(ctx, offset, limit)
+func transferHasItem(transfer *entity.InventoryTransfer, itemID int64) bool {
+	for _, item := range transfer.Items {
+		if item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
+
+// receiveTransferred adds transferred stock at the destination under the batch it left the source
+// with, keeping the lot's expiry date and origin, at the cost it left the source at
+func (s *InventoryService) receiveTransferred(ctx context.Context, warehouseID, variantID int64, allocation entity.LotAllocation, unitCost *decimal.Decimal, transferID, userID int64) error {
+	in := entity.NewInventoryMovement(warehouseID, variantID, allocation.Quantity, entity.MovementSourceTransferIn, &transferID, &userID)
+	in.UnitCost = unitCost
+	if allocation.Lot == nil {
+		return s.inventoryRepo.AdjustLevel(ctx, in)
+	}
+	lot := &entity.InventoryLot{
+		BatchNumber: allocation.Lot.BatchNumber,
+		ExpiryDate:  allocation.Lot.ExpiryDate,
+		SupplierID:  allocation.Lot.SupplierID,
+		SourceType:  allocation.Lot.SourceType,
+		ReferenceID: allocation.Lot.ReferenceID,
+	}
+	return receiveIntoLot(ctx, s.inventoryRepo, in, lot)
+}
+
+// ListTransfers retrieves transfers matching the filter with pagination
+func (s *InventoryService) ListTransfers(ctx context.Context, filter repository.TransferFilter, offset, limit int) ([]entity.InventoryTransfer, int64, error) {
+	return s.inventoryRepo.ListTransfers(ctx, filter, offset, limit)
 }
 
 // GetTransfer retrieves a transfer by ID
@@ -175,3 +559,131 @@ func (s *InventoryService) GetTransfer(ctx context.Context, id int64) (*entity.I
 	}
 	return transfer, nil
 }
+
+// InTransit returns the stock dispatched and not yet received, optionally for one destination
+func (s *InventoryService) InTransit(ctx context.Context, destinationWarehouseID *int64) ([]entity.InTransitStock, error) {
+	return s.inventoryRepo.ListInTransit(ctx, destinationWarehouseID)
+}
+
+// StockCard returns the movements of a variant at a warehouse within [from, to)
+// with running balances carried forward from the opening balance
+func (s *InventoryService) StockCard(ctx context.Context, warehouseID, variantID int64, from, to time.Time) (*entity.StockCard, error) {
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, domainErrors.ErrWarehouseNotFound
+	}
+	if _, err := s.variantRepo.GetByID(ctx, variantID); err != nil {
+		return nil, domainErrors.ErrProductVariantNotFound
+	}
+
+	opening, err := s.inventoryRepo.GetLedgerBalance(ctx, warehouseID, variantID, from)
+	if err != nil {
+		return nil, err
+	}
+	movements, err := s.inventoryRepo.ListMovements(ctx, warehouseID, variantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &entity.StockCard{
+		WarehouseID:    warehouseID,
+		VariantID:      variantID,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		TotalIn:        decimal.Zero,
+		TotalOut:       decimal.Zero,
+		ClosingBalance: opening,
+		Movements:      movements,
+	}
+	for _, m := range movements {
+		if m.QuantityDelta.IsPositive() {
+			card.TotalIn = card.TotalIn.Add(m.QuantityDelta)
+		} else {
+			card.TotalOut = card.TotalOut.Sub(m.QuantityDelta)
+		}
+		card.ClosingBalance = card.ClosingBalance.Add(m.QuantityDelta)
+	}
+	return card, nil
+}
+
+// Valuation values the stock held at the end of a day, per warehouse and variant, from the
+// stock value recorded by the ledger
+func (s *InventoryService) Valuation(ctx context.Context, asOf time.Time, warehouseID *int64) (*entity.InventoryValuation, error) {
+	if warehouseID != nil {
+		if _, err := s.warehouseRepo.GetByID(ctx, *warehouseID); err != nil {
+			return nil, domainErrors.ErrWarehouseNotFound
+		}
+	}
+	day := calendarDay(asOf)
+	lines, err := s.inventoryRepo.GetValuation(ctx, day.AddDate(0, 0, 1), warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation := &entity.InventoryValuation{AsOf: day, Method: s.costing, Lines: lines, TotalValue: decimal.Zero}
+	for _, l := range lines {
+		valuation.TotalValue = valuation.TotalValue.Add(l.Value)
+	}
+	return valuation, nil
+}
+
+// ListCostLayers retrieves the cost layers matching the filter, oldest first
+func (s *InventoryService) ListCostLayers(ctx context.Context, filter repository.CostLayerFilter) ([]entity.CostLayer, error) {
+	return s.inventoryRepo.ListCostLayers(ctx, filter)
+}
+
+// ListLots retrieves the lots matching the filter, first-expiring first
+func (s *InventoryService) ListLots(ctx context.Context, filter repository.InventoryLotFilter) ([]entity.InventoryLot, error) {
+	return s.inventoryRepo.ListLots(ctx, filter)
+}
+
+// TraceLot follows a batch of a variant from receipt through every warehouse it moved to and
+// the customers it was sold to, for recalls
+func (s *InventoryService) TraceLot(ctx context.Context, batchNumber string, variantID int64) (*entity.LotTrace, error) {
+	lots, err := s.inventoryRepo.ListLots(ctx, repository.InventoryLotFilter{
+		VariantID:    &variantID,
+		BatchNumber:  &batchNumber,
+		IncludeEmpty: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, domainErrors.ErrLotNotFound
+	}
+	movements, err := s.inventoryRepo.ListLotMovements(ctx, batchNumber, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewLotTrace(batchNumber, variantID, lots, movements), nil
+}
+
+// Drift reports stock levels that disagree with the movement ledger
+func (s *InventoryService) Drift(ctx context.Context) ([]entity.InventoryDrift, error) {
+	return s.inventoryRepo.ListDrift(ctx)
+}
+
+// RebuildLevels resets every drifting stock level to the sum of its ledger movements
+// and returns the drift that was corrected
+func (s *InventoryService) RebuildLevels(ctx context.Context) ([]entity.InventoryDrift, error) {
+	var drift []entity.InventoryDrift
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if drift, err = s.inventoryRepo.ListDrift(ctx); err != nil {
+			return err
+		}
+		for _, d := range drift {
+			if err := s.inventoryRepo.SyncLevelToLedger(ctx, d.WarehouseID, d.VariantID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/shopspring/decimal"
 
@@ -17,6 +19,11 @@ type ProcurementService struct {
 	inventoryRepo   repository.InventoryRepository
 	warehouseRepo   repository.WarehouseRepository
 	variantRepo     repository.ProductVariantRepository
+	roleRepo        repository.RoleRepository
+	txManager       repository.TxManager
+	prices          *SupplierPriceListService
+	// overReceiptTolerance is the fraction of the ordered quantity a line may be received beyond
+	overReceiptTolerance decimal.Decimal
 }
 
 // NewProcurementService creates a new procurement service
@@ -26,6 +33,10 @@ func NewProcurementService(
 	inventoryRepo repository.InventoryRepository,
 	warehouseRepo repository.WarehouseRepository,
 	variantRepo repository.ProductVariantRepository,
+	roleRepo repository.RoleRepository,
+	txManager repository.TxManager,
+	prices *SupplierPriceListService,
+	overReceiptTolerancePercent float64,
 ) *ProcurementService {
 	return &ProcurementService{
 		procurementRepo: procurementRepo,
@@ -33,10 +44,17 @@ func NewProcurementService(
 		inventoryRepo:   inventoryRepo,
 		warehouseRepo:   warehouseRepo,
 		variantRepo:     variantRepo,
+		roleRepo:        roleRepo,
+		txManager:       txManager,
+		prices:          prices,
+
+		overReceiptTolerance: decimal.NewFromFloat(overReceiptTolerancePercent).Div(decimal.NewFromInt(100)),
 	}
 }
 
-// Create creates a new purchase order
+// Create creates a new purchase order. Each line is checked against the supplier's price for its
+// quantity today: lines without a unit cost are ordered at that price, and every line keeps it as
+// its list price so lines ordered above it stand out.
 func (s *ProcurementService) Create(ctx context.Context, procurement *entity.Procurement) error {
 	// Verify supplier exists
 	if _, err := s.supplierRepo.GetByID(ctx, procurement.SupplierID); err != nil {
@@ -48,11 +66,26 @@ func (s *ProcurementService) Create(ctx context.Context, procurement *entity.Pro
 		return domainErrors.ErrWarehouseNotFound
 	}
 
-	// Verify all variants exist
-	for _, item := range procurement.Items {
+	// Verify all variants exist and price them from the supplier's price list
+	now := time.Now()
+	for i := range procurement.Items {
+		item := &procurement.Items[i]
 		if _, err := s.variantRepo.GetByID(ctx, item.VariantID); err != nil {
 			return domainErrors.ErrProductVariantNotFound
 		}
+
+		price, err := s.prices.EffectivePrice(ctx, procurement.SupplierID, item.VariantID, now, item.QuantityOrdered)
+		if err == domainErrors.ErrSupplierPriceNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		item.ListPrice = &price.UnitPrice
+		item.PriceListID = price.PriceListID
+		if item.UnitCost.IsZero() {
+			item.UnitCost = price.UnitPrice
+		}
 	}
 
 	if procurement.Status == "" {
@@ -77,47 +110,200 @@ func (s *ProcurementService) ListBySupplier(ctx context.Context, supplierID int6
 	return s.procurementRepo.ListBySupplier(ctx, supplierID)
 }
 
-// UpdateStatus updates the status of a procurement
-func (s *ProcurementService) UpdateStatus(ctx context.Context, id int64, status entity.ProcurementStatus) error {
+// UpdateStatus moves a procurement to the next status and records the change against userID.
+// Orders move pending -> approved -> ordered -> partial/received and can only be cancelled before
+// anything is received. Approval goes through Approve, and receipts move an order to partial or
+// received on their own; a partially received order can be closed short by marking it received.
+func (s *ProcurementService) UpdateStatus(ctx context.Context, id int64, status entity.ProcurementStatus, userID *int64, note string) error {
 	if !status.IsValid() {
 		return domainErrors.ErrInvalidInput
 	}
+	if status == entity.ProcurementStatusApproved {
+		return domainErrors.ErrApprovalRequired
+	}
 
-	// If marking as received, auto-adjust inventory
-	if status == entity.ProcurementStatusReceived {
-		procurement, err := s.procurementRepo.GetByID(ctx, id)
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Lock the order so a receipt cannot land between the checks and the change
+		procurement, err := s.procurementRepo.GetByIDForUpdate(ctx, id)
 		if err != nil {
 			return err
 		}
-		for _, item := range procurement.Items {
-			qty := item.QuantityOrdered
-			if !item.QuantityReceived.IsZero() {
-				qty = item.QuantityReceived
-			}
-			if err := s.inventoryRepo.AdjustLevel(ctx, procurement.WarehouseID, item.VariantID, qty); err != nil {
-				return err
-			}
+		if !procurement.Status.CanTransitionTo(status) {
+			return domainErrors.ErrProcurementStatus
+		}
+		closingShort := status == entity.ProcurementStatusReceived && procurement.Status == entity.ProcurementStatusPartial
+		if (status == entity.ProcurementStatusPartial || status == entity.ProcurementStatusReceived) && !closingShort {
+			return domainErrors.ErrReceiptRequired
 		}
+		if status == entity.ProcurementStatusCancelled && procurement.HasReceipts() {
+			return domainErrors.ErrProcurementReceived
+		}
+		return s.changeStatus(ctx, procurement, status, userID, nil, note)
+	})
+}
+
+// Approve approves a pending purchase order. The approver's role must allow approving the
+// order's total, and the approver cannot be the user who raised it.
+func (s *ProcurementService) Approve(ctx context.Context, id, approverID, roleID int64, note string) (*entity.Procurement, error) {
+	procurement, err := s.procurementRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !procurement.Status.CanTransitionTo(entity.ProcurementStatusApproved) {
+		return nil, domainErrors.ErrProcurementStatus
+	}
+	if procurement.OrderedByUserID == approverID {
+		return nil, domainErrors.ErrProcurementSelfApproval
+	}
+
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.POApprovalLimit != nil && procurement.CalculateTotalCost().GreaterThan(*role.POApprovalLimit) {
+		return nil, domainErrors.ErrApprovalLimitExceeded
 	}
 
-	return s.procurementRepo.UpdateStatus(ctx, id, status)
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.changeStatus(ctx, procurement, entity.ProcurementStatusApproved, &approverID, &roleID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return procurement, nil
 }
 
-// ReceiveItems updates received quantities for procurement items
-func (s *ProcurementService) ReceiveItems(ctx context.Context, id int64, items []struct {
-	ItemID           int64
-	QuantityReceived decimal.Decimal
-}) error {
-	// Verify procurement exists
-	if _, err := s.procurementRepo.GetByID(ctx, id); err != nil {
+// changeStatus moves the procurement on from the status it was loaded in and records the change
+func (s *ProcurementService) changeStatus(ctx context.Context, procurement *entity.Procurement, status entity.ProcurementStatus, userID, roleID *int64, note string) error {
+	if err := s.procurementRepo.UpdateStatus(ctx, procurement.ID, procurement.Status, status); err != nil {
+		return err
+	}
+	if err := s.procurementRepo.AddStatusEvent(ctx, &entity.ProcurementStatusEvent{
+		ProcurementID:   procurement.ID,
+		FromStatus:      procurement.Status,
+		ToStatus:        status,
+		Amount:          procurement.CalculateTotalCost(),
+		ChangedByUserID: userID,
+		RoleID:          roleID,
+		Note:            note,
+	}); err != nil {
 		return err
 	}
+	procurement.Status = status
+	return nil
+}
 
-	for _, item := range items {
-		qty, _ := item.QuantityReceived.Float64()
-		if err := s.procurementRepo.UpdateItemReceived(ctx, item.ItemID, qty); err != nil {
+// GetStatusHistory retrieves the status changes of a procurement, approvals included, oldest first
+func (s *ProcurementService) GetStatusHistory(ctx context.Context, id int64) ([]entity.ProcurementStatusEvent, error) {
+	if _, err := s.procurementRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.procurementRepo.ListStatusHistory(ctx, id)
+}
+
+// ReceiveGoods records a delivery against a purchase order as a goods receipt. Accepted quantities
+// are booked into stock straight away, in a lot per line named by its batch; rejected quantities
+// need a reason. A line cannot be received beyond its ordered quantity plus the over-receipt
+// tolerance. The order then moves to partial, or to received once every line is in full.
+func (s *ProcurementService) ReceiveGoods(ctx context.Context, receipt *entity.GoodsReceipt) error {
+	if len(receipt.Items) == 0 {
+		return domainErrors.ErrInvalidInput
+	}
+	for _, item := range receipt.Items {
+		if item.QuantityAccepted.IsNegative() || item.QuantityRejected.IsNegative() || !item.QuantityDelivered().IsPositive() {
+			return domainErrors.ErrInvalidInput
+		}
+		if item.QuantityRejected.IsPositive() && item.RejectionReason == "" {
+			return domainErrors.ErrRejectionReasonRequired
+		}
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Lock the order so concurrent deliveries cannot both squeeze under the tolerance
+		procurement, err := s.procurementRepo.GetByIDForUpdate(ctx, receipt.ProcurementID)
+		if err != nil {
+			return err
+		}
+		if procurement.Status != entity.ProcurementStatusOrdered && procurement.Status != entity.ProcurementStatusPartial {
+			return domainErrors.ErrProcurementStatus
+		}
+
+		lines := make(map[int64]*entity.ProcurementItem, len(procurement.Items))
+		for i := range procurement.Items {
+			lines[procurement.Items[i].ID] = &procurement.Items[i]
+		}
+		for i := range receipt.Items {
+			line, ok := lines[receipt.Items[i].ProcurementItemID]
+			if !ok {
+				return domainErrors.ErrReceiptItemNotOnOrder
+			}
+			line.QuantityReceived = line.QuantityReceived.Add(receipt.Items[i].QuantityAccepted)
+			line.QuantityRejected = line.QuantityRejected.Add(receipt.Items[i].QuantityRejected)
+			allowed := line.QuantityOrdered.Add(line.QuantityOrdered.Mul(s.overReceiptTolerance))
+			if line.QuantityReceived.GreaterThan(allowed) {
+				return domainErrors.ErrOverReceipt
+			}
+			receipt.Items[i].VariantID = line.VariantID
+		}
+
+		receipt.WarehouseID = procurement.WarehouseID
+		if err := s.procurementRepo.CreateReceipt(ctx, receipt); err != nil {
 			return err
 		}
+
+		for i := range receipt.Items {
+			item := &receipt.Items[i]
+			item.ReceiptID = receipt.ID
+			if item.QuantityAccepted.IsPositive() {
+				line := lines[item.ProcurementItemID]
+				movement := entity.NewInventoryMovement(procurement.WarehouseID, item.VariantID, item.QuantityAccepted, entity.MovementSourceProcurement, &procurement.ID, receipt.ReceivedByUserID)
+				unitCost := line.UnitCost
+				movement.UnitCost = &unitCost
+				lot := &entity.InventoryLot{
+					BatchNumber: fmt.Sprintf("GRN%d-%d", receipt.ID, item.ProcurementItemID),
+					ExpiryDate:  item.ExpiryDate,
+					SupplierID:  &procurement.SupplierID,
+				}
+				if item.BatchNumber != nil && *item.BatchNumber != "" {
+					lot.BatchNumber = *item.BatchNumber
+				}
+				if err := receiveIntoLot(ctx, s.inventoryRepo, movement, lot); err != nil {
+					return err
+				}
+				item.LotID = &lot.ID
+			}
+			if err := s.procurementRepo.AddReceiptItem(ctx, item); err != nil {
+				return err
+			}
+		}
+
+		status := entity.ProcurementStatusPartial
+		if procurement.IsFullyReceived() {
+			status = entity.ProcurementStatusReceived
+		}
+		if status == procurement.Status {
+			return nil
+		}
+		return s.changeStatus(ctx, procurement, status, receipt.ReceivedByUserID, nil, fmt.Sprintf("Goods receipt #%d", receipt.ID))
+	})
+}
+
+// GetReceipt retrieves a goods receipt of a procurement
+func (s *ProcurementService) GetReceipt(ctx context.Context, procurementID, receiptID int64) (*entity.GoodsReceipt, error) {
+	receipt, err := s.procurementRepo.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if receipt.ProcurementID != procurementID {
+		return nil, domainErrors.ErrGoodsReceiptNotFound
+	}
+	return receipt, nil
+}
+
+// ListReceipts retrieves the goods receipts of a procurement, oldest first
+func (s *ProcurementService) ListReceipts(ctx context.Context, procurementID int64) ([]entity.GoodsReceipt, error) {
+	if _, err := s.procurementRepo.GetByID(ctx, procurementID); err != nil {
+		return nil, err
+	}
+	return s.procurementRepo.ListReceipts(ctx, procurementID)
 }
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// ProductionService handles production run business logic
+type ProductionService struct {
+	productionRepo repository.ProductionRepository
+	recipeRepo     repository.RecipeRepository
+	inventoryRepo  repository.InventoryRepository
+	warehouseRepo  repository.WarehouseRepository
+	variantRepo    repository.ProductVariantRepository
+	txManager      repository.TxManager
+}
+
+// NewProductionService creates a new production service
+func NewProductionService(
+	productionRepo repository.ProductionRepository,
+	recipeRepo repository.RecipeRepository,
+	inventoryRepo repository.InventoryRepository,
+	warehouseRepo repository.WarehouseRepository,
+	variantRepo repository.ProductVariantRepository,
+	txManager repository.TxManager,
+) *ProductionService {
+	return &ProductionService{
+		productionRepo: productionRepo,
+		recipeRepo:     recipeRepo,
+		inventoryRepo:  inventoryRepo,
+		warehouseRepo:  warehouseRepo,
+		variantRepo:    variantRepo,
+		txManager:      txManager,
+	}
+}
+
+// RecordRun records a production run at a factory warehouse.
+// Input variants are consumed from stock and output variants are credited in the same transaction.
+func (s *ProductionService) RecordRun(ctx context.Context, run *entity.ProductionRun) error {
+	run.BatchCode = strings.TrimSpace(run.BatchCode)
+	if run.BatchCode == "" || len(run.Logs) == 0 {
+		return domainErrors.ErrInvalidInput
+	}
+
+	// 1. Verify the warehouse is a factory
+	warehouse, err := s.warehouseRepo.GetByID(ctx, run.WarehouseID)
+	if err != nil {
+		return domainErrors.ErrWarehouseNotFound
+	}
+	if warehouse.Type != entity.WarehouseTypeFactory {
+		return domainErrors.ErrNotFactoryWarehouse
+	}
+
+	// 2. Batch codes identify a run for yield reporting, so they must be unique. This check gives
+	// an early answer; the unique constraint catches runs recorded concurrently.
+	if _, err := s.productionRepo.GetRunByBatchCode(ctx, run.BatchCode); err == nil {
+		return domainErrors.ErrBatchCodeExists
+	} else if err != domainErrors.ErrProductionRunNotFound {
+		return err
+	}
+
+	// 3. Validate logs and aggregate consumption per input variant
+	consumption := make(map[int64]decimal.Decimal)
+	var inputs []int64
+	for _, log := range run.Logs {
+		if log.InputQty.LessThanOrEqual(decimal.Zero) || log.OutputQty.IsNegative() {
+			return domainErrors.ErrInvalidQuantity
+		}
+		if log.InputVariantID == log.OutputVariantID {
+			return domainErrors.ErrInvalidInput
+		}
+		if _, err := s.variantRepo.GetByID(ctx, log.InputVariantID); err != nil {
+			return domainErrors.ErrProductVariantNotFound
+		}
+		if _, err := s.variantRepo.GetByID(ctx, log.OutputVariantID); err != nil {
+			return domainErrors.ErrProductVariantNotFound
+		}
+
+		if _, seen := consumption[log.InputVariantID]; !seen {
+			inputs = append(inputs, log.InputVariantID)
+		}
+		consumption[log.InputVariantID] = consumption[log.InputVariantID].Add(log.InputQty)
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i] < inputs[j] })
+
+	// 4. Lock input stock, record the run, consume inputs and credit outputs atomically
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Inputs are consumed first-expiry-first-out; expired lots cannot be used
+		allocations := make(map[int64][]entity.LotAllocation, len(inputs))
+		for _, variantID := range inputs {
+			planned, err := allocateFEFO(ctx, s.inventoryRepo, run.WarehouseID, variantID, consumption[variantID])
+			if err != nil {
+				return err
+			}
+			allocations[variantID] = planned
+		}
+
+		if err := s.productionRepo.CreateRun(ctx, run); err != nil {
+			return err
+		}
+
+		inputUnitCost := make(map[int64]decimal.Decimal, len(inputs))
+		for _, variantID := range inputs {
+			input := entity.NewInventoryMovement(run.WarehouseID, variantID, consumption[variantID].Neg(), entity.MovementSourceProductionInput, &run.ID, &run.StaffID)
+			movements, err := postWithdrawal(ctx, s.inventoryRepo, input, allocations[variantID])
+			if err != nil {
+				return err
+			}
+			inputUnitCost[variantID] = issuedCost(movements).Div(consumption[variantID])
+		}
+
+		// Each line's input is costed at what that variant issued at and charged to the line's
+		// output variant, so outputs in different units never share a per-unit cost. An output
+		// variant with nothing produced carries its inputs' cost as a production loss.
+		outputCost := make(map[int64]decimal.Decimal)
+		outputQty := make(map[int64]decimal.Decimal)
+		for _, log := range run.Logs {
+			outputCost[log.OutputVariantID] = outputCost[log.OutputVariantID].Add(log.InputQty.Mul(inputUnitCost[log.InputVariantID]))
+			outputQty[log.OutputVariantID] = outputQty[log.OutputVariantID].Add(log.OutputQty)
+		}
+
+		// Outputs go into a lot named after the run's batch code
+		for _, log := range run.Logs {
+			if log.OutputQty.IsZero() {
+				continue
+			}
+			output := entity.NewInventoryMovement(run.WarehouseID, log.OutputVariantID, log.OutputQty, entity.MovementSourceProductionOutput, &run.ID, &run.StaffID)
+			cost := outputCost[log.OutputVariantID].Div(outputQty[log.OutputVariantID]).Round(4)
+			output.UnitCost = &cost
+			lot := &entity.InventoryLot{BatchNumber: run.BatchCode, ExpiryDate: run.ExpiryDate}
+			if err := receiveIntoLot(ctx, s.inventoryRepo, output, lot); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a production run with its logs
+func (s *ProductionService) GetByID(ctx context.Context, id int64) (*entity.ProductionRun, error) {
+	return s.productionRepo.GetRunByID(ctx, id)
+}
+
+// GetByBatchCode retrieves a production run by batch code, used for yield reporting
+func (s *ProductionService) GetByBatchCode(ctx context.Context, batchCode string) (*entity.ProductionRun, error) {
+	return s.productionRepo.GetRunByBatchCode(ctx, strings.TrimSpace(batchCode))
+}
+
+// List retrieves production runs with pagination
+func (s *ProductionService) List(ctx context.Context, offset, limit int) ([]entity.ProductionRun, int64, error) {
+	return s.productionRepo.ListRuns(ctx, offset, limit)
+}
+
+// Plan pre-fills the expected inputs for producing outputQty of a variant from its recipe.
+// The output is credited on the primary (first) input line so per-line yield stays meaningful.
+func (s *ProductionService) Plan(ctx context.Context, outputVariantID int64, outputQty decimal.Decimal) ([]entity.ProductionLog, error) {
+	if outputQty.LessThanOrEqual(decimal.Zero) {
+		return nil, domainErrors.ErrInvalidQuantity
+	}
+
+	recipe, err := s.recipeRepo.GetByOutputVariant(ctx, outputVariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []entity.ProductionLog
+	for i, item := range recipe.Scale(outputQty) {
+		log := entity.ProductionLog{
+			InputVariantID:  item.InputVariantID,
+			InputVariant:    item.InputVariant,
+			InputQty:        item.Quantity,
+			OutputVariantID: recipe.OutputVariantID,
+			OutputVariant:   recipe.OutputVariant,
+			OutputQty:       decimal.Zero,
+		}
+		if i == 0 {
+			log.OutputQty = outputQty
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// YieldDeviations returns production logs between from and to whose yield falls outside the recipe tolerance.
+// Only output-bearing lines consuming the recipe's primary input are compared.
+func (s *ProductionService) YieldDeviations(ctx context.Context, from, to time.Time) ([]entity.ProductionYieldDeviation, error) {
+	runs, err := s.productionRepo.ListRunsBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes := make(map[int64]*entity.Recipe)
+	deviations := []entity.ProductionYieldDeviation{}
+	for _, run := range runs {
+		for _, log := range run.Logs {
+			if log.OutputQty.IsZero() {
+				continue
+			}
+
+			recipe, cached := recipes[log.OutputVariantID]
+			if !cached {
+				recipe, err = s.recipeRepo.GetByOutputVariant(ctx, log.OutputVariantID)
+				if err != nil && err != domainErrors.ErrRecipeNotFound {
+					return nil, err
+				}
+				recipes[log.OutputVariantID] = recipe
+			}
+			if recipe == nil || len(recipe.Items) == 0 || recipe.Items[0].InputVariantID != log.InputVariantID {
+				continue
+			}
+
+			actual := log.Yield()
+			if recipe.IsYieldWithinTolerance(actual) {
+				continue
+			}
+			deviations = append(deviations, entity.ProductionYieldDeviation{
+				RunID:          run.ID,
+				BatchCode:      run.BatchCode,
+				WarehouseID:    run.WarehouseID,
+				CreatedAt:      run.CreatedAt,
+				Log:            log,
+				ActualYield:    actual.Round(2),
+				ExpectedYield:  recipe.ExpectedYield,
+				YieldTolerance: recipe.YieldTolerance,
+			})
+		}
+	}
+	return deviations, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// RecipeService handles bill of materials management for manufactured variants
+type RecipeService struct {
+	recipeRepo  repository.RecipeRepository
+	variantRepo repository.ProductVariantRepository
+}
+
+// NewRecipeService creates a new recipe service
+func NewRecipeService(recipeRepo repository.RecipeRepository, variantRepo repository.ProductVariantRepository) *RecipeService {
+	return &RecipeService{recipeRepo: recipeRepo, variantRepo: variantRepo}
+}
+
+// Create creates the recipe for a manufactured variant
+func (s *RecipeService) Create(ctx context.Context, recipe *entity.Recipe) error {
+	if err := s.validate(ctx, recipe); err != nil {
+		return err
+	}
+
+	if _, err := s.recipeRepo.GetByOutputVariant(ctx, recipe.OutputVariantID); err == nil {
+		return domainErrors.ErrRecipeExists
+	} else if err != domainErrors.ErrRecipeNotFound {
+		return err
+	}
+
+	return s.recipeRepo.Create(ctx, recipe)
+}
+
+// GetByVariant retrieves the recipe for a variant
+func (s *RecipeService) GetByVariant(ctx context.Context, variantID int64) (*entity.Recipe, error) {
+	return s.recipeRepo.GetByOutputVariant(ctx, variantID)
+}
+
+// Update replaces the recipe for a variant
+func (s *RecipeService) Update(ctx context.Context, recipe *entity.Recipe) error {
+	existing, err := s.recipeRepo.GetByOutputVariant(ctx, recipe.OutputVariantID)
+	if err != nil {
+		return err
+	}
+	recipe.ID = existing.ID
+	recipe.CreatedAt = existing.CreatedAt
+
+	if err := s.validate(ctx, recipe); err != nil {
+		return err
+	}
+	return s.recipeRepo.Update(ctx, recipe)
+}
+
+// Delete deletes the recipe for a variant
+func (s *RecipeService) Delete(ctx context.Context, variantID int64) error {
+	return s.recipeRepo.Delete(ctx, variantID)
+}
+
+func (s *RecipeService) validate(ctx context.Context, recipe *entity.Recipe) error {
+	output, err := s.variantRepo.GetByID(ctx, recipe.OutputVariantID)
+	if err != nil {
+		return domainErrors.ErrProductVariantNotFound
+	}
+	if !output.IsManufactured {
+		return domainErrors.ErrVariantNotManufactured
+	}
+
+	if recipe.OutputQty.LessThanOrEqual(decimal.Zero) {
+		return domainErrors.ErrInvalidQuantity
+	}
+	if recipe.ExpectedYield.IsNegative() || recipe.YieldTolerance.IsNegative() || len(recipe.Items) == 0 {
+		return domainErrors.ErrInvalidInput
+	}
+
+	seen := make(map[int64]bool)
+	for _, item := range recipe.Items {
+		if item.Quantity.LessThanOrEqual(decimal.Zero) {
+			return domainErrors.ErrInvalidQuantity
+		}
+		if item.InputVariantID == recipe.OutputVariantID || seen[item.InputVariantID] {
+			return domainErrors.ErrInvalidInput
+		}
+		seen[item.InputVariantID] = true
+
+		if _, err := s.variantRepo.GetByID(ctx, item.InputVariantID); err != nil {
+			return domainErrors.ErrProductVariantNotFound
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// ReplenishmentService handles reorder settings and turns low projected stock into draft
+// purchase orders with each variant's preferred supplier
+type ReplenishmentService struct {
+	replenishmentRepo repository.ReplenishmentRepository
+	supplierRepo      repository.SupplierRepository
+	warehouseRepo     repository.WarehouseRepository
+	variantRepo       repository.ProductVariantRepository
+	scorecardService  *SupplierScorecardService
+	prices            *SupplierPriceListService
+	procurements      *ProcurementService
+	txManager         repository.TxManager
+	lookbackDays      int
+}
+
+// NewReplenishmentService creates a new replenishment service. Average daily sales are taken
+// over the last lookbackDays days; each variant is ordered from the supplier the scorecard
+// service selects as preferred, at that supplier's price in force for the quantity.
+func NewReplenishmentService(
+	replenishmentRepo repository.ReplenishmentRepository,
+	supplierRepo repository.SupplierRepository,
+	warehouseRepo repository.WarehouseRepository,
+	variantRepo repository.ProductVariantRepository,
+	scorecardService *SupplierScorecardService,
+	prices *SupplierPriceListService,
+	procurements *ProcurementService,
+	txManager repository.TxManager,
+	lookbackDays int,
+) *ReplenishmentService {
+	if lookbackDays < 1 {
+		lookbackDays = 30
+	}
+	return &ReplenishmentService{
+		replenishmentRepo: replenishmentRepo,
+		supplierRepo:      supplierRepo,
+		warehouseRepo:     warehouseRepo,
+		variantRepo:       variantRepo,
+		scorecardService:  scorecardService,
+		prices:            prices,
+		procurements:      procurements,
+		txManager:         txManager,
+		lookbackDays:      lookbackDays,
+	}
+}
+
+// SetReorderSetting creates or replaces the stock control levels of a variant at a warehouse
+func (s *ReplenishmentService) SetReorderSetting(ctx context.Context, setting *entity.ReorderSetting) error {
+	if !setting.IsValid() {
+		return domainErrors.ErrInvalidInput
+	}
+	if _, err := s.warehouseRepo.GetByID(ctx, setting.WarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
+	}
+	if _, err := s.variantRepo.GetByID(ctx, setting.VariantID); err != nil {
+		return domainErrors.ErrProductVariantNotFound
+	}
+	return s.replenishmentRepo.UpsertReorderSetting(ctx, setting)
+}
+
+// DeleteReorderSetting stops replenishing a variant at a warehouse
+func (s *ReplenishmentService) DeleteReorderSetting(ctx context.Context, warehouseID, variantID int64) error {
+	return s.replenishmentRepo.DeleteReorderSetting(ctx, warehouseID, variantID)
+}
+
+// ListReorderSettings retrieves reorder settings, optionally for one warehouse
+func (s *ReplenishmentService) ListReorderSettings(ctx context.Context, warehouseID *int64) ([]entity.ReorderSetting, error) {
+	return s.replenishmentRepo.ListReorderSettings(ctx, warehouseID)
+}
+
+// Suggest lists the variants whose projected stock is at or below their reorder point, with the
+// quantity to order and their preferred supplier at its price for that quantity today
+func (s *ReplenishmentService) Suggest(ctx context.Context, warehouseID *int64) ([]entity.ReplenishmentSuggestion, error) {
+	now := time.Now()
+	since := now.AddDate(0, 0, -s.lookbackDays)
+	candidates, err := s.replenishmentRepo.ListCandidates(ctx, warehouseID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	suppliers := map[int64]*entity.Supplier{}
+	preferredByVariant := map[int64]*entity.SupplierVariant{}
+	suggestions := []entity.ReplenishmentSuggestion{}
+	for _, c := range candidates {
+		quantity, due := c.Suggest()
+		if !due || !quantity.IsPositive() {
+			continue
+		}
+		suggestion := entity.ReplenishmentSuggestion{
+			ReplenishmentCandidate: c,
+			SuggestedQuantity:      quantity,
+			DaysOfCover:            c.DaysOfCover(),
+		}
+
+		preferred, ok := preferredByVariant[c.Setting.VariantID]
+		if !ok {
+			preferred, err = s.scorecardService.PreferredSupplier(ctx, c.Setting.VariantID)
+			if err != nil && err != domainErrors.ErrNotFound {
+				return nil, err
+			}
+			preferredByVariant[c.Setting.VariantID] = preferred
+		}
+		if preferred != nil {
+			supplier, ok := suppliers[preferred.SupplierID]
+			if !ok {
+				if supplier, err = s.supplierRepo.GetByID(ctx, preferred.SupplierID); err != nil {
+					return nil, err
+				}
+				suppliers[preferred.SupplierID] = supplier
+			}
+			suggestion.SupplierID = &supplier.ID
+			suggestion.SupplierName = &supplier.Name
+
+			// Without a price list or agreed cost the line is left unpriced for the buyer
+			price, err := s.prices.EffectivePrice(ctx, supplier.ID, c.Setting.VariantID, now, quantity)
+			if err != nil && err != domainErrors.ErrSupplierPriceNotFound {
+				return nil, err
+			}
+			if err == nil {
+				suggestion.UnitCost = price.UnitPrice
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// GenerateDraftOrders turns the current suggestions into pending purchase orders, one per
+// supplier and warehouse, for a buyer to review and approve. The orders are raised like any
+// other, so their lines carry the supplier's list price. Open orders count as stock on order,
+// so running it again does not order the same shortfall twice.
+func (s *ReplenishmentService) GenerateDraftOrders(ctx context.Context, warehouseID *int64, userID int64) (*entity.ReplenishmentRun, error) {
+	run := &entity.ReplenishmentRun{
+		Procurements: []entity.Procurement{},
+		Unsourced:    []entity.ReplenishmentSuggestion{},
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		suggestions, err := s.Suggest(ctx, warehouseID)
+		if err != nil {
+			return err
+		}
+
+		type orderKey struct{ supplierID, warehouseID int64 }
+		orders := map[orderKey]*entity.Procurement{}
+		leadTimes := map[orderKey]int{}
+		var keys []orderKey
+		for _, suggestion := range suggestions {
+			if suggestion.SupplierID == nil {
+				run.Unsourced = append(run.Unsourced, suggestion)
+				continue
+			}
+			key := orderKey{*suggestion.SupplierID, suggestion.Setting.WarehouseID}
+			po, ok := orders[key]
+			if !ok {
+				po = &entity.Procurement{
+					SupplierID:      key.supplierID,
+					Supplier:        &entity.Supplier{ID: key.supplierID, Name: *suggestion.SupplierName},
+					WarehouseID:     key.warehouseID,
+					Warehouse:       &entity.Warehouse{ID: key.warehouseID, Name: suggestion.Setting.WarehouseName},
+					OrderedByUserID: userID,
+					Status:          entity.ProcurementStatusPending,
+				}
+				orders[key] = po
+				keys = append(keys, key)
+			}
+			po.Items = append(po.Items, entity.ProcurementItem{
+				VariantID: suggestion.Setting.VariantID,
+				Variant: &entity.ProductVariant{
+					ID:   suggestion.Setting.VariantID,
+					Name: suggestion.Setting.VariantName,
+					SKU:  suggestion.Setting.SKU,
+				},
+				QuantityOrdered: suggestion.SuggestedQuantity,
+				UnitCost:        suggestion.UnitCost,
+			})
+			if suggestion.Setting.LeadTimeDays > leadTimes[key] {
+				leadTimes[key] = suggestion.Setting.LeadTimeDays
+			}
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].warehouseID != keys[j].warehouseID {
+				return keys[i].warehouseID < keys[j].warehouseID
+			}
+			return keys[i].supplierID < keys[j].supplierID
+		})
+		for _, key := range keys {
+			po := orders[key]
+			if days := leadTimes[key]; days > 0 {
+				expected := time.Now().AddDate(0, 0, days)
+				po.ExpectedDelivery = &expected
+			}
+			if err := s.procurements.Create(ctx, po); err != nil {
+				return err
+			}
+			run.Procurements = append(run.Procurements, *po)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
 	"github.com/qwikshelf/api/internal/domain/repository"
@@ -98,6 +100,24 @@ func (s *RoleService) Update(ctx context.Context, id int64, name, description *s
 	return role, nil
 }
 
+// SetApprovalLimit sets the highest purchase order total the role may approve; nil lifts the limit
+func (s *RoleService) SetApprovalLimit(ctx context.Context, id int64, limit *decimal.Decimal) (*entity.Role, error) {
+	if limit != nil && limit.IsNegative() {
+		return nil, domainErrors.ErrInvalidInput
+	}
+
+	role, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainErrors.ErrRoleNotFound
+	}
+	if err := s.roleRepo.SetApprovalLimit(ctx, id, limit); err != nil {
+		return nil, err
+	}
+
+	role.POApprovalLimit = limit
+	return role, nil
+}
+
 // Delete deletes a role
 func (s *RoleService) Delete(ctx context.Context, id int64) error {
 	if _, err := s.roleRepo.GetByID(ctx, id); err != nil {
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -11,12 +13,20 @@ import (
 	"github.com/qwikshelf/api/internal/domain/repository"
 )
 
+// paymentMethodCredit marks sales charged to the customer's account
+const paymentMethodCredit = "credit"
+
 // SaleService handles sale/POS logic
 type SaleService struct {
 	saleRepo      repository.SaleRepository
 	inventoryRepo repository.InventoryRepository
 	variantRepo   repository.ProductVariantRepository
 	warehouseRepo repository.WarehouseRepository
+	pincodeRepo   repository.PincodeRepository
+	ledgerRepo    repository.CustomerLedgerRepository
+	txManager     repository.TxManager
+	// orderHold is how long a placed order holds its stock before it must be packed
+	orderHold time.Duration
 }
 
 // NewSaleService creates a new sale service
@@ -25,89 +35,463 @@ func NewSaleService(
 	inventoryRepo repository.InventoryRepository,
 	variantRepo repository.ProductVariantRepository,
 	warehouseRepo repository.WarehouseRepository,
+	pincodeRepo repository.PincodeRepository,
+	ledgerRepo repository.CustomerLedgerRepository,
+	txManager repository.TxManager,
+	orderHold time.Duration,
 ) *SaleService {
 	return &SaleService{
 		saleRepo:      saleRepo,
 		inventoryRepo: inventoryRepo,
 		variantRepo:   variantRepo,
 		warehouseRepo: warehouseRepo,
+		pincodeRepo:   pincodeRepo,
+		ledgerRepo:    ledgerRepo,
+		txManager:     txManager,
+		orderHold:     orderHold,
 	}
 }
 
-// ProcessSale validates stock and records a new sale
+// ProcessSale validates stock and records a new sale, taking its items out of stock and
+// posting their cost of goods. Stock reserved for orders and subscriptions cannot be sold.
 func (s *SaleService) ProcessSale(ctx context.Context, sale *entity.Sale) error {
+	return s.recordSale(ctx, sale, false)
+}
+
+// recordSale records a sale and either takes its items out of stock or, for an order to be picked
+// later, reserves them
+func (s *SaleService) recordSale(ctx context.Context, sale *entity.Sale, reserve bool) error {
 	// 1. Verify warehouse exists
 	if _, err := s.warehouseRepo.GetByID(ctx, sale.WarehouseID); err != nil {
 		return domainErrors.ErrWarehouseNotFound
 	}
 
-	// 2. Validate all items, resolve base variants, and check stock
-	type resolvedItem struct {
-		baseVariantID int64
-		deductQty     decimal.Decimal
+	// 2. Validate all items and resolve base variants
+	deductions, order, err := s.resolveBaseQuantities(ctx, sale.Items)
+	if err != nil {
+		return err
 	}
-	resolved := make([]resolvedItem, len(sale.Items))
 
-	for i, item := range sale.Items {
-		// Verify variant exists and get its conversion factor + family
-		variant, err := s.variantRepo.GetByID(ctx, item.VariantID)
-		if err != nil {
-			return domainErrors.ErrProductVariantNotFound
-		}
+	// Counter sales are handed over immediately; storefront orders set their own initial status
+	if sale.Status == "" {
+		sale.Status = entity.OrderStatusDelivered
+	}
+	sale.CalculateTotals()
 
-		factor := variant.ConversionFactor
-		if factor.IsZero() {
-			factor = decimal.NewFromInt(1)
-		}
+	// Credit sales are charged to a customer account
+	isCredit := sale.PaymentMethod == paymentMethodCredit
+	if isCredit && sale.CustomerID == nil {
+		return domainErrors.ErrInvalidInput
+	}
 
-		// Calculate actual base-unit quantity to deduct
-		deductQty := item.Quantity.Mul(factor)
+	// 3. Lock stock rows, record the sale and deduct inventory atomically
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var account *entity.CustomerCreditAccount
+		if isCredit {
+			var err error
+			if account, err = s.ledgerRepo.GetCreditAccountForUpdate(ctx, *sale.CustomerID); err != nil {
+				return err
+			}
+			if account.PaymentTerms == entity.PaymentTermsCash {
+				return domainErrors.ErrCreditNotAllowed
+			}
+			if !account.CanCharge(sale.TotalAmount) {
+				return domainErrors.ErrCreditLimitExceeded
+			}
+		}
 
-		// Find the base variant (conversion_factor = 1) in the same family
-		baseVariantID := variant.ID
-		if factor.GreaterThan(decimal.NewFromInt(1)) {
-			siblings, err := s.variantRepo.ListByFamily(ctx, variant.FamilyID)
-			if err != nil {
+		if reserve {
+			if err := s.saleRepo.Create(ctx, sale); err != nil {
 				return err
 			}
-			for _, sib := range siblings {
-				cf := sib.ConversionFactor
-				if cf.IsZero() {
-					cf = decimal.NewFromInt(1)
+			expiresAt := time.Now().Add(s.orderHold)
+			for _, variantID := range order {
+				if err := reserveStock(ctx, s.inventoryRepo, &entity.StockReservation{
+					WarehouseID: sale.WarehouseID,
+					VariantID:   variantID,
+					Quantity:    deductions[variantID],
+					SourceType:  entity.ReservationSourceOrder,
+					ReferenceID: sale.ID,
+					ExpiresAt:   expiresAt,
+				}); err != nil {
+					return err
 				}
-				if cf.Equal(decimal.NewFromInt(1)) {
-					baseVariantID = sib.ID
-					break
+			}
+		} else {
+			// Stock is taken first-expiry-first-out; expired lots cannot be sold
+			allocations := make(map[int64][]entity.LotAllocation, len(order))
+			for _, variantID := range order {
+				planned, err := allocateFEFO(ctx, s.inventoryRepo, sale.WarehouseID, variantID, deductions[variantID])
+				if err != nil {
+					return err
 				}
+				allocations[variantID] = planned
+			}
+
+			if err := s.saleRepo.Create(ctx, sale); err != nil {
+				return err
+			}
+
+			issued := make(map[int64]decimal.Decimal, len(order))
+			for _, variantID := range order {
+				movement := entity.NewInventoryMovement(sale.WarehouseID, variantID, deductions[variantID].Neg(), entity.MovementSourceSale, &sale.ID, sale.ProcessedByUserID)
+				movements, err := postWithdrawal(ctx, s.inventoryRepo, movement, allocations[variantID])
+				if err != nil {
+					return err
+				}
+				issued[variantID] = issuedCost(movements)
+			}
+			if err := s.postCostOfGoods(ctx, sale, issued); err != nil {
+				return err
 			}
 		}
 
-		// Check stock level at warehouse using base variant
-		level, err := s.inventoryRepo.GetLevel(ctx, sale.WarehouseID, baseVariantID)
+		if isCredit {
+			dueDate := account.DueDateFor(sale.CreatedAt)
+			return s.ledgerRepo.AddEntry(ctx, &entity.CustomerLedgerEntry{
+				CustomerID:      *sale.CustomerID,
+				EntryType:       entity.LedgerEntrySale,
+				SaleID:          &sale.ID,
+				Debit:           sale.TotalAmount,
+				Description:     fmt.Sprintf("Sale #%d", sale.ID),
+				EntryDate:       sale.CreatedAt,
+				DueDate:         &dueDate,
+				CreatedByUserID: sale.ProcessedByUserID,
+			})
+		}
+		return nil
+	})
+}
+
+// PlaceOrder records a storefront order for delivery to the given address.
+// The order is fulfilled from the warehouse of the delivery zone serving the address (a saved
+// address's assigned zone, otherwise the zone mapped to its pincode), must meet the zone's
+// minimum order amount and carries the zone's delivery charge. Its items are reserved at the
+// warehouse and taken out of stock when the order is packed.
+func (s *SaleService) PlaceOrder(ctx context.Context, sale *entity.Sale) error {
+	if sale.DeliveryAddress == nil || sale.DeliveryAddress.Pincode == "" {
+		return domainErrors.ErrInvalidInput
+	}
+
+	zone, err := s.resolveDeliveryZone(ctx, sale)
+	if err != nil {
+		return err
+	}
+	if zone == nil || !zone.IsActive || zone.WarehouseID == nil {
+		return domainErrors.ErrPincodeNotServiceable
+	}
+
+	if sale.Subtotal().LessThan(decimal.NewFromFloat(zone.MinOrderAmount)) {
+		return domainErrors.ErrBelowMinOrderAmount
+	}
+
+	sale.WarehouseID = *zone.WarehouseID
+	sale.ZoneID = &zone.ID
+	sale.DeliveryCharge = decimal.NewFromFloat(zone.DeliveryCharge)
+	sale.Status = entity.OrderStatusPlaced
+	return s.recordSale(ctx, sale, true)
+}
+
+// resolveDeliveryZone returns the zone pre-assigned to the order, or the zone serving its pincode
+func (s *SaleService) resolveDeliveryZone(ctx context.Context, sale *entity.Sale) (*entity.DeliveryZone, error) {
+	if sale.ZoneID != nil {
+		return s.pincodeRepo.GetZone(ctx, *sale.ZoneID)
+	}
+
+	area, err := s.pincodeRepo.GetByPincode(ctx, sale.DeliveryAddress.Pincode)
+	if err != nil || area == nil {
+		return nil, err
+	}
+	return &entity.DeliveryZone{
+		ID:             area.ZoneID,
+		Name:           area.ZoneName,
+		WarehouseID:    area.WarehouseID,
+		MinOrderAmount: area.MinOrderAmount,
+		DeliveryCharge: area.DeliveryCharge,
+		IsActive:       area.IsActive,
+	}, nil
+}
+
+// restock returns a cancelled or returned sale's stock to the lots it was taken from by reversing
+// its ledger movements. Sales recorded before the ledger existed are restocked from their items
+// instead; an order that reserved its items has nothing to restock until it is packed.
+func (s *SaleService) restock(ctx context.Context, sale *entity.Sale, quantities map[int64]decimal.Decimal, order []int64, userID *int64, reserved bool) error {
+	taken, err := s.inventoryRepo.ListMovementsByReference(ctx, entity.MovementSourceSale, sale.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(taken) == 0 {
+		if reserved {
+			return nil
+		}
+		for _, variantID := range order {
+			movement := entity.NewInventoryMovement(sale.WarehouseID, variantID, quantities[variantID], entity.MovementSourceSaleCancellation, &sale.ID, userID)
+			if err := s.inventoryRepo.AdjustLevel(ctx, movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, m := range taken {
+		movement := entity.NewInventoryMovement(m.WarehouseID, m.VariantID, m.QuantityDelta.Neg(), entity.MovementSourceSaleCancellation, &sale.ID, userID)
+		movement.LotID = m.LotID
+		movement.UnitCost = m.UnitCost
+		if err := s.inventoryRepo.AdjustLevel(ctx, movement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pick takes a reserved order's items out of stock when it is packed, posts their cost of goods
+// and marks its reservations fulfilled. Items still held by the order's reservation are taken regardless of later ones; once
+// the hold has lapsed they can only come out of unreserved stock. Orders placed before
+// reservations existed took their stock when placed.
+func (s *SaleService) pick(ctx context.Context, sale *entity.Sale, quantities map[int64]decimal.Decimal, order []int64, reservations []entity.StockReservation, userID *int64) error {
+	now := time.Now()
+	held := make(map[int64]bool, len(reservations))
+	for i := range reservations {
+		if reservations[i].IsHeld(now) {
+			held[reservations[i].VariantID] = true
+		}
+	}
+
+	if _, err := s.inventoryRepo.CloseReservations(ctx, repository.ReservationScope{
+		SourceType:  entity.ReservationSourceOrder,
+		ReferenceID: sale.ID,
+	}, entity.ReservationStatusFulfilled); err != nil {
+		return err
+	}
+
+	issued := make(map[int64]decimal.Decimal, len(order))
+	for _, variantID := range order {
+		allocateFn := allocateFEFO
+		if held[variantID] {
+			allocateFn = allocateReserved
+		}
+		allocations, err := allocateFn(ctx, s.inventoryRepo, sale.WarehouseID, variantID, quantities[variantID])
 		if err != nil {
 			return err
 		}
-		if level.Quantity.LessThan(deductQty) {
-			return domainErrors.ErrInsufficientStock
+		movement := entity.NewInventoryMovement(sale.WarehouseID, variantID, quantities[variantID].Neg(), entity.MovementSourceSale, &sale.ID, userID)
+		movements, err := postWithdrawal(ctx, s.inventoryRepo, movement, allocations)
+		if err != nil {
+			return err
 		}
+		issued[variantID] = issuedCost(movements)
+	}
+	return s.postCostOfGoods(ctx, sale, issued)
+}
 
-		resolved[i] = resolvedItem{baseVariantID: baseVariantID, deductQty: deductQty}
+// postCostOfGoods splits the cost of the stock issued for a sale, per base variant, across its
+// items by their share of the base quantity and saves it on the items
+func (s *SaleService) postCostOfGoods(ctx context.Context, sale *entity.Sale, issued map[int64]decimal.Decimal) error {
+	baseIDs := make([]int64, len(sale.Items))
+	baseQty := make([]decimal.Decimal, len(sale.Items))
+	totals := make(map[int64]decimal.Decimal, len(issued))
+	for i, item := range sale.Items {
+		baseID, factor, err := resolveBaseVariant(ctx, s.variantRepo, item.VariantID)
+		if err != nil {
+			return err
+		}
+		baseIDs[i] = baseID
+		baseQty[i] = item.Quantity.Mul(factor)
+		totals[baseID] = totals[baseID].Add(baseQty[i])
 	}
 
-	// 3. Record the sale in the repository (includes item creation)
-	sale.CalculateTotals()
-	if err := s.saleRepo.Create(ctx, sale); err != nil {
-		return err
+	// The last item of each base variant takes what rounding leaves, so the items add up to the issue
+	left := make(map[int64]decimal.Decimal, len(issued))
+	for id, cost := range issued {
+		left[id] = cost
+	}
+	unallocated := make(map[int64]decimal.Decimal, len(totals))
+	for id, qty := range totals {
+		unallocated[id] = qty
+	}
+
+	total := decimal.Zero
+	for i := range sale.Items {
+		id := baseIDs[i]
+		unallocated[id] = unallocated[id].Sub(baseQty[i])
+		cost := left[id]
+		if unallocated[id].IsPositive() {
+			cost = issued[id].Mul(baseQty[i]).Div(totals[id]).Round(4)
+		}
+		left[id] = left[id].Sub(cost)
+		sale.Items[i].CostOfGoods = &cost
+		total = total.Add(cost)
+	}
+	sale.CostOfGoods = &total
+	return s.saleRepo.SetItemCosts(ctx, sale.Items)
+}
+
+// issuedCost is the cost of the stock taken out by a withdrawal's movements
+func issuedCost(movements []entity.InventoryMovement) decimal.Decimal {
+	cost := decimal.Zero
+	for _, m := range movements {
+		if m.TotalCost != nil {
+			cost = cost.Sub(*m.TotalCost)
+		}
+	}
+	return cost
+}
+
+// resolveBaseQuantities converts sale items into base-variant quantities (using each variant's
+// conversion factor) aggregated per base variant, returned with the variant IDs sorted so
+// callers lock inventory rows in a stable order.
+func (s *SaleService) resolveBaseQuantities(ctx context.Context, items []entity.SaleItem) (map[int64]decimal.Decimal, []int64, error) {
+	quantities := make(map[int64]decimal.Decimal)
+	var order []int64
+
+	for _, item := range items {
+		baseVariantID, factor, err := resolveBaseVariant(ctx, s.variantRepo, item.VariantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		baseQty := item.Quantity.Mul(factor)
+
+		if _, seen := quantities[baseVariantID]; !seen {
+			order = append(order, baseVariantID)
+		}
+		quantities[baseVariantID] = quantities[baseVariantID].Add(baseQty)
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	return quantities, order, nil
+}
+
+// resolveBaseVariant returns the base variant (conversion factor 1) of a variant's family, which
+// stock is held in, and how many base units one unit of the variant is
+func resolveBaseVariant(ctx context.Context, variantRepo repository.ProductVariantRepository, variantID int64) (int64, decimal.Decimal, error) {
+	// Verify variant exists and get its conversion factor + family
+	variant, err := variantRepo.GetByID(ctx, variantID)
+	if err != nil {
+		return 0, decimal.Zero, domainErrors.ErrProductVariantNotFound
+	}
+
+	factor := variant.ConversionFactor
+	if factor.IsZero() {
+		factor = decimal.NewFromInt(1)
+	}
+
+	// Find the base variant (conversion_factor = 1) in the same family
+	baseVariantID := variant.ID
+	if factor.GreaterThan(decimal.NewFromInt(1)) {
+		siblings, err := variantRepo.ListByFamily(ctx, variant.FamilyID)
+		if err != nil {
+			return 0, decimal.Zero, err
+		}
+		for _, sib := range siblings {
+			cf := sib.ConversionFactor
+			if cf.IsZero() {
+				cf = decimal.NewFromInt(1)
+			}
+			if cf.Equal(decimal.NewFromInt(1)) {
+				baseVariantID = sib.ID
+				break
+			}
+		}
+	}
+	return baseVariantID, factor, nil
+}
+
+// UpdateOrderStatus moves an order to the next status and records the transition.
+// Packing a reserved order takes its items out of stock; cancelling or returning an order releases
+// its reservations and puts any items already taken back in stock.
+func (s *SaleService) UpdateOrderStatus(ctx context.Context, id int64, status entity.OrderStatus, userID *int64, note string) (*entity.Sale, error) {
+	if !status.IsValid() {
+		return nil, domainErrors.ErrInvalidInput
 	}
 
-	// 4. Deduct inventory from base variants
-	for _, r := range resolved {
-		if err := s.inventoryRepo.AdjustLevel(ctx, sale.WarehouseID, r.baseVariantID, r.deductQty.Neg()); err != nil {
+	sale, err := s.saleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sale.Status.CanTransitionTo(status) {
+		return nil, domainErrors.ErrInvalidStatusTransition
+	}
+
+	undoing := status == entity.OrderStatusCancelled || status == entity.OrderStatusReturned
+	var quantities map[int64]decimal.Decimal
+	var order []int64
+	if undoing || status == entity.OrderStatusPacked {
+		if quantities, order, err = s.resolveBaseQuantities(ctx, sale.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.saleRepo.UpdateStatus(ctx, id, sale.Status, status); err != nil {
+			return err
+		}
+		if err := s.saleRepo.AddStatusEvent(ctx, &entity.OrderStatusEvent{
+			SaleID:          id,
+			Status:          status,
+			Note:            note,
+			ChangedByUserID: userID,
+		}); err != nil {
 			return err
 		}
+
+		if undoing || status == entity.OrderStatusPacked {
+			reservations, err := s.inventoryRepo.ListReservationsByReference(ctx, entity.ReservationSourceOrder, sale.ID)
+			if err != nil {
+				return err
+			}
+
+			if status == entity.OrderStatusPacked {
+				if len(reservations) > 0 {
+					if err := s.pick(ctx, sale, quantities, order, reservations, userID); err != nil {
+						return err
+					}
+				}
+			} else {
+				if _, err := s.inventoryRepo.CloseReservations(ctx, repository.ReservationScope{
+					SourceType:  entity.ReservationSourceOrder,
+					ReferenceID: sale.ID,
+				}, entity.ReservationStatusReleased); err != nil {
+					return err
+				}
+				if err := s.restock(ctx, sale, quantities, order, userID, len(reservations) > 0); err != nil {
+					return err
+				}
+				// The goods are back in stock, so the sale carries no cost
+				for i := range sale.Items {
+					sale.Items[i].CostOfGoods = nil
+				}
+				sale.CostOfGoods = nil
+				if err := s.saleRepo.SetItemCosts(ctx, sale.Items); err != nil {
+					return err
+				}
+			}
+		}
+
+		// A cancelled or returned credit sale is no longer owed
+		if sale.PaymentMethod == paymentMethodCredit && sale.CustomerID != nil && undoing {
+			return s.ledgerRepo.AddEntry(ctx, &entity.CustomerLedgerEntry{
+				CustomerID:      *sale.CustomerID,
+				EntryType:       entity.LedgerEntryReversal,
+				SaleID:          &sale.ID,
+				Credit:          sale.TotalAmount,
+				Description:     fmt.Sprintf("Sale #%d %s", sale.ID, status),
+				CreatedByUserID: userID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	sale.Status = status
+	return sale, nil
+}
+
+// GetStatusHistory retrieves the status timeline of an order
+func (s *SaleService) GetStatusHistory(ctx context.Context, id int64) ([]entity.OrderStatusEvent, error) {
+	return s.saleRepo.ListStatusHistory(ctx, id)
 }
 
 // GetByID retrieves a sale with full details
@@ -116,8 +500,8 @@ func (s *SaleService) GetByID(ctx context.Context, id int64) (*entity.Sale, erro
 }
 
 // List retrieves all sales with pagination and filtering
-func (s *SaleService) List(ctx context.Context, warehouseID *int64, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error) {
-	return s.saleRepo.List(ctx, warehouseID, startDate, endDate, offset, limit)
+func (s *SaleService) List(ctx context.Context, warehouseID *int64, status *entity.OrderStatus, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error) {
+	return s.saleRepo.List(ctx, warehouseID, status, startDate, endDate, offset, limit)
 }
 
 // ListByCustomer retrieves sales for a specific customer
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// StockReservationService holds stock for subscription deliveries, sweeps lapsed reservations
+// and reports the stock still available to promise
+type StockReservationService struct {
+	inventoryRepo    repository.InventoryRepository
+	subscriptionRepo repository.SubscriptionRepository
+	addressRepo      repository.CustomerAddressRepository
+	pincodeRepo      repository.PincodeRepository
+	variantRepo      repository.ProductVariantRepository
+	txManager        repository.TxManager
+}
+
+// NewStockReservationService creates a new stock reservation service
+func NewStockReservationService(
+	inventoryRepo repository.InventoryRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	addressRepo repository.CustomerAddressRepository,
+	pincodeRepo repository.PincodeRepository,
+	variantRepo repository.ProductVariantRepository,
+	txManager repository.TxManager,
+) *StockReservationService {
+	return &StockReservationService{
+		inventoryRepo:    inventoryRepo,
+		subscriptionRepo: subscriptionRepo,
+		addressRepo:      addressRepo,
+		pincodeRepo:      pincodeRepo,
+		variantRepo:      variantRepo,
+		txManager:        txManager,
+	}
+}
+
+// List retrieves reservations matching the filter
+func (s *StockReservationService) List(ctx context.Context, filter repository.ReservationFilter, offset, limit int) ([]entity.StockReservation, int64, error) {
+	return s.inventoryRepo.ListReservations(ctx, filter, offset, limit)
+}
+
+// Availability returns the stock on hand, reserved and available per warehouse and variant
+func (s *StockReservationService) Availability(ctx context.Context, filter repository.AvailabilityFilter) ([]entity.StockAvailability, error) {
+	return s.inventoryRepo.GetAvailability(ctx, filter)
+}
+
+// ExpireStale marks reservations past their expiry time as expired and returns how many there were
+func (s *StockReservationService) ExpireStale(ctx context.Context) (int64, error) {
+	return s.inventoryRepo.ExpireReservations(ctx, time.Now())
+}
+
+// ReserveRoster holds stock for the subscription deliveries due on a date at the warehouse of the
+// delivery zone serving each subscription's address. Reservations already made for the date are
+// replaced, so it is safe to re-run after the roster changes; deliveries already recorded are left
+// out. A subscription whose items cannot all be covered is reported short and reserves nothing.
+func (s *StockReservationService) ReserveRoster(ctx context.Context, date time.Time) (*entity.RosterReservationRun, error) {
+	day := calendarDay(date)
+	roster, err := s.subscriptionRepo.GetDailyRoster(ctx, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	run := &entity.RosterReservationRun{
+		Date:         day,
+		Reservations: []entity.StockReservation{},
+		Unassigned:   []int64{},
+		Short:        []int64{},
+	}
+	// Deliveries are due all day, so their stock is held until the day is over
+	expiresAt := day.AddDate(0, 0, 1)
+
+	for _, item := range roster {
+		sub := item.Subscription
+		if item.Delivery != nil {
+			continue
+		}
+
+		warehouseID, err := s.subscriptionWarehouse(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		if warehouseID == nil {
+			run.Unassigned = append(run.Unassigned, sub.ID)
+			continue
+		}
+
+		quantities, order, err := s.baseQuantities(ctx, sub.Items)
+		if err != nil {
+			return nil, err
+		}
+
+		var reserved []entity.StockReservation
+		err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := s.inventoryRepo.CloseReservations(ctx, repository.ReservationScope{
+				SourceType:  entity.ReservationSourceSubscription,
+				ReferenceID: sub.ID,
+				From:        &day,
+				To:          &day,
+			}, entity.ReservationStatusReleased); err != nil {
+				return err
+			}
+
+			reserved = reserved[:0]
+			for _, variantID := range order {
+				reservation := entity.StockReservation{
+					WarehouseID: *warehouseID,
+					VariantID:   variantID,
+					Quantity:    quantities[variantID],
+					SourceType:  entity.ReservationSourceSubscription,
+					ReferenceID: sub.ID,
+					ReservedFor: &day,
+					ExpiresAt:   expiresAt,
+				}
+				if err := reserveStock(ctx, s.inventoryRepo, &reservation); err != nil {
+					return err
+				}
+				reserved = append(reserved, reservation)
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+			run.Reservations = append(run.Reservations, reserved...)
+		case errors.Is(err, domainErrors.ErrInsufficientStock),
+			errors.Is(err, domainErrors.ErrStockReserved),
+			errors.Is(err, domainErrors.ErrStockExpired):
+			run.Short = append(run.Short, sub.ID)
+		default:
+			return nil, err
+		}
+	}
+
+	return run, nil
+}
+
+// IssueDelivery takes a subscription's items for a delivered day out of stock and marks the day's
+// reservations fulfilled. The stock comes from the warehouse that held it for the day, or the
+// warehouse serving the subscription when nothing was reserved. Items still held for the day are
+// taken regardless of later reservations; once the hold has lapsed they can only come out of
+// unreserved stock. It must run in the transaction recording the delivery.
+func (s *StockReservationService) IssueDelivery(ctx context.Context, sub *entity.Subscription, delivery *entity.SubscriptionDelivery) error {
+	day := calendarDay(delivery.DeliveryDate)
+	overrides, err := s.subscriptionRepo.ListOverrides(ctx, sub.ID, day)
+	if err != nil {
+		return err
+	}
+	var onDay []entity.SubscriptionDateOverride
+	for _, o := range overrides {
+		if calendarDay(o.DeliveryDate).Equal(day) {
+			onDay = append(onDay, *o)
+		}
+	}
+	quantities, order, err := s.baseQuantities(ctx, sub.ItemsFor(day, onDay))
+	if err != nil {
+		return err
+	}
+
+	reservations, err := s.inventoryRepo.ListReservationsByReference(ctx, entity.ReservationSourceSubscription, sub.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var warehouseID *int64
+	held := make(map[int64]bool, len(reservations))
+	for i := range reservations {
+		r := &reservations[i]
+		if r.ReservedFor == nil || !calendarDay(*r.ReservedFor).Equal(day) || !r.IsHeld(now) {
+			continue
+		}
+		warehouseID = &r.WarehouseID
+		held[r.VariantID] = true
+	}
+	if warehouseID == nil {
+		warehouseID, err = s.subscriptionWarehouse(ctx, sub)
+		if err != nil {
+			return err
+		}
+		if warehouseID == nil {
+			return domainErrors.ErrNoServingWarehouse
+		}
+	}
+
+	if _, err := s.inventoryRepo.CloseReservations(ctx, repository.ReservationScope{
+		SourceType:  entity.ReservationSourceSubscription,
+		ReferenceID: sub.ID,
+		From:        &day,
+		To:          &day,
+	}, entity.ReservationStatusFulfilled); err != nil {
+		return err
+	}
+
+	for _, variantID := range order {
+		allocateFn := allocateFEFO
+		if held[variantID] {
+			allocateFn = allocateReserved
+		}
+		allocations, err := allocateFn(ctx, s.inventoryRepo, *warehouseID, variantID, quantities[variantID])
+		if err != nil {
+			return err
+		}
+		movement := entity.NewInventoryMovement(*warehouseID, variantID, quantities[variantID].Neg(), entity.MovementSourceSubscriptionDelivery, &delivery.ID, delivery.RecordedBy)
+		if _, err := postWithdrawal(ctx, s.inventoryRepo, movement, allocations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscriptionWarehouse returns the warehouse of the delivery zone serving the subscription's
+// address, or the customer's default address when it has none. It is nil when no active zone
+// with a warehouse serves the address.
+func (s *StockReservationService) subscriptionWarehouse(ctx context.Context, sub *entity.Subscription) (*int64, error) {
+	var address *entity.CustomerAddress
+	if sub.AddressID != nil {
+		a, err := s.addressRepo.GetByID(ctx, *sub.AddressID)
+		if err != nil && !domainErrors.IsNotFound(err) {
+			return nil, err
+		}
+		address = a
+	} else {
+		addresses, err := s.addressRepo.ListByCustomer(ctx, sub.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range addresses {
+			if addresses[i].IsDefault {
+				address = &addresses[i]
+				break
+			}
+		}
+	}
+	if address == nil {
+		return nil, nil
+	}
+
+	if address.ZoneID != nil {
+		zone, err := s.pincodeRepo.GetZone(ctx, *address.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		if zone != nil && zone.IsActive {
+			return zone.WarehouseID, nil
+		}
+		return nil, nil
+	}
+
+	area, err := s.pincodeRepo.GetByPincode(ctx, address.Pincode)
+	if err != nil {
+		return nil, err
+	}
+	if area == nil || !area.IsActive {
+		return nil, nil
+	}
+	return area.WarehouseID, nil
+}
+
+// baseQuantities converts subscription items into base-variant quantities, with the variant IDs
+// sorted so stock rows are locked in a stable order
+func (s *StockReservationService) baseQuantities(ctx context.Context, items []entity.SubscriptionItem) (map[int64]decimal.Decimal, []int64, error) {
+	quantities := make(map[int64]decimal.Decimal, len(items))
+	var order []int64
+	for _, item := range items {
+		baseID, factor, err := resolveBaseVariant(ctx, s.variantRepo, item.VariantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, seen := quantities[baseID]; !seen {
+			order = append(order, baseID)
+		}
+		quantities[baseID] = quantities[baseID].Add(item.Quantity.Mul(factor))
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	return quantities, order, nil
+}
+
+// AvailableToPromise returns how many units of each variant can still be ordered: the available
+// stock of its base variant divided by its conversion factor, rounded down. With a pincode it is
+// the stock at the warehouse serving it (ErrPincodeNotServiceable when none does), otherwise
+// across all warehouses.
+func (s *StockReservationService) AvailableToPromise(ctx context.Context, variants []entity.ProductVariant, pincode string) (map[int64]decimal.Decimal, error) {
+	filter := repository.AvailabilityFilter{}
+	if pincode != "" {
+		area, err := s.pincodeRepo.GetByPincode(ctx, pincode)
+		if err != nil {
+			return nil, err
+		}
+		if area == nil || !area.IsActive || area.WarehouseID == nil {
+			return nil, domainErrors.ErrPincodeNotServiceable
+		}
+		filter.WarehouseID = area.WarehouseID
+	}
+
+	bases := make(map[int64]int64, len(variants))
+	factors := make(map[int64]decimal.Decimal, len(variants))
+	for _, v := range variants {
+		baseID, factor, err := resolveBaseVariant(ctx, s.variantRepo, v.ID)
+		if err != nil {
+			return nil, err
+		}
+		filter.VariantIDs = append(filter.VariantIDs, baseID)
+		bases[v.ID] = baseID
+		factors[v.ID] = factor
+	}
+
+	available := make(map[int64]decimal.Decimal, len(variants))
+	if len(filter.VariantIDs) == 0 {
+		return available, nil
+	}
+	positions, err := s.inventoryRepo.GetAvailability(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	baseAvailable := make(map[int64]decimal.Decimal)
+	for i := range positions {
+		baseAvailable[positions[i].VariantID] = baseAvailable[positions[i].VariantID].Add(positions[i].Available())
+	}
+
+	for variantID, baseID := range bases {
+		available[variantID] = baseAvailable[baseID].Div(factors[variantID]).Floor()
+	}
+	return available, nil
+}
+
+// calendarDay strips the time of day from a date
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// cycleCountLookbackDays is the window of consumption used to rank variants into ABC classes
+const cycleCountLookbackDays = 90
+
+// StockTakeService handles physical stock counts and their reconciliation with stock levels
+type StockTakeService struct {
+	stockTakeRepo    repository.StockTakeRepository
+	warehouseRepo    repository.WarehouseRepository
+	variantRepo      repository.ProductVariantRepository
+	inventoryService *InventoryService
+	txManager        repository.TxManager
+}
+
+// NewStockTakeService creates a new stock-take service
+func NewStockTakeService(
+	stockTakeRepo repository.StockTakeRepository,
+	warehouseRepo repository.WarehouseRepository,
+	variantRepo repository.ProductVariantRepository,
+	inventoryService *InventoryService,
+	txManager repository.TxManager,
+) *StockTakeService {
+	return &StockTakeService{
+		stockTakeRepo:    stockTakeRepo,
+		warehouseRepo:    warehouseRepo,
+		variantRepo:      variantRepo,
+		inventoryService: inventoryService,
+		txManager:        txManager,
+	}
+}
+
+// Start opens a stock-take session at a warehouse. A full count snapshots every stocked variant;
+// a cycle count snapshots only the variants of its ABC class that are due for counting.
+func (s *StockTakeService) Start(ctx context.Context, st *entity.StockTake) error {
+	if _, err := s.warehouseRepo.GetByID(ctx, st.WarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
+	}
+
+	var variantIDs []int64
+	switch st.Scope {
+	case entity.StockTakeScopeFull:
+		st.ABCClass = nil
+	case entity.StockTakeScopeCycle:
+		if st.ABCClass == nil || !st.ABCClass.IsValid() {
+			return fmt.Errorf("cycle counts need an ABC class: %w", domainErrors.ErrInvalidInput)
+		}
+		items, err := s.CycleCountPlan(ctx, st.WarehouseID, time.Now())
+		if err != nil {
+			return err
+		}
+		variantIDs = []int64{}
+		for _, item := range items {
+			if item.ABCClass == *st.ABCClass && item.IsDue(time.Now()) {
+				variantIDs = append(variantIDs, item.VariantID)
+			}
+		}
+		if len(variantIDs) == 0 {
+			return fmt.Errorf("no class %s items are due for counting: %w", *st.ABCClass, domainErrors.ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("unknown stock take scope: %w", domainErrors.ErrInvalidInput)
+	}
+
+	st.Status = entity.StockTakeStatusOpen
+	return s.stockTakeRepo.Create(ctx, st, variantIDs)
+}
+
+// GetByID retrieves a stock take with its lines
+func (s *StockTakeService) GetByID(ctx context.Context, id int64) (*entity.StockTake, error) {
+	return s.stockTakeRepo.GetByID(ctx, id)
+}
+
+// List retrieves stock takes matching the filter
+func (s *StockTakeService) List(ctx context.Context, filter repository.StockTakeFilter, offset, limit int) ([]entity.StockTake, int64, error) {
+	return s.stockTakeRepo.List(ctx, filter, offset, limit)
+}
+
+// RecordCount records a counted quantity for a variant, identified by ID or by barcode.
+// With add the quantity is added to what has been counted so far, as when scanning items one by one.
+func (s *StockTakeService) RecordCount(ctx context.Context, id int64, variantID *int64, barcode string, quantity decimal.Decimal, add bool, userID *int64) (*entity.StockTakeLine, error) {
+	if quantity.IsNegative() {
+		return nil, domainErrors.ErrInvalidQuantity
+	}
+
+	st, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != entity.StockTakeStatusOpen {
+		return nil, domainErrors.ErrStockTakeNotOpen
+	}
+
+	var resolvedID int64
+	switch {
+	case barcode != "":
+		variant, err := s.variantRepo.GetByBarcode(ctx, barcode)
+		if err != nil {
+			return nil, domainErrors.ErrProductVariantNotFound
+		}
+		resolvedID = variant.ID
+	case variantID != nil:
+		if _, err := s.variantRepo.GetByID(ctx, *variantID); err != nil {
+			return nil, domainErrors.ErrProductVariantNotFound
+		}
+		resolvedID = *variantID
+	default:
+		return nil, fmt.Errorf("a variant ID or barcode is required: %w", domainErrors.ErrInvalidInput)
+	}
+
+	return s.stockTakeRepo.RecordCount(ctx, id, resolvedID, quantity, add, userID)
+}
+
+// Variance compares counted with expected quantities for a session
+func (s *StockTakeService) Variance(ctx context.Context, id int64) (*entity.StockTakeVarianceReport, error) {
+	st, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewStockTakeVarianceReport(st), nil
+}
+
+// Post turns every counted variance into an approved count-correction adjustment and closes
+// the session, all in one transaction. Variances are taken against the snapshot plus what moved
+// until each count, so stock sold or received during the count is not corrected twice.
+// Uncounted lines are left alone.
+func (s *StockTakeService) Post(ctx context.Context, id int64, userID int64) (*entity.StockTakeVarianceReport, error) {
+	var st *entity.StockTake
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if st, err = s.stockTakeRepo.GetByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if st.Status != entity.StockTakeStatusOpen {
+			return domainErrors.ErrStockTakeNotOpen
+		}
+
+		notes := fmt.Sprintf("Stock take #%d", st.ID)
+		for i := range st.Lines {
+			line := &st.Lines[i]
+			variance := line.Variance()
+			if variance.IsZero() {
+				continue
+			}
+
+			adjustment := &entity.InventoryAdjustment{
+				WarehouseID:       st.WarehouseID,
+				VariantID:         line.VariantID,
+				ReasonCode:        entity.AdjustmentReasonCountCorrection,
+				QuantityDelta:     variance,
+				Notes:             &notes,
+				RequestedByUserID: line.CountedByUserID,
+			}
+			adjustment.Price(line.UnitCost)
+			if err := s.inventoryService.ApplyApprovedAdjustment(ctx, adjustment, &userID); err != nil {
+				return err
+			}
+			if err := s.stockTakeRepo.SetLineAdjustment(ctx, line.ID, adjustment.ID); err != nil {
+				return err
+			}
+			line.AdjustmentID = &adjustment.ID
+		}
+
+		return s.close(ctx, st, entity.StockTakeStatusPosted, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewStockTakeVarianceReport(st), nil
+}
+
+// Cancel abandons an open session without changing stock
+func (s *StockTakeService) Cancel(ctx context.Context, id int64, userID int64) (*entity.StockTake, error) {
+	var st *entity.StockTake
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if st, err = s.stockTakeRepo.GetByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if st.Status != entity.StockTakeStatusOpen {
+			return domainErrors.ErrStockTakeNotOpen
+		}
+		return s.close(ctx, st, entity.StockTakeStatusCancelled, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *StockTakeService) close(ctx context.Context, st *entity.StockTake, status entity.StockTakeStatus, userID int64) error {
+	now := time.Now()
+	st.Status = status
+	st.ClosedByUserID = &userID
+	st.ClosedAt = &now
+	return s.stockTakeRepo.Close(ctx, st)
+}
+
+// CycleCountPlan ranks the variants stocked at a warehouse into ABC classes by the value they
+// consumed over the last 90 days, and works out when each is next due for counting
+func (s *StockTakeService) CycleCountPlan(ctx context.Context, warehouseID int64, asOf time.Time) ([]entity.CycleCountItem, error) {
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, domainErrors.ErrWarehouseNotFound
+	}
+
+	items, err := s.stockTakeRepo.ListCycleCountItems(ctx, warehouseID, asOf.AddDate(0, 0, -cycleCountLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := s.cycleIntervals(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	entity.ClassifyABC(items)
+	for i := range items {
+		item := &items[i]
+		if item.LastCountedAt == nil {
+			item.DueAt = asOf // never counted: due now
+			continue
+		}
+		item.DueAt = item.LastCountedAt.AddDate(0, 0, intervals[item.ABCClass])
+	}
+	return items, nil
+}
+
+// CycleSchedules returns the count interval of each ABC class at a warehouse, defaults included
+func (s *StockTakeService) CycleSchedules(ctx context.Context, warehouseID int64) ([]entity.CycleCountSchedule, error) {
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, domainErrors.ErrWarehouseNotFound
+	}
+	intervals, err := s.cycleIntervals(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []entity.CycleCountSchedule{}
+	for _, class := range []entity.ABCClass{entity.ABCClassA, entity.ABCClassB, entity.ABCClassC} {
+		schedules = append(schedules, entity.CycleCountSchedule{WarehouseID: warehouseID, ABCClass: class, IntervalDays: intervals[class]})
+	}
+	return schedules, nil
+}
+
+// SetCycleSchedule sets how often an ABC class is counted at a warehouse
+func (s *StockTakeService) SetCycleSchedule(ctx context.Context, schedule *entity.CycleCountSchedule) error {
+	if !schedule.ABCClass.IsValid() || schedule.IntervalDays < 1 {
+		return domainErrors.ErrInvalidInput
+	}
+	if _, err := s.warehouseRepo.GetByID(ctx, schedule.WarehouseID); err != nil {
+		return domainErrors.ErrWarehouseNotFound
+	}
+	return s.stockTakeRepo.SetCycleSchedule(ctx, schedule)
+}
+
+// cycleIntervals returns the configured interval per class, falling back to the defaults
+func (s *StockTakeService) cycleIntervals(ctx context.Context, warehouseID int64) (map[entity.ABCClass]int, error) {
+	schedules, err := s.stockTakeRepo.ListCycleSchedules(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	intervals := make(map[entity.ABCClass]int, len(entity.DefaultCycleIntervalDays))
+	for class, days := range entity.DefaultCycleIntervalDays {
+		intervals[class] = days
+	}
+	for _, sched := range schedules {
+		intervals[sched.ABCClass] = sched.IntervalDays
+	}
+	return intervals, nil
+}
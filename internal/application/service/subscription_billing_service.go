@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SubscriptionBillingService turns delivered subscriptions into monthly invoices
+type SubscriptionBillingService struct {
+	invoiceRepo   repository.SubscriptionInvoiceRepository
+	ledgerRepo    repository.CustomerLedgerRepository
+	ledgerService *CustomerLedgerService
+	txManager     repository.TxManager
+}
+
+// NewSubscriptionBillingService creates a new subscription billing service
+func NewSubscriptionBillingService(
+	invoiceRepo repository.SubscriptionInvoiceRepository,
+	ledgerRepo repository.CustomerLedgerRepository,
+	ledgerService *CustomerLedgerService,
+	txManager repository.TxManager,
+) *SubscriptionBillingService {
+	return &SubscriptionBillingService{
+		invoiceRepo:   invoiceRepo,
+		ledgerRepo:    ledgerRepo,
+		ledgerService: ledgerService,
+		txManager:     txManager,
+	}
+}
+
+// RunBilling invoices every customer, or a single customer, for the subscription deliveries
+// marked delivered during the period that are not on an invoice yet. Each invoice is charged to
+// the customer's ledger. A customer already invoiced for the period gets a supplementary invoice
+// for deliveries recorded since, and is skipped when there are none, so the run can safely be
+// repeated.
+func (s *SubscriptionBillingService) RunBilling(ctx context.Context, period entity.BillingPeriod, customerID *int64, userID *int64) (*entity.BillingRunResult, error) {
+	result := &entity.BillingRunResult{
+		Period:    period,
+		Generated: []entity.SubscriptionInvoice{},
+		Skipped:   []int64{},
+	}
+
+	sequences, err := s.invoiceRepo.ListInvoiceSequences(ctx, period.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.invoiceRepo.ListBillableDeliveries(ctx, customerID, period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deliveries arrive ordered by customer; bill each customer's run of deliveries in turn
+	billed := make(map[int64]bool)
+	for start := 0; start < len(deliveries); {
+		end := start
+		for end < len(deliveries) && deliveries[end].CustomerID == deliveries[start].CustomerID {
+			end++
+		}
+		batch := deliveries[start:end]
+		start = end
+
+		cid := batch[0].CustomerID
+		billed[cid] = true
+		invoice := buildSubscriptionInvoice(period, batch, sequences[cid]+1, userID)
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			return s.createInvoice(ctx, invoice)
+		})
+		if err == domainErrors.ErrInvoiceExists {
+			// Another run invoiced this customer in the meantime
+			result.Skipped = append(result.Skipped, cid)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to invoice customer %d: %w", cid, err)
+		}
+		result.Generated = append(result.Generated, *invoice)
+	}
+
+	// Customers invoiced before with nothing delivered since
+	for id := range sequences {
+		if !billed[id] && (customerID == nil || *customerID == id) {
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+	sort.Slice(result.Skipped, func(i, j int) bool { return result.Skipped[i] < result.Skipped[j] })
+
+	return result, nil
+}
+
+// createInvoice stores the invoice and posts it to the customer's ledger
+func (s *SubscriptionBillingService) createInvoice(ctx context.Context, invoice *entity.SubscriptionInvoice) error {
+	account, err := s.ledgerRepo.GetCreditAccountForUpdate(ctx, invoice.CustomerID)
+	if err != nil {
+		return err
+	}
+	dueDate := account.DueDateFor(invoice.PeriodEnd)
+	invoice.DueDate = &dueDate
+
+	if err := s.invoiceRepo.Create(ctx, invoice); err != nil {
+		return err
+	}
+
+	return s.ledgerRepo.AddEntry(ctx, &entity.CustomerLedgerEntry{
+		CustomerID:      invoice.CustomerID,
+		EntryType:       entity.LedgerEntryInvoice,
+		InvoiceID:       &invoice.ID,
+		Debit:           invoice.TotalAmount,
+		Description:     fmt.Sprintf("Subscription invoice %s", invoice.InvoiceNumber),
+		EntryDate:       invoice.PeriodEnd,
+		DueDate:         invoice.DueDate,
+		CreatedByUserID: invoice.GeneratedByUserID,
+	})
+}
+
+// buildSubscriptionInvoice prices a customer's deliveries into an invoice with a line per product per day
+func buildSubscriptionInvoice(period entity.BillingPeriod, deliveries []entity.BillableDelivery, sequence int, userID *int64) *entity.SubscriptionInvoice {
+	customerID := deliveries[0].CustomerID
+	invoice := &entity.SubscriptionInvoice{
+		InvoiceNumber:     period.InvoiceNumber(customerID, sequence),
+		Sequence:          sequence,
+		CustomerID:        customerID,
+		PeriodStart:       period.Start,
+		PeriodEnd:         period.End,
+		TotalAmount:       decimal.Zero,
+		AmountPaid:        decimal.Zero,
+		Status:            entity.InvoiceStatusOutstanding,
+		GeneratedByUserID: userID,
+	}
+
+	for _, d := range deliveries {
+		lineTotal := d.Quantity.Mul(d.UnitPrice).Round(2)
+		invoice.Lines = append(invoice.Lines, entity.SubscriptionInvoiceLine{
+			SubscriptionID: &d.SubscriptionID,
+			DeliveryID:     &d.DeliveryID,
+			DeliveryDate:   d.DeliveryDate,
+			VariantID:      d.VariantID,
+			Quantity:       d.Quantity,
+			UnitPrice:      d.UnitPrice,
+			LineTotal:      lineTotal,
+		})
+		invoice.TotalAmount = invoice.TotalAmount.Add(lineTotal)
+	}
+	return invoice
+}
+
+// GetByID retrieves an invoice with its lines
+func (s *SubscriptionBillingService) GetByID(ctx context.Context, id int64) (*entity.SubscriptionInvoice, error) {
+	return s.invoiceRepo.GetByID(ctx, id)
+}
+
+// List retrieves invoices matching the filter
+func (s *SubscriptionBillingService) List(ctx context.Context, filter repository.SubscriptionInvoiceFilter, offset, limit int) ([]entity.SubscriptionInvoice, int64, error) {
+	return s.invoiceRepo.List(ctx, filter, offset, limit)
+}
+
+// RecordPayment records a customer payment against an invoice, crediting their ledger
+// and marking the invoice paid once it is fully settled
+func (s *SubscriptionBillingService) RecordPayment(ctx context.Context, invoiceID int64, payment *entity.CustomerPayment) (*entity.SubscriptionInvoice, error) {
+	var invoice *entity.SubscriptionInvoice
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if invoice, err = s.invoiceRepo.GetByIDForUpdate(ctx, invoiceID); err != nil {
+			return err
+		}
+		if invoice.Status == entity.InvoiceStatusPaid {
+			return domainErrors.ErrInvoiceAlreadyPaid
+		}
+		if payment.Amount.GreaterThan(invoice.BalanceDue()) {
+			return fmt.Errorf("payment exceeds the balance due: %w", domainErrors.ErrInvalidInput)
+		}
+
+		payment.CustomerID = invoice.CustomerID
+		payment.InvoiceID = &invoice.ID
+		if err := s.ledgerService.RecordPayment(ctx, payment); err != nil {
+			return err
+		}
+
+		invoice.ApplyPayment(payment.Amount)
+		return s.invoiceRepo.UpdatePayment(ctx, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -15,12 +16,75 @@ import (
 
 // SubscriptionService handles business logic for customer subscriptions
 type SubscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo          repository.SubscriptionRepository
+	addressRepo   repository.CustomerAddressRepository
+	inventoryRepo repository.InventoryRepository
+	reservations  *StockReservationService
+	txManager     repository.TxManager
 }
 
 // NewSubscriptionService creates a new SubscriptionService
-func NewSubscriptionService(repo repository.SubscriptionRepository) *SubscriptionService {
-	return &SubscriptionService{repo: repo}
+func NewSubscriptionService(repo repository.SubscriptionRepository, addressRepo repository.CustomerAddressRepository, inventoryRepo repository.InventoryRepository, reservations *StockReservationService, txManager repository.TxManager) *SubscriptionService {
+	return &SubscriptionService{repo: repo, addressRepo: addressRepo, inventoryRepo: inventoryRepo, reservations: reservations, txManager: txManager}
+}
+
+// releaseReservations frees the stock held for a subscription's deliveries between two dates
+// inclusive; nil bounds leave that side open
+func (s *SubscriptionService) releaseReservations(ctx context.Context, subID int64, from, to *time.Time, status entity.ReservationStatus) error {
+	_, err := s.inventoryRepo.CloseReservations(ctx, repository.ReservationScope{
+		SourceType:  entity.ReservationSourceSubscription,
+		ReferenceID: subID,
+		From:        from,
+		To:          to,
+	}, status)
+	return err
+}
+
+// validateAddress ensures the delivery address, when set, belongs to the subscribing customer
+func (s *SubscriptionService) validateAddress(ctx context.Context, sub *entity.Subscription) error {
+	if sub.AddressID == nil {
+		return nil
+	}
+	address, err := s.addressRepo.GetByID(ctx, *sub.AddressID)
+	if err != nil {
+		if err == domainErrors.ErrAddressNotFound {
+			return fmt.Errorf("delivery address not found: %w", domainErrors.ErrInvalidInput)
+		}
+		return err
+	}
+	if address.CustomerID != sub.CustomerID {
+		return fmt.Errorf("delivery address belongs to another customer: %w", domainErrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// normalizeSchedule validates the delivery days and clears those that do not apply to the frequency
+func normalizeSchedule(sub *entity.Subscription) error {
+	if sub.Frequency != entity.SubscriptionFrequencyWeekly {
+		sub.DeliveryWeekdays = nil
+	}
+	if sub.Frequency != entity.SubscriptionFrequencyMonthly {
+		sub.DeliveryDayOfMonth = nil
+	}
+
+	seen := make(map[time.Weekday]bool, len(sub.DeliveryWeekdays))
+	weekdays := sub.DeliveryWeekdays[:0]
+	for _, wd := range sub.DeliveryWeekdays {
+		if wd < time.Sunday || wd > time.Saturday {
+			return fmt.Errorf("delivery weekdays must be between 0 (Sunday) and 6 (Saturday): %w", domainErrors.ErrInvalidInput)
+		}
+		if !seen[wd] {
+			seen[wd] = true
+			weekdays = append(weekdays, wd)
+		}
+	}
+	sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+	sub.DeliveryWeekdays = weekdays
+
+	if sub.DeliveryDayOfMonth != nil && (*sub.DeliveryDayOfMonth < 1 || *sub.DeliveryDayOfMonth > 31) {
+		return fmt.Errorf("delivery day of month must be between 1 and 31: %w", domainErrors.ErrInvalidInput)
+	}
+	return nil
 }
 
 // Create validates and persists a new subscription
@@ -37,6 +101,13 @@ func (s *SubscriptionService) Create(ctx context.Context, sub *entity.Subscripti
 		}
 	}
 
+	if err := s.validateAddress(ctx, sub); err != nil {
+		return nil, err
+	}
+	if err := normalizeSchedule(sub); err != nil {
+		return nil, err
+	}
+
 	// Default status to active
 	if sub.Status == "" {
 		sub.Status = entity.SubscriptionStatusActive
@@ -80,6 +151,13 @@ func (s *SubscriptionService) Update(ctx context.Context, sub *entity.Subscripti
 		}
 	}
 
+	if err := s.validateAddress(ctx, sub); err != nil {
+		return nil, err
+	}
+	if err := normalizeSchedule(sub); err != nil {
+		return nil, err
+	}
+
 	if err := s.repo.Update(ctx, sub); err != nil {
 		return nil, err
 	}
@@ -87,7 +165,8 @@ func (s *SubscriptionService) Update(ctx context.Context, sub *entity.Subscripti
 	return s.repo.GetByID(ctx, sub.ID)
 }
 
-// UpdateStatus changes only the status field of a subscription
+// UpdateStatus changes only the status field of a subscription.
+// Pausing or cancelling it releases the stock reserved for its deliveries.
 func (s *SubscriptionService) UpdateStatus(ctx context.Context, id int64, status string) error {
 	// Validate the status value
 	switch entity.SubscriptionStatus(status) {
@@ -97,12 +176,21 @@ func (s *SubscriptionService) UpdateStatus(ctx context.Context, id int64, status
 		return fmt.Errorf("invalid status value: %w", domainErrors.ErrInvalidInput)
 	}
 
-	return s.repo.UpdateStatus(ctx, id, status)
+	if err := s.repo.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+	if entity.SubscriptionStatus(status) == entity.SubscriptionStatusActive {
+		return nil
+	}
+	return s.releaseReservations(ctx, id, nil, nil, entity.ReservationStatusReleased)
 }
 
-// Delete permanently removes a subscription
+// Delete permanently removes a subscription and releases the stock reserved for it
 func (s *SubscriptionService) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.releaseReservations(ctx, id, nil, nil, entity.ReservationStatusReleased)
 }
 
 // parseDate parses a "YYYY-MM-DD" string into a time.Time
@@ -114,7 +202,9 @@ func parseDate(dateStr string) (time.Time, error) {
 // Deliveries
 // ==========================================
 
-// RecordDelivery validates and records a daily delivery status for a subscription
+// RecordDelivery validates and records a daily delivery status for a subscription. Recording a
+// delivery as delivered takes its items out of stock; once that is done the delivery cannot be
+// changed to failed or skipped. Otherwise the stock held for the day is released.
 func (s *SubscriptionService) RecordDelivery(ctx context.Context, subID int64, dateStr, status, notes string, recordedBy *int64) (*entity.SubscriptionDelivery, error) {
 	delDate, err := parseDate(dateStr)
 	if err != nil {
@@ -149,7 +239,30 @@ func (s *SubscriptionService) RecordDelivery(ctx context.Context, subID int64, d
 		RecordedBy:     recordedBy,
 	}
 
-	if err := s.repo.RecordDelivery(ctx, delivery); err != nil {
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RecordDelivery(ctx, delivery); err != nil {
+			return err
+		}
+
+		// Re-recording a day keeps its log, so stock issued for it the first time is not issued again
+		issued, err := s.inventoryRepo.ListMovementsByReference(ctx, entity.MovementSourceSubscriptionDelivery, delivery.ID)
+		if err != nil {
+			return err
+		}
+		if len(issued) > 0 {
+			if delivery.Status != entity.DeliveryStatusDelivered {
+				return domainErrors.ErrDeliveryStockIssued
+			}
+			return nil
+		}
+
+		if delivery.Status == entity.DeliveryStatusDelivered {
+			return s.reservations.IssueDelivery(ctx, sub, delivery)
+		}
+		// The stock held for the day is not going out
+		return s.releaseReservations(ctx, subID, &delDate, &delDate, entity.ReservationStatusReleased)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -165,3 +278,77 @@ func (s *SubscriptionService) GetDailyRoster(ctx context.Context, dateStr string
 
 	return s.repo.GetDailyRoster(ctx, dateStr)
 }
+
+// ==========================================
+// Pauses & Date Overrides
+// ==========================================
+
+// AddPause puts a subscription on hold between two dates inclusive and releases the stock
+// reserved for deliveries in that window
+func (s *SubscriptionService) AddPause(ctx context.Context, pause *entity.SubscriptionPause) error {
+	if pause.EndDate.Before(pause.StartDate) {
+		return fmt.Errorf("pause end date is before its start date: %w", domainErrors.ErrInvalidInput)
+	}
+	if _, err := s.repo.GetByID(ctx, pause.SubscriptionID); err != nil {
+		return err
+	}
+	if err := s.repo.CreatePause(ctx, pause); err != nil {
+		return err
+	}
+	return s.releaseReservations(ctx, pause.SubscriptionID, &pause.StartDate, &pause.EndDate, entity.ReservationStatusReleased)
+}
+
+// ListPauses returns a subscription's pause windows
+func (s *SubscriptionService) ListPauses(ctx context.Context, subID int64) ([]*entity.SubscriptionPause, error) {
+	if _, err := s.repo.GetByID(ctx, subID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPauses(ctx, subID)
+}
+
+// RemovePause lifts a pause window
+func (s *SubscriptionService) RemovePause(ctx context.Context, subID, pauseID int64) error {
+	return s.repo.DeletePause(ctx, subID, pauseID)
+}
+
+// SetOverride sets a one-off quantity of a subscribed product for a single date.
+// A quantity of zero skips that product for the day.
+func (s *SubscriptionService) SetOverride(ctx context.Context, override *entity.SubscriptionDateOverride) error {
+	if override.Quantity.IsNegative() {
+		return fmt.Errorf("override quantity cannot be negative: %w", domainErrors.ErrInvalidInput)
+	}
+
+	sub, err := s.repo.GetByID(ctx, override.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if override.DeliveryDate.Before(sub.StartDate) || (sub.EndDate != nil && override.DeliveryDate.After(*sub.EndDate)) {
+		return fmt.Errorf("override date is outside the subscription period: %w", domainErrors.ErrInvalidInput)
+	}
+
+	subscribed := false
+	for _, item := range sub.Items {
+		if item.VariantID == override.VariantID {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return fmt.Errorf("product is not part of this subscription: %w", domainErrors.ErrInvalidInput)
+	}
+
+	return s.repo.SetOverride(ctx, override)
+}
+
+// ListOverrides returns a subscription's date overrides from the given date onwards
+func (s *SubscriptionService) ListOverrides(ctx context.Context, subID int64, from time.Time) ([]*entity.SubscriptionDateOverride, error) {
+	if _, err := s.repo.GetByID(ctx, subID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOverrides(ctx, subID, from)
+}
+
+// RemoveOverride deletes a date override, restoring the regular quantity for that date
+func (s *SubscriptionService) RemoveOverride(ctx context.Context, subID, overrideID int64) error {
+	return s.repo.DeleteOverride(ctx, subID, overrideID)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SupplierPayablesService handles supplier accounts payable: bills, payments, balances and statements
+type SupplierPayablesService struct {
+	payablesRepo    repository.SupplierPayablesRepository
+	supplierRepo    repository.SupplierRepository
+	procurementRepo repository.ProcurementRepository
+	txManager       repository.TxManager
+}
+
+// NewSupplierPayablesService creates a new supplier payables service
+func NewSupplierPayablesService(
+	payablesRepo repository.SupplierPayablesRepository,
+	supplierRepo repository.SupplierRepository,
+	procurementRepo repository.ProcurementRepository,
+	txManager repository.TxManager,
+) *SupplierPayablesService {
+	return &SupplierPayablesService{
+		payablesRepo:    payablesRepo,
+		supplierRepo:    supplierRepo,
+		procurementRepo: procurementRepo,
+		txManager:       txManager,
+	}
+}
+
+// BillProcurement raises a bill for the goods accepted against a purchase order that have not
+// been billed yet. The bill carries the supplier's reference, dates and notes given on it.
+func (s *SupplierPayablesService) BillProcurement(ctx context.Context, procurementID int64, bill *entity.SupplierBill) error {
+	procurement, err := s.procurementRepo.GetByID(ctx, procurementID)
+	if err != nil {
+		return err
+	}
+
+	bill.SupplierID = procurement.SupplierID
+	bill.Source = entity.SupplierBillFromProcurement
+	bill.ProcurementID = &procurement.ID
+	return s.createBill(ctx, bill, fmt.Sprintf("Bill for purchase order #%d", procurement.ID), func(ctx context.Context) ([]entity.SupplierBillLine, error) {
+		return s.payablesRepo.ListUnbilledReceiptLines(ctx, procurement.ID)
+	})
+}
+
+// BillCollections raises a bill for a supplier's collections dated within [from, to) that have
+// not been billed yet
+func (s *SupplierPayablesService) BillCollections(ctx context.Context, supplierID int64, from, to time.Time, bill *entity.SupplierBill) error {
+	if !from.Before(to) {
+		return domainErrors.ErrInvalidInput
+	}
+
+	periodEnd := to.AddDate(0, 0, -1)
+	bill.SupplierID = supplierID
+	bill.Source = entity.SupplierBillFromCollection
+	bill.PeriodStart = &from
+	bill.PeriodEnd = &periodEnd
+	description := fmt.Sprintf("Bill for collections %s to %s", from.Format("2006-01-02"), periodEnd.Format("2006-01-02"))
+	return s.createBill(ctx, bill, description, func(ctx context.Context) ([]entity.SupplierBillLine, error) {
+		return s.payablesRepo.ListUnbilledCollections(ctx, supplierID, from, to)
+	})
+}
+
+// createBill loads the unbilled lines with the supplier locked, so no line is billed twice,
+// then saves the bill and credits the supplier's ledger with it
+func (s *SupplierPayablesService) createBill(ctx context.Context, bill *entity.SupplierBill, description string, loadLines func(ctx context.Context) ([]entity.SupplierBillLine, error)) error {
+	if bill.BillDate.IsZero() {
+		now := time.Now()
+		bill.BillDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if bill.DueDate != nil && bill.DueDate.Before(bill.BillDate) {
+		return domainErrors.ErrInvalidInput
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.payablesRepo.LockSupplier(ctx, bill.SupplierID); err != nil {
+			return err
+		}
+
+		lines, err := loadLines(ctx)
+		if err != nil {
+			return err
+		}
+
+		bill.Amount = decimal.Zero
+		bill.Lines = bill.Lines[:0]
+		for _, line := range lines {
+			line.LineTotal = line.Quantity.Mul(line.UnitPrice).Round(2)
+			if !line.LineTotal.IsPositive() {
+				continue
+			}
+			bill.Amount = bill.Amount.Add(line.LineTotal)
+			bill.Lines = append(bill.Lines, line)
+		}
+		if len(bill.Lines) == 0 {
+			return domainErrors.ErrNothingToBill
+		}
+		bill.AmountPaid = decimal.Zero
+		bill.Status = entity.SupplierBillOpen
+
+		if err := s.payablesRepo.CreateBill(ctx, bill); err != nil {
+			return err
+		}
+
+		if bill.Reference != "" {
+			description = fmt.Sprintf("%s (ref %s)", description, bill.Reference)
+		}
+		return s.payablesRepo.AddEntry(ctx, &entity.SupplierLedgerEntry{
+			SupplierID:      bill.SupplierID,
+			EntryType:       entity.SupplierLedgerBill,
+			BillID:          &bill.ID,
+			Credit:          bill.Amount,
+			Description:     description,
+			EntryDate:       bill.BillDate,
+			DueDate:         bill.DueDate,
+			CreatedByUserID: bill.CreatedByUserID,
+		})
+	})
+}
+
+// GetBill retrieves a supplier bill with its lines
+func (s *SupplierPayablesService) GetBill(ctx context.Context, id int64) (*entity.SupplierBill, error) {
+	return s.payablesRepo.GetBillByID(ctx, id)
+}
+
+// ListBills retrieves supplier bills matching the filter
+func (s *SupplierPayablesService) ListBills(ctx context.Context, filter repository.SupplierBillFilter, offset, limit int) ([]entity.SupplierBill, int64, error) {
+	return s.payablesRepo.ListBills(ctx, filter, offset, limit)
+}
+
+// RecordPayment records money paid to a supplier and debits their ledger. A payment made against
+// a bill settles it and cannot exceed its balance due; otherwise it is held on the supplier's account.
+func (s *SupplierPayablesService) RecordPayment(ctx context.Context, payment *entity.SupplierPayment) error {
+	if !payment.Amount.IsPositive() {
+		return domainErrors.ErrInvalidInput
+	}
+	payment.PaymentMethod = strings.TrimSpace(payment.PaymentMethod)
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = "cash"
+	}
+	if payment.PaidAt.IsZero() {
+		payment.PaidAt = time.Now()
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.payablesRepo.LockSupplier(ctx, payment.SupplierID); err != nil {
+			return err
+		}
+
+		var bill *entity.SupplierBill
+		if payment.BillID != nil {
+			var err error
+			if bill, err = s.payablesRepo.GetBillByIDForUpdate(ctx, *payment.BillID); err != nil {
+				return err
+			}
+			if bill.SupplierID != payment.SupplierID {
+				return fmt.Errorf("bill belongs to another supplier: %w", domainErrors.ErrInvalidInput)
+			}
+			if bill.Status == entity.SupplierBillPaid {
+				return domainErrors.ErrSupplierBillPaid
+			}
+			if payment.Amount.GreaterThan(bill.BalanceDue()) {
+				return fmt.Errorf("payment exceeds the balance due: %w", domainErrors.ErrInvalidInput)
+			}
+		}
+
+		if err := s.payablesRepo.CreatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("Payment (%s)", payment.PaymentMethod)
+		if payment.Reference != "" {
+			description = fmt.Sprintf("Payment (%s, ref %s)", payment.PaymentMethod, payment.Reference)
+		}
+		if err := s.payablesRepo.AddEntry(ctx, &entity.SupplierLedgerEntry{
+			SupplierID:      payment.SupplierID,
+			EntryType:       entity.SupplierLedgerPayment,
+			BillID:          payment.BillID,
+			PaymentID:       &payment.ID,
+			Debit:           payment.Amount,
+			Description:     description,
+			EntryDate:       payment.PaidAt,
+			CreatedByUserID: payment.RecordedByUserID,
+		}); err != nil {
+			return err
+		}
+
+		if bill == nil {
+			return nil
+		}
+		bill.ApplyPayment(payment.Amount)
+		return s.payablesRepo.UpdateBillPayment(ctx, bill)
+	})
+}
+
+// GetStatement builds a supplier's statement for entries dated within [from, to)
+// with running balances carried forward from the opening balance
+func (s *SupplierPayablesService) GetStatement(ctx context.Context, supplierID int64, from, to time.Time) (*entity.SupplierStatement, error) {
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, domainErrors.ErrSupplierNotFound
+	}
+
+	opening, err := s.payablesRepo.GetBalance(ctx, supplierID, from)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.payablesRepo.ListEntries(ctx, supplierID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &entity.SupplierStatement{
+		Supplier:       supplier,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		Entries:        entries,
+		TotalDebit:     decimal.Zero,
+		TotalCredit:    decimal.Zero,
+	}
+	balance := opening
+	for i := range statement.Entries {
+		e := &statement.Entries[i]
+		balance = balance.Add(e.Credit).Sub(e.Debit)
+		e.Balance = balance
+		statement.TotalDebit = statement.TotalDebit.Add(e.Debit)
+		statement.TotalCredit = statement.TotalCredit.Add(e.Credit)
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// Aging returns the amount owed to every supplier as of a date, split into age buckets
+func (s *SupplierPayablesService) Aging(ctx context.Context, asOf time.Time) ([]entity.SupplierAging, error) {
+	balances, err := s.payablesRepo.ListBalances(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.payablesRepo.ListEntriesUpTo(ctx, nil, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	bySupplier := make(map[int64][]entity.SupplierLedgerEntry)
+	for _, e := range entries {
+		bySupplier[e.SupplierID] = append(bySupplier[e.SupplierID], e)
+	}
+	for i := range balances {
+		bucketPayable(&balances[i], bySupplier[balances[i].SupplierID], asOf)
+	}
+	return balances, nil
+}
+
+// SupplierAging returns the amount owed to a single supplier as of a date, split into age buckets
+func (s *SupplierPayablesService) SupplierAging(ctx context.Context, supplierID int64, asOf time.Time) (*entity.SupplierAging, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, domainErrors.ErrSupplierNotFound
+	}
+	entries, err := s.payablesRepo.ListEntriesUpTo(ctx, &supplierID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	aging := &entity.SupplierAging{SupplierID: supplier.ID, SupplierName: supplier.Name}
+	for _, e := range entries {
+		aging.Balance = aging.Balance.Add(e.Credit).Sub(e.Debit)
+	}
+	bucketPayable(aging, entries, asOf)
+	return aging, nil
+}
+
+// bucketPayable applies a supplier's payments to their oldest bills first
+// and ages whatever remains unpaid from its due date
+func bucketPayable(aging *entity.SupplierAging, entries []entity.SupplierLedgerEntry, asOf time.Time) {
+	paid := decimal.Zero
+	charges := make([]entity.AgedCharge, 0, len(entries))
+	for _, e := range entries {
+		paid = paid.Add(e.Debit)
+		charges = append(charges, entity.AgedCharge{Amount: e.Credit, EntryDate: e.EntryDate, DueDate: e.DueDate})
+	}
+	aging.AgeOutstanding(charges, paid, asOf)
+}
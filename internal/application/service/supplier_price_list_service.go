@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SupplierPriceListService handles dated supplier price lists and the price in force on a date
+type SupplierPriceListService struct {
+	priceListRepo repository.SupplierPriceListRepository
+	supplierRepo  repository.SupplierRepository
+	txManager     repository.TxManager
+}
+
+// NewSupplierPriceListService creates a new supplier price list service
+func NewSupplierPriceListService(
+	priceListRepo repository.SupplierPriceListRepository,
+	supplierRepo repository.SupplierRepository,
+	txManager repository.TxManager,
+) *SupplierPriceListService {
+	return &SupplierPriceListService{
+		priceListRepo: priceListRepo,
+		supplierRepo:  supplierRepo,
+		txManager:     txManager,
+	}
+}
+
+// Create adds a price list for a variant the supplier supplies. Lists for a variant cannot overlap,
+// except that an open-ended list replacing an earlier open-ended one ends the earlier one the day
+// before it starts. A list in force today also becomes the supplier's agreed cost for the variant.
+func (s *SupplierPriceListService) Create(ctx context.Context, list *entity.SupplierPriceList) error {
+	if err := validatePriceList(list); err != nil {
+		return err
+	}
+	if _, err := s.supplierRepo.GetByID(ctx, list.SupplierID); err != nil {
+		return domainErrors.ErrSupplierNotFound
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.priceListRepo.LockSupplierVariant(ctx, list.SupplierID, list.VariantID); err != nil {
+			return err
+		}
+
+		existing, err := s.priceListRepo.List(ctx, list.SupplierID, &list.VariantID)
+		if err != nil {
+			return err
+		}
+		for i := range existing {
+			prev := &existing[i]
+			if !list.Overlaps(prev) {
+				continue
+			}
+			if list.ValidTo != nil || prev.ValidTo != nil || !prev.ValidFrom.Before(list.ValidFrom) {
+				return domainErrors.ErrPriceListOverlap
+			}
+			if err := s.priceListRepo.SetValidTo(ctx, prev.ID, list.ValidFrom.AddDate(0, 0, -1)); err != nil {
+				return err
+			}
+		}
+
+		if err := s.priceListRepo.Create(ctx, list); err != nil {
+			return err
+		}
+		if !list.CoversDate(time.Now()) {
+			return nil
+		}
+		return s.priceListRepo.SetAgreedCost(ctx, list.SupplierID, list.VariantID, list.BasePrice())
+	})
+}
+
+// validatePriceList checks the dates and tiers of a new list and puts the tiers in quantity order
+func validatePriceList(list *entity.SupplierPriceList) error {
+	if list.ValidFrom.IsZero() || len(list.Tiers) == 0 {
+		return domainErrors.ErrInvalidInput
+	}
+	list.ValidFrom = calendarDay(list.ValidFrom)
+	if list.ValidTo != nil {
+		validTo := calendarDay(*list.ValidTo)
+		if validTo.Before(list.ValidFrom) {
+			return fmt.Errorf("valid_to is before valid_from: %w", domainErrors.ErrInvalidInput)
+		}
+		list.ValidTo = &validTo
+	}
+
+	seen := make(map[string]bool, len(list.Tiers))
+	for _, t := range list.Tiers {
+		if t.MinQuantity.IsNegative() || t.UnitPrice.IsNegative() {
+			return domainErrors.ErrInvalidInput
+		}
+		key := t.MinQuantity.String()
+		if seen[key] {
+			return fmt.Errorf("two tiers start at quantity %s: %w", key, domainErrors.ErrInvalidInput)
+		}
+		seen[key] = true
+	}
+	if !seen[decimal.Zero.String()] {
+		return fmt.Errorf("a price list needs a base tier from quantity 0: %w", domainErrors.ErrInvalidInput)
+	}
+
+	sort.Slice(list.Tiers, func(i, j int) bool {
+		return list.Tiers[i].MinQuantity.LessThan(list.Tiers[j].MinQuantity)
+	})
+	return nil
+}
+
+// Get retrieves a price list with its tiers
+func (s *SupplierPriceListService) Get(ctx context.Context, id int64) (*entity.SupplierPriceList, error) {
+	return s.priceListRepo.GetByID(ctx, id)
+}
+
+// List retrieves a supplier's price lists, of one variant when variantID is set, latest first
+func (s *SupplierPriceListService) List(ctx context.Context, supplierID int64, variantID *int64) ([]entity.SupplierPriceList, error) {
+	if _, err := s.supplierRepo.GetByID(ctx, supplierID); err != nil {
+		return nil, domainErrors.ErrSupplierNotFound
+	}
+	return s.priceListRepo.List(ctx, supplierID, variantID)
+}
+
+// EffectivePrice returns the unit price the supplier charges for a quantity of the variant on a
+// date: the tier the quantity reaches on the list in force then, or the agreed cost when no list
+// covers the date
+func (s *SupplierPriceListService) EffectivePrice(ctx context.Context, supplierID, variantID int64, on time.Time, quantity decimal.Decimal) (*entity.SupplierPrice, error) {
+	if quantity.IsNegative() {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	price := &entity.SupplierPrice{
+		SupplierID: supplierID,
+		VariantID:  variantID,
+		Date:       calendarDay(on),
+		Quantity:   quantity,
+	}
+
+	list, err := s.priceListRepo.FindEffective(ctx, supplierID, variantID, price.Date)
+	if err == nil {
+		if tier, ok := list.TierFor(quantity); ok {
+			price.UnitPrice = tier.UnitPrice
+			price.MinQuantity = tier.MinQuantity
+			price.PriceListID = &list.ID
+			price.ValidFrom = &list.ValidFrom
+			price.ValidTo = list.ValidTo
+			return price, nil
+		}
+	} else if err != domainErrors.ErrPriceListNotFound {
+		return nil, err
+	}
+
+	variants, err := s.supplierRepo.GetVariants(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sv := range variants {
+		if sv.VariantID == variantID && sv.AgreedCost.IsPositive() {
+			price.UnitPrice = sv.AgreedCost
+			return price, nil
+		}
+	}
+	return nil, domainErrors.ErrSupplierPriceNotFound
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
+	"github.com/qwikshelf/api/internal/domain/repository"
+)
+
+// SupplierScorecardService scores supplier performance and ranks suppliers for preferred-supplier selection
+type SupplierScorecardService struct {
+	scorecardRepo repository.SupplierScorecardRepository
+	supplierRepo  repository.SupplierRepository
+	ranking       string
+	lookbackDays  int
+}
+
+// NewSupplierScorecardService creates a new supplier scorecard service. ranking is "flag" to take
+// the supplier marked preferred for a variant, or "score" to take the best scored over lookbackDays.
+func NewSupplierScorecardService(
+	scorecardRepo repository.SupplierScorecardRepository,
+	supplierRepo repository.SupplierRepository,
+	ranking string,
+	lookbackDays int,
+) *SupplierScorecardService {
+	return &SupplierScorecardService{
+		scorecardRepo: scorecardRepo,
+		supplierRepo:  supplierRepo,
+		ranking:       ranking,
+		lookbackDays:  lookbackDays,
+	}
+}
+
+// Period returns [from, to) with defaults filled in: to defaults to the end of today and from
+// to the lookback period before it
+func (s *SupplierScorecardService) Period(from, to time.Time) (time.Time, time.Time) {
+	if to.IsZero() {
+		now := time.Now()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -s.lookbackDays)
+	}
+	return from, to
+}
+
+// Scorecards scores every supplier active in [from, to), best first
+func (s *SupplierScorecardService) Scorecards(ctx context.Context, from, to time.Time) ([]entity.SupplierScorecard, error) {
+	from, to = s.Period(from, to)
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	cards, err := s.scorecardRepo.ListMeasures(ctx, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		cards[i].Compute()
+	}
+	sort.SliceStable(cards, func(i, j int) bool { return scoreLess(&cards[j], &cards[i]) })
+	return cards, nil
+}
+
+// Scorecard scores one supplier over [from, to)
+func (s *SupplierScorecardService) Scorecard(ctx context.Context, supplierID int64, from, to time.Time) (*entity.SupplierScorecard, error) {
+	from, to = s.Period(from, to)
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	if _, err := s.supplierRepo.GetByID(ctx, supplierID); err != nil {
+		return nil, domainErrors.ErrSupplierNotFound
+	}
+	cards, err := s.scorecardRepo.ListMeasures(ctx, []int64{supplierID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	card := &cards[0]
+	card.Compute()
+	return card, nil
+}
+
+// RankSuppliers ranks the suppliers of a variant by their score over [from, to). Suppliers without
+// a score rank last; ties go to the supplier marked preferred, then the lowest agreed cost.
+func (s *SupplierScorecardService) RankSuppliers(ctx context.Context, variantID int64, from, to time.Time) ([]entity.SupplierRanking, error) {
+	from, to = s.Period(from, to)
+	if !from.Before(to) {
+		return nil, domainErrors.ErrInvalidInput
+	}
+	offers, err := s.supplierRepo.ListSuppliersForVariant(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(offers) == 0 {
+		return []entity.SupplierRanking{}, nil
+	}
+
+	ids := make([]int64, len(offers))
+	for i, offer := range offers {
+		ids[i] = offer.SupplierID
+	}
+	cards, err := s.scorecardRepo.ListMeasures(ctx, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	bySupplier := make(map[int64]*entity.SupplierScorecard, len(cards))
+	for i := range cards {
+		cards[i].Compute()
+		bySupplier[cards[i].SupplierID] = &cards[i]
+	}
+
+	rankings := make([]entity.SupplierRanking, len(offers))
+	for i, offer := range offers {
+		rankings[i] = entity.SupplierRanking{SupplierVariant: offer, Scorecard: bySupplier[offer.SupplierID]}
+	}
+	sort.SliceStable(rankings, func(i, j int) bool {
+		a, b := rankings[i], rankings[j]
+		if scoreLess(b.Scorecard, a.Scorecard) {
+			return true
+		}
+		if scoreLess(a.Scorecard, b.Scorecard) {
+			return false
+		}
+		if a.SupplierVariant.IsPreferred != b.SupplierVariant.IsPreferred {
+			return a.SupplierVariant.IsPreferred
+		}
+		return a.SupplierVariant.AgreedCost.LessThan(b.SupplierVariant.AgreedCost)
+	})
+	for i := range rankings {
+		rankings[i].Rank = i + 1
+	}
+	return rankings, nil
+}
+
+// PreferredSupplier returns the supplier to order a variant from: the one marked preferred, or with
+// score ranking the best scored over the lookback period. It returns ErrNotFound when there is none.
+func (s *SupplierScorecardService) PreferredSupplier(ctx context.Context, variantID int64) (*entity.SupplierVariant, error) {
+	if s.ranking != "score" {
+		return s.supplierRepo.GetPreferredSupplierForVariant(ctx, variantID)
+	}
+	rankings, err := s.RankSuppliers(ctx, variantID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(rankings) == 0 {
+		return nil, domainErrors.ErrNotFound
+	}
+	return &rankings[0].SupplierVariant, nil
+}
+
+// scoreLess reports whether a scores below b; a missing score is below any score
+func scoreLess(a, b *entity.SupplierScorecard) bool {
+	if b == nil || b.Score == nil {
+		return false
+	}
+	if a == nil || a.Score == nil {
+		return true
+	}
+	return a.Score.LessThan(*b.Score)
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/qwikshelf/api/internal/domain/entity"
 	domainErrors "github.com/qwikshelf/api/internal/domain/errors"
@@ -92,11 +95,13 @@ func (s *WarehouseService) Delete(ctx context.Context, id int64) error {
 // SupplierService handles supplier management logic
 type SupplierService struct {
 	supplierRepo repository.SupplierRepository
+	prices       *SupplierPriceListService
+	txManager    repository.TxManager
 }
 
 // NewSupplierService creates a new supplier service
-func NewSupplierService(supplierRepo repository.SupplierRepository) *SupplierService {
-	return &SupplierService{supplierRepo: supplierRepo}
+func NewSupplierService(supplierRepo repository.SupplierRepository, prices *SupplierPriceListService, txManager repository.TxManager) *SupplierService {
+	return &SupplierService{supplierRepo: supplierRepo, prices: prices, txManager: txManager}
 }
 
 // Create creates a new supplier
@@ -169,13 +174,42 @@ func (s *SupplierService) Delete(ctx context.Context, id int64) error {
 	return s.supplierRepo.Delete(ctx, id)
 }
 
-// AddVariant links a product variant to a supplier
+// AddVariant links a product variant to a supplier. An agreed cost that differs from the price in
+// force today starts a price list from today instead of overwriting the cost, so purchase orders
+// are priced at it from now on and the earlier price stays on record.
 func (s *SupplierService) AddVariant(ctx context.Context, supplierID int64, sv *entity.SupplierVariant) error {
 	if _, err := s.supplierRepo.GetByID(ctx, supplierID); err != nil {
 		return domainErrors.ErrSupplierNotFound
 	}
+	if sv.AgreedCost.IsNegative() {
+		return domainErrors.ErrInvalidInput
+	}
 	sv.SupplierID = supplierID
-	return s.supplierRepo.AddVariant(ctx, sv)
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.supplierRepo.AddVariant(ctx, sv); err != nil {
+			return err
+		}
+		if !sv.AgreedCost.IsPositive() {
+			return nil
+		}
+
+		today := time.Now()
+		price, err := s.prices.EffectivePrice(ctx, supplierID, sv.VariantID, today, decimal.Zero)
+		if err != nil && err != domainErrors.ErrSupplierPriceNotFound {
+			return err
+		}
+		if err == nil && price.PriceListID != nil && price.UnitPrice.Equal(sv.AgreedCost) {
+			return nil
+		}
+		return s.prices.Create(ctx, &entity.SupplierPriceList{
+			SupplierID: supplierID,
+			VariantID:  sv.VariantID,
+			ValidFrom:  today,
+			Notes:      "Agreed cost",
+			Tiers:      []entity.SupplierPriceTier{{MinQuantity: decimal.Zero, UnitPrice: sv.AgreedCost}},
+		})
+	})
 }
 
 // GetVariants retrieves all product variants for a supplier with full details
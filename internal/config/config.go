@@ -9,10 +9,11 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Log      LogConfig
+	App       AppConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Log       LogConfig
+	Inventory InventoryConfig
 }
 
 // AppConfig holds application-specific configuration
@@ -42,6 +43,29 @@ type JWTConfig struct {
 	ExpiryHours int
 }
 
+// InventoryConfig holds stock control configuration
+type InventoryConfig struct {
+	// AdjustmentApprovalThreshold is the value (quantity × cost price) above which
+	// a manual adjustment waits for a second user's approval
+	AdjustmentApprovalThreshold float64
+	// LowStockThreshold flags stock as low for variants without a reorder point at the warehouse
+	LowStockThreshold float64
+	// ReplenishmentLookbackDays is the period average daily sales are taken over for reordering
+	ReplenishmentLookbackDays int
+	// OrderReservationHours is how long a placed storefront order holds its stock before it is packed
+	OrderReservationHours int
+	// CostingMethod values stock issues: "fifo" or "average" (moving average)
+	CostingMethod string
+	// OverReceiptTolerancePercent is how far past the ordered quantity a purchase order line
+	// may be received, as a percentage of the ordered quantity
+	OverReceiptTolerancePercent float64
+	// PreferredSupplierRanking picks the supplier replenishment orders from: "flag" (the supplier
+	// marked preferred for the variant) or "score" (the best scorecard over SupplierScoreLookbackDays)
+	PreferredSupplierRanking string
+	// SupplierScoreLookbackDays is the period supplier scorecards are ranked over
+	SupplierScoreLookbackDays int
+}
+
 // LogConfig holds logging configuration
 type LogConfig struct {
 	Level  string
@@ -79,6 +103,16 @@ func Load() (*Config, error) {
 			Format: getEnv("LOG_FORMAT", "json"),
 			File:   getEnv("LOG_FILE", "logs/app.log"),
 		},
+		Inventory: InventoryConfig{
+			AdjustmentApprovalThreshold: getEnvAsFloat("INVENTORY_ADJUSTMENT_APPROVAL_THRESHOLD", 1000),
+			LowStockThreshold:           getEnvAsFloat("INVENTORY_LOW_STOCK_THRESHOLD", 10),
+			ReplenishmentLookbackDays:   getEnvAsInt("INVENTORY_REPLENISHMENT_LOOKBACK_DAYS", 30),
+			OrderReservationHours:       getEnvAsInt("INVENTORY_ORDER_RESERVATION_HOURS", 48),
+			CostingMethod:               getEnv("INVENTORY_COSTING_METHOD", "fifo"),
+			OverReceiptTolerancePercent: getEnvAsFloat("INVENTORY_OVER_RECEIPT_TOLERANCE_PERCENT", 0),
+			PreferredSupplierRanking:    getEnv("INVENTORY_PREFERRED_SUPPLIER_RANKING", "flag"),
+			SupplierScoreLookbackDays:   getEnvAsInt("INVENTORY_SUPPLIER_SCORE_LOOKBACK_DAYS", 90),
+		},
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -93,6 +127,18 @@ func (c *Config) Validate() error {
 	if c.JWT.Secret == "change-me-in-production" && c.App.Env == "production" {
 		return fmt.Errorf("JWT_SECRET must be set in production")
 	}
+	if c.Inventory.CostingMethod != "fifo" && c.Inventory.CostingMethod != "average" {
+		return fmt.Errorf("INVENTORY_COSTING_METHOD must be fifo or average")
+	}
+	if c.Inventory.OverReceiptTolerancePercent < 0 {
+		return fmt.Errorf("INVENTORY_OVER_RECEIPT_TOLERANCE_PERCENT cannot be negative")
+	}
+	if c.Inventory.PreferredSupplierRanking != "flag" && c.Inventory.PreferredSupplierRanking != "score" {
+		return fmt.Errorf("INVENTORY_PREFERRED_SUPPLIER_RANKING must be flag or score")
+	}
+	if c.Inventory.SupplierScoreLookbackDays <= 0 {
+		return fmt.Errorf("INVENTORY_SUPPLIER_SCORE_LOOKBACK_DAYS must be positive")
+	}
 	return nil
 }
 
@@ -128,6 +174,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := getEnv(key, "")
 	if value, err := strconv.ParseBool(valueStr); err == nil {
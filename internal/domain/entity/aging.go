@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AgingBuckets splits an unpaid balance by how many days it is past due
+type AgingBuckets struct {
+	Days0To15  decimal.Decimal `json:"days_0_15"`
+	Days16To30 decimal.Decimal `json:"days_16_30"`
+	Days31To60 decimal.Decimal `json:"days_31_60"`
+	Over60     decimal.Decimal `json:"over_60"`
+}
+
+// AddOutstanding places an unpaid amount into the bucket for its age in days.
+// Amounts not yet due count as current.
+func (a *AgingBuckets) AddOutstanding(amount decimal.Decimal, ageDays int) {
+	switch {
+	case ageDays <= 15:
+		a.Days0To15 = a.Days0To15.Add(amount)
+	case ageDays <= 30:
+		a.Days16To30 = a.Days16To30.Add(amount)
+	case ageDays <= 60:
+		a.Days31To60 = a.Days31To60.Add(amount)
+	default:
+		a.Over60 = a.Over60.Add(amount)
+	}
+}
+
+// AgedCharge is an amount charged to an account, posted on EntryDate and payable by DueDate
+type AgedCharge struct {
+	Amount    decimal.Decimal
+	EntryDate time.Time
+	DueDate   *time.Time
+}
+
+// AgeFrom is the date the charge starts ageing: its due date, or the date it was posted
+// when it has none
+func (c AgedCharge) AgeFrom() time.Time {
+	if c.DueDate != nil {
+		return *c.DueDate
+	}
+	return c.EntryDate
+}
+
+// AgeOutstanding applies the amount settled to the oldest charges first and buckets whatever
+// remains unpaid by the days since it fell due as of asOf. Charges must be in posting order.
+func (a *AgingBuckets) AgeOutstanding(charges []AgedCharge, settled decimal.Decimal, asOf time.Time) {
+	for _, c := range charges {
+		if !c.Amount.IsPositive() {
+			continue
+		}
+		if settled.GreaterThanOrEqual(c.Amount) {
+			settled = settled.Sub(c.Amount)
+			continue
+		}
+		unpaid := c.Amount.Sub(settled)
+		settled = decimal.Zero
+		a.AddOutstanding(unpaid, int(asOf.Sub(c.AgeFrom()).Hours()/24))
+	}
+}
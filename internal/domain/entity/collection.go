@@ -6,6 +6,30 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// CollectionShift is the milking round a collection was taken in
+type CollectionShift string
+
+const (
+	ShiftMorning CollectionShift = "morning"
+	ShiftEvening CollectionShift = "evening"
+)
+
+// IsValid checks if the shift is valid
+func (s CollectionShift) IsValid() bool {
+	return s == ShiftMorning || s == ShiftEvening
+}
+
+// ShiftAt returns the shift a collection taken at the given time falls in: morning before noon, evening after
+func ShiftAt(t time.Time) CollectionShift {
+	if t.Hour() < 12 {
+		return ShiftMorning
+	}
+	return ShiftEvening
+}
+
+// Adulterants that can be flagged when testing milk at collection
+var Adulterants = []string{"water", "urea", "starch", "sugar", "salt", "detergent", "neutralizer", "formalin", "hydrogen_peroxide"}
+
 // Collection represents a product receipt recorded by an agent
 type Collection struct {
 	ID          int64           `json:"id"`
@@ -15,10 +39,151 @@ type Collection struct {
 	WarehouseID int64           `json:"warehouse_id"` // Defaults to Main Warehouse
 	Weight      decimal.Decimal `json:"weight"`
 	CollectedAt time.Time       `json:"collected_at"`
+	Shift       CollectionShift `json:"shift"`
 	Notes       string          `json:"notes"`
 
+	// Quality measured at the point of collection
+	FatPercent        *decimal.Decimal `json:"fat_percent,omitempty"`
+	SNFPercent        *decimal.Decimal `json:"snf_percent,omitempty"`
+	Temperature       *decimal.Decimal `json:"temperature,omitempty"` // °C
+	AdulterationFlags []string         `json:"adulteration_flags,omitempty"`
+
+	// Pricing from the rate chart in force when the collection was recorded; nil when it wasn't rate-priced
+	RateChartID *int64           `json:"rate_chart_id,omitempty"`
+	Rate        *decimal.Decimal `json:"rate,omitempty"`
+	Amount      *decimal.Decimal `json:"amount,omitempty"`
+
+	// Lot the collected stock is received into; the batch number defaults to one per collection
+	BatchNumber *string    `json:"batch_number,omitempty"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+
 	// Optional nested data
 	Variant  *ProductVariant `json:"variant,omitempty"`
 	Supplier *Supplier       `json:"supplier,omitempty"`
 	Agent    *User           `json:"agent,omitempty"`
 }
+
+// IsAdulterated reports whether any adulterant was detected in the collection
+func (c *Collection) IsAdulterated() bool {
+	return len(c.AdulterationFlags) > 0
+}
+
+// CollectionRateChart prices milk by its fat and SNF content. A chart applies to one supplier, to
+// the suppliers of one zone, or, with neither set, to every supplier of the variant.
+type CollectionRateChart struct {
+	ID              int64                `json:"id"`
+	Name            string               `json:"name"`
+	VariantID       int64                `json:"variant_id"`
+	ZoneID          *int64               `json:"zone_id,omitempty"`
+	SupplierID      *int64               `json:"supplier_id,omitempty"`
+	EffectiveFrom   time.Time            `json:"effective_from"`
+	IsActive        bool                 `json:"is_active"`
+	CreatedByUserID *int64               `json:"created_by_user_id,omitempty"`
+	Slabs           []CollectionRateSlab `json:"slabs"`
+	CreatedAt       time.Time            `json:"created_at"`
+}
+
+// CollectionRateSlab is the rate per unit paid for milk meeting both the fat and SNF minimums
+type CollectionRateSlab struct {
+	ID            int64           `json:"id"`
+	MinFatPercent decimal.Decimal `json:"min_fat_percent"`
+	MinSNFPercent decimal.Decimal `json:"min_snf_percent"`
+	Rate          decimal.Decimal `json:"rate"`
+}
+
+// RateFor returns the rate of the highest slab whose minimums the milk meets, preferring a higher
+// fat slab over a higher SNF one. It returns false when the milk is below every slab.
+func (c *CollectionRateChart) RateFor(fat, snf decimal.Decimal) (decimal.Decimal, bool) {
+	var best *CollectionRateSlab
+	for i := range c.Slabs {
+		s := &c.Slabs[i]
+		if fat.LessThan(s.MinFatPercent) || snf.LessThan(s.MinSNFPercent) {
+			continue
+		}
+		if best == nil || s.MinFatPercent.GreaterThan(best.MinFatPercent) ||
+			(s.MinFatPercent.Equal(best.MinFatPercent) && s.MinSNFPercent.GreaterThan(best.MinSNFPercent)) {
+			best = s
+		}
+	}
+	if best == nil {
+		return decimal.Zero, false
+	}
+	return best.Rate, true
+}
+
+// CollectionSummary totals a supplier's collections for one shift of one day, or for a whole period
+// when Date and Shift are empty. Fat and SNF are averaged weighted by the quantity tested.
+type CollectionSummary struct {
+	SupplierID     int64            `json:"supplier_id"`
+	SupplierName   string           `json:"supplier_name"`
+	Date           *time.Time       `json:"date,omitempty"`
+	Shift          CollectionShift  `json:"shift,omitempty"`
+	Collections    int              `json:"collections"`
+	TotalWeight    decimal.Decimal  `json:"total_weight"`
+	AvgFatPercent  *decimal.Decimal `json:"avg_fat_percent,omitempty"`
+	AvgSNFPercent  *decimal.Decimal `json:"avg_snf_percent,omitempty"`
+	Adulterated    int              `json:"adulterated"`
+	TotalAmount    decimal.Decimal  `json:"total_amount"`
+	UnpricedWeight decimal.Decimal  `json:"unpriced_weight"` // collected without a rate chart price
+}
+
+// CollectionRoute is the ordered round of suppliers an agent collects from every shift
+type CollectionRoute struct {
+	ID          int64                 `json:"id"`
+	Name        string                `json:"name"`
+	AgentID     int64                 `json:"agent_id"`
+	AgentName   string                `json:"agent_name,omitempty"`
+	ZoneID      *int64                `json:"zone_id,omitempty"`
+	WarehouseID int64                 `json:"warehouse_id"`
+	IsActive    bool                  `json:"is_active"`
+	Stops       []CollectionRouteStop `json:"stops"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// CollectionRouteStop is a supplier visited on a route, in the order given by Sequence
+type CollectionRouteStop struct {
+	Sequence   int       `json:"sequence"`
+	SupplierID int64     `json:"supplier_id"`
+	Supplier   *Supplier `json:"supplier,omitempty"`
+}
+
+// HasSupplier reports whether the supplier is a stop on the route
+func (r *CollectionRoute) HasSupplier(supplierID int64) bool {
+	for _, s := range r.Stops {
+		if s.SupplierID == supplierID {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftSheet lists every stop of a route for one shift with the collections recorded from it
+type ShiftSheet struct {
+	Route     *CollectionRoute `json:"route"`
+	Date      time.Time        `json:"date"`
+	Shift     CollectionShift  `json:"shift"`
+	Rows      []ShiftSheetRow  `json:"rows"`
+	Collected int              `json:"collected"`
+	Pending   int              `json:"pending"`
+}
+
+// ShiftSheetRow is one route stop on a shift sheet; it is collected once any collection is recorded for it
+type ShiftSheetRow struct {
+	Stop        CollectionRouteStop `json:"stop"`
+	Collections []Collection        `json:"collections"`
+}
+
+// IsCollected reports whether anything was collected from the stop this shift
+func (r *ShiftSheetRow) IsCollected() bool {
+	return len(r.Collections) > 0
+}
+
+// TotalWeight returns the weight collected from the stop this shift
+func (r *ShiftSheetRow) TotalWeight() decimal.Decimal {
+	total := decimal.Zero
+	for _, c := range r.Collections {
+		total = total.Add(c.Weight)
+	}
+	return total
+}
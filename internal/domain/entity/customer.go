@@ -18,6 +18,46 @@ type Customer struct {
 	ZoneName         string    `json:"zone_name"` // populated via join
 	Latitude         *float64  `json:"latitude" db:"latitude"`
 	Longitude        *float64  `json:"longitude" db:"longitude"`
+	UserID           *int64    `json:"user_id,omitempty" db:"user_id"` // linked storefront account
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// AddressLabel identifies the kind of a saved customer address
+type AddressLabel string
+
+const (
+	AddressLabelHome  AddressLabel = "home"
+	AddressLabelWork  AddressLabel = "work"
+	AddressLabelOther AddressLabel = "other"
+)
+
+// IsValid checks if the address label is valid
+func (l AddressLabel) IsValid() bool {
+	switch l {
+	case AddressLabelHome, AddressLabelWork, AddressLabelOther:
+		return true
+	}
+	return false
+}
+
+// CustomerAddress is an entry in a customer's address book
+type CustomerAddress struct {
+	ID           int64        `json:"id"`
+	CustomerID   int64        `json:"customer_id"`
+	Label        AddressLabel `json:"label"`
+	ContactName  string       `json:"contact_name,omitempty"`
+	Phone        string       `json:"phone,omitempty"`
+	AddressLine1 string       `json:"address_line1"`
+	AddressLine2 string       `json:"address_line2,omitempty"`
+	Landmark     string       `json:"landmark,omitempty"`
+	City         string       `json:"city,omitempty"`
+	Pincode      string       `json:"pincode"`
+	Latitude     *float64     `json:"latitude,omitempty"`
+	Longitude    *float64     `json:"longitude,omitempty"`
+	ZoneID       *int64       `json:"zone_id,omitempty"`
+	ZoneName     string       `json:"zone_name,omitempty"` // populated via join
+	IsDefault    bool         `json:"is_default"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Customer payment terms
+const (
+	PaymentTermsCash    = "cash"
+	PaymentTermsNet15   = "net_15"
+	PaymentTermsPrePaid = "pre_paid"
+)
+
+// LedgerEntryType identifies what posted a customer ledger entry
+type LedgerEntryType string
+
+const (
+	LedgerEntrySale       LedgerEntryType = "sale"
+	LedgerEntryInvoice    LedgerEntryType = "invoice"
+	LedgerEntryPayment    LedgerEntryType = "payment"
+	LedgerEntryReversal   LedgerEntryType = "reversal"
+	LedgerEntryAdjustment LedgerEntryType = "adjustment"
+)
+
+// CustomerLedgerEntry is a debit (amount owed) or credit (amount paid) on a customer's account
+type CustomerLedgerEntry struct {
+	ID              int64           `json:"id"`
+	CustomerID      int64           `json:"customer_id"`
+	EntryType       LedgerEntryType `json:"entry_type"`
+	SaleID          *int64          `json:"sale_id,omitempty"`
+	InvoiceID       *int64          `json:"invoice_id,omitempty"`
+	PaymentID       *int64          `json:"payment_id,omitempty"`
+	Debit           decimal.Decimal `json:"debit"`
+	Credit          decimal.Decimal `json:"credit"`
+	Balance         decimal.Decimal `json:"balance"` // running balance, computed when listing
+	Description     string          `json:"description,omitempty"`
+	EntryDate       time.Time       `json:"entry_date"`
+	DueDate         *time.Time      `json:"due_date,omitempty"`
+	CreatedByUserID *int64          `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// CustomerPayment records money received from a customer against their account
+type CustomerPayment struct {
+	ID               int64           `json:"id"`
+	CustomerID       int64           `json:"customer_id"`
+	InvoiceID        *int64          `json:"invoice_id,omitempty"` // subscription invoice the payment settles
+	Amount           decimal.Decimal `json:"amount"`
+	PaymentMethod    string          `json:"payment_method"`
+	Reference        string          `json:"reference,omitempty"`
+	Notes            string          `json:"notes,omitempty"`
+	ReceivedAt       time.Time       `json:"received_at"`
+	RecordedByUserID *int64          `json:"recorded_by_user_id,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// CustomerCreditAccount is the credit standing of a customer used to approve credit sales
+type CustomerCreditAccount struct {
+	CustomerID   int64           `json:"customer_id"`
+	CreditLimit  decimal.Decimal `json:"credit_limit"`
+	PaymentTerms string          `json:"payment_terms"`
+	Balance      decimal.Decimal `json:"balance"`
+}
+
+// CanCharge checks whether a credit sale of amount fits the customer's terms and limit.
+// Cash customers cannot buy on credit; pre-paid customers may only draw down an advance.
+func (a *CustomerCreditAccount) CanCharge(amount decimal.Decimal) bool {
+	switch a.PaymentTerms {
+	case PaymentTermsCash:
+		return false
+	case PaymentTermsPrePaid:
+		return !a.Balance.Add(amount).IsPositive()
+	default:
+		return a.Balance.Add(amount).LessThanOrEqual(a.CreditLimit)
+	}
+}
+
+// DueDateFor returns when a credit sale made at t falls due under the payment terms
+func (a *CustomerCreditAccount) DueDateFor(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if a.PaymentTerms == PaymentTermsNet15 {
+		return day.AddDate(0, 0, 15)
+	}
+	return day
+}
+
+// CustomerAging is the outstanding balance of a customer split by how overdue unpaid debits are
+type CustomerAging struct {
+	CustomerID   int64           `json:"customer_id"`
+	CustomerName string          `json:"customer_name"`
+	Balance      decimal.Decimal `json:"balance"`
+	AgingBuckets
+}
+
+// CustomerStatement lists a customer's ledger activity over a period
+type CustomerStatement struct {
+	Customer       *Customer             `json:"customer"`
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	OpeningBalance decimal.Decimal       `json:"opening_balance"`
+	Entries        []CustomerLedgerEntry `json:"entries"`
+	TotalDebit     decimal.Decimal       `json:"total_debit"`
+	TotalCredit    decimal.Decimal       `json:"total_credit"`
+	ClosingBalance decimal.Decimal       `json:"closing_balance"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// GoodsReceipt is a goods receipt note (GRN): one delivery received against a purchase order
+type GoodsReceipt struct {
+	ID                int64              `json:"id"`
+	ProcurementID     int64              `json:"procurement_id"`
+	WarehouseID       int64              `json:"warehouse_id"`
+	ReceivedByUserID  *int64             `json:"received_by_user_id,omitempty"`
+	ReceivedByName    string             `json:"received_by_name,omitempty"`
+	DeliveryReference string             `json:"delivery_reference,omitempty"`
+	Notes             string             `json:"notes,omitempty"`
+	ReceivedAt        time.Time          `json:"received_at"`
+	Items             []GoodsReceiptItem `json:"items,omitempty"`
+}
+
+// GoodsReceiptItem is the quantity of a purchase order line delivered in a receipt. Accepted
+// stock is booked into the lot named by the batch; rejected stock goes back to the supplier.
+type GoodsReceiptItem struct {
+	ID                int64           `json:"id"`
+	ReceiptID         int64           `json:"receipt_id"`
+	ProcurementItemID int64           `json:"procurement_item_id"`
+	VariantID         int64           `json:"variant_id"`
+	VariantName       string          `json:"variant_name,omitempty"`
+	QuantityAccepted  decimal.Decimal `json:"quantity_accepted"`
+	QuantityRejected  decimal.Decimal `json:"quantity_rejected"`
+	RejectionReason   string          `json:"rejection_reason,omitempty"`
+	BatchNumber       *string         `json:"batch_number,omitempty"`
+	ExpiryDate        *time.Time      `json:"expiry_date,omitempty"`
+	LotID             *int64          `json:"lot_id,omitempty"`
+}
+
+// QuantityDelivered is the quantity that arrived, accepted or not
+func (i *GoodsReceiptItem) QuantityDelivered() decimal.Decimal {
+	return i.QuantityAccepted.Add(i.QuantityRejected)
+}
@@ -35,13 +35,15 @@ func (il *InventoryLevel) IsLowStock(threshold decimal.Decimal) bool {
 type TransferStatus string
 
 const (
+	TransferStatusRequested TransferStatus = "requested"
 	TransferStatusPending   TransferStatus = "pending"
 	TransferStatusInTransit TransferStatus = "in_transit"
 	TransferStatusCompleted TransferStatus = "completed"
 	TransferStatusCancelled TransferStatus = "cancelled"
 )
 
-// InventoryTransfer represents a transfer between warehouses
+// InventoryTransfer represents a transfer between warehouses. Stock leaves the source when the
+// transfer is dispatched and is in transit until the destination receives it.
 type InventoryTransfer struct {
 	ID                     int64                   `json:"id"`
 	SourceWarehouseID      int64                   `json:"source_warehouse_id"`
@@ -52,14 +54,66 @@ type InventoryTransfer struct {
 	AuthorizedBy           *User                   `json:"authorized_by,omitempty"`
 	TransferredAt          time.Time               `json:"transferred_at"`
 	Status                 TransferStatus          `json:"status"`
+	Notes                  *string                 `json:"notes,omitempty"`
+	RequestedByUserID      *int64                  `json:"requested_by_user_id,omitempty"`
+	DispatchedByUserID     *int64                  `json:"dispatched_by_user_id,omitempty"`
+	DispatchedAt           *time.Time              `json:"dispatched_at,omitempty"`
+	ReceivedByUserID       *int64                  `json:"received_by_user_id,omitempty"`
+	ReceivedAt             *time.Time              `json:"received_at,omitempty"`
+	CancelledByUserID      *int64                  `json:"cancelled_by_user_id,omitempty"`
+	CancelledAt            *time.Time              `json:"cancelled_at,omitempty"`
 	Items                  []InventoryTransferItem `json:"items,omitempty"`
 }
 
-// InventoryTransferItem represents a line item in an inventory transfer
+// CanDispatch reports whether the transfer is waiting to be sent
+func (t *InventoryTransfer) CanDispatch() bool {
+	return t.Status == TransferStatusRequested || t.Status == TransferStatusPending
+}
+
+// CanCancel reports whether the transfer has not been received or cancelled yet
+func (t *InventoryTransfer) CanCancel() bool {
+	return t.CanDispatch() || t.Status == TransferStatusInTransit
+}
+
+// InventoryTransferItem represents a line item in an inventory transfer. Quantity is what was
+// asked for; the dispatched, received and damaged quantities are filled in as the transfer moves.
 type InventoryTransferItem struct {
-	ID         int64           `json:"id"`
-	TransferID int64           `json:"transfer_id"`
-	VariantID  int64           `json:"variant_id"`
-	Variant    *ProductVariant `json:"variant,omitempty"`
-	Quantity   decimal.Decimal `json:"quantity"`
+	ID                 int64            `json:"id"`
+	TransferID         int64            `json:"transfer_id"`
+	VariantID          int64            `json:"variant_id"`
+	Variant            *ProductVariant  `json:"variant,omitempty"`
+	Quantity           decimal.Decimal  `json:"quantity"`
+	DispatchedQuantity *decimal.Decimal `json:"dispatched_quantity,omitempty"`
+	ReceivedQuantity   *decimal.Decimal `json:"received_quantity,omitempty"`
+	DamagedQuantity    *decimal.Decimal `json:"damaged_quantity,omitempty"`
+	Notes              *string          `json:"notes,omitempty"`
+}
+
+// ShortQuantity is the dispatched stock that neither arrived nor arrived damaged
+func (i *InventoryTransferItem) ShortQuantity() decimal.Decimal {
+	if i.DispatchedQuantity == nil || i.ReceivedQuantity == nil {
+		return decimal.Zero
+	}
+	short := i.DispatchedQuantity.Sub(*i.ReceivedQuantity)
+	if i.DamagedQuantity != nil {
+		short = short.Sub(*i.DamagedQuantity)
+	}
+	return short
+}
+
+// TransferReceipt is what the destination reports for one transfer item on receipt
+type TransferReceipt struct {
+	ItemID           int64
+	ReceivedQuantity decimal.Decimal
+	DamagedQuantity  decimal.Decimal
+	Notes            *string
+}
+
+// InTransitStock is the stock of a variant dispatched to a warehouse and not yet received
+type InTransitStock struct {
+	DestinationWarehouseID int64           `json:"destination_warehouse_id"`
+	VariantID              int64           `json:"variant_id"`
+	VariantName            string          `json:"variant_name"`
+	Quantity               decimal.Decimal `json:"quantity"`
+	Transfers              int             `json:"transfers"`
 }
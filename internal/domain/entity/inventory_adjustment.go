@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AdjustmentReason explains why stock was manually adjusted
+type AdjustmentReason string
+
+const (
+	AdjustmentReasonDamage          AdjustmentReason = "damage"
+	AdjustmentReasonSpoilage        AdjustmentReason = "spoilage"
+	AdjustmentReasonTheft           AdjustmentReason = "theft"
+	AdjustmentReasonCountCorrection AdjustmentReason = "count_correction"
+	AdjustmentReasonSample          AdjustmentReason = "sample"
+)
+
+// IsValid checks if the reason code is known
+func (r AdjustmentReason) IsValid() bool {
+	switch r {
+	case AdjustmentReasonDamage, AdjustmentReasonSpoilage, AdjustmentReasonTheft,
+		AdjustmentReasonCountCorrection, AdjustmentReasonSample:
+		return true
+	}
+	return false
+}
+
+// AdjustmentStatus represents where a manual adjustment is in the approval flow
+type AdjustmentStatus string
+
+const (
+	AdjustmentStatusPending  AdjustmentStatus = "pending"
+	AdjustmentStatusApproved AdjustmentStatus = "approved"
+	AdjustmentStatusRejected AdjustmentStatus = "rejected"
+)
+
+// InventoryAdjustment is a manual correction of a stock level. It changes stock only once approved.
+type InventoryAdjustment struct {
+	ID                int64            `json:"id"`
+	WarehouseID       int64            `json:"warehouse_id"`
+	WarehouseName     string           `json:"warehouse_name,omitempty"`
+	VariantID         int64            `json:"variant_id"`
+	VariantName       string           `json:"variant_name,omitempty"`
+	ReasonCode        AdjustmentReason `json:"reason_code"`
+	QuantityDelta     decimal.Decimal  `json:"quantity_delta"`
+	UnitCost          decimal.Decimal  `json:"unit_cost"`
+	Value             decimal.Decimal  `json:"value"` // |quantity_delta| × unit_cost
+	Notes             *string          `json:"notes,omitempty"`
+	Status            AdjustmentStatus `json:"status"`
+	RequestedByUserID *int64           `json:"requested_by_user_id,omitempty"`
+	ReviewedByUserID  *int64           `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt        *time.Time       `json:"reviewed_at,omitempty"`
+	ReviewNote        *string          `json:"review_note,omitempty"`
+	MovementID        *int64           `json:"movement_id,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+}
+
+// Price values the adjustment at the variant's cost price
+func (a *InventoryAdjustment) Price(unitCost decimal.Decimal) {
+	a.UnitCost = unitCost
+	a.Value = a.QuantityDelta.Abs().Mul(unitCost).Round(2)
+}
+
+// ShrinkageSummary totals the stock written off for one reason at one warehouse
+type ShrinkageSummary struct {
+	WarehouseID     int64            `json:"warehouse_id"`
+	WarehouseName   string           `json:"warehouse_name"`
+	ReasonCode      AdjustmentReason `json:"reason_code"`
+	AdjustmentCount int              `json:"adjustment_count"`
+	Quantity        decimal.Decimal  `json:"quantity"` // units written off
+	Value           decimal.Decimal  `json:"value"`
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CostingMethod decides the cost stock is issued at
+type CostingMethod string
+
+const (
+	// CostingFIFO issues stock at the cost of the oldest layers it consumes
+	CostingFIFO CostingMethod = "fifo"
+	// CostingAverage issues stock at the average cost of the stock held when it is issued
+	CostingAverage CostingMethod = "average"
+)
+
+// IsValid checks if the costing method is supported
+func (m CostingMethod) IsValid() bool {
+	return m == CostingFIFO || m == CostingAverage
+}
+
+// CostLayer is stock of a variant at a warehouse received at one unit cost. Issues consume the
+// oldest layers first; RemainingQuantity is what is left of it.
+type CostLayer struct {
+	ID                int64              `json:"id"`
+	WarehouseID       int64              `json:"warehouse_id"`
+	VariantID         int64              `json:"variant_id"`
+	VariantName       string             `json:"variant_name,omitempty"`
+	MovementID        *int64             `json:"movement_id,omitempty"`
+	SourceType        MovementSourceType `json:"source_type"`
+	UnitCost          decimal.Decimal    `json:"unit_cost"`
+	Quantity          decimal.Decimal    `json:"quantity"`
+	RemainingQuantity decimal.Decimal    `json:"remaining_quantity"`
+	ReceivedAt        time.Time          `json:"received_at"`
+}
+
+// CostLayerDraw is the part of an issue taken from one cost layer
+type CostLayerDraw struct {
+	Layer    *CostLayer
+	Quantity decimal.Decimal
+}
+
+// DrawCostLayers plans taking a quantity from layers, oldest first as given, and returns the draws
+// with their FIFO cost. Uncovered is the part the layers do not hold, e.g. stock received before
+// costing; the caller values it.
+func DrawCostLayers(layers []CostLayer, quantity decimal.Decimal) (draws []CostLayerDraw, cost, uncovered decimal.Decimal) {
+	remaining := quantity
+	for i := range layers {
+		if !remaining.IsPositive() {
+			break
+		}
+		if !layers[i].RemainingQuantity.IsPositive() {
+			continue
+		}
+		take := decimal.Min(layers[i].RemainingQuantity, remaining)
+		draws = append(draws, CostLayerDraw{Layer: &layers[i], Quantity: take})
+		cost = cost.Add(take.Mul(layers[i].UnitCost))
+		remaining = remaining.Sub(take)
+	}
+	return draws, cost, remaining
+}
+
+// InventoryValuationLine is the stock and value of a variant at a warehouse at a point in time
+type InventoryValuationLine struct {
+	WarehouseID   int64           `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name,omitempty"`
+	VariantID     int64           `json:"variant_id"`
+	VariantName   string          `json:"variant_name,omitempty"`
+	SKU           string          `json:"sku,omitempty"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Value         decimal.Decimal `json:"value"`
+}
+
+// UnitCost is the average cost of the stock valued
+func (l *InventoryValuationLine) UnitCost() decimal.Decimal {
+	if l.Quantity.IsZero() {
+		return decimal.Zero
+	}
+	return l.Value.Div(l.Quantity).Round(4)
+}
+
+// InventoryValuation is the value of stock at the end of a day
+type InventoryValuation struct {
+	AsOf       time.Time                `json:"as_of"`
+	Method     CostingMethod            `json:"method"`
+	Lines      []InventoryValuationLine `json:"lines"`
+	TotalValue decimal.Decimal          `json:"total_value"`
+}
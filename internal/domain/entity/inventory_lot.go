@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InventoryLot is the stock of a variant at a warehouse received under one batch number.
+// SourceType and ReferenceID record where the lot came from (a procurement, collection,
+// production run or transfer); lots moved between warehouses keep their batch number.
+type InventoryLot struct {
+	ID            int64              `json:"id"`
+	WarehouseID   int64              `json:"warehouse_id"`
+	WarehouseName string             `json:"warehouse_name,omitempty"`
+	VariantID     int64              `json:"variant_id"`
+	VariantName   string             `json:"variant_name,omitempty"`
+	BatchNumber   string             `json:"batch_number"`
+	ExpiryDate    *time.Time         `json:"expiry_date,omitempty"`
+	Quantity      decimal.Decimal    `json:"quantity"`
+	SupplierID    *int64             `json:"supplier_id,omitempty"`
+	SupplierName  *string            `json:"supplier_name,omitempty"`
+	SourceType    MovementSourceType `json:"source_type"`
+	ReferenceID   *int64             `json:"reference_id,omitempty"`
+	ReceivedAt    time.Time          `json:"received_at"`
+}
+
+// IsExpired reports whether the lot is past its expiry date on the given day.
+// A lot can still be used on its expiry date. Both sides are compared as calendar dates.
+func (l *InventoryLot) IsExpired(asOf time.Time) bool {
+	if l.ExpiryDate == nil {
+		return false
+	}
+	return calendarDate(asOf).After(calendarDate(*l.ExpiryDate))
+}
+
+// LotAllocation is the part of a stock withdrawal taken from one lot.
+// Lot is nil for the part taken from untracked stock.
+type LotAllocation struct {
+	Lot      *InventoryLot
+	Quantity decimal.Decimal
+}
+
+// AllocateFEFO plans which lots a quantity is taken from: first-expiry-first-out, lots without an
+// expiry date after dated ones, then untracked stock. Expired lots are skipped unless includeExpired,
+// in which case they are taken first, as when writing stock off. It reports false when the stock
+// allowed is not enough to cover the quantity.
+func AllocateFEFO(lots []InventoryLot, untracked, quantity decimal.Decimal, asOf time.Time, includeExpired bool) ([]LotAllocation, bool) {
+	ordered := make([]*InventoryLot, 0, len(lots))
+	for i := range lots {
+		if lots[i].Quantity.IsPositive() && (includeExpired || !lots[i].IsExpired(asOf)) {
+			ordered = append(ordered, &lots[i])
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if ae, be := a.IsExpired(asOf), b.IsExpired(asOf); ae != be {
+			return ae
+		}
+		switch {
+		case a.ExpiryDate == nil && b.ExpiryDate == nil:
+		case a.ExpiryDate == nil:
+			return false
+		case b.ExpiryDate == nil:
+			return true
+		case !a.ExpiryDate.Equal(*b.ExpiryDate):
+			return a.ExpiryDate.Before(*b.ExpiryDate)
+		}
+		return a.ReceivedAt.Before(b.ReceivedAt)
+	})
+
+	allocations := []LotAllocation{}
+	remaining := quantity
+	for _, lot := range ordered {
+		if !remaining.IsPositive() {
+			break
+		}
+		take := decimal.Min(lot.Quantity, remaining)
+		allocations = append(allocations, LotAllocation{Lot: lot, Quantity: take})
+		remaining = remaining.Sub(take)
+	}
+	if remaining.IsPositive() && untracked.IsPositive() {
+		take := decimal.Min(untracked, remaining)
+		allocations = append(allocations, LotAllocation{Quantity: take})
+		remaining = remaining.Sub(take)
+	}
+	return allocations, !remaining.IsPositive()
+}
+
+// LotTraceMovement is a ledger entry of a traced batch, with who it went to for sales
+type LotTraceMovement struct {
+	InventoryMovement
+	WarehouseName string  `json:"warehouse_name"`
+	BatchNumber   string  `json:"batch_number"`
+	CustomerID    *int64  `json:"customer_id,omitempty"`
+	CustomerName  *string `json:"customer_name,omitempty"`
+}
+
+// LotTraceCustomer is the net quantity of a traced batch sold to one customer
+type LotTraceCustomer struct {
+	CustomerID   *int64          `json:"customer_id,omitempty"`
+	CustomerName string          `json:"customer_name"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	SaleIDs      []int64         `json:"sale_ids"`
+}
+
+// LotTrace follows a batch from where it was received, through the warehouses it moved to,
+// to the customers it was sold to
+type LotTrace struct {
+	BatchNumber string             `json:"batch_number"`
+	VariantID   int64              `json:"variant_id"`
+	Lots        []InventoryLot     `json:"lots"`
+	Movements   []LotTraceMovement `json:"movements"`
+	Customers   []LotTraceCustomer `json:"customers"`
+}
+
+// NewLotTrace builds a trace from the lots of a batch and their movements, oldest first,
+// summing sales less cancellations per customer
+func NewLotTrace(batchNumber string, variantID int64, lots []InventoryLot, movements []LotTraceMovement) *LotTrace {
+	trace := &LotTrace{
+		BatchNumber: batchNumber,
+		VariantID:   variantID,
+		Lots:        lots,
+		Movements:   movements,
+		Customers:   []LotTraceCustomer{},
+	}
+
+	index := map[string]int{}
+	for _, m := range movements {
+		if m.SourceType != MovementSourceSale && m.SourceType != MovementSourceSaleCancellation {
+			continue
+		}
+		name := "Walk-in customer"
+		if m.CustomerName != nil && *m.CustomerName != "" {
+			name = *m.CustomerName
+		}
+		key := "name:" + name
+		if m.CustomerID != nil {
+			key = "id:" + strconv.FormatInt(*m.CustomerID, 10)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(trace.Customers)
+			index[key] = i
+			trace.Customers = append(trace.Customers, LotTraceCustomer{CustomerID: m.CustomerID, CustomerName: name, SaleIDs: []int64{}})
+		}
+		c := &trace.Customers[i]
+		c.Quantity = c.Quantity.Sub(m.QuantityDelta)
+		if m.SourceType == MovementSourceSale && m.ReferenceID != nil {
+			c.SaleIDs = append(c.SaleIDs, *m.ReferenceID)
+		}
+	}
+	return trace
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MovementSourceType identifies what caused a stock movement
+type MovementSourceType string
+
+const (
+	MovementSourceOpening              MovementSourceType = "opening"
+	MovementSourceAdjustment           MovementSourceType = "adjustment"
+	MovementSourceSale                 MovementSourceType = "sale"
+	MovementSourceSaleCancellation     MovementSourceType = "sale_cancellation"
+	MovementSourceCollection           MovementSourceType = "collection"
+	MovementSourceProcurement          MovementSourceType = "procurement"
+	MovementSourceTransferOut          MovementSourceType = "transfer_out"
+	MovementSourceTransferIn           MovementSourceType = "transfer_in"
+	MovementSourceTransferCancellation MovementSourceType = "transfer_cancellation"
+	MovementSourceProductionInput      MovementSourceType = "production_input"
+	MovementSourceProductionOutput     MovementSourceType = "production_output"
+	MovementSourceSubscriptionDelivery MovementSourceType = "subscription_delivery"
+)
+
+// InventoryMovement is an immutable stock ledger entry for a variant at a warehouse
+type InventoryMovement struct {
+	ID            int64              `json:"id"`
+	WarehouseID   int64              `json:"warehouse_id"`
+	VariantID     int64              `json:"variant_id"`
+	LotID         *int64             `json:"lot_id,omitempty"`
+	SourceType    MovementSourceType `json:"source_type"`
+	ReferenceID   *int64             `json:"reference_id,omitempty"` // id of the sale, transfer, collection... that caused it
+	UserID        *int64             `json:"user_id,omitempty"`
+	QuantityDelta decimal.Decimal    `json:"quantity_delta"`
+	BalanceAfter  decimal.Decimal    `json:"balance_after"`
+	UnitCost      *decimal.Decimal   `json:"unit_cost,omitempty"`   // cost a receipt comes in at (current average when unset); filled in for issues
+	TotalCost     *decimal.Decimal   `json:"total_cost,omitempty"`  // value added by a receipt, or taken out by an issue (negative)
+	ValueAfter    *decimal.Decimal   `json:"value_after,omitempty"` // stock value after the movement; unset on entries recorded before costing
+	Notes         *string            `json:"notes,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// NewInventoryMovement describes a stock change; the balance after it is filled in when it is applied
+func NewInventoryMovement(warehouseID, variantID int64, delta decimal.Decimal, source MovementSourceType, referenceID, userID *int64) *InventoryMovement {
+	return &InventoryMovement{
+		WarehouseID:   warehouseID,
+		VariantID:     variantID,
+		SourceType:    source,
+		ReferenceID:   referenceID,
+		UserID:        userID,
+		QuantityDelta: delta,
+	}
+}
+
+// StockCard is the movement history of a variant at a warehouse over a period
+type StockCard struct {
+	WarehouseID    int64               `json:"warehouse_id"`
+	VariantID      int64               `json:"variant_id"`
+	From           time.Time           `json:"from"`
+	To             time.Time           `json:"to"`
+	OpeningBalance decimal.Decimal     `json:"opening_balance"`
+	TotalIn        decimal.Decimal     `json:"total_in"`
+	TotalOut       decimal.Decimal     `json:"total_out"`
+	ClosingBalance decimal.Decimal     `json:"closing_balance"`
+	Movements      []InventoryMovement `json:"movements"`
+}
+
+// InventoryDrift is a stock level that disagrees with the sum of its ledger movements
+type InventoryDrift struct {
+	WarehouseID    int64           `json:"warehouse_id"`
+	VariantID      int64           `json:"variant_id"`
+	LevelQuantity  decimal.Decimal `json:"level_quantity"`
+	LedgerQuantity decimal.Decimal `json:"ledger_quantity"`
+}
+
+// Drift returns how far the stored level is off the ledger
+func (d InventoryDrift) Drift() decimal.Decimal {
+	return d.LevelQuantity.Sub(d.LedgerQuantity)
+}
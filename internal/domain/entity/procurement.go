@@ -28,6 +28,25 @@ func (s ProcurementStatus) IsValid() bool {
 	return false
 }
 
+// procurementTransitions lists the statuses a purchase order may move to from each status.
+// Orders can only be cancelled before anything has been received against them.
+var procurementTransitions = map[ProcurementStatus][]ProcurementStatus{
+	ProcurementStatusPending:  {ProcurementStatusApproved, ProcurementStatusCancelled},
+	ProcurementStatusApproved: {ProcurementStatusOrdered, ProcurementStatusCancelled},
+	ProcurementStatusOrdered:  {ProcurementStatusPartial, ProcurementStatusReceived, ProcurementStatusCancelled},
+	ProcurementStatusPartial:  {ProcurementStatusReceived},
+}
+
+// CanTransitionTo checks if a purchase order in this status may move to next
+func (s ProcurementStatus) CanTransitionTo(next ProcurementStatus) bool {
+	for _, allowed := range procurementTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Procurement represents a purchase order from a supplier
 type Procurement struct {
 	ID               int64             `json:"id"`
@@ -51,7 +70,13 @@ type ProcurementItem struct {
 	Variant          *ProductVariant `json:"variant,omitempty"`
 	QuantityOrdered  decimal.Decimal `json:"quantity_ordered"`
 	QuantityReceived decimal.Decimal `json:"quantity_received"`
+	QuantityRejected decimal.Decimal `json:"quantity_rejected"`
 	UnitCost         decimal.Decimal `json:"unit_cost"`
+	BatchNumber      *string         `json:"batch_number,omitempty"`
+	ExpiryDate       *time.Time      `json:"expiry_date,omitempty"`
+	// The supplier's price for the line when it was ordered, and the price list it came from
+	ListPrice   *decimal.Decimal `json:"list_price,omitempty"`
+	PriceListID *int64           `json:"price_list_id,omitempty"`
 }
 
 // LineTotal calculates the total cost for this line item
@@ -59,6 +84,39 @@ func (pi *ProcurementItem) LineTotal() decimal.Decimal {
 	return pi.QuantityOrdered.Mul(pi.UnitCost)
 }
 
+// IsAboveListPrice reports whether the line was ordered at more than the supplier's list price
+func (pi *ProcurementItem) IsAboveListPrice() bool {
+	return pi.ListPrice != nil && pi.UnitCost.GreaterThan(*pi.ListPrice)
+}
+
+// OutstandingQuantity is the quantity still to be received; it is zero once the line is received in full
+func (pi *ProcurementItem) OutstandingQuantity() decimal.Decimal {
+	if pi.QuantityReceived.GreaterThanOrEqual(pi.QuantityOrdered) {
+		return decimal.Zero
+	}
+	return pi.QuantityOrdered.Sub(pi.QuantityReceived)
+}
+
+// IsFullyReceived reports whether every line has been received in full
+func (p *Procurement) IsFullyReceived() bool {
+	for i := range p.Items {
+		if p.Items[i].OutstandingQuantity().IsPositive() {
+			return false
+		}
+	}
+	return true
+}
+
+// HasReceipts reports whether any goods have been accepted against the order
+func (p *Procurement) HasReceipts() bool {
+	for i := range p.Items {
+		if p.Items[i].QuantityReceived.IsPositive() {
+			return true
+		}
+	}
+	return false
+}
+
 // CalculateTotalCost calculates the total cost of all items in the procurement
 func (p *Procurement) CalculateTotalCost() decimal.Decimal {
 	total := decimal.Zero
@@ -67,3 +125,18 @@ func (p *Procurement) CalculateTotalCost() decimal.Decimal {
 	}
 	return total
 }
+
+// ProcurementStatusEvent records a purchase order moving from one status to another, with the
+// order total at the time and, for approvals, the role the approver acted under
+type ProcurementStatusEvent struct {
+	ID              int64             `json:"id"`
+	ProcurementID   int64             `json:"procurement_id"`
+	FromStatus      ProcurementStatus `json:"from_status"`
+	ToStatus        ProcurementStatus `json:"to_status"`
+	Amount          decimal.Decimal   `json:"amount"`
+	ChangedByUserID *int64            `json:"changed_by_user_id,omitempty"`
+	ChangedByName   string            `json:"changed_by_name,omitempty"`
+	RoleID          *int64            `json:"role_id,omitempty"`
+	Note            string            `json:"note,omitempty"`
+	ChangedAt       time.Time         `json:"changed_at"`
+}
@@ -14,6 +14,7 @@ type ProductionRun struct {
 	StaffID     int64           `json:"staff_id"`
 	Staff       *User           `json:"staff,omitempty"`
 	BatchCode   string          `json:"batch_code"`
+	ExpiryDate  *time.Time      `json:"expiry_date,omitempty"` // of the output lots
 	CreatedAt   time.Time       `json:"created_at"`
 	Logs        []ProductionLog `json:"logs,omitempty"`
 }
@@ -37,3 +38,42 @@ func (pl *ProductionLog) Yield() decimal.Decimal {
 	}
 	return pl.OutputQty.Div(pl.InputQty).Mul(decimal.NewFromInt(100))
 }
+
+// TotalInput returns the combined input quantity across all logs of the run
+func (pr *ProductionRun) TotalInput() decimal.Decimal {
+	total := decimal.Zero
+	for _, log := range pr.Logs {
+		total = total.Add(log.InputQty)
+	}
+	return total
+}
+
+// TotalOutput returns the combined output quantity across all logs of the run
+func (pr *ProductionRun) TotalOutput() decimal.Decimal {
+	total := decimal.Zero
+	for _, log := range pr.Logs {
+		total = total.Add(log.OutputQty)
+	}
+	return total
+}
+
+// Yield calculates the overall yield of the run (total output/total input ratio)
+func (pr *ProductionRun) Yield() decimal.Decimal {
+	input := pr.TotalInput()
+	if input.IsZero() {
+		return decimal.Zero
+	}
+	return pr.TotalOutput().Div(input).Mul(decimal.NewFromInt(100))
+}
+
+// ProductionYieldDeviation flags a production log whose yield falls outside its recipe tolerance
+type ProductionYieldDeviation struct {
+	RunID          int64           `json:"run_id"`
+	BatchCode      string          `json:"batch_code"`
+	WarehouseID    int64           `json:"warehouse_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Log            ProductionLog   `json:"log"`
+	ActualYield    decimal.Decimal `json:"actual_yield"`
+	ExpectedYield  decimal.Decimal `json:"expected_yield"`
+	YieldTolerance decimal.Decimal `json:"yield_tolerance"`
+}
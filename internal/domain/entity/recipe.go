@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Recipe represents the bill of materials for a manufactured variant.
+// Quantities are standard amounts for producing OutputQty of the output variant.
+type Recipe struct {
+	ID              int64           `json:"id"`
+	OutputVariantID int64           `json:"output_variant_id"`
+	OutputVariant   *ProductVariant `json:"output_variant,omitempty"`
+	OutputQty       decimal.Decimal `json:"output_qty"`
+	ExpectedYield   decimal.Decimal `json:"expected_yield"`  // percentage, output/primary input
+	YieldTolerance  decimal.Decimal `json:"yield_tolerance"` // allowed deviation in percentage points
+	Notes           string          `json:"notes,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Items           []RecipeItem    `json:"items,omitempty"`
+}
+
+// RecipeItem represents an input variant and its standard quantity in a recipe.
+// The first item is the primary input against which yield is measured.
+type RecipeItem struct {
+	ID             int64           `json:"id"`
+	RecipeID       int64           `json:"recipe_id"`
+	InputVariantID int64           `json:"input_variant_id"`
+	InputVariant   *ProductVariant `json:"input_variant,omitempty"`
+	Quantity       decimal.Decimal `json:"quantity"`
+}
+
+// Scale returns the input quantities needed to produce outputQty, in recipe order
+func (r *Recipe) Scale(outputQty decimal.Decimal) []RecipeItem {
+	if r.OutputQty.IsZero() {
+		return nil
+	}
+	factor := outputQty.Div(r.OutputQty)
+	scaled := make([]RecipeItem, len(r.Items))
+	for i, item := range r.Items {
+		scaled[i] = item
+		scaled[i].Quantity = item.Quantity.Mul(factor).Round(3)
+	}
+	return scaled
+}
+
+// IsYieldWithinTolerance reports whether an actual yield percentage is within the recipe's tolerance
+func (r *Recipe) IsYieldWithinTolerance(actual decimal.Decimal) bool {
+	return actual.Sub(r.ExpectedYield).Abs().LessThanOrEqual(r.YieldTolerance)
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReorderSetting holds the stock control levels of a variant at a warehouse. MinQuantity is the
+// safety stock, ReorderPoint the level at which to order and MaxQuantity the level to order up to.
+type ReorderSetting struct {
+	ID            int64           `json:"id"`
+	WarehouseID   int64           `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name,omitempty"`
+	VariantID     int64           `json:"variant_id"`
+	VariantName   string          `json:"variant_name,omitempty"`
+	SKU           string          `json:"sku,omitempty"`
+	MinQuantity   decimal.Decimal `json:"min_quantity"`
+	ReorderPoint  decimal.Decimal `json:"reorder_point"`
+	MaxQuantity   decimal.Decimal `json:"max_quantity"`
+	LeadTimeDays  int             `json:"lead_time_days"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// IsValid checks that 0 <= min <= reorder point < max and the lead time is not negative
+func (s *ReorderSetting) IsValid() bool {
+	return !s.MinQuantity.IsNegative() &&
+		s.ReorderPoint.GreaterThanOrEqual(s.MinQuantity) &&
+		s.MaxQuantity.GreaterThan(s.ReorderPoint) &&
+		s.LeadTimeDays >= 0
+}
+
+// ReplenishmentCandidate is a reorder setting with the stock position the engine decides on
+type ReplenishmentCandidate struct {
+	Setting ReorderSetting
+	// OnHand is the stock level at the warehouse
+	OnHand decimal.Decimal
+	// Reserved is held for orders and subscription deliveries and cannot be sold again
+	Reserved decimal.Decimal
+	// OnOrder is ordered on open purchase orders and not received yet
+	OnOrder decimal.Decimal
+	// InTransit is dispatched to the warehouse by other warehouses and not received yet
+	InTransit decimal.Decimal
+	// AvgDailySales is the base quantity sold per day over the lookback period
+	AvgDailySales decimal.Decimal
+}
+
+// Projected is the unreserved stock the warehouse will have once everything inbound arrives
+func (c *ReplenishmentCandidate) Projected() decimal.Decimal {
+	return c.OnHand.Sub(c.Reserved).Add(c.OnOrder).Add(c.InTransit)
+}
+
+// Suggest returns the quantity to order, in whole units, and whether the projected stock is at or
+// below the reorder point. It orders up to the maximum plus the sales expected during the lead time.
+func (c *ReplenishmentCandidate) Suggest() (decimal.Decimal, bool) {
+	projected := c.Projected()
+	if projected.GreaterThan(c.Setting.ReorderPoint) {
+		return decimal.Zero, false
+	}
+	leadTimeDemand := c.AvgDailySales.Mul(decimal.NewFromInt(int64(c.Setting.LeadTimeDays)))
+	return c.Setting.MaxQuantity.Sub(projected).Add(leadTimeDemand).Ceil(), true
+}
+
+// DaysOfCover is how many days the stock on hand lasts at the average sales rate,
+// or nil when nothing has sold
+func (c *ReplenishmentCandidate) DaysOfCover() *decimal.Decimal {
+	if !c.AvgDailySales.IsPositive() {
+		return nil
+	}
+	days := c.OnHand.Div(c.AvgDailySales).Round(1)
+	return &days
+}
+
+// ReplenishmentSuggestion is a variant to reorder at a warehouse and who to order it from.
+// SupplierID is nil when the variant has no preferred supplier.
+type ReplenishmentSuggestion struct {
+	ReplenishmentCandidate
+	SuggestedQuantity decimal.Decimal  `json:"suggested_quantity"`
+	DaysOfCover       *decimal.Decimal `json:"days_of_cover,omitempty"`
+	SupplierID        *int64           `json:"supplier_id,omitempty"`
+	SupplierName      *string          `json:"supplier_name,omitempty"`
+	UnitCost          decimal.Decimal  `json:"unit_cost"`
+}
+
+// ReplenishmentRun is the outcome of turning suggestions into draft purchase orders.
+// Unsourced lists the suggestions left out because no preferred supplier is set.
+type ReplenishmentRun struct {
+	Procurements []Procurement             `json:"procurements"`
+	Unsourced    []ReplenishmentSuggestion `json:"unsourced"`
+}
@@ -6,40 +6,131 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// OrderStatus represents the fulfilment state of a sale/order
+type OrderStatus string
+
+const (
+	OrderStatusPlaced         OrderStatus = "placed"
+	OrderStatusConfirmed      OrderStatus = "confirmed"
+	OrderStatusPacked         OrderStatus = "packed"
+	OrderStatusOutForDelivery OrderStatus = "out_for_delivery"
+	OrderStatusDelivered      OrderStatus = "delivered"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+	OrderStatusReturned       OrderStatus = "returned"
+)
+
+// orderTransitions lists the statuses an order may move to from each status
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPlaced:         {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:      {OrderStatusPacked, OrderStatusCancelled},
+	OrderStatusPacked:         {OrderStatusOutForDelivery, OrderStatusCancelled},
+	OrderStatusOutForDelivery: {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered:      {OrderStatusReturned},
+}
+
+// IsValid checks if the order status is valid
+func (st OrderStatus) IsValid() bool {
+	switch st {
+	case OrderStatusPlaced, OrderStatusConfirmed, OrderStatusPacked, OrderStatusOutForDelivery,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusReturned:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo checks if an order in this status may move to next
+func (st OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[st] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Sale represents a POS transaction
 type Sale struct {
-	ID                int64           `json:"id"`
-	WarehouseID       int64           `json:"warehouse_id"`
-	Warehouse         *Warehouse      `json:"warehouse,omitempty"`
-	CustomerID        *int64          `json:"customer_id,omitempty"`
-	CustomerName      string          `json:"customer_name"`
-	TotalAmount       decimal.Decimal `json:"total_amount"`
-	TaxAmount         decimal.Decimal `json:"tax_amount"`
-	DiscountAmount    decimal.Decimal `json:"discount_amount"`
-	PaymentMethod     string          `json:"payment_method"`
-	ProcessedByUserID int64           `json:"processed_by_user_id"`
-	ProcessedByUser   *User           `json:"processed_by_user,omitempty"`
-	CreatedAt         time.Time       `json:"created_at"`
-	Items             []SaleItem      `json:"items,omitempty"`
+	ID                int64                `json:"id"`
+	WarehouseID       int64                `json:"warehouse_id"`
+	Warehouse         *Warehouse           `json:"warehouse,omitempty"`
+	CustomerID        *int64               `json:"customer_id,omitempty"`
+	CustomerName      string               `json:"customer_name"`
+	TotalAmount       decimal.Decimal      `json:"total_amount"`
+	TaxAmount         decimal.Decimal      `json:"tax_amount"`
+	DiscountAmount    decimal.Decimal      `json:"discount_amount"`
+	DeliveryCharge    decimal.Decimal      `json:"delivery_charge"`
+	PaymentMethod     string               `json:"payment_method"`
+	Status            OrderStatus          `json:"status"`
+	ZoneID            *int64               `json:"zone_id,omitempty"`
+	ProcessedByUserID *int64               `json:"processed_by_user_id,omitempty"`
+	ProcessedByUser   *User                `json:"processed_by_user,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	CostOfGoods       *decimal.Decimal     `json:"cost_of_goods,omitempty"` // set once every item has left stock
+	Items             []SaleItem           `json:"items,omitempty"`
+	DeliveryAddress   *SaleDeliveryAddress `json:"delivery_address,omitempty"`
+}
+
+// SaleDeliveryAddress is the structured delivery address captured with a storefront order
+type SaleDeliveryAddress struct {
+	SaleID            int64  `json:"sale_id"`
+	CustomerID        *int64 `json:"customer_id,omitempty"`
+	CustomerAddressID *int64 `json:"customer_address_id,omitempty"`
+	ContactName       string `json:"contact_name"`
+	Phone             string `json:"phone"`
+	AddressLine1      string `json:"address_line1"`
+	AddressLine2      string `json:"address_line2,omitempty"`
+	Landmark          string `json:"landmark,omitempty"`
+	City              string `json:"city,omitempty"`
+	Pincode           string `json:"pincode"`
+}
+
+// OrderStatusEvent represents a timestamped status transition of an order
+type OrderStatusEvent struct {
+	ID              int64       `json:"id"`
+	SaleID          int64       `json:"sale_id"`
+	Status          OrderStatus `json:"status"`
+	Note            string      `json:"note,omitempty"`
+	ChangedByUserID *int64      `json:"changed_by_user_id,omitempty"`
+	ChangedAt       time.Time   `json:"changed_at"`
 }
 
 // SaleItem represents an item within a sale
 type SaleItem struct {
-	ID        int64           `json:"id"`
-	SaleID    int64           `json:"sale_id"`
-	VariantID int64           `json:"variant_id"`
-	Variant   *ProductVariant `json:"variant,omitempty"`
-	Quantity  decimal.Decimal `json:"quantity"`
-	UnitPrice decimal.Decimal `json:"unit_price"`
-	LineTotal decimal.Decimal `json:"line_total"`
+	ID          int64            `json:"id"`
+	SaleID      int64            `json:"sale_id"`
+	VariantID   int64            `json:"variant_id"`
+	Variant     *ProductVariant  `json:"variant,omitempty"`
+	Quantity    decimal.Decimal  `json:"quantity"`
+	UnitPrice   decimal.Decimal  `json:"unit_price"`
+	LineTotal   decimal.Decimal  `json:"line_total"`
+	CostOfGoods *decimal.Decimal `json:"cost_of_goods,omitempty"` // cost of the stock issued for the item
 }
 
-// CalculateTotals updates the total amount based on items
-func (s *Sale) CalculateTotals() {
+// Subtotal returns the sum of item line totals, before tax, discount and delivery charge
+func (s *Sale) Subtotal() decimal.Decimal {
 	var total decimal.Decimal
 	for i := range s.Items {
 		s.Items[i].LineTotal = s.Items[i].Quantity.Mul(s.Items[i].UnitPrice)
 		total = total.Add(s.Items[i].LineTotal)
 	}
-	s.TotalAmount = total.Add(s.TaxAmount).Sub(s.DiscountAmount)
+	return total
+}
+
+// CalculateTotals updates the total amount based on items
+func (s *Sale) CalculateTotals() {
+	s.TotalAmount = s.Subtotal().Add(s.TaxAmount).Sub(s.DiscountAmount).Add(s.DeliveryCharge)
+}
+
+// NetRevenue is what the sale earns on its goods: the total less tax and delivery charge
+func (s *Sale) NetRevenue() decimal.Decimal {
+	return s.TotalAmount.Sub(s.TaxAmount).Sub(s.DeliveryCharge)
+}
+
+// GrossMargin is the net revenue less the cost of goods, nil until the cost is known
+func (s *Sale) GrossMargin() *decimal.Decimal {
+	if s.CostOfGoods == nil {
+		return nil
+	}
+	margin := s.NetRevenue().Sub(*s.CostOfGoods)
+	return &margin
 }
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReservationSourceType identifies the demand a reservation holds stock for
+type ReservationSourceType string
+
+const (
+	ReservationSourceOrder        ReservationSourceType = "order"
+	ReservationSourceSubscription ReservationSourceType = "subscription"
+)
+
+// ReservationStatus represents the state of a stock reservation
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusFulfilled ReservationStatus = "fulfilled"
+	ReservationStatusReleased  ReservationStatus = "released"
+	ReservationStatusExpired   ReservationStatus = "expired"
+)
+
+// StockReservation holds base-unit stock of a variant at a warehouse for an order or a
+// subscription delivery, so it cannot be sold or moved elsewhere before it is picked.
+// ReferenceID is the sale or subscription; ReservedFor is the delivery date of a subscription.
+type StockReservation struct {
+	ID          int64                 `json:"id"`
+	WarehouseID int64                 `json:"warehouse_id"`
+	VariantID   int64                 `json:"variant_id"`
+	VariantName string                `json:"variant_name,omitempty"`
+	SKU         string                `json:"sku,omitempty"`
+	Quantity    decimal.Decimal       `json:"quantity"`
+	SourceType  ReservationSourceType `json:"source_type"`
+	ReferenceID int64                 `json:"reference_id"`
+	ReservedFor *time.Time            `json:"reserved_for,omitempty"`
+	Status      ReservationStatus     `json:"status"`
+	ExpiresAt   time.Time             `json:"expires_at"`
+	CreatedAt   time.Time             `json:"created_at"`
+	ClosedAt    *time.Time            `json:"closed_at,omitempty"`
+}
+
+// IsHeld reports whether the reservation still holds stock at the given time
+func (r *StockReservation) IsHeld(asOf time.Time) bool {
+	return r.Status == ReservationStatusActive && asOf.Before(r.ExpiresAt)
+}
+
+// StockAvailability is the available-to-promise position of a variant at a warehouse
+type StockAvailability struct {
+	WarehouseID int64           `json:"warehouse_id"`
+	VariantID   int64           `json:"variant_id"`
+	VariantName string          `json:"variant_name,omitempty"`
+	SKU         string          `json:"sku,omitempty"`
+	OnHand      decimal.Decimal `json:"on_hand"`
+	Expired     decimal.Decimal `json:"expired"`
+	Reserved    decimal.Decimal `json:"reserved"`
+}
+
+// Available is the stock that can still be promised: on hand less expired lots and reservations
+func (a *StockAvailability) Available() decimal.Decimal {
+	available := a.OnHand.Sub(a.Expired).Sub(a.Reserved)
+	if available.IsNegative() {
+		return decimal.Zero
+	}
+	return available
+}
+
+// RosterReservationRun is the outcome of reserving stock for a day's subscription roster.
+// Unassigned lists subscriptions with no delivery zone warehouse to reserve at and Short those
+// whose items could not be covered by available stock.
+type RosterReservationRun struct {
+	Date         time.Time          `json:"date"`
+	Reservations []StockReservation `json:"reservations"`
+	Unassigned   []int64            `json:"unassigned_subscription_ids"`
+	Short        []int64            `json:"short_subscription_ids"`
+}
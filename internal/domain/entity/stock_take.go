@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StockTakeStatus represents the lifecycle of a stock-take session
+type StockTakeStatus string
+
+const (
+	StockTakeStatusOpen      StockTakeStatus = "open"
+	StockTakeStatusPosted    StockTakeStatus = "posted"
+	StockTakeStatusCancelled StockTakeStatus = "cancelled"
+)
+
+// StockTakeScope says whether a session counts the whole warehouse or one ABC class that is due
+type StockTakeScope string
+
+const (
+	StockTakeScopeFull  StockTakeScope = "full"
+	StockTakeScopeCycle StockTakeScope = "cycle"
+)
+
+// ABCClass ranks variants by the value of stock they consume: A items are counted most often
+type ABCClass string
+
+const (
+	ABCClassA ABCClass = "A"
+	ABCClassB ABCClass = "B"
+	ABCClassC ABCClass = "C"
+)
+
+// IsValid checks if the class is A, B or C
+func (c ABCClass) IsValid() bool {
+	return c == ABCClassA || c == ABCClassB || c == ABCClassC
+}
+
+// DefaultCycleIntervalDays is how often each class is counted when a warehouse has no schedule
+var DefaultCycleIntervalDays = map[ABCClass]int{
+	ABCClassA: 30,
+	ABCClassB: 90,
+	ABCClassC: 180,
+}
+
+// StockTake is a physical count of a warehouse against the quantities expected when it was opened
+type StockTake struct {
+	ID              int64           `json:"id"`
+	WarehouseID     int64           `json:"warehouse_id"`
+	WarehouseName   string          `json:"warehouse_name,omitempty"`
+	Scope           StockTakeScope  `json:"scope"`
+	ABCClass        *ABCClass       `json:"abc_class,omitempty"`
+	Status          StockTakeStatus `json:"status"`
+	Notes           *string         `json:"notes,omitempty"`
+	StartedByUserID *int64          `json:"started_by_user_id,omitempty"`
+	StartedAt       time.Time       `json:"started_at"`
+	ClosedByUserID  *int64          `json:"closed_by_user_id,omitempty"`
+	ClosedAt        *time.Time      `json:"closed_at,omitempty"`
+	Lines           []StockTakeLine `json:"lines,omitempty"`
+}
+
+// StockTakeLine is the expected and counted quantity of one variant in a session
+type StockTakeLine struct {
+	ID               int64            `json:"id"`
+	StockTakeID      int64            `json:"stock_take_id"`
+	VariantID        int64            `json:"variant_id"`
+	VariantName      string           `json:"variant_name,omitempty"`
+	SKU              string           `json:"sku,omitempty"`
+	UnitCost         decimal.Decimal  `json:"unit_cost"`
+	ExpectedQuantity decimal.Decimal  `json:"expected_quantity"`
+	MovedQuantity    decimal.Decimal  `json:"moved_quantity"` // net stock movement between the snapshot and the count
+	CountedQuantity  *decimal.Decimal `json:"counted_quantity,omitempty"`
+	CountedByUserID  *int64           `json:"counted_by_user_id,omitempty"`
+	CountedAt        *time.Time       `json:"counted_at,omitempty"`
+	AdjustmentID     *int64           `json:"adjustment_id,omitempty"`
+}
+
+// IsCounted reports whether a quantity has been counted for the line
+func (l *StockTakeLine) IsCounted() bool {
+	return l.CountedQuantity != nil
+}
+
+// BookQuantity is the quantity the ledger held when the line was counted: the snapshot plus
+// whatever moved in or out since
+func (l *StockTakeLine) BookQuantity() decimal.Decimal {
+	return l.ExpectedQuantity.Add(l.MovedQuantity)
+}
+
+// Variance returns counted minus book quantity, zero while uncounted
+func (l *StockTakeLine) Variance() decimal.Decimal {
+	if l.CountedQuantity == nil {
+		return decimal.Zero
+	}
+	return l.CountedQuantity.Sub(l.BookQuantity())
+}
+
+// VarianceValue values the variance at the variant's cost price
+func (l *StockTakeLine) VarianceValue() decimal.Decimal {
+	return l.Variance().Mul(l.UnitCost).Round(2)
+}
+
+// StockTakeVarianceReport summarizes the differences found by a stock take
+type StockTakeVarianceReport struct {
+	StockTake      *StockTake      `json:"stock_take"`
+	CountedLines   int             `json:"counted_lines"`
+	UncountedLines int             `json:"uncounted_lines"`
+	VarianceLines  int             `json:"variance_lines"`
+	ShortageValue  decimal.Decimal `json:"shortage_value"`
+	SurplusValue   decimal.Decimal `json:"surplus_value"`
+	NetValue       decimal.Decimal `json:"net_value"`
+}
+
+// NewStockTakeVarianceReport totals the variances of a session's lines
+func NewStockTakeVarianceReport(st *StockTake) *StockTakeVarianceReport {
+	report := &StockTakeVarianceReport{
+		StockTake:     st,
+		ShortageValue: decimal.Zero,
+		SurplusValue:  decimal.Zero,
+		NetValue:      decimal.Zero,
+	}
+	for i := range st.Lines {
+		line := &st.Lines[i]
+		if !line.IsCounted() {
+			report.UncountedLines++
+			continue
+		}
+		report.CountedLines++
+		if line.Variance().IsZero() {
+			continue
+		}
+		report.VarianceLines++
+		value := line.VarianceValue()
+		if value.IsNegative() {
+			report.ShortageValue = report.ShortageValue.Sub(value)
+		} else {
+			report.SurplusValue = report.SurplusValue.Add(value)
+		}
+		report.NetValue = report.NetValue.Add(value)
+	}
+	return report
+}
+
+// CycleCountSchedule sets how often an ABC class is counted at a warehouse
+type CycleCountSchedule struct {
+	WarehouseID  int64    `json:"warehouse_id"`
+	ABCClass     ABCClass `json:"abc_class"`
+	IntervalDays int      `json:"interval_days"`
+}
+
+// CycleCountItem is a stocked variant ranked by consumption value, with when it was last counted
+type CycleCountItem struct {
+	VariantID        int64           `json:"variant_id"`
+	VariantName      string          `json:"variant_name"`
+	SKU              string          `json:"sku"`
+	ConsumptionValue decimal.Decimal `json:"consumption_value"`
+	ABCClass         ABCClass        `json:"abc_class"`
+	LastCountedAt    *time.Time      `json:"last_counted_at,omitempty"`
+	DueAt            time.Time       `json:"due_at"`
+}
+
+// IsDue reports whether the item should be counted as of a date
+func (i *CycleCountItem) IsDue(asOf time.Time) bool {
+	return !i.DueAt.After(asOf)
+}
+
+// ClassifyABC assigns classes to items sorted by descending consumption value: the items making up
+// the first 80% of total value are A, the next 15% B and the rest C. Items with no consumption are C.
+func ClassifyABC(items []CycleCountItem) {
+	total := decimal.Zero
+	for _, item := range items {
+		total = total.Add(item.ConsumptionValue)
+	}
+
+	cumulative := decimal.Zero
+	limitA := total.Mul(decimal.NewFromFloat(0.80))
+	limitB := total.Mul(decimal.NewFromFloat(0.95))
+	for i := range items {
+		item := &items[i]
+		if !item.ConsumptionValue.IsPositive() {
+			item.ABCClass = ABCClassC
+			continue
+		}
+		// Classify by where the item starts, so the item that crosses a boundary stays in the higher class
+		switch {
+		case cumulative.LessThan(limitA):
+			item.ABCClass = ABCClassA
+		case cumulative.LessThan(limitB):
+			item.ABCClass = ABCClassB
+		default:
+			item.ABCClass = ABCClassC
+		}
+		cumulative = cumulative.Add(item.ConsumptionValue)
+	}
+}
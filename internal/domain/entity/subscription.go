@@ -35,6 +35,9 @@ type Subscription struct {
 	StartDate            time.Time          `json:"start_date"`
 	EndDate              *time.Time         `json:"end_date,omitempty"`
 	DeliveryInstructions *string            `json:"delivery_instructions,omitempty"`
+	AddressID            *int64             `json:"address_id,omitempty"`
+	DeliveryWeekdays     []time.Weekday     `json:"delivery_weekdays,omitempty"`     // weekly plans; defaults to the start date's weekday
+	DeliveryDayOfMonth   *int               `json:"delivery_day_of_month,omitempty"` // monthly plans; defaults to the start date's day
 	Items                []SubscriptionItem `json:"items,omitempty"`
 	CreatedAt            time.Time          `json:"created_at"`
 	UpdatedAt            time.Time          `json:"updated_at"`
@@ -42,11 +45,12 @@ type Subscription struct {
 
 // SubscriptionItem represents one product line within a subscription
 type SubscriptionItem struct {
-	ID             int64           `json:"id"`
-	SubscriptionID int64           `json:"subscription_id"`
-	VariantID      int64           `json:"variant_id"`
-	Variant        *ProductVariant `json:"variant,omitempty"`
-	Quantity       decimal.Decimal `json:"quantity"`
+	ID             int64            `json:"id"`
+	SubscriptionID int64            `json:"subscription_id"`
+	VariantID      int64            `json:"variant_id"`
+	Variant        *ProductVariant  `json:"variant,omitempty"`
+	Quantity       decimal.Decimal  `json:"quantity"`
+	UnitPrice      *decimal.Decimal `json:"unit_price,omitempty"` // locked-in price, billed instead of the variant's selling price
 }
 
 // IsActive returns true if the subscription is currently active
@@ -62,6 +66,100 @@ func (s *Subscription) IsExpired() bool {
 	return time.Now().After(*s.EndDate)
 }
 
+// IsScheduledOn reports whether the subscription's frequency and delivery days fall on the date.
+// Alternate-day plans count from the start date. Status and pause windows are not considered.
+func (s *Subscription) IsScheduledOn(date time.Time) bool {
+	day := calendarDate(date)
+	start := calendarDate(s.StartDate)
+	if day.Before(start) {
+		return false
+	}
+	if s.EndDate != nil && day.After(calendarDate(*s.EndDate)) {
+		return false
+	}
+
+	switch s.Frequency {
+	case SubscriptionFrequencyDaily:
+		return true
+	case SubscriptionFrequencyAlternateDays:
+		return int(day.Sub(start).Hours()/24)%2 == 0
+	case SubscriptionFrequencyWeekly:
+		if len(s.DeliveryWeekdays) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		for _, wd := range s.DeliveryWeekdays {
+			if day.Weekday() == wd {
+				return true
+			}
+		}
+		return false
+	case SubscriptionFrequencyMonthly:
+		dom := start.Day()
+		if s.DeliveryDayOfMonth != nil {
+			dom = *s.DeliveryDayOfMonth
+		}
+		// Days past the end of a short month fall on its last day
+		if lastDay := day.AddDate(0, 1, -day.Day()).Day(); dom > lastDay {
+			dom = lastDay
+		}
+		return day.Day() == dom
+	}
+	return false
+}
+
+// ItemsFor returns the items due on the date with its one-off quantities applied. On a date the
+// schedule skips only the overridden items are due, at their override quantities. Items overridden
+// to zero are left out.
+func (s *Subscription) ItemsFor(date time.Time, overrides []SubscriptionDateOverride) []SubscriptionItem {
+	quantities := make(map[int64]decimal.Decimal, len(overrides))
+	for _, o := range overrides {
+		quantities[o.VariantID] = o.Quantity
+	}
+	scheduled := s.IsScheduledOn(date)
+
+	items := make([]SubscriptionItem, 0, len(s.Items))
+	for _, item := range s.Items {
+		qty, overridden := quantities[item.VariantID]
+		if overridden {
+			item.Quantity = qty
+		} else if !scheduled {
+			continue
+		}
+		if item.Quantity.IsPositive() {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// calendarDate strips the time of day so whole days can be compared
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SubscriptionPause is a vacation hold: no deliveries are due from StartDate to EndDate inclusive
+type SubscriptionPause struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	Reason         *string   `json:"reason,omitempty"`
+	CreatedBy      *int64    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SubscriptionDateOverride replaces an item's quantity for a single delivery date
+type SubscriptionDateOverride struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	DeliveryDate   time.Time       `json:"delivery_date"`
+	VariantID      int64           `json:"variant_id"`
+	Quantity       decimal.Decimal `json:"quantity"` // 0 skips the item that day
+	Notes          *string         `json:"notes,omitempty"`
+	CreatedBy      *int64          `json:"created_by,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
 // DeliveryStatus represents the status of a specific daily delivery
 type DeliveryStatus string
 
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceStatus represents whether a subscription invoice has been settled
+type InvoiceStatus string
+
+const (
+	InvoiceStatusOutstanding InvoiceStatus = "outstanding"
+	InvoiceStatusPaid        InvoiceStatus = "paid"
+)
+
+// SubscriptionInvoice bills a customer for the subscription deliveries made in a billing period
+type SubscriptionInvoice struct {
+	ID                int64                     `json:"id"`
+	InvoiceNumber     string                    `json:"invoice_number"`
+	Sequence          int                       `json:"sequence"` // 1 for a customer's first invoice of the period, then supplementary ones
+	CustomerID        int64                     `json:"customer_id"`
+	CustomerName      string                    `json:"customer_name,omitempty"`
+	PeriodStart       time.Time                 `json:"period_start"`
+	PeriodEnd         time.Time                 `json:"period_end"`
+	TotalAmount       decimal.Decimal           `json:"total_amount"`
+	AmountPaid        decimal.Decimal           `json:"amount_paid"`
+	Status            InvoiceStatus             `json:"status"`
+	DueDate           *time.Time                `json:"due_date,omitempty"`
+	GeneratedByUserID *int64                    `json:"generated_by_user_id,omitempty"`
+	Lines             []SubscriptionInvoiceLine `json:"lines,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+}
+
+// SubscriptionInvoiceLine is one delivered product on one day
+type SubscriptionInvoiceLine struct {
+	ID             int64           `json:"id"`
+	InvoiceID      int64           `json:"invoice_id"`
+	SubscriptionID *int64          `json:"subscription_id,omitempty"`
+	DeliveryID     *int64          `json:"delivery_id,omitempty"`
+	DeliveryDate   time.Time       `json:"delivery_date"`
+	VariantID      int64           `json:"variant_id"`
+	VariantName    string          `json:"variant_name,omitempty"`
+	Unit           string          `json:"unit,omitempty"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	LineTotal      decimal.Decimal `json:"line_total"`
+}
+
+// BalanceDue returns the unpaid amount of the invoice
+func (i *SubscriptionInvoice) BalanceDue() decimal.Decimal {
+	return i.TotalAmount.Sub(i.AmountPaid)
+}
+
+// ApplyPayment adds a payment to the invoice and marks it paid once fully settled
+func (i *SubscriptionInvoice) ApplyPayment(amount decimal.Decimal) {
+	i.AmountPaid = i.AmountPaid.Add(amount)
+	if !i.BalanceDue().IsPositive() {
+		i.Status = InvoiceStatusPaid
+	}
+}
+
+// BillingPeriod is a calendar month of subscription deliveries
+type BillingPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"` // last day of the month, inclusive
+}
+
+// NewBillingPeriod returns the billing period for a calendar month
+func NewBillingPeriod(year int, month time.Month) BillingPeriod {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return BillingPeriod{Start: start, End: start.AddDate(0, 1, -1)}
+}
+
+// ParseBillingPeriod parses a "YYYY-MM" month into a billing period
+func ParseBillingPeriod(month string) (BillingPeriod, error) {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return BillingPeriod{}, err
+	}
+	return NewBillingPeriod(t.Year(), t.Month()), nil
+}
+
+// InvoiceNumber returns the invoice number of a customer's invoice for the period; supplementary
+// invoices carry their sequence
+func (p BillingPeriod) InvoiceNumber(customerID int64, sequence int) string {
+	number := fmt.Sprintf("SUB-%s-%06d", p.Start.Format("200601"), customerID)
+	if sequence > 1 {
+		number = fmt.Sprintf("%s-%d", number, sequence)
+	}
+	return number
+}
+
+// BillableDelivery is a delivered subscription item priced for billing
+type BillableDelivery struct {
+	CustomerID     int64
+	SubscriptionID int64
+	DeliveryID     int64
+	DeliveryDate   time.Time
+	VariantID      int64
+	Quantity       decimal.Decimal
+	UnitPrice      decimal.Decimal
+}
+
+// BillingRunResult summarizes a billing run over a period
+type BillingRunResult struct {
+	Period    BillingPeriod         `json:"period"`
+	Generated []SubscriptionInvoice `json:"generated"`
+	Skipped   []int64               `json:"skipped_customer_ids"` // customers already invoiced for the period with nothing more to bill
+}
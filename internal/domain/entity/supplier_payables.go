@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SupplierBillSource identifies what a supplier bill was raised from
+type SupplierBillSource string
+
+const (
+	SupplierBillFromProcurement SupplierBillSource = "procurement"
+	SupplierBillFromCollection  SupplierBillSource = "collection"
+)
+
+// SupplierBillStatus represents how much of a supplier bill has been paid
+type SupplierBillStatus string
+
+const (
+	SupplierBillOpen          SupplierBillStatus = "open"
+	SupplierBillPartiallyPaid SupplierBillStatus = "partially_paid"
+	SupplierBillPaid          SupplierBillStatus = "paid"
+)
+
+// IsValid checks if the bill status is valid
+func (s SupplierBillStatus) IsValid() bool {
+	switch s {
+	case SupplierBillOpen, SupplierBillPartiallyPaid, SupplierBillPaid:
+		return true
+	}
+	return false
+}
+
+// SupplierBill is what we owe a supplier for goods accepted against a purchase order, or for
+// the collections made from them over a period
+type SupplierBill struct {
+	ID              int64              `json:"id"`
+	SupplierID      int64              `json:"supplier_id"`
+	SupplierName    string             `json:"supplier_name,omitempty"`
+	Source          SupplierBillSource `json:"source"`
+	ProcurementID   *int64             `json:"procurement_id,omitempty"`
+	PeriodStart     *time.Time         `json:"period_start,omitempty"`
+	PeriodEnd       *time.Time         `json:"period_end,omitempty"`
+	Reference       string             `json:"reference,omitempty"` // the supplier's invoice number
+	BillDate        time.Time          `json:"bill_date"`
+	DueDate         *time.Time         `json:"due_date,omitempty"`
+	Amount          decimal.Decimal    `json:"amount"`
+	AmountPaid      decimal.Decimal    `json:"amount_paid"`
+	Status          SupplierBillStatus `json:"status"`
+	Notes           string             `json:"notes,omitempty"`
+	CreatedByUserID *int64             `json:"created_by_user_id,omitempty"`
+	Lines           []SupplierBillLine `json:"lines,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// SupplierBillLine is one received purchase order line or one collection on a bill
+type SupplierBillLine struct {
+	ID                 int64           `json:"id"`
+	BillID             int64           `json:"bill_id"`
+	GoodsReceiptItemID *int64          `json:"goods_receipt_item_id,omitempty"`
+	CollectionID       *int64          `json:"collection_id,omitempty"`
+	VariantID          int64           `json:"variant_id"`
+	VariantName        string          `json:"variant_name,omitempty"`
+	Unit               string          `json:"unit,omitempty"`
+	LineDate           time.Time       `json:"line_date"`
+	Quantity           decimal.Decimal `json:"quantity"`
+	UnitPrice          decimal.Decimal `json:"unit_price"`
+	LineTotal          decimal.Decimal `json:"line_total"`
+}
+
+// BalanceDue returns the unpaid amount of the bill
+func (b *SupplierBill) BalanceDue() decimal.Decimal {
+	return b.Amount.Sub(b.AmountPaid)
+}
+
+// ApplyPayment adds a payment to the bill and moves it to partially paid or paid
+func (b *SupplierBill) ApplyPayment(amount decimal.Decimal) {
+	b.AmountPaid = b.AmountPaid.Add(amount)
+	if b.BalanceDue().IsPositive() {
+		b.Status = SupplierBillPartiallyPaid
+	} else {
+		b.Status = SupplierBillPaid
+	}
+}
+
+// SupplierPayment records money paid to a supplier, against a bill or on account
+type SupplierPayment struct {
+	ID               int64           `json:"id"`
+	SupplierID       int64           `json:"supplier_id"`
+	BillID           *int64          `json:"bill_id,omitempty"`
+	Amount           decimal.Decimal `json:"amount"`
+	PaymentMethod    string          `json:"payment_method"`
+	Reference        string          `json:"reference,omitempty"`
+	Notes            string          `json:"notes,omitempty"`
+	PaidAt           time.Time       `json:"paid_at"`
+	RecordedByUserID *int64          `json:"recorded_by_user_id,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// SupplierLedgerEntryType identifies what posted a supplier ledger entry
+type SupplierLedgerEntryType string
+
+const (
+	SupplierLedgerBill       SupplierLedgerEntryType = "bill"
+	SupplierLedgerPayment    SupplierLedgerEntryType = "payment"
+	SupplierLedgerAdjustment SupplierLedgerEntryType = "adjustment"
+)
+
+// SupplierLedgerEntry is a credit (amount we owe) or debit (amount we paid) on a supplier's account
+type SupplierLedgerEntry struct {
+	ID              int64                   `json:"id"`
+	SupplierID      int64                   `json:"supplier_id"`
+	EntryType       SupplierLedgerEntryType `json:"entry_type"`
+	BillID          *int64                  `json:"bill_id,omitempty"`
+	PaymentID       *int64                  `json:"payment_id,omitempty"`
+	Debit           decimal.Decimal         `json:"debit"`
+	Credit          decimal.Decimal         `json:"credit"`
+	Balance         decimal.Decimal         `json:"balance"` // running amount owed, computed when listing
+	Description     string                  `json:"description,omitempty"`
+	EntryDate       time.Time               `json:"entry_date"`
+	DueDate         *time.Time              `json:"due_date,omitempty"`
+	CreatedByUserID *int64                  `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// SupplierAging is the amount owed to a supplier split by how overdue unpaid bills are
+type SupplierAging struct {
+	SupplierID   int64           `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name"`
+	Balance      decimal.Decimal `json:"balance"`
+	AgingBuckets
+}
+
+// SupplierStatement lists a supplier's ledger activity over a period
+type SupplierStatement struct {
+	Supplier       *Supplier             `json:"supplier"`
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	OpeningBalance decimal.Decimal       `json:"opening_balance"`
+	Entries        []SupplierLedgerEntry `json:"entries"`
+	TotalDebit     decimal.Decimal       `json:"total_debit"`
+	TotalCredit    decimal.Decimal       `json:"total_credit"`
+	ClosingBalance decimal.Decimal       `json:"closing_balance"`
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SupplierPriceList is the price a supplier charges for a variant over a date range, with
+// quantity breaks. A list without ValidTo runs until the next list for the variant starts.
+type SupplierPriceList struct {
+	ID              int64               `json:"id"`
+	SupplierID      int64               `json:"supplier_id"`
+	SupplierName    string              `json:"supplier_name,omitempty"`
+	VariantID       int64               `json:"variant_id"`
+	VariantName     string              `json:"variant_name,omitempty"`
+	ValidFrom       time.Time           `json:"valid_from"`
+	ValidTo         *time.Time          `json:"valid_to,omitempty"`
+	Notes           string              `json:"notes,omitempty"`
+	CreatedByUserID *int64              `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	Tiers           []SupplierPriceTier `json:"tiers"`
+}
+
+// SupplierPriceTier is the unit price paid once an order line reaches MinQuantity
+type SupplierPriceTier struct {
+	ID          int64           `json:"id"`
+	MinQuantity decimal.Decimal `json:"min_quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+}
+
+// CoversDate reports whether the list is in force on the given day
+func (l *SupplierPriceList) CoversDate(on time.Time) bool {
+	day := calendarDate(on)
+	if day.Before(l.ValidFrom) {
+		return false
+	}
+	return l.ValidTo == nil || !day.After(*l.ValidTo)
+}
+
+// Overlaps reports whether the two lists are in force on any common day
+func (l *SupplierPriceList) Overlaps(other *SupplierPriceList) bool {
+	if l.ValidTo != nil && l.ValidTo.Before(other.ValidFrom) {
+		return false
+	}
+	if other.ValidTo != nil && other.ValidTo.Before(l.ValidFrom) {
+		return false
+	}
+	return true
+}
+
+// TierFor returns the tier with the highest minimum quantity the given quantity reaches
+func (l *SupplierPriceList) TierFor(quantity decimal.Decimal) (SupplierPriceTier, bool) {
+	var best SupplierPriceTier
+	found := false
+	for _, t := range l.Tiers {
+		if quantity.LessThan(t.MinQuantity) {
+			continue
+		}
+		if !found || t.MinQuantity.GreaterThan(best.MinQuantity) {
+			best = t
+			found = true
+		}
+	}
+	return best, found
+}
+
+// BasePrice is the unit price of the tier starting from zero
+func (l *SupplierPriceList) BasePrice() decimal.Decimal {
+	tier, _ := l.TierFor(decimal.Zero)
+	return tier.UnitPrice
+}
+
+// SupplierPrice is the unit price a supplier charges for a quantity of a variant on a date. It comes
+// from the price list in force then, or from the agreed cost when no list covers the date.
+type SupplierPrice struct {
+	SupplierID  int64           `json:"supplier_id"`
+	VariantID   int64           `json:"variant_id"`
+	Date        time.Time       `json:"date"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	MinQuantity decimal.Decimal `json:"min_quantity"`
+	PriceListID *int64          `json:"price_list_id,omitempty"`
+	ValidFrom   *time.Time      `json:"valid_from,omitempty"`
+	ValidTo     *time.Time      `json:"valid_to,omitempty"`
+}
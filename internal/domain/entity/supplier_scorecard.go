@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Weights of each measure in a supplier's overall score. Measures without data in the period are
+// left out and the remaining weights scaled up to 100.
+var scorecardWeights = struct {
+	onTime, fill, price, rejection, consistency float64
+}{onTime: 0.25, fill: 0.25, price: 0.20, rejection: 0.15, consistency: 0.15}
+
+// SupplierScorecard measures how a supplier performed over a period, from the purchase orders
+// raised with them and the collections made from them
+type SupplierScorecard struct {
+	SupplierID   int64     `json:"supplier_id"`
+	SupplierName string    `json:"supplier_name"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+
+	// Goods receipts against orders with an expected delivery date, and those received by that date
+	Receipts       int `json:"receipts"`
+	OnTimeReceipts int `json:"on_time_receipts"`
+	// Quantities ordered on due orders and received against them, each line counted up to its order
+	QuantityOrdered  decimal.Decimal `json:"quantity_ordered"`
+	QuantityReceived decimal.Decimal `json:"quantity_received"`
+	// Quantities accepted and rejected on the period's goods receipts
+	QuantityAccepted decimal.Decimal `json:"quantity_accepted"`
+	QuantityRejected decimal.Decimal `json:"quantity_rejected"`
+	// Ordered value at the supplier's list price (the agreed cost for lines raised without one) and at
+	// the price actually ordered at, for lines with an agreed price
+	AgreedValue  decimal.Decimal `json:"agreed_value"`
+	OrderedValue decimal.Decimal `json:"ordered_value"`
+	// Collections in the period, the days any were made, and the mean and spread of daily volume
+	Collections       int             `json:"collections"`
+	AdulteratedCount  int             `json:"adulterated_collections"`
+	CollectionDays    int             `json:"collection_days"`
+	PeriodDays        int             `json:"period_days"`
+	DailyVolumeMean   decimal.Decimal `json:"daily_volume_mean"`
+	DailyVolumeStdDev decimal.Decimal `json:"daily_volume_stddev"`
+
+	OnTimeRate        *decimal.Decimal `json:"on_time_rate,omitempty"`
+	FillRate          *decimal.Decimal `json:"fill_rate,omitempty"`
+	PriceVariance     *decimal.Decimal `json:"price_variance,omitempty"` // above zero when paying more than agreed
+	RejectionRate     *decimal.Decimal `json:"rejection_rate,omitempty"`
+	VolumeConsistency *decimal.Decimal `json:"volume_consistency,omitempty"` // 1 for the same volume every day
+	Score             *decimal.Decimal `json:"score,omitempty"`              // 0-100
+}
+
+// Compute derives the rates and the overall score from the measures
+func (s *SupplierScorecard) Compute() {
+	one := decimal.NewFromInt(1)
+	ratio := func(num, den decimal.Decimal) *decimal.Decimal {
+		if !den.IsPositive() {
+			return nil
+		}
+		r := num.Div(den).Round(4)
+		return &r
+	}
+
+	s.OnTimeRate = ratio(decimal.NewFromInt(int64(s.OnTimeReceipts)), decimal.NewFromInt(int64(s.Receipts)))
+	s.FillRate = ratio(s.QuantityReceived, s.QuantityOrdered)
+	s.RejectionRate = ratio(s.QuantityRejected, s.QuantityAccepted.Add(s.QuantityRejected))
+	s.PriceVariance = ratio(s.OrderedValue.Sub(s.AgreedValue), s.AgreedValue)
+	s.VolumeConsistency = nil
+	if s.DailyVolumeMean.IsPositive() {
+		c := decimal.Max(decimal.Zero, one.Sub(s.DailyVolumeStdDev.Div(s.DailyVolumeMean))).Round(4)
+		s.VolumeConsistency = &c
+	}
+
+	var total, weights float64
+	add := func(value *decimal.Decimal, weight float64) {
+		if value == nil {
+			return
+		}
+		v, _ := value.Float64()
+		total += v * weight
+		weights += weight
+	}
+	add(s.OnTimeRate, scorecardWeights.onTime)
+	add(s.FillRate, scorecardWeights.fill)
+	if s.PriceVariance != nil {
+		// Paying at or below the agreed cost scores in full; each percent above it costs a percent
+		priceScore := decimal.Max(decimal.Zero, one.Sub(decimal.Max(decimal.Zero, *s.PriceVariance)))
+		add(&priceScore, scorecardWeights.price)
+	}
+	if s.RejectionRate != nil {
+		accepted := one.Sub(*s.RejectionRate)
+		add(&accepted, scorecardWeights.rejection)
+	}
+	add(s.VolumeConsistency, scorecardWeights.consistency)
+
+	s.Score = nil
+	if weights > 0 {
+		score := decimal.NewFromFloat(total / weights * 100).Round(1)
+		s.Score = &score
+	}
+}
+
+// SupplierRanking is a supplier of a variant with its scorecard, as ranked for preferred-supplier selection
+type SupplierRanking struct {
+	Rank            int                `json:"rank"`
+	SupplierVariant SupplierVariant    `json:"supplier_variant"`
+	Scorecard       *SupplierScorecard `json:"scorecard"`
+}
@@ -1,12 +1,18 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // Role represents a user role in the system
 type Role struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
+	// POApprovalLimit caps the total of the purchase orders the role may approve; nil is no cap
+	POApprovalLimit *decimal.Decimal `json:"po_approval_limit,omitempty"`
 }
 
 // Permission represents a system permission
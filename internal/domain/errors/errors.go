@@ -29,30 +29,84 @@ var (
 	ErrProductVariantNotFound = errors.New("product variant not found")
 	ErrSKUExists              = errors.New("SKU already exists")
 	ErrBarcodeExists          = errors.New("barcode already exists")
+	ErrRecipeNotFound         = errors.New("recipe not found")
+	ErrRecipeExists           = errors.New("recipe already exists for this variant")
+	ErrVariantNotManufactured = errors.New("product variant is not manufactured")
 
 	// Warehouse errors
 	ErrWarehouseNotFound = errors.New("warehouse not found")
 
 	// Supplier errors
-	ErrSupplierNotFound = errors.New("supplier not found")
+	ErrSupplierNotFound      = errors.New("supplier not found")
+	ErrVariantNotSupplied    = errors.New("supplier does not supply this product variant")
+	ErrPriceListNotFound     = errors.New("supplier price list not found")
+	ErrPriceListOverlap      = errors.New("price list overlaps another price list for this variant")
+	ErrSupplierPriceNotFound = errors.New("no price agreed with the supplier for this variant")
+
+	// Collection errors
+	ErrRateChartNotFound       = errors.New("collection rate chart not found")
+	ErrCollectionRouteNotFound = errors.New("collection route not found")
+	ErrSupplierNotOnRoute      = errors.New("supplier is not a stop on the collection route")
 
 	// Customer errors
-	ErrCustomerNotFound = errors.New("customer not found")
+	ErrCustomerNotFound      = errors.New("customer not found")
+	ErrAddressNotFound       = errors.New("address not found")
+	ErrCustomerAlreadyLinked = errors.New("customer is already linked to another account")
+	ErrCreditNotAllowed      = errors.New("customer payment terms do not allow credit sales")
+	ErrCreditLimitExceeded   = errors.New("credit sale would exceed the customer's credit limit")
 
 	// Inventory errors
-	ErrInsufficientStock = errors.New("insufficient stock")
-	ErrInvalidQuantity   = errors.New("invalid quantity")
-	ErrTransferNotFound  = errors.New("transfer not found")
-	ErrSameWarehouse     = errors.New("source and destination warehouse cannot be the same")
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrInvalidQuantity        = errors.New("invalid quantity")
+	ErrTransferNotFound       = errors.New("transfer not found")
+	ErrSameWarehouse          = errors.New("source and destination warehouse cannot be the same")
+	ErrTransferStatus         = errors.New("transfer cannot be changed in its current status")
+	ErrAdjustmentNotFound     = errors.New("inventory adjustment not found")
+	ErrAdjustmentNotPending   = errors.New("inventory adjustment is not pending approval")
+	ErrSelfApproval           = errors.New("an adjustment cannot be approved by the user who requested it")
+	ErrStockTakeNotFound      = errors.New("stock take not found")
+	ErrStockTakeInProgress    = errors.New("warehouse already has an open stock take")
+	ErrStockTakeNotOpen       = errors.New("stock take is not open")
+	ErrStockExpired           = errors.New("only expired stock is available")
+	ErrLotNotFound            = errors.New("inventory lot not found")
+	ErrReorderSettingNotFound = errors.New("reorder setting not found")
+	ErrStockReserved          = errors.New("stock is reserved for orders and subscriptions")
+
+	// Order errors
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrPincodeNotServiceable   = errors.New("pincode is not serviceable")
+	ErrBelowMinOrderAmount     = errors.New("order is below the minimum order amount for this area")
 
 	// Procurement errors
-	ErrProcurementNotFound = errors.New("procurement not found")
+	ErrProcurementNotFound     = errors.New("procurement not found")
+	ErrProcurementStatus       = errors.New("purchase order cannot move to that status from its current status")
+	ErrApprovalRequired        = errors.New("purchase orders are approved through the approval endpoint")
+	ErrApprovalLimitExceeded   = errors.New("purchase order total exceeds the approval limit of your role")
+	ErrProcurementSelfApproval = errors.New("a purchase order cannot be approved by the user who raised it")
+	ErrReceiptRequired         = errors.New("purchase orders are received through goods receipts")
+	ErrProcurementReceived     = errors.New("purchase order cannot be cancelled once goods have been received against it")
+	ErrGoodsReceiptNotFound    = errors.New("goods receipt not found")
+	ErrReceiptItemNotOnOrder   = errors.New("receipt line is not on the purchase order")
+	ErrRejectionReasonRequired = errors.New("rejected quantities need a rejection reason")
+	ErrOverReceipt             = errors.New("received quantity exceeds the ordered quantity beyond the allowed tolerance")
 
 	// Production errors
 	ErrProductionRunNotFound = errors.New("production run not found")
+	ErrBatchCodeExists       = errors.New("batch code already exists")
+	ErrNotFactoryWarehouse   = errors.New("production can only be recorded at a factory warehouse")
 
 	// Subscription errors
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrDeliveryStockIssued  = errors.New("a delivered delivery has had its stock issued and cannot be changed to another status")
+	ErrNoServingWarehouse   = errors.New("no active delivery zone with a warehouse serves the subscription's address")
+	ErrInvoiceNotFound      = errors.New("invoice not found")
+	ErrInvoiceExists        = errors.New("customer is already invoiced for this period")
+	ErrInvoiceAlreadyPaid   = errors.New("invoice is already paid")
+
+	// Supplier payables errors
+	ErrSupplierBillNotFound = errors.New("supplier bill not found")
+	ErrSupplierBillPaid     = errors.New("supplier bill is already paid")
+	ErrNothingToBill        = errors.New("nothing left to bill")
 
 	// Expense errors
 	ErrExpenseCategoryNotFound = errors.New("expense category not found")
@@ -68,13 +122,26 @@ func IsNotFound(err error) bool {
 		errors.Is(err, ErrCategoryNotFound) ||
 		errors.Is(err, ErrProductFamilyNotFound) ||
 		errors.Is(err, ErrProductVariantNotFound) ||
+		errors.Is(err, ErrRecipeNotFound) ||
 		errors.Is(err, ErrWarehouseNotFound) ||
 		errors.Is(err, ErrSupplierNotFound) ||
+		errors.Is(err, ErrPriceListNotFound) ||
+		errors.Is(err, ErrSupplierPriceNotFound) ||
+		errors.Is(err, ErrRateChartNotFound) ||
+		errors.Is(err, ErrCollectionRouteNotFound) ||
 		errors.Is(err, ErrCustomerNotFound) ||
+		errors.Is(err, ErrAddressNotFound) ||
 		errors.Is(err, ErrTransferNotFound) ||
+		errors.Is(err, ErrAdjustmentNotFound) ||
+		errors.Is(err, ErrStockTakeNotFound) ||
+		errors.Is(err, ErrLotNotFound) ||
+		errors.Is(err, ErrReorderSettingNotFound) ||
 		errors.Is(err, ErrProcurementNotFound) ||
+		errors.Is(err, ErrGoodsReceiptNotFound) ||
 		errors.Is(err, ErrProductionRunNotFound) ||
 		errors.Is(err, ErrSubscriptionNotFound) ||
+		errors.Is(err, ErrInvoiceNotFound) ||
+		errors.Is(err, ErrSupplierBillNotFound) ||
 		errors.Is(err, ErrExpenseCategoryNotFound) ||
 		errors.Is(err, ErrExpenseNotFound)
 }
@@ -84,5 +151,11 @@ func IsConflict(err error) bool {
 	return errors.Is(err, ErrAlreadyExists) ||
 		errors.Is(err, ErrUsernameExists) ||
 		errors.Is(err, ErrSKUExists) ||
-		errors.Is(err, ErrBarcodeExists)
+		errors.Is(err, ErrBarcodeExists) ||
+		errors.Is(err, ErrBatchCodeExists) ||
+		errors.Is(err, ErrRecipeExists) ||
+		errors.Is(err, ErrCustomerAlreadyLinked) ||
+		errors.Is(err, ErrInvoiceExists) ||
+		errors.Is(err, ErrPriceListOverlap) ||
+		errors.Is(err, ErrStockTakeInProgress)
 }
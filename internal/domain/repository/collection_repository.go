@@ -2,12 +2,69 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/qwikshelf/api/internal/domain/entity"
 )
 
+// CollectionSummaryFilter selects the collections dated within [From, To) to summarise
+type CollectionSummaryFilter struct {
+	SupplierID  *int64
+	WarehouseID *int64
+	From        time.Time
+	To          time.Time
+}
+
+// CollectionFilter narrows a collection listing; From and To bound collected_at as [From, To)
+type CollectionFilter struct {
+	AgentID     *int64
+	SupplierID  *int64
+	SupplierIDs []int64
+	WarehouseID *int64
+	Shift       *entity.CollectionShift
+	From        *time.Time
+	To          *time.Time
+}
+
 type CollectionRepository interface {
 	Create(ctx context.Context, collection *entity.Collection) error
 	GetByID(ctx context.Context, id int64) (*entity.Collection, error)
-	List(ctx context.Context, offset, limit int) ([]entity.Collection, int64, error)
+	List(ctx context.Context, filter CollectionFilter, offset, limit int) ([]entity.Collection, int64, error)
+	// ListAll returns every collection matching the filter, oldest first
+	ListAll(ctx context.Context, filter CollectionFilter) ([]entity.Collection, error)
+
+	// SummarizeByShift totals collections per supplier, day and shift
+	SummarizeByShift(ctx context.Context, filter CollectionSummaryFilter) ([]entity.CollectionSummary, error)
+	// SummarizeByPeriod totals collections per supplier over the whole filter period
+	SummarizeByPeriod(ctx context.Context, filter CollectionSummaryFilter) ([]entity.CollectionSummary, error)
+}
+
+// CollectionRateChartFilter narrows a rate chart listing
+type CollectionRateChartFilter struct {
+	VariantID  *int64
+	ZoneID     *int64
+	SupplierID *int64
+	ActiveOnly bool
+}
+
+// CollectionRateChartRepository defines the interface for collection rate chart data access
+type CollectionRateChartRepository interface {
+	Create(ctx context.Context, chart *entity.CollectionRateChart) error
+	GetByID(ctx context.Context, id int64) (*entity.CollectionRateChart, error)
+	List(ctx context.Context, filter CollectionRateChartFilter) ([]entity.CollectionRateChart, error)
+	Deactivate(ctx context.Context, id int64) error
+
+	// FindApplicable returns the active chart for the variant in force on the date, preferring the
+	// supplier's own chart, then its zone's, then the default chart; nil when none applies
+	FindApplicable(ctx context.Context, variantID, supplierID int64, zoneID *int64, on time.Time) (*entity.CollectionRateChart, error)
+}
+
+// CollectionRouteRepository defines the interface for collection route data access
+type CollectionRouteRepository interface {
+	Create(ctx context.Context, route *entity.CollectionRoute) error
+	GetByID(ctx context.Context, id int64) (*entity.CollectionRoute, error)
+	List(ctx context.Context, agentID *int64, activeOnly bool) ([]entity.CollectionRoute, error)
+	Update(ctx context.Context, route *entity.CollectionRoute) error
+	// SetStops replaces the route's stops with the given ones in order
+	SetStops(ctx context.Context, routeID int64, stops []entity.CollectionRouteStop) error
 }
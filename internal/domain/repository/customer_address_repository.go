@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// CustomerAddressRepository defines the interface for customer address book data access
+type CustomerAddressRepository interface {
+	Create(ctx context.Context, address *entity.CustomerAddress) error
+	GetByID(ctx context.Context, id int64) (*entity.CustomerAddress, error)
+	ListByCustomer(ctx context.Context, customerID int64) ([]entity.CustomerAddress, error)
+	Update(ctx context.Context, address *entity.CustomerAddress) error
+	Delete(ctx context.Context, id int64) error
+}
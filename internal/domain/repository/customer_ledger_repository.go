@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// CustomerLedgerRepository defines the interface for accounts receivable data access
+type CustomerLedgerRepository interface {
+	// GetCreditAccountForUpdate locks the customer row and returns its credit terms and current balance
+	GetCreditAccountForUpdate(ctx context.Context, customerID int64) (*entity.CustomerCreditAccount, error)
+	AddEntry(ctx context.Context, entry *entity.CustomerLedgerEntry) error
+	CreatePayment(ctx context.Context, payment *entity.CustomerPayment) error
+
+	// GetBalance returns the balance of entries dated before the given time
+	GetBalance(ctx context.Context, customerID int64, before time.Time) (decimal.Decimal, error)
+	ListEntries(ctx context.Context, customerID int64, from, to time.Time) ([]entity.CustomerLedgerEntry, error)
+	ListEntriesUpTo(ctx context.Context, customerID *int64, asOf time.Time) ([]entity.CustomerLedgerEntry, error)
+	ListBalances(ctx context.Context, asOf time.Time) ([]entity.CustomerAging, error)
+}
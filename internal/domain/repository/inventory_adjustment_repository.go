@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// InventoryAdjustmentFilter narrows an adjustment listing
+type InventoryAdjustmentFilter struct {
+	WarehouseID *int64
+	Status      *entity.AdjustmentStatus
+	ReasonCode  *entity.AdjustmentReason
+}
+
+// InventoryAdjustmentRepository defines the interface for manual adjustment data access
+type InventoryAdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *entity.InventoryAdjustment) error
+	GetByID(ctx context.Context, id int64) (*entity.InventoryAdjustment, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (*entity.InventoryAdjustment, error)
+	List(ctx context.Context, filter InventoryAdjustmentFilter, offset, limit int) ([]entity.InventoryAdjustment, int64, error)
+	// UpdateReview saves the approval decision and the movement posted for it
+	UpdateReview(ctx context.Context, adjustment *entity.InventoryAdjustment) error
+	// Shrinkage totals approved write-offs made within [from, to) by warehouse and reason
+	Shrinkage(ctx context.Context, from, to time.Time, warehouseID *int64) ([]entity.ShrinkageSummary, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/shopspring/decimal"
 
@@ -12,38 +13,142 @@ import (
 type InventoryRepository interface {
 	// Inventory Levels
 	GetLevel(ctx context.Context, warehouseID, variantID int64) (*entity.InventoryLevel, error)
+	GetLevelForUpdate(ctx context.Context, warehouseID, variantID int64) (*entity.InventoryLevel, error)
 	GetLevelsByWarehouse(ctx context.Context, warehouseID int64) ([]entity.InventoryLevel, error)
 	GetLevelsByVariant(ctx context.Context, variantID int64) ([]entity.InventoryLevel, error)
 	List(ctx context.Context, offset, limit int) ([]entity.InventoryLevel, int64, error)
-	SetLevel(ctx context.Context, level *entity.InventoryLevel) error
-	AdjustLevel(ctx context.Context, warehouseID, variantID int64, quantityDelta decimal.Decimal) error
+	// AdjustLevel applies the movement's delta to the stock level, and to its lot if it has one,
+	// and appends it to the ledger
+	AdjustLevel(ctx context.Context, movement *entity.InventoryMovement) error
+
+	// Lots
+	// UpsertLot creates the lot of a batch at a warehouse, or loads it if the batch is already there
+	UpsertLot(ctx context.Context, lot *entity.InventoryLot) error
+	GetLotByID(ctx context.Context, id int64) (*entity.InventoryLot, error)
+	ListLotsForUpdate(ctx context.Context, warehouseID, variantID int64) ([]entity.InventoryLot, error)
+	ListLots(ctx context.Context, filter InventoryLotFilter) ([]entity.InventoryLot, error)
+	ListLotMovements(ctx context.Context, batchNumber string, variantID int64) ([]entity.LotTraceMovement, error)
+
+	// Movement ledger
+	ListMovements(ctx context.Context, warehouseID, variantID int64, from, to time.Time) ([]entity.InventoryMovement, error)
+	ListMovementsByReference(ctx context.Context, source entity.MovementSourceType, referenceID int64) ([]entity.InventoryMovement, error)
+	GetLedgerBalance(ctx context.Context, warehouseID, variantID int64, before time.Time) (decimal.Decimal, error)
+	ListDrift(ctx context.Context) ([]entity.InventoryDrift, error)
+	SyncLevelToLedger(ctx context.Context, warehouseID, variantID int64) error
 
 	// Transfers
 	CreateTransfer(ctx context.Context, transfer *entity.InventoryTransfer) error
 	GetTransferByID(ctx context.Context, id int64) (*entity.InventoryTransfer, error)
-	ListTransfers(ctx context.Context, offset, limit int) ([]entity.InventoryTransfer, int64, error)
-	UpdateTransferStatus(ctx context.Context, id int64, status entity.TransferStatus) error
+	GetTransferByIDForUpdate(ctx context.Context, id int64) (*entity.InventoryTransfer, error)
+	ListTransfers(ctx context.Context, filter TransferFilter, offset, limit int) ([]entity.InventoryTransfer, int64, error)
+	// UpdateTransfer saves the status of a transfer, who moved it on and the item quantities recorded so far
+	UpdateTransfer(ctx context.Context, transfer *entity.InventoryTransfer) error
+	ListInTransit(ctx context.Context, destinationWarehouseID *int64) ([]entity.InTransitStock, error)
+
+	// Reservations
+	CreateReservation(ctx context.Context, reservation *entity.StockReservation) error
+	// SumHeldReservations returns the stock of a variant at a warehouse held by active reservations
+	// that have not expired
+	SumHeldReservations(ctx context.Context, warehouseID, variantID int64) (decimal.Decimal, error)
+	ListReservationsByReference(ctx context.Context, source entity.ReservationSourceType, referenceID int64) ([]entity.StockReservation, error)
+	ListReservations(ctx context.Context, filter ReservationFilter, offset, limit int) ([]entity.StockReservation, int64, error)
+	// CloseReservations moves the active reservations in scope to the given status and returns how many it closed
+	CloseReservations(ctx context.Context, scope ReservationScope, status entity.ReservationStatus) (int64, error)
+	// ExpireReservations marks active reservations past their expiry time as expired
+	ExpireReservations(ctx context.Context, asOf time.Time) (int64, error)
+	GetAvailability(ctx context.Context, filter AvailabilityFilter) ([]entity.StockAvailability, error)
+
+	// Costing
+	ListCostLayers(ctx context.Context, filter CostLayerFilter) ([]entity.CostLayer, error)
+	// GetValuation returns the stock and its value per warehouse and variant from the last ledger
+	// entry recorded before a point in time
+	GetValuation(ctx context.Context, before time.Time, warehouseID *int64) ([]entity.InventoryValuationLine, error)
 
 	// Batch operations
-	GetExpiringStock(ctx context.Context, daysUntilExpiry int) ([]entity.InventoryLevel, error)
+	GetExpiringStock(ctx context.Context, daysUntilExpiry int) ([]entity.InventoryLot, error)
+	// GetLowStock returns levels at or below their reorder point, or below threshold where none is set
 	GetLowStock(ctx context.Context, threshold decimal.Decimal) ([]entity.InventoryLevel, error)
 }
 
+// TransferFilter narrows a transfer listing
+type TransferFilter struct {
+	Status *entity.TransferStatus
+	// WarehouseID keeps transfers from or to this warehouse
+	WarehouseID            *int64
+	SourceWarehouseID      *int64
+	DestinationWarehouseID *int64
+}
+
+// ReservationFilter narrows a reservation listing
+type ReservationFilter struct {
+	WarehouseID *int64
+	VariantID   *int64
+	SourceType  *entity.ReservationSourceType
+	ReferenceID *int64
+	Status      *entity.ReservationStatus
+	ReservedFor *time.Time
+}
+
+// ReservationScope selects the reservations of one order or subscription, optionally only those
+// reserved for delivery dates between From and To inclusive
+type ReservationScope struct {
+	SourceType  entity.ReservationSourceType
+	ReferenceID int64
+	From        *time.Time
+	To          *time.Time
+}
+
+// AvailabilityFilter narrows an availability listing
+type AvailabilityFilter struct {
+	WarehouseID *int64
+	VariantIDs  []int64
+}
+
+// CostLayerFilter narrows a cost layer listing
+type CostLayerFilter struct {
+	WarehouseID *int64
+	VariantID   *int64
+	// IncludeConsumed keeps layers whose stock has all been issued
+	IncludeConsumed bool
+}
+
+// InventoryLotFilter narrows a lot listing
+type InventoryLotFilter struct {
+	WarehouseID *int64
+	VariantID   *int64
+	BatchNumber *string
+	// ExpiringBy keeps lots with an expiry date on or before this day
+	ExpiringBy *time.Time
+	// IncludeEmpty keeps lots that have been used up
+	IncludeEmpty bool
+}
+
 // ProcurementRepository defines the interface for procurement data access
 type ProcurementRepository interface {
 	Create(ctx context.Context, procurement *entity.Procurement) error
 	GetByID(ctx context.Context, id int64) (*entity.Procurement, error)
+	// GetByIDForUpdate retrieves a procurement and locks it until the transaction ends
+	GetByIDForUpdate(ctx context.Context, id int64) (*entity.Procurement, error)
 	List(ctx context.Context, offset, limit int) ([]entity.Procurement, int64, error)
 	ListBySupplier(ctx context.Context, supplierID int64) ([]entity.Procurement, error)
-	UpdateStatus(ctx context.Context, id int64, status entity.ProcurementStatus) error
-	UpdateItemReceived(ctx context.Context, itemID int64, quantityReceived float64) error
+	// UpdateStatus moves a procurement from one status to another, failing if it has already moved on
+	UpdateStatus(ctx context.Context, id int64, from, to entity.ProcurementStatus) error
+	AddStatusEvent(ctx context.Context, event *entity.ProcurementStatusEvent) error
+	ListStatusHistory(ctx context.Context, procurementID int64) ([]entity.ProcurementStatusEvent, error)
+	CreateReceipt(ctx context.Context, receipt *entity.GoodsReceipt) error
+	// AddReceiptItem records a receipt line and adds its quantities to the purchase order line's totals
+	AddReceiptItem(ctx context.Context, item *entity.GoodsReceiptItem) error
+	GetReceiptByID(ctx context.Context, id int64) (*entity.GoodsReceipt, error)
+	ListReceipts(ctx context.Context, procurementID int64) ([]entity.GoodsReceipt, error)
 }
 
 // ProductionRepository defines the interface for production data access
 type ProductionRepository interface {
 	CreateRun(ctx context.Context, run *entity.ProductionRun) error
 	GetRunByID(ctx context.Context, id int64) (*entity.ProductionRun, error)
+	GetRunByBatchCode(ctx context.Context, batchCode string) (*entity.ProductionRun, error)
 	ListRuns(ctx context.Context, offset, limit int) ([]entity.ProductionRun, int64, error)
+	ListRunsBetween(ctx context.Context, from, to time.Time) ([]entity.ProductionRun, error)
 	AddLog(ctx context.Context, log *entity.ProductionLog) error
 	GetLogsByRun(ctx context.Context, runID int64) ([]entity.ProductionLog, error)
 }
@@ -10,6 +10,7 @@ import (
 type PincodeRepository interface {
 	// Serviceability
 	GetByPincode(ctx context.Context, pincode string) (*entity.ServiceableArea, error)
+	GetZoneByCoordinates(ctx context.Context, lat, lng float64) (int64, error)
 	
 	// Zones
 	CreateZone(ctx context.Context, zone *entity.DeliveryZone) error
@@ -39,3 +39,11 @@ type ProductVariantRepository interface {
 	ExistsBySKU(ctx context.Context, sku string) (bool, error)
 	ExistsByBarcode(ctx context.Context, barcode string) (bool, error)
 }
+
+// RecipeRepository defines the interface for recipe (bill of materials) data access
+type RecipeRepository interface {
+	Create(ctx context.Context, recipe *entity.Recipe) error
+	GetByOutputVariant(ctx context.Context, variantID int64) (*entity.Recipe, error)
+	Update(ctx context.Context, recipe *entity.Recipe) error
+	Delete(ctx context.Context, variantID int64) error
+}
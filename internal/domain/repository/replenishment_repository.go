@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// ReplenishmentRepository defines the interface for reorder settings and the stock position
+// the replenishment engine works from
+type ReplenishmentRepository interface {
+	// UpsertReorderSetting creates or replaces the setting of a variant at a warehouse
+	UpsertReorderSetting(ctx context.Context, setting *entity.ReorderSetting) error
+	DeleteReorderSetting(ctx context.Context, warehouseID, variantID int64) error
+	ListReorderSettings(ctx context.Context, warehouseID *int64) ([]entity.ReorderSetting, error)
+	// ListCandidates returns every reorder setting, optionally for one warehouse, with the stock
+	// on hand, on order and in transit, and the average daily base quantity sold since the given time
+	ListCandidates(ctx context.Context, warehouseID *int64, salesSince time.Time) ([]entity.ReplenishmentCandidate, error)
+}
@@ -11,6 +11,13 @@ import (
 type SaleRepository interface {
 	Create(ctx context.Context, sale *entity.Sale) error
 	GetByID(ctx context.Context, id int64) (*entity.Sale, error)
-	List(ctx context.Context, warehouseID *int64, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error)
+	List(ctx context.Context, warehouseID *int64, status *entity.OrderStatus, startDate, endDate *time.Time, offset, limit int) ([]entity.Sale, int64, error)
 	ListByCustomer(ctx context.Context, customerID int64, offset, limit int) ([]entity.Sale, int64, error)
+	// SetItemCosts saves the cost of goods of each of the sale's items, clearing it where unset
+	SetItemCosts(ctx context.Context, items []entity.SaleItem) error
+
+	// Order lifecycle
+	UpdateStatus(ctx context.Context, id int64, from, to entity.OrderStatus) error
+	AddStatusEvent(ctx context.Context, event *entity.OrderStatusEvent) error
+	ListStatusHistory(ctx context.Context, saleID int64) ([]entity.OrderStatusEvent, error)
 }
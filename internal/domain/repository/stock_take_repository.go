@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// StockTakeFilter narrows a stock-take listing
+type StockTakeFilter struct {
+	WarehouseID *int64
+	Status      *entity.StockTakeStatus
+}
+
+// StockTakeRepository defines the interface for stock-take data access
+type StockTakeRepository interface {
+	// Create opens a session and snapshots the current level of the given variants at the
+	// warehouse as their expected quantity; nil variantIDs snapshots every stocked variant.
+	// Returns ErrStockTakeInProgress if the warehouse already has an open session.
+	Create(ctx context.Context, stockTake *entity.StockTake, variantIDs []int64) error
+	GetByID(ctx context.Context, id int64) (*entity.StockTake, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (*entity.StockTake, error)
+	List(ctx context.Context, filter StockTakeFilter, offset, limit int) ([]entity.StockTake, int64, error)
+	// RecordCount sets, or with add adds to, the counted quantity of a variant. A variant
+	// outside the snapshot is added to the session with an expected quantity of zero.
+	RecordCount(ctx context.Context, stockTakeID, variantID int64, quantity decimal.Decimal, add bool, userID *int64) (*entity.StockTakeLine, error)
+	SetLineAdjustment(ctx context.Context, lineID, adjustmentID int64) error
+	Close(ctx context.Context, stockTake *entity.StockTake) error
+
+	// ListCycleCountItems returns every variant stocked at the warehouse with the value it consumed
+	// since the given time, sorted by that value descending, and when it was last counted
+	ListCycleCountItems(ctx context.Context, warehouseID int64, since time.Time) ([]entity.CycleCountItem, error)
+	ListCycleSchedules(ctx context.Context, warehouseID int64) ([]entity.CycleCountSchedule, error)
+	SetCycleSchedule(ctx context.Context, schedule *entity.CycleCountSchedule) error
+}
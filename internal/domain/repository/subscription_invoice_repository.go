@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// SubscriptionInvoiceFilter narrows a subscription invoice listing
+type SubscriptionInvoiceFilter struct {
+	CustomerID  *int64
+	PeriodStart *time.Time
+	Status      *entity.InvoiceStatus
+}
+
+// SubscriptionInvoiceRepository defines the interface for subscription billing data access
+type SubscriptionInvoiceRepository interface {
+	// ListBillableDeliveries returns delivered subscription items in [from, to] that are not yet invoiced,
+	// ordered by customer and delivery date
+	ListBillableDeliveries(ctx context.Context, customerID *int64, from, to time.Time) ([]entity.BillableDelivery, error)
+	// ListInvoiceSequences returns the sequence of the last invoice of each customer invoiced for
+	// the period starting on periodStart
+	ListInvoiceSequences(ctx context.Context, periodStart time.Time) (map[int64]int, error)
+
+	// Create inserts the invoice and its lines, returning ErrInvoiceExists if the customer
+	// already has an invoice with the same sequence for the period
+	Create(ctx context.Context, invoice *entity.SubscriptionInvoice) error
+	GetByID(ctx context.Context, id int64) (*entity.SubscriptionInvoice, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (*entity.SubscriptionInvoice, error)
+	List(ctx context.Context, filter SubscriptionInvoiceFilter, offset, limit int) ([]entity.SubscriptionInvoice, int64, error)
+	UpdatePayment(ctx context.Context, invoice *entity.SubscriptionInvoice) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/qwikshelf/api/internal/application/dto"
 	"github.com/qwikshelf/api/internal/domain/entity"
@@ -21,4 +22,12 @@ type SubscriptionRepository interface {
 	RecordDelivery(ctx context.Context, delivery *entity.SubscriptionDelivery) error
 	GetDeliveries(ctx context.Context, subscriptionID int64) ([]*entity.SubscriptionDelivery, error)
 	GetDailyRoster(ctx context.Context, date string) ([]*entity.DailyRosterItem, error)
+
+	// Pauses & date overrides
+	CreatePause(ctx context.Context, pause *entity.SubscriptionPause) error
+	ListPauses(ctx context.Context, subscriptionID int64) ([]*entity.SubscriptionPause, error)
+	DeletePause(ctx context.Context, subscriptionID, pauseID int64) error
+	SetOverride(ctx context.Context, override *entity.SubscriptionDateOverride) error
+	ListOverrides(ctx context.Context, subscriptionID int64, from time.Time) ([]*entity.SubscriptionDateOverride, error)
+	DeleteOverride(ctx context.Context, subscriptionID, overrideID int64) error
 }
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// SupplierBillFilter narrows a supplier bill listing
+type SupplierBillFilter struct {
+	SupplierID    *int64
+	ProcurementID *int64
+	Status        *entity.SupplierBillStatus
+}
+
+// SupplierPayablesRepository defines the interface for accounts payable data access
+type SupplierPayablesRepository interface {
+	// LockSupplier locks the supplier row so bills and payments for a supplier are posted one at a time
+	LockSupplier(ctx context.Context, supplierID int64) error
+
+	// ListUnbilledReceiptLines returns the accepted goods receipt lines of a purchase order not yet
+	// on a bill, priced at the order's unit cost
+	ListUnbilledReceiptLines(ctx context.Context, procurementID int64) ([]entity.SupplierBillLine, error)
+	// ListUnbilledCollections returns a supplier's collections dated within [from, to) not yet on a bill,
+	// priced at the collection's rate chart rate, else the agreed cost for the variant or its cost price
+	ListUnbilledCollections(ctx context.Context, supplierID int64, from, to time.Time) ([]entity.SupplierBillLine, error)
+
+	CreateBill(ctx context.Context, bill *entity.SupplierBill) error
+	GetBillByID(ctx context.Context, id int64) (*entity.SupplierBill, error)
+	GetBillByIDForUpdate(ctx context.Context, id int64) (*entity.SupplierBill, error)
+	ListBills(ctx context.Context, filter SupplierBillFilter, offset, limit int) ([]entity.SupplierBill, int64, error)
+	UpdateBillPayment(ctx context.Context, bill *entity.SupplierBill) error
+
+	CreatePayment(ctx context.Context, payment *entity.SupplierPayment) error
+	AddEntry(ctx context.Context, entry *entity.SupplierLedgerEntry) error
+
+	// GetBalance returns the amount owed to a supplier from entries dated before the given time
+	GetBalance(ctx context.Context, supplierID int64, before time.Time) (decimal.Decimal, error)
+	ListEntries(ctx context.Context, supplierID int64, from, to time.Time) ([]entity.SupplierLedgerEntry, error)
+	ListEntriesUpTo(ctx context.Context, supplierID *int64, asOf time.Time) ([]entity.SupplierLedgerEntry, error)
+	ListBalances(ctx context.Context, asOf time.Time) ([]entity.SupplierAging, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// SupplierPriceListRepository defines the interface for supplier price list data access
+type SupplierPriceListRepository interface {
+	// Create fails with ErrPriceListOverlap when the list overlaps another for the same variant
+	Create(ctx context.Context, list *entity.SupplierPriceList) error
+	GetByID(ctx context.Context, id int64) (*entity.SupplierPriceList, error)
+	// List returns a supplier's price lists, of one variant when variantID is set, latest first
+	List(ctx context.Context, supplierID int64, variantID *int64) ([]entity.SupplierPriceList, error)
+	// FindEffective returns the list in force for the supplier and variant on the date
+	FindEffective(ctx context.Context, supplierID, variantID int64, on time.Time) (*entity.SupplierPriceList, error)
+	SetValidTo(ctx context.Context, id int64, validTo time.Time) error
+
+	// LockSupplierVariant locks the supplier's link to the variant so its price lists can be changed
+	// without overlapping; it fails when the supplier does not supply the variant
+	LockSupplierVariant(ctx context.Context, supplierID, variantID int64) error
+	// SetAgreedCost keeps the supplier's agreed cost for the variant in step with the list in force
+	SetAgreedCost(ctx context.Context, supplierID, variantID int64, cost decimal.Decimal) error
+}
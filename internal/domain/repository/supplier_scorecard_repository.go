@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwikshelf/api/internal/domain/entity"
+)
+
+// SupplierScorecardRepository gathers the measures supplier scorecards are computed from
+type SupplierScorecardRepository interface {
+	// ListMeasures returns the raw measures for [from, to) of every supplier with any purchase order
+	// or collection activity in the period, or only of the given suppliers when supplierIDs is set
+	ListMeasures(ctx context.Context, supplierIDs []int64, from, to time.Time) ([]entity.SupplierScorecard, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// TxManager defines the interface for running several repository calls as a single unit of work.
+// Repositories invoked with the context passed to fn participate in the same transaction;
+// if fn returns an error every write made inside it is rolled back.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
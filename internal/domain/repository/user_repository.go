@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/qwikshelf/api/internal/domain/entity"
 )
 
@@ -53,6 +55,9 @@ type RoleRepository interface {
 	// Update updates an existing role
 	Update(ctx context.Context, role *entity.Role) error
 
+	// SetApprovalLimit sets the purchase order approval limit of a role; nil removes the limit
+	SetApprovalLimit(ctx context.Context, id int64, limit *decimal.Decimal) error
+
 	// Delete deletes a role by ID
 	Delete(ctx context.Context, id int64) error
 
@@ -27,8 +27,10 @@ type SupplierRepository interface {
 	Delete(ctx context.Context, id int64) error
 
 	// Supplier-Variant relationships
+	// AddVariant links a variant to a supplier; an existing link keeps its agreed cost
 	AddVariant(ctx context.Context, sv *entity.SupplierVariant) error
 	GetVariants(ctx context.Context, supplierID int64) ([]entity.SupplierVariant, error)
 	GetPreferredSupplierForVariant(ctx context.Context, variantID int64) (*entity.SupplierVariant, error)
+	ListSuppliersForVariant(ctx context.Context, variantID int64) ([]entity.SupplierVariant, error)
 	RemoveVariant(ctx context.Context, supplierID, variantID int64) error
 }
@@ -0,0 +1,66 @@
+-- +migrate Up
+-- Reshape the placeholder production tables from 001 to match the production run model:
+-- a run is a batch at a factory warehouse, and each log line converts an input variant into an output variant.
+DROP TABLE IF EXISTS production_logs;
+DROP TABLE IF EXISTS production_runs;
+
+CREATE TABLE production_runs (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    staff_id INTEGER NOT NULL REFERENCES users(id),
+    batch_code VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_production_runs_warehouse_id ON production_runs(warehouse_id);
+CREATE INDEX idx_production_runs_created_at ON production_runs(created_at);
+
+CREATE TABLE production_logs (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES production_runs(id) ON DELETE CASCADE,
+    input_variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    input_qty DECIMAL(12, 3) NOT NULL CHECK (input_qty > 0),
+    output_variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    output_qty DECIMAL(12, 3) NOT NULL CHECK (output_qty >= 0)
+);
+
+CREATE INDEX idx_production_logs_run_id ON production_logs(run_id);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('production.view', 'View production runs and yield reports'),
+    ('production.manage', 'Record production runs')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions
+WHERE slug IN ('production.view', 'production.manage')
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS production_logs;
+DROP TABLE IF EXISTS production_runs;
+
+CREATE TABLE production_runs (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    product_variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity_produced DECIMAL(12, 3) NOT NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    status VARCHAR(50) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'in_progress', 'completed', 'cancelled'))
+);
+
+CREATE INDEX idx_production_runs_warehouse_id ON production_runs(warehouse_id);
+CREATE INDEX idx_production_runs_variant_id ON production_runs(product_variant_id);
+CREATE INDEX idx_production_runs_status ON production_runs(status);
+
+CREATE TABLE production_logs (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES production_runs(id) ON DELETE CASCADE,
+    event VARCHAR(100) NOT NULL,
+    details TEXT,
+    logged_by_user_id INTEGER NOT NULL REFERENCES users(id),
+    logged_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_production_logs_run_id ON production_logs(run_id);
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- Bill of materials for manufactured variants
+CREATE TABLE recipes (
+    id SERIAL PRIMARY KEY,
+    output_variant_id INTEGER NOT NULL UNIQUE REFERENCES product_variants(id) ON DELETE CASCADE,
+    output_qty DECIMAL(12, 3) NOT NULL CHECK (output_qty > 0),
+    expected_yield DECIMAL(6, 2) NOT NULL CHECK (expected_yield >= 0),
+    yield_tolerance DECIMAL(6, 2) NOT NULL DEFAULT 0 CHECK (yield_tolerance >= 0),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE recipe_items (
+    id SERIAL PRIMARY KEY,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    input_variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    UNIQUE (recipe_id, input_variant_id)
+);
+
+CREATE INDEX idx_recipe_items_recipe_id ON recipe_items(recipe_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS recipe_items;
+DROP TABLE IF EXISTS recipes;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- Order lifecycle for sales. Existing sales were fulfilled at the counter, so they start as delivered.
+ALTER TABLE sales ADD COLUMN status VARCHAR(30) NOT NULL DEFAULT 'delivered'
+    CHECK (status IN ('placed', 'confirmed', 'packed', 'out_for_delivery', 'delivered', 'cancelled', 'returned'));
+
+CREATE INDEX idx_sales_status ON sales(status);
+
+CREATE TABLE order_status_history (
+    id SERIAL PRIMARY KEY,
+    sale_id INTEGER NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    status VARCHAR(30) NOT NULL,
+    note TEXT,
+    changed_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_status_history_sale_id ON order_status_history(sale_id);
+
+-- Seed the timeline for existing sales
+INSERT INTO order_status_history (sale_id, status, changed_by_user_id, changed_at)
+SELECT id, status, processed_by_user_id, created_at FROM sales;
+
+-- +migrate Down
+DROP TABLE IF EXISTS order_status_history;
+DROP INDEX IF EXISTS idx_sales_status;
+ALTER TABLE sales DROP COLUMN IF EXISTS status;
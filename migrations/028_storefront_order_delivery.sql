@@ -0,0 +1,31 @@
+-- +migrate Up
+-- Storefront orders are routed to the warehouse of the delivery zone serving their pincode
+-- and are not processed by a staff member at the counter.
+ALTER TABLE sales ALTER COLUMN processed_by_user_id DROP NOT NULL;
+ALTER TABLE sales ADD COLUMN delivery_charge DECIMAL(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN zone_id INTEGER REFERENCES delivery_zones(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_sales_zone_id ON sales(zone_id);
+
+-- Structured delivery address captured with each storefront order
+CREATE TABLE sale_delivery_addresses (
+    sale_id INTEGER PRIMARY KEY REFERENCES sales(id) ON DELETE CASCADE,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    contact_name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255),
+    landmark VARCHAR(255),
+    city VARCHAR(100),
+    pincode VARCHAR(10) NOT NULL
+);
+
+CREATE INDEX idx_sale_delivery_addresses_customer_id ON sale_delivery_addresses(customer_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS sale_delivery_addresses;
+DROP INDEX IF EXISTS idx_sales_zone_id;
+ALTER TABLE sales DROP COLUMN IF EXISTS zone_id;
+ALTER TABLE sales DROP COLUMN IF EXISTS delivery_charge;
+UPDATE sales SET processed_by_user_id = 1 WHERE processed_by_user_id IS NULL;
+ALTER TABLE sales ALTER COLUMN processed_by_user_id SET NOT NULL;
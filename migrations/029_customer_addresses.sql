@@ -0,0 +1,32 @@
+-- +migrate Up
+-- Customer address book. Each address is geocoded and assigned to the delivery zone containing it.
+CREATE TABLE customer_addresses (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    label VARCHAR(20) NOT NULL DEFAULT 'home' CHECK (label IN ('home', 'work', 'other')),
+    contact_name VARCHAR(255),
+    phone VARCHAR(20),
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255),
+    landmark VARCHAR(255),
+    city VARCHAR(100),
+    pincode VARCHAR(10) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    zone_id INTEGER REFERENCES delivery_zones(id) ON DELETE SET NULL,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_addresses_customer_id ON customer_addresses(customer_id);
+CREATE UNIQUE INDEX idx_customer_addresses_default ON customer_addresses(customer_id) WHERE is_default;
+
+-- Orders and subscriptions deliver to a saved address
+ALTER TABLE sale_delivery_addresses ADD COLUMN customer_address_id INTEGER REFERENCES customer_addresses(id) ON DELETE SET NULL;
+ALTER TABLE customer_subscriptions ADD COLUMN address_id INTEGER REFERENCES customer_addresses(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE customer_subscriptions DROP COLUMN IF EXISTS address_id;
+ALTER TABLE sale_delivery_addresses DROP COLUMN IF EXISTS customer_address_id;
+DROP TABLE IF EXISTS customer_addresses;
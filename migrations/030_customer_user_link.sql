@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Storefront accounts (users with the customer role) are linked to their CRM customer record.
+-- Existing accounts get their record on first use; staff merge it with any customer sharing the phone.
+ALTER TABLE customers ADD COLUMN user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE customers DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,46 @@
+-- +migrate Up
+-- Accounts receivable: credit sales post debits and customer payments post credits.
+CREATE TABLE customer_payments (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    payment_method VARCHAR(30) NOT NULL CHECK (payment_method IN ('cash', 'card', 'upi', 'bank_transfer', 'cheque', 'other')),
+    reference VARCHAR(100),
+    notes TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    recorded_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_payments_customer_id ON customer_payments(customer_id);
+
+CREATE TABLE customer_ledger_entries (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('sale', 'payment', 'reversal', 'adjustment')),
+    sale_id INTEGER REFERENCES sales(id) ON DELETE SET NULL,
+    payment_id INTEGER REFERENCES customer_payments(id) ON DELETE SET NULL,
+    debit DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    description TEXT,
+    entry_date TIMESTAMP NOT NULL DEFAULT NOW(),
+    due_date DATE,
+    created_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_ledger_customer_date ON customer_ledger_entries(customer_id, entry_date);
+CREATE INDEX idx_customer_ledger_sale_id ON customer_ledger_entries(sale_id);
+
+-- Post existing open credit sales to the ledger
+INSERT INTO customer_ledger_entries (customer_id, entry_type, sale_id, debit, description, entry_date, due_date, created_by_user_id)
+SELECT s.customer_id, 'sale', s.id, s.total_amount, 'Sale #' || s.id, s.created_at,
+       CASE WHEN c.payment_terms = 'net_15' THEN (s.created_at + INTERVAL '15 days')::date ELSE s.created_at::date END,
+       s.processed_by_user_id
+FROM sales s
+JOIN customers c ON c.id = s.customer_id
+WHERE s.payment_method = 'credit' AND s.status NOT IN ('cancelled', 'returned');
+
+-- +migrate Down
+DROP TABLE IF EXISTS customer_ledger_entries;
+DROP TABLE IF EXISTS customer_payments;
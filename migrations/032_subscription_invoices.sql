@@ -0,0 +1,58 @@
+-- +migrate Up
+-- Monthly billing of subscription deliveries.
+-- A subscription item may lock in a unit price; otherwise the variant's selling price is billed.
+ALTER TABLE subscription_items ADD COLUMN unit_price DECIMAL(12, 2) CHECK (unit_price >= 0);
+
+CREATE TABLE subscription_invoices (
+    id SERIAL PRIMARY KEY,
+    invoice_number VARCHAR(30) NOT NULL UNIQUE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    amount_paid DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'outstanding' CHECK (status IN ('outstanding', 'paid')),
+    due_date DATE,
+    generated_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    -- One invoice per customer per billing period keeps billing runs idempotent
+    UNIQUE (customer_id, period_start),
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX idx_subscription_invoices_status ON subscription_invoices(status);
+
+-- One line per delivered product per day
+CREATE TABLE subscription_invoice_lines (
+    id SERIAL PRIMARY KEY,
+    invoice_id INTEGER NOT NULL REFERENCES subscription_invoices(id) ON DELETE CASCADE,
+    subscription_id INTEGER REFERENCES customer_subscriptions(id) ON DELETE SET NULL,
+    delivery_id INTEGER REFERENCES subscription_deliveries(id) ON DELETE SET NULL,
+    delivery_date DATE NOT NULL,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity DECIMAL(10, 3) NOT NULL,
+    unit_price DECIMAL(12, 2) NOT NULL,
+    line_total DECIMAL(12, 2) NOT NULL,
+    UNIQUE (delivery_id, variant_id)
+);
+
+CREATE INDEX idx_subscription_invoice_lines_invoice_id ON subscription_invoice_lines(invoice_id);
+
+-- Invoices are charged to the customer's account until paid
+ALTER TABLE customer_payments ADD COLUMN invoice_id INTEGER REFERENCES subscription_invoices(id) ON DELETE SET NULL;
+ALTER TABLE customer_ledger_entries ADD COLUMN invoice_id INTEGER REFERENCES subscription_invoices(id) ON DELETE SET NULL;
+ALTER TABLE customer_ledger_entries DROP CONSTRAINT customer_ledger_entries_entry_type_check;
+ALTER TABLE customer_ledger_entries ADD CONSTRAINT customer_ledger_entries_entry_type_check
+    CHECK (entry_type IN ('sale', 'invoice', 'payment', 'reversal', 'adjustment'));
+
+-- +migrate Down
+DELETE FROM customer_ledger_entries WHERE entry_type = 'invoice';
+ALTER TABLE customer_ledger_entries DROP CONSTRAINT customer_ledger_entries_entry_type_check;
+ALTER TABLE customer_ledger_entries ADD CONSTRAINT customer_ledger_entries_entry_type_check
+    CHECK (entry_type IN ('sale', 'payment', 'reversal', 'adjustment'));
+ALTER TABLE customer_ledger_entries DROP COLUMN IF EXISTS invoice_id;
+ALTER TABLE customer_payments DROP COLUMN IF EXISTS invoice_id;
+DROP TABLE IF EXISTS subscription_invoice_lines;
+DROP TABLE IF EXISTS subscription_invoices;
+ALTER TABLE subscription_items DROP COLUMN IF EXISTS unit_price;
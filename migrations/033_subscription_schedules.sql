@@ -0,0 +1,40 @@
+-- +migrate Up
+-- Delivery-day schedules: weekly plans deliver on chosen weekdays (0 = Sunday … 6 = Saturday),
+-- monthly plans on a chosen day of the month. Without them the start date's weekday/day is used.
+ALTER TABLE customer_subscriptions ADD COLUMN delivery_weekdays SMALLINT[];
+ALTER TABLE customer_subscriptions ADD COLUMN delivery_day_of_month SMALLINT CHECK (delivery_day_of_month BETWEEN 1 AND 31);
+
+-- Dated pause windows (vacation holds); no deliveries are due between start_date and end_date inclusive
+CREATE TABLE subscription_pauses (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES customer_subscriptions(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_dates ON subscription_pauses(subscription_id, start_date, end_date);
+
+-- One-off quantity for a subscription item on a single date; 0 skips the item that day
+CREATE TABLE subscription_date_overrides (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES customer_subscriptions(id) ON DELETE CASCADE,
+    delivery_date DATE NOT NULL,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity DECIMAL(10, 3) NOT NULL CHECK (quantity >= 0),
+    notes TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, delivery_date, variant_id)
+);
+
+CREATE INDEX idx_subscription_date_overrides_date ON subscription_date_overrides(delivery_date);
+
+-- +migrate Down
+DROP TABLE IF EXISTS subscription_date_overrides;
+DROP TABLE IF EXISTS subscription_pauses;
+ALTER TABLE customer_subscriptions DROP COLUMN IF EXISTS delivery_day_of_month;
+ALTER TABLE customer_subscriptions DROP COLUMN IF EXISTS delivery_weekdays;
@@ -0,0 +1,44 @@
+-- +migrate Up
+-- Append-only stock ledger: every change to inventory_levels is recorded with its source and the balance after it
+CREATE TABLE inventory_movements (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    source_type VARCHAR(30) NOT NULL CHECK (source_type IN (
+        'opening', 'adjustment', 'sale', 'sale_cancellation', 'collection', 'procurement',
+        'transfer_out', 'transfer_in', 'production_input', 'production_output'
+    )),
+    reference_id BIGINT,
+    user_id INTEGER REFERENCES users(id),
+    quantity_delta DECIMAL(12, 3) NOT NULL,
+    balance_after DECIMAL(12, 3) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inventory_movements_stock_card ON inventory_movements(warehouse_id, variant_id, created_at, id);
+CREATE INDEX idx_inventory_movements_source ON inventory_movements(source_type, reference_id);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION reject_inventory_movement_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'inventory_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_inventory_movements_append_only
+BEFORE UPDATE OR DELETE ON inventory_movements
+FOR EACH ROW EXECUTE FUNCTION reject_inventory_movement_change();
+-- +migrate StatementEnd
+
+-- Existing stock becomes the opening balance of the ledger
+INSERT INTO inventory_movements (warehouse_id, variant_id, source_type, quantity_delta, balance_after, notes)
+SELECT warehouse_id, variant_id, 'opening', quantity, quantity, 'Opening balance'
+FROM inventory_levels
+WHERE quantity <> 0;
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS trg_inventory_movements_append_only ON inventory_movements;
+DROP FUNCTION IF EXISTS reject_inventory_movement_change();
+DROP TABLE IF EXISTS inventory_movements;
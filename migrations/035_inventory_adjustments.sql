@@ -0,0 +1,38 @@
+-- +migrate Up
+-- Manual stock adjustments carry a reason code. Adjustments worth more than the configured
+-- threshold stay pending until a second user with inventory.approve approves them.
+CREATE TABLE inventory_adjustments (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    reason_code VARCHAR(30) NOT NULL CHECK (reason_code IN ('damage', 'spoilage', 'theft', 'count_correction', 'sample')),
+    quantity_delta DECIMAL(12, 3) NOT NULL CHECK (quantity_delta <> 0),
+    unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    value DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    requested_by_user_id INTEGER REFERENCES users(id),
+    reviewed_by_user_id INTEGER REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    review_note TEXT,
+    movement_id BIGINT REFERENCES inventory_movements(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inventory_adjustments_status ON inventory_adjustments(status);
+CREATE INDEX idx_inventory_adjustments_warehouse_created ON inventory_adjustments(warehouse_id, created_at);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('inventory.approve', 'Approve high-value manual inventory adjustments')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions WHERE slug = 'inventory.approve'
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission_id IN (
+    SELECT id FROM permissions WHERE slug = 'inventory.approve'
+);
+DELETE FROM permissions WHERE slug = 'inventory.approve';
+DROP TABLE IF EXISTS inventory_adjustments;
@@ -0,0 +1,48 @@
+-- +migrate Up
+-- Physical stock-take sessions. Opening a session snapshots the expected quantity of every
+-- variant in scope; counted quantities are compared against that snapshot, so stock that moves
+-- while counting is still in progress does not show up as variance.
+CREATE TABLE stock_takes (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    scope VARCHAR(10) NOT NULL DEFAULT 'full' CHECK (scope IN ('full', 'cycle')),
+    abc_class CHAR(1) CHECK (abc_class IN ('A', 'B', 'C')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'posted', 'cancelled')),
+    notes TEXT,
+    started_by_user_id INTEGER REFERENCES users(id),
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_by_user_id INTEGER REFERENCES users(id),
+    closed_at TIMESTAMP,
+    CHECK (scope = 'full' OR abc_class IS NOT NULL)
+);
+
+-- Only one open session per warehouse at a time
+CREATE UNIQUE INDEX idx_stock_takes_open_warehouse ON stock_takes(warehouse_id) WHERE status = 'open';
+
+CREATE TABLE stock_take_lines (
+    id SERIAL PRIMARY KEY,
+    stock_take_id INTEGER NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    expected_quantity DECIMAL(12, 3) NOT NULL DEFAULT 0,
+    counted_quantity DECIMAL(12, 3) CHECK (counted_quantity >= 0),
+    counted_by_user_id INTEGER REFERENCES users(id),
+    counted_at TIMESTAMP,
+    adjustment_id INTEGER REFERENCES inventory_adjustments(id),
+    UNIQUE (stock_take_id, variant_id)
+);
+
+CREATE INDEX idx_stock_take_lines_variant ON stock_take_lines(variant_id);
+
+-- How often each ABC class is cycle counted at a warehouse
+CREATE TABLE cycle_count_schedules (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    abc_class CHAR(1) NOT NULL CHECK (abc_class IN ('A', 'B', 'C')),
+    interval_days INTEGER NOT NULL CHECK (interval_days > 0),
+    UNIQUE (warehouse_id, abc_class)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS cycle_count_schedules;
+DROP TABLE IF EXISTS stock_take_lines;
+DROP TABLE IF EXISTS stock_takes;
@@ -0,0 +1,46 @@
+-- +migrate Up
+-- Lot-level stock. inventory_levels keeps the total per warehouse and variant; each lot holds the
+-- part of it received under one batch number. Stock moved before lots existed, or added without
+-- one (e.g. a count correction surplus), stays untracked: the level minus the sum of its lots.
+CREATE TABLE inventory_lots (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    batch_number VARCHAR(100) NOT NULL,
+    expiry_date DATE,
+    quantity DECIMAL(12, 3) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    supplier_id INTEGER REFERENCES suppliers(id),
+    source_type VARCHAR(30) NOT NULL,
+    reference_id BIGINT,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (warehouse_id, variant_id, batch_number)
+);
+
+CREATE INDEX idx_inventory_lots_stock ON inventory_lots(warehouse_id, variant_id) WHERE quantity > 0;
+CREATE INDEX idx_inventory_lots_expiry ON inventory_lots(expiry_date) WHERE expiry_date IS NOT NULL AND quantity > 0;
+CREATE INDEX idx_inventory_lots_batch ON inventory_lots(batch_number);
+
+-- Ledger entries record the lot they moved, so a batch can be traced for recalls
+ALTER TABLE inventory_movements ADD COLUMN lot_id INTEGER REFERENCES inventory_lots(id);
+CREATE INDEX idx_inventory_movements_lot ON inventory_movements(lot_id) WHERE lot_id IS NOT NULL;
+
+-- Batch details captured when purchase order items are received
+ALTER TABLE procurement_items ADD COLUMN batch_number VARCHAR(100);
+ALTER TABLE procurement_items ADD COLUMN expiry_date DATE;
+
+-- Expiry date of the lots a production run outputs; the lots are named after its batch code
+ALTER TABLE production_runs ADD COLUMN expiry_date DATE;
+
+-- Existing stock that carries batch details becomes an opening lot
+INSERT INTO inventory_lots (warehouse_id, variant_id, batch_number, expiry_date, quantity, source_type, received_at)
+SELECT warehouse_id, variant_id, batch_number, expiry_date, quantity, 'opening', NOW()
+FROM inventory_levels
+WHERE batch_number IS NOT NULL AND quantity > 0;
+
+-- +migrate Down
+ALTER TABLE production_runs DROP COLUMN IF EXISTS expiry_date;
+ALTER TABLE procurement_items DROP COLUMN IF EXISTS expiry_date;
+ALTER TABLE procurement_items DROP COLUMN IF EXISTS batch_number;
+DROP INDEX IF EXISTS idx_inventory_movements_lot;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS lot_id;
+DROP TABLE IF EXISTS inventory_lots;
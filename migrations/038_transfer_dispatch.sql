@@ -0,0 +1,66 @@
+-- +migrate Up
+-- Transfers move in two phases: dispatch takes the stock out of the source warehouse and it is
+-- in transit until the destination receives it, recording any shortage or damage on the way.
+-- A receiving store can also request stock, which the source then dispatches or turns down.
+ALTER TABLE inventory_transfers DROP CONSTRAINT inventory_transfers_status_check;
+ALTER TABLE inventory_transfers ADD CONSTRAINT inventory_transfers_status_check
+    CHECK (status IN ('requested', 'pending', 'in_transit', 'completed', 'cancelled'));
+
+ALTER TABLE inventory_transfers ADD COLUMN notes TEXT;
+ALTER TABLE inventory_transfers ADD COLUMN requested_by_user_id INTEGER REFERENCES users(id);
+ALTER TABLE inventory_transfers ADD COLUMN dispatched_by_user_id INTEGER REFERENCES users(id);
+ALTER TABLE inventory_transfers ADD COLUMN dispatched_at TIMESTAMP;
+ALTER TABLE inventory_transfers ADD COLUMN received_by_user_id INTEGER REFERENCES users(id);
+ALTER TABLE inventory_transfers ADD COLUMN received_at TIMESTAMP;
+ALTER TABLE inventory_transfers ADD COLUMN cancelled_by_user_id INTEGER REFERENCES users(id);
+ALTER TABLE inventory_transfers ADD COLUMN cancelled_at TIMESTAMP;
+
+ALTER TABLE inventory_transfer_items ADD COLUMN dispatched_quantity DECIMAL(12, 3) CHECK (dispatched_quantity >= 0);
+ALTER TABLE inventory_transfer_items ADD COLUMN received_quantity DECIMAL(12, 3) CHECK (received_quantity >= 0);
+ALTER TABLE inventory_transfer_items ADD COLUMN damaged_quantity DECIMAL(12, 3) CHECK (damaged_quantity >= 0);
+ALTER TABLE inventory_transfer_items ADD COLUMN notes TEXT;
+
+-- Transfers made before this moved stock in one step
+UPDATE inventory_transfers
+SET dispatched_by_user_id = authorized_by_user_id, dispatched_at = transferred_at,
+    received_by_user_id = authorized_by_user_id, received_at = transferred_at
+WHERE status = 'completed';
+
+UPDATE inventory_transfer_items i
+SET dispatched_quantity = i.quantity, received_quantity = i.quantity, damaged_quantity = 0
+FROM inventory_transfers t
+WHERE t.id = i.transfer_id AND t.status = 'completed';
+
+-- Stock returned to the source when an in-transit transfer is cancelled
+ALTER TABLE inventory_movements DROP CONSTRAINT inventory_movements_source_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_source_type_check CHECK (source_type IN (
+    'opening', 'adjustment', 'sale', 'sale_cancellation', 'collection', 'procurement',
+    'transfer_out', 'transfer_in', 'transfer_cancellation', 'production_input', 'production_output'
+));
+
+-- +migrate Down
+-- The ledger is append-only, so cancellation entries stay and the old check is not re-validated
+ALTER TABLE inventory_movements DROP CONSTRAINT inventory_movements_source_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_source_type_check CHECK (source_type IN (
+    'opening', 'adjustment', 'sale', 'sale_cancellation', 'collection', 'procurement',
+    'transfer_out', 'transfer_in', 'production_input', 'production_output'
+)) NOT VALID;
+
+ALTER TABLE inventory_transfer_items DROP COLUMN IF EXISTS notes;
+ALTER TABLE inventory_transfer_items DROP COLUMN IF EXISTS damaged_quantity;
+ALTER TABLE inventory_transfer_items DROP COLUMN IF EXISTS received_quantity;
+ALTER TABLE inventory_transfer_items DROP COLUMN IF EXISTS dispatched_quantity;
+
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS cancelled_by_user_id;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS received_at;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS received_by_user_id;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS dispatched_at;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS dispatched_by_user_id;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS requested_by_user_id;
+ALTER TABLE inventory_transfers DROP COLUMN IF EXISTS notes;
+
+UPDATE inventory_transfers SET status = 'cancelled' WHERE status = 'requested';
+ALTER TABLE inventory_transfers DROP CONSTRAINT inventory_transfers_status_check;
+ALTER TABLE inventory_transfers ADD CONSTRAINT inventory_transfers_status_check
+    CHECK (status IN ('pending', 'in_transit', 'completed', 'cancelled'));
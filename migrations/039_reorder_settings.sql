@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Stock control settings per warehouse and variant. When the projected stock (on hand, plus open
+-- purchase orders and inbound transfers) falls to the reorder point, the replenishment engine
+-- suggests ordering up to the maximum, plus what is expected to sell during the lead time.
+CREATE TABLE reorder_settings (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    min_quantity DECIMAL(12, 3) NOT NULL DEFAULT 0 CHECK (min_quantity >= 0),
+    reorder_point DECIMAL(12, 3) NOT NULL CHECK (reorder_point >= min_quantity),
+    max_quantity DECIMAL(12, 3) NOT NULL CHECK (max_quantity > reorder_point),
+    lead_time_days INTEGER NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (warehouse_id, variant_id)
+);
+
+CREATE INDEX idx_reorder_settings_variant ON reorder_settings(variant_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS reorder_settings;
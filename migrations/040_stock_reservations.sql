@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Stock held for demand that has been promised but not yet picked: storefront orders until they
+-- are packed and the next day's subscription roster until it is delivered. Stock available to
+-- sell is the stock on hand, less expired lots, less active reservations. A reservation lapses
+-- at expires_at even before the sweep marks it expired.
+CREATE TABLE stock_reservations (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('order', 'subscription')),
+    reference_id BIGINT NOT NULL,
+    reserved_for DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'fulfilled', 'released', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_stock_reservations_active ON stock_reservations(warehouse_id, variant_id) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_reference ON stock_reservations(source_type, reference_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS stock_reservations;
@@ -0,0 +1,50 @@
+-- +migrate Up
+-- Inventory is valued at what it actually cost. Every receipt opens a cost layer at its unit cost
+-- (a purchase order's unit cost, the cost of a production run's inputs, the cost the stock left
+-- another warehouse at...). Issues consume the layers oldest first and are costed under the
+-- configured method: FIFO takes the cost of the layers consumed, moving average the average cost
+-- of the stock at the time. inventory_levels keeps the current stock value and each ledger entry
+-- records the value after it, so stock can be valued as of any past date.
+CREATE TABLE inventory_cost_layers (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    movement_id BIGINT REFERENCES inventory_movements(id),
+    source_type VARCHAR(30) NOT NULL,
+    unit_cost DECIMAL(14, 4) NOT NULL CHECK (unit_cost >= 0),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    remaining_quantity DECIMAL(12, 3) NOT NULL CHECK (remaining_quantity >= 0 AND remaining_quantity <= quantity),
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inventory_cost_layers_open ON inventory_cost_layers(warehouse_id, variant_id, received_at, id) WHERE remaining_quantity > 0;
+
+ALTER TABLE inventory_levels ADD COLUMN stock_value DECIMAL(16, 4) NOT NULL DEFAULT 0;
+
+-- Entries recorded before costing have no cost; they are valued at the variant's cost price
+ALTER TABLE inventory_movements ADD COLUMN unit_cost DECIMAL(14, 4);
+ALTER TABLE inventory_movements ADD COLUMN total_cost DECIMAL(16, 4);
+ALTER TABLE inventory_movements ADD COLUMN value_after DECIMAL(16, 4);
+
+-- Cost of the stock issued for a sale item, known once it has left the shelf
+ALTER TABLE sale_items ADD COLUMN cost_of_goods DECIMAL(16, 4);
+
+-- Existing stock opens at the variant's cost price
+UPDATE inventory_levels il
+SET stock_value = il.quantity * pv.cost_price
+FROM product_variants pv
+WHERE pv.id = il.variant_id AND il.quantity > 0;
+
+INSERT INTO inventory_cost_layers (warehouse_id, variant_id, source_type, unit_cost, quantity, remaining_quantity)
+SELECT il.warehouse_id, il.variant_id, 'opening', pv.cost_price, il.quantity, il.quantity
+FROM inventory_levels il
+JOIN product_variants pv ON pv.id = il.variant_id
+WHERE il.quantity > 0;
+
+-- +migrate Down
+ALTER TABLE sale_items DROP COLUMN IF EXISTS cost_of_goods;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS value_after;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS total_cost;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS unit_cost;
+ALTER TABLE inventory_levels DROP COLUMN IF EXISTS stock_value;
+DROP TABLE IF EXISTS inventory_cost_layers;
@@ -0,0 +1,36 @@
+-- +migrate Up
+-- Purchase orders move pending -> approved -> ordered -> partial/received and can only be
+-- cancelled before anything is received. Approval needs procurement.approve and is capped per role
+-- by the order total; a role without a ceiling may approve any amount. Every status change is
+-- recorded with the order total at the time.
+ALTER TABLE roles ADD COLUMN po_approval_limit DECIMAL(14, 2) CHECK (po_approval_limit >= 0);
+
+CREATE TABLE procurement_status_history (
+    id SERIAL PRIMARY KEY,
+    procurement_id INTEGER NOT NULL REFERENCES procurements(id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    changed_by_user_id INTEGER REFERENCES users(id),
+    role_id INTEGER REFERENCES roles(id),
+    note TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_procurement_status_history_procurement ON procurement_status_history(procurement_id, changed_at);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('procurement.approve', 'Approve purchase orders up to the role''s approval limit')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions WHERE slug = 'procurement.approve'
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission_id IN (
+    SELECT id FROM permissions WHERE slug = 'procurement.approve'
+);
+DELETE FROM permissions WHERE slug = 'procurement.approve';
+DROP TABLE IF EXISTS procurement_status_history;
+ALTER TABLE roles DROP COLUMN IF EXISTS po_approval_limit;
@@ -0,0 +1,148 @@
+-- +migrate Up
+-- Each delivery against a purchase order is recorded as a goods receipt note (GRN). Every line
+-- splits the delivered quantity into what was accepted into stock and what was rejected (with
+-- the reason), and carries the batch and expiry it arrived with. Accepted quantities are booked
+-- into stock when the receipt is recorded; procurement_items keeps the running totals, from which
+-- the order moves to partial or received on its own.
+CREATE TABLE goods_receipts (
+    id SERIAL PRIMARY KEY,
+    procurement_id INTEGER NOT NULL REFERENCES procurements(id),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    received_by_user_id INTEGER REFERENCES users(id),
+    delivery_reference VARCHAR(100),
+    notes TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_goods_receipts_procurement ON goods_receipts(procurement_id, received_at);
+
+CREATE TABLE goods_receipt_items (
+    id SERIAL PRIMARY KEY,
+    receipt_id INTEGER NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    procurement_item_id INTEGER NOT NULL REFERENCES procurement_items(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    quantity_accepted DECIMAL(12, 3) NOT NULL DEFAULT 0 CHECK (quantity_accepted >= 0),
+    quantity_rejected DECIMAL(12, 3) NOT NULL DEFAULT 0 CHECK (quantity_rejected >= 0),
+    rejection_reason TEXT,
+    batch_number VARCHAR(100),
+    expiry_date DATE,
+    lot_id BIGINT REFERENCES inventory_lots(id),
+    CHECK (quantity_accepted + quantity_rejected > 0),
+    CHECK (quantity_rejected = 0 OR rejection_reason IS NOT NULL)
+);
+
+CREATE INDEX idx_goods_receipt_items_receipt ON goods_receipt_items(receipt_id);
+CREATE INDEX idx_goods_receipt_items_procurement_item ON goods_receipt_items(procurement_item_id);
+
+ALTER TABLE procurement_items ADD COLUMN quantity_rejected DECIMAL(12, 3) NOT NULL DEFAULT 0;
+
+UPDATE procurement_items SET quantity_received = 0 WHERE quantity_received IS NULL;
+
+-- Quantities already received against open orders are carried over as one receipt note per order,
+-- accepted in full, so they keep counting towards the order and its over-receipt tolerance.
+INSERT INTO goods_receipts (procurement_id, warehouse_id, notes, received_at)
+SELECT p.id, p.warehouse_id, 'Received before goods receipt notes', NOW()
+FROM procurements p
+WHERE p.status IN ('pending', 'approved', 'ordered', 'partial')
+  AND EXISTS (SELECT 1 FROM procurement_items pi WHERE pi.procurement_id = p.id AND pi.quantity_received > 0);
+
+INSERT INTO goods_receipt_items (receipt_id, procurement_item_id, variant_id, quantity_accepted, batch_number, expiry_date)
+SELECT gr.id, pi.id, pi.variant_id, pi.quantity_received, pi.batch_number, pi.expiry_date
+FROM goods_receipts gr
+JOIN procurement_items pi ON pi.procurement_id = gr.procurement_id
+WHERE pi.quantity_received > 0;
+
+-- Stock used to be booked only when an order was marked received, so those quantities never
+-- reached stock. They are booked now the way a receipt books them: into a lot of their batch (or
+-- one named after the receipt line), as a procurement movement at the line's unit cost that opens
+-- a cost layer.
+INSERT INTO inventory_lots (warehouse_id, variant_id, batch_number, expiry_date, quantity, supplier_id, source_type, reference_id, received_at)
+SELECT gr.warehouse_id, gri.variant_id,
+       COALESCE(NULLIF(gri.batch_number, ''), 'GRN' || gr.id || '-' || gri.procurement_item_id),
+       gri.expiry_date, 0, p.supplier_id, 'procurement', p.id, gr.received_at
+FROM goods_receipt_items gri
+JOIN goods_receipts gr ON gr.id = gri.receipt_id
+JOIN procurements p ON p.id = gr.procurement_id
+ON CONFLICT (warehouse_id, variant_id, batch_number) DO NOTHING;
+
+UPDATE goods_receipt_items gri
+SET lot_id = l.id
+FROM goods_receipts gr, inventory_lots l
+WHERE gr.id = gri.receipt_id
+  AND l.warehouse_id = gr.warehouse_id AND l.variant_id = gri.variant_id
+  AND l.batch_number = COALESCE(NULLIF(gri.batch_number, ''), 'GRN' || gr.id || '-' || gri.procurement_item_id);
+
+INSERT INTO inventory_movements (
+    warehouse_id, variant_id, lot_id, source_type, reference_id, quantity_delta, balance_after,
+    unit_cost, total_cost, value_after, notes, created_at
+)
+SELECT warehouse_id, variant_id, lot_id, 'procurement', procurement_id, quantity, balance_after,
+       unit_cost, total_cost, CASE WHEN balance_after > 0 THEN GREATEST(value_after, 0) ELSE 0 END,
+       'Received before goods receipt notes', received_at
+FROM (
+    SELECT gr.warehouse_id, gri.variant_id, gri.lot_id, gr.procurement_id, gr.received_at,
+           gri.quantity_accepted AS quantity, pi.unit_cost,
+           ROUND(gri.quantity_accepted * pi.unit_cost, 4) AS total_cost,
+           COALESCE(il.quantity, 0) + SUM(gri.quantity_accepted) OVER w AS balance_after,
+           COALESCE(il.stock_value, 0) + SUM(ROUND(gri.quantity_accepted * pi.unit_cost, 4)) OVER w AS value_after
+    FROM goods_receipt_items gri
+    JOIN goods_receipts gr ON gr.id = gri.receipt_id
+    JOIN procurement_items pi ON pi.id = gri.procurement_item_id
+    LEFT JOIN inventory_levels il ON il.warehouse_id = gr.warehouse_id AND il.variant_id = gri.variant_id
+    WINDOW w AS (PARTITION BY gr.warehouse_id, gri.variant_id ORDER BY gri.id)
+) carried;
+
+INSERT INTO inventory_cost_layers (warehouse_id, variant_id, movement_id, source_type, unit_cost, quantity, remaining_quantity, received_at)
+SELECT warehouse_id, variant_id, id, source_type, unit_cost, quantity_delta, quantity_delta, created_at
+FROM inventory_movements
+WHERE source_type = 'procurement' AND notes = 'Received before goods receipt notes';
+
+UPDATE inventory_lots l
+SET quantity = l.quantity + carried.quantity
+FROM (
+    SELECT lot_id, SUM(quantity_accepted) AS quantity
+    FROM goods_receipt_items
+    GROUP BY lot_id
+) carried
+WHERE l.id = carried.lot_id;
+
+INSERT INTO inventory_levels (warehouse_id, variant_id, quantity, stock_value)
+SELECT gr.warehouse_id, gri.variant_id, SUM(gri.quantity_accepted), SUM(ROUND(gri.quantity_accepted * pi.unit_cost, 4))
+FROM goods_receipt_items gri
+JOIN goods_receipts gr ON gr.id = gri.receipt_id
+JOIN procurement_items pi ON pi.id = gri.procurement_item_id
+GROUP BY gr.warehouse_id, gri.variant_id
+ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET
+    quantity = inventory_levels.quantity + EXCLUDED.quantity,
+    stock_value = CASE WHEN inventory_levels.quantity + EXCLUDED.quantity > 0
+                       THEN GREATEST(inventory_levels.stock_value + EXCLUDED.stock_value, 0) ELSE 0 END;
+
+-- The orders then stand where their receipts put them: received once every line is in, partial otherwise
+WITH carried AS (
+    SELECT p.id, p.status AS from_status,
+           CASE WHEN bool_and(pi.quantity_received >= pi.quantity_ordered) THEN 'received' ELSE 'partial' END AS to_status,
+           SUM(pi.quantity_ordered * pi.unit_cost) AS amount,
+           gr.id AS receipt_id
+    FROM procurements p
+    JOIN goods_receipts gr ON gr.procurement_id = p.id
+    JOIN procurement_items pi ON pi.procurement_id = p.id
+    GROUP BY p.id, p.status, gr.id
+), moved AS (
+    UPDATE procurements p
+    SET status = carried.to_status
+    FROM carried
+    WHERE p.id = carried.id AND p.status <> carried.to_status
+    RETURNING p.id
+)
+INSERT INTO procurement_status_history (procurement_id, from_status, to_status, amount, note)
+SELECT carried.id, carried.from_status, carried.to_status, carried.amount, 'Goods receipt #' || carried.receipt_id
+FROM carried
+JOIN moved ON moved.id = carried.id;
+
+ALTER TABLE procurement_items ALTER COLUMN quantity_received SET NOT NULL;
+
+-- +migrate Down
+ALTER TABLE procurement_items ALTER COLUMN quantity_received DROP NOT NULL;
+ALTER TABLE procurement_items DROP COLUMN IF EXISTS quantity_rejected;
+DROP TABLE IF EXISTS goods_receipt_items;
+DROP TABLE IF EXISTS goods_receipts;
@@ -0,0 +1,92 @@
+-- +migrate Up
+-- Accounts payable: supplier bills are raised from goods accepted on purchase order receipts and
+-- from agent collections, each line billed once. Bills post credits (owed to the supplier) and
+-- supplier payments post debits to the supplier ledger. A payment may settle a particular bill or
+-- sit on the supplier's account.
+CREATE TABLE supplier_bills (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('procurement', 'collection')),
+    procurement_id INTEGER REFERENCES procurements(id),
+    period_start DATE,
+    period_end DATE,
+    reference VARCHAR(100),
+    bill_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    due_date DATE,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    amount_paid DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (amount_paid >= 0 AND amount_paid <= amount),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'partially_paid', 'paid')),
+    notes TEXT,
+    created_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_supplier_bills_supplier ON supplier_bills(supplier_id, bill_date);
+CREATE INDEX idx_supplier_bills_status ON supplier_bills(status);
+
+CREATE TABLE supplier_bill_lines (
+    id SERIAL PRIMARY KEY,
+    bill_id INTEGER NOT NULL REFERENCES supplier_bills(id) ON DELETE CASCADE,
+    goods_receipt_item_id INTEGER UNIQUE REFERENCES goods_receipt_items(id),
+    collection_id INTEGER UNIQUE REFERENCES collections(id),
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    line_date DATE NOT NULL,
+    quantity DECIMAL(12, 3) NOT NULL,
+    unit_price DECIMAL(12, 4) NOT NULL,
+    line_total DECIMAL(14, 2) NOT NULL,
+    CHECK (goods_receipt_item_id IS NOT NULL OR collection_id IS NOT NULL)
+);
+
+CREATE INDEX idx_supplier_bill_lines_bill ON supplier_bill_lines(bill_id);
+
+CREATE TABLE supplier_payments (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id),
+    bill_id INTEGER REFERENCES supplier_bills(id),
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    payment_method VARCHAR(30) NOT NULL CHECK (payment_method IN ('cash', 'upi', 'bank_transfer', 'cheque')),
+    reference VARCHAR(100),
+    notes TEXT,
+    paid_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    recorded_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_supplier_payments_supplier ON supplier_payments(supplier_id);
+
+CREATE TABLE supplier_ledger_entries (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id),
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('bill', 'payment', 'adjustment')),
+    bill_id INTEGER REFERENCES supplier_bills(id) ON DELETE SET NULL,
+    payment_id INTEGER REFERENCES supplier_payments(id) ON DELETE SET NULL,
+    debit DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    description TEXT,
+    entry_date TIMESTAMP NOT NULL DEFAULT NOW(),
+    due_date DATE,
+    created_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_supplier_ledger_supplier_date ON supplier_ledger_entries(supplier_id, entry_date);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('payables.view', 'View supplier bills, balances and statements'),
+    ('payables.manage', 'Raise supplier bills and record supplier payments')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions WHERE slug IN ('payables.view', 'payables.manage')
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission_id IN (
+    SELECT id FROM permissions WHERE slug IN ('payables.view', 'payables.manage')
+);
+DELETE FROM permissions WHERE slug IN ('payables.view', 'payables.manage');
+DROP TABLE IF EXISTS supplier_ledger_entries;
+DROP TABLE IF EXISTS supplier_payments;
+DROP TABLE IF EXISTS supplier_bill_lines;
+DROP TABLE IF EXISTS supplier_bills;
@@ -0,0 +1,70 @@
+-- +migrate Up
+-- Milk is bought on quality, not just weight. Each collection records the shift it was taken in,
+-- the fat and SNF (solids-not-fat) percentages and temperature measured at the point of collection,
+-- and any adulterants detected. When fat and SNF are known the collection is priced from the rate
+-- chart in force for the supplier: the chart for the supplier itself, else the one for the supplier's
+-- zone, else the default chart for the variant. The rate and amount payable are kept on the
+-- collection so later chart changes don't reprice it.
+CREATE TABLE collection_rate_charts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id),
+    zone_id INTEGER REFERENCES delivery_zones(id) ON DELETE CASCADE,
+    supplier_id INTEGER REFERENCES suppliers(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL DEFAULT CURRENT_DATE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_user_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (zone_id IS NULL OR supplier_id IS NULL)
+);
+
+CREATE INDEX idx_collection_rate_charts_lookup ON collection_rate_charts(variant_id, effective_from) WHERE is_active;
+
+-- A chart is a grid of slabs: milk is paid the rate of the highest slab whose fat and SNF
+-- minimums it meets
+CREATE TABLE collection_rate_slabs (
+    id SERIAL PRIMARY KEY,
+    chart_id INTEGER NOT NULL REFERENCES collection_rate_charts(id) ON DELETE CASCADE,
+    min_fat_percent DECIMAL(5, 2) NOT NULL CHECK (min_fat_percent >= 0),
+    min_snf_percent DECIMAL(5, 2) NOT NULL CHECK (min_snf_percent >= 0),
+    rate DECIMAL(12, 4) NOT NULL CHECK (rate >= 0),
+    UNIQUE (chart_id, min_fat_percent, min_snf_percent)
+);
+
+ALTER TABLE collections
+    ADD COLUMN shift VARCHAR(10) NOT NULL DEFAULT 'morning' CHECK (shift IN ('morning', 'evening')),
+    ADD COLUMN fat_percent DECIMAL(5, 2) CHECK (fat_percent > 0 AND fat_percent <= 100),
+    ADD COLUMN snf_percent DECIMAL(5, 2) CHECK (snf_percent > 0 AND snf_percent <= 100),
+    ADD COLUMN temperature DECIMAL(5, 2),
+    ADD COLUMN adulteration_flags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN rate_chart_id INTEGER REFERENCES collection_rate_charts(id) ON DELETE SET NULL,
+    ADD COLUMN rate DECIMAL(12, 4),
+    ADD COLUMN amount DECIMAL(12, 2);
+
+UPDATE collections SET shift = 'evening' WHERE EXTRACT(HOUR FROM collected_at) >= 12;
+
+CREATE INDEX idx_collections_supplier_shift ON collections(supplier_id, collected_at, shift);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('collection_rates.manage', 'Configure milk collection rate charts')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions WHERE slug = 'collection_rates.manage'
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE slug = 'collection_rates.manage');
+DELETE FROM permissions WHERE slug = 'collection_rates.manage';
+DROP INDEX IF EXISTS idx_collections_supplier_shift;
+ALTER TABLE collections
+    DROP COLUMN IF EXISTS amount,
+    DROP COLUMN IF EXISTS rate,
+    DROP COLUMN IF EXISTS rate_chart_id,
+    DROP COLUMN IF EXISTS adulteration_flags,
+    DROP COLUMN IF EXISTS temperature,
+    DROP COLUMN IF EXISTS snf_percent,
+    DROP COLUMN IF EXISTS fat_percent,
+    DROP COLUMN IF EXISTS shift;
+DROP TABLE IF EXISTS collection_rate_slabs;
+DROP TABLE IF EXISTS collection_rate_charts;
@@ -0,0 +1,41 @@
+-- +migrate Up
+-- A collection route is the ordered round of suppliers an agent visits every shift. Routes are
+-- usually built from the suppliers of a zone and then reordered to follow the road. The shift
+-- sheet for a route lists each stop with the collections recorded from it that shift.
+CREATE TABLE collection_routes (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    agent_id INTEGER NOT NULL REFERENCES users(id),
+    zone_id INTEGER REFERENCES delivery_zones(id) ON DELETE SET NULL,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_collection_routes_agent ON collection_routes(agent_id) WHERE is_active;
+
+CREATE TABLE collection_route_stops (
+    route_id INTEGER NOT NULL REFERENCES collection_routes(id) ON DELETE CASCADE,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    PRIMARY KEY (route_id, supplier_id),
+    UNIQUE (route_id, sequence)
+);
+
+CREATE INDEX idx_collections_agent_collected_at ON collections(agent_id, collected_at);
+
+INSERT INTO permissions (slug, description) VALUES
+    ('collection_routes.manage', 'Plan collection routes and assign them to agents')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions WHERE slug = 'collection_routes.manage'
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE slug = 'collection_routes.manage');
+DELETE FROM permissions WHERE slug = 'collection_routes.manage';
+DROP INDEX IF EXISTS idx_collections_agent_collected_at;
+DROP TABLE IF EXISTS collection_route_stops;
+DROP TABLE IF EXISTS collection_routes;
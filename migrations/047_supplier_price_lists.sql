@@ -0,0 +1,58 @@
+-- +migrate Up
+-- Supplier prices are kept as dated price lists rather than overwritten in place, so the price in
+-- force on any date can be looked up after a supplier renegotiates. A supplier has at most one list
+-- per variant on any date; a list with no valid_to runs until the next one starts. Each list is a
+-- set of quantity breaks: an order line is priced at the tier with the highest minimum quantity it
+-- reaches, and every list has a base tier from zero.
+-- btree_gist lets the exclusion constraint compare the supplier and variant alongside the dates
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE supplier_price_lists (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    notes TEXT,
+    created_by_user_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (valid_to IS NULL OR valid_to >= valid_from),
+    CONSTRAINT supplier_price_lists_no_overlap EXCLUDE USING gist (
+        supplier_id WITH =,
+        variant_id WITH =,
+        daterange(valid_from, valid_to, '[]') WITH &&
+    )
+);
+
+CREATE INDEX idx_supplier_price_lists_lookup ON supplier_price_lists(supplier_id, variant_id, valid_from);
+
+CREATE TABLE supplier_price_tiers (
+    id SERIAL PRIMARY KEY,
+    price_list_id INTEGER NOT NULL REFERENCES supplier_price_lists(id) ON DELETE CASCADE,
+    min_quantity DECIMAL(12, 3) NOT NULL CHECK (min_quantity >= 0),
+    unit_price DECIMAL(12, 4) NOT NULL CHECK (unit_price >= 0),
+    UNIQUE (price_list_id, min_quantity)
+);
+
+-- Carry the agreed costs over as the first price lists
+INSERT INTO supplier_price_lists (supplier_id, variant_id, valid_from, notes)
+SELECT supplier_id, variant_id, CURRENT_DATE, 'Agreed cost before price lists'
+FROM supplier_variants
+WHERE agreed_cost > 0;
+
+INSERT INTO supplier_price_tiers (price_list_id, min_quantity, unit_price)
+SELECT pl.id, 0, sv.agreed_cost
+FROM supplier_price_lists pl
+JOIN supplier_variants sv ON sv.supplier_id = pl.supplier_id AND sv.variant_id = pl.variant_id;
+
+-- Purchase order lines keep the list price they were raised against
+ALTER TABLE procurement_items
+    ADD COLUMN price_list_id INTEGER REFERENCES supplier_price_lists(id) ON DELETE SET NULL,
+    ADD COLUMN list_price DECIMAL(12, 4);
+
+-- +migrate Down
+ALTER TABLE procurement_items
+    DROP COLUMN IF EXISTS list_price,
+    DROP COLUMN IF EXISTS price_list_id;
+DROP TABLE IF EXISTS supplier_price_tiers;
+DROP TABLE IF EXISTS supplier_price_lists;
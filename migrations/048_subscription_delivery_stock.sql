@@ -0,0 +1,17 @@
+-- +migrate Up
+-- Subscription deliveries take the delivered items out of stock when they are recorded as
+-- delivered, referencing the delivery log
+ALTER TABLE inventory_movements DROP CONSTRAINT inventory_movements_source_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_source_type_check CHECK (source_type IN (
+    'opening', 'adjustment', 'sale', 'sale_cancellation', 'collection', 'procurement',
+    'transfer_out', 'transfer_in', 'transfer_cancellation', 'production_input', 'production_output',
+    'subscription_delivery'
+));
+
+-- +migrate Down
+-- The ledger is append-only, so delivery entries stay and the old check is not re-validated
+ALTER TABLE inventory_movements DROP CONSTRAINT inventory_movements_source_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_source_type_check CHECK (source_type IN (
+    'opening', 'adjustment', 'sale', 'sale_cancellation', 'collection', 'procurement',
+    'transfer_out', 'transfer_in', 'transfer_cancellation', 'production_input', 'production_output'
+)) NOT VALID;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Deliveries recorded after a customer was invoiced for a month are billed on a supplementary
+-- invoice for the month, numbered in sequence after the first. Invoice lines stay unique per
+-- delivery and product, so each delivery is still billed only once.
+ALTER TABLE subscription_invoices ADD COLUMN sequence INTEGER NOT NULL DEFAULT 1 CHECK (sequence >= 1);
+ALTER TABLE subscription_invoices DROP CONSTRAINT subscription_invoices_customer_id_period_start_key;
+ALTER TABLE subscription_invoices ADD CONSTRAINT subscription_invoices_customer_period_sequence_key
+    UNIQUE (customer_id, period_start, sequence);
+
+-- +migrate Down
+ALTER TABLE subscription_invoices DROP CONSTRAINT IF EXISTS subscription_invoices_customer_period_sequence_key;
+ALTER TABLE subscription_invoices ADD CONSTRAINT subscription_invoices_customer_id_period_start_key
+    UNIQUE (customer_id, period_start);
+ALTER TABLE subscription_invoices DROP COLUMN IF EXISTS sequence;
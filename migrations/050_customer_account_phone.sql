@@ -0,0 +1,13 @@
+-- +migrate Up
+-- Storefront phone numbers are not verified, so an account no longer claims the customer record
+-- sharing its phone; it gets a record of its own until staff merge the two. A phone is unique
+-- among records not linked to an account, and among those that are.
+ALTER TABLE customers DROP CONSTRAINT customers_phone_key;
+CREATE UNIQUE INDEX customers_phone_key ON customers(phone) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX customers_account_phone_key ON customers(phone) WHERE user_id IS NOT NULL;
+
+-- +migrate Down
+-- Fails while an account record and a customer record share a phone; merge them first
+DROP INDEX IF EXISTS customers_account_phone_key;
+DROP INDEX IF EXISTS customers_phone_key;
+ALTER TABLE customers ADD CONSTRAINT customers_phone_key UNIQUE (phone);
@@ -74,11 +74,19 @@ func main() {
 			_ = db.Pool.QueryRow(ctx, "SELECT id FROM product_variants WHERE sku = $1", p.sku).Scan(&variantID)
 		}
 
-		// Set Inventory
+		// Set Inventory, recording the change on the stock ledger
 		_, _ = db.Pool.Exec(ctx, `
-			INSERT INTO inventory_levels (warehouse_id, variant_id, quantity)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET quantity = EXCLUDED.quantity
+			WITH prev AS (
+				SELECT quantity FROM inventory_levels WHERE warehouse_id = $1 AND variant_id = $2
+			), level AS (
+				INSERT INTO inventory_levels (warehouse_id, variant_id, quantity)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (warehouse_id, variant_id) DO UPDATE SET quantity = EXCLUDED.quantity
+				RETURNING quantity
+			)
+			INSERT INTO inventory_movements (warehouse_id, variant_id, source_type, quantity_delta, balance_after, notes)
+			SELECT $1, $2, 'opening', level.quantity - COALESCE((SELECT quantity FROM prev), 0), level.quantity, 'Demo seed'
+			FROM level
 		`, warehouseID, variantID, p.qty)
 
 		// 5. Seed 14 days of sales for this product
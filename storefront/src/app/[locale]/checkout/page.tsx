@@ -41,7 +41,8 @@ export default function CheckoutPage() {
             const orderData = {
                 customer_name: formData.name,
                 customer_phone: formData.phone,
-                address: `${formData.address}${pincode ? " (Pincode: " + pincode + ")" : ""}`,
+                address: formData.address,
+                pincode: pincode,
                 items: items.map((item) => ({
                     variant_id: item.id,
                     quantity: item.quantity,
@@ -129,6 +129,7 @@ export default function SignupPage() {
                                     <Phone className="absolute left-3 top-1/2 -translate-y-1/2 w-5 h-5 text-slate-400" />
                                     <input
                                         type="tel"
+                                        required
                                         className="w-full pl-10 pr-4 py-3 bg-white/50 border border-slate-200 rounded-xl focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary transition-all"
                                         placeholder="9876543210"
                                         value={formData.phone}
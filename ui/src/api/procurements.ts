@@ -1,5 +1,7 @@
 import api from "./axios";
-import type { ApiResponse, ProcurementResponse, CreateProcurementRequest } from "@/types";
+import type {
+    ApiResponse, ProcurementResponse, CreateProcurementRequest, CreateGoodsReceiptRequest, GoodsReceiptResponse,
+} from "@/types";
 
 export const procurementsApi = {
     list: (page = 1, perPage = 20) =>
@@ -14,9 +16,16 @@ export const procurementsApi = {
     listBySupplier: (supplierId: number) =>
         api.get<ApiResponse<ProcurementResponse[]>>(`/procurements/supplier/${supplierId}`),
 
-    updateStatus: (id: number, status: string) =>
-        api.patch(`/procurements/${id}/status`, { status }),
+    // Approval goes through its own endpoint; receipts move an order to partial or received
+    updateStatus: (id: number, status: string, note?: string) =>
+        api.patch(`/procurements/${id}/status`, { status, note }),
 
-    receiveItems: (id: number, items: { item_id: number; quantity_received: string }[]) =>
-        api.patch(`/procurements/${id}/receive`, { items }),
+    approve: (id: number, note?: string) =>
+        api.post<ApiResponse<ProcurementResponse>>(`/procurements/${id}/approve`, { note }),
+
+    listReceipts: (id: number) =>
+        api.get<ApiResponse<GoodsReceiptResponse[]>>(`/procurements/${id}/receipts`),
+
+    receiveGoods: (id: number, data: CreateGoodsReceiptRequest) =>
+        api.post<ApiResponse<GoodsReceiptResponse>>(`/procurements/${id}/receipts`, data),
 };
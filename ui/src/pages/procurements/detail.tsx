@@ -1,6 +1,5 @@
 import { useState, useEffect, useCallback, useMemo } from "react";
 import { useParams, useNavigate } from "react-router-dom";
-import { useAuthStore } from "@/stores/auth-store";
 import { toast } from "sonner";
 import { ArrowLeft, Package, CheckCircle2, Truck, PackageCheck, XCircle } from "lucide-react";
 import { procurementsApi } from "@/api/procurements";
@@ -38,9 +37,10 @@ const STATUS_ICONS: Record<string, React.ReactNode> = {
     cancelled: <XCircle className="h-4 w-4" />,
 };
 
-// Workflow: draft → approved → ordered → received
-// Any non-terminal status can be cancelled
-// Workflow definitions
+// Workflow: pending → approved → ordered → partial → received
+// Approval goes through the approve endpoint, which checks the approver's limit.
+// Goods move an order to partial or received through receipts; a partial order can
+// only be closed short. Orders can be cancelled until something has been received.
 const WORKFLOW_ACTIONS: Record<string, { label: string; status: string; variant: "default" | "destructive" }[]> = {
     pending: [
         { label: "Approve", status: "approved", variant: "default" },
@@ -55,15 +55,22 @@ const WORKFLOW_ACTIONS: Record<string, { label: string; status: string; variant:
         { label: "Cancel", status: "cancelled", variant: "destructive" },
     ],
     ordered: [
-        { label: "Mark as Received", status: "received", variant: "default" },
         { label: "Cancel", status: "cancelled", variant: "destructive" },
     ],
     partial: [
-        { label: "Mark as Received", status: "received", variant: "default" },
-        { label: "Cancel", status: "cancelled", variant: "destructive" },
+        { label: "Close Short", status: "received", variant: "default" },
     ],
 };
 
+type ReceiveLine = {
+    item_id: number;
+    quantity_accepted: string;
+    quantity_rejected: string;
+    rejection_reason: string;
+    label: string;
+    max: string;
+};
+
 export default function ProcurementDetailPage() {
     const { id } = useParams<{ id: string }>();
     const navigate = useNavigate();
@@ -72,12 +79,11 @@ export default function ProcurementDetailPage() {
     const [po, setPo] = useState<ProcurementResponse | null>(null);
     const [loading, setLoading] = useState(true);
     const [statusSaving, setStatusSaving] = useState(false);
-    const user = useAuthStore((s) => s.user);
-    const isAdminOrManager = user?.role?.name?.toLowerCase() === "admin" || user?.role?.name?.toLowerCase() === "manager";
 
     // Receive items dialog
     const [receiveOpen, setReceiveOpen] = useState(false);
-    const [receiveItems, setReceiveItems] = useState<{ item_id: number; quantity_received: string; label: string; max: string }[]>([]);
+    const [receiveItems, setReceiveItems] = useState<ReceiveLine[]>([]);
+    const [deliveryReference, setDeliveryReference] = useState("");
     const [receiveSaving, setReceiveSaving] = useState(false);
 
     const load = useCallback(async () => {
@@ -94,70 +100,76 @@ export default function ProcurementDetailPage() {
     const handleStatusChange = async (newStatus: string) => {
         setStatusSaving(true);
         try {
-            await procurementsApi.updateStatus(poId, newStatus);
+            if (newStatus === "approved") {
+                await procurementsApi.approve(poId);
+            } else {
+                await procurementsApi.updateStatus(poId, newStatus);
+            }
             toast.success(`Status updated to ${newStatus}`);
             load();
-        } catch { toast.error("Failed to update status"); }
+        } catch (error: any) {
+            toast.error(error.response?.data?.error?.message || "Failed to update status");
+        }
         finally { setStatusSaving(false); }
     };
 
     const openReceive = () => {
         if (!po?.items) return;
         setReceiveItems(
-            po.items.map((item) => ({
-                item_id: item.id,
-                quantity_received: "",
-                label: `${item.variant_name || `Variant #${item.variant_id}`} (${item.variant_sku || "—"})`,
-                max: item.quantity_ordered,
-            }))
+            po.items
+                .filter((item) => parseFloat(item.quantity_outstanding) > 0)
+                .map((item) => ({
+                    item_id: item.id,
+                    quantity_accepted: "",
+                    quantity_rejected: "",
+                    rejection_reason: "",
+                    label: `${item.variant_name || `Variant #${item.variant_id}`} (${item.variant_sku || "—"})`,
+                    max: item.quantity_outstanding,
+                }))
         );
+        setDeliveryReference("");
         setReceiveOpen(true);
     };
 
-    const updateReceiveQty = (index: number, value: string) => {
+    const updateReceiveLine = (index: number, field: "quantity_accepted" | "quantity_rejected" | "rejection_reason", value: string) => {
         const updated = [...receiveItems];
-        updated[index].quantity_received = value;
+        updated[index] = { ...updated[index], [field]: value };
         setReceiveItems(updated);
     };
 
     const handleReceive = async () => {
-        const valid = receiveItems.filter((i) => i.quantity_received && parseFloat(i.quantity_received) > 0);
+        const valid = receiveItems.filter((i) =>
+            parseFloat(i.quantity_accepted || "0") > 0 || parseFloat(i.quantity_rejected || "0") > 0
+        );
         if (!valid.length) { toast.error("Enter quantities for at least one item"); return; }
+        if (valid.some((i) => parseFloat(i.quantity_rejected || "0") > 0 && !i.rejection_reason.trim())) {
+            toast.error("Give a reason for rejected quantities");
+            return;
+        }
         setReceiveSaving(true);
         try {
-            await procurementsApi.receiveItems(poId, valid.map((i) => ({
-                item_id: i.item_id,
-                quantity_received: i.quantity_received,
-            })));
-            toast.success("Items received");
+            await procurementsApi.receiveGoods(poId, {
+                delivery_reference: deliveryReference || undefined,
+                items: valid.map((i) => ({
+                    item_id: i.item_id,
+                    quantity_accepted: i.quantity_accepted || "0",
+                    quantity_rejected: i.quantity_rejected || "0",
+                    rejection_reason: i.rejection_reason || undefined,
+                })),
+            });
+            toast.success("Goods received");
             setReceiveOpen(false);
             load();
-        } catch { toast.error("Failed to receive items"); }
+        } catch (error: any) {
+            toast.error(error.response?.data?.error?.message || "Failed to receive items");
+        }
         finally { setReceiveSaving(false); }
     };
 
     const availableActions = useMemo(() => {
         if (!po) return [];
-        const standardActions = WORKFLOW_ACTIONS[po.status] || [];
-
-        if (isAdminOrManager) {
-            // Admins can transition to almost anything from anywhere if not terminal
-            const isTerminal = po.status === "received" || po.status === "cancelled";
-            if (isTerminal) return [];
-
-            const allPossible: { label: string; status: string; variant: "default" | "destructive" | "outline" | "secondary" }[] = [
-                { label: "Approve", status: "approved", variant: "secondary" },
-                { label: "Order", status: "ordered", variant: "secondary" },
-                { label: "Receive", status: "received", variant: "default" },
-                { label: "Cancel", status: "cancelled", variant: "destructive" },
-            ];
-
-            // Filter out current status
-            return allPossible.filter(a => a.status !== po.status);
-        }
-
-        return standardActions;
-    }, [po, isAdminOrManager]);
+        return WORKFLOW_ACTIONS[po.status] || [];
+    }, [po]);
 
     if (loading) {
         return (
@@ -226,7 +238,7 @@ export default function ProcurementDetailPage() {
                 <CardHeader>
                     <div className="flex items-center justify-between">
                         <CardTitle className="text-lg">Items</CardTitle>
-                        {po.status === "ordered" && (
+                        {(po.status === "ordered" || po.status === "partial") && (
                             <Button size="sm" onClick={openReceive}>
                                 <PackageCheck className="mr-2 h-4 w-4" /> Receive Items
                             </Button>
@@ -301,26 +313,52 @@ export default function ProcurementDetailPage() {
 
             {/* Receive Items Dialog */}
             <Dialog open={receiveOpen} onOpenChange={setReceiveOpen}>
-                <DialogContent className="max-w-lg">
+                <DialogContent className="max-w-2xl">
                     <DialogHeader>
                         <DialogTitle>Receive Items</DialogTitle>
                     </DialogHeader>
+                    <div className="space-y-2">
+                        <Label className="text-sm">Delivery Reference</Label>
+                        <Input
+                            value={deliveryReference}
+                            onChange={(e) => setDeliveryReference(e.target.value)}
+                            placeholder="Supplier delivery note or invoice number"
+                        />
+                    </div>
                     <div className="space-y-4 py-4 max-h-80 overflow-y-auto">
                         {receiveItems.map((item, idx) => (
-                            <div key={item.item_id} className="flex items-center gap-4">
-                                <div className="flex-1">
-                                    <Label className="text-sm">{item.label}</Label>
-                                    <p className="text-xs text-muted-foreground">Ordered: {item.max}</p>
+                            <div key={item.item_id} className="space-y-2">
+                                <div className="flex items-center gap-4">
+                                    <div className="flex-1">
+                                        <Label className="text-sm">{item.label}</Label>
+                                        <p className="text-xs text-muted-foreground">Outstanding: {item.max}</p>
+                                    </div>
+                                    <div className="w-28">
+                                        <Input
+                                            type="number"
+                                            step="0.01"
+                                            value={item.quantity_accepted}
+                                            onChange={(e) => updateReceiveLine(idx, "quantity_accepted", e.target.value)}
+                                            placeholder="Accepted"
+                                        />
+                                    </div>
+                                    <div className="w-28">
+                                        <Input
+                                            type="number"
+                                            step="0.01"
+                                            value={item.quantity_rejected}
+                                            onChange={(e) => updateReceiveLine(idx, "quantity_rejected", e.target.value)}
+                                            placeholder="Rejected"
+                                        />
+                                    </div>
                                 </div>
-                                <div className="w-28">
+                                {parseFloat(item.quantity_rejected || "0") > 0 && (
                                     <Input
-                                        type="number"
-                                        step="0.01"
-                                        value={item.quantity_received}
-                                        onChange={(e) => updateReceiveQty(idx, e.target.value)}
-                                        placeholder="Qty"
+                                        value={item.rejection_reason}
+                                        onChange={(e) => updateReceiveLine(idx, "rejection_reason", e.target.value)}
+                                        placeholder="Reason for rejection"
                                     />
-                                </div>
+                                )}
                             </div>
                         ))}
                     </div>
@@ -238,8 +238,12 @@ export interface ProcurementItemResponse {
     variant_unit?: string;
     quantity_ordered: string;
     quantity_received: string;
+    quantity_rejected: string;
+    quantity_outstanding: string;
     unit_cost: string;
     line_total: string;
+    list_price?: string;
+    above_list_price: boolean;
 }
 
 export interface CreateProcurementRequest {
@@ -249,6 +253,44 @@ export interface CreateProcurementRequest {
     items: { variant_id: number; quantity: string; unit_cost: string }[];
 }
 
+export interface GoodsReceiptItemRequest {
+    item_id: number;
+    quantity_accepted: string;
+    quantity_rejected?: string;
+    rejection_reason?: string;
+    batch_number?: string;
+    expiry_date?: string;
+}
+
+export interface CreateGoodsReceiptRequest {
+    delivery_reference?: string;
+    notes?: string;
+    items: GoodsReceiptItemRequest[];
+}
+
+export interface GoodsReceiptResponse {
+    id: number;
+    procurement_id: number;
+    warehouse_id: number;
+    received_by_user_id?: number;
+    received_by_name?: string;
+    delivery_reference?: string;
+    notes?: string;
+    received_at: string;
+    items: {
+        id: number;
+        procurement_item_id: number;
+        variant_id: number;
+        variant_name?: string;
+        quantity_accepted: string;
+        quantity_rejected: string;
+        rejection_reason?: string;
+        batch_number?: string;
+        expiry_date?: string;
+        lot_id?: number;
+    }[];
+}
+
 // --- Collections ---
 export interface CollectionResponse {
     id: number;